@@ -77,6 +77,7 @@ type ListOptions struct {
 	Parent     string // filter by parent ID
 	Assignee   string // filter by assignee (e.g., "gastown/Toast")
 	NoAssignee bool   // filter for issues with no assignee
+	Limit      int    // max results: 0 for bd's default, -1 for no limit
 }
 
 // CreateOptions specifies options for creating an issue.
@@ -313,6 +314,11 @@ func (b *Beads) List(opts ListOptions) ([]*Issue, error) {
 	if opts.NoAssignee {
 		args = append(args, "--no-assignee")
 	}
+	if opts.Limit < 0 {
+		args = append(args, "--limit=0")
+	} else if opts.Limit > 0 {
+		args = append(args, fmt.Sprintf("--limit=%d", opts.Limit))
+	}
 
 	out, err := b.run(args...)
 	if err != nil {
@@ -561,13 +561,18 @@ author: someone`,
 // TestMRFieldsRoundTrip tests that parse/format round-trips correctly.
 func TestMRFieldsRoundTrip(t *testing.T) {
 	original := &MRFields{
-		Branch:      "polecat/Nux/gt-xyz",
-		Target:      "main",
-		SourceIssue: "gt-xyz",
-		Worker:      "Nux",
-		Rig:         "gastown",
-		MergeCommit: "abc123def789",
-		CloseReason: "merged",
+		Branch:       "polecat/Nux/gt-xyz",
+		Target:       "main",
+		SourceIssue:  "gt-xyz",
+		Worker:       "Nux",
+		Rig:          "gastown",
+		MergeCommit:  "abc123def789",
+		CloseReason:  "merged",
+		FailureType:  "post_merge",
+		RevertCommit: "fed987cba321",
+		ParentMR:     "gt-mr-parent",
+		ParentBranch: "polecat/Toast/gt-abc",
+		ParentHead:   "0123456789ab",
 	}
 
 	// Format to string
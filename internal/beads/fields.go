@@ -197,6 +197,15 @@ type MRFields struct {
 	// Convoy tracking (for priority scoring - convoy starvation prevention)
 	ConvoyID        string // Parent convoy ID if part of a convoy
 	ConvoyCreatedAt string // Convoy creation time (ISO 8601) for starvation prevention
+
+	// Post-merge verification fields (set when a landed merge broke the target)
+	FailureType  string // Failure category of the last attempt (e.g., "post_merge")
+	RevertCommit string // SHA of the revert commit that backed the merge out
+
+	// Stacked MR fields (set when this MR builds on another unmerged MR)
+	ParentMR     string // MR bead this one is stacked on
+	ParentBranch string // Parent MR's source branch (the base this branch was cut from)
+	ParentHead   string // Parent branch tip when the parent landed (set by the Refinery)
 }
 
 // ParseMRFields extracts structured merge-request fields from an issue's description.
@@ -271,6 +280,21 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "convoy_created_at", "convoy-created-at", "convoycreatedat":
 			fields.ConvoyCreatedAt = value
 			hasFields = true
+		case "failure_type", "failure-type", "failuretype":
+			fields.FailureType = value
+			hasFields = true
+		case "revert_commit", "revert-commit", "revertcommit":
+			fields.RevertCommit = value
+			hasFields = true
+		case "parent_mr", "parent-mr", "parentmr":
+			fields.ParentMR = value
+			hasFields = true
+		case "parent_branch", "parent-branch", "parentbranch":
+			fields.ParentBranch = value
+			hasFields = true
+		case "parent_head", "parent-head", "parenthead":
+			fields.ParentHead = value
+			hasFields = true
 		}
 	}
 
@@ -335,6 +359,21 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.ConvoyCreatedAt != "" {
 		lines = append(lines, "convoy_created_at: "+fields.ConvoyCreatedAt)
 	}
+	if fields.FailureType != "" {
+		lines = append(lines, "failure_type: "+fields.FailureType)
+	}
+	if fields.RevertCommit != "" {
+		lines = append(lines, "revert_commit: "+fields.RevertCommit)
+	}
+	if fields.ParentMR != "" {
+		lines = append(lines, "parent_mr: "+fields.ParentMR)
+	}
+	if fields.ParentBranch != "" {
+		lines = append(lines, "parent_branch: "+fields.ParentBranch)
+	}
+	if fields.ParentHead != "" {
+		lines = append(lines, "parent_head: "+fields.ParentHead)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -381,6 +420,21 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 		"convoy_created_at":  true,
 		"convoy-created-at":  true,
 		"convoycreatedat":    true,
+		"failure_type":       true,
+		"failure-type":       true,
+		"failuretype":        true,
+		"revert_commit":      true,
+		"revert-commit":      true,
+		"revertcommit":       true,
+		"parent_mr":          true,
+		"parent-mr":          true,
+		"parentmr":           true,
+		"parent_branch":      true,
+		"parent-branch":      true,
+		"parentbranch":       true,
+		"parent_head":        true,
+		"parent-head":        true,
+		"parenthead":         true,
 	}
 
 	// Collect non-MR lines from existing description
@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/eventstore"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/townlog"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -25,6 +26,8 @@ var (
 	auditSince string
 	auditLimit int
 	auditJSON  bool
+
+	auditVerifyJSON bool
 )
 
 var auditCmd = &cobra.Command{
@@ -46,16 +49,45 @@ Examples:
   gt audit --actor=mayor                  # Show mayor's activity
   gt audit --since=24h                    # Show all activity in last 24h
   gt audit --actor=joe --since=1h         # Combined filters
-  gt audit --json                         # Output as JSON`,
+  gt audit --json                         # Output as JSON
+  gt audit verify                         # Check the audit chain for tampering`,
 	RunE: runAudit,
 }
 
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the audit log's hash chain for edits and gaps",
+	Long: `Check that the audit log has not been edited.
+
+Every audit-visible event carries a sequence number and a hash chained to
+the event before it. Every 64 events, and on each KRC prune, the chain head
+is signed with the town's audit key as a checkpoint. The key is
+.runtime/audit.key, or $GT_AUDIT_KEY_FILE to keep it outside the town where
+agents can't re-sign an edited chain with it.
+When old days are sealed, each day's first and last records are anchored
+so the chain still verifies after their archives are deleted.
+
+verify reads the archive, sealed segments and the active log, and reports
+edited, deleted, duplicated or inserted records, records that no longer
+match a signed checkpoint, and truncation. The first problem names the
+first broken record. Exits 1 if the chain is broken.
+
+Examples:
+  gt audit verify
+  gt audit verify --json`,
+	Args: cobra.NoArgs,
+	RunE: runAuditVerify,
+}
+
 func init() {
 	auditCmd.Flags().StringVar(&auditActor, "actor", "", "Filter by actor (agent address or partial match)")
 	auditCmd.Flags().StringVar(&auditSince, "since", "", "Show events since duration (e.g., 1h, 24h, 7d)")
 	auditCmd.Flags().IntVarP(&auditLimit, "limit", "n", 50, "Maximum number of entries to show")
 	auditCmd.Flags().BoolVar(&auditJSON, "json", false, "Output as JSON")
 
+	auditVerifyCmd.Flags().BoolVar(&auditVerifyJSON, "json", false, "Output as JSON")
+	auditCmd.AddCommand(auditVerifyCmd)
+
 	rootCmd.AddCommand(auditCmd)
 }
 
@@ -144,6 +176,74 @@ func runAudit(cmd *cobra.Command, args []string) error {
 	return outputAuditText(allEntries)
 }
 
+func runAuditVerify(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	result, err := eventstore.Verify(townRoot)
+	if err != nil {
+		return fmt.Errorf("verifying audit chain: %w", err)
+	}
+
+	if auditVerifyJSON {
+		if err := outputJSON(result); err != nil {
+			return err
+		}
+	} else {
+		printAuditVerify(result)
+	}
+	if !result.OK() {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+func printAuditVerify(result *eventstore.VerifyResult) {
+	if result.Records == 0 && result.OK() {
+		fmt.Println("No chained audit records yet.")
+		return
+	}
+	if result.OK() {
+		fmt.Printf("%s Audit chain intact: %d records (%d-%d), %d checkpoints verified\n",
+			style.Success.Render("✓"), result.Records, result.First, result.Last, result.Checkpoints)
+	} else {
+		first := result.Problems[0]
+		headline := "First problem"
+		if first.Seq > 0 {
+			headline = fmt.Sprintf("First broken record: %d", first.Seq)
+		}
+		if first.Path != "" {
+			headline += fmt.Sprintf(" (%s:%d)", first.Path, first.Line)
+		}
+		fmt.Printf("%s Audit chain broken. %s\n", style.Error.Render("✗"), headline)
+		for _, p := range result.Problems {
+			loc := ""
+			if p.Path != "" {
+				loc = style.Dim.Render(fmt.Sprintf("  %s:%d", p.Path, p.Line))
+			}
+			seq := "-"
+			if p.Seq > 0 {
+				seq = fmt.Sprintf("%d", p.Seq)
+			}
+			fmt.Printf("  %6s  %s%s\n", seq, p.Reason, loc)
+		}
+	}
+	if len(result.Bridged) > 0 {
+		fmt.Printf("  Deleted days vouched for by anchors: %s\n", strings.Join(result.Bridged, ", "))
+	}
+	if result.Unchained > 0 {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("%d audit events predate the chain and cannot be verified", result.Unchained)))
+	}
+	if result.Keyless {
+		fmt.Printf("  %s\n", style.Warning.Render("No audit key: checkpoint and anchor signatures not checked"))
+	}
+	if result.KeyInTown {
+		fmt.Printf("  %s\n", style.Warning.Render(fmt.Sprintf(
+			"Audit key is inside the town, so agents could re-sign an edited chain; move it out and set %s", events.AuditKeyFileEnv)))
+	}
+}
+
 // parseDuration parses a duration string with support for days (d).
 func parseDuration(s string) (time.Duration, error) {
 	// Check for days suffix
@@ -399,50 +499,27 @@ func formatTownlogSummary(e townlog.Event) string {
 	}
 }
 
-// collectFeedEvents queries the activity feed for events.
+// collectFeedEvents queries the event log, including sealed segments, for
+// events.
 func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, error) {
 	var entries []AuditEntry
-
-	eventsPath := filepath.Join(townRoot, events.EventsFile)
-	file, err := os.Open(eventsPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // No events file yet
-		}
-		return nil, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		var e events.Event
-		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
-			continue // Skip malformed lines
-		}
-
+	err := eventstore.Scan(townRoot, eventstore.Query{Since: since}, func(e *events.Event) bool {
 		// Apply actor filter
 		if actor != "" && !matchesActor(e.Actor, actor) {
-			continue
+			return true
 		}
 
-		// Parse timestamp
 		ts, _ := time.Parse(time.RFC3339, e.Timestamp)
-
-		// Apply since filter
-		if !since.IsZero() && ts.Before(since) {
-			continue
-		}
-
 		entries = append(entries, AuditEntry{
 			Timestamp: ts,
 			Source:    "events",
 			Type:      e.Type,
 			Actor:     e.Actor,
-			Summary:   formatFeedSummary(e),
+			Summary:   formatFeedSummary(*e),
 		})
-	}
-
-	return entries, nil
+		return true
+	})
+	return entries, err
 }
 
 // formatFeedSummary creates a readable summary from a feed event.
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/crash"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Crash command flags
+var (
+	crashListAgent string
+	crashListLimit int
+	crashListJSON  bool
+
+	crashShowLines int
+	crashShowEnv   bool
+	crashShowJSON  bool
+)
+
+var crashCmd = &cobra.Command{
+	Use:     "crash",
+	GroupID: GroupDiag,
+	Short:   "Browse crash forensics captured when agent panes die",
+	RunE:    requireSubcommand,
+	Long: `Browse forensic bundles captured when an agent's pane dies.
+
+When an agent exits with a non-zero status, the pane-died hook captures a
+bundle under <town>/crashes/<id>/ before the pane disappears:
+
+  - the last 5000 lines of scrollback
+  - the exit status and death reason (crash or resource limit kill)
+  - the session environment (secret-looking values redacted)
+  - the hooked bead and current molecule step
+  - the worktree's branch, HEAD and git status
+  - the agent's recent events
+  - a link to the session recording, if the rig records sessions
+
+The newest 100 bundles are kept.
+
+Commands:
+  gt crash list          List bundles, newest first
+  gt crash show <id>     Show a bundle and its final output`,
+}
+
+var crashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List crash bundles",
+	Long: `List crash bundles, newest first.
+
+Examples:
+  gt crash list
+  gt crash list --agent gastown/`,
+	RunE: runCrashList,
+}
+
+var crashShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a crash bundle",
+	Long: `Show a crash bundle: what the agent was doing, the state of its
+worktree, its last events and the tail of its output.
+
+<id> may be a unique prefix of a bundle ID.
+
+Examples:
+  gt crash show 20260112-093012-gastown-Toast
+  gt crash show 20260112-0930 --lines 200
+  gt crash show 20260112-0930 --lines 0     # Full scrollback`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCrashShow,
+}
+
+func init() {
+	crashListCmd.Flags().StringVarP(&crashListAgent, "agent", "a", "", "Filter by agent prefix (e.g., gastown/)")
+	crashListCmd.Flags().IntVarP(&crashListLimit, "limit", "n", 20, "Maximum bundles to show (0 for all)")
+	crashListCmd.Flags().BoolVar(&crashListJSON, "json", false, "Output as JSON")
+
+	crashShowCmd.Flags().IntVarP(&crashShowLines, "lines", "n", 50, "Lines of final output to show (0 for all)")
+	crashShowCmd.Flags().BoolVar(&crashShowEnv, "env", false, "Show the session environment")
+	crashShowCmd.Flags().BoolVar(&crashShowJSON, "json", false, "Output the bundle as JSON")
+
+	crashCmd.AddCommand(crashListCmd)
+	crashCmd.AddCommand(crashShowCmd)
+	rootCmd.AddCommand(crashCmd)
+}
+
+func runCrashList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	bundles, err := crash.List(townRoot)
+	if err != nil {
+		return fmt.Errorf("listing crash bundles: %w", err)
+	}
+	var filtered []*crash.Bundle
+	for _, b := range bundles {
+		if crashListAgent != "" && !strings.HasPrefix(b.Agent, crashListAgent) && !strings.HasPrefix(b.Address, crashListAgent) {
+			continue
+		}
+		filtered = append(filtered, b)
+	}
+	if crashListLimit > 0 && len(filtered) > crashListLimit {
+		filtered = filtered[:crashListLimit]
+	}
+
+	if crashListJSON {
+		return outputJSON(filtered)
+	}
+
+	if len(filtered) == 0 {
+		fmt.Println("No crash bundles.")
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "ID", Width: 40},
+		style.Column{Name: "AGENT", Width: 28},
+		style.Column{Name: "AGE", Width: 8},
+		style.Column{Name: "REASON", Width: 24},
+		style.Column{Name: "HOOKED", Width: 12},
+	)
+	for _, b := range filtered {
+		hooked := b.HookedBead
+		if hooked == "" {
+			hooked = "-"
+		}
+		table.AddRow(b.ID, b.Address, formatAge(b.Time), b.Reason, hooked)
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runCrashShow(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	b, err := crash.Find(townRoot, args[0])
+	if err != nil {
+		return err
+	}
+	if crashShowJSON {
+		return outputJSON(b)
+	}
+	scrollback, err := crash.Scrollback(townRoot, b.ID)
+	if err != nil {
+		style.PrintWarning("could not read scrollback: %v", err)
+	}
+
+	fmt.Printf("%s %s\n", style.Bold.Render("Crash:"), b.ID)
+	fmt.Printf("  Agent:    %s\n", b.Address)
+	fmt.Printf("  Session:  %s\n", b.Session)
+	fmt.Printf("  Time:     %s (%s)\n", b.Time.Local().Format(time.RFC3339), formatAge(b.Time))
+	fmt.Printf("  Reason:   %s\n", style.Error.Render(b.Reason))
+
+	if b.HookedBead != "" {
+		fmt.Printf("\n%s\n", style.Bold.Render("Work"))
+		fmt.Printf("  Hooked:   %s %s\n", b.HookedBead, style.Dim.Render(b.HookedTitle))
+		if b.MoleculeStep != "" {
+			fmt.Printf("  Step:     %s %s\n", b.MoleculeStep, style.Dim.Render(b.StepTitle))
+		}
+	}
+
+	if b.Worktree != "" {
+		fmt.Printf("\n%s\n", style.Bold.Render("Worktree"))
+		fmt.Printf("  Path:     %s\n", b.Worktree)
+		if b.Branch != "" {
+			fmt.Printf("  Branch:   %s @ %s\n", b.Branch, shortSHA(b.Head))
+		}
+		if len(b.GitStatus) == 0 {
+			fmt.Printf("  Status:   %s\n", style.Dim.Render("clean"))
+		} else {
+			fmt.Printf("  Status:   %d uncommitted change(s)\n", len(b.GitStatus))
+			for _, line := range b.GitStatus {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+
+	if len(b.RecentEvents) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Recent events"))
+		start := 0
+		if len(b.RecentEvents) > 10 {
+			start = len(b.RecentEvents) - 10
+		}
+		for _, e := range b.RecentEvents[start:] {
+			fmt.Printf("  %s  %s\n", style.Dim.Render(e.Timestamp), e.Type)
+		}
+	}
+
+	if crashShowEnv && len(b.Env) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Environment"))
+		keys := make([]string, 0, len(b.Env))
+		for k := range b.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("  %s=%s\n", k, b.Env[k])
+		}
+	}
+
+	if len(b.Errors) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Not captured"))
+		for _, e := range b.Errors {
+			fmt.Printf("  %s\n", style.Dim.Render(e))
+		}
+	}
+
+	if b.Recording != "" {
+		fmt.Printf("\n%s gt session replay %s\n", style.Bold.Render("Recording:"), b.Session)
+	}
+
+	lines := strings.Split(strings.TrimRight(scrollback, "\n"), "\n")
+	if crashShowLines > 0 && len(lines) > crashShowLines {
+		lines = lines[len(lines)-crashShowLines:]
+	}
+	fmt.Printf("\n%s\n", style.Bold.Render(fmt.Sprintf("Final output (last %d of %d lines)", len(lines), b.ScrollbackLines)))
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// shortSHA abbreviates a commit hash for display.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/crew"
+	"github.com/steveyegge/gastown/internal/recording"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -181,6 +183,8 @@ func runCrewAt(cmd *cobra.Command, args []string) error {
 		theme := getThemeForRig(r.Name)
 		_ = t.ConfigureGasTownSession(sessionID, theme, r.Name, name, "crew")
 
+		_ = recording.Start(t, r.Path, sessionID)
+
 		// Wait for shell to be ready after session creation
 		if err := t.WaitForShellReady(sessionID, constants.ShellReadyTimeout); err != nil {
 			return fmt.Errorf("waiting for shell: %w", err)
@@ -213,11 +217,14 @@ func runCrewAt(cmd *cobra.Command, args []string) error {
 		if runtimeConfig.Session != nil && runtimeConfig.Session.ConfigDirEnv != "" && claudeConfigDir != "" {
 			startupCmd = config.PrependEnv(startupCmd, map[string]string{runtimeConfig.Session.ConfigDirEnv: claudeConfigDir})
 		}
+		startupCmd = withResourceLimits(townRoot, r.Path, "crew", sessionID, startupCmd)
+		startupCmd, discardSecrets := withSessionSecrets(townRoot, r.Name, "crew", address, startupCmd)
 		// Note: Don't call KillPaneProcesses here - this is a NEW session with just
 		// a fresh shell. Killing it would destroy the pane before we can respawn.
 		// KillPaneProcesses is only needed when restarting in an EXISTING session
 		// where Claude/Node processes might be running and ignoring SIGHUP.
 		if err := t.RespawnPane(paneID, startupCmd); err != nil {
+			discardSecrets()
 			return fmt.Errorf("starting runtime: %w", err)
 		}
 
@@ -260,6 +267,8 @@ func runCrewAt(cmd *cobra.Command, args []string) error {
 			if runtimeConfig.Session != nil && runtimeConfig.Session.ConfigDirEnv != "" && claudeConfigDir != "" {
 				startupCmd = config.PrependEnv(startupCmd, map[string]string{runtimeConfig.Session.ConfigDirEnv: claudeConfigDir})
 			}
+			startupCmd = withResourceLimits(townRoot, r.Path, "crew", sessionID, startupCmd)
+			startupCmd, discardSecrets := withSessionSecrets(townRoot, r.Name, "crew", address, startupCmd)
 			// Kill all processes in the pane before respawning to prevent orphan leaks
 			// RespawnPane's -k flag only sends SIGHUP which Claude/Node may ignore
 			if err := t.KillPaneProcesses(paneID); err != nil {
@@ -267,6 +276,7 @@ func runCrewAt(cmd *cobra.Command, args []string) error {
 				style.PrintWarning("could not kill pane processes: %v", err)
 			}
 			if err := t.RespawnPane(paneID, startupCmd); err != nil {
+				discardSecrets()
 				// If pane is stale (session exists but pane doesn't), recreate the session
 				if strings.Contains(err.Error(), "can't find pane") {
 					if crewAtRetried {
@@ -333,3 +343,13 @@ func runCrewAt(cmd *cobra.Command, args []string) error {
 	}
 	return attachToTmuxSession(sessionID)
 }
+
+// withResourceLimits wraps a session command with the role's resource
+// limits. Failures are warned about, never fatal.
+func withResourceLimits(townRoot, rigPath, role, sessionID, command string) string {
+	wrapped, warnings := sandbox.WrapRoleChecked(townRoot, rigPath, role, sessionID, command)
+	for _, w := range warnings {
+		style.PrintWarning("%s", w)
+	}
+	return wrapped
+}
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 	"os/exec"
 	"runtime"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/inbound"
 	"github.com/steveyegge/gastown/internal/web"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -29,6 +31,9 @@ The dashboard shows real-time convoy status with:
 - Last activity indicator (green/yellow/red)
 - Auto-refresh every 30 seconds via htmx
 
+Inbound webhooks configured in the town settings are accepted on
+/hooks/<source> (see gt inbound).
+
 Example:
   gt dashboard              # Start on default port 8080
   gt dashboard --port 3000  # Start on port 3000
@@ -47,7 +52,7 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 	var handler http.Handler
 	var err error
 
-	if _, wsErr := workspace.FindFromCwdOrError(); wsErr != nil {
+	if townRoot, wsErr := workspace.FindFromCwdOrError(); wsErr != nil {
 		// No workspace - run in setup mode
 		handler, err = web.NewSetupMux()
 		if err != nil {
@@ -60,10 +65,16 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("creating convoy fetcher: %w", fetchErr)
 		}
 
-		handler, err = web.NewDashboardMux(fetcher)
-		if err != nil {
-			return fmt.Errorf("creating dashboard handler: %w", err)
+		dashboard, dashErr := web.NewDashboardMux(fetcher)
+		if dashErr != nil {
+			return fmt.Errorf("creating dashboard handler: %w", dashErr)
 		}
+
+		// Inbound webhooks share the port: see gt inbound
+		mux := http.NewServeMux()
+		mux.Handle(inbound.PathPrefix, inbound.NewHandler(townRoot, log.Printf))
+		mux.Handle("/", dashboard)
+		handler = mux
 	}
 
 	// Build the URL
@@ -17,11 +17,13 @@ var dndCmd = &cobra.Command{
 	Long: `Control notification level for the current agent.
 
 Do Not Disturb (DND) mode mutes non-critical notifications,
-allowing you to focus on work without interruption.
+allowing you to focus on work without interruption. Mail still arrives,
+but its notifications and any nudges are held and mailed as a periodic
+digest (see gt mail digest). Urgent mail still gets through.
 
 Subcommands:
   on      Enable DND mode (mute notifications)
-  off     Disable DND mode (resume normal notifications)
+  off     Disable DND mode (resume normal notifications, mail the digest)
   status  Show current notification level
 
 Without arguments, toggles DND mode.
@@ -92,7 +94,7 @@ func runDnd(cmd *cobra.Command, args []string) error {
 		if err := bd.UpdateAgentNotificationLevel(agentBeadID, beads.NotifyMuted); err != nil {
 			return fmt.Errorf("enabling DND: %w", err)
 		}
-		fmt.Printf("%s DND enabled - notifications muted\n", style.SuccessPrefix)
+		fmt.Printf("%s DND enabled - notifications muted and batched into a digest\n", style.SuccessPrefix)
 		fmt.Printf("  Run %s to resume notifications\n", style.Bold.Render("gt dnd off"))
 
 	case "off":
@@ -100,6 +102,7 @@ func runDnd(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("disabling DND: %w", err)
 		}
 		fmt.Printf("%s DND disabled - notifications resumed\n", style.SuccessPrefix)
+		printFlushedDigest(townRoot)
 
 	case "status":
 		levelDisplay := currentLevel
@@ -127,3 +130,15 @@ func runDnd(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// printFlushedDigest mails the current agent the notifications held while
+// they were muted.
+func printFlushedDigest(townRoot string) {
+	sent, err := flushDigest(townRoot, detectSender())
+	switch {
+	case err != nil:
+		style.PrintWarning("held notifications not mailed, the daemon will retry: %v", err)
+	case sent != nil:
+		fmt.Printf("  Mailed a digest of %d held notification(s): %s\n", len(sent.Items), style.Bold.Render("gt mail inbox"))
+	}
+}
@@ -172,6 +172,7 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	d.Register(doctor.NewLinkedPaneCheck())
 	d.Register(doctor.NewThemeCheck())
 	d.Register(doctor.NewCrashReportCheck())
+	d.Register(doctor.NewAuditKeyCheck())
 	d.Register(doctor.NewEnvVarsCheck())
 
 	// Patrol system checks
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/eventstore"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Events command flags
+var (
+	eventsQueryTypes    []string
+	eventsQueryActors   []string
+	eventsQuerySince    string
+	eventsQueryUntil    string
+	eventsQueryArchived bool
+	eventsQueryLimit    int
+	eventsQueryJSON     bool
+
+	eventsSegmentsJSON bool
+)
+
+var eventsCmd = &cobra.Command{
+	Use:     "events",
+	GroupID: GroupDiag,
+	Short:   "Query the town event log",
+	RunE:    requireSubcommand,
+	Long: `Query the town event log across its segments.
+
+New events are appended to .events.jsonl. KRC pruning (hourly in the daemon,
+or gt krc prune) seals events from before yesterday (UTC) into one file per
+day under .events/, each with an index of its time range and of the lines
+carrying every event type and actor. Expired events move to gzip archives
+in .events/archive/ instead of being deleted.
+
+Queries skip segments outside the time range and read only the lines the
+index says can match.
+
+Commands:
+  gt events query       Find events by type, actor and time
+  gt events segments    List sealed and archived segments`,
+}
+
+var eventsQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Find events by type, actor and time",
+	Long: `Find events by type, actor and time, oldest first.
+
+--type and --actor take globs ("*" matches anything, including "/") and can
+be repeated. --since and --until take a duration back from now (30m, 24h,
+7d), a date (2026-01-14) or an RFC3339 time.
+
+Examples:
+  gt events query --type 'merge_*' --since 24h
+  gt events query --actor 'gastown/polecats/*' --type done --json
+  gt events query --type session_death --since 2026-01-01 --until 2026-01-14 --archived
+  gt events query --since 1h --limit 20`,
+	Args: cobra.NoArgs,
+	RunE: runEventsQuery,
+}
+
+var eventsSegmentsCmd = &cobra.Command{
+	Use:   "segments",
+	Short: "List sealed and archived segments",
+	Args:  cobra.NoArgs,
+	RunE:  runEventsSegments,
+}
+
+func init() {
+	eventsQueryCmd.Flags().StringArrayVar(&eventsQueryTypes, "type", nil, "Event type glob, can be repeated")
+	eventsQueryCmd.Flags().StringArrayVar(&eventsQueryActors, "actor", nil, "Actor glob, can be repeated")
+	eventsQueryCmd.Flags().StringVar(&eventsQuerySince, "since", "", "Start: duration ago (24h, 7d), date or RFC3339 time")
+	eventsQueryCmd.Flags().StringVar(&eventsQueryUntil, "until", "", "End: duration ago, date or RFC3339 time")
+	eventsQueryCmd.Flags().BoolVar(&eventsQueryArchived, "archived", false, "Also search archived (expired) events")
+	eventsQueryCmd.Flags().IntVar(&eventsQueryLimit, "limit", 0, "Show only the newest N matches")
+	eventsQueryCmd.Flags().BoolVar(&eventsQueryJSON, "json", false, "Output as JSON")
+
+	eventsSegmentsCmd.Flags().BoolVar(&eventsSegmentsJSON, "json", false, "Output as JSON")
+
+	eventsCmd.AddCommand(eventsQueryCmd)
+	eventsCmd.AddCommand(eventsSegmentsCmd)
+	rootCmd.AddCommand(eventsCmd)
+}
+
+// parseEventTime parses a --since/--until value: a duration back from now,
+// a date, or an RFC3339 time.
+func parseEventTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want a duration like 24h or 7d, a date, or RFC3339)", s)
+}
+
+func runEventsQuery(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	now := time.Now()
+	q := eventstore.Query{
+		Types:    eventsQueryTypes,
+		Actors:   eventsQueryActors,
+		Archived: eventsQueryArchived,
+	}
+	if q.Since, err = parseEventTime(eventsQuerySince, now); err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	if q.Until, err = parseEventTime(eventsQueryUntil, now); err != nil {
+		return fmt.Errorf("--until: %w", err)
+	}
+
+	found := []*events.Event{}
+	err = eventstore.Scan(townRoot, q, func(e *events.Event) bool {
+		found = append(found, e)
+		if eventsQueryLimit > 0 && len(found) > eventsQueryLimit {
+			found = found[1:]
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if eventsQueryJSON {
+		return outputJSON(found)
+	}
+	if len(found) == 0 {
+		fmt.Println("No matching events.")
+		return nil
+	}
+	for _, e := range found {
+		ts := e.Timestamp
+		if t, err := time.Parse(time.RFC3339, e.Timestamp); err == nil {
+			ts = t.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s  %-18s %-32s %s\n", style.Dim.Render(ts), e.Type, e.Actor, style.Dim.Render(formatEventPayload(e.Payload)))
+	}
+	return nil
+}
+
+// formatEventPayload renders a payload as sorted key=value pairs.
+func formatEventPayload(payload map[string]interface{}) string {
+	if len(payload) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(payload))
+	for k := range payload {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, payload[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func runEventsSegments(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	segments, err := eventstore.Segments(townRoot)
+	if err != nil {
+		return err
+	}
+	if eventsSegmentsJSON {
+		if segments == nil {
+			segments = []eventstore.Segment{}
+		}
+		return outputJSON(segments)
+	}
+	if len(segments) == 0 {
+		fmt.Println("No sealed segments yet. Events before yesterday are sealed by gt krc prune.")
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "DAY", Width: 12},
+		style.Column{Name: "STATE", Width: 9},
+		style.Column{Name: "EVENTS", Width: 8, Align: style.AlignRight},
+		style.Column{Name: "SIZE", Width: 10, Align: style.AlignRight},
+		style.Column{Name: "TYPES", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "ACTORS", Width: 7, Align: style.AlignRight},
+	)
+	for _, seg := range segments {
+		state := "sealed"
+		if seg.Archived {
+			state = style.Dim.Render("archived")
+		}
+		table.AddRow(seg.Day, state, fmt.Sprintf("%d", seg.Events), formatBytes(seg.Bytes),
+			fmt.Sprintf("%d", seg.Types), fmt.Sprintf("%d", seg.Actors))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Formula simulate flags
+var (
+	formulaSimVars            []string
+	formulaSimEstimates       []string
+	formulaSimDefaultEstimate time.Duration
+	formulaSimExport          string
+	formulaSimJSON            bool
+)
+
+var formulaSimulateCmd = &cobra.Command{
+	Use:   "simulate <name|path>",
+	Short: "Dry-run a formula: waves, critical path and warnings",
+	Long: `Simulate how a formula will unfold before slinging it to real agents.
+
+Nothing is poured or dispatched. The simulator:
+  - expands {{variables}} from defaults and --var values
+  - groups steps into the waves they would be dispatched in (parallel
+    steps together, sequential steps one at a time)
+  - computes the critical path and run time from per-step estimates
+  - warns about unknown, missing, unused and undeclared variables,
+    unreachable steps, serialized independent steps, unjoined end steps
+    and convoy legs that are never synthesized
+
+Steps may declare an estimate in the formula (estimate = "45m"); override
+or supply them with --estimate. Steps without one take --default-estimate.
+
+Use --export mermaid or --export dot to print the DAG for design reviews,
+with the critical path highlighted.
+
+The formula is looked up like 'gt formula run' (project, town, user), then
+among the built-in formulas; a path to a .formula.toml file also works.
+
+Examples:
+  gt formula simulate shiny --var feature="user login"
+  gt formula simulate code-review --estimate synthesis=1h
+  gt formula simulate ./my.formula.toml --default-estimate 1h
+  gt formula simulate shiny --export mermaid > shiny.mmd
+  gt formula simulate code-review --export dot | dot -Tsvg > review.svg`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaSimulate,
+}
+
+func init() {
+	formulaSimulateCmd.Flags().StringArrayVar(&formulaSimVars, "var", nil, "Formula variable (key=value), can be repeated")
+	formulaSimulateCmd.Flags().StringArrayVar(&formulaSimEstimates, "estimate", nil, "Step estimate (step=duration, e.g. design=2h), can be repeated")
+	formulaSimulateCmd.Flags().DurationVar(&formulaSimDefaultEstimate, "default-estimate", formula.DefaultEstimate, "Estimate for steps without one")
+	formulaSimulateCmd.Flags().StringVar(&formulaSimExport, "export", "", "Print the DAG instead: mermaid or dot")
+	formulaSimulateCmd.Flags().BoolVar(&formulaSimJSON, "json", false, "Output as JSON")
+
+	formulaCmd.AddCommand(formulaSimulateCmd)
+}
+
+func runFormulaSimulate(cmd *cobra.Command, args []string) error {
+	f, err := loadFormulaForSimulation(args[0])
+	if err != nil {
+		return err
+	}
+
+	opts := formula.SimulateOptions{
+		Vars:            make(map[string]string),
+		Estimates:       make(map[string]time.Duration),
+		DefaultEstimate: formulaSimDefaultEstimate,
+	}
+	for _, v := range formulaSimVars {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --var %q (want key=value)", v)
+		}
+		opts.Vars[key] = value
+	}
+	for _, e := range formulaSimEstimates {
+		id, raw, ok := strings.Cut(e, "=")
+		d, err := time.ParseDuration(raw)
+		if !ok || id == "" || err != nil || d <= 0 {
+			return fmt.Errorf("invalid --estimate %q (want step=duration, e.g. design=2h)", e)
+		}
+		opts.Estimates[id] = d
+	}
+
+	sim := f.Simulate(opts)
+
+	switch formulaSimExport {
+	case "":
+	case "mermaid":
+		fmt.Print(sim.Mermaid())
+		return nil
+	case "dot":
+		fmt.Print(sim.DOT())
+		return nil
+	default:
+		return fmt.Errorf("unknown --export format %q (want mermaid or dot)", formulaSimExport)
+	}
+
+	if formulaSimJSON {
+		return outputJSON(sim)
+	}
+	printSimulation(sim)
+	return nil
+}
+
+// loadFormulaForSimulation parses a formula from a path, the formula search
+// paths, or the built-in formulas, in that order.
+func loadFormulaForSimulation(nameOrPath string) (*formula.Formula, error) {
+	if strings.HasSuffix(nameOrPath, ".toml") {
+		if _, err := os.Stat(nameOrPath); err == nil {
+			return formula.ParseFile(nameOrPath)
+		}
+	}
+	if path, err := findFormulaFile(nameOrPath); err == nil {
+		f, err := formula.ParseFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return f, nil
+	}
+	data, err := formula.Embedded(nameOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("formula '%s' not found in search paths or built-in formulas", nameOrPath)
+	}
+	return formula.Parse(data)
+}
+
+func printSimulation(sim *formula.Simulation) {
+	fmt.Printf("%s %s %s\n", style.Bold.Render("Formula:"), sim.Formula,
+		style.Dim.Render(fmt.Sprintf("(%s, %d steps)", sim.Type, len(sim.Steps))))
+	if len(sim.Vars) > 0 {
+		var pairs []string
+		for _, k := range sortedVarNames(sim.Vars) {
+			pairs = append(pairs, k+"="+sim.Vars[k])
+		}
+		fmt.Printf("%s %s\n", style.Bold.Render("Vars:   "), strings.Join(pairs, " "))
+	}
+
+	byID := make(map[string]formula.SimStep, len(sim.Steps))
+	idWidth := 0
+	for _, s := range sim.Steps {
+		byID[s.ID] = s
+		if len(s.ID) > idWidth {
+			idWidth = len(s.ID)
+		}
+	}
+
+	maxParallel := 0
+	for i, w := range sim.Waves {
+		mode := "sequential"
+		if w.Parallel {
+			mode = fmt.Sprintf("%d in parallel", len(w.Steps))
+		}
+		if len(w.Steps) > maxParallel {
+			maxParallel = len(w.Steps)
+		}
+		fmt.Printf("\n%s %s\n", style.Bold.Render(fmt.Sprintf("Wave %d", i+1)),
+			style.Dim.Render(fmt.Sprintf("+%s, %s, %s", formula.FormatEstimate(byID[w.Steps[0]].Start), formula.FormatEstimate(w.Duration), mode)))
+		for _, id := range w.Steps {
+			s := byID[id]
+			marker := " "
+			if s.Critical {
+				marker = style.Warning.Render("★")
+			}
+			fmt.Printf("  %s %-*s  %6s  %s\n", marker, idWidth, s.ID, formula.FormatEstimate(s.Estimate), s.Title)
+		}
+	}
+
+	fmt.Println()
+	if len(sim.CriticalPath) > 0 {
+		fmt.Printf("%s %s %s\n", style.Bold.Render("Critical path:"), strings.Join(sim.CriticalPath, " → "),
+			style.Dim.Render("("+formula.FormatEstimate(sim.CriticalPathLength)+")"))
+	}
+	fmt.Printf("%s %s %s\n", style.Bold.Render("Run time:     "), formula.FormatEstimate(sim.Makespan),
+		style.Dim.Render("(waves dispatched in turn)"))
+	fmt.Printf("%s %s %s\n", style.Bold.Render("Agent time:   "), formula.FormatEstimate(sim.TotalWork),
+		style.Dim.Render(fmt.Sprintf("(up to %d agents at once)", maxParallel)))
+
+	if len(sim.Warnings) > 0 {
+		fmt.Printf("\n%s\n", style.Warning.Render(fmt.Sprintf("⚠ %d warning(s)", len(sim.Warnings))))
+		for _, w := range sim.Warnings {
+			fmt.Printf("  - %s\n", w)
+		}
+	}
+}
+
+func sortedVarNames(vars map[string]string) []string {
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
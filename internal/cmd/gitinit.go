@@ -66,6 +66,7 @@ const HQGitignore = `# Gas Town HQ .gitignore
 **/heartbeat.json
 **/activity.json
 .events.jsonl
+.events/
 .feed.jsonl
 
 # =============================================================================
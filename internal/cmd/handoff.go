@@ -225,14 +225,42 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 		if _, err := os.Stat(paneWorkDir); err != nil {
 			if townRoot := detectTownRootFromCwd(); townRoot != "" {
 				style.PrintWarning("pane working directory deleted, using town root")
-				return t.RespawnPaneWithWorkDir(pane, townRoot, restartCmd)
+				return respawnAgentPane(t, pane, currentSession, townRoot, restartCmd)
 			}
 		}
 	}
 
 	// Use respawn-pane -k to atomically kill current process and start new one
 	// Note: respawn-pane automatically resets remain-on-exit to off
-	return t.RespawnPane(pane, restartCmd)
+	return respawnAgentPane(t, pane, currentSession, "", restartCmd)
+}
+
+// respawnAgentPane respawns pane with a restart command from
+// buildRestartCommand, exporting the env secrets of sessionName's agent the
+// way a fresh session start does. workDir, if set, replaces the pane's
+// starting directory. The env file is removed if the respawn fails.
+func respawnAgentPane(t *tmux.Tmux, pane, sessionName, workDir, restartCmd string) error {
+	restartCmd, discardSecrets := restartWithSecrets(detectTownRootFromCwd(), sessionName, restartCmd)
+	var err error
+	if workDir != "" {
+		err = t.RespawnPaneWithWorkDir(pane, workDir, restartCmd)
+	} else {
+		err = t.RespawnPane(pane, restartCmd)
+	}
+	if err != nil {
+		discardSecrets()
+	}
+	return err
+}
+
+// restartWithSecrets wraps a restart command with the env secrets of
+// sessionName's agent (see withSessionSecrets).
+func restartWithSecrets(townRoot, sessionName, restartCmd string) (string, func()) {
+	identity, err := session.ParseSessionName(sessionName)
+	if err != nil || townRoot == "" {
+		return restartCmd, func() {}
+	}
+	return withSessionSecrets(townRoot, identity.Rig, string(identity.Role), identity.Address(), restartCmd)
 }
 
 // getCurrentTmuxSession returns the current tmux session name.
@@ -616,11 +644,11 @@ func handoffRemoteSession(t *tmux.Tmux, targetSession, restartCmd string) error
 			if _, statErr := os.Stat(paneWorkDir); statErr != nil {
 				if townRoot := detectTownRootFromCwd(); townRoot != "" {
 					style.PrintWarning("pane working directory deleted, using town root")
-					return t.RespawnPaneWithWorkDir(targetPane, townRoot, restartCmd)
+					return respawnAgentPane(t, targetPane, targetSession, townRoot, restartCmd)
 				}
 			}
 		}
-		return t.RespawnPane(targetPane, restartCmd)
+		return respawnAgentPane(t, targetPane, targetSession, "", restartCmd)
 	}()
 	if respawnErr != nil {
 		return fmt.Errorf("respawning pane: %w", respawnErr)
@@ -3,8 +3,11 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/steveyegge/gastown/internal/secrets"
+	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -177,3 +180,59 @@ func TestDetectTownRootFromCwd_EnvFallback(t *testing.T) {
 		}
 	})
 }
+
+func TestRestartWithSecrets(t *testing.T) {
+	townRoot := t.TempDir()
+	err := secrets.Update(townRoot, func(s *secrets.Store) error {
+		_, err := s.Set("TOKEN", "gastown", "crew", "", []byte("s3cret"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	envDir := filepath.Join(secrets.Dir(townRoot), "sessions")
+
+	// Roles without secrets restart unchanged
+	if cmd, _ := restartWithSecrets(townRoot, "gt-gastown-witness", "exec claude"); cmd != "exec claude" {
+		t.Errorf("witness restart = %q, want unchanged", cmd)
+	}
+
+	cmd, discard := restartWithSecrets(townRoot, "gt-gastown-crew-holden", "exec claude")
+	if !strings.HasSuffix(cmd, "&& exec claude") || strings.Contains(cmd, "s3cret") {
+		t.Errorf("crew restart = %q, want env file sourced before the agent", cmd)
+	}
+	entries, _ := os.ReadDir(envDir)
+	if len(entries) != 1 {
+		t.Fatalf("env files = %d, want 1", len(entries))
+	}
+	discard()
+	if entries, _ := os.ReadDir(envDir); len(entries) != 0 {
+		t.Errorf("env file left behind after discard: %v", entries)
+	}
+}
+
+func TestRespawnAgentPaneFailureRemovesEnvFile(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "town.json"), []byte(`{"type":"town"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := secrets.Update(townRoot, func(s *secrets.Store) error {
+		_, err := s.Set("TOKEN", "gastown", "crew", "", []byte("s3cret"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	t.Chdir(townRoot)
+
+	// No such pane: the respawn fails and must not leave secrets on disk
+	if err := respawnAgentPane(tmux.NewTmux(), "%no-such-pane", "gt-gastown-crew-holden", "", "exec true"); err == nil {
+		t.Fatal("expected respawn of a missing pane to fail")
+	}
+	if entries, _ := os.ReadDir(filepath.Join(secrets.Dir(townRoot), "sessions")); len(entries) != 0 {
+		t.Errorf("env file left behind after failed respawn: %v", entries)
+	}
+}
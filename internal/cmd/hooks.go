@@ -8,17 +8,23 @@ var hooksCmd = &cobra.Command{
 	Use:     "hooks",
 	GroupID: GroupConfig,
 	Short:   "Centralized hook management for Gas Town",
-	Long: `Manage Claude Code hooks across the Gas Town workspace.
+	Long: `Manage agent hooks across the Gas Town workspace.
 
 Provides centralized hook configuration with a base config and
 per-role/per-rig overrides. Changes are propagated to all workers
 via the sync command.
 
+Hooks are written as Claude Code settings.json and translated for each
+target's agent runtime: Gemini CLI settings, Cursor hooks.json, an
+OpenCode plugin, or AGENTS.md instructions that run 'gt hooks run' for
+runtimes (or events) without native hooks.
+
 Subcommands:
   base       Edit the shared base hook config
   override   Edit overrides for a role or rig
-  sync       Regenerate all .claude/settings.json files
+  sync       Regenerate all hook files
   diff       Show what sync would change
+  run        Run the hooks for an event (for runtimes without hooks)
   list       Show all managed settings.json locations
   scan       Scan workspace for existing hooks
   registry   List hooks from the registry
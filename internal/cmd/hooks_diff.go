@@ -16,9 +16,9 @@ var hooksDiffCmd = &cobra.Command{
 	Short: "Show what sync would change",
 	Long: `Show what 'gt hooks sync' would change without applying.
 
-Compares the current .claude/settings.json files against what would
-be generated from base + overrides. Uses color to highlight additions
-and removals.
+Compares the current .claude/settings.json files, and the hook files of
+each target's non-Claude agent runtime, against what would be generated
+from base + overrides. Uses color to highlight additions and removals.
 
 Exit codes:
   0 - No changes pending
@@ -26,12 +26,16 @@ Exit codes:
 
 Examples:
   gt hooks diff                    # Show all pending changes
-  gt hooks diff gastown/crew       # Show changes for specific target`,
+  gt hooks diff gastown/crew       # Show changes for specific target
+  gt hooks diff --agent gemini     # Include Gemini CLI hook files`,
 	RunE: runHooksDiff,
 }
 
+var hooksDiffAgents []string
+
 func init() {
 	hooksCmd.AddCommand(hooksDiffCmd)
+	hooksDiffCmd.Flags().StringSliceVar(&hooksDiffAgents, "agent", nil, "Also diff hooks for these agent presets in every target ("+sortedAgentPresets()+")")
 }
 
 // diffStyles for colored diff output.
@@ -79,6 +83,15 @@ func runHooksDiff(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("loading current settings for %s: %w", target.DisplayKey(), err)
 		}
 
+		runtimeChanges, err := providerChanges(townRoot, target, hooksDiffAgents)
+		if err != nil {
+			return fmt.Errorf("%s: %w", target.DisplayKey(), err)
+		}
+		for _, c := range runtimeChanges {
+			hasChanges = true
+			printFileDiff(townRoot, c)
+		}
+
 		if hooks.HooksEqual(expected, &current.Hooks) {
 			continue
 		}
@@ -111,6 +124,34 @@ func runHooksDiff(cmd *cobra.Command, args []string) error {
 	return NewSilentExit(1)
 }
 
+// printFileDiff prints a pending change to a runtime hook file.
+func printFileDiff(townRoot string, c hooks.Change) {
+	relPath, err := filepath.Rel(townRoot, c.Path)
+	if err != nil {
+		relPath = c.Path
+	}
+	switch {
+	case c.Remove:
+		fmt.Printf("%s: %s\n", style.Bold.Render(relPath), diffRemove.Render("(would be removed)"))
+	case !c.Exists:
+		fmt.Printf("%s: %s\n", style.Bold.Render(relPath), diffAdd.Render("(would be created)"))
+	default:
+		fmt.Printf("%s:\n", style.Bold.Render(relPath))
+	}
+	var expected []byte
+	if !c.Remove {
+		expected = c.Content
+	}
+	removed, added := diffLines(c.Current, expected)
+	for _, line := range removed {
+		fmt.Printf("    %s\n", diffRemove.Render("- "+truncateCommand(line)))
+	}
+	for _, line := range added {
+		fmt.Printf("    %s\n", diffAdd.Render("+ "+truncateCommand(line)))
+	}
+	fmt.Println()
+}
+
 // diffHooksConfigs compares current and expected configs, returning formatted diff lines.
 func diffHooksConfigs(current, expected *hooks.HooksConfig) []string {
 	var lines []string
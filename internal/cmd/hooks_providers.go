@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/hooks"
+)
+
+// runtimeHooks is a hook provider and the instructions file it falls back to.
+type runtimeHooks struct {
+	Agent        string
+	Provider     hooks.Provider
+	Instructions string
+}
+
+// targetRuntimeHooks returns the non-Claude hook providers to render for a
+// target: its role's configured agent, plus any agents named with --agent.
+// Claude's settings.json is synced for every target regardless.
+func targetRuntimeHooks(townRoot string, target hooks.Target, extraAgents []string) ([]runtimeHooks, error) {
+	rigPath := ""
+	if target.Rig != "" {
+		rigPath = filepath.Join(townRoot, target.Rig)
+	}
+
+	var rcs []*config.RuntimeConfig
+	var names []string
+	switch target.Role {
+	case "rig":
+		rcs = append(rcs, config.ResolveAgentConfig(townRoot, rigPath))
+	default:
+		role := target.Role
+		if role == "polecats" {
+			role = "polecat"
+		}
+		rcs = append(rcs, config.ResolveRoleAgentConfig(role, townRoot, rigPath))
+	}
+	names = append(names, rcs[0].Provider)
+	for _, agent := range extraAgents {
+		if config.GetAgentPresetByName(agent) == nil {
+			return nil, fmt.Errorf("unknown agent %q (presets: %s)", agent, strings.Join(config.ListAgentPresets(), ", "))
+		}
+		rcs = append(rcs, config.RuntimeConfigFromPreset(config.AgentPreset(agent)))
+		names = append(names, agent)
+	}
+
+	var out []runtimeHooks
+	seen := make(map[string]bool)
+	for i, rc := range rcs {
+		if rc.Hooks == nil || rc.Hooks.Provider == "claude" || seen[rc.Hooks.Provider] {
+			continue
+		}
+		p := hooks.GetProvider(rc.Hooks.Provider)
+		if p == nil {
+			continue
+		}
+		seen[rc.Hooks.Provider] = true
+		instructions := ""
+		if rc.Instructions != nil {
+			instructions = rc.Instructions.File
+		}
+		out = append(out, runtimeHooks{Agent: names[i], Provider: p, Instructions: instructions})
+	}
+	return out, nil
+}
+
+// providerChanges renders a target's non-Claude hook files and returns the
+// ones that differ from disk.
+func providerChanges(townRoot string, target hooks.Target, extraAgents []string) ([]hooks.Change, error) {
+	runtimes, err := targetRuntimeHooks(townRoot, target, extraAgents)
+	if err != nil || len(runtimes) == 0 {
+		return nil, err
+	}
+	expected, err := hooks.ComputeExpected(target.Key)
+	if err != nil {
+		return nil, fmt.Errorf("computing expected config: %w", err)
+	}
+	var files []hooks.File
+	for _, rt := range runtimes {
+		rendered, err := hooks.RenderFor(rt.Provider, target.WorkDir(), rt.Instructions, expected)
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s hooks: %w", rt.Agent, err)
+		}
+		files = append(files, rendered...)
+	}
+	return hooks.Pending(files)
+}
+
+// diffLines returns the non-blank lines only in current and only in
+// expected, each in file order.
+func diffLines(current, expected []byte) (removed, added []string) {
+	count := func(data []byte) map[string]int {
+		m := make(map[string]int)
+		for _, line := range strings.Split(string(data), "\n") {
+			m[line]++
+		}
+		return m
+	}
+	inCurrent, inExpected := count(current), count(expected)
+	for _, line := range strings.Split(string(current), "\n") {
+		if inExpected[line] > 0 {
+			inExpected[line]--
+		} else if strings.TrimSpace(line) != "" {
+			removed = append(removed, line)
+		}
+	}
+	for _, line := range strings.Split(string(expected), "\n") {
+		if inCurrent[line] > 0 {
+			inCurrent[line]--
+		} else if strings.TrimSpace(line) != "" {
+			added = append(added, line)
+		}
+	}
+	return removed, added
+}
+
+// sortedAgentPresets lists the built-in agent presets for help text.
+func sortedAgentPresets() string {
+	names := config.ListAgentPresets()
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
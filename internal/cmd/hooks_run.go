@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/hooks"
+)
+
+var hooksRunTarget string
+
+var hooksRunCmd = &cobra.Command{
+	Use:   "run <event> [tool]",
+	Short: "Run the hooks for an event (shim for runtimes without hooks)",
+	Long: `Run the configured hook commands for an event, as a runtime with native
+hooks would.
+
+Runtimes that cannot run some of Gas Town's hooks themselves (Codex, Amp,
+Auggie, and parts of Cursor and OpenCode) get instructions in their
+AGENTS.md telling the agent to run this command at the right moments.
+gt hooks sync writes those instructions.
+
+Events:
+  session-start   SessionStart
+  prompt-submit   UserPromptSubmit
+  pre-tool        PreToolUse (give the tool name)
+  post-tool       PostToolUse (give the tool name)
+  stop            Stop
+  compact         PreCompact
+
+The target defaults to the one for GT_ROLE. A failing pre-tool hook stops
+the remaining hooks and exits with its status, meaning the tool should not
+be used.
+
+Examples:
+  gt hooks run session-start
+  gt hooks run pre-tool Bash
+  gt hooks run stop --target gastown/crew`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runHooksRun,
+}
+
+func init() {
+	hooksCmd.AddCommand(hooksRunCmd)
+	hooksRunCmd.Flags().StringVar(&hooksRunTarget, "target", "", "Override target (default: from GT_ROLE)")
+}
+
+func runHooksRun(cmd *cobra.Command, args []string) error {
+	eventType, ok := hooks.EventForShim(args[0])
+	if !ok {
+		var names []string
+		for _, s := range hooks.ShimEvents {
+			names = append(names, s.Name)
+		}
+		return fmt.Errorf("unknown event %q (valid: %s)", args[0], strings.Join(names, ", "))
+	}
+	tool := ""
+	if len(args) > 1 {
+		tool = args[1]
+	}
+
+	target := hooksRunTarget
+	if target == "" {
+		role := os.Getenv("GT_ROLE")
+		if role == "" {
+			return fmt.Errorf("GT_ROLE not set; pass --target")
+		}
+		target = hooks.TargetForRole(role, "")
+	}
+
+	expected, err := hooks.ComputeExpected(target)
+	if err != nil {
+		return fmt.Errorf("computing hooks for %s: %w", target, err)
+	}
+
+	// Hook failures are reported by exit status, which agents read; keep
+	// cobra's error and usage text out of their context.
+	cmd.SilenceErrors, cmd.SilenceUsage = true, true
+
+	failed := 0
+	for _, command := range hooks.CommandsFor(expected, eventType, tool) {
+		c := exec.Command("sh", "-c", command)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		err := c.Run()
+		if err == nil {
+			continue
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			fmt.Fprintf(os.Stderr, "running %q: %v\n", command, err)
+			return NewSilentExit(1)
+		}
+		if eventType == "PreToolUse" {
+			return NewSilentExit(exitErr.ExitCode())
+		}
+		fmt.Fprintf(os.Stderr, "hook failed (exit %d): %s\n", exitErr.ExitCode(), command)
+		failed++
+	}
+	if failed > 0 {
+		return NewSilentExit(1)
+	}
+	return nil
+}
@@ -11,11 +11,14 @@ import (
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
-var hooksSyncDryRun bool
+var (
+	hooksSyncDryRun bool
+	hooksSyncAgents []string
+)
 
 var hooksSyncCmd = &cobra.Command{
 	Use:   "sync",
-	Short: "Regenerate all .claude/settings.json files",
+	Short: "Regenerate all hook files from the base config and overrides",
 	Long: `Regenerate all .claude/settings.json files from the base config and overrides.
 
 For each target (mayor, deacon, rig/crew, rig/witness, etc.):
@@ -24,16 +27,21 @@ For each target (mayor, deacon, rig/crew, rig/witness, etc.):
 3. Apply rig+role override (if exists)
 4. Merge hooks section into existing settings.json (preserving all fields)
 5. Write updated settings.json
+6. Render the same hooks for the target's agent runtime, if it is not
+   Claude (.gemini/settings.json, .cursor/hooks.json, the OpenCode
+   plugin, or a managed block in AGENTS.md for runtimes without hooks)
 
 Examples:
-  gt hooks sync             # Regenerate all settings.json files
-  gt hooks sync --dry-run   # Show what would change without writing`,
+  gt hooks sync                # Regenerate all settings.json files
+  gt hooks sync --dry-run      # Show what would change without writing
+  gt hooks sync --agent codex  # Also render hooks for Codex everywhere`,
 	RunE: runHooksSync,
 }
 
 func init() {
 	hooksCmd.AddCommand(hooksSyncCmd)
 	hooksSyncCmd.Flags().BoolVar(&hooksSyncDryRun, "dry-run", false, "Show what would change without writing")
+	hooksSyncCmd.Flags().StringSliceVar(&hooksSyncAgents, "agent", nil, "Also render hooks for these agent presets in every target ("+sortedAgentPresets()+")")
 }
 
 func runHooksSync(cmd *cobra.Command, args []string) error {
@@ -58,6 +66,7 @@ func runHooksSync(cmd *cobra.Command, args []string) error {
 	unchanged := 0
 	created := 0
 	errors := 0
+	runtimeFiles := 0
 
 	for _, target := range targets {
 		result, err := syncTarget(target, hooksSyncDryRun)
@@ -91,6 +100,38 @@ func runHooksSync(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  %s %s %s\n", style.Dim.Render("·"), relPath, style.Dim.Render("(unchanged)"))
 			unchanged++
 		}
+
+		changes, err := providerChanges(townRoot, target, hooksSyncAgents)
+		if err != nil {
+			fmt.Printf("  %s %s: %v\n", style.Error.Render("✖"), target.DisplayKey(), err)
+			errors++
+			continue
+		}
+		if !hooksSyncDryRun {
+			if err := hooks.Apply(changes); err != nil {
+				fmt.Printf("  %s %s: %v\n", style.Error.Render("✖"), target.DisplayKey(), err)
+				errors++
+				continue
+			}
+		}
+		for _, c := range changes {
+			relPath, pathErr := filepath.Rel(townRoot, c.Path)
+			if pathErr != nil {
+				relPath = c.Path
+			}
+			action := "updated"
+			if c.Remove {
+				action = "removed"
+			} else if !c.Exists {
+				action = "created"
+			}
+			runtimeFiles++
+			if hooksSyncDryRun {
+				fmt.Printf("  %s %s %s\n", style.Warning.Render("~"), relPath, style.Dim.Render("(would be "+action+")"))
+			} else {
+				fmt.Printf("  %s %s %s\n", style.Success.Render("✓"), relPath, style.Dim.Render("("+action+")"))
+			}
+		}
 	}
 
 	// Summary
@@ -103,6 +144,9 @@ func runHooksSync(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Synced %d targets (%d created, %d updated, %d unchanged",
 			total, created, updated, unchanged)
 	}
+	if runtimeFiles > 0 {
+		fmt.Printf(", %d runtime hook files", runtimeFiles)
+	}
 	if errors > 0 {
 		fmt.Printf(", %s", style.Error.Render(fmt.Sprintf("%d errors", errors)))
 	}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/inbound"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Inbound command flags
+var (
+	inboundServePort int
+
+	inboundPayload string
+	inboundHeaders []string
+	inboundRun     bool
+	inboundJSON    bool
+)
+
+var inboundCmd = &cobra.Command{
+	Use:     "inbound",
+	GroupID: GroupConfig,
+	Short:   "Receive webhooks that close gates, create beads and send mail",
+	RunE:    requireSubcommand,
+	Long: `Receive webhooks from CI, review tools or forms and act on them.
+
+Requests are POSTed to /hooks/<source>, served by gt dashboard or by
+gt inbound serve. Rules under "inbound" in the town settings/config.json map
+payloads to actions:
+
+  {"inbound": {
+    "secret": "$GT_INBOUND_SECRET",
+    "rules": [{
+      "name": "ci-green",
+      "source": "ci",
+      "match": {"status": "success"},
+      "action": "gate_close",
+      "gate": "{{.gate}}",
+      "reason": "CI passed: {{.url}}"
+    }]
+  }}
+
+Actions:
+  gate_close     bd gate close <gate> --reason <reason>, then gt gate wake
+  gate_approve   bd gate approve <gate>, then gt gate wake
+  create_bead    bd create in <rig> (town beads if empty): title, description, priority
+  sling          gt sling <formula> [target] --var k=v ...
+  mail           gt mail send <to> -s <subject> -m <body>
+
+"match" maps dotted payload paths (or "header:<Name>") to globs; every
+matching rule runs, in order. Action fields are Go templates over the JSON
+payload; one that references a missing field fails the rule.
+
+Authentication: each request carries X-Gastown-Timestamp (unix seconds) and
+X-Gastown-Signature ("sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>"),
+keyed by "secret" or by "secrets" for the source. Requests more than max_skew
+(default 5m) off the clock are rejected, and each signature is accepted once:
+a retry must be signed again. Use gt inbound sign to produce headers.
+
+Commands:
+  gt inbound serve            Run a standalone receiver
+  gt inbound test <source>    Show (or --run) what a payload would do
+  gt inbound sign <source>    Print signed headers for a payload`,
+}
+
+var inboundServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a standalone inbound webhook receiver",
+	Long: `Serve /hooks/<source> without the dashboard.
+
+Example:
+  gt inbound serve --port 8081`,
+	Args: cobra.NoArgs,
+	RunE: runInboundServe,
+}
+
+var inboundTestCmd = &cobra.Command{
+	Use:   "test <source>",
+	Short: "Show which rules a payload matches and what they would run",
+	Long: `Evaluate the rules for a source against a payload, without signing.
+
+By default nothing is executed: the matched rules and their commands are
+printed. --run executes them, as the receiver would.
+
+Examples:
+  gt inbound test ci --payload run.json
+  echo '{"status":"success","gate":"gt-abc"}' | gt inbound test ci
+  gt inbound test github --payload run.json --header X-GitHub-Event=workflow_run --run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInboundTest,
+}
+
+var inboundSignCmd = &cobra.Command{
+	Use:   "sign <source>",
+	Short: "Print signed request headers for a payload",
+	Long: `Sign a payload with the source's secret and print the headers to send.
+
+Example:
+  gt inbound sign ci --payload run.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInboundSign,
+}
+
+func init() {
+	inboundServeCmd.Flags().IntVar(&inboundServePort, "port", 8081, "HTTP port to listen on")
+
+	for _, c := range []*cobra.Command{inboundTestCmd, inboundSignCmd} {
+		c.Flags().StringVar(&inboundPayload, "payload", "-", "JSON payload file (- for stdin)")
+	}
+	inboundTestCmd.Flags().StringArrayVar(&inboundHeaders, "header", nil, "Request header for header: matches (Name=value), can be repeated")
+	inboundTestCmd.Flags().BoolVar(&inboundRun, "run", false, "Execute the matched actions")
+	inboundTestCmd.Flags().BoolVar(&inboundJSON, "json", false, "Output as JSON")
+
+	inboundCmd.AddCommand(inboundServeCmd)
+	inboundCmd.AddCommand(inboundTestCmd)
+	inboundCmd.AddCommand(inboundSignCmd)
+	rootCmd.AddCommand(inboundCmd)
+}
+
+func runInboundServe(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := inbound.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Rules) == 0 {
+		fmt.Printf("%s No inbound rules configured yet; requests will get 404 until some are added.\n", style.Warning.Render("⚠"))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(inbound.PathPrefix, inbound.NewHandler(townRoot, log.Printf))
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", inboundServePort),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      5 * time.Minute, // Actions run before the response
+		IdleTimeout:       120 * time.Second,
+	}
+	fmt.Printf("Receiving webhooks at http://localhost:%d%s<source>  •  ctrl+c to stop\n", inboundServePort, inbound.PathPrefix)
+	return server.ListenAndServe()
+}
+
+// InboundTestItem is one matched rule in gt inbound test output.
+type InboundTestItem struct {
+	Rule     string            `json:"rule"`
+	Step     *inbound.Step     `json:"step,omitempty"`
+	Commands []inbound.Command `json:"commands,omitempty"`
+	Result   *inbound.Result   `json:"result,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+func runInboundTest(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := inbound.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	source := args[0]
+	rules := inbound.Rules(cfg, source)
+	if len(rules) == 0 {
+		return fmt.Errorf("no inbound rules for source %q", source)
+	}
+
+	body, err := readInboundPayload()
+	if err != nil {
+		return err
+	}
+	var payload any
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return fmt.Errorf("payload is not JSON: %w", err)
+		}
+	}
+	header := http.Header{}
+	for _, h := range inboundHeaders {
+		name, value, ok := strings.Cut(h, "=")
+		if !ok {
+			return fmt.Errorf("invalid --header %q (want Name=value)", h)
+		}
+		header.Add(name, value)
+	}
+
+	items := []InboundTestItem{}
+	failed := false
+	for _, rule := range rules {
+		if !inbound.Matches(rule, payload, header) {
+			continue
+		}
+		item := InboundTestItem{Rule: rule.Name}
+		step, err := inbound.Plan(rule, payload)
+		if err != nil {
+			item.Error = err.Error()
+			failed = true
+		} else {
+			item.Step = step
+			item.Commands = inbound.Commands(step)
+			if inboundRun {
+				res := inbound.Execute(context.Background(), inbound.ExecRunner, townRoot, step)
+				item.Result = &res
+				failed = failed || res.Error != ""
+			}
+		}
+		items = append(items, item)
+	}
+
+	if inboundJSON {
+		if err := outputJSON(items); err != nil {
+			return err
+		}
+	} else {
+		printInboundTest(source, len(rules), items)
+	}
+	if failed {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+func printInboundTest(source string, total int, items []InboundTestItem) {
+	if len(items) == 0 {
+		fmt.Printf("%s No rule for %q matched (%d checked)\n", style.Dim.Render("○"), source, total)
+		return
+	}
+	for _, item := range items {
+		switch {
+		case item.Error != "":
+			fmt.Printf("%s %s: %s\n", style.Error.Render("✗"), style.Bold.Render(item.Rule), item.Error)
+			continue
+		case item.Result != nil && item.Result.Error != "":
+			fmt.Printf("%s %s (%s): %s\n", style.Error.Render("✗"), style.Bold.Render(item.Rule), item.Step.Action, item.Result.Error)
+		case item.Result != nil:
+			fmt.Printf("%s %s (%s)\n", style.Success.Render("✓"), style.Bold.Render(item.Rule), item.Step.Action)
+		default:
+			fmt.Printf("%s %s (%s) would run:\n", style.Bold.Render("→"), style.Bold.Render(item.Rule), item.Step.Action)
+		}
+		if item.Result == nil {
+			for _, c := range item.Commands {
+				fmt.Printf("    %s\n", c)
+			}
+		} else if item.Result.Bead != "" {
+			fmt.Printf("    created %s\n", item.Result.Bead)
+		}
+	}
+}
+
+func runInboundSign(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := inbound.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	secret := inbound.Secret(cfg, args[0])
+	if secret == "" {
+		return fmt.Errorf("no inbound secret configured for source %q", args[0])
+	}
+	body, err := readInboundPayload()
+	if err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	fmt.Printf("%s: %s\n", inbound.HeaderTimestamp, ts)
+	fmt.Printf("%s: %s\n", inbound.HeaderSignature, inbound.Sign(secret, ts, body))
+	return nil
+}
+
+// readInboundPayload reads the --payload file, or stdin for "-".
+func readInboundPayload() ([]byte, error) {
+	if inboundPayload == "-" {
+		return io.ReadAll(io.LimitReader(os.Stdin, inbound.MaxBodySize))
+	}
+	return os.ReadFile(inboundPayload)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/krc"
+	"github.com/steveyegge/gastown/internal/recording"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -54,8 +55,16 @@ var krcPruneCmd = &cobra.Command{
 	Short: "Remove expired events",
 	Long: `Prune events that have exceeded their TTL.
 
-Events are removed from both .events.jsonl and .feed.jsonl.
-The operation is atomic (uses temp files and rename).
+The event log is first sealed: events from before yesterday (UTC) move from
+.events.jsonl into indexed day segments under .events/. Expired events in
+those segments are then moved to gzip archives in .events/archive/ rather
+than deleted; gt events query --archived still finds them.
+
+Expired events are removed from .feed.jsonl, and resource samples from
+.resources.jsonl. These rewrites are atomic (temp files and rename).
+
+Session recordings (<rig>/.runtime/recordings/) older than their rig's
+recording TTL are deleted too.
 
 Use --dry-run to preview what would be pruned without making changes.`,
 	RunE: runKrcPrune,
@@ -148,7 +157,14 @@ func runKrcStats(cmd *cobra.Command, args []string) error {
 	// File stats
 	fmt.Println(style.Bold.Render("Files:"))
 	fmt.Printf("  Events: %s (%d events)\n", formatBytes(stats.EventsFile.Size), stats.EventsFile.EventCount)
+	if stats.Segments.EventCount > 0 || stats.Archive.EventCount > 0 {
+		fmt.Printf("  Sealed: %s (%d events)\n", formatBytes(stats.Segments.Size), stats.Segments.EventCount)
+		fmt.Printf("  Archived: %s gzip (%d events)\n", formatBytes(stats.Archive.Size), stats.Archive.EventCount)
+	}
 	fmt.Printf("  Feed:   %s (%d events)\n", formatBytes(stats.FeedFile.Size), stats.FeedFile.EventCount)
+	if stats.ResourcesFile.EventCount > 0 {
+		fmt.Printf("  Resources: %s (%d samples)\n", formatBytes(stats.ResourcesFile.Size), stats.ResourcesFile.EventCount)
+	}
 	fmt.Println()
 
 	// Age distribution
@@ -258,7 +274,15 @@ func runKrcPrune(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("pruning: %w", err)
 	}
 
-	if result.EventsPruned == 0 {
+	recordingsPruned, err := recording.PruneTown(townRoot)
+	if err != nil {
+		style.PrintWarning("pruning session recordings: %v", err)
+	}
+	if recordingsPruned > 0 {
+		fmt.Printf("Pruned %d expired session recording(s).\n", recordingsPruned)
+	}
+
+	if result.EventsPruned == 0 && len(result.ArchiveDeleted) == 0 {
 		fmt.Println("No expired events to prune.")
 		return nil
 	}
@@ -266,6 +290,12 @@ func runKrcPrune(cmd *cobra.Command, args []string) error {
 	fmt.Println(style.Bold.Render("Prune complete:"))
 	fmt.Printf("  Events processed: %d\n", result.EventsProcessed)
 	fmt.Printf("  Events pruned:    %d\n", result.EventsPruned)
+	if result.EventsArchived > 0 {
+		fmt.Printf("  Events archived:  %d (gzip, in .events/archive)\n", result.EventsArchived)
+	}
+	if len(result.ArchiveDeleted) > 0 {
+		fmt.Printf("  Archive deleted:  %s\n", strings.Join(result.ArchiveDeleted, ", "))
+	}
 	fmt.Printf("  Events retained:  %d\n", result.EventsRetained)
 	fmt.Printf("  Space saved:      %s\n", formatBytes(result.BytesBefore-result.BytesAfter))
 	fmt.Printf("  Duration:         %s\n", result.Duration.Round(time.Millisecond))
@@ -309,6 +339,11 @@ func runKrcConfig(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Default TTL:     %s\n", krcFormatDuration(config.DefaultTTL))
 	fmt.Printf("Prune interval:  %s\n", krcFormatDuration(config.PruneInterval))
 	fmt.Printf("Min retain:      %d events\n", config.MinRetainCount)
+	if config.ArchiveRetention > 0 {
+		fmt.Printf("Archive kept:    %s\n", krcFormatDuration(config.ArchiveRetention))
+	} else {
+		fmt.Printf("Archive kept:    forever\n")
+	}
 	fmt.Println()
 	fmt.Println(style.Bold.Render("TTLs by pattern:"))
 
@@ -8,7 +8,11 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/crash"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/townlog"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -41,6 +45,7 @@ Events logged include:
   done    - agent finished work
   crash   - agent exited unexpectedly
   kill    - agent killed intentionally
+  limit_kill - agent killed by its resource limits (e.g. out of memory)
 
 Examples:
   gt log                     # Show last 20 events
@@ -64,6 +69,10 @@ The exit code determines if this was a crash or expected exit:
   - Exit code 0: Expected exit (logged as 'done' if no other done was recorded)
   - Exit code non-zero: Crash (logged as 'crash')
 
+Crashes also capture a forensic bundle under <town>/crashes/ (scrollback,
+environment, hooked work, git status, recent events); browse them with
+'gt crash list' and 'gt crash show'.
+
 Examples:
   gt log crash --agent greenplace/Toast --session gt-greenplace-Toast --exit-code 1`,
 	RunE: runLogCrash,
@@ -71,7 +80,7 @@ Examples:
 
 func init() {
 	logCmd.Flags().IntVarP(&logTail, "tail", "n", 20, "Number of events to show")
-	logCmd.Flags().StringVarP(&logType, "type", "t", "", "Filter by event type (spawn,wake,nudge,handoff,done,crash,kill)")
+	logCmd.Flags().StringVarP(&logType, "type", "t", "", "Filter by event type (spawn,wake,nudge,handoff,done,crash,kill,limit_kill)")
 	logCmd.Flags().StringVarP(&logAgent, "agent", "a", "", "Filter by agent prefix (e.g., gastown/, greenplace/crew/max)")
 	logCmd.Flags().StringVar(&logSince, "since", "", "Show events since duration (e.g., 1h, 30m, 24h)")
 	logCmd.Flags().BoolVarP(&logFollow, "follow", "f", false, "Follow log output (like tail -f)")
@@ -199,6 +208,8 @@ func printEvent(e townlog.Event) {
 		typeStr = style.Error.Render("[crash]")
 	case townlog.EventKill:
 		typeStr = style.Warning.Render("[kill]")
+	case townlog.EventLimitKill:
+		typeStr = style.Error.Render("[limit_kill]")
 	case townlog.EventCallback:
 		typeStr = style.Bold.Render("[callback]")
 	case townlog.EventPatrolStarted:
@@ -342,6 +353,44 @@ func runLogCrash(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// A session killed by its cgroup limits (e.g. OOM) gets a distinct
+	// death reason so it isn't mistaken for an agent crash.
+	var limitReason string
+	if crashExitCode != 0 && crashSession != "" {
+		if reason := sandbox.LimitKill(townRoot, crashSession); reason != "" {
+			eventType = townlog.EventLimitKill
+			context = reason
+			limitReason = reason
+		}
+	}
+
+	// Capture forensics while the dead pane still exists; its scrollback
+	// and environment vanish with it.
+	var bundleID string
+	if eventType == townlog.EventCrash || eventType == townlog.EventLimitKill {
+		bundle, err := crash.Capture(tmux.NewTmux(), crash.Options{
+			TownRoot: townRoot,
+			Agent:    crashAgent,
+			Session:  crashSession,
+			ExitCode: crashExitCode,
+			Reason:   context,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not capture crash bundle: %v\n", err)
+		} else {
+			bundleID = bundle.ID
+			context += fmt.Sprintf(" [crash bundle: %s]", bundleID)
+		}
+	}
+
+	if limitReason != "" {
+		payload := events.SessionDeathPayload(crashSession, crashAgent, limitReason, "cgroup")
+		if bundleID != "" {
+			payload["crash_bundle"] = bundleID
+		}
+		_ = events.LogFeed(events.TypeSessionDeath, crashAgent, payload)
+	}
+
 	// Log the event
 	logger := townlog.NewLogger(townRoot)
 	if err := logger.Log(eventType, crashAgent, context); err != nil {
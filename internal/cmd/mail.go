@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
 )
 
 // Mail command flags
@@ -18,6 +21,11 @@ var (
 	mailNotify        bool
 	mailSendSelf      bool
 	mailCC            []string // CC recipients
+	mailSendAt        string
+	mailSendIn        string
+	mailSendCron      string
+	mailRequireAck    bool
+	mailAckWithin     time.Duration
 	mailInboxJSON     bool
 	mailReadJSON      bool
 	mailInboxUnread   bool
@@ -121,6 +129,18 @@ Priority levels:
 
 Use --urgent as shortcut for --priority 0.
 
+Acknowledgements:
+  --require-ack asks the recipient to confirm with gt mail ack. Mail not
+  acknowledged within --ack-within (default 30m) is re-delivered as an
+  interrupt; after another window it is escalated through the routes in
+  settings/escalation.json. Track it with gt mail outbox --pending-ack.
+
+Scheduling:
+  --at, --in and --cron store the message and let the daemon send it
+  later (see gt schedule). --at takes "15:04", "2006-01-02 15:04" or
+  RFC3339; --in takes a duration like 2h or 1d; --cron takes a five-field
+  cron expression and repeats until cancelled.
+
 Examples:
   gt mail send greenplace/Toast -s "Status check" -m "How's that bug fix going?"
   gt mail send mayor/ -s "Work complete" -m "Finished gt-abc"
@@ -130,7 +150,10 @@ Examples:
   gt mail send mayor/ -s "Re: Status" -m "Done" --reply-to msg-abc123
   gt mail send --self -s "Handoff" -m "Context for next session"
   gt mail send greenplace/Toast -s "Update" -m "Progress report" --cc overseer
-  gt mail send list:oncall -s "Alert" -m "System down"`,
+  gt mail send list:oncall -s "Alert" -m "System down"
+  gt mail send gastown/witness -s "Re-check nux" -m "Is it still stuck?" --in 2h
+  gt mail send gastown/refinery -s "Freeze merges" -m "Release in progress" --urgent --require-ack
+  gt mail send mayor/ -s "Standup" -m "Post your standup" --cron "0 9 * * 1-5"`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runMailSend,
 }
@@ -458,6 +481,11 @@ func init() {
 	mailSendCmd.Flags().BoolVar(&mailPermanent, "permanent", false, "Send as permanent (not ephemeral, synced to remote)")
 	mailSendCmd.Flags().BoolVar(&mailSendSelf, "self", false, "Send to self (auto-detect from cwd)")
 	mailSendCmd.Flags().StringArrayVar(&mailCC, "cc", nil, "CC recipients (can be used multiple times)")
+	mailSendCmd.Flags().BoolVar(&mailRequireAck, "require-ack", false, "Require the recipient to acknowledge (gt mail ack)")
+	mailSendCmd.Flags().DurationVar(&mailAckWithin, "ack-within", mail.DefaultAckWithin, "Re-deliver, then escalate, if not acknowledged within this long (implies --require-ack)")
+	mailSendCmd.Flags().StringVar(&mailSendAt, "at", "", "Send at a time instead of now (15:04, 2006-01-02 15:04, or RFC3339)")
+	mailSendCmd.Flags().StringVar(&mailSendIn, "in", "", "Send after a delay instead of now (e.g., 30m, 2h, 1d)")
+	mailSendCmd.Flags().StringVar(&mailSendCron, "cron", "", "Send on a recurring cron schedule (e.g., \"0 9 * * 1-5\")")
 	_ = mailSendCmd.MarkFlagRequired("subject") // cobra flags: error only at runtime if missing
 
 	// Inbox flags
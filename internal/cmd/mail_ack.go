@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Acknowledgement command flags
+var (
+	mailOutboxJSON       bool
+	mailOutboxPendingAck bool
+	mailCheckAcksJSON    bool
+)
+
+var mailAckCmd = &cobra.Command{
+	Use:   "ack <message-id> [message-id...]",
+	Short: "Acknowledge messages that require it",
+	Long: `Acknowledge messages sent with --require-ack.
+
+The ack marks the message read and sends the sender a receipt event.
+Unacknowledged mail is re-delivered as an interrupt, then escalated.
+
+Examples:
+  gt mail ack hq-abc123
+  gt mail ack hq-abc123 hq-def456`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMailAck,
+}
+
+var mailOutboxCmd = &cobra.Command{
+	Use:   "outbox [address]",
+	Short: "Show sent messages",
+	Long: `Show messages you sent, newest first, with their read and ack status.
+
+Use --pending-ack to see only mail sent with --require-ack that has not
+been acknowledged yet, with the next follow-up: re-delivery, then
+escalation.
+
+Examples:
+  gt mail outbox
+  gt mail outbox --pending-ack
+  gt mail outbox mayor/ --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMailOutbox,
+}
+
+var mailCheckAcksCmd = &cobra.Command{
+	Use:   "check-acks",
+	Short: "Re-deliver or escalate overdue unacknowledged mail",
+	Long: `Follow up on mail sent with --require-ack that is overdue.
+
+Mail unacknowledged for its ack window is reopened in the recipient's inbox
+and nudged; one more window without an ack escalates it through the
+routes in settings/escalation.json. Each step happens once per message,
+and the sender gets a receipt event for it.
+
+The daemon runs this on every heartbeat.`,
+	Args: cobra.NoArgs,
+	RunE: runMailCheckAcks,
+}
+
+func init() {
+	mailOutboxCmd.Flags().BoolVar(&mailOutboxPendingAck, "pending-ack", false, "Only mail still waiting for an acknowledgement")
+	mailOutboxCmd.Flags().BoolVar(&mailOutboxJSON, "json", false, "Output as JSON")
+	mailCheckAcksCmd.Flags().BoolVar(&mailCheckAcksJSON, "json", false, "Output as JSON")
+
+	mailCmd.AddCommand(mailAckCmd)
+	mailCmd.AddCommand(mailOutboxCmd)
+	mailCmd.AddCommand(mailCheckAcksCmd)
+}
+
+func runMailAck(cmd *cobra.Command, args []string) error {
+	address := detectSender()
+	mailbox, err := getMailbox(address)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, id := range args {
+		msg, err := mailbox.Ack(id)
+		switch {
+		case errors.Is(err, mail.ErrNoAckRequested), errors.Is(err, mail.ErrAlreadyAcked):
+			fmt.Printf("%s %s: %v\n", style.Dim.Render("○"), id, err)
+			continue
+		case err != nil:
+			fmt.Printf("%s %s: %v\n", style.ErrorPrefix, id, err)
+			failed++
+			continue
+		}
+		_ = events.LogFeed(events.TypeMailReceipt, address,
+			events.MailReceiptPayload(msg.ID, msg.From, msg.To, msg.Subject, mail.ReceiptAcked))
+		fmt.Printf("%s Acknowledged %s from %s: %s\n", style.Bold.Render("✓"), id, msg.From, msg.Subject)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to acknowledge %d message(s)", failed)
+	}
+	return nil
+}
+
+func runMailOutbox(cmd *cobra.Command, args []string) error {
+	address := detectSender()
+	if len(args) > 0 {
+		address = args[0]
+	}
+	mailbox, err := getMailbox(address)
+	if err != nil {
+		return err
+	}
+	sent, err := mailbox.ListSent(mailOutboxPendingAck)
+	if err != nil {
+		return fmt.Errorf("listing sent mail: %w", err)
+	}
+	if mailOutboxPendingAck {
+		pending := sent[:0]
+		for _, msg := range sent {
+			if msg.PendingAck() {
+				pending = append(pending, msg)
+			}
+		}
+		sent = pending
+	}
+
+	if mailOutboxJSON {
+		if sent == nil {
+			sent = []*mail.Message{}
+		}
+		return outputJSON(sent)
+	}
+
+	title := "Outbox"
+	if mailOutboxPendingAck {
+		title = "Awaiting acknowledgement"
+	}
+	fmt.Printf("%s %s: %s (%d messages)\n\n", style.Bold.Render("📤"), title, address, len(sent))
+	if len(sent) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(no messages)"))
+		return nil
+	}
+	for _, msg := range sent {
+		fmt.Printf("  %s %s\n", outboxStatus(msg), msg.Subject)
+		fmt.Printf("      %s to %s  %s\n", style.Dim.Render(msg.ID), msg.To,
+			style.Dim.Render(msg.Timestamp.Format("2006-01-02 15:04")))
+		if msg.PendingAck() {
+			fmt.Printf("      %s\n", style.Dim.Render(ackFollowUp(msg)))
+		}
+	}
+	return nil
+}
+
+// outboxStatus is the status column of the outbox listing.
+func outboxStatus(msg *mail.Message) string {
+	switch {
+	case msg.AckedAt != nil:
+		return style.Bold.Render("✓ acked")
+	case msg.AckEscalatedAt != nil:
+		return style.Bold.Render("⚠ escalated")
+	case msg.AckRequired:
+		return style.Bold.Render("… awaiting ack")
+	case msg.Read:
+		return style.Dim.Render("○ read")
+	default:
+		return "● unread"
+	}
+}
+
+// ackFollowUp describes what happens next to an unacknowledged message.
+func ackFollowUp(msg *mail.Message) string {
+	if msg.AckEscalatedAt != nil {
+		return "escalated " + msg.AckEscalatedAt.Local().Format("2006-01-02 15:04")
+	}
+	next := "re-delivery"
+	if msg.AckRedeliveredAt != nil {
+		next = "escalation"
+	}
+	due := msg.AckDue()
+	if !due.After(time.Now()) {
+		return next + " due on the next daemon heartbeat"
+	}
+	return fmt.Sprintf("%s in %s", next, time.Until(due).Round(time.Minute))
+}
+
+func runMailCheckAcks(cmd *cobra.Command, args []string) error {
+	workDir, err := findMailWorkDir()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	actions, err := mail.NewRouter(workDir).CheckAcks(time.Now())
+	if err != nil {
+		return fmt.Errorf("checking acknowledgements: %w", err)
+	}
+	for _, a := range actions {
+		_ = events.LogFeed(events.TypeMailReceipt, a.To,
+			events.MailReceiptPayload(a.ID, a.From, a.To, a.Subject, a.Status))
+	}
+
+	if mailCheckAcksJSON {
+		if actions == nil {
+			actions = []mail.AckAction{}
+		}
+		return outputJSON(map[string]interface{}{"actions": actions})
+	}
+
+	if len(actions) == 0 {
+		fmt.Println("No overdue acknowledgements.")
+		return nil
+	}
+	for _, a := range actions {
+		detail := ""
+		if a.Escalation != "" {
+			detail = " (" + a.Escalation + ")"
+		}
+		if a.Error != "" {
+			style.PrintWarning("%s to %s %s%s: %s", a.ID, a.To, a.Status, detail, a.Error)
+			continue
+		}
+		fmt.Printf("%s %s to %s %s%s: %s\n", style.Bold.Render("✓"), a.ID, a.To, a.Status, detail, a.Subject)
+	}
+	return nil
+}
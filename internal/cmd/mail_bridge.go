@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/emailbridge"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var mailBridgeSyncJSON bool
+
+var mailBridgeCmd = &cobra.Command{
+	Use:   "bridge",
+	Short: "Read and answer mail from an email inbox",
+	RunE:  requireSubcommand,
+	Long: `Bridge mail to a human's email inbox.
+
+Mail addressed to the overseer, and mail sent to chosen mailing lists, is
+emailed through an SMTP relay. Emails thread like gt mail thread does.
+Replies are read from a maildir and sent back to the original sender as
+replies from the overseer, in the same thread, so questions like "should
+I proceed?" can be answered from a phone.
+
+Configure the bridge under "email" in settings/config.json:
+
+  "email": {
+    "to": ["me@example.com"],
+    "lists": {"oncall": ["ops@example.com"]},
+    "from": "gastown@example.com",
+    "smtp": "smtp.example.com:587",
+    "username": "gastown@example.com",
+    "password": "$GT_SMTP_PASSWORD",
+    "maildir": "~/Maildir/gastown"
+  }
+
+"to" defaults to the email in mayor/overseer.json. Replies to "from" must
+be delivered to "maildir", e.g. by mbsync, fetchmail or getmail polling
+an IMAP account. Only replies from the "to" and list addresses are
+accepted, and only when they answer an email the bridge sent: each
+Message-ID carries a token signed with the key in .runtime/email/reply.key.
+
+The daemon syncs on every heartbeat. The first sync only records the
+time: earlier mail is not emailed.`,
+}
+
+var mailBridgeSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Email new mail and ingest replies",
+	Args:  cobra.NoArgs,
+	RunE:  runMailBridgeSync,
+}
+
+var mailBridgeStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the email bridge configuration and activity",
+	Args:  cobra.NoArgs,
+	RunE:  runMailBridgeStatus,
+}
+
+func init() {
+	mailBridgeSyncCmd.Flags().BoolVar(&mailBridgeSyncJSON, "json", false, "Output as JSON")
+
+	mailBridgeCmd.AddCommand(mailBridgeSyncCmd)
+	mailBridgeCmd.AddCommand(mailBridgeStatusCmd)
+	mailCmd.AddCommand(mailBridgeCmd)
+}
+
+func runMailBridgeSync(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := emailbridge.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	res := &emailbridge.Result{Forwarded: []emailbridge.Forwarded{}, Replies: []emailbridge.Reply{}}
+	if cfg != nil && !cfg.Disabled {
+		b := emailbridge.New(townRoot, cfg, emailbridge.NewBeadsMailer(townRoot), smtp.SendMail)
+		if res, err = b.Sync(time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if mailBridgeSyncJSON {
+		return outputJSON(res)
+	}
+	switch {
+	case cfg == nil:
+		fmt.Println("Email bridge is not configured. See gt mail bridge --help.")
+		return nil
+	case cfg.Disabled:
+		fmt.Println("Email bridge is disabled.")
+		return nil
+	case len(res.Forwarded) == 0 && len(res.Replies) == 0:
+		fmt.Println("Nothing to email, no new replies.")
+		return nil
+	}
+	for _, f := range res.Forwarded {
+		if f.Error != "" {
+			style.PrintWarning("emailing %s to %s failed, will retry: %s", f.ID, strings.Join(f.Emails, ", "), f.Error)
+			continue
+		}
+		fmt.Printf("%s Emailed %s from %s to %s: %s\n", style.Bold.Render("✓"), f.ID, f.From, strings.Join(f.Emails, ", "), f.Subject)
+	}
+	for _, r := range res.Replies {
+		switch {
+		case r.Error != "":
+			style.PrintWarning("reply %s from %s failed, will retry: %s", r.File, r.From, r.Error)
+		case r.Skipped != "":
+			fmt.Printf("%s Skipped %s from %s: %s\n", style.Dim.Render("○"), r.File, r.From, r.Skipped)
+		default:
+			fmt.Printf("%s Reply from %s to %s (re %s): %s\n", style.Bold.Render("✓"), r.From, r.To, r.ReplyTo, r.Subject)
+		}
+	}
+	return nil
+}
+
+func runMailBridgeStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := emailbridge.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		fmt.Println("Email bridge is not configured. See gt mail bridge --help.")
+		return nil
+	}
+
+	state := "enabled"
+	if cfg.Disabled {
+		state = "disabled"
+	}
+	fmt.Printf("%s Email bridge (%s)\n", style.Bold.Render("✉"), state)
+	fmt.Printf("  Relay:    %s as %s\n", cfg.SMTP, cfg.From)
+	if len(cfg.To) > 0 {
+		fmt.Printf("  Overseer: %s\n", strings.Join(cfg.To, ", "))
+	}
+	lists := make([]string, 0, len(cfg.Lists))
+	for name := range cfg.Lists {
+		lists = append(lists, name)
+	}
+	sort.Strings(lists)
+	for _, name := range lists {
+		fmt.Printf("  list:%s: %s\n", name, strings.Join(cfg.Lists[name], ", "))
+	}
+	if cfg.Maildir != "" {
+		fmt.Printf("  Replies:  %s\n", emailbridge.MaildirPath(townRoot, cfg))
+	} else {
+		fmt.Printf("  Replies:  %s\n", style.Dim.Render("not ingested (no maildir)"))
+	}
+
+	st, err := emailbridge.LoadState(townRoot)
+	if err != nil {
+		return err
+	}
+	if st.Since.IsZero() {
+		fmt.Printf("\n  %s\n", style.Dim.Render("Not synced yet."))
+		return nil
+	}
+	fmt.Printf("\n  Syncing since %s\n", st.Since.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("  Last %d days: %d emailed, %d replies ingested\n",
+		int(emailbridge.MaxAge.Hours()/24), len(st.Forwarded), len(st.Ingested))
+	return nil
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// defaultDigestInterval is how long notifications are held when the town
+// settings do not set digest.interval.
+const defaultDigestInterval = time.Hour
+
+// Digest command flags
+var (
+	mailDigestAll     bool
+	mailDigestJSON    bool
+	mailDigestRunJSON bool
+)
+
+var mailDigestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Show notifications held for your next digest",
+	Long: `Show notifications held back while you are muted.
+
+While an agent is muted (gt dnd on, gt notify muted), new mail is still
+delivered but does not nudge them, and nudges are not sent. Instead both
+are held and mailed as one digest, grouped into merges, convoy updates,
+escalations, help requests, nudges and other mail, with the command that
+opens each message. Urgent mail and mail sent with --require-ack still
+nudge.
+
+The daemon mails a digest once its oldest notification is older than the
+interval under "digest" in settings/config.json (default 1h), and at most
+once per interval per mailbox:
+
+  "digest": {"interval": "30m"}
+
+Turning DND off mails the digest right away.
+
+Examples:
+  gt mail digest              # Your held notifications
+  gt mail digest --all        # Everyone's
+  gt mail digest send         # Mail your digest now`,
+	Args: cobra.NoArgs,
+	RunE: runMailDigest,
+}
+
+var mailDigestSendCmd = &cobra.Command{
+	Use:   "send [address]",
+	Short: "Mail a digest now",
+	Long: `Mail the digest of held notifications now, instead of waiting for the
+daemon. Defaults to your own mailbox.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMailDigestSend,
+}
+
+var mailDigestRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Mail the digests that are due",
+	Long: `Mail a digest to each mailbox whose oldest held notification is older
+than the digest interval.
+
+The daemon runs this on every heartbeat.`,
+	Args: cobra.NoArgs,
+	RunE: runMailDigestRun,
+}
+
+func init() {
+	mailDigestCmd.Flags().BoolVar(&mailDigestAll, "all", false, "Show every mailbox's held notifications")
+	mailDigestCmd.Flags().BoolVar(&mailDigestJSON, "json", false, "Output as JSON")
+	mailDigestRunCmd.Flags().BoolVar(&mailDigestRunJSON, "json", false, "Output as JSON")
+
+	mailDigestCmd.AddCommand(mailDigestSendCmd)
+	mailDigestCmd.AddCommand(mailDigestRunCmd)
+	mailCmd.AddCommand(mailDigestCmd)
+}
+
+func runMailDigest(cmd *cobra.Command, args []string) error {
+	townRoot, err := findMailWorkDir()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	queues, err := mail.PendingDigests(townRoot)
+	if err != nil {
+		return fmt.Errorf("reading digests: %w", err)
+	}
+	if !mailDigestAll {
+		identity := mail.AddressToIdentity(detectSender())
+		for id := range queues {
+			if id != identity {
+				delete(queues, id)
+			}
+		}
+	}
+
+	if mailDigestJSON {
+		return outputJSON(queues)
+	}
+	if len(queues) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("No notifications held for a digest."))
+		return nil
+	}
+	interval := digestInterval(townRoot)
+	identities := make([]string, 0, len(queues))
+	for id := range queues {
+		identities = append(identities, id)
+	}
+	sort.Strings(identities)
+	for _, id := range identities {
+		q := queues[id]
+		due := q.Oldest().Add(interval)
+		when := "due on the next daemon heartbeat"
+		if due.After(time.Now()) {
+			when = "due in " + time.Until(due).Round(time.Minute).String()
+		}
+		fmt.Printf("%s %s: %d held (%s)\n", style.Bold.Render("📋"), id, len(q.Items), style.Dim.Render(when))
+		for _, it := range q.Items {
+			ref := ""
+			if it.ID != "" {
+				ref = "  " + style.Dim.Render(it.ID)
+			}
+			fmt.Printf("  [%s] %s %s%s\n", it.Kind, it.Subject, style.Dim.Render("from "+it.From), ref)
+		}
+	}
+	return nil
+}
+
+func runMailDigestSend(cmd *cobra.Command, args []string) error {
+	townRoot, err := findMailWorkDir()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	address := detectSender()
+	if len(args) > 0 {
+		address = args[0]
+	}
+	sent, err := flushDigest(townRoot, address)
+	if err != nil {
+		return err
+	}
+	if sent == nil {
+		fmt.Printf("%s\n", style.Dim.Render("No notifications held for "+address+"."))
+		return nil
+	}
+	fmt.Printf("%s Mailed %s: %s\n", style.Bold.Render("✓"), sent.Address, sent.Subject)
+	return nil
+}
+
+func runMailDigestRun(cmd *cobra.Command, args []string) error {
+	townRoot, err := findMailWorkDir()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	interval := digestInterval(townRoot)
+	nm := digestSlots(townRoot, interval)
+	now := time.Now()
+	sent, err := mail.NewRouter(townRoot).SendDigests(func(identity string, q *mail.DigestQueue) bool {
+		if now.Sub(q.Oldest()) < interval {
+			return false
+		}
+		ok, _ := nm.ShouldSend(identity, daemon.SlotDigest)
+		return ok
+	})
+	if err != nil {
+		return fmt.Errorf("sending digests: %w", err)
+	}
+	recordDigests(nm, sent)
+
+	if mailDigestRunJSON {
+		if sent == nil {
+			sent = []mail.DigestSent{}
+		}
+		return outputJSON(map[string]interface{}{"digests": sent})
+	}
+	if len(sent) == 0 {
+		fmt.Println("No digests due.")
+		return nil
+	}
+	for _, d := range sent {
+		if d.Error != "" {
+			style.PrintWarning("digest for %s failed, will retry: %s", d.Address, d.Error)
+			continue
+		}
+		fmt.Printf("%s Mailed %s: %s\n", style.Bold.Render("✓"), d.Address, d.Subject)
+	}
+	return nil
+}
+
+// flushDigest mails address's digest now, e.g. when DND is turned off.
+// Returns nil if nothing was held for it.
+func flushDigest(townRoot, address string) (*mail.DigestSent, error) {
+	identity := mail.AddressToIdentity(address)
+	sent, err := mail.NewRouter(townRoot).SendDigests(func(id string, _ *mail.DigestQueue) bool {
+		return id == identity
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sending digest: %w", err)
+	}
+	if len(sent) == 0 {
+		return nil, nil
+	}
+	if sent[0].Error != "" {
+		return nil, fmt.Errorf("sending digest to %s: %s", address, sent[0].Error)
+	}
+	recordDigests(digestSlots(townRoot, digestInterval(townRoot)), sent)
+	return &sent[0], nil
+}
+
+// digestInterval returns the configured digest interval.
+func digestInterval(townRoot string) time.Duration {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil || settings.Digest == nil || settings.Digest.Interval == "" {
+		return defaultDigestInterval
+	}
+	d, err := time.ParseDuration(settings.Digest.Interval)
+	if err != nil || d <= 0 {
+		return defaultDigestInterval
+	}
+	return d
+}
+
+// digestSlots returns the notification slots that space out each
+// mailbox's digests by the interval.
+func digestSlots(townRoot string, interval time.Duration) *daemon.NotificationManager {
+	return daemon.NewNotificationManager(filepath.Join(townRoot, "daemon", "notifications"), interval)
+}
+
+// recordDigests records each mailed digest, and the notifications it
+// covered, in its mailbox's digest slot.
+func recordDigests(nm *daemon.NotificationManager, sent []mail.DigestSent) {
+	for _, d := range sent {
+		if d.Error != "" {
+			continue
+		}
+		ids := make([]string, 0, len(d.Items))
+		for _, it := range d.Items {
+			if it.ID != "" {
+				ids = append(ids, it.ID)
+			} else {
+				ids = append(ids, it.Subject)
+			}
+		}
+		_ = nm.RecordDigest(d.Mailbox, daemon.SlotDigest, d.Subject, ids) // best-effort
+	}
+}
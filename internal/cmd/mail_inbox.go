@@ -100,10 +100,14 @@ func runMailInbox(cmd *cobra.Command, args []string) error {
 		if msg.Wisp {
 			wispMarker = " " + style.Dim.Render("(wisp)")
 		}
+		ackMarker := ""
+		if msg.PendingAck() {
+			ackMarker = " " + style.Bold.Render("[ack required]")
+		}
 
 		// Show 1-based index for easy reference with 'gt mail read <n>'
 		indexStr := style.Dim.Render(fmt.Sprintf("%d.", i+1))
-		fmt.Printf("  %s %s %s%s%s%s\n", indexStr, readMarker, msg.Subject, typeMarker, priorityMarker, wispMarker)
+		fmt.Printf("  %s %s %s%s%s%s%s\n", indexStr, readMarker, msg.Subject, typeMarker, priorityMarker, wispMarker, ackMarker)
 		fmt.Printf("      %s from %s\n",
 			style.Dim.Render(msg.ID),
 			msg.From)
@@ -189,6 +193,11 @@ func runMailRead(cmd *cobra.Command, args []string) error {
 	if msg.ReplyTo != "" {
 		fmt.Printf("Reply-To: %s\n", style.Dim.Render(msg.ReplyTo))
 	}
+	if msg.PendingAck() {
+		fmt.Printf("Ack: %s\n", style.Bold.Render("required - run gt mail ack "+msg.ID))
+	} else if msg.AckedAt != nil {
+		fmt.Printf("Ack: %s\n", style.Dim.Render("acknowledged "+msg.AckedAt.Local().Format("2006-01-02 15:04")))
+	}
 
 	if msg.Body != "" {
 		fmt.Printf("\n%s\n", msg.Body)
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Rules command flags
+var (
+	mailRulesJSON     bool
+	mailRulesTo       string
+	mailRulesFrom     string
+	mailRulesSubject  string
+	mailRulesBody     string
+	mailRulesPriority string
+)
+
+var mailRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Show and test mailbox rules",
+	Long: `Show and test the mailbox rules in config/messaging.json.
+
+Rules filter mail as it is delivered. Each mailbox (or wildcard pattern
+like */witness, or * for every mailbox) has an ordered list of rules; the
+first rule that matches decides, unless it sets "continue".
+
+Match on (all given must match):
+  from       Sender address pattern (gastown/polecats/*)
+  subject    Regular expression on the subject
+  protocol   polecat_done, lifecycle_shutdown, help, merged, merge_failed,
+             merge_ready, rework_request, handoff, swarm_start, or none
+  priority   low, normal, high, urgent; "high+" for high or urgent
+  body       Regular expression on the body
+
+Actions:
+  archive    Deliver already read, out of the inbox
+  labels     Add labels to the message
+  forward    Send a copy to other addresses
+  bead       File as a bead of this type (e.g. task) instead of mail
+  escalate   Raise an escalation of this severity
+  delivery   "interrupt" nudges the agent, "queue" waits for gt mail check
+
+Example config/messaging.json:
+  "rules": {
+    "mayor/": [
+      {"name": "done", "match": {"protocol": "polecat_done"}, "archive": true},
+      {"name": "help", "match": {"protocol": "help"}, "delivery": "interrupt"}
+    ],
+    "*/witness": [
+      {"name": "failed", "match": {"protocol": "merge_failed"}, "escalate": "medium", "continue": true},
+      {"name": "quiet", "match": {"priority": "low"}, "delivery": "queue"}
+    ]
+  }
+
+Examples:
+  gt mail rules list                          # All rules
+  gt mail rules list gastown/witness          # Rules for one mailbox, in order
+  gt mail rules test hq-abc123                # Which rules an existing message hits
+  gt mail rules test --to mayor/ --subject "POLECAT_DONE nux"`,
+	RunE: requireSubcommand,
+}
+
+var mailRulesListCmd = &cobra.Command{
+	Use:   "list [mailbox]",
+	Short: "List mailbox rules",
+	Long:  "List mailbox rules, or the rules one mailbox runs, in evaluation order.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runMailRulesList,
+}
+
+var mailRulesTestCmd = &cobra.Command{
+	Use:   "test [message-id]",
+	Short: "Show what mailbox rules do with a message",
+	Long: `Evaluate mailbox rules against a message without delivering anything.
+
+Give an existing message ID, or describe a message with flags. Flags
+override the fields of an existing message. The mailbox defaults to the
+message's recipient, else your own.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMailRulesTest,
+}
+
+func init() {
+	mailRulesListCmd.Flags().BoolVar(&mailRulesJSON, "json", false, "Output as JSON")
+
+	mailRulesTestCmd.Flags().StringVar(&mailRulesTo, "to", "", "Recipient mailbox")
+	mailRulesTestCmd.Flags().StringVar(&mailRulesFrom, "from", "", "Sender address")
+	mailRulesTestCmd.Flags().StringVarP(&mailRulesSubject, "subject", "s", "", "Message subject")
+	mailRulesTestCmd.Flags().StringVarP(&mailRulesBody, "body", "m", "", "Message body")
+	mailRulesTestCmd.Flags().StringVar(&mailRulesPriority, "priority", "", "Message priority (low, normal, high, urgent)")
+	mailRulesTestCmd.Flags().BoolVar(&mailRulesJSON, "json", false, "Output as JSON")
+
+	mailRulesCmd.AddCommand(mailRulesListCmd)
+	mailRulesCmd.AddCommand(mailRulesTestCmd)
+
+	mailCmd.AddCommand(mailRulesCmd)
+}
+
+func loadMailRulesConfig() (*config.MessagingConfig, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := config.LoadOrCreateMessagingConfig(config.MessagingConfigPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading messaging config: %w", err)
+	}
+	return cfg, nil
+}
+
+func runMailRulesList(cmd *cobra.Command, args []string) error {
+	cfg, err := loadMailRulesConfig()
+	if err != nil {
+		return err
+	}
+
+	rules := cfg.Rules
+	if len(args) == 1 {
+		rules = map[string][]config.MailRule{}
+		if r := mail.MailboxRules(cfg, args[0]); len(r) > 0 {
+			rules[mail.AddressToIdentity(args[0])] = r
+		}
+	}
+
+	if mailRulesJSON {
+		return outputJSON(rules)
+	}
+	if len(rules) == 0 {
+		fmt.Printf("%s No mailbox rules configured\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	keys := make([]string, 0, len(rules))
+	for k := range rules {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("%s\n", style.Bold.Render(key))
+		for i, rule := range rules[key] {
+			fmt.Printf("  %d. %s  %s → %s\n", i+1, rule.Name,
+				style.Dim.Render(describeMailMatch(rule.Match)), describeMailActions(rule))
+		}
+	}
+	return nil
+}
+
+func runMailRulesTest(cmd *cobra.Command, args []string) error {
+	cfg, err := loadMailRulesConfig()
+	if err != nil {
+		return err
+	}
+
+	msg := &mail.Message{Priority: mail.PriorityNormal}
+	if len(args) == 1 {
+		mailbox, err := getMailbox(detectSender())
+		if err != nil {
+			return err
+		}
+		if msg, err = mailbox.Get(args[0]); err != nil {
+			return fmt.Errorf("getting message: %w", err)
+		}
+	}
+	if mailRulesTo != "" {
+		msg.To = mailRulesTo
+	}
+	if mailRulesFrom != "" {
+		msg.From = mailRulesFrom
+	}
+	if mailRulesSubject != "" {
+		msg.Subject = mailRulesSubject
+	}
+	if mailRulesBody != "" {
+		msg.Body = mailRulesBody
+	}
+	if mailRulesPriority != "" {
+		msg.Priority = mail.ParsePriority(mailRulesPriority)
+	}
+	if msg.To == "" {
+		msg.To = detectSender()
+	}
+
+	res := mail.EvaluateRules(cfg, msg)
+	if mailRulesJSON {
+		return outputJSON(res)
+	}
+
+	fmt.Printf("%s %s\n", style.Bold.Render("Mailbox:"), res.Mailbox)
+	fmt.Printf("%s %s\n", style.Bold.Render("Protocol:"), res.Protocol)
+	if len(res.Matched) == 0 {
+		fmt.Printf("%s No rule matches: delivered to the inbox as usual\n", style.Dim.Render("○"))
+		return nil
+	}
+	fmt.Printf("%s %s\n", style.Bold.Render("Matched:"), strings.Join(res.Matched, ", "))
+	for _, action := range describeRuleResult(res) {
+		fmt.Printf("  → %s\n", action)
+	}
+	return nil
+}
+
+// describeMailMatch renders a rule's condition.
+func describeMailMatch(m config.MailMatch) string {
+	var parts []string
+	for _, f := range []struct{ name, value string }{
+		{"from", m.From}, {"protocol", m.Protocol}, {"priority", m.Priority},
+		{"subject", m.Subject}, {"body", m.Body},
+	} {
+		if f.value != "" {
+			parts = append(parts, fmt.Sprintf("%s=%s", f.name, f.value))
+		}
+	}
+	if len(parts) == 0 {
+		return "all mail"
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeMailActions renders a rule's actions.
+func describeMailActions(r config.MailRule) string {
+	var parts []string
+	if r.Archive {
+		parts = append(parts, "archive")
+	}
+	if len(r.Labels) > 0 {
+		parts = append(parts, "label "+strings.Join(r.Labels, ","))
+	}
+	if len(r.Forward) > 0 {
+		parts = append(parts, "forward "+strings.Join(r.Forward, ","))
+	}
+	if r.Bead != "" {
+		parts = append(parts, r.Bead+" bead")
+	}
+	if r.Escalate != "" {
+		parts = append(parts, "escalate "+r.Escalate)
+	}
+	if r.Delivery != "" {
+		parts = append(parts, r.Delivery)
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "deliver")
+	}
+	if r.Continue {
+		parts = append(parts, "continue")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeRuleResult lists what delivery will do with a message.
+func describeRuleResult(res *mail.RuleResult) []string {
+	if res.Bead != "" {
+		out := []string{fmt.Sprintf("filed as a %s bead instead of mail", res.Bead)}
+		return append(out, describeFollowUps(res)...)
+	}
+	var out []string
+	switch {
+	case res.Archive:
+		out = append(out, "delivered archived (already read, no nudge)")
+	case res.Delivery == mail.DeliveryQueue:
+		out = append(out, "delivered to the inbox without a nudge")
+	case res.Delivery == mail.DeliveryInterrupt:
+		out = append(out, "delivered and the recipient nudged")
+	default:
+		out = append(out, "delivered to the inbox")
+	}
+	out = append(out, "labels: "+strings.Join(res.Labels, ", "))
+	return append(out, describeFollowUps(res)...)
+}
+
+func describeFollowUps(res *mail.RuleResult) []string {
+	var out []string
+	for _, to := range res.Forward {
+		out = append(out, "forwarded to "+to)
+	}
+	if res.Escalate != "" {
+		out = append(out, fmt.Sprintf("escalated (%s)", res.Escalate))
+	}
+	return out
+}
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/schedule"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -18,6 +20,12 @@ import (
 func runMailSend(cmd *cobra.Command, args []string) error {
 	var to string
 
+	// Validate scheduling flags before doing any work
+	next, scheduled, err := parseScheduleFlags(mailSendAt, mailSendIn, mailSendCron, time.Now())
+	if err != nil {
+		return err
+	}
+
 	if mailSendSelf {
 		// Auto-detect identity from cwd
 		cwd, err := os.Getwd()
@@ -88,6 +96,20 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 	// Set CC recipients
 	msg.CC = mailCC
 
+	// Acknowledgements apply to mail delivered to an inbox
+	if mailRequireAck || cmd.Flags().Changed("ack-within") {
+		if mailAckWithin <= 0 {
+			return fmt.Errorf("--ack-within must be positive")
+		}
+		for _, prefix := range []string{"queue:", "channel:", "announce:"} {
+			if strings.HasPrefix(to, prefix) {
+				return fmt.Errorf("--require-ack needs direct or list recipients, not %s addresses", strings.TrimSuffix(prefix, ":"))
+			}
+		}
+		msg.AckRequired = true
+		msg.AckWithin = mailAckWithin
+	}
+
 	// Handle reply-to: auto-set type to reply and look up thread
 	if mailReplyTo != "" {
 		msg.ReplyTo = mailReplyTo
@@ -105,6 +127,55 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Scheduled mail is stored as built and sent by the daemon heartbeat.
+	// Thread IDs are assigned at delivery so each occurrence of a recurring
+	// message starts its own thread.
+	if scheduled {
+		townRoot, err := workspace.FindFromCwdOrError()
+		if err != nil {
+			return fmt.Errorf("not in a Gas Town workspace: %w", err)
+		}
+		entry := &schedule.Entry{CreatedBy: from, Next: next, Cron: mailSendCron, Mail: msg}
+		if err := schedule.Add(townRoot, entry); err != nil {
+			return fmt.Errorf("scheduling message: %w", err)
+		}
+		printScheduled(entry)
+		return nil
+	}
+
+	recipientAddrs, err := deliverMail(workDir, msg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Message sent to %s\n", style.Bold.Render("✓"), to)
+	fmt.Printf("  Subject: %s\n", mailSubject)
+
+	// Show resolved recipients if fan-out occurred
+	if len(recipientAddrs) > 1 || (len(recipientAddrs) == 1 && recipientAddrs[0] != to) {
+		fmt.Printf("  Recipients: %s\n", strings.Join(recipientAddrs, ", "))
+	}
+
+	if len(msg.CC) > 0 {
+		fmt.Printf("  CC: %s\n", strings.Join(msg.CC, ", "))
+	}
+	if msg.Type != mail.TypeNotification {
+		fmt.Printf("  Type: %s\n", msg.Type)
+	}
+	if msg.AckRequired {
+		fmt.Printf("  Ack: required within %s (gt mail outbox --pending-ack)\n", msg.AckWithin)
+	}
+
+	return nil
+}
+
+// deliverMail sends msg to msg.To, fanning out through the address resolver,
+// and logs it to the activity feed. It returns the resolved recipients,
+// or nil when the resolver could not handle the address and legacy routing
+// was used.
+func deliverMail(workDir string, msg *mail.Message) ([]string, error) {
+	to := msg.To
+
 	// Generate thread ID for new threads
 	if msg.ThreadID == "" {
 		msg.ThreadID = generateThreadID()
@@ -120,12 +191,10 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 		// Fall back to legacy routing if resolver fails
 		router := mail.NewRouter(workDir)
 		if err := router.Send(msg); err != nil {
-			return fmt.Errorf("sending message: %w", err)
+			return nil, fmt.Errorf("sending message: %w", err)
 		}
-		_ = events.LogFeed(events.TypeMail, from, events.MailPayload(to, mailSubject))
-		fmt.Printf("%s Message sent to %s\n", style.Bold.Render("✓"), to)
-		fmt.Printf("  Subject: %s\n", mailSubject)
-		return nil
+		_ = events.LogFeed(events.TypeMail, msg.From, events.MailPayload(to, msg.Subject))
+		return nil, nil
 	}
 
 	// Route based on recipient type
@@ -138,7 +207,7 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 			// Queue messages: single message, workers claim
 			msg.To = rec.Address
 			if err := router.Send(msg); err != nil {
-				return fmt.Errorf("sending to queue: %w", err)
+				return nil, fmt.Errorf("sending to queue: %w", err)
 			}
 			recipientAddrs = append(recipientAddrs, rec.Address)
 
@@ -146,7 +215,7 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 			// Channel messages: single message, broadcast
 			msg.To = rec.Address
 			if err := router.Send(msg); err != nil {
-				return fmt.Errorf("sending to channel: %w", err)
+				return nil, fmt.Errorf("sending to channel: %w", err)
 			}
 			recipientAddrs = append(recipientAddrs, rec.Address)
 
@@ -155,31 +224,15 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 			msgCopy := *msg
 			msgCopy.To = rec.Address
 			if err := router.Send(&msgCopy); err != nil {
-				return fmt.Errorf("sending to %s: %w", rec.Address, err)
+				return nil, fmt.Errorf("sending to %s: %w", rec.Address, err)
 			}
 			recipientAddrs = append(recipientAddrs, rec.Address)
 		}
 	}
 
 	// Log mail event to activity feed
-	_ = events.LogFeed(events.TypeMail, from, events.MailPayload(to, mailSubject))
-
-	fmt.Printf("%s Message sent to %s\n", style.Bold.Render("✓"), to)
-	fmt.Printf("  Subject: %s\n", mailSubject)
-
-	// Show resolved recipients if fan-out occurred
-	if len(recipientAddrs) > 1 || (len(recipientAddrs) == 1 && recipientAddrs[0] != to) {
-		fmt.Printf("  Recipients: %s\n", strings.Join(recipientAddrs, ", "))
-	}
-
-	if len(msg.CC) > 0 {
-		fmt.Printf("  CC: %s\n", strings.Join(msg.CC, ", "))
-	}
-	if msg.Type != mail.TypeNotification {
-		fmt.Printf("  Type: %s\n", msg.Type)
-	}
-
-	return nil
+	_ = events.LogFeed(events.TypeMail, msg.From, events.MailPayload(to, msg.Subject))
+	return recipientAddrs, nil
 }
 
 // generateThreadID creates a random thread ID for new message threads.
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mockagent"
+)
+
+var mockAgentScenario string
+
+var mockAgentCmd = &cobra.Command{
+	Use:    "mock-agent [prompt]",
+	Short:  "Run the scriptable mock agent (for end-to-end tests)",
+	Hidden: true, // Started by the "mock" agent preset, not by hand
+	Long: `Run a deterministic fake agent that follows a scenario file.
+
+The built-in "mock" agent preset starts this command in place of an LLM CLI,
+so whole-town flows can be tested in CI with no API keys or network. It
+prints the same ❯ prompt as Claude Code, so session startup detects it as
+ready, reads nudges from the terminal and runs real gt, bd and git commands.
+
+The scenario comes from --scenario or $GT_MOCK_SCENARIO; relative paths are
+resolved against the town root. A directory holds one scenario per role:
+<role>.toml (polecat, witness, refinery, crew, ...) or else default.toml.
+Without a scenario, the agent primes and idles.
+
+Example scenario (TOML):
+
+  name = "polecat-happy-path"
+
+  [[step]]
+  action = "prime"                 # gt prime, then read the hook
+
+  [[step]]
+  action = "wait"                  # block until a nudge arrives
+  match = "hook"                   # ...that matches this regexp
+  text = "On it."                  # reply
+
+  [[step]]
+  action = "complete-steps"        # bd close each molecule step in order
+
+  [[step]]
+  action = "commit"
+  message = "Fix $MOCK_HOOK"
+  files = { "fix.txt" = "fixed\n" }
+
+  [[step]]
+  action = "done"                  # gt done, then exit
+
+Other actions: say (text), run (command), sleep (duration), help (mail the
+Witness), escalate (text, severity), hang (stop responding, optional
+duration), crash (exit_code) and exit.
+
+To use it, point the preset at a scenario in settings/agents.json and make
+it the default agent (gt config default-agent mock):
+
+  {"version": 1, "agents": {"mock": {
+    "command": "gt", "args": ["mock-agent"],
+    "env": {"GT_MOCK_SCENARIO": "scenarios"}}}}`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runMockAgent,
+}
+
+func init() {
+	mockAgentCmd.Flags().StringVar(&mockAgentScenario, "scenario", "", "Scenario file (default: $GT_MOCK_SCENARIO)")
+	rootCmd.AddCommand(mockAgentCmd)
+}
+
+func runMockAgent(cmd *cobra.Command, args []string) error {
+	scenario := mockagent.DefaultScenario()
+	path := mockAgentScenario
+	if path == "" {
+		path = os.Getenv(mockagent.ScenarioEnv)
+	}
+	if path != "" {
+		if !filepath.IsAbs(path) && os.Getenv("GT_ROOT") != "" {
+			path = filepath.Join(os.Getenv("GT_ROOT"), path)
+		}
+		var err error
+		scenario, err = mockagent.Resolve(path, os.Getenv("GT_ROLE"))
+		if err != nil {
+			return fmt.Errorf("loading mock scenario: %w", err)
+		}
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	r := &mockagent.Runner{
+		Scenario:      scenario,
+		In:            os.Stdin,
+		Out:           os.Stdout,
+		Dir:           dir,
+		InitialPrompt: strings.Join(args, " "),
+	}
+	if code := r.Run(); code != 0 {
+		os.Exit(code)
+	}
+	return nil
+}
@@ -452,7 +452,7 @@ func handleStepContinue(cwd, townRoot, _ string, nextStep *beads.Issue, dryRun b
 		style.PrintWarning("could not clear history: %v", err)
 	}
 
-	return t.RespawnPane(pane, restartCmd)
+	return respawnAgentPane(t, pane, currentSession, "", restartCmd)
 }
 
 // handleParallelSteps handles executing multiple steps concurrently (fan-out pattern).
@@ -22,6 +22,7 @@ var (
 	mqSubmitEpic      string
 	mqSubmitPriority  int
 	mqSubmitNoCleanup bool
+	mqSubmitParent    string
 
 	// Retry flags
 	mqRetryNow bool
@@ -88,6 +89,13 @@ Target branch auto-detection:
 
 This ensures batch work on epics automatically flows to integration branches.
 
+Stacked MRs:
+  When your branch builds on another polecat's unmerged branch, use
+  --parent <mr-id-or-branch> to stack on that MR. The stacked MR targets the
+  parent's target and stays blocked until the parent lands. The Refinery then
+  replays only your commits onto the target. If the parent is rejected, the
+  stacked MR is closed too.
+
 Polecat auto-cleanup:
   When run from a polecat work branch (polecat/<worker>/<issue>), this command
   automatically triggers polecat shutdown after submitting the MR. The polecat
@@ -101,7 +109,8 @@ Examples:
   gt mq submit --issue gp-abc            # Explicit issue
   gt mq submit --epic gt-xyz             # Target integration branch explicitly
   gt mq submit --priority 0              # Override priority (P0)
-  gt mq submit --no-cleanup              # Submit without auto-cleanup`,
+  gt mq submit --no-cleanup              # Submit without auto-cleanup
+  gt mq submit --parent gt-mr-abc        # Stack on an unmerged MR`,
 	RunE: runMqSubmit,
 }
 
@@ -134,6 +143,9 @@ Output format:
   gt-mr-003   blocked      P1        polecat/Capable/gt-def    Capable 8m
               (waiting on gt-mr-001)
 
+Stacked MRs (gt mq submit --parent) are listed under their parent:
+  gt-mr-004   blocked      P1        └─ polecat/Toast/gt-ghi   Toast   3m
+
 Examples:
   gt mq list greenplace
   gt mq list greenplace --ready
@@ -151,6 +163,9 @@ var mqRejectCmd = &cobra.Command{
 This closes the MR with a 'rejected' status without merging.
 The source issue is NOT closed (work is not done).
 
+MRs stacked on the rejected MR (see 'gt mq submit --parent') carry its
+commits, so they are closed too.
+
 Examples:
   gt mq reject greenplace polecat/Nux/gp-xyz --reason "Does not meet requirements"
   gt mq reject greenplace mr-Nux-12345 --reason "Superseded by other work" --notify`,
@@ -276,6 +291,7 @@ func init() {
 	mqSubmitCmd.Flags().StringVar(&mqSubmitEpic, "epic", "", "Target epic's integration branch instead of main")
 	mqSubmitCmd.Flags().IntVarP(&mqSubmitPriority, "priority", "p", -1, "Override priority (0-4, default: inherit from issue)")
 	mqSubmitCmd.Flags().BoolVar(&mqSubmitNoCleanup, "no-cleanup", false, "Don't auto-cleanup after submit (for polecats)")
+	mqSubmitCmd.Flags().StringVar(&mqSubmitParent, "parent", "", "Stack on an unmerged MR (ID or branch)")
 
 	// Retry flags
 	mqRetryCmd.Flags().BoolVar(&mqRetryNow, "now", false, "Immediately process instead of waiting for refinery loop")
@@ -430,5 +446,9 @@ func runMQReject(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s\n", style.Dim.Render("Worker notified via mail"))
 	}
 
+	for _, childID := range result.InvalidatedChildren {
+		fmt.Printf("  %s %s %s\n", style.Bold.Render("✗"), childID, style.Dim.Render("(stacked on rejected MR)"))
+	}
+
 	return nil
 }
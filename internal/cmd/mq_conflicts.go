@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ conflicts command flags
+var (
+	mqConflictsJSON   bool
+	mqConflictsNotify bool
+)
+
+var mqConflictsCmd = &cobra.Command{
+	Use:   "conflicts <rig>",
+	Short: "Predict merge conflicts between in-flight branches",
+	Long: `Show which in-flight branches are editing the same code.
+
+Every working polecat's branch and every open merge request is diffed
+against the merge target (git diff target...branch). Branches that change
+overlapping or adjacent lines of the same file will likely conflict when
+the Refinery merges the second one.
+
+The matrix shows, for each pair of branches:
+  !N   N files with overlapping hunks (likely conflict)
+  ~N   N files touched by both, in different regions
+  .    no shared files
+
+With --notify, both polecats of every likely conflict are mailed a warning
+(once per overlap) suggesting they coordinate or get serialized. The daemon
+runs this on each heartbeat.
+
+Examples:
+  gt mq conflicts gastown
+  gt mq conflicts gastown --json
+  gt mq conflicts gastown --notify`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQConflicts,
+}
+
+func init() {
+	mqConflictsCmd.Flags().BoolVar(&mqConflictsJSON, "json", false, "Output as JSON")
+	mqConflictsCmd.Flags().BoolVar(&mqConflictsNotify, "notify", false, "Mail polecats about new likely conflicts")
+
+	mqCmd.AddCommand(mqConflictsCmd)
+}
+
+// MQConflictsOutput is the JSON output of gt mq conflicts.
+type MQConflictsOutput struct {
+	Rig      string                    `json:"rig"`
+	Target   string                    `json:"target"`
+	Branches []refinery.InFlightBranch `json:"branches"`
+	Overlaps []*refinery.BranchOverlap `json:"overlaps"`
+	Notified int                       `json:"notified,omitempty"`
+}
+
+func runMQConflicts(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	branches, err := collectInFlightBranches(rigName)
+	if err != nil {
+		return err
+	}
+
+	changes, overlaps := eng.PredictConflicts(branches)
+
+	notified := 0
+	if mqConflictsNotify {
+		notified, err = refinery.WarnOverlaps(mail.NewRouter(r.Path), rigName, r.Path, overlaps)
+		if err != nil {
+			return err
+		}
+	}
+
+	if mqConflictsJSON {
+		out := MQConflictsOutput{
+			Rig:      rigName,
+			Target:   eng.Config().TargetBranch,
+			Branches: make([]refinery.InFlightBranch, 0, len(changes)),
+			Overlaps: overlaps,
+			Notified: notified,
+		}
+		for _, c := range changes {
+			out.Branches = append(out.Branches, c.InFlightBranch)
+		}
+		if out.Overlaps == nil {
+			out.Overlaps = []*refinery.BranchOverlap{}
+		}
+		return outputJSON(out)
+	}
+
+	fmt.Printf("%s Conflict prediction for '%s' (against %s):\n\n",
+		style.Bold.Render("⚔"), rigName, eng.Config().TargetBranch)
+
+	if len(changes) < 2 {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("(%d in-flight branch(es) with changes, nothing to compare)", len(changes))))
+		return nil
+	}
+
+	printConflictMatrix(changes, overlaps)
+
+	if len(overlaps) == 0 {
+		fmt.Printf("\n  %s\n", style.Dim.Render("No overlapping files"))
+	} else {
+		fmt.Println()
+		for _, o := range overlaps {
+			marker := style.Dim.Render("~")
+			if o.Likely() {
+				marker = style.Error.Render("!")
+			}
+			fmt.Printf("  %s %s ↔ %s\n", marker, o.A.Label(), o.B.Label())
+			for _, f := range o.Files {
+				note := style.Dim.Render("different regions")
+				if f.Hunks {
+					note = style.Warning.Render("overlapping hunks")
+				}
+				fmt.Printf("      %s  %s\n", f.Path, note)
+			}
+		}
+	}
+
+	if mqConflictsNotify {
+		fmt.Printf("\n  Sent %d warning(s)\n", notified)
+	}
+	return nil
+}
+
+// collectInFlightBranches returns the branches of working polecats and open
+// merge requests in a rig.
+func collectInFlightBranches(rigName string) ([]refinery.InFlightBranch, error) {
+	mgr, r, err := getPolecatManager(rigName)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []refinery.InFlightBranch
+	polecats, err := mgr.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing polecats: %w", err)
+	}
+	byBranch := make(map[string]string)
+	for _, p := range polecats {
+		// Idle polecats may still have a branch whose work already merged
+		if !p.State.IsActive() || p.Branch == "" {
+			continue
+		}
+		byBranch[p.Branch] = p.Name
+		branches = append(branches, refinery.InFlightBranch{Branch: p.Branch, Polecat: p.Name})
+	}
+
+	b := beads.New(r.BeadsPath())
+	mrs, err := b.List(beads.ListOptions{Type: "merge-request", Status: "open", Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("querying merge queue: %w", err)
+	}
+	for _, issue := range mrs {
+		// Workaround for bd list not respecting --status filter
+		if issue.Status != "open" {
+			continue
+		}
+		fields := beads.ParseMRFields(issue)
+		if fields == nil || fields.Branch == "" {
+			continue
+		}
+		name := byBranch[fields.Branch]
+		if name == "" && fields.Worker != "" {
+			name = path.Base(fields.Worker)
+		}
+		branches = append(branches, refinery.InFlightBranch{Branch: fields.Branch, Polecat: name, MRID: issue.ID})
+	}
+	return branches, nil
+}
+
+// printConflictMatrix prints the pairwise overlap matrix.
+func printConflictMatrix(changes []*refinery.BranchChanges, overlaps []*refinery.BranchOverlap) {
+	byPair := make(map[string]*refinery.BranchOverlap, len(overlaps))
+	for _, o := range overlaps {
+		byPair[o.A.Branch+" "+o.B.Branch] = o
+		byPair[o.B.Branch+" "+o.A.Branch] = o
+	}
+
+	columns := []style.Column{{Name: "BRANCH", Width: 28}}
+	for i := range changes {
+		columns = append(columns, style.Column{Name: fmt.Sprintf("%d", i+1), Width: 4, Align: style.AlignRight})
+	}
+	table := style.NewTable(columns...)
+
+	for i, row := range changes {
+		cells := []string{fmt.Sprintf("%d %s", i+1, row.Label())}
+		for j, col := range changes {
+			if i == j {
+				cells = append(cells, style.Dim.Render("-"))
+				continue
+			}
+			o, ok := byPair[row.Branch+" "+col.Branch]
+			if !ok {
+				cells = append(cells, style.Dim.Render("."))
+				continue
+			}
+			hunks := 0
+			for _, f := range o.Files {
+				if f.Hunks {
+					hunks++
+				}
+			}
+			if hunks > 0 {
+				cells = append(cells, style.Error.Render(fmt.Sprintf("!%d", hunks)))
+			} else {
+				cells = append(cells, style.Warning.Render(fmt.Sprintf("~%d", len(o.Files))))
+			}
+		}
+		table.AddRow(cells...)
+	}
+
+	fmt.Print(table.Render())
+}
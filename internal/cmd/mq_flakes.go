@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ flakes command flags
+var (
+	mqFlakesJSON bool
+	mqFlakesAll  bool
+)
+
+var mqFlakesCmd = &cobra.Command{
+	Use:   "flakes <rig>",
+	Short: "Show flaky tests detected by the merge queue",
+	Long: `Show the flake history recorded by the Refinery for a rig.
+
+When merge_queue.test_output_format is set ("go-json" or "junit"), the
+Refinery parses test results, reruns only the failing tests, and records a
+flake whenever a test fails and then passes against the same commit.
+
+Tests with at least flaky_quarantine_threshold flakes are quarantined: their
+failures no longer bounce MRs back to the polecat, and are counted under
+WAIVED instead. Tests with at least flaky_bead_threshold flakes get a bug
+bead filed automatically.
+
+Examples:
+  gt mq flakes gastown
+  gt mq flakes gastown --all     # Include tests that failed but never flaked
+  gt mq flakes gastown --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQFlakes,
+}
+
+func init() {
+	mqFlakesCmd.Flags().BoolVar(&mqFlakesJSON, "json", false, "Output as JSON")
+	mqFlakesCmd.Flags().BoolVar(&mqFlakesAll, "all", false, "Include tests that failed but never flaked")
+
+	mqCmd.AddCommand(mqFlakesCmd)
+}
+
+func runMQFlakes(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	cfg := eng.Config()
+
+	history, err := refinery.LoadFlakeHistory(r.Path)
+	if err != nil {
+		return fmt.Errorf("loading flake history: %w", err)
+	}
+
+	records := history.Flaky()
+	if mqFlakesAll {
+		records = records[:0]
+		for _, rec := range history.Tests {
+			records = append(records, rec)
+		}
+		refinery.SortFlakeRecords(records)
+	}
+
+	if mqFlakesJSON {
+		if records == nil {
+			records = []*refinery.FlakeRecord{}
+		}
+		return outputJSON(records)
+	}
+
+	fmt.Printf("%s Flaky tests for '%s':\n\n", style.Bold.Render("🎲"), rigName)
+
+	if len(records) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(none recorded)"))
+		if cfg.TestOutputFormat == refinery.TestOutputNone {
+			fmt.Printf("  %s\n", style.Dim.Render("Set merge_queue.test_output_format to enable flake detection"))
+		}
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "TEST", Width: 40},
+		style.Column{Name: "FLAKES", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "PASS", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "FAIL", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "WAIVED", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "FIRST SEEN", Width: 10},
+		style.Column{Name: "STATUS", Width: 14},
+	)
+
+	for _, rec := range records {
+		firstSeen := style.Dim.Render("-")
+		if rec.FirstSeenCommit != "" {
+			firstSeen = rec.FirstSeenCommit
+			if len(firstSeen) > 8 {
+				firstSeen = firstSeen[:8]
+			}
+		}
+
+		status := style.Dim.Render("tracking")
+		switch {
+		case rec.BeadID != "":
+			status = style.Error.Render(rec.BeadID)
+		case cfg.FlakyQuarantineThreshold > 0 && rec.Flakes >= cfg.FlakyQuarantineThreshold:
+			status = style.Warning.Render("quarantined")
+		}
+
+		table.AddRow(rec.Key(), fmt.Sprintf("%d", rec.Flakes), fmt.Sprintf("%d", rec.Passes),
+			fmt.Sprintf("%d", rec.Failures), fmt.Sprintf("%d", rec.Waived), firstSeen, status)
+	}
+
+	fmt.Print(table.Render())
+	return nil
+}
@@ -123,6 +123,22 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		return scored[i].score > scored[j].score
 	})
 
+	// Group stacked MRs under their parent
+	ids := make([]string, len(scored))
+	parents := make([]string, len(scored))
+	for i, s := range scored {
+		ids[i] = s.issue.ID
+		if s.fields != nil {
+			parents[i] = s.fields.ParentMR
+		}
+	}
+	order, depths := stackOrder(ids, parents)
+	stacked := make([]scoredIssue, len(order))
+	for i, idx := range order {
+		stacked[i] = scored[idx]
+	}
+	scored = stacked
+
 	// Extract filtered issues for JSON output compatibility
 	var filtered []*beads.Issue
 	for _, s := range scored {
@@ -153,8 +169,8 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		style.Column{Name: "AGE", Width: 6, Align: style.AlignRight},
 	)
 
-	// Add rows using scored items (already sorted by score)
-	for _, item := range scored {
+	// Add rows using scored items (sorted by score, stacks grouped)
+	for i, item := range scored {
 		issue := item.issue
 		fields := item.fields
 
@@ -189,6 +205,11 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			convoyID = fields.ConvoyID
 		}
 
+		// Indent stacked MRs under their parent
+		if depths[i] > 0 {
+			branch = strings.Repeat("  ", depths[i]-1) + "└─ " + branch
+		}
+
 		// Format convoy column
 		convoyDisplay := style.Dim.Render("(none)")
 		if convoyID != "" {
@@ -244,6 +265,53 @@ func runMQList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// stackOrder orders queue entries so stacked MRs follow their parent MR,
+// and returns each entry's depth in its stack. Entries whose parent isn't
+// listed are roots. The input order is kept among roots and siblings.
+func stackOrder(ids, parents []string) (order []int, depths []int) {
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	children := make(map[int][]int)
+	var roots []int
+	for i, parent := range parents {
+		if p, ok := index[parent]; ok && parent != "" && p != i {
+			children[p] = append(children[p], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	depthOf := make([]int, len(ids))
+	visited := make([]bool, len(ids))
+	var walk func(i, depth int)
+	walk = func(i, depth int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		order = append(order, i)
+		depthOf[i] = depth
+		for _, c := range children[i] {
+			walk(c, depth+1)
+		}
+	}
+	for _, r := range roots {
+		walk(r, 0)
+	}
+	// Parent cycles have no root; list them flat rather than dropping them
+	for i := range ids {
+		walk(i, 0)
+	}
+
+	depths = make([]int, len(order))
+	for i, idx := range order {
+		depths[i] = depthOf[idx]
+	}
+	return order, depths
+}
+
 // formatMRAge formats the age of an MR from its created_at timestamp.
 func formatMRAge(createdAt string) string {
 	t, err := time.Parse(time.RFC3339, createdAt)
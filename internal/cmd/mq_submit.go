@@ -146,6 +146,19 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Stacked submission: land after the parent MR, on the parent's target
+	var parentMR *beads.Issue
+	var parentFields *beads.MRFields
+	if mqSubmitParent != "" {
+		parentMR, parentFields, err = resolveParentMR(bd, mqSubmitParent)
+		if err != nil {
+			return err
+		}
+		if parentFields.Target != "" {
+			target = parentFields.Target
+		}
+	}
+
 	// Get source issue for priority inheritance
 	var priority int
 	if mqSubmitPriority >= 0 {
@@ -168,6 +181,9 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	if worker != "" {
 		description += fmt.Sprintf("\nworker: %s", worker)
 	}
+	if parentMR != nil {
+		description += fmt.Sprintf("\nparent_mr: %s\nparent_branch: %s", parentMR.ID, parentFields.Branch)
+	}
 
 	// Check if MR bead already exists for this branch (idempotency)
 	var mrIssue *beads.Issue
@@ -191,6 +207,13 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("creating merge request bead: %w", err)
 		}
 
+		// Block on the parent so the Refinery lands the stack in order
+		if parentMR != nil {
+			if err := bd.AddDependency(mrIssue.ID, parentMR.ID); err != nil {
+				style.PrintWarning("could not block MR on parent %s: %v", parentMR.ID, err)
+			}
+		}
+
 		// Nudge refinery to pick up the new MR
 		nudgeRefinery(rigName, fmt.Sprintf("MR submitted: %s branch=%s", mrIssue.ID, branch))
 	}
@@ -205,6 +228,9 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Worker: %s\n", worker)
 	}
 	fmt.Printf("  Priority: P%d\n", priority)
+	if parentMR != nil {
+		fmt.Printf("  Stacked on: %s (%s)\n", parentMR.ID, parentFields.Branch)
+	}
 
 	// Auto-cleanup for polecats: if this is a polecat branch and cleanup not disabled,
 	// send lifecycle request and wait for termination
@@ -223,6 +249,30 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// resolveParentMR finds the open MR a stacked submission builds on,
+// by MR ID or by source branch.
+func resolveParentMR(bd *beads.Beads, idOrBranch string) (*beads.Issue, *beads.MRFields, error) {
+	parent, err := bd.Show(idOrBranch)
+	if err != nil || beads.ParseMRFields(parent) == nil {
+		parent, err = bd.FindMRForBranch(idOrBranch)
+		if err != nil {
+			return nil, nil, fmt.Errorf("looking up parent MR %s: %w", idOrBranch, err)
+		}
+	}
+	if parent == nil {
+		return nil, nil, fmt.Errorf("parent MR %s not found in the merge queue", idOrBranch)
+	}
+
+	fields := beads.ParseMRFields(parent)
+	if fields == nil || fields.Branch == "" {
+		return nil, nil, fmt.Errorf("%s is not a merge request", parent.ID)
+	}
+	if parent.Status == "closed" {
+		return nil, nil, fmt.Errorf("parent MR %s is already closed; rebase onto its target and submit without --parent", parent.ID)
+	}
+	return parent, fields, nil
+}
+
 // detectIntegrationBranch checks if an issue is a descendant of an epic that has an integration branch.
 // Traverses up the parent chain until it finds an epic or runs out of parents.
 // Returns the integration branch target (e.g., "integration/gt-epic") if found, or "" if not.
@@ -801,3 +801,33 @@ func TestMRFilteringByLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestStackOrder(t *testing.T) {
+	// Queue order by score: child2, root, child1, grandchild, orphan
+	ids := []string{"child2", "root", "child1", "grandchild", "orphan"}
+	parents := []string{"root", "", "root", "child1", "gone"}
+
+	order, depths := stackOrder(ids, parents)
+
+	var got []string
+	for _, i := range order {
+		got = append(got, ids[i])
+	}
+	want := []string{"root", "child2", "child1", "grandchild", "orphan"}
+	wantDepths := []int{0, 1, 1, 2, 0}
+	if len(got) != len(want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] || depths[i] != wantDepths[i] {
+			t.Errorf("position %d = %s (depth %d), want %s (depth %d)", i, got[i], depths[i], want[i], wantDepths[i])
+		}
+	}
+}
+
+func TestStackOrder_Cycle(t *testing.T) {
+	order, _ := stackOrder([]string{"a", "b"}, []string{"b", "a"})
+	if len(order) != 2 {
+		t.Errorf("cyclic stacks should still be listed, got %v", order)
+	}
+}
@@ -19,7 +19,10 @@ var notifyCmd = &cobra.Command{
 Notification levels:
   verbose  All notifications (mail, convoy events, status updates)
   normal   Important notifications only (default)
-  muted    Silent/DND mode - batch notifications for later
+  muted    Silent/DND mode - batch notifications into a digest for later
+
+While muted, mail notifications and nudges are held and mailed as a
+periodic digest (see gt mail digest). Leaving muted mails it right away.
 
 Without arguments, shows the current notification level.
 
@@ -98,6 +101,9 @@ func runNotify(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("%s Notification level set to %s\n", style.SuccessPrefix, style.Bold.Render(newLevel))
 	showNotificationLevelDescription(newLevel)
+	if currentLevel == beads.NotifyMuted && newLevel != beads.NotifyMuted {
+		printFlushedDigest(townRoot)
+	}
 
 	return nil
 }
@@ -126,6 +132,6 @@ func showNotificationLevelDescription(level string) {
 	case beads.NotifyNormal:
 		fmt.Printf("  %s\n", style.Dim.Render("Important notifications: convoy landed, escalations"))
 	case beads.NotifyMuted:
-		fmt.Printf("  %s\n", style.Dim.Render("Silent mode: notifications batched for later review"))
+		fmt.Printf("  %s\n", style.Dim.Render("Silent mode: notifications batched into a digest (gt mail digest)"))
 	}
 }
@@ -9,6 +9,8 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/schedule"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -17,11 +19,15 @@ import (
 
 var nudgeMessageFlag string
 var nudgeForceFlag bool
+var nudgeAtFlag, nudgeInFlag, nudgeCronFlag string
 
 func init() {
 	rootCmd.AddCommand(nudgeCmd)
 	nudgeCmd.Flags().StringVarP(&nudgeMessageFlag, "message", "m", "", "Message to send")
 	nudgeCmd.Flags().BoolVarP(&nudgeForceFlag, "force", "f", false, "Send even if target has DND enabled")
+	nudgeCmd.Flags().StringVar(&nudgeAtFlag, "at", "", "Nudge at a time instead of now (15:04, 2006-01-02 15:04, or RFC3339)")
+	nudgeCmd.Flags().StringVar(&nudgeInFlag, "in", "", "Nudge after a delay instead of now (e.g., 30m, 2h, 1d)")
+	nudgeCmd.Flags().StringVar(&nudgeCronFlag, "cron", "", "Nudge on a recurring cron schedule (e.g., \"0 9 * * 1-5\")")
 }
 
 var nudgeCmd = &cobra.Command{
@@ -57,13 +63,20 @@ DND (Do Not Disturb):
   If the target has DND enabled (gt dnd on), the nudge is skipped.
   Use --force to override DND and send anyway.
 
+Scheduling:
+  --at, --in and --cron store the nudge and let the daemon send it later
+  (see gt schedule). DND is checked when the nudge fires. The witness and
+  refinery shortcuts resolve to the current rig when scheduling.
+
 Examples:
   gt nudge greenplace/furiosa "Check your mail and start working"
   gt nudge greenplace/alpha -m "What's your status?"
   gt nudge mayor "Status update requested"
   gt nudge witness "Check polecat health"
   gt nudge deacon session-started
-  gt nudge channel:workers "New priority work available"`,
+  gt nudge channel:workers "New priority work available"
+  gt nudge witness "Re-check polecat nux" --in 2h
+  gt nudge mayor "Time for standup" --cron "0 9 * * 1-5"`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runNudge,
 }
@@ -81,47 +94,114 @@ func runNudge(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("message required: use -m flag or provide as second argument")
 	}
 
+	next, scheduled, err := parseScheduleFlags(nudgeAtFlag, nudgeInFlag, nudgeCronFlag, time.Now())
+	if err != nil {
+		return err
+	}
+
+	sender := nudgeSender()
+	if scheduled {
+		return scheduleNudge(target, sender, message, nudgeForceFlag, next)
+	}
+	return sendNudge(target, sender, message, nudgeForceFlag)
+}
+
+// nudgeSender identifies the current agent for the nudge message prefix.
+func nudgeSender() string {
+	roleInfo, err := GetRole()
+	if err != nil {
+		return "unknown"
+	}
+	switch roleInfo.Role {
+	case RoleMayor:
+		return "mayor"
+	case RoleCrew:
+		return fmt.Sprintf("%s/crew/%s", roleInfo.Rig, roleInfo.Polecat)
+	case RolePolecat:
+		return fmt.Sprintf("%s/%s", roleInfo.Rig, roleInfo.Polecat)
+	case RoleWitness:
+		return fmt.Sprintf("%s/witness", roleInfo.Rig)
+	case RoleRefinery:
+		return fmt.Sprintf("%s/refinery", roleInfo.Rig)
+	case RoleDeacon:
+		return "deacon"
+	default:
+		return string(roleInfo.Role)
+	}
+}
+
+// rigShortcutSession expands the witness and refinery shortcuts to the
+// current rig's session name.
+func rigShortcutSession(target string) (string, error) {
+	roleInfo, err := GetRole()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine rig for %s shortcut: %w", target, err)
+	}
+	if roleInfo.Rig == "" {
+		return "", fmt.Errorf("cannot determine rig for %s shortcut (not in a rig context)", target)
+	}
+	if target == "witness" {
+		return session.WitnessSessionName(roleInfo.Rig), nil
+	}
+	return session.RefinerySessionName(roleInfo.Rig), nil
+}
+
+// scheduleNudge stores a nudge for the daemon to send at next, or on the
+// --cron schedule. Rig shortcuts are expanded now because the daemon runs
+// outside any rig. force is saved with the entry, so DND is overridden when
+// the daemon sends it just as for an immediate --force nudge.
+func scheduleNudge(target, sender, message string, force bool, next time.Time) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("cannot find town root: %w", err)
+	}
+	if target == "witness" || target == "refinery" {
+		if target, err = rigShortcutSession(target); err != nil {
+			return err
+		}
+	}
+	entry := &schedule.Entry{
+		CreatedBy: sender,
+		Next:      next,
+		Cron:      nudgeCronFlag,
+		Nudge:     &schedule.Nudge{Target: target, Sender: sender, Message: message, Force: force},
+	}
+	if err := schedule.Add(townRoot, entry); err != nil {
+		return fmt.Errorf("scheduling nudge: %w", err)
+	}
+	printScheduled(entry)
+	return nil
+}
+
+// sendNudge delivers a nudge from sender to target now. A target with DND
+// enabled is skipped unless force is set.
+func sendNudge(target, sender, message string, force bool) error {
 	// Handle channel syntax: channel:<name>
 	if strings.HasPrefix(target, "channel:") {
 		channelName := strings.TrimPrefix(target, "channel:")
-		return runNudgeChannel(channelName, message)
-	}
-
-	// Identify sender for message prefix
-	sender := "unknown"
-	if roleInfo, err := GetRole(); err == nil {
-		switch roleInfo.Role {
-		case RoleMayor:
-			sender = "mayor"
-		case RoleCrew:
-			sender = fmt.Sprintf("%s/crew/%s", roleInfo.Rig, roleInfo.Polecat)
-		case RolePolecat:
-			sender = fmt.Sprintf("%s/%s", roleInfo.Rig, roleInfo.Polecat)
-		case RoleWitness:
-			sender = fmt.Sprintf("%s/witness", roleInfo.Rig)
-		case RoleRefinery:
-			sender = fmt.Sprintf("%s/refinery", roleInfo.Rig)
-		case RoleDeacon:
-			sender = "deacon"
-		default:
-			sender = string(roleInfo.Role)
-		}
+		return runNudgeChannel(channelName, sender, message)
 	}
 
-	// Prefix message with sender
-	message = fmt.Sprintf("[from %s] %s", sender, message)
-
-	// Check DND status for target (unless force flag or channel target)
+	// Check DND status for target (unless force flag). The nudge is held
+	// for the target's next digest instead.
 	townRoot, _ := workspace.FindFromCwd()
-	if townRoot != "" && !nudgeForceFlag && !strings.HasPrefix(target, "channel:") {
-		shouldSend, level, _ := shouldNudgeTarget(townRoot, target, nudgeForceFlag)
+	if townRoot != "" && !force {
+		shouldSend, level, _ := shouldNudgeTarget(townRoot, target, force)
 		if !shouldSend {
-			fmt.Printf("%s Target has DND enabled (%s) - nudge skipped\n", style.Dim.Render("○"), level)
+			item := mail.DigestItem{Kind: mail.DigestNudges, From: sender, Subject: message, At: time.Now()}
+			if err := mail.QueueDigest(townRoot, nudgeMailAddress(target), item); err != nil {
+				fmt.Printf("%s Target has DND enabled (%s) - nudge skipped\n", style.Dim.Render("○"), level)
+			} else {
+				fmt.Printf("%s Target has DND enabled (%s) - nudge held for their digest\n", style.Dim.Render("○"), level)
+			}
 			fmt.Printf("  Use %s to override\n", style.Bold.Render("--force"))
 			return nil
 		}
 	}
 
+	// Prefix message with sender
+	message = fmt.Sprintf("[from %s] %s", sender, message)
+
 	t := tmux.NewTmux()
 
 	// Expand role shortcuts to session names
@@ -131,17 +211,9 @@ func runNudge(cmd *cobra.Command, args []string) error {
 		target = session.MayorSessionName()
 	case "witness", "refinery":
 		// These need the current rig
-		roleInfo, err := GetRole()
-		if err != nil {
-			return fmt.Errorf("cannot determine rig for %s shortcut: %w", target, err)
-		}
-		if roleInfo.Rig == "" {
-			return fmt.Errorf("cannot determine rig for %s shortcut (not in a rig context)", target)
-		}
-		if target == "witness" {
-			target = session.WitnessSessionName(roleInfo.Rig)
-		} else {
-			target = session.RefinerySessionName(roleInfo.Rig)
+		var err error
+		if target, err = rigShortcutSession(target); err != nil {
+			return err
 		}
 	}
 
@@ -243,7 +315,7 @@ func runNudge(cmd *cobra.Command, args []string) error {
 }
 
 // runNudgeChannel nudges all members of a named channel.
-func runNudgeChannel(channelName, message string) error {
+func runNudgeChannel(channelName, sender, message string) error {
 	// Find town root
 	townRoot, err := workspace.FindFromCwdOrError()
 	if err != nil {
@@ -267,27 +339,6 @@ func runNudgeChannel(channelName, message string) error {
 		return fmt.Errorf("nudge channel %q has no members", channelName)
 	}
 
-	// Identify sender for message prefix
-	sender := "unknown"
-	if roleInfo, err := GetRole(); err == nil {
-		switch roleInfo.Role {
-		case RoleMayor:
-			sender = "mayor"
-		case RoleCrew:
-			sender = fmt.Sprintf("%s/crew/%s", roleInfo.Rig, roleInfo.Polecat)
-		case RolePolecat:
-			sender = fmt.Sprintf("%s/%s", roleInfo.Rig, roleInfo.Polecat)
-		case RoleWitness:
-			sender = fmt.Sprintf("%s/witness", roleInfo.Rig)
-		case RoleRefinery:
-			sender = fmt.Sprintf("%s/refinery", roleInfo.Rig)
-		case RoleDeacon:
-			sender = "deacon"
-		default:
-			sender = string(roleInfo.Role)
-		}
-	}
-
 	// Prefix message with sender
 	prefixedMessage := fmt.Sprintf("[from %s] %s", sender, message)
 
@@ -458,6 +509,16 @@ func shouldNudgeTarget(townRoot, targetAddress string, force bool) (bool, string
 	return level != beads.NotifyMuted, level, nil
 }
 
+// nudgeMailAddress converts a nudge target to the mail address of its
+// digest: "mayor" -> "mayor/", "gastown/alpha" stays as is.
+func nudgeMailAddress(target string) string {
+	switch target {
+	case "mayor", "deacon":
+		return target + "/"
+	}
+	return target
+}
+
 // addressToAgentBeadID converts a target address to an agent bead ID.
 // Examples:
 //   - "mayor" -> "gt-{town}-mayor"
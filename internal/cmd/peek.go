@@ -2,19 +2,31 @@ package cmd
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
 // Peek command flags
-var peekLines int
+var (
+	peekLines    int
+	peekFollow   bool
+	peekMatch    string
+	peekInterval time.Duration
+)
 
 func init() {
 	rootCmd.AddCommand(peekCmd)
 	peekCmd.Flags().IntVarP(&peekLines, "lines", "n", 100, "Number of lines to capture")
+	peekCmd.Flags().BoolVarP(&peekFollow, "follow", "f", false, "Stream new output until the session ends")
+	peekCmd.Flags().StringVar(&peekMatch, "match", "", "Highlight lines matching this regex")
+	peekCmd.Flags().DurationVar(&peekInterval, "interval", 500*time.Millisecond, "Poll interval in follow mode")
 }
 
 var peekCmd = &cobra.Command{
@@ -34,11 +46,18 @@ Supports both polecats and crew workers:
   - Polecats: rig/name format (e.g., greenplace/furiosa)
   - Crew: rig/crew/name format (e.g., beads/crew/dave)
 
+Use -f to keep streaming new output, like tail -f, until the session ends.
+Use --match to highlight lines matching a regular expression.
+
+For a session that has already died, see 'gt session replay' (requires
+session recording to be enabled for the rig).
+
 Examples:
   gt peek greenplace/furiosa         # Polecat: last 100 lines (default)
   gt peek greenplace/furiosa 50      # Polecat: last 50 lines
   gt peek beads/crew/dave            # Crew: last 100 lines
-  gt peek beads/crew/dave -n 200     # Crew: last 200 lines`,
+  gt peek beads/crew/dave -n 200     # Crew: last 200 lines
+  gt peek greenplace/furiosa -f --match 'error|FAIL'`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runPeek,
 }
@@ -61,27 +80,110 @@ func runPeek(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var match *regexp.Regexp
+	if peekMatch != "" {
+		match, err = regexp.Compile(peekMatch)
+		if err != nil {
+			return fmt.Errorf("invalid --match pattern: %w", err)
+		}
+	}
+
 	mgr, _, err := getSessionManager(rigName)
 	if err != nil {
 		return err
 	}
 
-	var output string
-
 	// Handle crew/ prefix for cross-rig crew workers
 	// e.g., "beads/crew/dave" -> session name "gt-beads-crew-dave"
+	var sessionID string
 	if strings.HasPrefix(polecatName, "crew/") {
 		crewName := strings.TrimPrefix(polecatName, "crew/")
-		sessionID := session.CrewSessionName(rigName, crewName)
-		output, err = mgr.CaptureSession(sessionID, lines)
+		sessionID = session.CrewSessionName(rigName, crewName)
 	} else {
-		output, err = mgr.Capture(polecatName, lines)
+		sessionID = mgr.SessionName(polecatName)
 	}
 
+	output, err := mgr.CaptureSession(sessionID, lines)
 	if err != nil {
 		return fmt.Errorf("capturing output: %w", err)
 	}
 
-	fmt.Print(output)
+	if !peekFollow && match == nil {
+		fmt.Print(output)
+		return nil
+	}
+
+	shown := trimTrailingBlank(strings.Split(output, "\n"))
+	printPeekLines(shown, match)
+	if !peekFollow {
+		return nil
+	}
+	return followPeek(tmux.NewTmux(), sessionID, lines, shown, match)
+}
+
+// followPeek polls a session's pane and prints lines that weren't on screen
+// at the previous poll, until the session ends.
+func followPeek(t *tmux.Tmux, sessionID string, lines int, prev []string, match *regexp.Regexp) error {
+	ticker := time.NewTicker(peekInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cur, err := t.CapturePaneLines(sessionID, lines)
+		if err != nil {
+			if running, _ := t.HasSession(sessionID); !running {
+				fmt.Printf("%s\n", style.Dim.Render("— session ended —"))
+				return nil
+			}
+			return fmt.Errorf("capturing output: %w", err)
+		}
+		cur = trimTrailingBlank(cur)
+		printPeekLines(newPeekLines(prev, cur), match)
+		prev = cur
+	}
 	return nil
 }
+
+// newPeekLines returns the lines of cur that follow the longest overlap
+// between the end of prev and the start of cur, i.e. what scrolled into view
+// since prev was captured. With no overlap (a redraw, or more than a
+// screenful of new output), all of cur is new.
+func newPeekLines(prev, cur []string) []string {
+	maxOverlap := len(prev)
+	if len(cur) < maxOverlap {
+		maxOverlap = len(cur)
+	}
+	for k := maxOverlap; k > 0; k-- {
+		if equalLines(prev[len(prev)-k:], cur[:k]) {
+			return cur[k:]
+		}
+	}
+	return cur
+}
+
+func equalLines(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// trimTrailingBlank drops the empty lines below the cursor that capture-pane
+// includes, so they don't count as output.
+func trimTrailingBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// printPeekLines prints lines, highlighting those that match.
+func printPeekLines(lines []string, match *regexp.Regexp) {
+	for _, line := range lines {
+		if match != nil && match.MatchString(line) {
+			line = style.Warning.Render(line)
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewPeekLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur []string
+		want      []string
+	}{
+		{"unchanged", []string{"a", "b"}, []string{"a", "b"}, nil},
+		{"appended", []string{"a", "b"}, []string{"a", "b", "c"}, []string{"c"}},
+		{"scrolled", []string{"a", "b", "c"}, []string{"b", "c", "d", "e"}, []string{"d", "e"}},
+		{"redrawn", []string{"a", "b"}, []string{"x", "y"}, []string{"x", "y"}},
+		{"first poll", nil, []string{"a"}, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newPeekLines(tt.prev, tt.cur)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newPeekLines(%q, %q) = %q, want %q", tt.prev, tt.cur, got, tt.want)
+			}
+		})
+	}
+}
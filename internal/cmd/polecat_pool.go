@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Polecat pool command flags
+var (
+	polecatPoolJSON     bool
+	polecatPoolFillJSON bool
+)
+
+var polecatPoolCmd = &cobra.Command{
+	Use:   "pool <rig>",
+	Short: "Show the warm pool of pre-provisioned polecats",
+	Long: `Show the rig's warm pool of pre-provisioned polecat worktrees.
+
+Spawning a polecat normally creates a worktree, copies the overlay and runs
+the rig's setup hooks (npm install, go mod download, ...) before the agent
+can start. A warm pool keeps N worktrees ready with all of that done and
+their names already allocated. gt sling claims one instantly and only has
+to cut the polecat's branch.
+
+The pool size is the rig config key polecat_warm_pool (default 0, off):
+  gt rig config set <rig> polecat_warm_pool 2
+
+The daemon refreshes and refills the pool on each heartbeat.
+
+Examples:
+  gt polecat pool gastown
+  gt polecat pool fill gastown
+  gt polecat pool drain gastown`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPolecatPool,
+}
+
+var polecatPoolFillCmd = &cobra.Command{
+	Use:   "fill <rig>",
+	Short: "Refresh and refill the warm pool",
+	Long: `Bring the warm pool up to date and up to size.
+
+Warm worktrees that have fallen behind origin/<default> are moved forward
+and have their setup hooks rerun. Then new worktrees are provisioned until
+the pool reaches polecat_warm_pool, or surplus ones are removed if the size
+was lowered.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPolecatPoolFill,
+}
+
+var polecatPoolDrainCmd = &cobra.Command{
+	Use:   "drain <rig>",
+	Short: "Remove all warm polecats",
+	Long: `Remove every warm worktree and release its name.
+
+The daemon refills the pool on its next heartbeat unless polecat_warm_pool
+is set to 0.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPolecatPoolDrain,
+}
+
+func init() {
+	polecatPoolCmd.Flags().BoolVar(&polecatPoolJSON, "json", false, "Output as JSON")
+	polecatPoolFillCmd.Flags().BoolVar(&polecatPoolFillJSON, "json", false, "Output as JSON")
+
+	polecatPoolCmd.AddCommand(polecatPoolFillCmd)
+	polecatPoolCmd.AddCommand(polecatPoolDrainCmd)
+	polecatCmd.AddCommand(polecatPoolCmd)
+}
+
+// PolecatPoolOutput is the JSON output of gt polecat pool.
+type PolecatPoolOutput struct {
+	Rig  string                 `json:"rig"`
+	Size int                    `json:"size"`
+	Warm []*polecat.WarmPolecat `json:"warm"`
+}
+
+// PolecatPoolFillOutput is the JSON output of gt polecat pool fill.
+type PolecatPoolFillOutput struct {
+	Rig       string `json:"rig"`
+	Size      int    `json:"size"`
+	Refreshed int    `json:"refreshed"`
+	Added     int    `json:"added"`
+	Warm      int    `json:"warm"`
+}
+
+func runPolecatPool(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+	mgr, _, err := getPolecatManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	warm, err := mgr.ListWarm()
+	if err != nil {
+		return fmt.Errorf("listing warm pool: %w", err)
+	}
+
+	if polecatPoolJSON {
+		out := PolecatPoolOutput{Rig: rigName, Size: mgr.WarmPoolSize(), Warm: warm}
+		if out.Warm == nil {
+			out.Warm = []*polecat.WarmPolecat{}
+		}
+		return outputJSON(out)
+	}
+
+	size := mgr.WarmPoolSize()
+	fmt.Printf("%s Warm pool for '%s': %d/%d\n\n", style.Bold.Render("♨"), rigName, len(warm), size)
+
+	if len(warm) == 0 {
+		if size == 0 {
+			fmt.Printf("  %s\n", style.Dim.Render("(disabled - set polecat_warm_pool to enable)"))
+		} else {
+			fmt.Printf("  %s\n", style.Dim.Render("(empty - run 'gt polecat pool fill "+rigName+"')"))
+		}
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "NAME", Width: 16},
+		style.Column{Name: "COMMIT", Width: 10},
+		style.Column{Name: "BEHIND", Width: 8, Align: style.AlignRight},
+		style.Column{Name: "WARMED", Width: 16},
+	)
+	for _, w := range warm {
+		behind := style.Success.Render("0")
+		if w.CommitsBehind > 0 {
+			behind = style.Warning.Render(fmt.Sprintf("%d", w.CommitsBehind))
+		}
+		commit := w.Commit
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		table.AddRow(w.Name, commit, behind, formatAge(w.WarmedAt))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runPolecatPoolFill(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+	mgr, _, err := getPolecatManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	refreshed, err := mgr.RefreshWarmPool()
+	if err != nil {
+		return fmt.Errorf("refreshing warm pool: %w", err)
+	}
+	added, err := mgr.FillWarmPool()
+	if err != nil {
+		return fmt.Errorf("filling warm pool: %w", err)
+	}
+	warm, _ := mgr.ListWarm()
+
+	if polecatPoolFillJSON {
+		return outputJSON(PolecatPoolFillOutput{
+			Rig:       rigName,
+			Size:      mgr.WarmPoolSize(),
+			Refreshed: refreshed,
+			Added:     added,
+			Warm:      len(warm),
+		})
+	}
+
+	fmt.Printf("%s Warm pool for '%s': %d/%d (refreshed %d, added %d)\n",
+		style.Bold.Render("✓"), rigName, len(warm), mgr.WarmPoolSize(), refreshed, added)
+	return nil
+}
+
+func runPolecatPoolDrain(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+	mgr, _, err := getPolecatManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	removed, err := mgr.DrainWarmPool()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed %d warm polecat(s) from '%s'\n", style.Bold.Render("✓"), removed, rigName)
+	return nil
+}
@@ -50,6 +50,7 @@ type SlingSpawnOptions struct {
 	Create   bool   // Create polecat if it doesn't exist (currently always true for sling)
 	HookBead string // Bead ID to set as hook_bead at spawn time (atomic assignment)
 	Agent    string // Agent override for this spawn (e.g., "gemini", "codex", "claude-haiku")
+	Name     string // Polecat name chosen by routing; empty allocates the next free name
 }
 
 // SpawnPolecatForSling creates a fresh polecat and optionally starts its session.
@@ -81,9 +82,35 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 	t := tmux.NewTmux()
 	polecatMgr := polecat.NewManager(r, polecatGit, t)
 
+	// Build add options with hook_bead set atomically at spawn time
+	addOpts := polecat.AddOptions{
+		HookBead: opts.HookBead,
+	}
+
+	// Claim a pre-provisioned worktree from the warm pool if one is ready.
+	// This skips worktree creation and setup hooks entirely. A routed name
+	// is claimed from the pool only if it is warm itself.
+	claimWarm := func() (*polecat.Polecat, error) { return polecatMgr.ClaimWarm(addOpts) }
+	if opts.Name != "" {
+		claimWarm = func() (*polecat.Polecat, error) { return polecatMgr.ClaimWarmNamed(opts.Name, addOpts) }
+	}
+	if warm, claimErr := claimWarm(); claimErr == nil {
+		fmt.Printf("Claimed warm polecat: %s\n", warm.Name)
+		return finishSpawnedPolecat(polecatMgr, t, r, rigName, warm.Name, opts)
+	} else if claimErr != polecat.ErrNoWarmPolecat {
+		fmt.Printf("Warning: could not claim warm polecat: %v\n", claimErr)
+	}
+
 	// Allocate a new polecat name
-	polecatName, err := polecatMgr.AllocateName()
-	if err != nil {
+	polecatName := opts.Name
+	if polecatName != "" {
+		if polecatName, err = polecatMgr.AllocatePreferred(opts.Name); err != nil {
+			return nil, fmt.Errorf("allocating polecat name: %w", err)
+		}
+		if polecatName != opts.Name {
+			fmt.Printf("Polecat name %s was taken meanwhile\n", opts.Name)
+		}
+	} else if polecatName, err = polecatMgr.AllocateName(); err != nil {
 		return nil, fmt.Errorf("allocating polecat name: %w", err)
 	}
 	fmt.Printf("Allocated polecat: %s\n", polecatName)
@@ -91,11 +118,6 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 	// Check if polecat already exists (shouldn't happen - indicates stale state needing repair)
 	existingPolecat, err := polecatMgr.Get(polecatName)
 
-	// Build add options with hook_bead set atomically at spawn time
-	addOpts := polecat.AddOptions{
-		HookBead: opts.HookBead,
-	}
-
 	if err == nil {
 		// Stale state: polecat exists despite fresh name allocation - repair it
 		// Check for uncommitted work first
@@ -134,6 +156,12 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		return nil, fmt.Errorf("getting polecat: %w", err)
 	}
 
+	return finishSpawnedPolecat(polecatMgr, t, r, rigName, polecatName, opts)
+}
+
+// finishSpawnedPolecat verifies a freshly created or claimed polecat and
+// returns its spawn info with session start deferred.
+func finishSpawnedPolecat(polecatMgr *polecat.Manager, t *tmux.Tmux, r *rig.Rig, rigName, polecatName string, opts SlingSpawnOptions) (*SpawnedPolecatInfo, error) {
 	// Get polecat object for path info
 	polecatObj, err := polecatMgr.Get(polecatName)
 	if err != nil {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -217,6 +218,27 @@ Examples:
 
 var refineryBlockedJSON bool
 
+var refineryResumeCmd = &cobra.Command{
+	Use:   "resume [rig]",
+	Short: "Resume a merge queue paused by post-merge verification",
+	Long: `Resume a merge queue that was paused because a landed merge broke the target.
+
+When merge_queue.post_merge_verify is enabled, the Refinery runs the verify
+command on the target branch after every push. If it fails, the merge is
+reverted and the queue pauses so no further MRs land on a broken target.
+
+This command re-runs the verify command on the latest target branch and
+resumes the queue only if it passes. Use --force to resume without verifying.
+
+Examples:
+  gt refinery resume greenplace
+  gt refinery resume --force`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryResume,
+}
+
+var refineryResumeForce bool
+
 func init() {
 	// Start flags
 	refineryStartCmd.Flags().BoolVar(&refineryForeground, "foreground", false, "Run in foreground (default: background)")
@@ -243,6 +265,9 @@ func init() {
 	// Blocked flags
 	refineryBlockedCmd.Flags().BoolVar(&refineryBlockedJSON, "json", false, "Output as JSON")
 
+	// Resume flags
+	refineryResumeCmd.Flags().BoolVar(&refineryResumeForce, "force", false, "Resume without re-running verification")
+
 	// Add subcommands
 	refineryCmd.AddCommand(refineryStartCmd)
 	refineryCmd.AddCommand(refineryStopCmd)
@@ -255,6 +280,7 @@ func init() {
 	refineryCmd.AddCommand(refineryUnclaimedCmd)
 	refineryCmd.AddCommand(refineryReadyCmd)
 	refineryCmd.AddCommand(refineryBlockedCmd)
+	refineryCmd.AddCommand(refineryResumeCmd)
 
 	rootCmd.AddCommand(refineryCmd)
 }
@@ -698,6 +724,12 @@ func runRefineryReady(cmd *cobra.Command, args []string) error {
 	// Human-readable output
 	fmt.Printf("%s Ready MRs for '%s':\n\n", style.Bold.Render("🚀"), rigName)
 
+	if state, err := eng.PauseState(); err == nil && state != nil {
+		fmt.Printf("  %s Queue paused: %s\n", style.Warning.Render("⏸"), state.Reason)
+		fmt.Printf("  %s\n", style.Dim.Render("Fix "+state.Target+", then run: gt refinery resume "+rigName))
+		return nil
+	}
+
 	if len(ready) == 0 {
 		fmt.Printf("  %s\n", style.Dim.Render("(none ready)"))
 		return nil
@@ -758,3 +790,45 @@ func runRefineryBlocked(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runRefineryResume(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	state, err := eng.PauseState()
+	if err != nil {
+		return fmt.Errorf("reading pause state: %w", err)
+	}
+	if state == nil {
+		fmt.Printf("%s Merge queue for '%s' is not paused\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+
+	if refineryResumeForce {
+		if err := refinery.ResumeQueue(r.Path); err != nil {
+			return fmt.Errorf("resuming queue: %w", err)
+		}
+		fmt.Printf("%s Merge queue resumed for '%s' (verification skipped)\n", style.Bold.Render("✓"), rigName)
+		return nil
+	}
+
+	result := eng.VerifyTarget(context.Background())
+	if !result.Success {
+		return fmt.Errorf("target still failing verification, queue remains paused: %s", result.Error)
+	}
+
+	fmt.Printf("%s Merge queue resumed for '%s'\n", style.Bold.Render("✓"), rigName)
+	return nil
+}
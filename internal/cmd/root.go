@@ -61,7 +61,9 @@ var beadsExemptCommands = map[string]bool{
 	"install":    true,
 	"tap":        true,
 	"dnd":        true,
+	"schedule":   true,
 	"krc":        true, // KRC doesn't require beads
+	"mock-agent": true, // Warnings would pollute the scripted pane output
 }
 
 // Commands exempt from the town root branch warning.
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/schedule"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Schedule command flags
+var (
+	scheduleListJSON bool
+	scheduleRunJSON  bool
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:     "schedule",
+	GroupID: GroupComm,
+	Short:   "Manage scheduled mail and nudges",
+	RunE:    requireSubcommand,
+	Long: `Manage mail and nudges scheduled with --at, --in or --cron.
+
+gt mail send and gt nudge take scheduling flags that store the message
+instead of sending it:
+
+  --at <time>     Once, at 15:04 (today, or tomorrow if past),
+                  2006-01-02 15:04, or an RFC3339 timestamp
+  --in <delay>    Once, after a delay like 30m, 2h or 1d
+  --cron <expr>   Repeatedly, on a five-field cron schedule
+                  (minute hour day month weekday), e.g. "0 9 * * 1-5"
+
+The daemon delivers due entries on each heartbeat, so delivery happens
+within a heartbeat of the scheduled time. Entries are kept in the town
+runtime directory and survive daemon restarts: anything that came due
+while the daemon was down is delivered once when it comes back. A
+failed delivery is retried on later heartbeats, up to 5 times.
+
+Examples:
+  gt nudge witness "Re-check polecat nux" --in 2h
+  gt mail send mayor/ -s "Standup" -m "Post your standup" --cron "0 9 * * 1-5"
+  gt schedule list
+  gt schedule cancel sch-1a2b3c4d`,
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scheduled mail and nudges",
+	Args:  cobra.NoArgs,
+	RunE:  runScheduleList,
+}
+
+var scheduleCancelCmd = &cobra.Command{
+	Use:   "cancel <id>...",
+	Short: "Cancel scheduled mail or nudges",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runScheduleCancel,
+}
+
+var scheduleRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Deliver scheduled mail and nudges that are due",
+	Long: `Deliver scheduled mail and nudges that are due.
+
+The daemon runs this on every heartbeat; run it by hand to deliver due
+entries without waiting for the next one.`,
+	Args: cobra.NoArgs,
+	RunE: runScheduleRun,
+}
+
+func init() {
+	scheduleListCmd.Flags().BoolVar(&scheduleListJSON, "json", false, "Output as JSON")
+	scheduleRunCmd.Flags().BoolVar(&scheduleRunJSON, "json", false, "Output as JSON")
+
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleCancelCmd)
+	scheduleCmd.AddCommand(scheduleRunCmd)
+	rootCmd.AddCommand(scheduleCmd)
+}
+
+// parseScheduleFlags turns --at, --in and --cron into the first delivery
+// time. scheduled is false when none is set and the send is immediate.
+func parseScheduleFlags(at, in, cron string, now time.Time) (next time.Time, scheduled bool, err error) {
+	set := 0
+	for _, v := range []string{at, in, cron} {
+		if v != "" {
+			set++
+		}
+	}
+	switch {
+	case set == 0:
+		return time.Time{}, false, nil
+	case set > 1:
+		return time.Time{}, false, fmt.Errorf("--at, --in and --cron are mutually exclusive")
+	case at != "":
+		next, err = parseAtTime(at, now)
+	case in != "":
+		var d time.Duration
+		if d, err = parseDuration(in); err == nil && d <= 0 {
+			err = fmt.Errorf("--in must be positive")
+		}
+		next = now.Add(d)
+	case cron != "":
+		var c *schedule.Cron
+		if c, err = schedule.ParseCron(cron); err == nil {
+			if next = c.Next(now); next.IsZero() {
+				err = fmt.Errorf("cron %q never fires", cron)
+			}
+		}
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return next, true, nil
+}
+
+// parseAtTime parses an --at value in local time: an RFC3339 timestamp,
+// "2006-01-02 15:04", or "15:04" meaning the next time the clock reads it.
+func parseAtTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("15:04", s, now.Location()); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --at %q: use 15:04, 2006-01-02 15:04, or RFC3339", s)
+}
+
+// printScheduled confirms a newly scheduled entry.
+func printScheduled(e *schedule.Entry) {
+	fmt.Printf("%s Scheduled %s to %s (%s)\n", style.Bold.Render("✓"), e.Kind, e.Target(), e.ID)
+	fmt.Printf("  Next: %s\n", e.Next.Format("2006-01-02 15:04 MST"))
+	if e.Cron != "" {
+		fmt.Printf("  Repeats: %s\n", e.Cron)
+	}
+	if e.Nudge != nil && e.Nudge.Force {
+		fmt.Printf("  Force: sent even if the target has DND enabled\n")
+	}
+	fmt.Printf("  %s\n", style.Dim.Render("Cancel with: gt schedule cancel "+e.ID))
+}
+
+func runScheduleList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	entries, err := schedule.List(townRoot)
+	if err != nil {
+		return err
+	}
+
+	if scheduleListJSON {
+		if entries == nil {
+			entries = []*schedule.Entry{}
+		}
+		return outputJSON(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No scheduled mail or nudges.")
+		return nil
+	}
+	for _, e := range entries {
+		when := e.Next.Format("2006-01-02 15:04")
+		if e.Cron != "" {
+			when += "  " + style.Dim.Render("cron "+e.Cron)
+		}
+		if e.Nudge != nil && e.Nudge.Force {
+			when += "  " + style.Dim.Render("force")
+		}
+		fmt.Printf("%s  %-5s  %s  %s\n", style.Bold.Render(e.ID), e.Kind, e.Target(), when)
+		fmt.Printf("    %s\n", truncateScheduleSummary(e.Summary()))
+		if e.LastError != "" {
+			fmt.Printf("    %s\n", style.Dim.Render(fmt.Sprintf("last error (%d attempt(s)): %s", e.Attempts, e.LastError)))
+		}
+	}
+	return nil
+}
+
+// truncateScheduleSummary keeps list output to one line per entry.
+func truncateScheduleSummary(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > 72 {
+		return s[:69] + "..."
+	}
+	return s
+}
+
+func runScheduleCancel(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	var failed int
+	for _, id := range args {
+		e, err := schedule.Cancel(townRoot, id)
+		if err != nil {
+			if errors.Is(err, schedule.ErrNotFound) {
+				style.PrintWarning("no scheduled entry %s", id)
+			} else {
+				style.PrintWarning("cancelling %s: %v", id, err)
+			}
+			failed++
+			continue
+		}
+		fmt.Printf("%s Cancelled %s %s to %s\n", style.Bold.Render("✓"), e.ID, e.Kind, e.Target())
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d cancellation(s) failed", failed)
+	}
+	return nil
+}
+
+func runScheduleRun(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	fired, err := schedule.Run(townRoot, time.Now(), deliverScheduled)
+	if err != nil {
+		return err
+	}
+
+	if scheduleRunJSON {
+		if fired == nil {
+			fired = []schedule.Fired{}
+		}
+		return outputJSON(map[string]interface{}{"fired": fired})
+	}
+
+	if len(fired) == 0 {
+		fmt.Println("Nothing due.")
+		return nil
+	}
+	for _, f := range fired {
+		switch {
+		case f.Interrupted:
+			style.PrintWarning("%s %s to %s was interrupted by an earlier run; not repeated", f.ID, f.Kind, f.Target)
+		case f.Dropped:
+			style.PrintWarning("%s %s to %s dropped after %d failed attempts: %s", f.ID, f.Kind, f.Target, schedule.MaxAttempts, f.Error)
+		case f.Error != "":
+			style.PrintWarning("%s %s to %s failed, will retry: %s", f.ID, f.Kind, f.Target, f.Error)
+		default:
+			fmt.Printf("%s Delivered %s %s to %s\n", style.Bold.Render("✓"), f.ID, f.Kind, f.Target)
+		}
+	}
+	return nil
+}
+
+// deliverScheduled sends one occurrence of a scheduled entry. Mail is
+// copied so per-delivery fields like the thread ID are not saved back to
+// a recurring entry.
+func deliverScheduled(e *schedule.Entry) error {
+	switch {
+	case e.Mail != nil:
+		workDir, err := findMailWorkDir()
+		if err != nil {
+			return err
+		}
+		msg := *e.Mail
+		_, err = deliverMail(workDir, &msg)
+		return err
+	case e.Nudge != nil:
+		n := e.Nudge
+		return sendNudge(n.Target, n.Sender, n.Message, n.Force)
+	}
+	return fmt.Errorf("entry has no mail or nudge")
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleFlags(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 30, 0, 0, time.Local)
+	tests := []struct {
+		name          string
+		at, in, cron  string
+		want          time.Time
+		wantScheduled bool
+		wantErr       bool
+	}{
+		{name: "immediate"},
+		{name: "at later today", at: "17:00", want: time.Date(2026, 10, 16, 17, 0, 0, 0, time.Local), wantScheduled: true},
+		{name: "at past rolls to tomorrow", at: "09:00", want: time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local), wantScheduled: true},
+		{name: "at date", at: "2026-12-24 08:15", want: time.Date(2026, 12, 24, 8, 15, 0, 0, time.Local), wantScheduled: true},
+		{name: "at rfc3339", at: "2026-10-20T10:00:00Z", want: time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC), wantScheduled: true},
+		{name: "in hours", in: "2h", want: now.Add(2 * time.Hour), wantScheduled: true},
+		{name: "in days", in: "1d", want: now.Add(24 * time.Hour), wantScheduled: true},
+		{name: "cron", cron: "0 9 * * 1-5", want: time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local), wantScheduled: true},
+		{name: "bad at", at: "tomorrow", wantErr: true},
+		{name: "negative in", in: "-5m", wantErr: true},
+		{name: "bad cron", cron: "0 9 * *", wantErr: true},
+		{name: "exclusive", at: "17:00", in: "2h", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, scheduled, err := parseScheduleFlags(tt.at, tt.in, tt.cron, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if scheduled != tt.wantScheduled || !got.Equal(tt.want) {
+				t.Errorf("parseScheduleFlags() = %v, %v; want %v, %v", got, scheduled, tt.want, tt.wantScheduled)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/search"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Search command flags
+var (
+	searchSources  []string
+	searchRigs     []string
+	searchActors   []string
+	searchSince    string
+	searchUntil    string
+	searchLimit    int
+	searchJSON     bool
+	searchRebuild  bool
+	searchNoUpdate bool
+	searchStats    bool
+)
+
+var searchCmd = &cobra.Command{
+	Use:     "search <query>...",
+	GroupID: GroupWork,
+	Short:   "Search beads, mail, handoffs, events and transcripts",
+	Long: `Search everything the town has written down, ranked by relevance.
+
+Covers bead titles and descriptions in every rig, mail, handoff notes
+(handoff beads and HANDOFF mail), event payloads and, when enabled in town
+settings ("search": {"transcripts": true}), agent session transcripts.
+
+The index lives in .runtime/search/ and is brought up to date before each
+search: new events are indexed, and the beads databases they touched are
+read again. Use --rebuild to start over.
+
+Every word must match. "Quoted phrases" must appear as written, and a
+trailing * matches a prefix. Results show a snippet around the first match.
+
+--source, --rig and --actor can be repeated. --rig town selects town-level
+documents (mayor, deacon, town beads); --actor takes globs. --since and
+--until take a duration back from now (30m, 24h, 7d), a date or an
+RFC3339 time.
+
+Examples:
+  gt search auth refactor
+  gt search '"auth refactor"' --source mail --source handoff
+  gt search flak* --rig gastown --since 7d
+  gt search oauth --actor 'gastown/polecats/*' --json
+  gt search --stats
+  gt search --rebuild`,
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().StringArrayVar(&searchSources, "source", nil, "Only this source: "+strings.Join(search.Sources, ", ")+" (repeatable)")
+	searchCmd.Flags().StringArrayVar(&searchRigs, "rig", nil, "Only this rig, or town (repeatable)")
+	searchCmd.Flags().StringArrayVar(&searchActors, "actor", nil, "Only documents by or for this actor glob (repeatable)")
+	searchCmd.Flags().StringVar(&searchSince, "since", "", "Start: duration ago (24h, 7d), date or RFC3339 time")
+	searchCmd.Flags().StringVar(&searchUntil, "until", "", "End: duration ago, date or RFC3339 time")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Show at most N results (0 for all)")
+	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "Output as JSON")
+	searchCmd.Flags().BoolVar(&searchRebuild, "rebuild", false, "Rebuild the index from scratch")
+	searchCmd.Flags().BoolVar(&searchNoUpdate, "no-update", false, "Search the index as it is, without updating it")
+	searchCmd.Flags().BoolVar(&searchStats, "stats", false, "Show index statistics")
+
+	rootCmd.AddCommand(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if len(args) == 0 && !searchStats && !searchRebuild {
+		return fmt.Errorf("missing query (see gt search --help)")
+	}
+	for _, s := range searchSources {
+		if !searchHasSource(s) {
+			return fmt.Errorf("unknown source %q (valid: %s)", s, strings.Join(search.Sources, ", "))
+		}
+	}
+
+	now := time.Now()
+	q := search.Query{
+		Text:    strings.Join(args, " "),
+		Sources: searchSources,
+		Rigs:    searchRigs,
+		Actors:  searchActors,
+		Limit:   searchLimit,
+	}
+	if q.Since, err = parseEventTime(searchSince, now); err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	if q.Until, err = parseEventTime(searchUntil, now); err != nil {
+		return fmt.Errorf("--until: %w", err)
+	}
+
+	var idx *search.Index
+	if searchNoUpdate && !searchRebuild {
+		if idx, err = search.Load(townRoot); err != nil {
+			return err
+		}
+	} else {
+		settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+		if err != nil {
+			return fmt.Errorf("loading town settings: %w", err)
+		}
+		opts := search.UpdateOptions{
+			Rebuild:     searchRebuild,
+			Transcripts: settings.Search != nil && settings.Search.Transcripts,
+			Now:         now,
+		}
+		var result *search.UpdateResult
+		if idx, result, err = search.Update(townRoot, opts); err != nil {
+			return err
+		}
+		for _, e := range result.Errors {
+			fmt.Fprintf(os.Stderr, "%s could not index %s\n", style.WarningPrefix, e)
+		}
+		if searchRebuild && !searchJSON {
+			fmt.Printf("%s Indexed %d documents\n", style.Success.Render("✓"), idx.Len())
+		}
+	}
+
+	if searchStats {
+		return printSearchStats(idx.Stats(townRoot))
+	}
+	if q.Text == "" {
+		return nil
+	}
+
+	results := idx.Search(q, now)
+	if searchJSON {
+		if results == nil {
+			results = []*search.Result{}
+		}
+		return outputJSON(results)
+	}
+	if len(results) == 0 {
+		fmt.Println("No matches.")
+		return nil
+	}
+	for i, r := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		printSearchResult(r)
+	}
+	return nil
+}
+
+func searchHasSource(s string) bool {
+	for _, source := range search.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// printSearchResult prints a result's title line, where it came from, and
+// its snippet with the matching words in bold.
+func printSearchResult(r *search.Result) {
+	title := r.Title
+	if r.Source == search.SourceTranscript {
+		title = r.Title + " message"
+	}
+	fmt.Printf("%s %s\n", style.Dim.Render("["+r.Source+"]"),
+		search.Highlight(title, r.Terms, func(s string) string { return style.Bold.Render(s) }))
+
+	var meta []string
+	if r.Ref != "" && r.Ref != r.Title {
+		meta = append(meta, r.Ref)
+	}
+	if len(r.Actors) > 0 {
+		meta = append(meta, strings.Join(r.Actors, " → "))
+	}
+	if r.Rig != "" {
+		meta = append(meta, "rig "+r.Rig)
+	}
+	if !r.Time.IsZero() {
+		meta = append(meta, formatAge(r.Time))
+	}
+	fmt.Printf("    %s\n", style.Dim.Render(strings.Join(meta, " · ")))
+	if r.Snippet != "" && r.Snippet != r.Title {
+		fmt.Printf("    %s\n", search.Highlight(r.Snippet, r.Terms, func(s string) string { return style.Bold.Render(s) }))
+	}
+}
+
+func printSearchStats(stats *search.Stats) error {
+	if searchJSON {
+		return outputJSON(stats)
+	}
+	fmt.Printf("%s %d documents, %d terms, %s on disk\n", style.Bold.Render("Search index:"),
+		stats.Docs, stats.Terms, formatBytes(stats.Bytes))
+	sources := make([]string, 0, len(stats.BySource))
+	for s := range stats.BySource {
+		sources = append(sources, s)
+	}
+	sort.Strings(sources)
+	for _, s := range sources {
+		fmt.Printf("  %-12s %d\n", s, stats.BySource[s])
+	}
+	if !stats.Updated.IsZero() {
+		fmt.Printf("%s\n", style.Dim.Render(fmt.Sprintf("Built %s, updated %s",
+			stats.Built.Local().Format("2006-01-02 15:04"), formatAge(stats.Updated))))
+	}
+	return nil
+}
@@ -0,0 +1,455 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/secrets"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+	"golang.org/x/term"
+)
+
+// Secrets command flags
+var (
+	secretsRig   string
+	secretsRole  string
+	secretsFile  string
+	secretsValue string
+
+	secretsListJSON bool
+
+	secretsAuditSecret string
+	secretsAuditLimit  int
+	secretsAuditJSON   bool
+)
+
+// secretRoles are the roles secrets can be delivered to.
+var secretRoles = []string{"polecat", "crew"}
+
+var secretsCmd = &cobra.Command{
+	Use:     "secrets",
+	GroupID: GroupConfig,
+	Short:   "Manage encrypted secrets for agent worktrees",
+	RunE:    requireSubcommand,
+	Long: `Manage the town's encrypted secret store.
+
+Secrets are encrypted with AES-256-GCM under a local key file
+(.runtime/secrets/key, or $GT_SECRETS_KEY_FILE to keep it outside the town)
+and scoped by rig and role. Unlike overlay files, secrets are delivered only
+to the agents they're scoped to, and every delivery is audited:
+
+  env secrets    Exported into the agent's session environment at start.
+                 Values pass through a private file that is sourced and
+                 deleted, so they never appear on the command line.
+  file secrets   Written into the worktree (mode 0600, git-excluded) at
+                 spawn and scrubbed when the polecat is nuked or the crew
+                 member removed.
+
+When a name is set at several scopes, the most specific wins:
+rig+role, then rig, then role, then town-wide.
+
+Commands:
+  gt secrets set <name>       Add a secret (value from stdin or prompt)
+  gt secrets list             List secrets (never shows values)
+  gt secrets rotate <name>    Replace a secret's value
+  gt secrets revoke <name>    Delete a secret and scrub its files
+  gt secrets audit            Show the access log`,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Add a secret",
+	Long: `Add a secret to the store.
+
+The value is read from stdin, or prompted for when stdin is a terminal.
+--value is accepted for scripting but leaves the value in shell history.
+
+Without --file the secret is exported as the environment variable <name>.
+With --file it is written to that path inside the worktree.
+
+Examples:
+  gt secrets set GITHUB_TOKEN --rig gastown --role polecat
+  echo "$KEY" | gt secrets set OPENAI_API_KEY
+  gt secrets set app-env --rig gastown --file .env < .env.production`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsSet,
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List secrets",
+	Long: `List secrets with their scope and delivery. Values are never shown.
+
+With --rig and/or --role, shows the secrets an agent with that scope
+would receive.`,
+	Args: cobra.NoArgs,
+	RunE: runSecretsList,
+}
+
+var secretsRotateCmd = &cobra.Command{
+	Use:   "rotate <name>",
+	Short: "Replace a secret's value",
+	Long: `Replace a secret's value and bump its version.
+
+File secrets are rewritten in every worktree that holds the old version.
+Env secrets reach running sessions only when they restart.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsRotate,
+}
+
+var secretsRevokeCmd = &cobra.Command{
+	Use:   "revoke <name>",
+	Short: "Delete a secret and scrub its files",
+	Long: `Delete a secret from the store and remove its file from every worktree
+it was delivered to.
+
+Env secrets already exported to running sessions can't be recalled;
+restart those sessions and rotate the credential upstream.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsRevoke,
+}
+
+var secretsAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the secrets access log",
+	Long:  `Show who set, rotated, revoked, received or scrubbed each secret.`,
+	Args:  cobra.NoArgs,
+	RunE:  runSecretsAudit,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{secretsSetCmd, secretsListCmd, secretsRotateCmd, secretsRevokeCmd} {
+		c.Flags().StringVar(&secretsRig, "rig", "", "Scope to a rig (default: all rigs)")
+		c.Flags().StringVar(&secretsRole, "role", "", "Scope to a role: polecat or crew (default: all roles)")
+	}
+	secretsSetCmd.Flags().StringVar(&secretsFile, "file", "", "Deliver as a file at this worktree-relative path")
+	secretsSetCmd.Flags().StringVar(&secretsValue, "value", "", "Secret value (default: read from stdin)")
+	secretsRotateCmd.Flags().StringVar(&secretsValue, "value", "", "New value (default: read from stdin)")
+	secretsListCmd.Flags().BoolVar(&secretsListJSON, "json", false, "Output as JSON")
+	secretsAuditCmd.Flags().StringVar(&secretsAuditSecret, "secret", "", "Only show entries for this secret")
+	secretsAuditCmd.Flags().IntVarP(&secretsAuditLimit, "limit", "n", 50, "Show the last N entries (0 for all)")
+	secretsAuditCmd.Flags().BoolVar(&secretsAuditJSON, "json", false, "Output as JSON")
+
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsRotateCmd)
+	secretsCmd.AddCommand(secretsRevokeCmd)
+	secretsCmd.AddCommand(secretsAuditCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+// secretsScope validates --rig and --role against the town.
+func secretsScope(townRoot string) error {
+	if secretsRig != "" {
+		if info, err := os.Stat(filepath.Join(townRoot, secretsRig)); err != nil || !info.IsDir() {
+			return fmt.Errorf("rig '%s' not found", secretsRig)
+		}
+	}
+	if secretsRole != "" {
+		for _, r := range secretRoles {
+			if secretsRole == r {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid role %q: must be one of %s", secretsRole, strings.Join(secretRoles, ", "))
+	}
+	return nil
+}
+
+// readSecretValue returns --value, or reads the value from stdin. On a
+// terminal it prompts without echo.
+func readSecretValue(name string) ([]byte, error) {
+	if secretsValue != "" {
+		return []byte(secretsValue), nil
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("reading value: %w", err)
+		}
+		if len(value) == 0 {
+			return nil, fmt.Errorf("empty value")
+		}
+		return value, nil
+	}
+
+	value, err := io.ReadAll(bufio.NewReader(os.Stdin))
+	if err != nil {
+		return nil, fmt.Errorf("reading value from stdin: %w", err)
+	}
+	// Env values come from echo/heredocs; drop the trailing newline.
+	// File secrets are stored byte for byte.
+	if secretsFile == "" {
+		value = []byte(strings.TrimRight(string(value), "\r\n"))
+	}
+	if len(value) == 0 {
+		return nil, fmt.Errorf("empty value on stdin")
+	}
+	return value, nil
+}
+
+func runSecretsSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if err := secretsScope(townRoot); err != nil {
+		return err
+	}
+
+	value, err := readSecretValue(name)
+	if err != nil {
+		return err
+	}
+
+	var sec *secrets.Secret
+	err = secrets.Update(townRoot, func(s *secrets.Store) error {
+		var setErr error
+		sec, setErr = s.Set(name, secretsRig, secretsRole, secretsFile, value)
+		return setErr
+	})
+	if errors.Is(err, secrets.ErrSecretExists) {
+		return fmt.Errorf("secret %s already exists at this scope; use 'gt secrets rotate' to change it", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	entry := secrets.AuditEntry{Action: secrets.ActionSet, Secret: sec.Name, Scope: sec.ScopeLabel(), Version: sec.Version, Actor: detectActor()}
+	_ = secrets.Audit(townRoot, entry)
+
+	fmt.Printf("%s Set secret %s (%s, %s)\n", style.Bold.Render("✓"), sec.Name, sec.ScopeLabel(), sec.Delivery())
+	fmt.Printf("  %s\n", style.Dim.Render("Delivered to agents spawned from now on"))
+	return nil
+}
+
+// SecretListItem is the JSON form of a secret. Values are never included.
+type SecretListItem struct {
+	Name      string `json:"name"`
+	Rig       string `json:"rig,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Delivery  string `json:"delivery"`
+	Version   int    `json:"version"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func runSecretsList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if err := secretsScope(townRoot); err != nil {
+		return err
+	}
+
+	store, err := secrets.Load(townRoot)
+	if err != nil {
+		return err
+	}
+
+	list := store.Secrets
+	if secretsRig != "" || secretsRole != "" {
+		list = store.Resolve(secretsRig, secretsRole)
+	}
+
+	if secretsListJSON {
+		items := make([]SecretListItem, 0, len(list))
+		for _, sec := range list {
+			items = append(items, SecretListItem{
+				Name:      sec.Name,
+				Rig:       sec.Rig,
+				Role:      sec.Role,
+				Delivery:  sec.Delivery(),
+				Version:   sec.Version,
+				UpdatedAt: sec.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			})
+		}
+		return outputJSON(items)
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No secrets.")
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "NAME", Width: 24},
+		style.Column{Name: "SCOPE", Width: 20},
+		style.Column{Name: "DELIVERY", Width: 20},
+		style.Column{Name: "VER", Width: 4, Align: style.AlignRight},
+		style.Column{Name: "UPDATED", Width: 16},
+	)
+	for _, sec := range list {
+		table.AddRow(sec.Name, sec.ScopeLabel(), sec.Delivery(), fmt.Sprintf("%d", sec.Version), formatAge(sec.UpdatedAt))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runSecretsRotate(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if err := secretsScope(townRoot); err != nil {
+		return err
+	}
+
+	store, err := secrets.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	existing := store.Find(name, secretsRig, secretsRole)
+	if existing == nil {
+		return fmt.Errorf("secret %s not found at scope %s", name, scopeLabel(secretsRig, secretsRole))
+	}
+	secretsFile = existing.File // Keep file secrets byte for byte
+
+	value, err := readSecretValue(name)
+	if err != nil {
+		return err
+	}
+
+	var sec *secrets.Secret
+	err = secrets.Update(townRoot, func(s *secrets.Store) error {
+		var rotErr error
+		sec, rotErr = s.Rotate(name, secretsRig, secretsRole, value)
+		store = s
+		return rotErr
+	})
+	if err != nil {
+		return err
+	}
+
+	entry := secrets.AuditEntry{Action: secrets.ActionRotate, Secret: sec.Name, Scope: sec.ScopeLabel(), Version: sec.Version, Actor: detectActor()}
+	_ = secrets.Audit(townRoot, entry)
+
+	fmt.Printf("%s Rotated secret %s (%s) to version %d\n", style.Bold.Render("✓"), sec.Name, sec.ScopeLabel(), sec.Version)
+
+	if sec.File != "" {
+		n, err := secrets.Redeliver(townRoot, store, sec)
+		if err != nil {
+			return fmt.Errorf("rewriting delivered files: %w", err)
+		}
+		fmt.Printf("  Rewrote %d delivered file(s)\n", n)
+	} else {
+		fmt.Printf("  %s\n", style.Dim.Render("Running sessions keep the old value until they restart"))
+	}
+	return nil
+}
+
+func runSecretsRevoke(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if err := secretsScope(townRoot); err != nil {
+		return err
+	}
+
+	var sec *secrets.Secret
+	err = secrets.Update(townRoot, func(s *secrets.Store) error {
+		var revErr error
+		sec, revErr = s.Revoke(name, secretsRig, secretsRole)
+		return revErr
+	})
+	if errors.Is(err, secrets.ErrSecretNotFound) {
+		return fmt.Errorf("secret %s not found at scope %s", name, scopeLabel(secretsRig, secretsRole))
+	}
+	if err != nil {
+		return err
+	}
+
+	entry := secrets.AuditEntry{Action: secrets.ActionRevoke, Secret: sec.Name, Scope: sec.ScopeLabel(), Version: sec.Version, Actor: detectActor()}
+	_ = secrets.Audit(townRoot, entry)
+
+	fmt.Printf("%s Revoked secret %s (%s)\n", style.Bold.Render("✓"), sec.Name, sec.ScopeLabel())
+
+	if sec.File != "" {
+		n, err := secrets.ScrubSecret(townRoot, sec)
+		if err != nil {
+			return fmt.Errorf("scrubbing delivered files: %w", err)
+		}
+		fmt.Printf("  Scrubbed %d delivered file(s)\n", n)
+	} else {
+		fmt.Printf("  %s\n", style.Warning.Render("Running sessions still hold the value; restart them and rotate the credential upstream"))
+	}
+	return nil
+}
+
+func runSecretsAudit(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	all, err := secrets.ReadAudit(townRoot)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]secrets.AuditEntry, 0, len(all))
+	for _, e := range all {
+		if secretsAuditSecret == "" || e.Secret == secretsAuditSecret {
+			entries = append(entries, e)
+		}
+	}
+	if secretsAuditLimit > 0 && len(entries) > secretsAuditLimit {
+		entries = entries[len(entries)-secretsAuditLimit:]
+	}
+
+	if secretsAuditJSON {
+		return outputJSON(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No secrets activity.")
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "TIME", Width: 20},
+		style.Column{Name: "ACTION", Width: 12},
+		style.Column{Name: "SECRET", Width: 24},
+		style.Column{Name: "SCOPE", Width: 18},
+		style.Column{Name: "WHO", Width: 32},
+	)
+	for _, e := range entries {
+		who := e.Agent
+		if who == "" {
+			who = e.Actor
+		}
+		table.AddRow(e.Time.Local().Format("2006-01-02 15:04:05"), e.Action, fmt.Sprintf("%s v%d", e.Secret, e.Version), e.Scope, who)
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// withSessionSecrets wraps a session command so the agent starts with its
+// env secrets exported. Failures are warned about, never fatal. Call discard
+// if the session fails to start, so the env file doesn't linger.
+func withSessionSecrets(townRoot, rigName, role, agent, command string) (wrapped string, discard func()) {
+	envFile, err := secrets.SessionEnvFile(townRoot, rigName, role, agent)
+	if err != nil {
+		style.PrintWarning("could not export secrets: %v", err)
+	}
+	return secrets.WrapCommand(command, envFile), func() { secrets.DiscardEnvFile(envFile) }
+}
+
+// scopeLabel renders a rig/role scope like Secret.ScopeLabel.
+func scopeLabel(rig, role string) string {
+	return (&secrets.Secret{Rig: rig, Role: role}).ScopeLabel()
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/recording"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Session recording flags
+var (
+	sessionRecordOutput   string
+	sessionRecordWidth    int
+	sessionRecordHeight   int
+	sessionRecordTitle    string
+	sessionRecordingsRig  string
+	sessionRecordingsJSON bool
+	sessionReplaySpeed    float64
+	sessionReplayMaxIdle  time.Duration
+	sessionReplayRaw      bool
+)
+
+var sessionRecordCmd = &cobra.Command{
+	Use:    "record",
+	Short:  "Record stdin as an asciicast file (internal use)",
+	Hidden: true, // Internal command run by tmux pipe-pane
+	RunE:   runSessionRecord,
+}
+
+var sessionRecordingsCmd = &cobra.Command{
+	Use:   "recordings",
+	Short: "List archived session recordings",
+	Long: `List archived agent session recordings, newest first.
+
+Rigs with recording enabled archive the full output of each polecat and
+crew session under <rig>/.runtime/recordings/ in asciicast v2 format.
+Enable it in the rig's settings/config.json:
+
+  "recording": {"enabled": true, "ttl": "72h"}
+
+Recordings outlive their sessions, so 'gt session replay' can show what a
+polecat did before it died. They are pruned after the TTL by 'gt krc prune'
+and the daemon.
+
+Examples:
+  gt session recordings
+  gt session recordings --rig greenplace`,
+	RunE: runSessionRecordings,
+}
+
+var sessionReplayCmd = &cobra.Command{
+	Use:   "replay <id>",
+	Short: "Replay an archived session recording",
+	Long: `Replay an archived session recording in the terminal.
+
+<id> is a recording ID from 'gt session recordings', a unique prefix of
+one, or a session name to replay that session's latest recording.
+
+Output is replayed with its original timing; long idle gaps are shortened
+to --max-idle. Use --raw to dump the output without delays (e.g., to pipe
+into less -R or grep). The file is standard asciicast v2, so it can also be
+played with asciinema.
+
+Examples:
+  gt session replay gt-greenplace-Toast
+  gt session replay gt-greenplace-Toast-20260112-0930 --speed 4
+  gt session replay gt-greenplace-Toast --raw | less -R`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionReplay,
+}
+
+func init() {
+	sessionRecordCmd.Flags().StringVar(&sessionRecordOutput, "output", "", "Recording file to write")
+	sessionRecordCmd.Flags().IntVar(&sessionRecordWidth, "width", 80, "Terminal width")
+	sessionRecordCmd.Flags().IntVar(&sessionRecordHeight, "height", 24, "Terminal height")
+	sessionRecordCmd.Flags().StringVar(&sessionRecordTitle, "title", "", "Recording title")
+	_ = sessionRecordCmd.MarkFlagRequired("output")
+
+	sessionRecordingsCmd.Flags().StringVar(&sessionRecordingsRig, "rig", "", "Filter by rig name")
+	sessionRecordingsCmd.Flags().BoolVar(&sessionRecordingsJSON, "json", false, "Output as JSON")
+
+	sessionReplayCmd.Flags().Float64Var(&sessionReplaySpeed, "speed", 1, "Playback speed multiplier")
+	sessionReplayCmd.Flags().DurationVar(&sessionReplayMaxIdle, "max-idle", 2*time.Second, "Shorten pauses longer than this (0 keeps them)")
+	sessionReplayCmd.Flags().BoolVar(&sessionReplayRaw, "raw", false, "Dump output without timing")
+
+	sessionCmd.AddCommand(sessionRecordCmd)
+	sessionCmd.AddCommand(sessionRecordingsCmd)
+	sessionCmd.AddCommand(sessionReplayCmd)
+}
+
+func runSessionRecord(cmd *cobra.Command, args []string) error {
+	if err := os.MkdirAll(filepath.Dir(sessionRecordOutput), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(sessionRecordOutput, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return recording.Record(os.Stdin, f, recording.Header{
+		Width:  sessionRecordWidth,
+		Height: sessionRecordHeight,
+		Title:  sessionRecordTitle,
+		Env:    map[string]string{"TERM": os.Getenv("TERM")},
+	})
+}
+
+func runSessionRecordings(cmd *cobra.Command, args []string) error {
+	rigs, _, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+
+	var all []recording.Info
+	for _, r := range rigs {
+		if sessionRecordingsRig != "" && r.Name != sessionRecordingsRig {
+			continue
+		}
+		infos, err := recording.List(r.Path)
+		if err != nil {
+			return fmt.Errorf("listing recordings for %s: %w", r.Name, err)
+		}
+		all = append(all, infos...)
+	}
+
+	if sessionRecordingsJSON {
+		return outputJSON(all)
+	}
+
+	if len(all) == 0 {
+		fmt.Println("No recordings.")
+		fmt.Printf("%s\n", style.Dim.Render(`Enable with "recording": {"enabled": true} in <rig>/settings/config.json`))
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "ID", Width: 44},
+		style.Column{Name: "RIG", Width: 12},
+		style.Column{Name: "STARTED", Width: 10},
+		style.Column{Name: "LAST OUTPUT", Width: 12},
+		style.Column{Name: "SIZE", Width: 9, Align: style.AlignRight},
+	)
+	for _, info := range all {
+		started := "-"
+		if !info.Started.IsZero() {
+			started = formatAge(info.Started)
+		}
+		table.AddRow(info.ID, info.Rig, started, formatAge(info.Updated), formatBytes(info.Size))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+func runSessionReplay(cmd *cobra.Command, args []string) error {
+	rigs, _, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+	rigPaths := make([]string, len(rigs))
+	for i, r := range rigs {
+		rigPaths[i] = r.Path
+	}
+
+	info, err := recording.Find(rigPaths, args[0])
+	if err != nil {
+		return err
+	}
+	cast, err := recording.Load(info.Path)
+	if err != nil {
+		return fmt.Errorf("reading recording %s: %w", info.ID, err)
+	}
+
+	speed := sessionReplaySpeed
+	if sessionReplayRaw {
+		speed = 0
+	} else {
+		fmt.Fprintf(os.Stderr, "%s %s (%dx%d, %s)\n", style.Bold.Render("Replaying"), info.ID,
+			cast.Header.Width, cast.Header.Height, cast.Duration().Round(time.Second))
+	}
+	if err := recording.Play(cast, os.Stdout, speed, sessionReplayMaxIdle); err != nil {
+		return err
+	}
+	if !sessionReplayRaw {
+		fmt.Fprintf(os.Stderr, "\n%s\n", style.Dim.Render("— end of recording —"))
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/routing"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -51,6 +52,16 @@ Spawning Options (when target is a rig):
   gt sling gp-abc greenplace --force                # Ignore unread mail
   gt sling gp-abc greenplace --account work         # Use specific Claude account
 
+Skill-Based Routing (when target is a rig):
+  gt sling gt-abc gastown --explain      # Rank polecat identities and runtimes
+  gt sling gt-abc gastown --auto         # Spawn the best-ranked one
+  gt sling gt-abc --auto                 # Rig taken from the bead's prefix
+
+  Candidates are scored on past success with beads sharing the bead's
+  labels and type (and the formula's capabilities), failures on them, work
+  still hooked to the identity, and the runtime's cost tier. Tune it with
+  "routing" in settings/config.json (agents, cost_tiers, min_tiers, weights).
+
 Natural Language Args:
   gt sling gt-abc --args "patch release"
   gt sling code-review --args "focus on security"
@@ -98,6 +109,8 @@ var (
 	slingAgent    string // --agent: override runtime agent for this sling/spawn
 	slingNoConvoy bool   // --no-convoy: skip auto-convoy creation
 	slingNoMerge  bool   // --no-merge: skip merge queue on completion (for upstream PRs/human review)
+	slingAuto     bool   // --auto: spawn the polecat identity and runtime routing ranks best
+	slingExplain  bool   // --explain: show the routing ranking without slinging
 )
 
 func init() {
@@ -116,6 +129,8 @@ func init() {
 	slingCmd.Flags().BoolVar(&slingNoConvoy, "no-convoy", false, "Skip auto-convoy creation for single-issue sling")
 	slingCmd.Flags().BoolVar(&slingHookRawBead, "hook-raw-bead", false, "Hook raw bead without default formula (expert mode)")
 	slingCmd.Flags().BoolVar(&slingNoMerge, "no-merge", false, "Skip merge queue on completion (keep work on feature branch for review)")
+	slingCmd.Flags().BoolVar(&slingAuto, "auto", false, "Route to the best polecat identity and runtime for the bead (rig targets)")
+	slingCmd.Flags().BoolVar(&slingExplain, "explain", false, "Show how routing ranks polecats for the bead, without slinging")
 
 
 	rootCmd.AddCommand(slingCmd)
@@ -195,6 +210,37 @@ func runSling(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Skill-based routing: rank who should take the bead and, with --auto,
+	// spawn the best of them in its rig
+	var router *slingRouter
+	var routed *routing.Candidate
+	if slingAuto || slingExplain {
+		target := ""
+		if len(args) > 1 {
+			target = args[1]
+		}
+		rigName, err := slingRouteRig(townRoot, target, beadID)
+		if err != nil {
+			return err
+		}
+		if router, err = newSlingRouter(townRoot, rigName); err != nil {
+			return err
+		}
+		routeFormula := formulaName
+		if routeFormula == "" && !slingHookRawBead {
+			routeFormula = "mol-polecat-work"
+		}
+		if slingExplain {
+			return router.explain(beadID, routeFormula)
+		}
+		if routed, err = router.pick(beadID, routeFormula); err != nil {
+			return err
+		}
+		if target == "" {
+			args = append(args, rigName)
+		}
+	}
+
 	// Determine target agent (self or specified)
 	var targetAgent string
 	var targetPane string
@@ -248,6 +294,9 @@ func runSling(cmd *cobra.Command, args []string) error {
 				// Dry run - just indicate what would happen
 				fmt.Printf("Would spawn fresh polecat in rig '%s'\n", rigName)
 				targetAgent = fmt.Sprintf("%s/polecats/<new>", rigName)
+				if routed != nil {
+					targetAgent = fmt.Sprintf("%s/polecats/%s", rigName, routed.Name)
+				}
 				targetPane = "<new-pane>"
 			} else {
 				// Spawn a fresh polecat in the rig
@@ -259,6 +308,9 @@ func runSling(cmd *cobra.Command, args []string) error {
 					HookBead: beadID, // Set atomically at spawn time
 					Agent:    slingAgent,
 				}
+				if router != nil {
+					spawnOpts = router.spawnOptions(routed, spawnOpts)
+				}
 				spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
 					return fmt.Errorf("spawning polecat: %w", spawnErr)
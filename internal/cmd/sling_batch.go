@@ -21,10 +21,38 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 		}
 	}
 
+	townRoot := filepath.Dir(townBeadsDir)
+	formulaName := "mol-polecat-work"
+
+	// Skill-based routing: each bead goes to the best identity still free
+	var router *slingRouter
+	if slingAuto || slingExplain {
+		var err error
+		if router, err = newSlingRouter(townRoot, rigName); err != nil {
+			return err
+		}
+		if slingExplain {
+			for i, beadID := range beadIDs {
+				if i > 0 {
+					fmt.Println()
+				}
+				if err := router.explain(beadID, formulaName); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
 	if slingDryRun {
 		fmt.Printf("%s Batch slinging %d beads to rig '%s':\n", style.Bold.Render("🎯"), len(beadIDs), rigName)
 		fmt.Printf("  Would cook mol-polecat-work formula once\n")
 		for _, beadID := range beadIDs {
+			if router != nil {
+				if _, err := router.pick(beadID, formulaName); err != nil {
+					return err
+				}
+			}
 			fmt.Printf("  Would spawn polecat and apply mol-polecat-work to: %s\n", beadID)
 		}
 		return nil
@@ -34,8 +62,6 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 
 	// Issue #288: Auto-apply mol-polecat-work for batch sling
 	// Cook once before the loop for efficiency
-	townRoot := filepath.Dir(townBeadsDir)
-	formulaName := "mol-polecat-work"
 	formulaCooked := false
 
 	// Track results for summary
@@ -73,6 +99,15 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 			HookBead: beadID, // Set atomically at spawn time
 			Agent:    slingAgent,
 		}
+		if router != nil {
+			routed, err := router.pick(beadID, formulaName)
+			if err != nil {
+				results = append(results, slingResult{beadID: beadID, success: false, errMsg: err.Error()})
+				fmt.Printf("  %s Could not route: %v\n", style.Dim.Render("✗"), err)
+				continue
+			}
+			spawnOpts = router.spawnOptions(routed, spawnOpts)
+		}
 		spawnInfo, err := SpawnPolecatForSling(rigName, spawnOpts)
 		if err != nil {
 			results = append(results, slingResult{beadID: beadID, success: false, errMsg: err.Error()})
@@ -11,6 +11,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/routing"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -88,6 +89,25 @@ func runSlingFormula(args []string) error {
 		target = args[1]
 	}
 
+	// Skill-based routing on the formula's capabilities
+	var router *slingRouter
+	var routed *routing.Candidate
+	if slingAuto || slingExplain {
+		rigName, err := slingRouteRig(townRoot, target, "")
+		if err != nil {
+			return err
+		}
+		if router, err = newSlingRouter(townRoot, rigName); err != nil {
+			return err
+		}
+		if slingExplain {
+			return router.explain("", formulaName)
+		}
+		if routed, err = router.pick("", formulaName); err != nil {
+			return err
+		}
+	}
+
 	// Resolve target agent and pane
 	var targetAgent string
 	var targetPane string
@@ -147,6 +167,9 @@ func runSlingFormula(args []string) error {
 					Create:  slingCreate,
 					Agent:   slingAgent,
 				}
+				if router != nil {
+					spawnOpts = router.spawnOptions(routed, spawnOpts)
+				}
 				spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
 					return fmt.Errorf("spawning polecat: %w", spawnErr)
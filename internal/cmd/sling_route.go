@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/routing"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// explainRows is how many candidates gt sling --explain shows per bead.
+const explainRows = 10
+
+// slingRouter ranks polecat identities and runtimes for work slung to a
+// rig with --auto or --explain. Names picked for earlier beads of a batch
+// are taken, so the next bead goes elsewhere.
+type slingRouter struct {
+	rigName      string
+	router       *routing.Router
+	slots        []polecat.NameSlot
+	defaultAgent string
+}
+
+func newSlingRouter(townRoot, rigName string) (*slingRouter, error) {
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		rigsConfig = &config.RigsConfig{Rigs: make(map[string]config.RigEntry)}
+	}
+	r, err := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot)).GetRig(rigName)
+	if err != nil {
+		return nil, fmt.Errorf("rig '%s' not found", rigName)
+	}
+
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	defaultAgent, _ := config.ResolveRoleAgentName("polecat", townRoot, r.Path)
+	agents := []string{defaultAgent}
+	switch {
+	case slingAgent != "":
+		agents = []string{slingAgent}
+	case settings.Routing != nil && len(settings.Routing.Agents) > 0:
+		agents = settings.Routing.Agents
+	}
+
+	// Routing on no history still works (it picks what allocation would),
+	// so an unreadable beads database is a warning
+	issues, err := beads.New(r.Path).List(beads.ListOptions{Status: "all", Priority: -1, Limit: -1})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s could not read %s work history: %v\n", style.WarningPrefix, rigName, err)
+	}
+
+	return &slingRouter{
+		rigName:      rigName,
+		router:       routing.New(routing.NewHistory(rigName, issues), agents, settings.Routing),
+		slots:        polecat.NewManager(r, git.NewGit(r.Path), nil).NameSlots(),
+		defaultAgent: defaultAgent,
+	}, nil
+}
+
+// rank ranks the candidates for a bead slung with a formula. beadID is
+// empty when the formula is slung on its own.
+func (s *slingRouter) rank(beadID, formulaName string) (*routing.Task, []*routing.Candidate, error) {
+	var issue *beads.Issue
+	if beadID != "" {
+		var err error
+		if issue, err = beads.New(resolveBeadDir(beadID)).Show(beadID); err != nil {
+			return nil, nil, fmt.Errorf("reading %s: %w", beadID, err)
+		}
+	}
+	task := routing.NewTask(issue, formulaCapabilities(formulaName))
+	if issue == nil {
+		task.ID = formulaName
+	}
+	return task, s.router.Rank(task, s.slots), nil
+}
+
+// pick returns the best candidate for a bead and takes its name. It
+// returns nil when the pool has no free name, leaving allocation to the
+// usual overflow naming.
+func (s *slingRouter) pick(beadID, formulaName string) (*routing.Candidate, error) {
+	task, ranked, err := s.rank(beadID, formulaName)
+	if err != nil {
+		return nil, err
+	}
+	best := routing.Best(ranked)
+	if best == nil {
+		for _, c := range ranked {
+			if c.Excluded != "busy" {
+				return nil, fmt.Errorf("no runtime can take %s: %s", task.ID, c.Excluded)
+			}
+		}
+		fmt.Printf("%s No free polecat name in %s to route to, allocating as usual\n", style.Dim.Render("○"), s.rigName)
+		return nil, nil
+	}
+	s.take(best.Name)
+	fmt.Printf("%s Routed %s to %s/polecats/%s on %s %s\n", style.Bold.Render("→"), task.ID, s.rigName, best.Name, best.Agent,
+		style.Dim.Render(fmt.Sprintf("(score %.2f, %s)", best.Score, routeHistory(best))))
+	return best, nil
+}
+
+// explain prints the ranking for a bead, then takes the best name as pick
+// would, so a batch explains what --auto would do.
+func (s *slingRouter) explain(beadID, formulaName string) error {
+	task, ranked, err := s.rank(beadID, formulaName)
+	if err != nil {
+		return err
+	}
+	title := task.ID
+	if task.Title != "" {
+		title += " " + style.Dim.Render(strconv.Quote(task.Title))
+	}
+	fmt.Printf("%s %s in %s\n", style.Bold.Render("Routing"), title, s.rigName)
+	tags := "none"
+	if len(task.Tags) > 0 {
+		tags = strings.Join(task.Tags, ", ")
+	}
+	fmt.Printf("  Tags: %s\n\n", tags)
+
+	if len(ranked) == 0 {
+		fmt.Printf("  No free polecat names: --auto allocates as usual\n")
+		return nil
+	}
+	table := style.NewTable(
+		style.Column{Name: "#", Width: 3, Align: style.AlignRight},
+		style.Column{Name: "POLECAT", Width: 14},
+		style.Column{Name: "AGENT", Width: 16},
+		style.Column{Name: "SCORE", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "SKILL", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "RELIABLE", Width: 8, Align: style.AlignRight},
+		style.Column{Name: "LOAD", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "COST", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "HISTORY", Width: 22},
+		style.Column{Name: "NOTE", Width: 24},
+	)
+	for i, c := range ranked {
+		if i == explainRows {
+			break
+		}
+		rank := strconv.Itoa(i + 1)
+		var notes []string
+		if c.Excluded != "" {
+			rank = "-"
+			notes = append(notes, c.Excluded)
+		}
+		if c.Warm {
+			notes = append(notes, "warm")
+		}
+		if c.Hooked > 0 {
+			notes = append(notes, fmt.Sprintf("%d hooked", c.Hooked))
+		}
+		table.AddRow(rank, c.Name, c.Agent,
+			fmt.Sprintf("%.2f", c.Score), fmt.Sprintf("%.2f", c.Skill), fmt.Sprintf("%.2f", c.Reliability),
+			fmt.Sprintf("%.2f", c.Load), fmt.Sprintf("%.2f", c.Cost),
+			routeHistory(c), strings.Join(notes, ", "))
+	}
+	fmt.Print(table.Render())
+	if len(ranked) > explainRows {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("… %d more", len(ranked)-explainRows)))
+	}
+	w := s.router.Weights
+	fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("score = %.2g skill + %.2g reliable + %.2g load + %.2g cost (normalized)",
+		w.Skill, w.Reliability, w.Load, w.Cost)))
+
+	if best := routing.Best(ranked); best != nil {
+		s.take(best.Name)
+	}
+	return nil
+}
+
+// take marks a name as no longer free.
+func (s *slingRouter) take(name string) {
+	for i := range s.slots {
+		if s.slots[i].Name == name {
+			s.slots[i].Busy, s.slots[i].Warm = true, false
+		}
+	}
+}
+
+// spawnOptions applies a routing pick to polecat spawn options. The agent
+// is only overridden when routing chose one other than the rig's default.
+func (s *slingRouter) spawnOptions(c *routing.Candidate, opts SlingSpawnOptions) SlingSpawnOptions {
+	if c == nil {
+		return opts
+	}
+	opts.Name = c.Name
+	if c.Agent != s.defaultAgent {
+		opts.Agent = c.Agent
+	}
+	return opts
+}
+
+// routeHistory describes a candidate's similar past work.
+func routeHistory(c *routing.Candidate) string {
+	if c.Done == 0 && c.Failed == 0 {
+		return "no similar work"
+	}
+	return fmt.Sprintf("%s done, %s failed", formatRouteCount(c.Done), formatRouteCount(c.Failed))
+}
+
+func formatRouteCount(v float64) string {
+	return strings.TrimSuffix(strconv.FormatFloat(v, 'f', 1, 64), ".0")
+}
+
+// formulaCapabilities returns the capability tags a formula declares, or
+// nil if it declares none or cannot be read.
+func formulaCapabilities(formulaName string) []string {
+	if formulaName == "" {
+		return nil
+	}
+	f, err := loadFormulaForSimulation(formulaName)
+	if err != nil {
+		return nil
+	}
+	return f.Capabilities.Tags()
+}
+
+// slingRouteRig returns the rig --auto and --explain route a bead to: the
+// target if given, else the rig whose beads hold it.
+func slingRouteRig(townRoot, target, beadID string) (string, error) {
+	if target != "" {
+		rigName, isRig := IsRigName(target)
+		if !isRig {
+			return "", fmt.Errorf("--auto and --explain route work to a rig's polecats; '%s' is not a rig", target)
+		}
+		return rigName, nil
+	}
+	if beadID == "" {
+		return "", fmt.Errorf("--auto and --explain need a rig target for a formula")
+	}
+	if rel, err := filepath.Rel(townRoot, resolveBeadDir(beadID)); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		if rigName, isRig := IsRigName(strings.Split(filepath.ToSlash(rel), "/")[0]); isRig {
+			return rigName, nil
+		}
+	}
+	return "", fmt.Errorf("cannot tell which rig %s belongs to; give the rig as target", beadID)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -30,6 +31,7 @@ var statusFast bool
 var statusWatch bool
 var statusInterval int
 var statusVerbose bool
+var statusResources bool
 
 var statusCmd = &cobra.Command{
 	Use:     "status",
@@ -41,7 +43,9 @@ var statusCmd = &cobra.Command{
 Shows town name, registered rigs, active polecats, and witness status.
 
 Use --fast to skip mail lookups for faster execution.
-Use --watch to continuously refresh status at regular intervals.`,
+Use --watch to continuously refresh status at regular intervals.
+Use --resources to add per-agent CPU, memory, open files and disk usage
+(Linux; peak CPU and disk come from the daemon's resources patrol).`,
 	RunE: runStatus,
 }
 
@@ -51,17 +55,19 @@ func init() {
 	statusCmd.Flags().BoolVarP(&statusWatch, "watch", "w", false, "Watch mode: refresh status continuously")
 	statusCmd.Flags().IntVarP(&statusInterval, "interval", "n", 2, "Refresh interval in seconds")
 	statusCmd.Flags().BoolVarP(&statusVerbose, "verbose", "v", false, "Show detailed multi-line output per agent")
+	statusCmd.Flags().BoolVar(&statusResources, "resources", false, "Show per-agent CPU, memory and disk usage (Linux)")
 	rootCmd.AddCommand(statusCmd)
 }
 
 // TownStatus represents the overall status of the workspace.
 type TownStatus struct {
-	Name     string         `json:"name"`
-	Location string         `json:"location"`
-	Overseer *OverseerInfo  `json:"overseer,omitempty"` // Human operator
-	Agents   []AgentRuntime `json:"agents"`             // Global agents (Mayor, Deacon)
-	Rigs     []RigStatus    `json:"rigs"`
-	Summary  StatusSum      `json:"summary"`
+	Name      string          `json:"name"`
+	Location  string          `json:"location"`
+	Overseer  *OverseerInfo   `json:"overseer,omitempty"` // Human operator
+	Agents    []AgentRuntime  `json:"agents"`             // Global agents (Mayor, Deacon)
+	Rigs      []RigStatus     `json:"rigs"`
+	Summary   StatusSum       `json:"summary"`
+	Resources []ResourceUsage `json:"resources,omitempty"` // Only with --resources
 }
 
 // OverseerInfo represents the human operator's identity and status.
@@ -86,6 +92,8 @@ type AgentRuntime struct {
 	State        string `json:"state,omitempty"`         // Agent state from agent bead
 	UnreadMail   int    `json:"unread_mail"`             // Number of unread messages
 	FirstSubject string `json:"first_subject,omitempty"` // Subject of first unread message
+
+	Limits *sandbox.Stats `json:"limits,omitempty"` // cgroup usage, for sessions started with resource limits
 }
 
 // RigStatus represents status of a single rig.
@@ -423,6 +431,14 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 	}
 	status.Summary.RigCount = len(rigs)
 
+	if statusResources {
+		rigNames := make([]string, len(rigs))
+		for i, r := range rigs {
+			rigNames[i] = r.Name
+		}
+		status.Resources = collectResourceUsage(townRoot, rigNames, t)
+	}
+
 	// Output
 	if statusJSON {
 		return outputStatusJSON(status)
@@ -597,6 +613,10 @@ func outputStatusText(status TownStatus) error {
 		fmt.Println()
 	}
 
+	if statusResources {
+		renderResourceUsage(status.Resources)
+	}
+
 	return nil
 }
 
@@ -697,6 +717,41 @@ func renderAgentDetails(agent AgentRuntime, indent string, hooks []AgentHookInfo
 		}
 		fmt.Printf("%s  mail: %s\n", indent, mailStr)
 	}
+
+	// Line 4: Resource usage (if started with limits)
+	if agent.Limits != nil {
+		fmt.Printf("%s  limits: %s\n", indent, formatLimits(agent.Limits))
+	}
+}
+
+// formatLimits formats a sandboxed session's usage against its limits,
+// e.g. "mem 1.2 GB/4.0 GB (30%), pids 42/512, cpu 3m12s".
+func formatLimits(l *sandbox.Stats) string {
+	mem := "mem " + formatBytes(l.MemoryCurrent)
+	if l.MemoryMax > 0 {
+		mem = fmt.Sprintf("mem %s/%s (%d%%)", formatBytes(l.MemoryCurrent), formatBytes(l.MemoryMax), l.MemoryPercent())
+		if l.MemoryPercent() >= 80 {
+			mem = style.Warning.Render(mem)
+		}
+	}
+	pids := fmt.Sprintf("pids %d", l.PidsCurrent)
+	if l.PidsMax > 0 {
+		pids = fmt.Sprintf("pids %d/%d", l.PidsCurrent, l.PidsMax)
+	}
+	parts := []string{mem, pids, "cpu " + l.CPUTime.Round(time.Second).String()}
+	if l.OOMKills > 0 {
+		parts = append(parts, style.Error.Render(fmt.Sprintf("%d OOM kills", l.OOMKills)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatLimitsCompact returns a short suffix flagging a session close to its
+// memory limit, or "" when it has headroom.
+func formatLimitsCompact(l *sandbox.Stats) string {
+	if l == nil || l.MemoryPercent() < 80 {
+		return ""
+	}
+	return style.Warning.Render(fmt.Sprintf(" [mem %d%%]", l.MemoryPercent()))
 }
 
 // formatMQSummary formats the MQ status for verbose display
@@ -787,7 +842,7 @@ func renderAgentCompactWithSuffix(agent AgentRuntime, indent string, hooks []Age
 	}
 
 	// Print single line: name + status + hook + mail + suffix
-	fmt.Printf("%s%-12s %s%s%s%s\n", indent, agent.Name, statusIndicator, hookSuffix, mailSuffix, suffix)
+	fmt.Printf("%s%-12s %s%s%s%s%s\n", indent, agent.Name, statusIndicator, hookSuffix, mailSuffix, formatLimitsCompact(agent.Limits), suffix)
 }
 
 // renderAgentCompact renders a single-line agent status
@@ -827,7 +882,7 @@ func renderAgentCompact(agent AgentRuntime, indent string, hooks []AgentHookInfo
 	}
 
 	// Print single line: name + status + hook + mail
-	fmt.Printf("%s%-12s %s%s%s\n", indent, agent.Name, statusIndicator, hookSuffix, mailSuffix)
+	fmt.Printf("%s%-12s %s%s%s%s\n", indent, agent.Name, statusIndicator, hookSuffix, mailSuffix, formatLimitsCompact(agent.Limits))
 }
 
 // buildStatusIndicator creates the visual status indicator for an agent.
@@ -1139,6 +1194,10 @@ func discoverRigAgents(allSessions map[string]bool, r *rig.Rig, crews []string,
 				populateMailInfo(&agent, mailRouter)
 			}
 
+			if agent.Running {
+				agent.Limits, _ = sandbox.Usage(townRoot, d.session)
+			}
+
 			agents[idx] = agent
 		}(i, def)
 	}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/resources"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// resourcePeakWindow is how far back gt status --resources looks for peak CPU.
+const resourcePeakWindow = time.Hour
+
+// ResourceUsage is an agent's resource usage for gt status --resources.
+type ResourceUsage struct {
+	Agent      string  `json:"agent"`
+	Running    bool    `json:"running"`
+	CPUPercent float64 `json:"cpu_percent"`
+	PeakCPU    float64 `json:"peak_cpu_percent"` // Highest sampled in the last hour
+	RSS        int64   `json:"rss_bytes"`
+	OpenFDs    int     `json:"open_fds"`
+	Children   int     `json:"children"`
+	DiskBytes  int64   `json:"disk_bytes,omitempty"`
+}
+
+// collectResourceUsage samples every agent's process tree now and combines
+// it with the daemon's recorded history: peak CPU over the last hour, and
+// disk usage (measured live only when the daemon hasn't recorded any).
+func collectResourceUsage(townRoot string, rigNames []string, t *tmux.Tmux) []ResourceUsage {
+	history, _ := resources.Load(townRoot, time.Now().Add(-resourcePeakWindow))
+	disk := resources.Latest(history, resources.TypeDisk)
+
+	targets := resources.Targets(townRoot, rigNames)
+	samples := resources.NewSampler(t).Sample(targets, len(disk) == 0)
+	for agent, s := range resources.Latest(samples, resources.TypeDisk) {
+		disk[agent] = s
+	}
+	live := resources.Latest(samples, resources.TypeProcess)
+
+	var usage []ResourceUsage
+	for _, target := range targets {
+		proc, running := live[target.Agent]
+		d, hasDisk := disk[target.Agent]
+		if !running && !hasDisk {
+			continue
+		}
+
+		u := ResourceUsage{
+			Agent:      target.Agent,
+			Running:    running,
+			CPUPercent: proc.CPUPercent,
+			PeakCPU:    proc.CPUPercent,
+			RSS:        proc.RSS,
+			OpenFDs:    proc.OpenFDs,
+			Children:   proc.Children,
+			DiskBytes:  d.DiskBytes,
+		}
+		if running {
+			for _, s := range resources.History(history, target.Agent, resources.TypeProcess) {
+				if s.CPUPercent > u.PeakCPU {
+					u.PeakCPU = s.CPUPercent
+				}
+			}
+		}
+		usage = append(usage, u)
+	}
+
+	// Busiest first, so a spinning agent tops the table
+	sort.SliceStable(usage, func(i, j int) bool {
+		if usage[i].CPUPercent != usage[j].CPUPercent {
+			return usage[i].CPUPercent > usage[j].CPUPercent
+		}
+		return usage[i].RSS > usage[j].RSS
+	})
+	return usage
+}
+
+// renderResourceUsage prints the gt status --resources table.
+func renderResourceUsage(usage []ResourceUsage) {
+	fmt.Printf("%s\n", style.Bold.Render("Resources"))
+	if len(usage) == 0 {
+		fmt.Printf("   %s\n\n", style.Dim.Render("(no agents running)"))
+		return
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "AGENT", Width: 28},
+		style.Column{Name: "CPU", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "PEAK 1H", Width: 8, Align: style.AlignRight},
+		style.Column{Name: "RSS", Width: 9, Align: style.AlignRight},
+		style.Column{Name: "FDS", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "PROCS", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "DISK", Width: 9, Align: style.AlignRight},
+	)
+	for _, u := range usage {
+		cpu, peak, rss, fds, procs := "-", "-", "-", "-", "-"
+		if u.Running {
+			cpu = formatCPU(u.CPUPercent)
+			peak = formatCPU(u.PeakCPU)
+			rss = formatBytes(u.RSS)
+			fds = fmt.Sprintf("%d", u.OpenFDs)
+			procs = fmt.Sprintf("%d", u.Children)
+		}
+		disk := "-"
+		if u.DiskBytes > 0 {
+			disk = formatBytes(u.DiskBytes)
+		}
+		table.AddRow(u.Agent, cpu, peak, rss, fds, procs, disk)
+	}
+	fmt.Print(table.Render())
+	fmt.Println()
+}
+
+// formatCPU formats a CPU percentage, flagging a spinning agent.
+func formatCPU(percent float64) string {
+	s := fmt.Sprintf("%.0f%%", percent)
+	if percent >= resources.HotCPUPercent {
+		return style.Error.Render(s)
+	}
+	return s
+}
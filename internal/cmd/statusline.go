@@ -1,49 +1,160 @@
 package cmd
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/statusline"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 var (
-	statusLineSession string
+	statusLineSession   string
+	statusLineTemplate  string
+	statusLineProviders bool
 )
 
 var statusLineCmd = &cobra.Command{
 	Use:    "status-line",
 	Short:  "Output status line content for tmux (internal use)",
 	Hidden: true, // Internal command called by tmux
-	RunE:   runStatusLine,
+	Long: `Output the tmux status line for a Gas Town session.
+
+The status line is rendered from a Go template chosen by role. Override the
+built-in templates per role in the town or rig settings/config.json:
+
+  {"status_line": {
+    "templates": {"polecat": "{{join .Icon .Hook .Context .Cost}} |"},
+    "cache_ttl": "30s"
+  }}
+
+A rig template overrides the town template for that role. Bead, mail, merge
+queue and transcript lookups are cached under .runtime/statusline for
+cache_ttl, so tmux refreshes do not run bd on every tick.
+
+Use --providers to list the values templates can use, and --template to try
+a template against a running session.`,
+	RunE: runStatusLine,
 }
 
 func init() {
 	rootCmd.AddCommand(statusLineCmd)
 	statusLineCmd.Flags().StringVar(&statusLineSession, "session", "", "Tmux session name")
+	statusLineCmd.Flags().StringVar(&statusLineTemplate, "template", "", "Render this template instead of the configured one")
+	statusLineCmd.Flags().BoolVar(&statusLineProviders, "providers", false, "List the data providers and template functions")
+}
+
+// Per-role truncation widths for the hook and mail segments.
+var statusLineWidths = map[string][2]int{
+	"mayor":    {40, 45},
+	"deacon":   {35, 40},
+	"witness":  {30, 35},
+	"refinery": {25, 30},
+	"polecat":  {40, 45},
+	"crew":     {40, 45},
 }
 
+// claudeContextWindow is the context window size used for .Context.
+const claudeContextWindow = 200_000
+
 func runStatusLine(cmd *cobra.Command, args []string) error {
+	if statusLineProviders {
+		printStatusLineProviders()
+		return nil
+	}
+
 	t := tmux.NewTmux()
+	d := newStatusLineData(t, statusLineSession)
 
-	// Get session environment
-	var rigName, polecat, crew, issue, role string
+	var townCfg, rigCfg *config.StatusLineConfig
+	if d.townRoot != "" {
+		if ts, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(d.townRoot)); err == nil {
+			townCfg = ts.StatusLine
+		}
+		if d.rig != "" {
+			if rs, err := config.LoadRigSettings(config.RigSettingsPath(filepath.Join(d.townRoot, d.rig))); err == nil {
+				rigCfg = rs.StatusLine
+			}
+		}
+	}
+	d.cache = statusline.NewCache(d.townRoot, statusline.CacheTTL(townCfg, rigCfg))
 
-	if statusLineSession != "" {
+	tmpl := statusLineTemplate
+	if tmpl == "" {
+		tmpl = statusline.TemplateFor(d.role, townCfg, rigCfg)
+	}
+	out, err := statusline.Render(d.role, tmpl, d)
+	if err != nil {
+		// tmux discards stderr, so surface template mistakes in the bar itself.
+		fmt.Print(statusline.Truncate(60, "⚠ status_line."+d.role+": "+err.Error()) + " |")
+		return nil
+	}
+	fmt.Print(out)
+	return nil
+}
+
+func printStatusLineProviders() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tCACHED\tDESCRIPTION")
+	for _, p := range statusline.Providers {
+		cached := ""
+		if p.Cached {
+			cached = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, cached, p.Description)
+	}
+	_ = w.Flush()
+
+	fmt.Println("\nFunctions: join (non-empty args with \" | \"), spaced (with \" \"), trunc N s,")
+	fmt.Println("plus the text/template builtins (and, or, not, printf, with, if).")
+	fmt.Println("\nBuilt-in templates:")
+	for _, role := range statusline.Roles {
+		fmt.Printf("  %-9s %s\n", role, statusline.DefaultTemplates[role])
+	}
+}
+
+// statusLineData holds the session being rendered and exposes the status line
+// data providers as methods. Providers run lazily, only when the template
+// references them, and each runs at most once per render.
+type statusLineData struct {
+	t        *tmux.Tmux
+	cache    *statusline.Cache
+	memo     map[string]any
+	townRoot string
+	paneDir  string
+	beadsDir string // where hooked beads live: town root or <rig>/mayor/rig
+	assignee string // hook assignee, e.g. "mayor" or "gastown/witness"
+
+	role     string
+	rig      string
+	identity string
+	session  string
+	issue    string
+	icon     string
+}
+
+// newStatusLineData identifies the session from its tmux environment (or the
+// process environment when no session is given).
+func newStatusLineData(t *tmux.Tmux, session string) *statusLineData {
+	var rigName, polecat, crew, issue, role string
+	if session != "" {
 		// Non-fatal: missing env vars are handled gracefully below
-		rigName, _ = t.GetEnvironment(statusLineSession, "GT_RIG")
-		polecat, _ = t.GetEnvironment(statusLineSession, "GT_POLECAT")
-		crew, _ = t.GetEnvironment(statusLineSession, "GT_CREW")
-		issue, _ = t.GetEnvironment(statusLineSession, "GT_ISSUE")
-		role, _ = t.GetEnvironment(statusLineSession, "GT_ROLE")
+		rigName, _ = t.GetEnvironment(session, "GT_RIG")
+		polecat, _ = t.GetEnvironment(session, "GT_POLECAT")
+		crew, _ = t.GetEnvironment(session, "GT_CREW")
+		issue, _ = t.GetEnvironment(session, "GT_ISSUE")
+		role, _ = t.GetEnvironment(session, "GT_ROLE")
 	} else {
 		// Fallback to process environment
 		rigName = os.Getenv("GT_RIG")
@@ -53,149 +164,320 @@ func runStatusLine(cmd *cobra.Command, args []string) error {
 		role = os.Getenv("GT_ROLE")
 	}
 
-	// Get session names for comparison
-	mayorSession := getMayorSessionName()
-	deaconSession := getDeaconSessionName()
+	d := &statusLineData{t: t, memo: make(map[string]any), session: session, issue: issue}
+	paneSession := session
+
+	switch {
+	case role == "mayor" || session == getMayorSessionName():
+		d.role, d.identity, d.assignee = "mayor", "mayor/", "mayor"
+		d.icon = AgentTypeIcons[AgentMayor]
+		paneSession = getMayorSessionName()
+	case role == "deacon" || session == getDeaconSessionName():
+		d.role, d.identity, d.assignee = "deacon", "deacon/", "deacon"
+		d.icon = AgentTypeIcons[AgentDeacon]
+		paneSession = getDeaconSessionName()
+	case role == "witness" || strings.HasSuffix(session, "-witness"):
+		// Session naming: gt-<rig>-witness
+		if rigName == "" && strings.HasPrefix(session, "gt-") {
+			rigName = strings.TrimPrefix(strings.TrimSuffix(session, "-witness"), "gt-")
+		}
+		d.role, d.identity = "witness", rigName+"/witness"
+		d.icon = AgentTypeIcons[AgentWitness]
+		paneSession = fmt.Sprintf("gt-%s-witness", rigName)
+	case role == "refinery" || strings.HasSuffix(session, "-refinery"):
+		if rigName == "" && strings.HasPrefix(session, "gt-") {
+			rigName = strings.TrimPrefix(strings.TrimSuffix(session, "-refinery"), "gt-")
+		}
+		d.role, d.identity = "refinery", rigName+"/refinery"
+		d.icon = AgentTypeIcons[AgentRefinery]
+		paneSession = fmt.Sprintf("gt-%s-refinery", rigName)
+	case crew != "":
+		d.role, d.identity = "crew", fmt.Sprintf("%s/crew/%s", rigName, crew)
+		d.icon = AgentTypeIcons[AgentCrew]
+	default:
+		d.role = "polecat"
+		if polecat != "" {
+			d.identity = fmt.Sprintf("%s/%s", rigName, polecat)
+			d.icon = AgentTypeIcons[AgentPolecat]
+		}
+	}
+	d.rig = rigName
+	if d.assignee == "" && rigName != "" {
+		d.assignee = d.identity
+	}
+
+	// The pane's working directory locates the town
+	if paneSession != "" {
+		if dir, err := t.GetPaneWorkDir(paneSession); err == nil && dir != "" {
+			d.paneDir = dir
+			d.townRoot, _ = workspace.Find(dir)
+		}
+	}
+	if d.townRoot != "" {
+		d.beadsDir = d.townRoot
+		if rigName != "" && d.role != "mayor" && d.role != "deacon" {
+			d.beadsDir = filepath.Join(d.townRoot, rigName, "mayor", "rig")
+		}
+	}
+	return d
+}
 
-	// Determine identity and output based on role
-	if role == "mayor" || statusLineSession == mayorSession {
-		return runMayorStatusLine(t)
+// lookup runs a provider at most once per render, going through the disk
+// cache. Provider errors render as empty values.
+func lookup[T any](d *statusLineData, key string, fill func() (T, error)) T {
+	if v, ok := d.memo[key].(T); ok {
+		return v
+	}
+	v, err := statusline.Fetch(d.cache, key, fill)
+	if err != nil {
+		var zero T
+		v = zero
 	}
+	d.memo[key] = v
+	return v
+}
+
+func (d *statusLineData) Role() string     { return d.role }
+func (d *statusLineData) Rig() string      { return d.rig }
+func (d *statusLineData) Identity() string { return d.identity }
+func (d *statusLineData) Session() string  { return d.session }
+func (d *statusLineData) Icon() string     { return d.icon }
 
-	// Deacon status line
-	if role == "deacon" || statusLineSession == deaconSession {
-		return runDeaconStatusLine(t)
+func (d *statusLineData) width(i int) int { return statusLineWidths[d.role][i] }
+
+// HookBead returns the hooked bead as "id: title", or "".
+func (d *statusLineData) HookBead() string {
+	if d.beadsDir == "" || d.assignee == "" {
+		return ""
 	}
+	return lookup(d, "hook:"+d.assignee, func() (string, error) {
+		return getHookedWork(d.assignee, d.beadsDir)
+	})
+}
 
-	// Witness status line (session naming: gt-<rig>-witness)
-	if role == "witness" || strings.HasSuffix(statusLineSession, "-witness") {
-		return runWitnessStatusLine(t, rigName)
+// Hook returns the hooked bead with the hook icon, truncated for the role.
+func (d *statusLineData) Hook() string {
+	if bead := d.HookBead(); bead != "" {
+		return "🪝 " + statusline.Truncate(d.width(0), bead)
 	}
+	return ""
+}
 
-	// Refinery status line
-	if role == "refinery" || strings.HasSuffix(statusLineSession, "-refinery") {
-		return runRefineryStatusLine(t, rigName)
+// Work returns $GT_ISSUE, or the first in_progress bead in the session's
+// worktree, truncated for the role.
+func (d *statusLineData) Work() string {
+	if d.issue != "" {
+		return d.issue
+	}
+	if d.paneDir == "" {
+		return ""
 	}
+	return statusline.Truncate(d.width(0), lookup(d, "work:"+d.paneDir, func() (string, error) {
+		return getCurrentWork(d.paneDir)
+	}))
+}
 
-	// Crew/Polecat status line
-	return runWorkerStatusLine(t, statusLineSession, rigName, polecat, crew, issue)
+// statusLineMail is the cached unread mail summary.
+type statusLineMail struct {
+	Count   int    `json:"count"`
+	Subject string `json:"subject"`
 }
 
-// runWorkerStatusLine outputs status for crew or polecat sessions.
-func runWorkerStatusLine(t *tmux.Tmux, session, rigName, polecat, crew, issue string) error {
-	// Determine agent type and identity
-	var icon, identity string
-	if polecat != "" {
-		icon = AgentTypeIcons[AgentPolecat]
-		identity = fmt.Sprintf("%s/%s", rigName, polecat)
-	} else if crew != "" {
-		icon = AgentTypeIcons[AgentCrew]
-		identity = fmt.Sprintf("%s/crew/%s", rigName, crew)
+func (d *statusLineData) mail() statusLineMail {
+	if d.townRoot == "" || d.identity == "" {
+		return statusLineMail{}
 	}
+	return lookup(d, "mail:"+d.identity, func() (statusLineMail, error) {
+		return getMailPreview(d.identity, d.townRoot)
+	})
+}
 
-	// Get pane's working directory to find workspace
-	var townRoot string
-	if session != "" {
-		paneDir, err := t.GetPaneWorkDir(session)
-		if err == nil && paneDir != "" {
-			townRoot, _ = workspace.Find(paneDir)
-		}
+func (d *statusLineData) MailCount() int      { return d.mail().Count }
+func (d *statusLineData) MailSubject() string { return d.mail().Subject }
+
+// Mail returns the first unread subject (or the unread count) with the
+// mailbox icon, or "" when there is no unread mail.
+func (d *statusLineData) Mail() string {
+	m := d.mail()
+	switch {
+	case m.Count == 0:
+		return ""
+	case m.Subject != "":
+		return "\U0001F4EC " + statusline.Truncate(d.width(1), m.Subject)
+	default:
+		return fmt.Sprintf("\U0001F4EC %d", m.Count)
 	}
+}
 
-	// Build status parts
-	var parts []string
+// statusLineMQ is the cached merge queue summary.
+type statusLineMQ struct {
+	Merging string `json:"merging"`
+	Pending int    `json:"pending"`
+	Known   bool   `json:"known"`
+}
 
-	// Priority 1: Check for hooked work (use rig beads)
-	hookedWork := ""
-	if identity != "" && rigName != "" && townRoot != "" {
-		rigBeadsDir := filepath.Join(townRoot, rigName, "mayor", "rig")
-		hookedWork = getHookedWork(identity, 40, rigBeadsDir)
+func (d *statusLineData) mq() statusLineMQ {
+	if d.rig == "" {
+		return statusLineMQ{}
 	}
+	return lookup(d, "mq:"+d.rig, func() (statusLineMQ, error) {
+		return getMergeQueueSummary(d.rig)
+	})
+}
 
-	// Priority 2: Fall back to GT_ISSUE env var or in_progress beads
-	currentWork := issue
-	if currentWork == "" && hookedWork == "" && session != "" {
-		currentWork = getCurrentWork(t, session, 40)
+// MQDepth returns the number of merge requests queued, including the one
+// being merged.
+func (d *statusLineData) MQDepth() int {
+	mq := d.mq()
+	if mq.Merging != "" {
+		return mq.Pending + 1
 	}
+	return mq.Pending
+}
 
-	// Show hooked work (takes precedence)
-	if hookedWork != "" {
-		if icon != "" {
-			parts = append(parts, fmt.Sprintf("%s 🪝 %s", icon, hookedWork))
-		} else {
-			parts = append(parts, fmt.Sprintf("🪝 %s", hookedWork))
-		}
-	} else if currentWork != "" {
-		// Fall back to current work (in_progress)
-		if icon != "" {
-			parts = append(parts, fmt.Sprintf("%s %s", icon, currentWork))
-		} else {
-			parts = append(parts, currentWork)
-		}
-	} else if icon != "" {
-		parts = append(parts, icon)
-	}
-
-	// Mail preview - only show if hook is empty
-	if hookedWork == "" && identity != "" && townRoot != "" {
-		unread, subject := getMailPreviewWithRoot(identity, 45, townRoot)
-		if unread > 0 {
-			if subject != "" {
-				parts = append(parts, fmt.Sprintf("\U0001F4EC %s", subject))
-			} else {
-				parts = append(parts, fmt.Sprintf("\U0001F4EC %d", unread))
-			}
+// MQ summarizes the rig's merge queue.
+func (d *statusLineData) MQ() string {
+	mq := d.mq()
+	switch {
+	case !mq.Known:
+		return "MQ: ?"
+	case mq.Merging != "" && mq.Pending > 0:
+		return fmt.Sprintf("merging %s | +%d queued", mq.Merging, mq.Pending)
+	case mq.Merging != "":
+		return "merging " + mq.Merging
+	case mq.Pending > 0:
+		return fmt.Sprintf("%d queued", mq.Pending)
+	default:
+		return "idle"
+	}
+}
+
+// CrewCount returns the number of running crew sessions in the rig.
+func (d *statusLineData) CrewCount() int {
+	count := 0
+	for _, agent := range d.agentSessions() {
+		if agent.Rig == d.rig && agent.Type == AgentCrew {
+			count++
 		}
 	}
+	return count
+}
 
-	// Output
-	if len(parts) > 0 {
-		fmt.Print(strings.Join(parts, " | ") + " |")
+// Crew returns "N crew", or "" when no crew is running.
+func (d *statusLineData) Crew() string {
+	if n := d.CrewCount(); n > 0 {
+		return fmt.Sprintf("%d crew", n)
 	}
+	return ""
+}
 
-	return nil
+// ContextPercent returns how full the agent's context window is (0-100).
+func (d *statusLineData) ContextPercent() int {
+	if d.paneDir == "" {
+		return 0
+	}
+	return lookup(d, "context:"+d.paneDir, func() (int, error) {
+		return contextPercentFromWorkDir(d.paneDir)
+	})
 }
 
-func runMayorStatusLine(t *tmux.Tmux) error {
-	// Count active sessions by listing tmux sessions
-	sessions, err := t.ListSessions()
-	if err != nil {
-		return nil // Silent fail
+// Context returns the context window usage with the brain icon.
+func (d *statusLineData) Context() string {
+	if pct := d.ContextPercent(); pct > 0 {
+		return fmt.Sprintf("🧠 %d%%", pct)
 	}
+	return ""
+}
 
-	// Get town root from mayor pane's working directory
-	var townRoot string
-	mayorSession := getMayorSessionName()
-	paneDir, err := t.GetPaneWorkDir(mayorSession)
-	if err == nil && paneDir != "" {
-		townRoot, _ = workspace.Find(paneDir)
+// CostUSD returns the cost of the agent's current session so far.
+func (d *statusLineData) CostUSD() float64 {
+	if d.paneDir == "" {
+		return 0
 	}
+	return lookup(d, "cost:"+d.paneDir, func() (float64, error) {
+		return extractCostFromWorkDir(d.paneDir)
+	})
+}
+
+// Cost returns the session cost formatted as dollars.
+func (d *statusLineData) Cost() string {
+	if cost := d.CostUSD(); cost > 0 {
+		return fmt.Sprintf("$%.2f", cost)
+	}
+	return ""
+}
 
-	// Load registered rigs to validate against
-	registeredRigs := make(map[string]bool)
-	if townRoot != "" {
-		rigsConfigPath := filepath.Join(townRoot, "mayor", "rigs.json")
+// agentSessions returns the categorized Gas Town tmux sessions.
+func (d *statusLineData) agentSessions() []*AgentSession {
+	return lookupLocal(d, "sessions", func() []*AgentSession {
+		sessions, err := d.t.ListSessions()
+		if err != nil {
+			return nil
+		}
+		var agents []*AgentSession
+		for _, s := range sessions {
+			if agent := categorizeSession(s); agent != nil {
+				agents = append(agents, agent)
+			}
+		}
+		return agents
+	})
+}
+
+// registeredRigs returns the rigs in mayor/rigs.json.
+func (d *statusLineData) registeredRigs() map[string]bool {
+	return lookupLocal(d, "rigs", func() map[string]bool {
+		rigs := make(map[string]bool)
+		if d.townRoot == "" {
+			return rigs
+		}
+		rigsConfigPath := filepath.Join(d.townRoot, "mayor", "rigs.json")
 		if rigsConfig, err := config.LoadRigsConfig(rigsConfigPath); err == nil {
 			for rigName := range rigsConfig.Rigs {
-				registeredRigs[rigName] = true
+				rigs[rigName] = true
 			}
 		}
+		return rigs
+	})
+}
+
+// lookupLocal memoizes a cheap provider for the current render only.
+func lookupLocal[T any](d *statusLineData, key string, fill func() T) T {
+	if v, ok := d.memo[key].(T); ok {
+		return v
 	}
+	v := fill()
+	d.memo[key] = v
+	return v
+}
 
-	// Track per-rig status for LED indicators and sorting
-	type rigStatus struct {
-		hasWitness  bool
-		hasRefinery bool
-		opState     string // "OPERATIONAL", "PARKED", or "DOCKED"
+// ActiveRigs returns the number of registered rigs with a running agent.
+func (d *statusLineData) ActiveRigs() int {
+	registered := d.registeredRigs()
+	rigs := make(map[string]bool)
+	for _, agent := range d.agentSessions() {
+		// Only count registered rigs
+		if agent.Rig != "" && registered[agent.Rig] {
+			rigs[agent.Rig] = true
+		}
 	}
-	rigStatuses := make(map[string]*rigStatus)
+	return len(rigs)
+}
 
-	// Initialize for all registered rigs
-	for rigName := range registeredRigs {
-		rigStatuses[rigName] = &rigStatus{}
+// Deacon returns the deacon icon when the deacon is running.
+func (d *statusLineData) Deacon() string {
+	for _, agent := range d.agentSessions() {
+		if agent.Type == AgentDeacon {
+			return AgentTypeIcons[AgentDeacon]
+		}
 	}
+	return ""
+}
 
-	// Track per-agent-type health (working/zombie counts)
+// Agents returns per-agent-type health in consistent order.
+// Format: "1/3 👁️" = 1 working out of 3 total. Only agent types that have
+// sessions are shown. Polecats are excluded - idle state is misleading noise.
+func (d *statusLineData) Agents() string {
 	type agentHealth struct {
 		total   int
 		working int
@@ -204,89 +486,67 @@ func runMayorStatusLine(t *tmux.Tmux) error {
 		AgentWitness:  {},
 		AgentRefinery: {},
 	}
-
-	// Track deacon presence (just icon, no count)
-	hasDeacon := false
-
-	// Single pass: track rig status AND agent health
-	for _, s := range sessions {
-		agent := categorizeSession(s)
-		if agent == nil {
-			continue
-		}
-
-		// Track rig-level status (witness/refinery presence)
-		// Polecats are not tracked in tmux - they're a GC concern, not a display concern
-		if agent.Rig != "" && registeredRigs[agent.Rig] {
-			if rigStatuses[agent.Rig] == nil {
-				rigStatuses[agent.Rig] = &rigStatus{}
-			}
-			switch agent.Type {
-			case AgentWitness:
-				rigStatuses[agent.Rig].hasWitness = true
-			case AgentRefinery:
-				rigStatuses[agent.Rig].hasRefinery = true
-			}
-		}
-
-		// Track agent health (skip Mayor and Crew)
+	for _, agent := range d.agentSessions() {
 		if health := healthByType[agent.Type]; health != nil {
 			health.total++
 			// Detect working state via ✻ symbol
-			if isSessionWorking(t, s) {
+			if isSessionWorking(d.t, agent.Name) {
 				health.working++
 			}
 		}
-
-		// Track deacon presence (just the icon, no count)
-		if agent.Type == AgentDeacon {
-			hasDeacon = true
-		}
 	}
 
-	// Get operational state for each rig
-	for rigName, status := range rigStatuses {
-		opState, _ := getRigOperationalState(townRoot, rigName)
-		if opState == "PARKED" || opState == "DOCKED" {
-			status.opState = opState
-		} else {
-			status.opState = "OPERATIONAL"
-		}
-	}
-
-	// Build status
-	var parts []string
-
-	// Add per-agent-type health in consistent order
-	// Format: "1/3 👁️" = 1 working out of 3 total
-	// Only show agent types that have sessions
-	// Note: Polecats excluded - idle state is misleading noise
-	// Deacon gets just an icon (no count) - shown separately below
-	agentOrder := []AgentType{AgentWitness, AgentRefinery}
 	var agentParts []string
-	for _, agentType := range agentOrder {
+	for _, agentType := range []AgentType{AgentWitness, AgentRefinery} {
 		health := healthByType[agentType]
 		if health.total == 0 {
 			continue
 		}
-		icon := AgentTypeIcons[agentType]
-		agentParts = append(agentParts, fmt.Sprintf("%d/%d %s", health.working, health.total, icon))
+		agentParts = append(agentParts, fmt.Sprintf("%d/%d %s", health.working, health.total, AgentTypeIcons[agentType]))
 	}
-	if len(agentParts) > 0 {
-		parts = append(parts, strings.Join(agentParts, " "))
+	return strings.Join(agentParts, " ")
+}
+
+// Rigs returns the rig status display with LED indicators:
+// 🟢 = both witness and refinery running (fully active)
+// 🟡 = one of witness/refinery running (partially active)
+// 🅿️ = parked (nothing running, intentionally paused)
+// 🛑 = docked (nothing running, global shutdown)
+// ⚫ = operational but nothing running (unexpected state)
+func (d *statusLineData) Rigs() string {
+	type rigStatus struct {
+		hasWitness  bool
+		hasRefinery bool
+		opState     string // "OPERATIONAL", "PARKED", or "DOCKED"
+	}
+	registered := d.registeredRigs()
+	rigStatuses := make(map[string]*rigStatus)
+	for rigName := range registered {
+		rigStatuses[rigName] = &rigStatus{}
 	}
 
-	// Add deacon icon if running (just presence, no count)
-	if hasDeacon {
-		parts = append(parts, AgentTypeIcons[AgentDeacon])
+	// Polecats are not tracked in tmux - they're a GC concern, not a display concern
+	for _, agent := range d.agentSessions() {
+		if agent.Rig == "" || !registered[agent.Rig] {
+			continue
+		}
+		switch agent.Type {
+		case AgentWitness:
+			rigStatuses[agent.Rig].hasWitness = true
+		case AgentRefinery:
+			rigStatuses[agent.Rig].hasRefinery = true
+		}
 	}
 
-	// Build rig status display with LED indicators
-	// 🟢 = both witness and refinery running (fully active)
-	// 🟡 = one of witness/refinery running (partially active)
-	// 🅿️ = parked (nothing running, intentionally paused)
-	// 🛑 = docked (nothing running, global shutdown)
-	// ⚫ = operational but nothing running (unexpected state)
+	// Get operational state for each rig
+	for rigName, status := range rigStatuses {
+		opState, _ := getRigOperationalState(d.townRoot, rigName)
+		if opState == "PARKED" || opState == "DOCKED" {
+			status.opState = opState
+		} else {
+			status.opState = "OPERATIONAL"
+		}
+	}
 
 	// Create sortable rig list
 	type rigInfo struct {
@@ -299,6 +559,7 @@ func runMayorStatusLine(t *tmux.Tmux) error {
 	}
 
 	// Sort by: 1) running state, 2) operational state, 3) alphabetical
+	stateOrder := map[string]int{"OPERATIONAL": 0, "PARKED": 1, "DOCKED": 2}
 	sort.Slice(rigs, func(i, j int) bool {
 		isRunningI := rigs[i].status.hasWitness || rigs[i].status.hasRefinery
 		isRunningJ := rigs[j].status.hasWitness || rigs[j].status.hasRefinery
@@ -309,7 +570,6 @@ func runMayorStatusLine(t *tmux.Tmux) error {
 		}
 
 		// Secondary sort: operational state (for non-running rigs: OPERATIONAL < PARKED < DOCKED)
-		stateOrder := map[string]int{"OPERATIONAL": 0, "PARKED": 1, "DOCKED": 2}
 		stateI := stateOrder[rigs[i].status.opState]
 		stateJ := stateOrder[rigs[j].status.opState]
 		if stateI != stateJ {
@@ -324,12 +584,11 @@ func runMayorStatusLine(t *tmux.Tmux) error {
 	var rigParts []string
 	var lastGroup string
 	for _, rig := range rigs {
-		isRunning := rig.status.hasWitness || rig.status.hasRefinery
-		var currentGroup string
-		if isRunning {
-			currentGroup = "running"
-		} else {
-			currentGroup = "idle-" + rig.status.opState
+		status := rig.status
+		isRunning := status.hasWitness || status.hasRefinery
+		currentGroup := "running"
+		if !isRunning {
+			currentGroup = "idle-" + status.opState
 		}
 
 		// Add separator when group changes (running -> non-running, or different opStates within non-running)
@@ -338,13 +597,11 @@ func runMayorStatusLine(t *tmux.Tmux) error {
 		}
 		lastGroup = currentGroup
 
-		status := rig.status
 		var led string
-
 		// Check if processes are running first (regardless of operational state)
 		if status.hasWitness && status.hasRefinery {
 			led = "🟢" // Both running - fully active
-		} else if status.hasWitness || status.hasRefinery {
+		} else if isRunning {
 			led = "🟡" // One running - partially active
 		} else {
 			// Nothing running - show operational state
@@ -365,260 +622,7 @@ func runMayorStatusLine(t *tmux.Tmux) error {
 		}
 		rigParts = append(rigParts, led+space+rig.name)
 	}
-
-	if len(rigParts) > 0 {
-		parts = append(parts, strings.Join(rigParts, " "))
-	}
-
-	// Priority 1: Check for hooked work (town beads for mayor)
-	hookedWork := ""
-	if townRoot != "" {
-		hookedWork = getHookedWork("mayor", 40, townRoot)
-	}
-	if hookedWork != "" {
-		parts = append(parts, fmt.Sprintf("🪝 %s", hookedWork))
-	} else if townRoot != "" {
-		// Priority 2: Fall back to mail preview
-		unread, subject := getMailPreviewWithRoot("mayor/", 45, townRoot)
-		if unread > 0 {
-			if subject != "" {
-				parts = append(parts, fmt.Sprintf("\U0001F4EC %s", subject))
-			} else {
-				parts = append(parts, fmt.Sprintf("\U0001F4EC %d", unread))
-			}
-		}
-	}
-
-	fmt.Print(strings.Join(parts, " | ") + " |")
-	return nil
-}
-
-// runDeaconStatusLine outputs status for the deacon session.
-// Shows: active rigs, polecat count, hook or mail preview
-func runDeaconStatusLine(t *tmux.Tmux) error {
-	// Count active rigs and polecats
-	sessions, err := t.ListSessions()
-	if err != nil {
-		return nil // Silent fail
-	}
-
-	// Get town root from deacon pane's working directory
-	var townRoot string
-	deaconSession := getDeaconSessionName()
-	paneDir, err := t.GetPaneWorkDir(deaconSession)
-	if err == nil && paneDir != "" {
-		townRoot, _ = workspace.Find(paneDir)
-	}
-
-	// Load registered rigs to validate against
-	registeredRigs := make(map[string]bool)
-	if townRoot != "" {
-		rigsConfigPath := filepath.Join(townRoot, "mayor", "rigs.json")
-		if rigsConfig, err := config.LoadRigsConfig(rigsConfigPath); err == nil {
-			for rigName := range rigsConfig.Rigs {
-				registeredRigs[rigName] = true
-			}
-		}
-	}
-
-	rigs := make(map[string]bool)
-	for _, s := range sessions {
-		agent := categorizeSession(s)
-		if agent == nil {
-			continue
-		}
-		// Only count registered rigs
-		if agent.Rig != "" && registeredRigs[agent.Rig] {
-			rigs[agent.Rig] = true
-		}
-	}
-	rigCount := len(rigs)
-
-	// Build status
-	// Note: Polecats excluded - they're ephemeral and idle detection is a GC concern
-	var parts []string
-	parts = append(parts, fmt.Sprintf("%d rigs", rigCount))
-
-	// Priority 1: Check for hooked work (town beads for deacon)
-	hookedWork := ""
-	if townRoot != "" {
-		hookedWork = getHookedWork("deacon", 35, townRoot)
-	}
-	if hookedWork != "" {
-		parts = append(parts, fmt.Sprintf("🪝 %s", hookedWork))
-	} else if townRoot != "" {
-		// Priority 2: Fall back to mail preview
-		unread, subject := getMailPreviewWithRoot("deacon/", 40, townRoot)
-		if unread > 0 {
-			if subject != "" {
-				parts = append(parts, fmt.Sprintf("\U0001F4EC %s", subject))
-			} else {
-				parts = append(parts, fmt.Sprintf("\U0001F4EC %d", unread))
-			}
-		}
-	}
-
-	fmt.Print(strings.Join(parts, " | ") + " |")
-	return nil
-}
-
-// runWitnessStatusLine outputs status for a witness session.
-// Shows: crew count, hook or mail preview
-// Note: Polecats excluded - they're ephemeral and idle detection is a GC concern
-func runWitnessStatusLine(t *tmux.Tmux, rigName string) error {
-	if rigName == "" {
-		// Try to extract from session name: gt-<rig>-witness
-		if strings.HasSuffix(statusLineSession, "-witness") && strings.HasPrefix(statusLineSession, "gt-") {
-			rigName = strings.TrimPrefix(strings.TrimSuffix(statusLineSession, "-witness"), "gt-")
-		}
-	}
-
-	// Get town root from witness pane's working directory
-	var townRoot string
-	sessionName := fmt.Sprintf("gt-%s-witness", rigName)
-	paneDir, err := t.GetPaneWorkDir(sessionName)
-	if err == nil && paneDir != "" {
-		townRoot, _ = workspace.Find(paneDir)
-	}
-
-	// Count crew in this rig (crew are persistent, worth tracking)
-	sessions, err := t.ListSessions()
-	if err != nil {
-		return nil // Silent fail
-	}
-
-	crewCount := 0
-	for _, s := range sessions {
-		agent := categorizeSession(s)
-		if agent == nil {
-			continue
-		}
-		if agent.Rig == rigName && agent.Type == AgentCrew {
-			crewCount++
-		}
-	}
-
-	identity := fmt.Sprintf("%s/witness", rigName)
-
-	// Build status
-	var parts []string
-	if crewCount > 0 {
-		parts = append(parts, fmt.Sprintf("%d crew", crewCount))
-	}
-
-	// Priority 1: Check for hooked work (rig beads for witness)
-	hookedWork := ""
-	if townRoot != "" && rigName != "" {
-		rigBeadsDir := filepath.Join(townRoot, rigName, "mayor", "rig")
-		hookedWork = getHookedWork(identity, 30, rigBeadsDir)
-	}
-	if hookedWork != "" {
-		parts = append(parts, fmt.Sprintf("🪝 %s", hookedWork))
-	} else if townRoot != "" {
-		// Priority 2: Fall back to mail preview
-		unread, subject := getMailPreviewWithRoot(identity, 35, townRoot)
-		if unread > 0 {
-			if subject != "" {
-				parts = append(parts, fmt.Sprintf("\U0001F4EC %s", subject))
-			} else {
-				parts = append(parts, fmt.Sprintf("\U0001F4EC %d", unread))
-			}
-		}
-	}
-
-	fmt.Print(strings.Join(parts, " | ") + " |")
-	return nil
-}
-
-// runRefineryStatusLine outputs status for a refinery session.
-// Shows: MQ length, current item, hook or mail preview
-func runRefineryStatusLine(t *tmux.Tmux, rigName string) error {
-	if rigName == "" {
-		// Try to extract from session name: gt-<rig>-refinery
-		if strings.HasPrefix(statusLineSession, "gt-") && strings.HasSuffix(statusLineSession, "-refinery") {
-			rigName = strings.TrimPrefix(statusLineSession, "gt-")
-			rigName = strings.TrimSuffix(rigName, "-refinery")
-		}
-	}
-
-	if rigName == "" {
-		fmt.Printf("%s ? |", AgentTypeIcons[AgentRefinery])
-		return nil
-	}
-
-	// Get town root from refinery pane's working directory
-	var townRoot string
-	sessionName := fmt.Sprintf("gt-%s-refinery", rigName)
-	paneDir, err := t.GetPaneWorkDir(sessionName)
-	if err == nil && paneDir != "" {
-		townRoot, _ = workspace.Find(paneDir)
-	}
-
-	// Get refinery manager using shared helper
-	mgr, _, _, err := getRefineryManager(rigName)
-	if err != nil {
-		// Fallback to simple status if we can't access refinery
-		fmt.Printf("%s MQ: ? |", AgentTypeIcons[AgentRefinery])
-		return nil
-	}
-
-	// Get queue
-	queue, err := mgr.Queue()
-	if err != nil {
-		// Fallback to simple status if we can't read queue
-		fmt.Printf("%s MQ: ? |", AgentTypeIcons[AgentRefinery])
-		return nil
-	}
-
-	// Count pending items and find current item
-	pending := 0
-	var currentItem string
-	for _, item := range queue {
-		if item.Position == 0 && item.MR != nil {
-			// Currently processing - show issue ID
-			currentItem = item.MR.IssueID
-		} else {
-			pending++
-		}
-	}
-
-	identity := fmt.Sprintf("%s/refinery", rigName)
-
-	// Build status
-	var parts []string
-	if currentItem != "" {
-		parts = append(parts, fmt.Sprintf("merging %s", currentItem))
-		if pending > 0 {
-			parts = append(parts, fmt.Sprintf("+%d queued", pending))
-		}
-	} else if pending > 0 {
-		parts = append(parts, fmt.Sprintf("%d queued", pending))
-	} else {
-		parts = append(parts, "idle")
-	}
-
-	// Priority 1: Check for hooked work (rig beads for refinery)
-	hookedWork := ""
-	if townRoot != "" && rigName != "" {
-		rigBeadsDir := filepath.Join(townRoot, rigName, "mayor", "rig")
-		hookedWork = getHookedWork(identity, 25, rigBeadsDir)
-	}
-	if hookedWork != "" {
-		parts = append(parts, fmt.Sprintf("🪝 %s", hookedWork))
-	} else if townRoot != "" {
-		// Priority 2: Fall back to mail preview
-		unread, subject := getMailPreviewWithRoot(identity, 30, townRoot)
-		if unread > 0 {
-			if subject != "" {
-				parts = append(parts, fmt.Sprintf("\U0001F4EC %s", subject))
-			} else {
-				parts = append(parts, fmt.Sprintf("\U0001F4EC %d", unread))
-			}
-		}
-	}
-
-	fmt.Print(strings.Join(parts, " | ") + " |")
-	return nil
+	return strings.Join(rigParts, " ")
 }
 
 // isSessionWorking detects if a Claude Code session is actively working.
@@ -642,86 +646,26 @@ func isSessionWorking(t *tmux.Tmux, session string) bool {
 	return false
 }
 
-// getUnreadMailCount returns unread mail count for an identity.
-// Fast path - returns 0 on any error.
-func getUnreadMailCount(identity string) int {
-	// Find workspace
-	workDir, err := findMailWorkDir()
-	if err != nil {
-		return 0
-	}
-
-	// Create mailbox using beads
-	mailbox := mail.NewMailboxBeads(identity, workDir)
-
-	// Get count
-	_, unread, err := mailbox.Count()
-	if err != nil {
-		return 0
-	}
-
-	return unread
-}
-
-// getMailPreview returns unread count and a truncated subject of the first unread message.
-// Returns (count, subject) where subject is empty if no unread mail.
-func getMailPreview(identity string, maxLen int) (int, string) {
-	workDir, err := findMailWorkDir()
-	if err != nil {
-		return 0, ""
-	}
-
-	mailbox := mail.NewMailboxBeads(identity, workDir)
-
-	// Get unread messages
-	messages, err := mailbox.ListUnread()
-	if err != nil || len(messages) == 0 {
-		return 0, ""
-	}
-
-	// Get first message subject, truncated
-	subject := messages[0].Subject
-	if len(subject) > maxLen {
-		subject = subject[:maxLen-1] + "…"
-	}
-
-	return len(messages), subject
-}
-
-// getMailPreviewWithRoot is like getMailPreview but uses an explicit town root.
-func getMailPreviewWithRoot(identity string, maxLen int, townRoot string) (int, string) {
+// getMailPreview returns the unread count and the subject of the first
+// unread message for an identity in the given town.
+func getMailPreview(identity, townRoot string) (statusLineMail, error) {
 	// Use NewMailboxFromAddress to normalize identity (e.g., gastown/crew/gus -> gastown/gus)
 	mailbox := mail.NewMailboxFromAddress(identity, townRoot)
 
-	// Get unread messages
 	messages, err := mailbox.ListUnread()
-	if err != nil || len(messages) == 0 {
-		return 0, ""
+	if err != nil {
+		return statusLineMail{}, err
 	}
-
-	// Get first message subject, truncated
-	subject := messages[0].Subject
-	if len(subject) > maxLen {
-		subject = subject[:maxLen-1] + "…"
+	if len(messages) == 0 {
+		return statusLineMail{}, nil
 	}
-
-	return len(messages), subject
+	return statusLineMail{Count: len(messages), Subject: messages[0].Subject}, nil
 }
 
-// getHookedWork returns a truncated title of the hooked bead for an agent.
-// Returns empty string if nothing is hooked.
-// beadsDir should be the directory containing .beads (for rig-level) or
-// empty to use the town root (for town-level roles).
-func getHookedWork(identity string, maxLen int, beadsDir string) string {
-	// If no beadsDir specified, use town root
-	if beadsDir == "" {
-		var err error
-		beadsDir, err = findMailWorkDir()
-		if err != nil {
-			return ""
-		}
-	}
-
+// getHookedWork returns "id: title" of the first bead hooked to an agent.
+// Returns "" if nothing is hooked. beadsDir is the directory containing
+// .beads: the rig's mayor/rig for rig-level roles, the town root otherwise.
+func getHookedWork(identity, beadsDir string) (string, error) {
 	b := beads.New(beadsDir)
 
 	// Query for hooked beads assigned to this agent
@@ -731,31 +675,19 @@ func getHookedWork(identity string, maxLen int, beadsDir string) string {
 		Priority: -1,
 	})
 	if err != nil || len(hookedBeads) == 0 {
-		return ""
+		return "", err
 	}
 
-	// Return first hooked bead's ID and title, truncated
 	bead := hookedBeads[0]
-	display := fmt.Sprintf("%s: %s", bead.ID, bead.Title)
-	if len(display) > maxLen {
-		display = display[:maxLen-1] + "…"
-	}
-	return display
+	return fmt.Sprintf("%s: %s", bead.ID, bead.Title), nil
 }
 
-// getCurrentWork returns a truncated title of the first in_progress issue.
-// Uses the pane's working directory to find the beads.
-func getCurrentWork(t *tmux.Tmux, session string, maxLen int) string {
-	// Get the pane's working directory
-	workDir, err := t.GetPaneWorkDir(session)
-	if err != nil || workDir == "" {
-		return ""
-	}
-
+// getCurrentWork returns "id: title" of the first in_progress issue in a
+// working directory's beads, or "" if there is none.
+func getCurrentWork(workDir string) (string, error) {
 	// Check if there's a .beads directory
-	beadsDir := filepath.Join(workDir, ".beads")
-	if _, err := os.Stat(beadsDir); os.IsNotExist(err) {
-		return ""
+	if _, err := os.Stat(filepath.Join(workDir, ".beads")); os.IsNotExist(err) {
+		return "", nil
 	}
 
 	// Query beads for in_progress issues
@@ -765,14 +697,74 @@ func getCurrentWork(t *tmux.Tmux, session string, maxLen int) string {
 		Priority: -1,
 	})
 	if err != nil || len(issues) == 0 {
-		return ""
+		return "", err
 	}
 
-	// Return first issue's ID and title, truncated
 	issue := issues[0]
-	display := fmt.Sprintf("%s: %s", issue.ID, issue.Title)
-	if len(display) > maxLen {
-		display = display[:maxLen-1] + "…"
+	return fmt.Sprintf("%s: %s", issue.ID, issue.Title), nil
+}
+
+// getMergeQueueSummary returns the bead being merged and the number of
+// merge requests waiting behind it.
+func getMergeQueueSummary(rigName string) (statusLineMQ, error) {
+	mgr, _, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return statusLineMQ{}, err
+	}
+	queue, err := mgr.Queue()
+	if err != nil {
+		return statusLineMQ{}, err
+	}
+
+	summary := statusLineMQ{Known: true}
+	for _, item := range queue {
+		if item.Position == 0 && item.MR != nil {
+			// Currently processing - show issue ID
+			summary.Merging = item.MR.IssueID
+		} else {
+			summary.Pending++
+		}
+	}
+	return summary, nil
+}
+
+// contextPercentFromWorkDir estimates how full the agent's context window is
+// from the last assistant turn of its most recent Claude Code transcript.
+func contextPercentFromWorkDir(workDir string) (int, error) {
+	projectDir, err := getClaudeProjectDir(workDir)
+	if err != nil {
+		return 0, err
 	}
-	return display
+	transcriptPath, err := findLatestTranscript(projectDir)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.Open(transcriptPath) //nolint:gosec // G304: path is under the Claude projects dir
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var last *TranscriptUsage
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 256*1024), 1024*1024)
+	for scanner.Scan() {
+		var msg TranscriptMessage
+		if json.Unmarshal(scanner.Bytes(), &msg) != nil {
+			continue // Skip malformed lines
+		}
+		if msg.Type == "assistant" && msg.Message != nil && msg.Message.Usage != nil {
+			last = msg.Message.Usage
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if last == nil {
+		return 0, nil
+	}
+
+	tokens := last.InputTokens + last.CacheReadInputTokens + last.CacheCreationInputTokens
+	return min(100, tokens*100/claudeContextWindow), nil
 }
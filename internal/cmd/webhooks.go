@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/webhook"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Webhooks command flags
+var (
+	webhooksListJSON bool
+
+	webhooksTestType string
+
+	webhooksReplayID     string
+	webhooksReplayDryRun bool
+	webhooksReplayJSON   bool
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:     "webhooks",
+	GroupID: GroupConfig,
+	Short:   "Manage outbound webhook subscriptions",
+	RunE:    requireSubcommand,
+	Long: `Manage outbound webhooks that push town events to other systems.
+
+Subscriptions live under "webhooks" in the town settings/config.json:
+
+  {"webhooks": [{
+    "name": "ci",
+    "url": "https://ci.example.com/hooks/gastown",
+    "events": ["merged", "merge_failed", "done"],
+    "secret": "$GT_WEBHOOK_SECRET",
+    "template": "{\"text\": {{json (printf \"%s by %s\" .Type .Actor)}}}"
+  }]}
+
+The daemon tails .events.jsonl and POSTs each event whose type matches one
+of the subscription's globs (all events when "events" is empty). The body is
+the event as JSON, or the rendered Go template. With a secret, the body is
+signed with HMAC-SHA256 in X-Gastown-Signature ("sha256=<hex>"); a secret
+starting with "$" is read from that environment variable, and "$VAR" in
+header values is expanded too.
+
+Failed deliveries are retried with exponential backoff (2s, 4s, 8s, ...) up
+to max_attempts (default 5), then written to .runtime/webhooks/dead-letter.jsonl.
+Events reach each endpoint in order: a retrying delivery holds back the
+events behind it.
+
+Commands:
+  gt webhooks list            Subscriptions and delivery stats
+  gt webhooks test <name>     Send a test event now
+  gt webhooks replay [name]   Redeliver dead-lettered events`,
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List subscriptions and delivery stats",
+	Args:  cobra.NoArgs,
+	RunE:  runWebhooksList,
+}
+
+var webhooksTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Send a test event to a subscription",
+	Long: `Send a synthetic event to a subscription and report the response.
+
+The event goes straight to the endpoint, bypassing the daemon, the event
+filter and retries. Use --type to check how a template renders a given
+event type.
+
+Examples:
+  gt webhooks test ci
+  gt webhooks test chat --type merged`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWebhooksTest,
+}
+
+var webhooksReplayCmd = &cobra.Command{
+	Use:   "replay [name]",
+	Short: "Redeliver dead-lettered events",
+	Long: `Redeliver events that exhausted their delivery attempts.
+
+Each dead letter is tried once, with its original delivery ID. Successful
+ones are removed from the dead-letter file; failures stay for next time.
+
+Examples:
+  gt webhooks replay --dry-run     # Show what is dead-lettered
+  gt webhooks replay               # Replay everything
+  gt webhooks replay ci            # Only the "ci" subscription
+  gt webhooks replay --id 3f9a2c1e77d04b18`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWebhooksReplay,
+}
+
+func init() {
+	webhooksListCmd.Flags().BoolVar(&webhooksListJSON, "json", false, "Output as JSON")
+
+	webhooksTestCmd.Flags().StringVar(&webhooksTestType, "type", webhook.TestEventType, "Event type to send")
+
+	webhooksReplayCmd.Flags().StringVar(&webhooksReplayID, "id", "", "Replay only this delivery ID")
+	webhooksReplayCmd.Flags().BoolVar(&webhooksReplayDryRun, "dry-run", false, "List dead letters without sending")
+	webhooksReplayCmd.Flags().BoolVar(&webhooksReplayJSON, "json", false, "Output as JSON")
+
+	webhooksCmd.AddCommand(webhooksListCmd)
+	webhooksCmd.AddCommand(webhooksTestCmd)
+	webhooksCmd.AddCommand(webhooksReplayCmd)
+	rootCmd.AddCommand(webhooksCmd)
+}
+
+// WebhookListItem is the JSON form of a subscription in gt webhooks list.
+type WebhookListItem struct {
+	Name        string         `json:"name"`
+	URL         string         `json:"url"`
+	Events      []string       `json:"events,omitempty"`
+	Disabled    bool           `json:"disabled,omitempty"`
+	Stats       webhook.Stats  `json:"stats"`
+	Retry       *webhook.Retry `json:"retry,omitempty"`
+	DeadLetters int            `json:"dead_letters"`
+}
+
+func runWebhooksList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	subs, err := webhook.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	dead, err := webhook.ListDeadLetters(townRoot)
+	if err != nil {
+		return err
+	}
+	deadBySub := make(map[string]int)
+	for _, dl := range dead {
+		deadBySub[dl.Subscription]++
+	}
+
+	items := make([]WebhookListItem, 0, len(subs))
+	for _, sub := range subs {
+		item := WebhookListItem{
+			Name:        sub.Name,
+			URL:         sub.URL,
+			Events:      sub.Events,
+			Disabled:    sub.Disabled,
+			DeadLetters: deadBySub[sub.Name],
+		}
+		if st, err := webhook.LoadState(townRoot, sub.Name); err == nil && st != nil {
+			item.Stats = st.Stats
+			item.Retry = st.Retry
+		}
+		items = append(items, item)
+	}
+
+	if webhooksListJSON {
+		return outputJSON(items)
+	}
+	if len(items) == 0 {
+		fmt.Println("No webhooks configured. Add them under \"webhooks\" in settings/config.json.")
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "NAME", Width: 16},
+		style.Column{Name: "EVENTS", Width: 20},
+		style.Column{Name: "URL", Width: 36},
+		style.Column{Name: "SENT", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "DEAD", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "STATUS", Width: 24},
+	)
+	for _, item := range items {
+		events := "*"
+		if len(item.Events) > 0 {
+			events = strings.Join(item.Events, ",")
+		}
+		table.AddRow(item.Name, events, item.URL,
+			fmt.Sprintf("%d", item.Stats.Delivered), fmt.Sprintf("%d", item.DeadLetters), webhookStatus(item))
+	}
+	fmt.Print(table.Render())
+	return nil
+}
+
+// webhookStatus summarizes a subscription's delivery health.
+func webhookStatus(item WebhookListItem) string {
+	switch {
+	case item.Disabled:
+		return style.Dim.Render("disabled")
+	case item.Retry != nil:
+		return style.Warning.Render(fmt.Sprintf("retrying (%d failed)", item.Retry.Attempts))
+	case !item.Stats.LastFailed.IsZero() && item.Stats.LastFailed.After(item.Stats.LastDelivered):
+		return style.Error.Render("failing: " + item.Stats.LastError)
+	case !item.Stats.LastDelivered.IsZero():
+		return "ok, " + formatAge(item.Stats.LastDelivered)
+	default:
+		return style.Dim.Render("no deliveries yet")
+	}
+}
+
+func runWebhooksTest(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	subs, err := webhook.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	sub := webhook.Find(subs, args[0])
+	if sub == nil {
+		return fmt.Errorf("no webhook named %q", args[0])
+	}
+
+	event := webhook.TestEvent(webhooksTestType)
+	id := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	res := webhook.Send(context.Background(), &http.Client{Timeout: webhook.RequestTimeout}, sub, id, event)
+	if !res.OK() {
+		return fmt.Errorf("%s: %s", sub.Name, res.Error)
+	}
+	fmt.Printf("%s %s: HTTP %d in %s\n", style.Bold.Render("✓"), sub.Name, res.Status, res.Duration.Round(time.Millisecond))
+	return nil
+}
+
+func runWebhooksReplay(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	subs, err := webhook.Load(townRoot)
+	if err != nil {
+		return err
+	}
+
+	match := func(dl webhook.DeadLetter) bool {
+		if len(args) > 0 && dl.Subscription != args[0] {
+			return false
+		}
+		return webhooksReplayID == "" || dl.ID == webhooksReplayID
+	}
+
+	if webhooksReplayDryRun {
+		dead, err := webhook.ListDeadLetters(townRoot)
+		if err != nil {
+			return err
+		}
+		var selected []webhook.DeadLetter
+		for _, dl := range dead {
+			if match(dl) {
+				selected = append(selected, dl)
+			}
+		}
+		if webhooksReplayJSON {
+			return outputJSON(selected)
+		}
+		if len(selected) == 0 {
+			fmt.Println("No dead letters.")
+			return nil
+		}
+		for _, dl := range selected {
+			fmt.Printf("  %s  %-16s %-20s %s  %s\n", dl.ID, dl.Subscription, dl.Event.Type,
+				formatAge(dl.FailedAt), style.Dim.Render(dl.LastError))
+		}
+		return nil
+	}
+
+	results, err := webhook.Replay(context.Background(), townRoot, subs, match)
+	if webhooksReplayJSON {
+		if jsonErr := outputJSON(results); jsonErr != nil {
+			return jsonErr
+		}
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Println("No dead letters to replay.")
+		return err
+	}
+
+	delivered := 0
+	for _, r := range results {
+		if r.Result.OK() {
+			delivered++
+			fmt.Printf("  %s %s %s %s\n", style.Success.Render("✓"), r.DeadLetter.ID, r.DeadLetter.Subscription, r.DeadLetter.Event.Type)
+		} else {
+			fmt.Printf("  %s %s %s %s: %s\n", style.Error.Render("✗"), r.DeadLetter.ID, r.DeadLetter.Subscription, r.DeadLetter.Event.Type, r.Result.Error)
+		}
+	}
+	fmt.Printf("\nReplayed %d of %d dead letter(s).\n", delivered, len(results))
+	return err
+}
@@ -29,6 +29,8 @@ const (
 	AgentAmp AgentPreset = "amp"
 	// AgentOpenCode is OpenCode multi-model CLI.
 	AgentOpenCode AgentPreset = "opencode"
+	// AgentMock is the scriptable mock agent for end-to-end tests.
+	AgentMock AgentPreset = "mock"
 )
 
 // AgentPresetInfo contains the configuration details for an agent preset.
@@ -203,6 +205,17 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 			OutputFlag: "--format json",
 		},
 	},
+	AgentMock: {
+		Name:                AgentMock,
+		Command:             "gt",
+		Args:                []string{"mock-agent"}, // Scenario from GT_MOCK_SCENARIO
+		ProcessNames:        []string{"gt"},
+		SessionIDEnv:        "",
+		ResumeFlag:          "",
+		ResumeStyle:         "",
+		SupportsHooks:       false, // Runs gt prime itself
+		SupportsForkSession: false,
+	},
 }
 
 // Registry state with proper synchronization.
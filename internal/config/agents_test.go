@@ -83,6 +83,7 @@ func TestRuntimeConfigFromPreset(t *testing.T) {
 		{AgentCursor, "cursor-agent"},
 		{AgentAuggie, "auggie"},
 		{AgentAmp, "amp"},
+		{AgentMock, "gt"},
 	}
 
 	for _, tt := range tests {
@@ -102,6 +103,23 @@ func TestRuntimeConfigFromPreset(t *testing.T) {
 	}
 }
 
+func TestMockPresetRuntimeDefaults(t *testing.T) {
+	t.Parallel()
+	rc := RuntimeConfigFromPreset(AgentMock)
+	if got := rc.BuildCommandWithPrompt("beacon"); got != `gt mock-agent "beacon"` {
+		t.Errorf("BuildCommandWithPrompt = %q", got)
+	}
+	if rc.Tmux.ReadyPromptPrefix != "❯ " {
+		t.Errorf("ReadyPromptPrefix = %q, want the prompt the mock agent prints", rc.Tmux.ReadyPromptPrefix)
+	}
+	if rc.Hooks.Provider != "none" {
+		t.Errorf("Hooks.Provider = %q, want none", rc.Hooks.Provider)
+	}
+	if len(rc.Tmux.ProcessNames) != 1 || rc.Tmux.ProcessNames[0] != "gt" {
+		t.Errorf("ProcessNames = %v, want [gt]", rc.Tmux.ProcessNames)
+	}
+}
+
 func TestRuntimeConfigFromPresetReturnsNilEnvForPresetsWithoutEnv(t *testing.T) {
 	t.Parallel()
 	// Built-in presets like Claude don't have Env set
@@ -131,6 +149,7 @@ func TestIsKnownPreset(t *testing.T) {
 		{"amp", true},
 		{"aider", false},    // Not built-in, can be added via config
 		{"opencode", true},  // Built-in multi-model CLI agent
+		{"mock", true},      // Built-in scriptable test agent
 		{"unknown", false},
 		{"chatgpt", false},
 	}
@@ -413,7 +432,7 @@ func TestGetProcessNames(t *testing.T) {
 func TestListAgentPresetsMatchesConstants(t *testing.T) {
 	t.Parallel()
 	// Ensure all AgentPreset constants are returned by ListAgentPresets
-	allConstants := []AgentPreset{AgentClaude, AgentGemini, AgentCodex, AgentCursor, AgentAuggie, AgentAmp}
+	allConstants := []AgentPreset{AgentClaude, AgentGemini, AgentCodex, AgentCursor, AgentAuggie, AgentAmp, AgentMock}
 	presets := ListAgentPresets()
 
 	// Convert to map for quick lookup
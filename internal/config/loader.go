@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -655,6 +656,9 @@ func validateMessagingConfig(c *MessagingConfig) error {
 	if c.NudgeChannels == nil {
 		c.NudgeChannels = make(map[string][]string)
 	}
+	if c.Rules == nil {
+		c.Rules = make(map[string][]MailRule)
+	}
 
 	// Validate lists have at least one recipient
 	for name, recipients := range c.Lists {
@@ -693,6 +697,45 @@ func validateMessagingConfig(c *MessagingConfig) error {
 		}
 	}
 
+	for mailbox, rules := range c.Rules {
+		for i, rule := range rules {
+			if err := validateMailRule(rule); err != nil {
+				return fmt.Errorf("mail rule %s[%d] %q: %w", mailbox, i, rule.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateMailRule checks a mail rule's name, patterns and actions.
+func validateMailRule(r MailRule) error {
+	if r.Name == "" {
+		return fmt.Errorf("%w: name", ErrMissingField)
+	}
+	for _, re := range []string{r.Match.Subject, r.Match.Body} {
+		if _, err := regexp.Compile(re); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	switch strings.TrimSuffix(r.Match.Priority, "+") {
+	case "", "low", "normal", "high", "urgent":
+	default:
+		return fmt.Errorf("invalid priority %q: must be low, normal, high or urgent", r.Match.Priority)
+	}
+	switch r.Delivery {
+	case "", "interrupt", "queue":
+	default:
+		return fmt.Errorf("invalid delivery %q: must be interrupt or queue", r.Delivery)
+	}
+	if r.Escalate != "" && !IsValidSeverity(r.Escalate) {
+		return fmt.Errorf("invalid escalate severity %q: must be critical, high, medium, or low", r.Escalate)
+	}
+	for _, addr := range r.Forward {
+		if addr == "" {
+			return fmt.Errorf("%w: forward address", ErrMissingField)
+		}
+	}
 	return nil
 }
 
@@ -747,6 +747,49 @@ func TestMessagingConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid config with rules",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules: map[string][]MailRule{
+					"mayor/":    {{Name: "done", Match: MailMatch{Protocol: "polecat_done"}, Archive: true}},
+					"*/witness": {{Name: "loud", Match: MailMatch{Priority: "high+", Subject: "^HELP"}, Delivery: "interrupt"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "rule without name",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules:   map[string][]MailRule{"mayor/": {{Archive: true}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rule with bad subject pattern",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules:   map[string][]MailRule{"mayor/": {{Name: "bad", Match: MailMatch{Subject: "("}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rule with bad delivery",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules:   map[string][]MailRule{"mayor/": {{Name: "bad", Delivery: "now"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rule with bad escalation severity",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules:   map[string][]MailRule{"mayor/": {{Name: "bad", Escalate: "panic"}}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
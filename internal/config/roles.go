@@ -38,6 +38,9 @@ type RoleDefinition struct {
 
 	// PromptTemplate is the name of the role's prompt template file.
 	PromptTemplate string `toml:"prompt_template,omitempty"`
+
+	// Resources contains resource limits applied to the session on Linux.
+	Resources RoleResourceConfig `toml:"resources"`
 }
 
 // RoleSessionConfig contains session-related configuration.
@@ -75,6 +78,31 @@ type RoleHealthConfig struct {
 	StuckThreshold Duration `toml:"stuck_threshold"`
 }
 
+// RoleResourceConfig contains resource limits for an agent session.
+// Limits are enforced with cgroup v2 on Linux; a zero value means unlimited.
+type RoleResourceConfig struct {
+	// CPUWeight is the session's relative CPU share (cgroup cpu.weight,
+	// 1-10000; the kernel default is 100).
+	CPUWeight int `toml:"cpu_weight,omitempty" json:"cpu_weight,omitempty"`
+
+	// MemoryMax is the hard memory limit (e.g., "4G", "512M"). The kernel
+	// OOM-kills the session's processes when it is exceeded.
+	MemoryMax string `toml:"memory_max,omitempty" json:"memory_max,omitempty"`
+
+	// PidsMax is the maximum number of processes and threads.
+	PidsMax int `toml:"pids_max,omitempty" json:"pids_max,omitempty"`
+
+	// Network is "host" (default) or "none". "none" runs the session in an
+	// empty network namespace, which also cuts off the model API, so it is
+	// only useful for runtimes that don't need the network.
+	Network string `toml:"network,omitempty" json:"network,omitempty"`
+}
+
+// IsZero reports whether no limits are configured.
+func (rc RoleResourceConfig) IsZero() bool {
+	return rc.CPUWeight == 0 && rc.MemoryMax == "" && rc.PidsMax == 0 && (rc.Network == "" || rc.Network == "host")
+}
+
 // Duration is a wrapper for time.Duration that supports TOML marshaling.
 type Duration struct {
 	time.Duration
@@ -247,6 +275,20 @@ func mergeRoleDefinition(base, override *RoleDefinition) {
 		base.Health.StuckThreshold = override.Health.StuckThreshold
 	}
 
+	// Resource limits
+	if override.Resources.CPUWeight != 0 {
+		base.Resources.CPUWeight = override.Resources.CPUWeight
+	}
+	if override.Resources.MemoryMax != "" {
+		base.Resources.MemoryMax = override.Resources.MemoryMax
+	}
+	if override.Resources.PidsMax != 0 {
+		base.Resources.PidsMax = override.Resources.PidsMax
+	}
+	if override.Resources.Network != "" {
+		base.Resources.Network = override.Resources.Network
+	}
+
 	// Prompts
 	if override.Nudge != "" {
 		base.Nudge = override.Nudge
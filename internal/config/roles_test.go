@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -270,3 +272,38 @@ func TestToLegacyRoleConfig(t *testing.T) {
 		t.Errorf("ConsecutiveFailures = %d, want 3", legacy.ConsecutiveFailures)
 	}
 }
+
+func TestLoadRoleDefinition_ResourceOverrides(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "gastown")
+	for _, dir := range []string{filepath.Join(townRoot, "roles"), filepath.Join(rigPath, "roles")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	town := "[resources]\ncpu_weight = 50\nmemory_max = \"4G\"\n"
+	rig := "[resources]\nmemory_max = \"8G\"\npids_max = 512\n"
+	if err := os.WriteFile(filepath.Join(townRoot, "roles", "polecat.toml"), []byte(town), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rigPath, "roles", "polecat.toml"), []byte(rig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	def, err := LoadRoleDefinition(townRoot, rigPath, "polecat")
+	if err != nil {
+		t.Fatalf("LoadRoleDefinition: %v", err)
+	}
+	want := RoleResourceConfig{CPUWeight: 50, MemoryMax: "8G", PidsMax: 512}
+	if def.Resources != want {
+		t.Errorf("Resources = %+v, want %+v", def.Resources, want)
+	}
+
+	builtin, err := LoadRoleDefinition(townRoot, "", "crew")
+	if err != nil {
+		t.Fatalf("LoadRoleDefinition: %v", err)
+	}
+	if !builtin.Resources.IsZero() {
+		t.Errorf("built-in crew resources = %+v, want none", builtin.Resources)
+	}
+}
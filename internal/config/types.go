@@ -68,6 +68,203 @@ type TownSettings struct {
 	// Agent addresses like "gastown/crew/jack" become "gastown.crew.jack@{domain}".
 	// Default: "gastown.local"
 	AgentEmailDomain string `json:"agent_email_domain,omitempty"`
+
+	// StatusLine customizes the tmux status line for each role, town-wide.
+	// Rig settings can override individual roles.
+	StatusLine *StatusLineConfig `json:"status_line,omitempty"`
+
+	// Webhooks are outbound subscriptions: the daemon POSTs matching events
+	// from .events.jsonl to each URL. See gt webhooks.
+	Webhooks []*WebhookConfig `json:"webhooks,omitempty"`
+
+	// Inbound configures the HTTP receiver that lets external systems (CI,
+	// review tools, forms) close gates, create beads, sling formulas and
+	// send mail. See gt inbound.
+	Inbound *InboundConfig `json:"inbound,omitempty"`
+
+	// Email bridges mail to a human's inbox: mail for the overseer and
+	// chosen mailing lists goes out over SMTP, and replies come back as
+	// mail. See gt mail bridge.
+	Email *EmailConfig `json:"email,omitempty"`
+
+	// Digest batches notifications for muted agents (gt dnd on) into
+	// periodic digest mail. See gt mail digest.
+	Digest *DigestConfig `json:"digest,omitempty"`
+
+	// Search configures the gt search index.
+	Search *SearchConfig `json:"search,omitempty"`
+
+	// Routing tunes how gt sling --auto picks a polecat identity and
+	// runtime for a bead.
+	Routing *RoutingConfig `json:"routing,omitempty"`
+}
+
+// RoutingConfig tunes skill-based work routing (town settings).
+type RoutingConfig struct {
+	// Agents are the runtimes --auto may start a polecat with. Empty means
+	// the rig's polecat agent only.
+	Agents []string `json:"agents,omitempty"`
+
+	// CostTiers sets the cost tier of agents, from 1 (cheap) to 3
+	// (expensive). Agents not listed are guessed from their name: haiku,
+	// flash and mini models are 1, opus is 3, the rest 2.
+	CostTiers map[string]int `json:"cost_tiers,omitempty"`
+
+	// MinTiers keeps beads with a label off cheaper runtimes.
+	// Example: {"security": 3, "architecture": 3}
+	MinTiers map[string]int `json:"min_tiers,omitempty"`
+
+	// Weights of the score components. Zero fields use the defaults.
+	Weights *RoutingWeights `json:"weights,omitempty"`
+}
+
+// RoutingWeights weighs the parts of a routing score.
+type RoutingWeights struct {
+	Skill       float64 `json:"skill,omitempty"`       // past success on similar beads
+	Reliability float64 `json:"reliability,omitempty"` // few failures on similar beads
+	Load        float64 `json:"load,omitempty"`        // little work already hooked
+	Cost        float64 `json:"cost,omitempty"`        // cheap runtime
+}
+
+// SearchConfig configures the town-wide search index (town settings).
+type SearchConfig struct {
+	// Transcripts also indexes agent session transcripts. They are large
+	// and may hold secrets pasted into a session, so they are opt-in.
+	Transcripts bool `json:"transcripts,omitempty"`
+}
+
+// WebhookConfig is one outbound webhook subscription (town settings).
+type WebhookConfig struct {
+	// Name identifies the subscription in gt webhooks and its delivery state.
+	Name string `json:"name"`
+
+	// URL receives an HTTP POST per matching event.
+	URL string `json:"url"`
+
+	// Events are event type globs ("*" matches anything), e.g. ["merge*", "done"].
+	// If empty, every event is delivered.
+	Events []string `json:"events,omitempty"`
+
+	// Secret signs each body with HMAC-SHA256 in the X-Gastown-Signature header.
+	// A value starting with "$" names an environment variable holding the secret.
+	Secret string `json:"secret,omitempty"`
+
+	// Template is a Go template for the request body, evaluated against the
+	// event (.Type, .Actor, .Timestamp, .Payload, ...). If empty, the event
+	// is sent as JSON.
+	Template string `json:"template,omitempty"`
+
+	// Headers are extra request headers, e.g. {"Authorization": "Bearer ..."}.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// MaxAttempts is how many times a delivery is tried before it is
+	// dead-lettered. Default: 5.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+
+	// Disabled pauses delivery; events are skipped, not queued.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// InboundConfig configures the inbound webhook receiver (town settings).
+type InboundConfig struct {
+	// Secret verifies the X-Gastown-Signature of every request.
+	// A value starting with "$" names an environment variable holding it.
+	// Requests are rejected while no secret is configured.
+	Secret string `json:"secret,omitempty"`
+
+	// Secrets override Secret per source, so each sender gets its own key.
+	Secrets map[string]string `json:"secrets,omitempty"`
+
+	// MaxSkew is how far a request's X-Gastown-Timestamp may be from the
+	// receiver's clock, as a duration ("5m"). Default: 5m.
+	MaxSkew string `json:"max_skew,omitempty"`
+
+	// Rules map requests to actions. Every matching rule runs, in order.
+	Rules []*InboundRule `json:"rules,omitempty"`
+}
+
+// InboundRule maps payloads posted to /hooks/<source> to one action.
+//
+// String parameters are Go templates evaluated against the JSON payload,
+// e.g. "{{.workflow_run.head_sha}}".
+type InboundRule struct {
+	// Name identifies the rule in responses and the event log.
+	Name string `json:"name"`
+
+	// Source is the endpoint the rule listens on: /hooks/<source>.
+	Source string `json:"source"`
+
+	// Match requires payload fields to match globs, e.g.
+	// {"action": "completed", "workflow_run.conclusion": "success"}.
+	// Keys are dotted paths into the payload; "header:<Name>" matches a
+	// request header instead. An empty Match accepts every request.
+	Match map[string]string `json:"match,omitempty"`
+
+	// Action is one of gate_close, gate_approve, create_bead, sling, mail.
+	Action string `json:"action"`
+
+	// Gate and Reason are used by gate_close and gate_approve. Waiters are
+	// woken once the gate closes.
+	Gate   string `json:"gate,omitempty"`
+	Reason string `json:"reason,omitempty"`
+
+	// Rig, Title, Description and Priority are used by create_bead.
+	// An empty Rig creates the bead in town beads.
+	Rig         string `json:"rig,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Priority    int    `json:"priority,omitempty"`
+
+	// Formula, Target and Vars are used by sling.
+	Formula string            `json:"formula,omitempty"`
+	Target  string            `json:"target,omitempty"`
+	Vars    map[string]string `json:"vars,omitempty"`
+
+	// To, Subject and Body are used by mail. To may be any mail address,
+	// including "channel:<name>" and "group:<name>".
+	To      string `json:"to,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body,omitempty"`
+}
+
+// EmailConfig configures the email bridge (town settings).
+type EmailConfig struct {
+	// To receives mail addressed to the overseer. Default: the email in
+	// mayor/overseer.json.
+	To []string `json:"to,omitempty"`
+
+	// Lists emails mail sent to a mailing list, once per send rather than
+	// once per member. Example: {"oncall": ["ops@example.com"]}
+	Lists map[string][]string `json:"lists,omitempty"`
+
+	// From is the sender of forwarded mail. Replies to it must end up in
+	// Maildir for the bridge to see them.
+	From string `json:"from"`
+
+	// SMTP is the relay as host:port, e.g. "smtp.example.com:587".
+	SMTP string `json:"smtp"`
+
+	// Username and Password authenticate to the relay with PLAIN auth,
+	// which is only used over TLS or to localhost. A Password starting
+	// with "$" names an environment variable holding it.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Maildir is where replies are delivered, e.g. by mbsync or fetchmail
+	// from an IMAP account. "~/" is the home directory; other relative
+	// paths are under the town root. Replies are not ingested while it is
+	// unset.
+	Maildir string `json:"maildir,omitempty"`
+
+	// Disabled pauses the bridge in both directions.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// DigestConfig schedules digests for muted agents (town settings).
+type DigestConfig struct {
+	// Interval is how long a notification is held before the daemon mails
+	// the digest that lists it, e.g. "30m". Default: "1h".
+	Interval string `json:"interval,omitempty"`
 }
 
 // NewTownSettings creates a new TownSettings with defaults.
@@ -207,6 +404,31 @@ type WorkflowConfig struct {
 	DefaultFormula string `json:"default_formula,omitempty"`
 }
 
+// RecordingConfig represents session recording settings for a rig.
+type RecordingConfig struct {
+	// Enabled archives the full output of the rig's polecat and crew sessions
+	// as asciicast v2 files under <rig>/.runtime/recordings/.
+	Enabled bool `json:"enabled"`
+
+	// TTL is how long recordings are kept, as a Go duration (e.g., "72h").
+	// If empty, recordings are kept for 72 hours.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// StatusLineConfig customizes the tmux status line (town or rig settings).
+type StatusLineConfig struct {
+	// Templates maps role names to Go templates rendered by gt status-line.
+	// Keys: "mayor", "deacon", "witness", "refinery", "polecat", "crew".
+	// Roles without an entry use the rig's, then the town's, then the built-in template.
+	// Example: {"polecat": "{{join .Icon .Hook .Context .Cost}} |"}
+	Templates map[string]string `json:"templates,omitempty"`
+
+	// CacheTTL is how long bd and transcript lookups are reused between
+	// refreshes, as a Go duration (e.g., "30s"). "0" disables caching.
+	// If empty, results are cached for 30 seconds.
+	CacheTTL string `json:"cache_ttl,omitempty"`
+}
+
 // RigSettings represents per-rig behavioral configuration (settings/config.json).
 type RigSettings struct {
 	Type       string            `json:"type"`                  // "rig-settings"
@@ -216,6 +438,8 @@ type RigSettings struct {
 	Namepool   *NamepoolConfig   `json:"namepool,omitempty"`    // polecat name pool settings
 	Crew       *CrewConfig       `json:"crew,omitempty"`        // crew startup settings
 	Workflow   *WorkflowConfig   `json:"workflow,omitempty"`    // workflow settings
+	Recording  *RecordingConfig  `json:"recording,omitempty"`   // session recording settings
+	StatusLine *StatusLineConfig `json:"status_line,omitempty"` // tmux status line templates
 	Runtime    *RuntimeConfig    `json:"runtime,omitempty"`     // LLM runtime settings (deprecated: use Agent)
 
 	// Agent selects which agent preset to use for this rig.
@@ -310,7 +534,9 @@ type RuntimeSessionConfig struct {
 
 // RuntimeHooksConfig configures runtime hook installation.
 type RuntimeHooksConfig struct {
-	// Provider controls which hook templates to install: "claude", "opencode", or "none".
+	// Provider controls how hooks are installed: "claude", "gemini",
+	// "cursor", "opencode", "instructions" (an AGENTS.md block that has the
+	// agent run gt hooks run), or "none".
 	Provider string `json:"provider,omitempty"`
 
 	// Dir is the settings directory (e.g., ".claude").
@@ -478,6 +704,8 @@ func defaultRuntimeCommand(provider string) string {
 		return "codex"
 	case "opencode":
 		return "opencode"
+	case "mock":
+		return "gt"
 	case "generic":
 		return ""
 	default:
@@ -514,6 +742,8 @@ func defaultRuntimeArgs(provider string) []string {
 	switch provider {
 	case "claude":
 		return []string{"--dangerously-skip-permissions"}
+	case "mock":
+		return []string{"mock-agent"}
 	default:
 		return nil
 	}
@@ -546,10 +776,10 @@ func defaultConfigDirEnv(provider string) string {
 
 func defaultHooksProvider(provider string) string {
 	switch provider {
-	case "claude":
-		return "claude"
-	case "opencode":
-		return "opencode"
+	case "claude", "opencode", "gemini", "cursor":
+		return provider
+	case "codex", "auggie", "amp":
+		return "instructions"
 	default:
 		return "none"
 	}
@@ -593,8 +823,9 @@ func defaultProcessNames(provider, command string) []string {
 }
 
 func defaultReadyPromptPrefix(provider string) string {
-	if provider == "claude" {
-		// Claude Code uses ❯ (U+276F) as the prompt character
+	if provider == "claude" || provider == "mock" {
+		// Claude Code uses ❯ (U+276F) as the prompt character; the mock
+		// agent prints the same prompt whenever it waits for input.
 		return "❯ "
 	}
 	return ""
@@ -617,10 +848,8 @@ func defaultReadyDelayMs(provider string) int {
 }
 
 func defaultInstructionsFile(provider string) string {
-	if provider == "codex" {
-		return "AGENTS.md"
-	}
-	if provider == "opencode" {
+	switch provider {
+	case "codex", "opencode", "cursor", "auggie", "amp":
 		return "AGENTS.md"
 	}
 	return "CLAUDE.md"
@@ -708,7 +937,9 @@ type MergeQueueConfig struct {
 	// DeleteMergedBranches controls whether to delete branches after merging.
 	DeleteMergedBranches bool `json:"delete_merged_branches"`
 
-	// RetryFlakyTests is the number of times to retry flaky tests.
+	// RetryFlakyTests is the number of times to retry flaky tests. Without
+	// TestOutputFormat it counts whole runs of the command (at least one);
+	// with it, reruns of just the failing tests (0 = no reruns).
 	RetryFlakyTests int `json:"retry_flaky_tests"`
 
 	// PollInterval is how often to poll for new merge requests (e.g., "30s").
@@ -716,6 +947,13 @@ type MergeQueueConfig struct {
 
 	// MaxConcurrent is the maximum number of concurrent merges.
 	MaxConcurrent int `json:"max_concurrent"`
+
+	// PostMergeVerify runs VerifyCommand on the target after each merge lands.
+	// A failure reverts the merge and pauses the queue until the target is green.
+	PostMergeVerify bool `json:"post_merge_verify,omitempty"`
+
+	// VerifyCommand is the post-merge verification command (defaults to TestCommand).
+	VerifyCommand string `json:"verify_command,omitempty"`
 }
 
 // OnConflict strategy constants.
@@ -812,6 +1050,12 @@ type MessagingConfig struct {
 	// Like mailing lists but for tmux send-keys instead of durable mail.
 	// Example: {"workers": ["gastown/polecats/*", "gastown/crew/*"], "witnesses": ["*/witness"]}
 	NudgeChannels map[string][]string `json:"nudge_channels,omitempty"`
+
+	// Rules filter mail as it is delivered to a mailbox, keyed by mailbox
+	// address. '*' matches one path segment ("*/witness"); "*" alone matches
+	// every mailbox. A mailbox's own rules run before wildcard ones.
+	// Example: {"mayor/": [{"name": "done", "match": {"protocol": "polecat_done"}, "archive": true}]}
+	Rules map[string][]MailRule `json:"rules,omitempty"`
 }
 
 // QueueConfig represents a work queue configuration.
@@ -834,6 +1078,62 @@ type AnnounceConfig struct {
 	RetainCount int `json:"retain_count,omitempty"`
 }
 
+// MailRule matches mail delivered to a mailbox and acts on it. Rules run
+// in order and the first match stops evaluation unless it sets Continue,
+// so a rule with no actions lets a message through untouched.
+type MailRule struct {
+	// Name identifies the rule in labels and `gt mail rules test` output.
+	Name string `json:"name"`
+
+	// Match selects messages. Every field set must match.
+	Match MailMatch `json:"match"`
+
+	// Archive delivers the message already read, out of the inbox.
+	Archive bool `json:"archive,omitempty"`
+
+	// Labels are added to the message bead.
+	Labels []string `json:"labels,omitempty"`
+
+	// Forward sends a copy to each address. Copies skip mailbox rules.
+	Forward []string `json:"forward,omitempty"`
+
+	// Bead converts the message into a bead of this type (e.g. "task")
+	// in the town beads instead of delivering it.
+	Bead string `json:"bead,omitempty"`
+
+	// Escalate raises an escalation of this severity (low, medium, high,
+	// critical) routed by settings/escalation.json.
+	Escalate string `json:"escalate,omitempty"`
+
+	// Delivery overrides how the recipient is told: "interrupt" nudges
+	// the agent's session, "queue" leaves the message for `gt mail check`.
+	Delivery string `json:"delivery,omitempty"`
+
+	// Continue keeps evaluating later rules after this one matches.
+	Continue bool `json:"continue,omitempty"`
+}
+
+// MailMatch is the condition of a mail rule.
+type MailMatch struct {
+	// From is a sender address pattern ("gastown/polecats/*").
+	From string `json:"from,omitempty"`
+
+	// Subject is a regular expression matched against the subject.
+	Subject string `json:"subject,omitempty"`
+
+	// Protocol is the protocol message type from the subject: polecat_done,
+	// lifecycle_shutdown, help, merged, merge_failed, merge_ready,
+	// rework_request, handoff, swarm_start, or "none" for ordinary mail.
+	Protocol string `json:"protocol,omitempty"`
+
+	// Priority is low, normal, high or urgent; a trailing "+" also matches
+	// higher priorities ("high+").
+	Priority string `json:"priority,omitempty"`
+
+	// Body is a regular expression matched against the body.
+	Body string `json:"body,omitempty"`
+}
+
 // CurrentMessagingVersion is the current schema version for MessagingConfig.
 const CurrentMessagingVersion = 1
 
@@ -846,6 +1146,7 @@ func NewMessagingConfig() *MessagingConfig {
 		Queues:        make(map[string]QueueConfig),
 		Announces:     make(map[string]AnnounceConfig),
 		NudgeChannels: make(map[string][]string),
+		Rules:         make(map[string][]MailRule),
 	}
 }
 
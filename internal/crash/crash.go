@@ -0,0 +1,467 @@
+// Package crash captures forensic bundles when an agent's pane dies.
+//
+// The pane-died hook runs `gt log crash`, which calls Capture while the dead
+// pane still exists. A bundle records what would otherwise vanish with the
+// pane: its scrollback, the exit status, the session environment, the hooked
+// bead and molecule step, the worktree's git state, and the agent's recent
+// events. Bundles are stored under <town>/crashes/<id>/ and browsed with
+// `gt crash list` and `gt crash show`.
+package crash
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/recording"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// DirName is the crash bundle directory in the town root.
+const DirName = "crashes"
+
+// Bundle files.
+const (
+	bundleFile     = "bundle.json"
+	scrollbackFile = "scrollback.txt"
+)
+
+// DefaultScrollbackLines is how much scrollback a bundle keeps by default.
+const DefaultScrollbackLines = 5000
+
+// MaxBundles is how many bundles are kept; older ones are removed when a new
+// one is saved.
+const MaxBundles = 100
+
+// recentEventCount is how many of the agent's events a bundle keeps.
+const recentEventCount = 50
+
+// idTimeFormat is the timestamp prefix of a bundle ID.
+const idTimeFormat = "20060102-150405"
+
+// Bundle is the forensic record of one pane death.
+type Bundle struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Agent    string    `json:"agent"`             // As given by the pane-died hook, e.g. "gastown/Toast"
+	Address  string    `json:"address,omitempty"` // Full address, e.g. "gastown/polecats/Toast"
+	Session  string    `json:"session"`
+	ExitCode int       `json:"exit_code"`
+	Reason   string    `json:"reason"` // e.g. "exit code 1", or a resource limit kill
+
+	Worktree  string   `json:"worktree,omitempty"`
+	Branch    string   `json:"branch,omitempty"`
+	Head      string   `json:"head,omitempty"`
+	GitStatus []string `json:"git_status,omitempty"` // "M path", "?? path", ...
+
+	HookedBead   string `json:"hooked_bead,omitempty"`
+	HookedTitle  string `json:"hooked_title,omitempty"`
+	MoleculeStep string `json:"molecule_step,omitempty"` // In-progress step of the hooked molecule
+	StepTitle    string `json:"step_title,omitempty"`
+
+	Env          map[string]string `json:"env,omitempty"` // Session environment, secrets redacted
+	RecentEvents []events.Event    `json:"recent_events,omitempty"`
+	Recording    string            `json:"recording,omitempty"` // Session recording, if the rig records
+
+	ScrollbackLines int      `json:"scrollback_lines"`
+	Errors          []string `json:"errors,omitempty"` // Parts that couldn't be captured
+}
+
+// Options describes a pane death to capture.
+type Options struct {
+	TownRoot        string
+	Agent           string
+	Session         string
+	ExitCode        int
+	Reason          string
+	ScrollbackLines int // 0 means DefaultScrollbackLines
+}
+
+// Dir returns a town's crash bundle directory.
+func Dir(townRoot string) string {
+	return filepath.Join(townRoot, DirName)
+}
+
+// Capture collects a bundle for a dead pane and saves it. Each part is
+// best-effort: whatever can't be captured is noted in Errors rather than
+// failing the whole bundle.
+func Capture(t *tmux.Tmux, opts Options) (*Bundle, error) {
+	lines := opts.ScrollbackLines
+	if lines <= 0 {
+		lines = DefaultScrollbackLines
+	}
+	b := &Bundle{
+		Time:     time.Now().UTC(),
+		Agent:    opts.Agent,
+		Address:  opts.Agent,
+		Session:  opts.Session,
+		ExitCode: opts.ExitCode,
+		Reason:   opts.Reason,
+	}
+	fail := func(part string, err error) {
+		b.Errors = append(b.Errors, fmt.Sprintf("%s: %v", part, err))
+	}
+
+	var scrollback string
+	if opts.Session != "" {
+		var err error
+		if scrollback, err = t.CapturePane(opts.Session, lines); err != nil {
+			fail("scrollback", err)
+		}
+		if env, err := t.GetAllEnvironment(opts.Session); err != nil {
+			fail("environment", err)
+		} else {
+			b.Env = RedactEnv(env)
+			b.Address = addressFromEnv(env, opts.Agent)
+		}
+		if dir, err := t.GetPaneWorkDir(opts.Session); err == nil && dir != "" {
+			b.Worktree = dir
+		}
+	}
+	if b.Worktree == "" {
+		b.Worktree = worktreeFor(opts.TownRoot, b.Address)
+	}
+
+	if b.Worktree != "" {
+		g := git.NewGit(b.Worktree)
+		b.Branch, _ = g.CurrentBranch()
+		b.Head, _ = g.Rev("HEAD")
+		if status, err := g.Status(); err != nil {
+			fail("git status", err)
+		} else {
+			b.GitStatus = statusLines(status)
+		}
+		findHookedWork(b)
+	}
+
+	if recent, err := RecentEvents(opts.TownRoot, []string{b.Agent, b.Address}, recentEventCount); err != nil {
+		fail("events", err)
+	} else {
+		b.RecentEvents = recent
+	}
+
+	if rigPath := rigPathFor(opts.TownRoot, b.Address); rigPath != "" && opts.Session != "" {
+		if info, err := recording.Find([]string{rigPath}, opts.Session); err == nil {
+			b.Recording = info.Path
+		}
+	}
+
+	b.ScrollbackLines = strings.Count(scrollback, "\n")
+	if err := Save(opts.TownRoot, b, scrollback); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Save writes a bundle and its scrollback, assigning its ID, then prunes the
+// oldest bundles beyond MaxBundles. An agent crashing twice within a second
+// gets a -2, -3, ... suffix rather than overwriting its earlier bundle.
+func Save(townRoot string, b *Bundle, scrollback string) error {
+	if b.Time.IsZero() {
+		b.Time = time.Now().UTC()
+	}
+	// Bundles hold environment and output, so keep them private
+	if err := os.MkdirAll(Dir(townRoot), 0700); err != nil {
+		return fmt.Errorf("creating bundle dir: %w", err)
+	}
+	id, err := createBundleDir(townRoot, b.Time.Format(idTimeFormat)+"-"+strings.ReplaceAll(b.Agent, "/", "-"))
+	if err != nil {
+		return err
+	}
+	b.ID = id
+	dir := filepath.Join(Dir(townRoot), b.ID)
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, bundleFile), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, scrollbackFile), []byte(scrollback), 0600); err != nil {
+		return fmt.Errorf("writing scrollback: %w", err)
+	}
+
+	_, _ = Prune(townRoot, MaxBundles)
+	return nil
+}
+
+// createBundleDir creates a new bundle directory named id, or id-2, id-3, ...
+// if it is taken, and returns the name used.
+func createBundleDir(townRoot, id string) (string, error) {
+	for n := 1; ; n++ {
+		name := id
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d", id, n)
+		}
+		err := os.Mkdir(filepath.Join(Dir(townRoot), name), 0700)
+		if err == nil {
+			return name, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("creating bundle dir: %w", err)
+		}
+	}
+}
+
+// List returns a town's bundles, newest first.
+func List(townRoot string) ([]*Bundle, error) {
+	entries, err := os.ReadDir(Dir(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var bundles []*Bundle
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		b, err := Load(townRoot, e.Name())
+		if err != nil {
+			continue
+		}
+		bundles = append(bundles, b)
+	}
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].Time.After(bundles[j].Time) })
+	return bundles, nil
+}
+
+// Load reads a bundle by ID.
+func Load(townRoot, id string) (*Bundle, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return nil, fmt.Errorf("invalid crash bundle ID %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(Dir(townRoot), id, bundleFile)) //nolint:gosec // G304: ID is validated above
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no crash bundle %q", id)
+		}
+		return nil, err
+	}
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parsing bundle %s: %w", id, err)
+	}
+	return &b, nil
+}
+
+// Find looks up a bundle by ID or unique ID prefix.
+func Find(townRoot, id string) (*Bundle, error) {
+	if b, err := Load(townRoot, id); err == nil {
+		return b, nil
+	}
+	bundles, err := List(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	var matches []*Bundle
+	for _, b := range bundles {
+		if strings.HasPrefix(b.ID, id) {
+			matches = append(matches, b)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no crash bundle %q", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%q matches %d crash bundles; use a longer ID", id, len(matches))
+	}
+}
+
+// Scrollback returns a bundle's captured pane output.
+func Scrollback(townRoot, id string) (string, error) {
+	if _, err := Load(townRoot, id); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(Dir(townRoot), id, scrollbackFile)) //nolint:gosec // G304: ID is validated by Load
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Prune removes all but the newest keep bundles.
+func Prune(townRoot string, keep int) (int, error) {
+	entries, err := os.ReadDir(Dir(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() {
+			ids = append(ids, e.Name())
+		}
+	}
+	if len(ids) <= keep {
+		return 0, nil
+	}
+	// IDs start with a sortable timestamp
+	sort.Strings(ids)
+	removed := 0
+	for _, id := range ids[:len(ids)-keep] {
+		if err := os.RemoveAll(filepath.Join(Dir(townRoot), id)); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// RecentEvents returns the last n events whose actor is one of actors,
+// oldest first.
+func RecentEvents(townRoot string, actors []string, n int) ([]events.Event, error) {
+	f, err := os.Open(filepath.Join(townRoot, events.EventsFile)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	want := make(map[string]bool)
+	for _, a := range actors {
+		if a != "" {
+			want[a] = true
+		}
+	}
+
+	var recent []events.Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e events.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || !want[e.Actor] {
+			continue
+		}
+		recent = append(recent, e)
+		if len(recent) > n {
+			recent = recent[1:]
+		}
+	}
+	return recent, scanner.Err()
+}
+
+// secretMarkers are substrings of environment variable names whose values
+// are redacted from bundles.
+var secretMarkers = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "AUTH"}
+
+// RedactEnv returns env with the values of secret-looking variables replaced.
+func RedactEnv(env map[string]string) map[string]string {
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		upper := strings.ToUpper(k)
+		for _, marker := range secretMarkers {
+			if strings.Contains(upper, marker) {
+				v = "[redacted]"
+				break
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// addressFromEnv derives an agent's full address from its session
+// environment, falling back to the hook's agent ID.
+func addressFromEnv(env map[string]string, fallback string) string {
+	rig := env["GT_RIG"]
+	switch {
+	case rig != "" && env["GT_POLECAT"] != "":
+		return rig + "/polecats/" + env["GT_POLECAT"]
+	case rig != "" && env["GT_CREW"] != "":
+		return rig + "/crew/" + env["GT_CREW"]
+	case rig != "" && (env["GT_ROLE"] == "witness" || env["GT_ROLE"] == "refinery"):
+		return rig + "/" + env["GT_ROLE"]
+	}
+	return fallback
+}
+
+// rigPathFor returns the rig directory of a rig agent's address.
+func rigPathFor(townRoot, address string) string {
+	parts := strings.Split(address, "/")
+	if len(parts) < 2 || townRoot == "" {
+		return ""
+	}
+	return filepath.Join(townRoot, parts[0])
+}
+
+// worktreeFor guesses a polecat or crew worktree from its address, for when
+// the dead pane's working directory is unavailable.
+func worktreeFor(townRoot, address string) string {
+	parts := strings.Split(address, "/")
+	if len(parts) != 3 || townRoot == "" {
+		return ""
+	}
+	dir := filepath.Join(townRoot, parts[0], parts[1], parts[2])
+	// Polecats use polecats/<name>/<rig>/ in the new layout
+	if nested := filepath.Join(dir, parts[0]); isDir(nested) {
+		return nested
+	}
+	if isDir(dir) {
+		return dir
+	}
+	return ""
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// statusLines flattens a git status into short-format lines.
+func statusLines(s *git.GitStatus) []string {
+	var lines []string
+	for _, f := range s.Modified {
+		lines = append(lines, "M  "+f)
+	}
+	for _, f := range s.Added {
+		lines = append(lines, "A  "+f)
+	}
+	for _, f := range s.Deleted {
+		lines = append(lines, "D  "+f)
+	}
+	for _, f := range s.Untracked {
+		lines = append(lines, "?? "+f)
+	}
+	return lines
+}
+
+// findHookedWork fills in the bead hooked by the agent, or failing that the
+// bead it had in progress, and the current step if that bead is a molecule.
+func findHookedWork(b *Bundle) {
+	bd := beads.New(b.Worktree)
+	var hooked *beads.Issue
+	for _, status := range []string{beads.StatusHooked, "in_progress"} {
+		issues, err := bd.List(beads.ListOptions{Status: status, Assignee: b.Address, Priority: -1})
+		if err == nil && len(issues) > 0 {
+			hooked = issues[0]
+			break
+		}
+	}
+	if hooked == nil {
+		return
+	}
+	b.HookedBead = hooked.ID
+	b.HookedTitle = hooked.Title
+
+	steps, err := bd.List(beads.ListOptions{Parent: hooked.ID, Status: "in_progress", Priority: -1})
+	if err == nil && len(steps) > 0 {
+		b.MoleculeStep = steps[0].ID
+		b.StepTitle = steps[0].Title
+	}
+}
@@ -0,0 +1,145 @@
+package crash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveListFind(t *testing.T) {
+	town := t.TempDir()
+	base := time.Date(2026, 1, 12, 9, 30, 0, 0, time.UTC)
+
+	for i, agent := range []string{"gastown/Toast", "gastown/Nux"} {
+		b := &Bundle{Time: base.Add(time.Duration(i) * time.Minute), Agent: agent, Address: agent, Reason: "exit code 1"}
+		if err := Save(town, b, "line 1\nline 2\n"); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	bundles, err := List(town)
+	if err != nil || len(bundles) != 2 {
+		t.Fatalf("List = %d, %v; want 2", len(bundles), err)
+	}
+	if bundles[0].ID != "20260112-093100-gastown-Nux" {
+		t.Errorf("newest ID = %q", bundles[0].ID)
+	}
+
+	b, err := Find(town, "20260112-0930")
+	if err != nil || b.Agent != "gastown/Toast" {
+		t.Errorf("Find(prefix) = %+v, %v", b, err)
+	}
+	if _, err := Find(town, "20260112"); err == nil {
+		t.Error("Find accepted an ambiguous prefix")
+	}
+	if _, err := Load(town, "../etc"); err == nil {
+		t.Error("Load accepted a path")
+	}
+
+	scrollback, err := Scrollback(town, b.ID)
+	if err != nil || scrollback != "line 1\nline 2\n" {
+		t.Errorf("Scrollback = %q, %v", scrollback, err)
+	}
+	info, err := os.Stat(filepath.Join(Dir(town), b.ID, bundleFile))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("bundle file mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+}
+
+func TestSaveSameSecond(t *testing.T) {
+	town := t.TempDir()
+	at := time.Date(2026, 1, 12, 9, 30, 0, 0, time.UTC)
+
+	var ids []string
+	for _, reason := range []string{"exit code 1", "exit code 2"} {
+		b := &Bundle{Time: at, Agent: "gastown/Toast", Reason: reason}
+		if err := Save(town, b, reason); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		ids = append(ids, b.ID)
+	}
+	if ids[0] != "20260112-093000-gastown-Toast" || ids[1] != "20260112-093000-gastown-Toast-2" {
+		t.Fatalf("IDs = %v, want distinct bundles", ids)
+	}
+	for i, id := range ids {
+		b, err := Load(town, id)
+		if err != nil || b.Reason != fmt.Sprintf("exit code %d", i+1) {
+			t.Errorf("Load(%s) = %+v, %v", id, b, err)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	town := t.TempDir()
+	for i := 0; i < 5; i++ {
+		b := &Bundle{Time: time.Date(2026, 1, 12, 9, i, 0, 0, time.UTC), Agent: "gastown/Toast"}
+		if err := Save(town, b, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := Prune(town, 2)
+	if err != nil || n != 3 {
+		t.Fatalf("Prune = %d, %v; want 3", n, err)
+	}
+	bundles, _ := List(town)
+	if len(bundles) != 2 || bundles[1].ID != "20260112-090300-gastown-Toast" {
+		t.Errorf("kept %+v, want the two newest", bundles)
+	}
+}
+
+func TestRecentEvents(t *testing.T) {
+	town := t.TempDir()
+	var lines string
+	for i := 0; i < 5; i++ {
+		lines += fmt.Sprintf(`{"ts":"2026-01-12T09:0%d:00Z","type":"hook","actor":"gastown/polecats/Toast"}`+"\n", i)
+		lines += `{"ts":"2026-01-12T09:00:00Z","type":"mail","actor":"mayor"}` + "\n"
+	}
+	lines += "not json\n"
+	if err := os.WriteFile(filepath.Join(town, ".events.jsonl"), []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	recent, err := RecentEvents(town, []string{"gastown/Toast", "gastown/polecats/Toast"}, 3)
+	if err != nil {
+		t.Fatalf("RecentEvents: %v", err)
+	}
+	if len(recent) != 3 || recent[0].Timestamp != "2026-01-12T09:02:00Z" || recent[2].Timestamp != "2026-01-12T09:04:00Z" {
+		t.Errorf("RecentEvents = %+v, want the last 3 of Toast's, oldest first", recent)
+	}
+}
+
+func TestRedactEnv(t *testing.T) {
+	env := RedactEnv(map[string]string{
+		"GT_RIG":            "gastown",
+		"ANTHROPIC_API_KEY": "sk-123",
+		"GITHUB_TOKEN":      "ghp_123",
+		"DB_PASSWORD":       "hunter2",
+	})
+	if env["GT_RIG"] != "gastown" {
+		t.Errorf("GT_RIG = %q, want kept", env["GT_RIG"])
+	}
+	for _, k := range []string{"ANTHROPIC_API_KEY", "GITHUB_TOKEN", "DB_PASSWORD"} {
+		if env[k] != "[redacted]" {
+			t.Errorf("%s = %q, want redacted", k, env[k])
+		}
+	}
+}
+
+func TestAddressFromEnv(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"GT_RIG": "gastown", "GT_POLECAT": "Toast"}, "gastown/polecats/Toast"},
+		{map[string]string{"GT_RIG": "gastown", "GT_CREW": "max"}, "gastown/crew/max"},
+		{map[string]string{"GT_RIG": "gastown", "GT_ROLE": "witness"}, "gastown/witness"},
+		{map[string]string{"GT_ROLE": "mayor"}, "hook/agent"},
+	}
+	for _, tt := range tests {
+		if got := addressFromEnv(tt.env, "hook/agent"); got != tt.want {
+			t.Errorf("addressFromEnv(%v) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
@@ -11,7 +11,10 @@ import (
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/recording"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/sandbox"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
@@ -187,6 +190,12 @@ func (m *Manager) Add(name string, createBranch bool) (*CrewWorker, error) {
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
 
+	// Materialize file secrets scoped to this rig's crew
+	townRoot := filepath.Dir(m.rig.Path)
+	if _, err := secrets.Materialize(townRoot, m.rig.Name, "crew", fmt.Sprintf("%s/crew/%s", m.rig.Name, name), crewPath); err != nil {
+		fmt.Printf("Warning: could not materialize secrets: %v\n", err)
+	}
+
 	// Ensure .gitignore has required Gas Town patterns
 	if err := rig.EnsureGitignorePatterns(crewPath); err != nil {
 		// Non-fatal - log warning but continue
@@ -240,6 +249,12 @@ func (m *Manager) Remove(name string, force bool) error {
 		}
 	}
 
+	// Delete materialized secrets first so the scrub is recorded
+	townRoot := filepath.Dir(m.rig.Path)
+	if _, err := secrets.Scrub(townRoot, crewPath, fmt.Sprintf("%s/crew/%s", m.rig.Name, name)); err != nil {
+		fmt.Printf("Warning: could not scrub secrets: %v\n", err)
+	}
+
 	// Remove directory
 	if err := os.RemoveAll(crewPath); err != nil {
 		return fmt.Errorf("removing crew dir: %w", err)
@@ -511,9 +526,23 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 		claudeCmd = strings.Replace(claudeCmd, " --dangerously-skip-permissions", "", 1)
 	}
 
+	// Apply the crew role's resource limits (cgroup v2, Linux only)
+	claudeCmd, warnings := sandbox.WrapRoleChecked(townRoot, m.rig.Path, "crew", sessionID, claudeCmd)
+	for _, w := range warnings {
+		fmt.Printf("Warning: %s\n", w)
+	}
+
+	// Export env secrets scoped to this rig's crew via a self-deleting file
+	envFile, err := secrets.SessionEnvFile(townRoot, m.rig.Name, "crew", address)
+	if err != nil {
+		fmt.Printf("Warning: could not export secrets: %v\n", err)
+	}
+	claudeCmd = secrets.WrapCommand(claudeCmd, envFile)
+
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
 	if err := t.NewSessionWithCommand(sessionID, worker.ClonePath, claudeCmd); err != nil {
+		secrets.DiscardEnvFile(envFile)
 		return fmt.Errorf("creating session: %w", err)
 	}
 
@@ -535,6 +564,8 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 	theme := tmux.AssignTheme(m.rig.Name)
 	_ = t.ConfigureGasTownSession(sessionID, theme, m.rig.Name, name, "crew")
 
+	_ = recording.Start(t, m.rig.Path, sessionID)
+
 	// Set up C-b n/p keybindings for crew session cycling (non-fatal)
 	_ = t.SetCrewCycleBindings(sessionID)
 
@@ -25,10 +25,14 @@ import (
 	"github.com/steveyegge/gastown/internal/feed"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/resources"
+	"github.com/steveyegge/gastown/internal/recording"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
+	"github.com/steveyegge/gastown/internal/webhook"
 	"github.com/steveyegge/gastown/internal/wisp"
 	"github.com/steveyegge/gastown/internal/witness"
 )
@@ -48,6 +52,7 @@ type Daemon struct {
 	convoyWatcher *ConvoyWatcher
 	doltServer    *DoltServerManager
 	krcPruner     *KRCPruner
+	webhooks      *webhook.Dispatcher
 
 	// Mass death detection: track recent session deaths
 	deathsMu     sync.Mutex
@@ -58,6 +63,10 @@ type Daemon struct {
 	// See: https://github.com/steveyegge/gastown/issues/567
 	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
 	deaconLastStarted time.Time
+
+	// Last time the resource sampler measured disk usage, which is too
+	// slow to do every heartbeat. Only accessed from the heartbeat loop.
+	lastDiskSample time.Time
 }
 
 // sessionDeath records a detected session death for mass death analysis.
@@ -72,6 +81,10 @@ const (
 	massDeathThreshold = 3                // Number of deaths to trigger alert
 )
 
+// resourceDiskInterval is how often the resource sampler measures worktree
+// and repo disk usage, which walks whole directory trees.
+const resourceDiskInterval = 30 * time.Minute
+
 // New creates a new daemon instance.
 func New(config *Config) (*Daemon, error) {
 	// Ensure daemon directory exists
@@ -192,6 +205,14 @@ func (d *Daemon) Run() error {
 		}
 	}
 
+	// Start webhook dispatcher for outbound event subscriptions
+	d.webhooks = webhook.NewDispatcher(d.config.TownRoot, d.logger.Printf)
+	if err := d.webhooks.Start(); err != nil {
+		d.logger.Printf("Warning: failed to start webhook dispatcher: %v", err)
+	} else {
+		d.logger.Println("Webhook dispatcher started")
+	}
+
 	// Initial heartbeat
 	d.heartbeat(state)
 
@@ -324,6 +345,52 @@ func (d *Daemon) heartbeat(state *State) {
 	// If they have local .beads with databases, bd uses the wrong database.
 	d.cleanupTownServiceBeads()
 
+	// 14. Predict merge conflicts between in-flight polecats.
+	// Warns both polecats while they can still coordinate, instead of the
+	// Refinery discovering the conflict at merge time.
+	if IsPatrolEnabled(d.patrolConfig, "conflicts") {
+		d.checkPredictedConflicts()
+	}
+
+	// 15. Refresh and refill polecat warm pools.
+	// Keeps pre-provisioned worktrees current so gt sling can claim one
+	// instead of waiting on worktree creation and setup hooks.
+	if IsPatrolEnabled(d.patrolConfig, "warm_pool") {
+		d.refillWarmPools()
+	}
+
+	// 16. Sample per-agent CPU, memory and disk usage.
+	// History feeds gt status --resources and the dashboard; agents stuck
+	// at full CPU across two samples are reported to the feed.
+	if IsPatrolEnabled(d.patrolConfig, "resources") {
+		d.sampleResources()
+	}
+
+	// 17. Deliver scheduled mail and nudges that are due.
+	// Entries are stored in the town runtime dir, so anything that came due
+	// while the daemon was down fires on the first heartbeat after restart.
+	if IsPatrolEnabled(d.patrolConfig, "schedule") {
+		d.runSchedule()
+	}
+
+	// 18. Follow up on mail sent with --require-ack that is overdue.
+	// Re-delivers it as an interrupt, then escalates it, once each.
+	if IsPatrolEnabled(d.patrolConfig, "mail_acks") {
+		d.checkMailAcks()
+	}
+
+	// 19. Bridge mail to the overseer's email inbox, if configured.
+	// Emails new overseer and list mail; turns emailed replies into mail.
+	if IsPatrolEnabled(d.patrolConfig, "mail_bridge") {
+		d.syncMailBridge()
+	}
+
+	// 20. Mail digests of the notifications held for muted agents.
+	// Each mailbox gets one once its oldest notification is an interval old.
+	if IsPatrolEnabled(d.patrolConfig, "digest") {
+		d.sendDigests()
+	}
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -966,6 +1033,12 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("KRC pruner stopped")
 	}
 
+	// Stop webhook dispatcher
+	if d.webhooks != nil {
+		d.webhooks.Stop()
+		d.logger.Println("Webhook dispatcher stopped")
+	}
+
 	// Stop Dolt server if we're managing it
 	if d.doltServer != nil && d.doltServer.IsEnabled() && !d.doltServer.IsExternal() {
 		if err := d.doltServer.Stop(); err != nil {
@@ -1287,6 +1360,10 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	d.logger.Printf("CRASH DETECTED: polecat %s/%s has hook_bead=%s but session %s is dead",
 		rigName, polecatName, info.HookBead, sessionName)
 
+	if reason := sandbox.LimitKill(d.config.TownRoot, sessionName); reason != "" {
+		d.logger.Printf("Session %s was killed by its resource limits: %s", sessionName, reason)
+	}
+
 	// Track this death for mass death detection
 	d.recordSessionDeath(sessionName)
 
@@ -1401,6 +1478,10 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 	theme := tmux.AssignTheme(rigName)
 	_ = d.tmux.ConfigureGasTownSession(sessionName, theme, rigName, polecatName, "polecat")
 
+	if err := recording.Start(d.tmux, rigPath, sessionName); err != nil {
+		d.logger.Printf("Warning: could not record %s: %v", sessionName, err)
+	}
+
 	// Set pane-died hook for future crash detection
 	agentID := fmt.Sprintf("%s/%s", rigName, polecatName)
 	_ = d.tmux.SetPaneDiedHook(sessionName, agentID)
@@ -1442,6 +1523,61 @@ Manual intervention may be required.`,
 	}
 }
 
+// checkPredictedConflicts runs conflict prediction for each rig with polecats
+// and mails polecats whose branches overlap. gt mq conflicts --notify keeps its
+// own dedup state, so repeated heartbeats don't resend warnings.
+func (d *Daemon) checkPredictedConflicts() {
+	for _, rigName := range d.getPatrolRigs("conflicts") {
+		polecatsDir := filepath.Join(d.config.TownRoot, rigName, "polecats")
+		polecats, err := listPolecatWorktrees(polecatsDir)
+		if err != nil || len(polecats) < 1 {
+			continue // No polecats - nothing in flight
+		}
+
+		cmd := exec.Command("gt", "mq", "conflicts", rigName, "--notify", "--json") //nolint:gosec // G204: args are constructed internally
+		cmd.Dir = d.config.TownRoot
+		cmd.Env = os.Environ() // Inherit PATH to find gt executable
+		out, err := cmd.Output()
+		if err != nil {
+			d.logger.Printf("Warning: conflict prediction for %s failed: %v", rigName, err)
+			continue
+		}
+
+		var result struct {
+			Notified int `json:"notified"`
+		}
+		if err := json.Unmarshal(out, &result); err == nil && result.Notified > 0 {
+			d.logger.Printf("Conflict prediction for %s: sent %d warning(s)", rigName, result.Notified)
+		}
+	}
+}
+
+// sampleResources records a resource sample for every agent and reports
+// agents that were at full CPU in both this sample and the previous one.
+func (d *Daemon) sampleResources() {
+	townRoot := d.config.TownRoot
+	withDisk := time.Since(d.lastDiskSample) >= resourceDiskInterval
+	samples := resources.NewSampler(d.tmux).Sample(resources.Targets(townRoot, d.getPatrolRigs("resources")), withDisk)
+	if withDisk {
+		d.lastDiskSample = time.Now()
+	}
+
+	// A single hot sample may be a build or test run; two in a row is spinning
+	history, _ := resources.Load(townRoot, time.Now().Add(-resourceDiskInterval))
+	previous := resources.Latest(history, resources.TypeProcess)
+	for _, s := range samples {
+		if prev, ok := previous[s.Agent]; ok && s.Hot() && prev.Hot() {
+			d.logger.Printf("Agent %s at %.0f%% CPU for two samples", s.Agent, s.CPUPercent)
+			_ = events.LogFeed(events.TypeResourceAlert, s.Agent,
+				events.ResourceAlertPayload(s.Session, s.CPUPercent, s.RSS))
+		}
+	}
+
+	if err := resources.Record(townRoot, samples); err != nil {
+		d.logger.Printf("Warning: failed to record resource samples: %v", err)
+	}
+}
+
 // cleanupOrphanedProcesses kills orphaned claude subagent processes.
 // These are Task tool subagents that didn't clean up after completion.
 // Detection uses TTY column: processes with TTY "?" have no controlling terminal.
@@ -1464,3 +1600,206 @@ func (d *Daemon) cleanupOrphanedProcesses() {
 		}
 	}
 }
+
+// runSchedule runs gt schedule run, which delivers the scheduled mail and
+// nudges that are due, and logs what it fired.
+func (d *Daemon) runSchedule() {
+	cmd := exec.Command("gt", "schedule", "run", "--json") //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ() // Inherit PATH to find gt executable
+	out, err := cmd.Output()
+	if err != nil {
+		d.logger.Printf("Warning: schedule run failed: %v", err)
+		return
+	}
+
+	// Delivery may write to stdout ahead of the JSON object
+	if i := strings.LastIndex(string(out), "\n{"); i >= 0 {
+		out = out[i+1:]
+	}
+	var result struct {
+		Fired []struct {
+			ID          string `json:"id"`
+			Kind        string `json:"kind"`
+			Target      string `json:"target"`
+			Error       string `json:"error"`
+			Interrupted bool   `json:"interrupted"`
+			Dropped     bool   `json:"dropped"`
+		} `json:"fired"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return
+	}
+	for _, f := range result.Fired {
+		switch {
+		case f.Interrupted:
+			d.logger.Printf("Schedule: %s %s to %s was interrupted by an earlier run; not repeated", f.ID, f.Kind, f.Target)
+		case f.Dropped:
+			d.logger.Printf("Warning: schedule: %s %s to %s dropped after repeated failures: %s", f.ID, f.Kind, f.Target, f.Error)
+		case f.Error != "":
+			d.logger.Printf("Warning: schedule: %s %s to %s failed, will retry: %s", f.ID, f.Kind, f.Target, f.Error)
+		default:
+			d.logger.Printf("Schedule: delivered %s %s to %s", f.ID, f.Kind, f.Target)
+		}
+	}
+}
+
+// checkMailAcks runs gt mail check-acks, which re-delivers or escalates
+// unacknowledged mail, and logs the follow-ups it took.
+func (d *Daemon) checkMailAcks() {
+	cmd := exec.Command("gt", "mail", "check-acks", "--json") //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ() // Inherit PATH to find gt executable
+	out, err := cmd.Output()
+	if err != nil {
+		d.logger.Printf("Warning: mail ack check failed: %v", err)
+		return
+	}
+
+	if i := strings.LastIndex(string(out), "\n{"); i >= 0 {
+		out = out[i+1:]
+	}
+	var result struct {
+		Actions []struct {
+			ID         string `json:"id"`
+			To         string `json:"to"`
+			Status     string `json:"status"`
+			Escalation string `json:"escalation"`
+			Error      string `json:"error"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return
+	}
+	for _, a := range result.Actions {
+		if a.Error != "" {
+			d.logger.Printf("Warning: unacknowledged mail %s to %s %s: %s", a.ID, a.To, a.Status, a.Error)
+			continue
+		}
+		if a.Escalation != "" {
+			d.logger.Printf("Unacknowledged mail %s to %s %s as %s", a.ID, a.To, a.Status, a.Escalation)
+			continue
+		}
+		d.logger.Printf("Unacknowledged mail %s to %s %s", a.ID, a.To, a.Status)
+	}
+}
+
+// syncMailBridge runs gt mail bridge sync when the town settings configure
+// an email bridge.
+func (d *Daemon) syncMailBridge() {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(d.config.TownRoot))
+	if err != nil || settings.Email == nil || settings.Email.Disabled {
+		return
+	}
+	cmd := exec.Command("gt", "mail", "bridge", "sync", "--json") //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ() // Inherit PATH to find gt executable
+	out, err := cmd.Output()
+	if err != nil {
+		d.logger.Printf("Warning: mail bridge sync failed: %v", err)
+		return
+	}
+
+	if i := strings.LastIndex(string(out), "\n{"); i >= 0 {
+		out = out[i+1:]
+	}
+	var result struct {
+		Forwarded []struct {
+			ID    string `json:"id"`
+			To    string `json:"to"`
+			Error string `json:"error"`
+		} `json:"forwarded"`
+		Replies []struct {
+			File    string `json:"file"`
+			To      string `json:"to"`
+			ReplyTo string `json:"reply_to"`
+			Skipped string `json:"skipped"`
+			Error   string `json:"error"`
+		} `json:"replies"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return
+	}
+	for _, f := range result.Forwarded {
+		if f.Error != "" {
+			d.logger.Printf("Warning: emailing mail %s to %s failed: %s", f.ID, f.To, f.Error)
+			continue
+		}
+		d.logger.Printf("Mail bridge: emailed %s to %s", f.ID, f.To)
+	}
+	for _, r := range result.Replies {
+		switch {
+		case r.Error != "":
+			d.logger.Printf("Warning: email reply %s failed: %s", r.File, r.Error)
+		case r.Skipped != "":
+			d.logger.Printf("Mail bridge: skipped email %s: %s", r.File, r.Skipped)
+		default:
+			d.logger.Printf("Mail bridge: reply to %s sent to %s", r.ReplyTo, r.To)
+		}
+	}
+}
+
+// sendDigests runs gt mail digest run, which mails muted agents the
+// digests that are due, and logs them.
+func (d *Daemon) sendDigests() {
+	cmd := exec.Command("gt", "mail", "digest", "run", "--json") //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ() // Inherit PATH to find gt executable
+	out, err := cmd.Output()
+	if err != nil {
+		d.logger.Printf("Warning: mail digest run failed: %v", err)
+		return
+	}
+
+	if i := strings.LastIndex(string(out), "\n{"); i >= 0 {
+		out = out[i+1:]
+	}
+	var result struct {
+		Digests []struct {
+			Address string        `json:"address"`
+			Items   []interface{} `json:"items"`
+			Error   string        `json:"error"`
+		} `json:"digests"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return
+	}
+	for _, dg := range result.Digests {
+		if dg.Error != "" {
+			d.logger.Printf("Warning: digest for %s failed: %s", dg.Address, dg.Error)
+			continue
+		}
+		d.logger.Printf("Digest: mailed %d held notification(s) to %s", len(dg.Items), dg.Address)
+	}
+}
+
+// refillWarmPools runs gt polecat pool fill for each operational rig.
+// Rigs without polecat_warm_pool configured return immediately.
+func (d *Daemon) refillWarmPools() {
+	for _, rigName := range d.getPatrolRigs("warm_pool") {
+		if ok, _ := d.isRigOperational(rigName); !ok {
+			continue // Don't provision worktrees for parked/docked rigs
+		}
+
+		cmd := exec.Command("gt", "polecat", "pool", "fill", rigName, "--json") //nolint:gosec // G204: args are constructed internally
+		cmd.Dir = d.config.TownRoot
+		cmd.Env = os.Environ() // Inherit PATH to find gt executable
+		out, err := cmd.Output()
+		if err != nil {
+			d.logger.Printf("Warning: warm pool refill for %s failed: %v", rigName, err)
+			continue
+		}
+
+		// Setup hooks may write to stdout ahead of the JSON object
+		if i := strings.LastIndex(string(out), "\n{"); i >= 0 {
+			out = out[i+1:]
+		}
+		var result struct {
+			Refreshed int `json:"refreshed"`
+			Added     int `json:"added"`
+		}
+		if err := json.Unmarshal(out, &result); err == nil && (result.Refreshed > 0 || result.Added > 0) {
+			d.logger.Printf("Warm pool for %s: refreshed %d, added %d", rigName, result.Refreshed, result.Added)
+		}
+	}
+}
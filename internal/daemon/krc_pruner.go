@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/krc"
+	"github.com/steveyegge/gastown/internal/recording"
 )
 
 // KRCPruner manages automatic pruning of expired ephemeral records.
@@ -74,6 +75,13 @@ func (p *KRCPruner) run() {
 
 // prune runs a single prune operation.
 func (p *KRCPruner) prune() {
+	// Session recordings expire by each rig's own TTL
+	if n, err := recording.PruneTown(p.townRoot); err != nil {
+		p.logger("Recording prune error: %v", err)
+	} else if n > 0 {
+		p.logger("Pruned %d expired session recording(s)", n)
+	}
+
 	pruner := krc.NewPruner(p.townRoot, p.config)
 	result, err := pruner.Prune()
 	if err != nil {
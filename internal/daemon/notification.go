@@ -17,6 +17,7 @@ type NotificationSlot struct {
 	SentAt    time.Time `json:"sent_at"`
 	Consumed  bool      `json:"consumed"`
 	ConsumedAt time.Time `json:"consumed_at,omitempty"`
+	Digested  []string  `json:"digested,omitempty"` // IDs of the notifications a digest covered
 }
 
 // NotificationManager handles slot-based notification deduplication.
@@ -118,6 +119,29 @@ func (m *NotificationManager) RecordSend(session, slot, message string) error {
 	return os.WriteFile(m.slotPath(session, slot), data, 0600)
 }
 
+// RecordDigest records that a digest covering the notifications ids was
+// sent for a slot.
+func (m *NotificationManager) RecordDigest(session, slot, message string, ids []string) error {
+	if err := os.MkdirAll(m.stateDir, 0755); err != nil {
+		return err
+	}
+
+	ns := &NotificationSlot{
+		Slot:     slot,
+		Session:  session,
+		Message:  message,
+		SentAt:   time.Now(),
+		Digested: ids,
+	}
+
+	data, err := json.Marshal(ns)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(m.slotPath(session, slot), data, 0600)
+}
+
 // MarkConsumed marks a slot's notification as consumed (agent responded).
 func (m *NotificationManager) MarkConsumed(session, slot string) error {
 	ns, err := m.GetSlot(session, slot)
@@ -209,4 +233,5 @@ func (m *NotificationManager) ClearStaleSlots() error {
 const (
 	SlotHeartbeat = "heartbeat"
 	SlotStatus    = "status"
+	SlotDigest    = "digest"
 )
@@ -0,0 +1,32 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNotificationManager_RecordDigest(t *testing.T) {
+	nm := NewNotificationManager(t.TempDir(), time.Hour)
+
+	if ok, err := nm.ShouldSend("gastown/crew/jack", SlotDigest); err != nil || !ok {
+		t.Fatalf("ShouldSend before any digest = %v, %v; want true", ok, err)
+	}
+	if err := nm.RecordDigest("gastown/crew/jack", SlotDigest, "📋 Digest: 2 notification(s)", []string{"hq-1", "hq-2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ns, err := nm.GetSlot("gastown/crew/jack", SlotDigest)
+	if err != nil || ns == nil {
+		t.Fatalf("GetSlot = %v, %v", ns, err)
+	}
+	if !reflect.DeepEqual(ns.Digested, []string{"hq-1", "hq-2"}) {
+		t.Errorf("Digested = %v", ns.Digested)
+	}
+	if ok, _ := nm.ShouldSend("gastown/crew/jack", SlotDigest); ok {
+		t.Error("ShouldSend within maxAge of a digest = true, want false")
+	}
+	if ok, _ := nm.ShouldSend("gastown/crew/max", SlotDigest); !ok {
+		t.Error("ShouldSend for another mailbox = false, want true")
+	}
+}
@@ -20,7 +20,14 @@ func TestLoadPatrolConfig(t *testing.T) {
 		"version": 1,
 		"patrols": {
 			"refinery": {"enabled": false},
-			"witness": {"enabled": true}
+			"witness": {"enabled": true},
+			"conflicts": {"enabled": false},
+			"warm_pool": {"enabled": false, "rigs": ["gastown"]},
+			"resources": {"enabled": false},
+			"schedule": {"enabled": false},
+			"mail_acks": {"enabled": false},
+			"mail_bridge": {"enabled": false},
+			"digest": {"enabled": false}
 		}
 	}`
 	if err := os.WriteFile(filepath.Join(mayorDir, "daemon.json"), []byte(configJSON), 0644); err != nil {
@@ -43,6 +50,30 @@ func TestLoadPatrolConfig(t *testing.T) {
 	if !IsPatrolEnabled(config, "deacon") {
 		t.Error("expected deacon to be enabled (default)")
 	}
+	if IsPatrolEnabled(config, "conflicts") {
+		t.Error("expected conflicts to be disabled")
+	}
+	if IsPatrolEnabled(config, "warm_pool") {
+		t.Error("expected warm_pool to be disabled")
+	}
+	if rigs := GetPatrolRigs(config, "warm_pool"); len(rigs) != 1 || rigs[0] != "gastown" {
+		t.Errorf("GetPatrolRigs(warm_pool) = %v, want [gastown]", rigs)
+	}
+	if IsPatrolEnabled(config, "resources") {
+		t.Error("expected resources to be disabled")
+	}
+	if IsPatrolEnabled(config, "schedule") {
+		t.Error("expected schedule to be disabled")
+	}
+	if IsPatrolEnabled(config, "mail_acks") {
+		t.Error("expected mail_acks to be disabled")
+	}
+	if IsPatrolEnabled(config, "mail_bridge") {
+		t.Error("expected mail_bridge to be disabled")
+	}
+	if IsPatrolEnabled(config, "digest") {
+		t.Error("expected digest to be disabled")
+	}
 }
 
 func TestIsPatrolEnabled_NilConfig(t *testing.T) {
@@ -116,6 +116,13 @@ type PatrolsConfig struct {
 	Refinery   *PatrolConfig     `json:"refinery,omitempty"`
 	Witness    *PatrolConfig     `json:"witness,omitempty"`
 	Deacon     *PatrolConfig     `json:"deacon,omitempty"`
+	Conflicts  *PatrolConfig     `json:"conflicts,omitempty"`
+	WarmPool   *PatrolConfig     `json:"warm_pool,omitempty"`
+	Resources  *PatrolConfig     `json:"resources,omitempty"`
+	Schedule   *PatrolConfig     `json:"schedule,omitempty"`
+	MailAcks   *PatrolConfig     `json:"mail_acks,omitempty"`
+	MailBridge *PatrolConfig     `json:"mail_bridge,omitempty"`
+	Digest     *PatrolConfig     `json:"digest,omitempty"`
 	DoltServer *DoltServerConfig `json:"dolt_server,omitempty"`
 }
 
@@ -168,6 +175,34 @@ func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
 		if config.Patrols.Deacon != nil {
 			return config.Patrols.Deacon.Enabled
 		}
+	case "conflicts":
+		if config.Patrols.Conflicts != nil {
+			return config.Patrols.Conflicts.Enabled
+		}
+	case "warm_pool":
+		if config.Patrols.WarmPool != nil {
+			return config.Patrols.WarmPool.Enabled
+		}
+	case "resources":
+		if config.Patrols.Resources != nil {
+			return config.Patrols.Resources.Enabled
+		}
+	case "schedule":
+		if config.Patrols.Schedule != nil {
+			return config.Patrols.Schedule.Enabled
+		}
+	case "mail_acks":
+		if config.Patrols.MailAcks != nil {
+			return config.Patrols.MailAcks.Enabled
+		}
+	case "mail_bridge":
+		if config.Patrols.MailBridge != nil {
+			return config.Patrols.MailBridge.Enabled
+		}
+	case "digest":
+		if config.Patrols.Digest != nil {
+			return config.Patrols.Digest.Enabled
+		}
 	}
 	return true // Default: enabled
 }
@@ -187,6 +222,18 @@ func GetPatrolRigs(config *DaemonPatrolConfig, patrol string) []string {
 		if config.Patrols.Witness != nil {
 			return config.Patrols.Witness.Rigs
 		}
+	case "conflicts":
+		if config.Patrols.Conflicts != nil {
+			return config.Patrols.Conflicts.Rigs
+		}
+	case "warm_pool":
+		if config.Patrols.WarmPool != nil {
+			return config.Patrols.WarmPool.Rigs
+		}
+	case "resources":
+		if config.Patrols.Resources != nil {
+			return config.Patrols.Resources.Rigs
+		}
 	}
 	return nil // All rigs
 }
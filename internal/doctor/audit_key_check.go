@@ -0,0 +1,51 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// AuditKeyCheck warns when the audit signing key lives inside the town,
+// where agents can read it and re-sign an edited audit chain.
+type AuditKeyCheck struct {
+	BaseCheck
+}
+
+// NewAuditKeyCheck creates a new audit key check.
+func NewAuditKeyCheck() *AuditKeyCheck {
+	return &AuditKeyCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "audit-key",
+			CheckDescription: "Check that the audit signing key is kept outside the town",
+			CheckCategory:    CategoryConfig,
+		},
+	}
+}
+
+// Run checks where the audit key lives.
+func (c *AuditKeyCheck) Run(ctx *CheckContext) *CheckResult {
+	path := events.AuditKeyPath(ctx.TownRoot)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No audit key yet",
+		}
+	}
+	if !events.AuditKeyInTown(ctx.TownRoot) {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "Audit key is outside the town",
+		}
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusWarning,
+		Message: "Audit key is inside the town, so agents could re-sign an edited audit chain",
+		Details: []string{path},
+		FixHint: fmt.Sprintf("Move the key outside the town and set %s to its path", events.AuditKeyFileEnv),
+	}
+}
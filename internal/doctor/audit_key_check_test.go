@@ -0,0 +1,36 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func TestAuditKeyCheck(t *testing.T) {
+	townRoot := t.TempDir()
+	ctx := &CheckContext{TownRoot: townRoot}
+	check := NewAuditKeyCheck()
+	t.Setenv(events.AuditKeyFileEnv, "")
+
+	if result := check.Run(ctx); result.Status != StatusOK {
+		t.Errorf("no key: status %v, want OK", result.Status)
+	}
+
+	if _, err := events.AuditKey(townRoot, true); err != nil {
+		t.Fatal(err)
+	}
+	if result := check.Run(ctx); result.Status != StatusWarning {
+		t.Errorf("key in town: status %v, want warning", result.Status)
+	}
+
+	outside := filepath.Join(t.TempDir(), "audit.key")
+	if err := os.Rename(events.AuditKeyPath(townRoot), outside); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(events.AuditKeyFileEnv, outside)
+	if result := check.Run(ctx); result.Status != StatusOK {
+		t.Errorf("key outside town: status %v (%s), want OK", result.Status, result.Message)
+	}
+}
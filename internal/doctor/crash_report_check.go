@@ -8,13 +8,17 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/crash"
 )
 
-// CrashReportCheck looks for recent macOS crash reports related to tmux or Claude.
-// This helps diagnose mass session death events.
+// CrashReportCheck looks for recent agent crash bundles and, on macOS, crash
+// reports related to tmux or Claude. This helps diagnose mass session death
+// events.
 type CrashReportCheck struct {
 	BaseCheck
-	crashReports []crashReport // Cached during Run for display
+	crashReports []crashReport   // Cached during Run for display
+	bundles      []*crash.Bundle // Agent crash bundles, cached during Run
 }
 
 // crashReport represents a found crash report file.
@@ -30,27 +34,33 @@ func NewCrashReportCheck() *CrashReportCheck {
 	return &CrashReportCheck{
 		BaseCheck: BaseCheck{
 			CheckName:        "crash-reports",
-			CheckDescription: "Check for recent macOS crash reports (tmux, Claude)",
+			CheckDescription: "Check for recent agent crashes and macOS crash reports (tmux, Claude)",
 			CheckCategory:    CategoryCleanup,
 		},
 	}
 }
 
-// Run checks for recent crash reports in macOS diagnostic directories.
+// Run checks for recent agent crash bundles and for crash reports in macOS
+// diagnostic directories.
 func (c *CrashReportCheck) Run(ctx *CheckContext) *CheckResult {
-	// Only run on macOS
+	// Look for crashes in the last 24 hours
+	lookbackWindow := 24 * time.Hour
+	cutoff := time.Now().Add(-lookbackWindow)
+
+	c.bundles = recentCrashBundles(ctx.TownRoot, cutoff)
+
+	// OS crash reports are only collected on macOS
 	if runtime.GOOS != "darwin" {
+		if len(c.bundles) > 0 {
+			return c.bundleResult()
+		}
 		return &CheckResult{
 			Name:    c.Name(),
 			Status:  StatusOK,
-			Message: "Crash report check not applicable (non-macOS)",
+			Message: "No recent agent crashes",
 		}
 	}
 
-	// Look for crash reports in the last 24 hours
-	lookbackWindow := 24 * time.Hour
-	cutoff := time.Now().Add(-lookbackWindow)
-
 	// macOS crash report locations
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -132,6 +142,9 @@ func (c *CrashReportCheck) Run(ctx *CheckContext) *CheckResult {
 	c.crashReports = reports
 
 	if len(reports) == 0 {
+		if len(c.bundles) > 0 {
+			return c.bundleResult()
+		}
 		return &CheckResult{
 			Name:    c.Name(),
 			Status:  StatusOK,
@@ -166,6 +179,11 @@ func (c *CrashReportCheck) Run(ctx *CheckContext) *CheckResult {
 		message += " - TMUX CRASHED (may explain session deaths)"
 	}
 
+	if len(c.bundles) > 0 {
+		message += fmt.Sprintf("; %d agent crash(es)", len(c.bundles))
+		details = append(details, bundleDetails(c.bundles)...)
+	}
+
 	return &CheckResult{
 		Name:    c.Name(),
 		Status:  status,
@@ -175,6 +193,45 @@ func (c *CrashReportCheck) Run(ctx *CheckContext) *CheckResult {
 	}
 }
 
+// recentCrashBundles returns the town's crash bundles captured since cutoff.
+func recentCrashBundles(townRoot string, cutoff time.Time) []*crash.Bundle {
+	if townRoot == "" {
+		return nil
+	}
+	all, err := crash.List(townRoot)
+	if err != nil {
+		return nil
+	}
+	var recent []*crash.Bundle
+	for _, b := range all {
+		if b.Time.After(cutoff) {
+			recent = append(recent, b)
+		}
+	}
+	return recent
+}
+
+// bundleResult reports recent agent crashes, pointing at their bundles.
+func (c *CrashReportCheck) bundleResult() *CheckResult {
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusWarning,
+		Message: fmt.Sprintf("%d agent crash(es) in the last 24h", len(c.bundles)),
+		Details: bundleDetails(c.bundles),
+		FixHint: fmt.Sprintf("Inspect the evidence: gt crash show %s", c.bundles[0].ID),
+	}
+}
+
+// bundleDetails describes each crash bundle with the command to open it.
+func bundleDetails(bundles []*crash.Bundle) []string {
+	var details []string
+	for _, b := range bundles {
+		age := time.Since(b.Time).Round(time.Minute)
+		details = append(details, fmt.Sprintf("%s (%s ago): %s → gt crash show %s", b.Address, age, b.Reason, b.ID))
+	}
+	return details
+}
+
 // Fix does nothing - crash reports are informational.
 func (c *CrashReportCheck) Fix(ctx *CheckContext) error {
 	return nil
@@ -124,8 +124,8 @@ func (c *ZombieSessionCheck) Fix(ctx *CheckContext) error {
 			continue
 		}
 
-		// Log pre-death event for audit trail
-		_ = events.LogFeed(events.TypeSessionDeath, sess,
+		// Log pre-death event for audit trail, in the town being checked
+		_ = events.LogFeedTo(ctx.TownRoot, events.TypeSessionDeath, sess,
 			events.SessionDeathPayload(sess, "unknown", "zombie cleanup", "gt doctor"))
 
 		// Use KillSessionWithProcesses to ensure all descendant processes are killed.
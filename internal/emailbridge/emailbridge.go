@@ -0,0 +1,464 @@
+// Package emailbridge lets humans read and answer agent mail from an email
+// inbox.
+//
+// Sync emails new mail addressed to the overseer, and mail sent to the
+// mailing lists under "email.lists" in the town settings, through an SMTP
+// relay. An email's Message-ID names the mail bead and its thread, and its
+// In-Reply-To and References point at the message it answers and the
+// thread, so mail clients group a conversation the way gt mail thread does.
+//
+// Replies are read from a maildir that a local tool such as mbsync,
+// fetchmail or getmail fills from the human's IMAP account. A reply whose
+// In-Reply-To or References names a forwarded bead is sent back to that
+// bead's sender as a reply from the overseer, in the same thread. Replies
+// are only accepted from the configured addresses, and only when the
+// Message-ID they answer carries a valid token: the From header alone is
+// easily forged, the town's reply key is not.
+package emailbridge
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// Overseer is the mail address the bridge forwards for and replies as.
+const Overseer = "overseer"
+
+// MaxAge bounds how far back Sync looks: older mail is never forwarded,
+// and the record of what was forwarded or ingested is dropped after it.
+const MaxAge = 7 * 24 * time.Hour
+
+// Load returns the email bridge configuration from the town settings, or
+// nil when none is set. To defaults to the overseer's email.
+func Load(townRoot string) (*config.EmailConfig, error) {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	if settings.Email == nil {
+		return nil, nil
+	}
+	cfg := *settings.Email
+	if len(cfg.To) == 0 {
+		if overseer, err := config.LoadOverseerConfig(config.OverseerConfigPath(townRoot)); err == nil && overseer.Email != "" {
+			cfg.To = []string{overseer.Email}
+		}
+	}
+	if err := Validate(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks the sender, the relay address, and that there is at
+// least one well-formed recipient.
+func Validate(cfg *config.EmailConfig) error {
+	if _, err := netmail.ParseAddress(cfg.From); err != nil {
+		return fmt.Errorf("email: invalid from %q: %w", cfg.From, err)
+	}
+	if _, _, err := net.SplitHostPort(cfg.SMTP); err != nil {
+		return fmt.Errorf("email: smtp must be host:port, got %q", cfg.SMTP)
+	}
+	if len(cfg.To) == 0 && len(cfg.Lists) == 0 {
+		return errors.New("email: no recipients: set email.to or email.lists, or an email in mayor/overseer.json")
+	}
+	for _, addr := range cfg.To {
+		if _, err := netmail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("email: invalid to %q: %w", addr, err)
+		}
+	}
+	for list, addrs := range cfg.Lists {
+		if len(addrs) == 0 {
+			return fmt.Errorf("email: list %q has no addresses", list)
+		}
+		for _, addr := range addrs {
+			if _, err := netmail.ParseAddress(addr); err != nil {
+				return fmt.Errorf("email: list %q: invalid address %q: %w", list, addr, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Password resolves the SMTP password, reading "$NAME" from the
+// environment.
+func Password(cfg *config.EmailConfig) string {
+	if strings.HasPrefix(cfg.Password, "$") {
+		return os.Getenv(strings.TrimPrefix(cfg.Password, "$"))
+	}
+	return cfg.Password
+}
+
+// Domain returns the domain Message-IDs are minted in: that of From.
+func Domain(cfg *config.EmailConfig) string {
+	if addr, err := netmail.ParseAddress(cfg.From); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			return strings.ToLower(addr.Address[i+1:])
+		}
+	}
+	return "gastown.local"
+}
+
+// MaildirPath returns the reply maildir, expanding "~/" and resolving
+// relative paths against the town root.
+func MaildirPath(townRoot string, cfg *config.EmailConfig) string {
+	dir := cfg.Maildir
+	if strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[2:])
+		}
+	}
+	if dir == "" || filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(townRoot, dir)
+}
+
+// Mailer is the beads mail the bridge forwards from and replies into.
+type Mailer interface {
+	// Unread returns the overseer's unread mail.
+	Unread() ([]*mail.Message, error)
+
+	// ListCopies returns the members' copies of mail sent to a list.
+	ListCopies(list string) ([]*mail.Message, error)
+
+	// Get returns a message by ID.
+	Get(id string) (*mail.Message, error)
+
+	// Send sends a message.
+	Send(msg *mail.Message) error
+
+	// MarkRead marks one of the overseer's messages read.
+	MarkRead(id string) error
+}
+
+// beadsMailer is the Mailer backed by town beads.
+type beadsMailer struct {
+	router *mail.Router
+	inbox  *mail.Mailbox
+}
+
+// NewBeadsMailer returns the Mailer for a town's beads mail.
+func NewBeadsMailer(townRoot string) Mailer {
+	return &beadsMailer{
+		router: mail.NewRouter(townRoot),
+		inbox:  mail.NewMailboxFromAddress(Overseer, townRoot),
+	}
+}
+
+func (m *beadsMailer) Unread() ([]*mail.Message, error) {
+	return m.inbox.ListUnread()
+}
+
+func (m *beadsMailer) ListCopies(list string) ([]*mail.Message, error) {
+	return m.router.ListCopies(list)
+}
+
+func (m *beadsMailer) Get(id string) (*mail.Message, error) {
+	return m.inbox.Get(id)
+}
+
+func (m *beadsMailer) Send(msg *mail.Message) error {
+	return m.router.Send(msg)
+}
+
+func (m *beadsMailer) MarkRead(id string) error {
+	return m.inbox.MarkReadOnly(id)
+}
+
+// SendFunc sends one email; smtp.SendMail has this signature.
+type SendFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// Bridge moves mail between town beads and email.
+type Bridge struct {
+	townRoot string
+	cfg      *config.EmailConfig
+	mail     Mailer
+	send     SendFunc
+	key      []byte // reply key, loaded by Sync
+}
+
+// New returns a bridge for a town.
+func New(townRoot string, cfg *config.EmailConfig, m Mailer, send SendFunc) *Bridge {
+	return &Bridge{townRoot: townRoot, cfg: cfg, mail: m, send: send}
+}
+
+// Forwarded is mail Sync emailed, or failed to. Failed mail is tried again
+// on the next sync.
+type Forwarded struct {
+	ID        string   `json:"id"`
+	From      string   `json:"from"`
+	To        string   `json:"to"` // mail address, or "list:<name>"
+	Subject   string   `json:"subject"`
+	Emails    []string `json:"emails"`
+	MessageID string   `json:"message_id"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// Reply is an email Sync read from the maildir. Skipped emails are moved
+// out of new/ like ingested ones; emails that failed stay for the next
+// sync.
+type Reply struct {
+	File    string `json:"file"`
+	From    string `json:"from,omitempty"`     // email sender
+	Subject string `json:"subject,omitempty"`  // email subject
+	ReplyTo string `json:"reply_to,omitempty"` // mail ID answered
+	To      string `json:"to,omitempty"`       // mail address the reply went to
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Result is what one Sync did.
+type Result struct {
+	Forwarded []Forwarded `json:"forwarded"`
+	Replies   []Reply     `json:"replies"`
+}
+
+// Sync forwards new mail and ingests replies. The first sync in a town
+// only records the time: mail sent before it is not forwarded.
+func (b *Bridge) Sync(now time.Time) (*Result, error) {
+	res := &Result{Forwarded: []Forwarded{}, Replies: []Reply{}}
+	err := withLock(b.townRoot, func() error {
+		st, err := LoadState(b.townRoot)
+		if err != nil {
+			return err
+		}
+		if st.Since.IsZero() {
+			st.Since = now
+		}
+		if b.key, err = ReplyKey(b.townRoot); err != nil {
+			return err
+		}
+		st.prune(now)
+		if err := b.forward(st, now, res); err != nil {
+			return err
+		}
+		if b.cfg.Maildir != "" {
+			if err := b.ingest(st, now, res); err != nil {
+				return err
+			}
+		}
+		return saveState(b.townRoot, st)
+	})
+	return res, err
+}
+
+// outgoing is mail due to be emailed.
+type outgoing struct {
+	msg    *mail.Message
+	to     string
+	emails []string
+	key    string // State.Forwarded key
+}
+
+// forward emails the overseer's new unread mail and new list sends,
+// oldest first so threads arrive in order.
+func (b *Bridge) forward(st *State, now time.Time, res *Result) error {
+	since := st.Since
+	if cutoff := now.Add(-MaxAge); since.Before(cutoff) {
+		since = cutoff
+	}
+	due := func(msg *mail.Message, key string) bool {
+		_, done := st.Forwarded[key]
+		return !done && !msg.Timestamp.Before(since)
+	}
+
+	var out []outgoing
+	if len(b.cfg.To) > 0 {
+		unread, err := b.mail.Unread()
+		if err != nil {
+			return fmt.Errorf("listing overseer mail: %w", err)
+		}
+		for _, msg := range unread {
+			if len(b.cfg.Lists[msg.List]) > 0 {
+				continue // emailed once for the whole list
+			}
+			if due(msg, msg.ID) {
+				out = append(out, outgoing{msg: msg, to: msg.To, emails: b.cfg.To, key: msg.ID})
+			}
+		}
+	}
+
+	lists := make([]string, 0, len(b.cfg.Lists))
+	for name := range b.cfg.Lists {
+		lists = append(lists, name)
+	}
+	sort.Strings(lists)
+	for _, name := range lists {
+		copies, err := b.mail.ListCopies(name)
+		if err != nil {
+			return fmt.Errorf("listing mail to list %s: %w", name, err)
+		}
+		sort.Slice(copies, func(i, j int) bool { return copies[i].Timestamp.Before(copies[j].Timestamp) })
+		queued := make(map[string]bool)
+		for _, msg := range copies {
+			key := "list-send:" + msg.ListSend
+			if msg.ListSend == "" {
+				key = msg.ID
+			}
+			if queued[key] || !due(msg, key) {
+				continue
+			}
+			queued[key] = true
+			out = append(out, outgoing{msg: msg, to: "list:" + name, emails: b.cfg.Lists[name], key: key})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].msg.Timestamp.Before(out[j].msg.Timestamp) })
+
+	var auth smtp.Auth
+	if b.cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(b.cfg.SMTP)
+		auth = smtp.PlainAuth("", b.cfg.Username, Password(b.cfg), host)
+	}
+	sender, _ := netmail.ParseAddress(b.cfg.From)
+	domain := Domain(b.cfg)
+	for _, o := range out {
+		f := Forwarded{
+			ID:        o.msg.ID,
+			From:      o.msg.From,
+			To:        o.to,
+			Subject:   o.msg.Subject,
+			Emails:    o.emails,
+			MessageID: MessageID(b.key, o.msg.ID, o.msg.ThreadID, domain),
+		}
+		raw := Compose(b.cfg, b.key, o.msg, o.to, o.emails)
+		if err := b.send(b.cfg.SMTP, auth, sender.Address, o.emails, raw); err != nil {
+			f.Error = err.Error()
+		} else {
+			st.Forwarded[o.key] = now
+			// Saved per email so a failure later in the run cannot resend it
+			if err := saveState(b.townRoot, st); err != nil {
+				return err
+			}
+		}
+		res.Forwarded = append(res.Forwarded, f)
+	}
+	return nil
+}
+
+// ingest turns replies in the maildir's new/ into mail, oldest first.
+func (b *Bridge) ingest(st *State, now time.Time, res *Result) error {
+	dir := MaildirPath(b.townRoot, b.cfg)
+	names, err := newMail(dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		r := Reply{File: name}
+		if err := b.ingestOne(filepath.Join(dir, "new", name), st, now, &r); err != nil {
+			r.Error = err.Error()
+			res.Replies = append(res.Replies, r)
+			continue
+		}
+		if err := markSeen(dir, name); err != nil {
+			return err
+		}
+		res.Replies = append(res.Replies, r)
+	}
+	return nil
+}
+
+// ingestOne sends one email as a reply, or sets r.Skipped when it is not
+// a reply the bridge accepts. It returns an error only when the email
+// should be tried again.
+func (b *Bridge) ingestOne(path string, st *State, now time.Time, r *Reply) error {
+	f, err := os.Open(path) //nolint:gosec // G304: path is in the configured maildir
+	if err != nil {
+		return err
+	}
+	e, err := ParseEmail(f)
+	_ = f.Close()
+	if err != nil {
+		r.Skipped = "unreadable: " + err.Error()
+		return nil
+	}
+	r.From, r.Subject = e.From, e.Subject
+
+	switch {
+	case !b.accepts(e.From):
+		r.Skipped = "sender is not a bridge recipient"
+		return nil
+	case e.MessageID != "" && !st.Ingested[e.MessageID].IsZero():
+		r.Skipped = "already ingested"
+		return nil
+	}
+	var id string
+	unsigned := false
+	domain := Domain(b.cfg)
+	for _, ref := range e.Refs {
+		if beadID, _, ok := ParseMessageID(b.key, ref, domain); ok {
+			id = beadID
+			break
+		}
+		unsigned = unsigned || strings.HasPrefix(strings.TrimPrefix(ref, "<"), "mail.")
+	}
+	switch {
+	case id == "" && unsigned:
+		r.Skipped = "reply token does not verify"
+		return nil
+	case id == "":
+		r.Skipped = "not a reply to forwarded mail"
+		return nil
+	}
+	if e.Body == "" {
+		r.Skipped = "empty reply"
+		return nil
+	}
+	original, err := b.mail.Get(id)
+	if errors.Is(err, mail.ErrMessageNotFound) {
+		r.Skipped = fmt.Sprintf("mail %s not found", id)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting mail %s: %w", id, err)
+	}
+
+	reply := mail.NewReplyMessage(Overseer, original.From, replySubject(original.Subject), e.Body, original)
+	if err := b.mail.Send(reply); err != nil {
+		return fmt.Errorf("sending reply: %w", err)
+	}
+	r.ReplyTo, r.To = original.ID, original.From
+	if e.MessageID != "" {
+		st.Ingested[e.MessageID] = now
+		if err := saveState(b.townRoot, st); err != nil {
+			return err
+		}
+	}
+	if mail.AddressToIdentity(original.To) == Overseer {
+		_ = b.mail.MarkRead(original.ID) // best-effort: the reply is sent
+	}
+	return nil
+}
+
+// accepts reports whether from is one of the configured recipients.
+func (b *Bridge) accepts(from string) bool {
+	all := append([]string(nil), b.cfg.To...)
+	for _, addrs := range b.cfg.Lists {
+		all = append(all, addrs...)
+	}
+	for _, a := range all {
+		if addr, err := netmail.ParseAddress(a); err == nil && strings.EqualFold(addr.Address, from) {
+			return true
+		}
+	}
+	return false
+}
+
+// replySubject mirrors gt mail reply.
+func replySubject(subject string) string {
+	if strings.HasPrefix(subject, "Re: ") {
+		return subject
+	}
+	return "Re: " + subject
+}
@@ -0,0 +1,121 @@
+package emailbridge
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// MessageID returns the Message-ID of the email forwarding a mail bead:
+// <mail.BEAD+THREAD=TOKEN@domain>, or <mail.BEAD=TOKEN@domain> outside a
+// thread. TOKEN signs the bead and thread with the town's reply key, so a
+// reply can only answer mail the bridge actually sent.
+func MessageID(key []byte, beadID, threadID, domain string) string {
+	token := replyToken(key, beadID, threadID)
+	if threadID == "" {
+		return fmt.Sprintf("<mail.%s=%s@%s>", beadID, token, domain)
+	}
+	return fmt.Sprintf("<mail.%s+%s=%s@%s>", beadID, threadID, token, domain)
+}
+
+// replyToken is the HMAC-SHA256 of a bead and thread, truncated to 128 bits.
+func replyToken(key []byte, beadID, threadID string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(beadID + "\x00" + threadID))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// threadRef is the reference every email of a thread carries, so clients
+// group them even when the thread's first message was never emailed.
+func threadRef(threadID, domain string) string {
+	return fmt.Sprintf("<thread.%s@%s>", threadID, domain)
+}
+
+// ParseMessageID returns the bead and thread named by a Message-ID from
+// MessageID in domain. ok is false for any other Message-ID, including one
+// whose token was not made with key.
+func ParseMessageID(key []byte, id, domain string) (beadID, threadID string, ok bool) {
+	id = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(id), "<"), ">")
+	at := strings.LastIndex(id, "@")
+	if at < 0 || !strings.EqualFold(id[at+1:], domain) || !strings.HasPrefix(id[:at], "mail.") {
+		return "", "", false
+	}
+	local, token, found := cutLast(strings.TrimPrefix(id[:at], "mail."), "=")
+	if !found {
+		return "", "", false
+	}
+	beadID, threadID, _ = strings.Cut(local, "+")
+	if beadID == "" || !hmac.Equal([]byte(token), []byte(replyToken(key, beadID, threadID))) {
+		return "", "", false
+	}
+	return beadID, threadID, true
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// Compose builds the email forwarding msg, sent to the mail address or
+// list to, to emails. Its Message-IDs are signed with key.
+func Compose(cfg *config.EmailConfig, key []byte, msg *mail.Message, to string, emails []string) []byte {
+	domain := Domain(cfg)
+	sender := cfg.From
+	if addr, err := netmail.ParseAddress(cfg.From); err == nil {
+		sender = (&netmail.Address{Name: msg.From, Address: addr.Address}).String()
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", sender)
+	header("To", strings.Join(emails, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", msg.Timestamp.Format(time.RFC1123Z))
+	header("Message-ID", MessageID(key, msg.ID, msg.ThreadID, domain))
+	var refs []string
+	if msg.ThreadID != "" {
+		refs = append(refs, threadRef(msg.ThreadID, domain))
+	}
+	if msg.ReplyTo != "" {
+		parent := MessageID(key, msg.ReplyTo, msg.ThreadID, domain)
+		header("In-Reply-To", parent)
+		refs = append(refs, parent)
+	}
+	if len(refs) > 0 {
+		header("References", strings.Join(refs, " "))
+	}
+	switch msg.Priority {
+	case mail.PriorityUrgent:
+		header("X-Priority", "1 (Highest)")
+		header("Importance", "high")
+	case mail.PriorityHigh:
+		header("X-Priority", "2 (High)")
+		header("Importance", "high")
+	}
+	header("X-Gastown-Mail", msg.ID)
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	fmt.Fprintf(qp, "%s\n\n-- \nMail %s from %s to %s.\nReply to this email to answer %s.\n",
+		strings.TrimRight(msg.Body, "\n"), msg.ID, msg.From, to, msg.From)
+	_ = qp.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,154 @@
+package emailbridge
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxEmailSize caps how much of an email body is read.
+const maxEmailSize = 1 << 20
+
+// errNoText means an email has no text/plain part.
+var errNoText = errors.New("no text/plain part")
+
+// Email is an email read from the maildir.
+type Email struct {
+	From      string   // sender address
+	Subject   string   // decoded subject
+	MessageID string   // the email's own Message-ID
+	Refs      []string // In-Reply-To, then References newest first
+	Body      string   // what the sender wrote, without quote or signature
+}
+
+// ParseEmail reads an RFC 5322 email.
+func ParseEmail(r io.Reader) (*Email, error) {
+	msg, err := netmail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	from, err := msg.Header.AddressList("From")
+	if err != nil || len(from) == 0 {
+		return nil, errors.New("no From address")
+	}
+	e := &Email{
+		From:      strings.ToLower(from[0].Address),
+		MessageID: strings.TrimSpace(msg.Header.Get("Message-ID")),
+	}
+	dec := new(mime.WordDecoder)
+	if e.Subject, err = dec.DecodeHeader(msg.Header.Get("Subject")); err != nil {
+		e.Subject = msg.Header.Get("Subject")
+	}
+	e.Refs = strings.Fields(msg.Header.Get("In-Reply-To"))
+	refs := strings.Fields(msg.Header.Get("References"))
+	for i := len(refs) - 1; i >= 0; i-- {
+		e.Refs = append(e.Refs, refs[i])
+	}
+
+	text, err := textBody(msg.Header, io.LimitReader(msg.Body, maxEmailSize))
+	if err != nil {
+		return nil, err
+	}
+	e.Body = replyText(text)
+	return e, nil
+}
+
+// textBody returns the first text/plain part of a body, decoded.
+func textBody(header interface{ Get(string) string }, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain" // RFC 2045 default
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return "", errNoText
+			}
+			if err != nil {
+				return "", err
+			}
+			if text, err := textBody(part.Header, part); err == nil {
+				return text, nil
+			}
+		}
+	}
+	if mediaType != "text/plain" {
+		return "", errNoText
+	}
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	data, err := io.ReadAll(body)
+	return string(data), err
+}
+
+// quoteIntro matches the line mail clients put above a quoted message,
+// e.g. "On Fri, Oct 16, 2026 at 9:02 AM mayor/ <gt@example.com> wrote:".
+var quoteIntro = regexp.MustCompile(`^On\s.*wrote:$`)
+
+// replyText returns what the sender wrote above the quoted message and
+// their signature.
+func replyText(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	var out []string
+	for _, line := range lines {
+		t := strings.TrimSpace(line)
+		if strings.HasPrefix(t, ">") || line == "-- " || quoteIntro.MatchString(t) ||
+			strings.HasPrefix(t, "-----Original Message-----") || strings.HasPrefix(t, "Sent from my ") {
+			break
+		}
+		out = append(out, line)
+	}
+	// Clients wrap a long intro line: "On ... <gt@example.com>" / "wrote:"
+	for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+		out = out[:len(out)-1]
+	}
+	if n := len(out); n >= 2 && strings.TrimSpace(out[n-1]) == "wrote:" && strings.HasPrefix(strings.TrimSpace(out[n-2]), "On ") {
+		out = out[:n-2]
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// newMail returns the names of the emails in a maildir's new/, oldest
+// first: delivery tools name them by arrival time.
+func newMail(dir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, "new"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// markSeen moves an email from new/ to cur/ with the Seen flag, as a mail
+// client that read it would.
+func markSeen(dir, name string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "cur"), 0700); err != nil {
+		return err
+	}
+	seen := name
+	if !strings.Contains(name, ":2,") {
+		seen += ":2,S"
+	}
+	return os.Rename(filepath.Join(dir, "new", name), filepath.Join(dir, "cur", seen))
+}
@@ -0,0 +1,120 @@
+package emailbridge
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// State records what the bridge has done, so nothing is emailed or
+// ingested twice.
+type State struct {
+	// Since is when the bridge first ran; earlier mail is not forwarded.
+	Since time.Time `json:"since"`
+
+	// Forwarded maps mail IDs, and "list-send:<id>" for list sends, to
+	// when they were emailed.
+	Forwarded map[string]time.Time `json:"forwarded"`
+
+	// Ingested maps the Message-IDs of ingested replies to when they were
+	// sent on as mail.
+	Ingested map[string]time.Time `json:"ingested"`
+}
+
+// Dir returns the directory holding the bridge state.
+func Dir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "email")
+}
+
+func statePath(townRoot string) string {
+	return filepath.Join(Dir(townRoot), "state.json")
+}
+
+func keyPath(townRoot string) string {
+	return filepath.Join(Dir(townRoot), "reply.key")
+}
+
+// ReplyKey returns the key that signs forwarded mail's Message-IDs,
+// generating it on first use. Call it holding the bridge lock.
+func ReplyKey(townRoot string) ([]byte, error) {
+	data, err := os.ReadFile(keyPath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if os.IsNotExist(err) {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(Dir(townRoot), 0755); err != nil {
+			return nil, err
+		}
+		if err := util.AtomicWriteFile(keyPath(townRoot), []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+			return nil, fmt.Errorf("saving reply key: %w", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("invalid reply key in %s", keyPath(townRoot))
+	}
+	return key, nil
+}
+
+// withLock runs fn holding the bridge lock.
+func withLock(townRoot string, fn func() error) error {
+	if err := os.MkdirAll(Dir(townRoot), 0755); err != nil {
+		return err
+	}
+	lock := flock.New(filepath.Join(Dir(townRoot), ".lock"))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking email bridge state: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return fn()
+}
+
+// LoadState returns the bridge state; it is empty before the first sync.
+func LoadState(townRoot string) (*State, error) {
+	st := &State{}
+	data, err := os.ReadFile(statePath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", statePath(townRoot), err)
+		}
+	}
+	if st.Forwarded == nil {
+		st.Forwarded = make(map[string]time.Time)
+	}
+	if st.Ingested == nil {
+		st.Ingested = make(map[string]time.Time)
+	}
+	return st, nil
+}
+
+func saveState(townRoot string, st *State) error {
+	return util.AtomicWriteJSON(statePath(townRoot), st)
+}
+
+// prune drops records older than MaxAge: their mail is out of range.
+func (st *State) prune(now time.Time) {
+	for _, m := range []map[string]time.Time{st.Forwarded, st.Ingested} {
+		for k, at := range m {
+			if now.Sub(at) > MaxAge {
+				delete(m, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// The audit stream (audit- and both-visibility events) is hash-chained:
+// each record carries a sequence number, the hash of the record before it,
+// and its own hash, a SHA-256 of the encoded record up to the hash field.
+// Editing or deleting a record breaks the chain at that point. Rewriting
+// every later hash to cover it up fails against the checkpoints, which sign
+// the chain head with the town's audit key every CheckpointEvery records and
+// on each KRC prune.
+
+// CheckpointEvery is how many audit records pass between checkpoints.
+const CheckpointEvery = 64
+
+// ChainHead is the last record of the audit chain.
+type ChainHead struct {
+	Seq  int64  `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// Checkpoint is a signed statement of the chain head at a point in time.
+type Checkpoint struct {
+	Seq       int64  `json:"seq"`
+	Hash      string `json:"hash"`
+	Timestamp string `json:"ts"`
+	Sig       string `json:"sig"`
+}
+
+// Chained reports whether events of a visibility belong to the audit chain.
+func Chained(visibility string) bool {
+	return visibility == VisibilityAudit || visibility == VisibilityBoth
+}
+
+// HeadPath returns the file that tracks the chain head.
+func HeadPath(townRoot string) string {
+	return filepath.Join(townRoot, SegmentsDir, ".audit-head.json")
+}
+
+// CheckpointsPath returns the checkpoint log.
+func CheckpointsPath(townRoot string) string {
+	return filepath.Join(townRoot, SegmentsDir, "checkpoints.jsonl")
+}
+
+// AuditKeyFileEnv overrides the audit key location, so the key can live
+// outside the town where agents can't use it to re-sign the chain.
+const AuditKeyFileEnv = "GT_AUDIT_KEY_FILE"
+
+// AuditKeyPath returns the town's audit signing key, honoring
+// GT_AUDIT_KEY_FILE.
+func AuditKeyPath(townRoot string) string {
+	if p := os.Getenv(AuditKeyFileEnv); p != "" {
+		return p
+	}
+	return filepath.Join(constants.TownRuntimePath(townRoot), "audit.key")
+}
+
+// AuditKeyInTown reports whether the audit key lives under the town root,
+// where agents can read it.
+func AuditKeyInTown(townRoot string) bool {
+	root, err := filepath.Abs(townRoot)
+	if err != nil {
+		return true
+	}
+	key, err := filepath.Abs(AuditKeyPath(townRoot))
+	if err != nil {
+		return true
+	}
+	rel, err := filepath.Rel(root, key)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ReadHead returns the chain head, or a zero head before the first record.
+func ReadHead(townRoot string) (*ChainHead, error) {
+	head := &ChainHead{}
+	data, err := os.ReadFile(HeadPath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if os.IsNotExist(err) {
+		return head, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, head); err != nil {
+		return nil, fmt.Errorf("parsing audit head: %w", err)
+	}
+	return head, nil
+}
+
+// chain links an event onto the audit chain and returns its encoding and
+// the new head. The caller holds the events lock, so the timestamp is taken
+// here to keep sequence and time order in step.
+func chain(townRoot string, event *Event) ([]byte, *ChainHead, error) {
+	head, err := ReadHead(townRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	event.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	event.Seq = head.Seq + 1
+	event.Prev = head.Hash
+	event.Hash = ""
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	event.Hash = HashRecord(body)
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, &ChainHead{Seq: event.Seq, Hash: event.Hash}, nil
+}
+
+// advanceChain records a written record as the new head and checkpoints
+// every CheckpointEvery records.
+func advanceChain(townRoot string, head *ChainHead) error {
+	if err := util.AtomicWriteJSON(HeadPath(townRoot), head); err != nil {
+		return fmt.Errorf("saving audit head: %w", err)
+	}
+	if head.Seq%CheckpointEvery == 0 {
+		if _, err := WriteCheckpoint(townRoot); err != nil {
+			return fmt.Errorf("writing audit checkpoint: %w", err)
+		}
+	}
+	return nil
+}
+
+// HashRecord hashes an encoded record without its hash field.
+func HashRecord(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// SplitHash splits an encoded chained record into the bytes its hash covers
+// and the hash it claims. ok is false for records without a trailing hash.
+func SplitHash(line []byte) (body []byte, hash string, ok bool) {
+	line = bytes.TrimRight(line, "\r\n")
+	const prefix = `,"hash":"`
+	const hashLen = sha256.Size * 2
+	n := len(prefix) + hashLen + len(`"}`)
+	if len(line) < n || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return nil, "", false
+	}
+	start := len(line) - n
+	if !bytes.Equal(line[start:start+len(prefix)], []byte(prefix)) {
+		return nil, "", false
+	}
+	hash = string(line[start+len(prefix) : len(line)-2])
+	body = append(append([]byte(nil), line[:start]...), '}')
+	return body, hash, true
+}
+
+// AuditKey returns the town's audit key. With create, a missing key is
+// generated; otherwise a missing key returns an error satisfying
+// os.IsNotExist.
+func AuditKey(townRoot string, create bool) ([]byte, error) {
+	path := AuditKeyPath(townRoot)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if os.IsNotExist(err) && create {
+		return createAuditKey(townRoot)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("invalid audit key in %s", path)
+	}
+	return key, nil
+}
+
+func createAuditKey(townRoot string) ([]byte, error) {
+	path := AuditKeyPath(townRoot)
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if errors.Is(err, os.ErrExist) {
+		return AuditKey(townRoot, false) // Lost the race: use the winner's key
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// SignAudit returns the HMAC-SHA256 of fields under the audit key.
+func SignAudit(key []byte, fields ...string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *Checkpoint) fields() []string {
+	return []string{"checkpoint", strconv.FormatInt(c.Seq, 10), c.Hash, c.Timestamp}
+}
+
+// Verify reports whether the checkpoint was signed with key.
+func (c *Checkpoint) Verify(key []byte) bool {
+	return hmac.Equal([]byte(c.Sig), []byte(SignAudit(key, c.fields()...)))
+}
+
+// WriteCheckpoint signs the current chain head and appends it to the
+// checkpoint log. It returns nil when there are no records yet or the head
+// is already checkpointed. Callers other than Append must hold the events
+// lock (LockPath).
+func WriteCheckpoint(townRoot string) (*Checkpoint, error) {
+	head, err := ReadHead(townRoot)
+	if err != nil || head.Seq == 0 {
+		return nil, err
+	}
+	existing, err := LoadCheckpoints(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(existing); n > 0 && existing[n-1].Seq >= head.Seq {
+		return nil, nil
+	}
+	key, err := AuditKey(townRoot, true)
+	if err != nil {
+		return nil, fmt.Errorf("loading audit key: %w", err)
+	}
+
+	cp := &Checkpoint{Seq: head.Seq, Hash: head.Hash, Timestamp: time.Now().UTC().Format(time.RFC3339)}
+	cp.Sig = SignAudit(key, cp.fields()...)
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(CheckpointsPath(townRoot), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: checkpoints are public, only the key is secret
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// LoadCheckpoints reads the checkpoint log, oldest first. Lines that do not
+// parse are returned as zero checkpoints so verification can flag them.
+func LoadCheckpoints(townRoot string) ([]Checkpoint, error) {
+	f, err := os.Open(CheckpointsPath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Checkpoint
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var cp Checkpoint
+		_ = json.Unmarshal(line, &cp)
+		out = append(out, cp)
+	}
+	return out, scanner.Err()
+}
@@ -1,7 +1,9 @@
 // Package events provides event logging for the gt activity feed.
 //
 // Events are written to ~/gt/.events.jsonl (raw audit log) and later
-// curated by the feed daemon into ~/.feed.jsonl (user-facing).
+// curated by the feed daemon into ~/.feed.jsonl (user-facing). Older days
+// are rotated into indexed segments under ~/gt/.events/ by the eventstore
+// package.
 package events
 
 import (
@@ -12,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gofrs/flock"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -23,6 +26,12 @@ type Event struct {
 	Actor      string                 `json:"actor"`
 	Payload    map[string]interface{} `json:"payload,omitempty"`
 	Visibility string                 `json:"visibility"`
+
+	// Audit chain fields, set on audit-visible events (see chain.go).
+	// Hash must stay last: it covers the encoded event up to it.
+	Seq  int64  `json:"seq,omitempty"`
+	Prev string `json:"prev,omitempty"`
+	Hash string `json:"hash,omitempty"`
 }
 
 // Visibility levels for events.
@@ -54,6 +63,9 @@ const (
 	TypeSessionDeath = "session_death" // Feed-visible session termination
 	TypeMassDeath    = "mass_death"    // Multiple sessions died in short window
 
+	// Resource events (emitted by the daemon's resource sampler)
+	TypeResourceAlert = "resource_alert" // Agent spinning at full CPU
+
 	// Witness patrol events
 	TypePatrolStarted   = "patrol_started"
 	TypePolecatChecked  = "polecat_checked"
@@ -68,11 +80,26 @@ const (
 	TypeMerged       = "merged"
 	TypeMergeFailed  = "merge_failed"
 	TypeMergeSkipped = "merge_skipped"
+
+	// Inbound webhook events (emitted by the inbound receiver)
+	TypeInbound = "inbound"
+
+	// Receipts for mail sent with --require-ack: acked, redelivered, escalated
+	TypeMailReceipt = "mail_receipt"
 )
 
 // EventsFile is the name of the raw events log.
 const EventsFile = ".events.jsonl"
 
+// SegmentsDir holds the sealed segments of the events log.
+const SegmentsDir = ".events"
+
+// LockPath returns the lock that serializes appends to the events log with
+// segment rotation.
+func LockPath(townRoot string) string {
+	return filepath.Join(townRoot, SegmentsDir, ".lock")
+}
+
 // mutex protects concurrent writes to the events file.
 var mutex sync.Mutex
 
@@ -96,6 +123,22 @@ func LogFeed(eventType, actor string, payload map[string]interface{}) error {
 	return Log(eventType, actor, payload, VisibilityFeed)
 }
 
+// LogFeedTo logs a feed-visible event to a known town, for callers that
+// don't run inside it (servers, checks given a town root).
+func LogFeedTo(townRoot, eventType, actor string, payload map[string]interface{}) error {
+	if townRoot == "" {
+		return nil
+	}
+	return Append(townRoot, Event{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Source:     "gt",
+		Type:       eventType,
+		Actor:      actor,
+		Payload:    payload,
+		Visibility: VisibilityFeed,
+	})
+}
+
 // LogAudit is a convenience wrapper for audit-only events.
 func LogAudit(eventType, actor string, payload map[string]interface{}) error {
 	return Log(eventType, actor, payload, VisibilityAudit)
@@ -109,20 +152,43 @@ func write(event Event) error {
 		// Silently ignore - we're not in a Gas Town workspace
 		return nil
 	}
+	return Append(townRoot, event)
+}
 
+// Append writes an event to the events file of a known town. Audit-visible
+// events are chained onto the audit log (see chain.go).
+func Append(townRoot string, event Event) error {
 	eventsPath := filepath.Join(townRoot, EventsFile)
 
+	// Append to file with proper locking
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// Hold off segment rotation while appending. The lock file is created
+	// here if need be, so even the first append can't race the first Seal.
+	if err := os.MkdirAll(filepath.Join(townRoot, SegmentsDir), 0755); err != nil {
+		return fmt.Errorf("creating segments directory: %w", err)
+	}
+	lock := flock.New(LockPath(townRoot))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking events log: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
 	// Marshal event to JSON
-	data, err := json.Marshal(event)
+	var data []byte
+	var head *ChainHead
+	var err error
+	if Chained(event.Visibility) {
+		data, head, err = chain(townRoot, &event)
+	} else {
+		data, err = json.Marshal(event)
+	}
 	if err != nil {
 		return fmt.Errorf("marshaling event: %w", err)
 	}
 	data = append(data, '\n')
 
-	// Append to file with proper locking
-	mutex.Lock()
-	defer mutex.Unlock()
-
 	f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events file is non-sensitive operational data
 	if err != nil {
 		return fmt.Errorf("opening events file: %w", err)
@@ -133,6 +199,9 @@ func write(event Event) error {
 		return fmt.Errorf("writing event: %w", err)
 	}
 
+	if head != nil {
+		return advanceChain(townRoot, head)
+	}
 	return nil
 }
 
@@ -180,6 +249,18 @@ func MailPayload(to, subject string) map[string]interface{} {
 	}
 }
 
+// MailReceiptPayload creates a payload for mail receipt events. from is
+// the original sender, who the receipt is for.
+func MailReceiptPayload(messageID, from, to, subject, status string) map[string]interface{} {
+	return map[string]interface{}{
+		"message": messageID,
+		"from":    from,
+		"to":      to,
+		"subject": subject,
+		"status":  status,
+	}
+}
+
 // SpawnPayload creates a payload for spawn events.
 func SpawnPayload(rig, polecat string) map[string]interface{} {
 	return map[string]interface{}{
@@ -294,6 +375,18 @@ func SessionDeathPayload(session, agent, reason, caller string) map[string]inter
 	}
 }
 
+// ResourceAlertPayload creates a payload for resource alert events.
+// session: tmux session of the agent
+// cpuPercent: CPU use of the agent's process tree (100 = one core)
+// rssBytes: resident memory of the agent's process tree
+func ResourceAlertPayload(session string, cpuPercent float64, rssBytes int64) map[string]interface{} {
+	return map[string]interface{}{
+		"session":     session,
+		"cpu_percent": cpuPercent,
+		"rss_bytes":   rssBytes,
+	}
+}
+
 // MassDeathPayload creates a payload for mass death events.
 // count: number of sessions that died
 // window: time window in which deaths occurred (e.g., "5s")
@@ -0,0 +1,217 @@
+package eventstore
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// writeActive writes events to the active segment, one per (age, type, actor).
+func writeActive(t *testing.T, townRoot string, now time.Time, evs ...testEvent) {
+	t.Helper()
+	var b strings.Builder
+	for _, e := range evs {
+		data, _ := json.Marshal(events.Event{
+			Timestamp: now.Add(-e.age).UTC().Format(time.RFC3339),
+			Source:    "gt",
+			Type:      e.typ,
+			Actor:     e.actor,
+		})
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, events.EventsFile), []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type testEvent struct {
+	age   time.Duration
+	typ   string
+	actor string
+}
+
+const day = 24 * time.Hour
+
+func query(t *testing.T, townRoot string, q Query) []string {
+	t.Helper()
+	var got []string
+	if err := Scan(townRoot, q, func(e *events.Event) bool {
+		got = append(got, e.Type+" "+e.Actor)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestAppendBeforeRotation(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := events.LogFeedTo(townRoot, "sling", "mayor", nil); err != nil {
+		t.Fatal(err)
+	}
+	// Even the first append takes the lock Seal rotates under
+	if _, err := os.Stat(events.LockPath(townRoot)); err != nil {
+		t.Errorf("append did not create the events lock: %v", err)
+	}
+
+	if _, err := Seal(townRoot, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := events.LogFeedTo(townRoot, "done", "gastown/polecats/Toast", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := query(t, townRoot, Query{}); len(got) != 2 {
+		t.Errorf("query = %q, want both events", got)
+	}
+}
+
+func TestSealAndQuery(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	writeActive(t, townRoot, now,
+		testEvent{5 * day, "sling", "mayor"},
+		testEvent{5*day - time.Hour, "merged", "gastown/refinery"},
+		testEvent{3 * day, "done", "gastown/polecats/Toast"},
+		testEvent{3*day - time.Hour, "merge_failed", "gastown/refinery"},
+		testEvent{30 * time.Hour, "done", "gastown/polecats/Nux"}, // yesterday: stays active
+		testEvent{time.Hour, "merged", "gastown/refinery"},
+	)
+
+	res, err := Seal(townRoot, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Sealed != 4 || res.Retained != 2 || len(res.Days) != 2 || res.Days[0] != "2026-03-05" {
+		t.Fatalf("Seal = %+v", res)
+	}
+	active, _ := os.ReadFile(filepath.Join(townRoot, events.EventsFile))
+	if n := strings.Count(string(active), "\n"); n != 2 {
+		t.Errorf("active segment has %d events, want 2", n)
+	}
+
+	// Sealing again is a no-op
+	if res, _ := Seal(townRoot, now); res.Sealed != 0 {
+		t.Errorf("second Seal moved %d events", res.Sealed)
+	}
+
+	got := query(t, townRoot, Query{Types: []string{"merge*"}})
+	want := []string{"merged gastown/refinery", "merge_failed gastown/refinery", "merged gastown/refinery"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("merge* = %q, want %q", got, want)
+	}
+
+	got = query(t, townRoot, Query{Actors: []string{"gastown/polecats/*"}, Types: []string{"done"}, Since: now.Add(-4 * day)})
+	want = []string{"done gastown/polecats/Toast", "done gastown/polecats/Nux"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("polecat done = %q, want %q", got, want)
+	}
+
+	// A stale index is rebuilt from its segment
+	if err := os.WriteFile(indexPath(townRoot, "2026-03-05", false), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := query(t, townRoot, Query{Types: []string{"sling"}}); len(got) != 1 {
+		t.Errorf("sling after index loss = %q", got)
+	}
+}
+
+func TestArchiveMovesExpiredEventsToGzip(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	writeActive(t, townRoot, now,
+		testEvent{10 * day, "patrol_started", "gastown/witness"},
+		testEvent{10 * day, "mail", "mayor"},
+		testEvent{3 * day, "patrol_started", "gastown/witness"},
+	)
+	if _, err := Seal(townRoot, now); err != nil {
+		t.Fatal(err)
+	}
+
+	ttl := func(eventType string) time.Duration {
+		if strings.HasPrefix(eventType, "patrol_") {
+			return day
+		}
+		return 30 * day
+	}
+	res, err := Archive(townRoot, ttl, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Archived != 2 || res.ByType["patrol_started"] != 2 || res.BytesAfter >= res.BytesBefore {
+		t.Fatalf("Archive = %+v", res)
+	}
+
+	// The 3-day-old segment held only patrol noise: gone from the hot store
+	if _, err := os.Stat(segmentPath(townRoot, "2026-03-07", false)); !os.IsNotExist(err) {
+		t.Errorf("emptied segment still exists: %v", err)
+	}
+	if got := query(t, townRoot, Query{}); len(got) != 1 || got[0] != "mail mayor" {
+		t.Errorf("hot events = %q, want just the mail", got)
+	}
+	if got := query(t, townRoot, Query{Types: []string{"patrol_*"}, Archived: true}); len(got) != 2 {
+		t.Errorf("archived patrol events = %q, want 2", got)
+	}
+
+	// Archiving appends gzip members; the archive still reads as one stream
+	writeActive(t, townRoot, now, testEvent{10*day - time.Hour, "patrol_complete", "gastown/witness"})
+	if _, err := Seal(townRoot, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Archive(townRoot, ttl, now); err != nil {
+		t.Fatal(err)
+	}
+	got := query(t, townRoot, Query{Since: now.Add(-11 * day), Until: now.Add(-9 * day), Archived: true})
+	want := []string{"patrol_started gastown/witness", "mail mayor", "patrol_complete gastown/witness"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("day with archive = %q, want %q", got, want)
+	}
+
+	segs, err := Segments(townRoot)
+	if err != nil || len(segs) != 3 || !segs[1].Archived || segs[1].Events != 2 {
+		t.Errorf("Segments = %+v, %v", segs, err)
+	}
+}
+
+func TestPruneArchive(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	writeActive(t, townRoot, now,
+		testEvent{40 * day, "mail", "mayor"},
+		testEvent{10 * day, "mail", "mayor"},
+	)
+	if _, err := Seal(townRoot, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Archive(townRoot, func(string) time.Duration { return day }, now); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := PruneArchive(townRoot, 30*day, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0] != "2026-01-29" {
+		t.Fatalf("PruneArchive deleted %v, want [2026-01-29]", deleted)
+	}
+	if got := query(t, townRoot, Query{Archived: true}); len(got) != 1 {
+		t.Errorf("archived events after prune = %q, want the 10-day-old one", got)
+	}
+}
+
+func TestPostings(t *testing.T) {
+	p := Postings{0, 120, 121, 4096}
+	data, _ := json.Marshal(p)
+	if string(data) != "[0,120,1,3975]" {
+		t.Errorf("Postings JSON = %s", data)
+	}
+	var back Postings
+	if err := json.Unmarshal(data, &back); err != nil || len(back) != 4 || back[3] != 4096 {
+		t.Errorf("round trip = %v, %v", back, err)
+	}
+}
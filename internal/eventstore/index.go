@@ -0,0 +1,211 @@
+package eventstore
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Index summarizes one segment: its time range and, for every event type and
+// actor, the byte offsets of the lines that carry it. Offsets are into the
+// uncompressed segment, so archives use the same layout.
+type Index struct {
+	Day    string              `json:"day"`
+	Start  time.Time           `json:"start"`
+	End    time.Time           `json:"end"`
+	Count  int                 `json:"count"`
+	Size   int64               `json:"size"`
+	Types  map[string]Postings `json:"types"`
+	Actors map[string]Postings `json:"actors"`
+}
+
+// Postings are ascending line offsets. They are stored delta-encoded, which
+// keeps the index a small fraction of the segment.
+type Postings []int64
+
+// MarshalJSON writes the gaps between offsets.
+func (p Postings) MarshalJSON() ([]byte, error) {
+	deltas := make([]int64, len(p))
+	var prev int64
+	for i, off := range p {
+		deltas[i] = off - prev
+		prev = off
+	}
+	return json.Marshal(deltas)
+}
+
+// UnmarshalJSON restores offsets from their gaps.
+func (p *Postings) UnmarshalJSON(data []byte) error {
+	var deltas []int64
+	if err := json.Unmarshal(data, &deltas); err != nil {
+		return err
+	}
+	out := make(Postings, len(deltas))
+	var off int64
+	for i, d := range deltas {
+		off += d
+		out[i] = off
+	}
+	*p = out
+	return nil
+}
+
+func newIndex(day string) *Index {
+	return &Index{Day: day, Types: make(map[string]Postings), Actors: make(map[string]Postings)}
+}
+
+// add indexes one line written at the end of the segment. Lines that are not
+// events are counted in Size only.
+func (idx *Index) add(line []byte) {
+	offset := idx.Size
+	idx.Size += int64(len(line))
+	e, ts, ok := parseLine(line)
+	if !ok {
+		return
+	}
+	idx.Count++
+	if idx.Start.IsZero() || ts.Before(idx.Start) {
+		idx.Start = ts
+	}
+	if ts.After(idx.End) {
+		idx.End = ts
+	}
+	idx.Types[e.Type] = append(idx.Types[e.Type], offset)
+	idx.Actors[e.Actor] = append(idx.Actors[e.Actor], offset)
+}
+
+// overlaps reports whether the segment can hold events in [since, until].
+// Zero bounds are open.
+func (idx *Index) overlaps(since, until time.Time) bool {
+	if idx.Count == 0 {
+		return false
+	}
+	if !since.IsZero() && idx.End.Before(since) {
+		return false
+	}
+	return until.IsZero() || !idx.Start.After(until)
+}
+
+// candidates returns the offsets of lines that can match the query's type
+// and actor globs, or all=true when the query does not filter on either.
+func (idx *Index) candidates(q Query) (offsets []int64, all bool) {
+	if len(q.Types) == 0 && len(q.Actors) == 0 {
+		return nil, true
+	}
+	var sets []map[int64]bool
+	for _, dim := range []struct {
+		globs    []string
+		postings map[string]Postings
+	}{{q.Types, idx.Types}, {q.Actors, idx.Actors}} {
+		if len(dim.globs) == 0 {
+			continue
+		}
+		set := make(map[int64]bool)
+		for key, offs := range dim.postings {
+			if matchAny(dim.globs, key) {
+				for _, off := range offs {
+					set[off] = true
+				}
+			}
+		}
+		sets = append(sets, set)
+	}
+	for off := range sets[0] {
+		if len(sets) == 1 || sets[1][off] {
+			offsets = append(offsets, off)
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets, false
+}
+
+// loadIndex reads a segment's index, rebuilding it from the segment when it
+// is missing, unreadable or stale.
+func loadIndex(indexPath, segmentPath, day string, gzipped bool) (*Index, error) {
+	if data, err := os.ReadFile(indexPath); err == nil { //nolint:gosec // G304: path is constructed from trusted townRoot
+		idx := newIndex(day)
+		if json.Unmarshal(data, idx) == nil && (gzipped || sizeOf(segmentPath) == idx.Size) {
+			return idx, nil
+		}
+	}
+	idx, err := buildIndex(segmentPath, day, gzipped)
+	if err != nil {
+		return nil, err
+	}
+	_ = util.AtomicWriteJSON(indexPath, idx) // Best-effort: rebuilt next time otherwise
+	return idx, nil
+}
+
+// buildIndex indexes a segment from scratch.
+func buildIndex(segmentPath, day string, gzipped bool) (*Index, error) {
+	idx := newIndex(day)
+	err := eachLine(segmentPath, gzipped, func(line []byte) bool {
+		idx.add(line)
+		return true
+	})
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	return idx, err
+}
+
+// eachLine calls fn for every newline-terminated line of a segment until fn
+// returns false. The line is only valid during the call.
+func eachLine(path string, gzipped bool, fn func(line []byte) bool) error {
+	f, err := os.Open(path) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	reader := bufio.NewReaderSize(r, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !fn(line) {
+			return nil
+		}
+	}
+}
+
+// parseLine decodes an event line and its timestamp.
+func parseLine(line []byte) (*events.Event, time.Time, bool) {
+	var e events.Event
+	if err := json.Unmarshal(line, &e); err != nil {
+		return nil, time.Time{}, false
+	}
+	ts, err := time.Parse(time.RFC3339, e.Timestamp)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return &e, ts, true
+}
+
+func sizeOf(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
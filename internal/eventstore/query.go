@@ -0,0 +1,200 @@
+package eventstore
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Query selects events. Zero fields do not filter.
+type Query struct {
+	Types  []string  // event type globs, e.g. "merge_*"
+	Actors []string  // actor globs, e.g. "gastown/polecats/*"
+	Since  time.Time // inclusive
+	Until  time.Time // inclusive
+
+	// Archived also searches the gzip archive of expired events.
+	Archived bool
+}
+
+// Matches reports whether an event satisfies the query.
+func (q Query) Matches(e *events.Event, ts time.Time) bool {
+	if len(q.Types) > 0 && !matchAny(q.Types, e.Type) {
+		return false
+	}
+	if len(q.Actors) > 0 && !matchAny(q.Actors, e.Actor) {
+		return false
+	}
+	if !q.Since.IsZero() && ts.Before(q.Since) {
+		return false
+	}
+	return q.Until.IsZero() || !ts.After(q.Until)
+}
+
+// Scan calls fn for every event matching q, oldest first: archived and
+// sealed segments by day, then the active segment. Returning false from fn
+// stops the scan.
+func Scan(townRoot string, q Query, fn func(*events.Event) bool) error {
+	days, err := listDays(townRoot, false)
+	if err != nil {
+		return err
+	}
+	archived := make(map[string]bool)
+	if q.Archived {
+		archivedDays, err := listDays(townRoot, true)
+		if err != nil {
+			return err
+		}
+		for _, day := range archivedDays {
+			archived[day] = true
+		}
+		days = mergeDays(days, archivedDays)
+	}
+
+	for _, day := range days {
+		var found []matched
+		collect := func(e *events.Event, ts time.Time) {
+			found = append(found, matched{e, ts})
+		}
+		if archived[day] {
+			if err := scanSegment(townRoot, day, true, q, collect); err != nil {
+				return err
+			}
+		}
+		if err := scanSegment(townRoot, day, false, q, collect); err != nil {
+			return err
+		}
+		// Archive and segment split a day by TTL, not time: interleave them
+		if archived[day] {
+			sort.SliceStable(found, func(i, j int) bool { return found[i].ts.Before(found[j].ts) })
+		}
+		for _, m := range found {
+			if !fn(m.event) {
+				return nil
+			}
+		}
+	}
+
+	err = eachLine(filepath.Join(townRoot, events.EventsFile), false, func(line []byte) bool {
+		if e, ts, ok := parseLine(line); ok && q.Matches(e, ts) {
+			return fn(e)
+		}
+		return true
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+type matched struct {
+	event *events.Event
+	ts    time.Time
+}
+
+// scanSegment collects the matching events of one segment.
+func scanSegment(townRoot, day string, gzipped bool, q Query, collect func(*events.Event, time.Time)) error {
+	path := segmentPath(townRoot, day, gzipped)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	idx, err := loadIndex(indexPath(townRoot, day, gzipped), path, day, gzipped)
+	if err != nil {
+		return err
+	}
+	if !idx.overlaps(q.Since, q.Until) {
+		return nil
+	}
+	offsets, all := idx.candidates(q)
+	if !all && len(offsets) == 0 {
+		return nil
+	}
+
+	emit := func(line []byte) {
+		if e, ts, ok := parseLine(line); ok && q.Matches(e, ts) {
+			collect(e, ts)
+		}
+	}
+	if all || gzipped {
+		// Read sequentially; with postings, parse only the indexed lines
+		want := make(map[int64]bool, len(offsets))
+		for _, off := range offsets {
+			want[off] = true
+		}
+		var pos int64
+		err := eachLine(path, gzipped, func(line []byte) bool {
+			if all || want[pos] {
+				emit(line)
+			}
+			pos += int64(len(line))
+			return true
+		})
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return readAt(path, offsets, emit)
+}
+
+// readAt reads the lines starting at the given ascending offsets, reusing
+// the buffered reader across consecutive lines.
+func readAt(path string, offsets []int64, fn func(line []byte)) error {
+	f, err := os.Open(path) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var reader *bufio.Reader
+	pos := int64(-1)
+	for _, off := range offsets {
+		if off != pos {
+			reader = bufio.NewReader(io.NewSectionReader(f, off, 1<<62))
+			pos = off
+		}
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF {
+				return nil // Segment rewritten under us: the rest is gone
+			}
+			return err
+		}
+		pos += int64(len(line))
+		fn(line)
+	}
+	return nil
+}
+
+// mergeDays returns the sorted union of two sorted day lists.
+func mergeDays(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var out []string
+	for _, day := range append(append([]string(nil), a...), b...) {
+		if !seen[day] {
+			seen[day] = true
+			out = append(out, day)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// matchAny reports whether s matches any of the globs.
+func matchAny(globs []string, s string) bool {
+	for _, g := range globs {
+		if util.MatchGlob(g, s) {
+			return true
+		}
+	}
+	return false
+}
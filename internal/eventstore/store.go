@@ -0,0 +1,435 @@
+// Package eventstore keeps the town event log as time-segmented, indexed
+// files and answers queries over them.
+//
+// New events are appended to ~/gt/.events.jsonl, the active segment, which
+// the feed curator, webhooks and gt feed tail. Seal moves every event older
+// than yesterday (UTC) out of it into one file per day:
+//
+//	.events/2026-01-14.jsonl           sealed segment
+//	.events/2026-01-14.idx.json        time range, type and actor postings
+//	.events/archive/2026-01-14.jsonl.gz  expired events, gzip-compressed
+//	.events/archive/2026-01-14.idx.json
+//
+// Archive moves events past their KRC TTL from sealed segments into the
+// gzip archive rather than deleting them. The archive is kept forever
+// unless PruneArchive is given a retention. Seal also anchors each day's
+// audit records (see Anchor) so the audit chain stays verifiable once old
+// archives are deleted. Scan reads segments in time order,
+// skipping those outside the time range and seeking straight to the lines
+// the index says can match.
+package eventstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// dayLayout names segments.
+const dayLayout = "2006-01-02"
+
+// ArchiveDir is the archive's directory under the segments directory.
+const ArchiveDir = "archive"
+
+// Dir returns the directory holding sealed segments.
+func Dir(townRoot string) string {
+	return filepath.Join(townRoot, events.SegmentsDir)
+}
+
+func segmentPath(townRoot, day string, archived bool) string {
+	if archived {
+		return filepath.Join(Dir(townRoot), ArchiveDir, day+".jsonl.gz")
+	}
+	return filepath.Join(Dir(townRoot), day+".jsonl")
+}
+
+func indexPath(townRoot, day string, archived bool) string {
+	if archived {
+		return filepath.Join(Dir(townRoot), ArchiveDir, day+".idx.json")
+	}
+	return filepath.Join(Dir(townRoot), day+".idx.json")
+}
+
+// withLock runs fn holding a lock file. The events log lock (which
+// events.Log also takes to append) guards the active segment; the segments
+// lock guards sealed segments and the archive, so archiving does not hold up
+// writers.
+func withLock(townRoot, path string, fn func() error) error {
+	if err := os.MkdirAll(filepath.Join(Dir(townRoot), ArchiveDir), 0755); err != nil {
+		return err
+	}
+	lock := flock.New(path)
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking %s: %w", filepath.Base(path), err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return fn()
+}
+
+func segmentsLock(townRoot string) string {
+	return filepath.Join(Dir(townRoot), ".segments.lock")
+}
+
+// SealCutoff returns the boundary Seal uses: the start of yesterday (UTC).
+// The active segment keeps at least a full day of history for tailers.
+func SealCutoff(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+}
+
+// SealResult describes one Seal.
+type SealResult struct {
+	Sealed   int      `json:"sealed"`   // events moved into segments
+	Retained int      `json:"retained"` // events left in the active segment
+	Days     []string `json:"days,omitempty"`
+}
+
+// Seal moves events older than SealCutoff from the active segment into their
+// day segments and indexes them. Lines without a parseable timestamp stay
+// in the active segment.
+func Seal(townRoot string, now time.Time) (*SealResult, error) {
+	result := &SealResult{}
+	err := withLock(townRoot, events.LockPath(townRoot), func() error {
+		return withLock(townRoot, segmentsLock(townRoot), func() error {
+			return seal(townRoot, now, result)
+		})
+	})
+	return result, err
+}
+
+// seal does the work of Seal with both locks held.
+func seal(townRoot string, now time.Time, result *SealResult) error {
+	activePath := filepath.Join(townRoot, events.EventsFile)
+	data, err := os.ReadFile(activePath) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cutoff := SealCutoff(now)
+	byDay := make(map[string][][]byte)
+	var retained bytes.Buffer
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			retained.Write(data) // Unterminated tail: leave it for the writer
+			break
+		}
+		line := data[:i+1]
+		data = data[i+1:]
+		if _, ts, ok := parseLine(line); ok && ts.Before(cutoff) {
+			day := ts.UTC().Format(dayLayout)
+			byDay[day] = append(byDay[day], line)
+			result.Sealed++
+			continue
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			result.Retained++
+		}
+		retained.Write(line)
+	}
+	if result.Sealed == 0 {
+		return nil
+	}
+
+	for day, lines := range byDay {
+		if err := appendSegment(townRoot, day, lines); err != nil {
+			return fmt.Errorf("sealing %s: %w", day, err)
+		}
+		result.Days = append(result.Days, day)
+	}
+	sort.Strings(result.Days)
+	if err := updateAnchors(townRoot, result.Days); err != nil {
+		return fmt.Errorf("anchoring audit chain: %w", err)
+	}
+	return util.AtomicWriteFile(activePath, retained.Bytes(), 0644)
+}
+
+// appendSegment appends lines to a sealed segment and its index.
+func appendSegment(townRoot, day string, lines [][]byte) error {
+	path := segmentPath(townRoot, day, false)
+	idx, err := loadIndex(indexPath(townRoot, day, false), path, day, false)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events are non-sensitive operational data
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if _, err := f.Write(line); err != nil {
+			_ = f.Close()
+			return err
+		}
+		idx.add(line)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(indexPath(townRoot, day, false), idx)
+}
+
+// ArchiveResult describes one Archive.
+type ArchiveResult struct {
+	Processed   int            `json:"processed"` // events in sealed segments
+	Archived    int            `json:"archived"`
+	ByType      map[string]int `json:"by_type"`
+	BytesBefore int64          `json:"bytes_before"` // sealed segment bytes
+	BytesAfter  int64          `json:"bytes_after"`
+}
+
+// Archive moves expired events from sealed segments into the gzip archive.
+// ttl gives the lifetime of each event type. Segments whose index shows no
+// type old enough to expire are not read.
+func Archive(townRoot string, ttl func(eventType string) time.Duration, now time.Time) (*ArchiveResult, error) {
+	result := &ArchiveResult{ByType: make(map[string]int)}
+	err := withLock(townRoot, segmentsLock(townRoot), func() error {
+		days, err := listDays(townRoot, false)
+		if err != nil {
+			return err
+		}
+		for _, day := range days {
+			path := segmentPath(townRoot, day, false)
+			size := sizeOf(path)
+			result.BytesBefore += size
+			idx, err := loadIndex(indexPath(townRoot, day, false), path, day, false)
+			if err != nil {
+				return err
+			}
+			if !mayExpire(idx, ttl, now) {
+				result.Processed += idx.Count
+				result.BytesAfter += size
+				continue
+			}
+			after, err := archiveSegment(townRoot, day, ttl, now, result)
+			if err != nil {
+				return fmt.Errorf("archiving %s: %w", day, err)
+			}
+			result.BytesAfter += after
+		}
+		return nil
+	})
+	return result, err
+}
+
+// PruneArchive deletes archived days that ended more than retention ago,
+// and returns the days deleted. The audit chain still verifies across them
+// through their anchors.
+func PruneArchive(townRoot string, retention time.Duration, now time.Time) ([]string, error) {
+	var deleted []string
+	err := withLock(townRoot, segmentsLock(townRoot), func() error {
+		days, err := listDays(townRoot, true)
+		if err != nil {
+			return err
+		}
+		cutoff := now.Add(-retention)
+		for _, day := range days {
+			start, _ := time.Parse(dayLayout, day) // listDays only returns valid days
+			if start.AddDate(0, 0, 1).After(cutoff) {
+				continue
+			}
+			if err := os.Remove(segmentPath(townRoot, day, true)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if err := os.Remove(indexPath(townRoot, day, true)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			deleted = append(deleted, day)
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// mayExpire reports whether any event type in a segment could be past its
+// TTL, judging by the segment's oldest event.
+func mayExpire(idx *Index, ttl func(string) time.Duration, now time.Time) bool {
+	for t := range idx.Types {
+		if now.Sub(idx.Start) > ttl(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveSegment splits one segment into expired and live events, appends
+// the expired ones to the day's archive as a new gzip member, and rewrites
+// the segment with the rest. It returns the segment's new size.
+func archiveSegment(townRoot, day string, ttl func(string) time.Duration, now time.Time, result *ArchiveResult) (int64, error) {
+	path := segmentPath(townRoot, day, false)
+	var expired, kept [][]byte
+	err := eachLine(path, false, func(line []byte) bool {
+		line = append([]byte(nil), line...)
+		e, ts, ok := parseLine(line)
+		if ok {
+			result.Processed++
+		}
+		if ok && now.Sub(ts) > ttl(e.Type) {
+			expired = append(expired, line)
+			result.ByType[e.Type]++
+		} else {
+			kept = append(kept, line)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(expired) == 0 {
+		return sizeOf(path), nil
+	}
+
+	if err := appendArchive(townRoot, day, expired); err != nil {
+		return 0, err
+	}
+	result.Archived += len(expired)
+
+	if len(kept) == 0 {
+		if err := os.Remove(path); err != nil {
+			return 0, err
+		}
+		_ = os.Remove(indexPath(townRoot, day, false))
+		return 0, nil
+	}
+	idx := newIndex(day)
+	var buf bytes.Buffer
+	for _, line := range kept {
+		buf.Write(line)
+		idx.add(line)
+	}
+	if err := util.AtomicWriteFile(path, buf.Bytes(), 0644); err != nil {
+		return 0, err
+	}
+	return idx.Size, util.AtomicWriteJSON(indexPath(townRoot, day, false), idx)
+}
+
+// appendArchive appends lines to a day's archive. Each call adds a gzip
+// member; readers see the members as one stream.
+func appendArchive(townRoot, day string, lines [][]byte) error {
+	path := segmentPath(townRoot, day, true)
+	idx, err := loadIndex(indexPath(townRoot, day, true), path, day, true)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events are non-sensitive operational data
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(f)
+	for _, line := range lines {
+		if _, err := gz.Write(line); err != nil {
+			_ = f.Close()
+			return err
+		}
+		idx.add(line)
+	}
+	if err := gz.Close(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(indexPath(townRoot, day, true), idx)
+}
+
+// listDays returns the days with a sealed (or archived) segment, oldest
+// first.
+func listDays(townRoot string, archived bool) ([]string, error) {
+	dir := Dir(townRoot)
+	suffix := ".jsonl"
+	if archived {
+		dir = filepath.Join(dir, ArchiveDir)
+		suffix = ".jsonl.gz"
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var days []string
+	for _, e := range entries {
+		day, ok := strings.CutSuffix(e.Name(), suffix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(dayLayout, day); err == nil {
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+// Segment describes one sealed or archived segment.
+type Segment struct {
+	Day      string    `json:"day"`
+	Archived bool      `json:"archived,omitempty"`
+	Path     string    `json:"path"`
+	Bytes    int64     `json:"bytes"` // on disk
+	Events   int       `json:"events"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Types    int       `json:"types"`
+	Actors   int       `json:"actors"`
+}
+
+// Segments lists the sealed segments, then the archived ones, each oldest
+// first.
+func Segments(townRoot string) ([]Segment, error) {
+	var out []Segment
+	for _, archived := range []bool{false, true} {
+		days, err := listDays(townRoot, archived)
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			path := segmentPath(townRoot, day, archived)
+			idx, err := loadIndex(indexPath(townRoot, day, archived), path, day, archived)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, Segment{
+				Day:      day,
+				Archived: archived,
+				Path:     path,
+				Bytes:    sizeOf(path),
+				Events:   idx.Count,
+				Start:    idx.Start,
+				End:      idx.End,
+				Types:    len(idx.Types),
+				Actors:   len(idx.Actors),
+			})
+		}
+	}
+	return out, nil
+}
+
+// Size returns the bytes on disk of the sealed segments and of the archive.
+func Size(townRoot string) (sealed, archived int64) {
+	for _, a := range []bool{false, true} {
+		days, _ := listDays(townRoot, a)
+		for _, day := range days {
+			n := sizeOf(segmentPath(townRoot, day, a))
+			if a {
+				archived += n
+			} else {
+				sealed += n
+			}
+		}
+	}
+	return sealed, archived
+}
@@ -0,0 +1,259 @@
+package eventstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Problem is one break in the audit chain.
+type Problem struct {
+	Seq    int64  `json:"seq,omitempty"`
+	Path   string `json:"path,omitempty"` // relative to the town root
+	Line   int    `json:"line,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// VerifyResult describes the audit chain.
+type VerifyResult struct {
+	Records     int       `json:"records"`
+	First       int64     `json:"first"`
+	Last        int64     `json:"last"`
+	Checkpoints int       `json:"checkpoints"`           // checkpoints verified
+	Bridged     []string  `json:"bridged,omitempty"`     // deleted days vouched for by their anchors
+	Unchained   int       `json:"unchained"`             // audit events written before chaining began
+	Keyless     bool      `json:"keyless,omitempty"`     // no audit key: signatures not checked
+	KeyInTown   bool      `json:"key_in_town,omitempty"` // audit key readable by agents
+	Problems    []Problem `json:"problems,omitempty"`
+}
+
+// OK reports whether the chain verified without problems.
+func (r *VerifyResult) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// record is one chained event as found on disk.
+type record struct {
+	seq      int64
+	prev     string
+	hash     string
+	computed string
+	ts       time.Time
+	path     string
+	line     int
+}
+
+// Verify walks the audit chain through the archive, sealed segments and the
+// active segment. It checks that sequence numbers run without gaps (or that
+// signed anchors vouch for deleted days), that every record hashes to its
+// claimed hash and links to the one before, and that the chain agrees with
+// every signed checkpoint. Problems are ordered by sequence number, so the
+// first names the first broken record.
+func Verify(townRoot string) (*VerifyResult, error) {
+	result := &VerifyResult{}
+	records, unchained, err := collectRecords(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	key, err := events.AuditKey(townRoot, false)
+	if os.IsNotExist(err) {
+		result.Keyless = true
+	} else if err != nil {
+		return nil, err
+	} else {
+		result.KeyInTown = events.AuditKeyInTown(townRoot)
+	}
+	anchors, err := LoadAnchors(townRoot)
+	if err != nil {
+		return nil, fmt.Errorf("loading anchors: %w", err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].seq < records[j].seq })
+	result.Records = len(records)
+	if len(records) > 0 {
+		result.First = records[0].seq
+		result.Last = records[len(records)-1].seq
+		// Audit events after chaining began must be in the chain
+		for _, u := range unchained {
+			if u.ts.Before(records[0].ts) {
+				result.Unchained++
+			} else {
+				result.Problems = append(result.Problems, Problem{Path: u.path, Line: u.line,
+					Reason: "audit event outside the chain (inserted)"})
+			}
+		}
+	} else {
+		result.Unchained += len(unchained)
+	}
+
+	bySeq := walkChain(records, anchors, key, result)
+	checkCheckpoints(townRoot, bySeq, anchors, key, result)
+
+	if head, err := events.ReadHead(townRoot); err == nil && head.Seq > result.Last {
+		result.Problems = append(result.Problems, Problem{Seq: result.Last + 1,
+			Reason: fmt.Sprintf("chain truncated: head is record %d but the last record is %d", head.Seq, result.Last)})
+	}
+
+	sort.SliceStable(result.Problems, func(i, j int) bool { return result.Problems[i].Seq < result.Problems[j].Seq })
+	return result, nil
+}
+
+// collectRecords reads every chained record, and the audit events without
+// chain fields, from all segments.
+func collectRecords(townRoot string) (records, unchained []record, err error) {
+	var files []string
+	var gzipped []bool
+	for _, archived := range []bool{true, false} {
+		days, err := listDays(townRoot, archived)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, day := range days {
+			files = append(files, segmentPath(townRoot, day, archived))
+			gzipped = append(gzipped, archived)
+		}
+	}
+	files = append(files, filepath.Join(townRoot, events.EventsFile))
+	gzipped = append(gzipped, false)
+
+	for i, path := range files {
+		rel, _ := filepath.Rel(townRoot, path)
+		n := 0
+		err := eachLine(path, gzipped[i], func(line []byte) bool {
+			n++
+			e, ts, ok := parseLine(line)
+			if !ok {
+				return true
+			}
+			if e.Seq == 0 {
+				if events.Chained(e.Visibility) {
+					unchained = append(unchained, record{ts: ts, path: rel, line: n})
+				}
+				return true
+			}
+			r := record{seq: e.Seq, prev: e.Prev, hash: e.Hash, ts: ts, path: rel, line: n}
+			if body, hash, ok := events.SplitHash(line); ok && hash == e.Hash {
+				r.computed = events.HashRecord(body)
+			}
+			records = append(records, r)
+			return true
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("reading %s: %w", rel, err)
+		}
+	}
+	return records, unchained, nil
+}
+
+// walkChain checks sequence, hashes and links, and returns the records by
+// sequence number.
+func walkChain(records []record, anchors map[string]*Anchor, key []byte, result *VerifyResult) map[int64]*record {
+	bySeq := make(map[int64]*record, len(records))
+	expected := int64(1)
+	prevHash := ""
+	for i := range records {
+		r := &records[i]
+		problem := func(reason string, args ...interface{}) {
+			result.Problems = append(result.Problems, Problem{Seq: r.seq, Path: r.path, Line: r.line,
+				Reason: fmt.Sprintf(reason, args...)})
+		}
+		if r.seq < expected {
+			problem("duplicate record %d", r.seq)
+			continue
+		}
+		bySeq[r.seq] = r
+
+		if r.seq > expected {
+			if days, ok := bridge(anchors, key, expected, r.seq-1, prevHash, r.prev); ok {
+				result.Bridged = append(result.Bridged, days...)
+			} else {
+				result.Problems = append(result.Problems, Problem{Seq: expected, Path: r.path, Line: r.line,
+					Reason: missingReason(expected, r.seq-1)})
+			}
+		} else if r.prev != prevHash {
+			problem("does not link to record %d (one of them was rewritten)", r.seq-1)
+		}
+		if r.computed != r.hash {
+			problem("record edited: contents do not match its hash")
+		}
+		prevHash = r.hash
+		expected = r.seq + 1
+	}
+	return bySeq
+}
+
+func missingReason(from, to int64) string {
+	if from == to {
+		return fmt.Sprintf("record %d missing", from)
+	}
+	return fmt.Sprintf("records %d-%d missing", from, to)
+}
+
+// bridge reports whether signed anchors of whole deleted days cover records
+// from..to, continuing from prevHash and ending on the hash nextPrev names.
+func bridge(anchors map[string]*Anchor, key []byte, from, to int64, prevHash, nextPrev string) ([]string, bool) {
+	if key == nil {
+		return nil, false
+	}
+	byFirst := make(map[int64]*Anchor, len(anchors))
+	for _, a := range anchors {
+		byFirst[a.First] = a
+	}
+	var days []string
+	for seq := from; ; {
+		a := byFirst[seq]
+		if a == nil || !a.Verify(key) || a.Prev != prevHash || int64(a.Count) != a.Last-a.First+1 || a.Last > to {
+			return nil, false
+		}
+		days = append(days, a.Day)
+		if a.Last == to {
+			return days, a.Hash == nextPrev
+		}
+		seq, prevHash = a.Last+1, a.Hash
+	}
+}
+
+// checkCheckpoints verifies checkpoint signatures and that the chain still
+// holds the hash each one signed.
+func checkCheckpoints(townRoot string, bySeq map[int64]*record, anchors map[string]*Anchor, key []byte, result *VerifyResult) {
+	checkpoints, err := events.LoadCheckpoints(townRoot)
+	if err != nil {
+		result.Problems = append(result.Problems, Problem{Reason: fmt.Sprintf("reading checkpoints: %v", err)})
+		return
+	}
+	if key == nil {
+		return
+	}
+	rel, _ := filepath.Rel(townRoot, events.CheckpointsPath(townRoot))
+	anchoredHash := make(map[int64]string)
+	for _, a := range anchors {
+		anchoredHash[a.Last] = a.Hash
+	}
+	for i, cp := range checkpoints {
+		problem := func(reason string, args ...interface{}) {
+			result.Problems = append(result.Problems, Problem{Seq: cp.Seq, Path: rel, Line: i + 1,
+				Reason: fmt.Sprintf(reason, args...)})
+		}
+		if !cp.Verify(key) {
+			problem("checkpoint signature invalid")
+			continue
+		}
+		if r := bySeq[cp.Seq]; r != nil {
+			if r.hash != cp.Hash {
+				problem("record differs from the checkpoint signed at %s (chain rewritten)", cp.Timestamp)
+				continue
+			}
+		} else if cp.Seq > result.Last {
+			problem("chain truncated: checkpoint at record %d but the last record is %d", cp.Seq, result.Last)
+			continue
+		} else if h, ok := anchoredHash[cp.Seq]; ok && h != cp.Hash {
+			problem("anchor differs from the checkpoint signed at %s", cp.Timestamp)
+			continue
+		}
+		result.Checkpoints++
+	}
+}
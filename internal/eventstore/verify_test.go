@@ -0,0 +1,182 @@
+package eventstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// writeChain appends n audit records to a fresh town and returns its root.
+func writeChain(t *testing.T, n int) string {
+	t.Helper()
+	townRoot := t.TempDir()
+	for i := 1; i <= n; i++ {
+		err := events.Append(townRoot, events.Event{
+			Source:     "gt",
+			Type:       "kill",
+			Actor:      "mayor",
+			Payload:    map[string]interface{}{"n": i},
+			Visibility: events.VisibilityAudit,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return townRoot
+}
+
+// editActive rewrites line n (1-based) of the active segment.
+func editActive(t *testing.T, townRoot string, n int, edit func(line []byte) []byte) {
+	t.Helper()
+	path := filepath.Join(townRoot, events.EventsFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	lines[n-1] = edit(lines[n-1])
+	if err := os.WriteFile(path, bytes.Join(lines, nil), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func firstProblem(t *testing.T, townRoot string) *Problem {
+	t.Helper()
+	res, err := Verify(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.OK() {
+		return nil
+	}
+	return &res.Problems[0]
+}
+
+func TestVerifyIntactChain(t *testing.T) {
+	townRoot := writeChain(t, events.CheckpointEvery+3)
+	res, err := Verify(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.OK() || res.Records != events.CheckpointEvery+3 || res.Checkpoints != 1 || res.Keyless {
+		t.Fatalf("Verify = %+v", res)
+	}
+}
+
+func TestVerifyNamesFirstBrokenRecord(t *testing.T) {
+	t.Run("edited", func(t *testing.T) {
+		townRoot := writeChain(t, 5)
+		editActive(t, townRoot, 3, func(line []byte) []byte {
+			return bytes.Replace(line, []byte(`"n":3`), []byte(`"n":9`), 1)
+		})
+		if p := firstProblem(t, townRoot); p == nil || p.Seq != 3 || p.Line != 3 || !strings.Contains(p.Reason, "edited") {
+			t.Errorf("problem = %+v", p)
+		}
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		townRoot := writeChain(t, 5)
+		editActive(t, townRoot, 2, func([]byte) []byte { return nil })
+		if p := firstProblem(t, townRoot); p == nil || p.Seq != 2 || p.Reason != "record 2 missing" {
+			t.Errorf("problem = %+v", p)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		townRoot := writeChain(t, 5)
+		editActive(t, townRoot, 5, func([]byte) []byte { return nil })
+		if p := firstProblem(t, townRoot); p == nil || !strings.Contains(p.Reason, "truncated") {
+			t.Errorf("problem = %+v", p)
+		}
+	})
+
+	t.Run("rewritten past checkpoint", func(t *testing.T) {
+		// Editing a record and re-hashing the rest of the chain leaves every
+		// link consistent, but the signed checkpoint no longer matches.
+		townRoot := writeChain(t, events.CheckpointEvery)
+		path := filepath.Join(townRoot, events.EventsFile)
+		data, _ := os.ReadFile(path)
+		var out bytes.Buffer
+		prev := ""
+		for i, line := range bytes.SplitAfter(bytes.TrimSpace(data), []byte("\n")) {
+			e, _, _ := parseLine(line)
+			if i == 9 {
+				e.Actor = "someone-else"
+			}
+			e.Prev, e.Hash = prev, ""
+			body := mustJSON(t, e)
+			e.Hash = events.HashRecord(body)
+			out.Write(mustJSON(t, e))
+			out.WriteByte('\n')
+			prev = e.Hash
+		}
+		_ = os.WriteFile(path, out.Bytes(), 0644)
+		_ = os.WriteFile(events.HeadPath(townRoot), []byte(fmt.Sprintf(`{"seq":%d,"hash":%q}`, events.CheckpointEvery, prev)), 0644)
+
+		if p := firstProblem(t, townRoot); p == nil || p.Seq != events.CheckpointEvery || !strings.Contains(p.Reason, "checkpoint") {
+			t.Errorf("problem = %+v", p)
+		}
+	})
+}
+
+func TestVerifyBridgesDeletedDaysWithAnchors(t *testing.T) {
+	townRoot := writeChain(t, 4)
+	// Backdate the first two records to a sealed day, re-hashing them as the
+	// writer would have
+	old := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	prev := ""
+	for n := 1; n <= 4; n++ {
+		editActive(t, townRoot, n, func(line []byte) []byte {
+			e, _, _ := parseLine(line)
+			if n <= 2 {
+				e.Timestamp = old
+			}
+			e.Prev, e.Hash = prev, ""
+			e.Hash = events.HashRecord(mustJSON(t, e))
+			prev = e.Hash
+			return append(mustJSON(t, e), '\n')
+		})
+	}
+	_ = os.WriteFile(events.HeadPath(townRoot), []byte(fmt.Sprintf(`{"seq":4,"hash":%q}`, prev)), 0644)
+
+	if _, err := Seal(townRoot, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	anchors, _ := LoadAnchors(townRoot)
+	if a := anchors["2026-03-01"]; a == nil || a.First != 1 || a.Last != 2 {
+		t.Fatalf("anchors = %+v", anchors)
+	}
+
+	// Deleting the day is vouched for by its anchor
+	_ = os.Remove(segmentPath(townRoot, "2026-03-01", false))
+	res, err := Verify(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.OK() || len(res.Bridged) != 1 || res.Records != 2 {
+		t.Fatalf("Verify = %+v", res)
+	}
+
+	// A forged anchor is not
+	anchors["2026-03-01"].Hash = strings.Repeat("0", 64)
+	_ = os.WriteFile(AnchorsPath(townRoot), mustJSON(t, anchors), 0644)
+	if p := firstProblem(t, townRoot); p == nil || p.Reason != "records 1-2 missing" {
+		t.Errorf("problem = %+v", p)
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -387,6 +387,25 @@ func (c *Curator) generateSummary(event *events.Event) string {
 		}
 		return "Multiple sessions died simultaneously"
 
+	case events.TypeResourceAlert:
+		if cpu, ok := event.Payload["cpu_percent"].(float64); ok {
+			return fmt.Sprintf("%s spinning at %.0f%% CPU", event.Actor, cpu)
+		}
+		return fmt.Sprintf("%s using excessive CPU", event.Actor)
+
+	case events.TypeMailReceipt:
+		to, _ := event.Payload["to"].(string)
+		subject, _ := event.Payload["subject"].(string)
+		switch event.Payload["status"] {
+		case "acked":
+			return fmt.Sprintf("%s acknowledged: %s", to, subject)
+		case "redelivered":
+			return fmt.Sprintf("No ack from %s, re-delivered: %s", to, subject)
+		case "escalated":
+			return fmt.Sprintf("No ack from %s, escalated: %s", to, subject)
+		}
+		return fmt.Sprintf("Mail receipt from %s: %s", to, subject)
+
 	default:
 		return fmt.Sprintf("%s: %s", event.Actor, event.Type)
 	}
@@ -185,6 +185,14 @@ func TestCurator_GeneratesSummary(t *testing.T) {
 			},
 			expected: "gastown/witness handed off to fresh session",
 		},
+		{
+			event: &events.Event{
+				Type:    events.TypeMailReceipt,
+				Actor:   "gastown/refinery",
+				Payload: events.MailReceiptPayload("hq-abc", "mayor/", "gastown/refinery", "Freeze merges", "redelivered"),
+			},
+			expected: "No ack from gastown/refinery, re-delivered: Freeze merges",
+		},
 	}
 
 	for _, tc := range tests {
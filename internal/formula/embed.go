@@ -332,3 +332,13 @@ func UpdateFormulas(beadsPath string) (updated, skipped, reinstalled int, err er
 
 	return updated, skipped, reinstalled, nil
 }
+
+// Embedded returns the content of a built-in formula by name
+// (e.g., "shiny" for formulas/shiny.formula.toml).
+func Embedded(name string) ([]byte, error) {
+	data, err := formulasFS.ReadFile("formulas/" + name + ".formula.toml")
+	if err != nil {
+		return nil, fmt.Errorf("no built-in formula %q", name)
+	}
+	return data, nil
+}
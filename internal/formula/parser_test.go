@@ -135,6 +135,34 @@ needs = ["{target}.draft"]
 	}
 }
 
+func TestParse_Capabilities(t *testing.T) {
+	data := []byte(`
+formula = "code-review"
+
+[capabilities]
+primary = ["go", "code-review"]
+secondary = ["security"]
+
+[[steps]]
+id = "review"
+title = "Review"
+`)
+
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	tags := f.Capabilities.Tags()
+	if len(tags) != 3 || tags[0] != "go" || tags[2] != "security" {
+		t.Errorf("Capabilities.Tags() = %v, want primary then secondary", tags)
+	}
+
+	var none *Capabilities
+	if tags := none.Tags(); tags != nil {
+		t.Errorf("nil Capabilities.Tags() = %v, want nil", tags)
+	}
+}
+
 func TestValidate_MissingName(t *testing.T) {
 	data := []byte(`
 type = "workflow"
@@ -0,0 +1,553 @@
+package formula
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultEstimate is the duration assumed for a step without an estimate.
+const DefaultEstimate = 30 * time.Minute
+
+// SynthesisID is the step ID a convoy's synthesis is simulated under.
+const SynthesisID = "synthesis"
+
+// SimulateOptions configures a dry-run simulation.
+type SimulateOptions struct {
+	// Vars are variable values, as passed with --var. They override defaults.
+	Vars map[string]string
+
+	// Estimates override per-step duration estimates by step ID.
+	Estimates map[string]time.Duration
+
+	// DefaultEstimate applies to steps with no estimate (default: DefaultEstimate).
+	DefaultEstimate time.Duration
+}
+
+// SimStep is one step (or leg, template, aspect) as the simulator sees it.
+type SimStep struct {
+	ID       string        `json:"id"`
+	Title    string        `json:"title"`
+	Needs    []string      `json:"needs,omitempty"`
+	Parallel bool          `json:"parallel,omitempty"`
+	Estimate time.Duration `json:"estimate"`
+	// Wave is the 1-based wave the step is dispatched in; 0 if it never is.
+	Wave int `json:"wave"`
+	// Start and Finish are offsets from the start of the run, assuming each
+	// wave is dispatched when the previous one completes.
+	Start    time.Duration `json:"start"`
+	Finish   time.Duration `json:"finish"`
+	Critical bool          `json:"critical,omitempty"`
+}
+
+// SimWave is a set of steps dispatched together.
+type SimWave struct {
+	Steps    []string      `json:"steps"`
+	Parallel bool          `json:"parallel"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Simulation is the result of dry-running a formula.
+type Simulation struct {
+	Formula string            `json:"formula"`
+	Type    FormulaType       `json:"type"`
+	Vars    map[string]string `json:"vars,omitempty"`
+	Steps   []SimStep         `json:"steps"`
+	Waves   []SimWave         `json:"waves"`
+
+	// CriticalPath is the longest chain of dependent steps by estimate, and
+	// CriticalPathLength its total: the run time with unlimited agents.
+	CriticalPath       []string      `json:"critical_path"`
+	CriticalPathLength time.Duration `json:"critical_path_length"`
+
+	// Makespan is the run time when waves are dispatched one after another,
+	// as ParallelReadySteps hands them out.
+	Makespan time.Duration `json:"makespan"`
+
+	// TotalWork is the sum of all estimates (agent time).
+	TotalWork time.Duration `json:"total_work"`
+
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Simulate dry-runs the formula: it expands variables, groups steps into the
+// waves they would be dispatched in, computes the critical path from
+// per-step estimates, and reports likely design mistakes as warnings.
+// Nothing is created or dispatched.
+func (f *Formula) Simulate(opts SimulateOptions) *Simulation {
+	if opts.DefaultEstimate <= 0 {
+		opts.DefaultEstimate = DefaultEstimate
+	}
+	sim := &Simulation{Formula: f.Name, Type: f.Type}
+
+	sim.Vars = f.resolveVars(opts.Vars, sim)
+	sim.Steps = f.simSteps(sim.Vars)
+	sim.applyEstimates(f, opts)
+	sim.schedule(f)
+	sim.criticalPath()
+	f.checkStructure(sim)
+
+	return sim
+}
+
+// resolveVars merges defaults with supplied values and warns about unknown,
+// missing, unused and undeclared variables.
+func (f *Formula) resolveVars(supplied map[string]string, sim *Simulation) map[string]string {
+	values := make(map[string]string)
+	for name, v := range f.Vars {
+		if v.Default != "" {
+			values[name] = v.Default
+		}
+	}
+	for name, in := range f.Inputs {
+		if in.Default != "" {
+			values[name] = in.Default
+		}
+	}
+
+	for _, name := range sortedKeys(supplied) {
+		_, isVar := f.Vars[name]
+		_, isInput := f.Inputs[name]
+		if !isVar && !isInput {
+			sim.warn("--var %s is not declared by the formula", name)
+		}
+		values[name] = supplied[name]
+	}
+
+	for _, name := range sortedKeys(f.Vars) {
+		if f.Vars[name].Required && values[name] == "" {
+			sim.warn("required variable %q has no value (use --var %s=...)", name, name)
+		}
+	}
+	for _, name := range sortedKeys(f.Inputs) {
+		in := f.Inputs[name]
+		if !in.Required || values[name] != "" {
+			continue
+		}
+		satisfied := false
+		for _, alt := range in.RequiredUnless {
+			if values[alt] != "" {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			sim.warn("required input %q has no value (use --var %s=...)", name, name)
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, name := range ExtractTemplateVariables(f.allText()) {
+		used[name] = true
+	}
+	for _, name := range sortedKeys(f.Vars) {
+		if !used[name] {
+			sim.warn("variable %q is declared but never used", name)
+		}
+	}
+	for _, name := range f.GetUndefinedVariables() {
+		sim.warn("{{%s}} is used but not declared in [vars]", name)
+	}
+
+	return values
+}
+
+// simSteps flattens the formula's steps, legs, templates or aspects into
+// simulator nodes with variables expanded in their titles.
+func (f *Formula) simSteps(vars map[string]string) []SimStep {
+	var steps []SimStep
+	switch f.Type {
+	case TypeWorkflow:
+		for _, s := range f.Steps {
+			steps = append(steps, SimStep{ID: s.ID, Title: expandVars(s.Title, vars), Needs: s.Needs, Parallel: s.Parallel})
+		}
+	case TypeExpansion:
+		for _, t := range f.Template {
+			steps = append(steps, SimStep{ID: t.ID, Title: expandVars(t.Title, vars), Needs: t.Needs})
+		}
+	case TypeConvoy:
+		for _, l := range f.Legs {
+			steps = append(steps, SimStep{ID: l.ID, Title: expandVars(l.Title, vars), Parallel: true})
+		}
+		if f.Synthesis != nil {
+			needs := f.Synthesis.DependsOn
+			if len(needs) == 0 {
+				for _, l := range f.Legs {
+					needs = append(needs, l.ID)
+				}
+			}
+			steps = append(steps, SimStep{ID: SynthesisID, Title: expandVars(f.Synthesis.Title, vars), Needs: needs})
+		}
+	case TypeAspect:
+		for _, a := range f.Aspects {
+			steps = append(steps, SimStep{ID: a.ID, Title: expandVars(a.Title, vars), Parallel: true})
+		}
+	}
+	return steps
+}
+
+// estimateFor returns the estimate declared in the formula for a step ID.
+func (f *Formula) estimateFor(id string) string {
+	switch f.Type {
+	case TypeWorkflow:
+		if s := f.GetStep(id); s != nil {
+			return s.Estimate
+		}
+	case TypeExpansion:
+		if t := f.GetTemplate(id); t != nil {
+			return t.Estimate
+		}
+	case TypeConvoy:
+		if id == SynthesisID && f.Synthesis != nil {
+			return f.Synthesis.Estimate
+		}
+		if l := f.GetLeg(id); l != nil {
+			return l.Estimate
+		}
+	case TypeAspect:
+		if a := f.GetAspect(id); a != nil {
+			return a.Estimate
+		}
+	}
+	return ""
+}
+
+func (sim *Simulation) applyEstimates(f *Formula, opts SimulateOptions) {
+	known := make(map[string]bool)
+	for i := range sim.Steps {
+		s := &sim.Steps[i]
+		known[s.ID] = true
+		s.Estimate = opts.DefaultEstimate
+		if raw := f.estimateFor(s.ID); raw != "" {
+			if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+				sim.warn("step %q has invalid estimate %q; using %s", s.ID, raw, FormatEstimate(opts.DefaultEstimate))
+			} else {
+				s.Estimate = d
+			}
+		}
+		if d, ok := opts.Estimates[s.ID]; ok {
+			s.Estimate = d
+		}
+		sim.TotalWork += s.Estimate
+	}
+	for _, id := range sortedKeys(opts.Estimates) {
+		if !known[id] {
+			sim.warn("--estimate %s does not match any step", id)
+		}
+	}
+}
+
+// schedule groups steps into dispatch waves. Workflows follow
+// ParallelReadySteps exactly: all ready parallel steps together, otherwise
+// one sequential step at a time. Other types dispatch everything ready.
+func (sim *Simulation) schedule(f *Formula) {
+	index := make(map[string]*SimStep, len(sim.Steps))
+	for i := range sim.Steps {
+		index[sim.Steps[i].ID] = &sim.Steps[i]
+	}
+
+	completed := make(map[string]bool)
+	var clock time.Duration
+	serialized := make(map[string]bool)
+	for {
+		var wave []string
+		parallel := true
+		if f.Type == TypeWorkflow {
+			var seq string
+			wave, seq = f.ParallelReadySteps(completed)
+			if len(wave) == 0 && seq != "" {
+				wave, parallel = []string{seq}, false
+				// Independent sequential steps that were ready together
+				// could have run side by side.
+				for _, id := range f.ReadySteps(completed) {
+					if id != seq && !index[id].Parallel {
+						serialized[seq], serialized[id] = true, true
+					}
+				}
+			}
+		} else {
+			wave = readySimSteps(sim.Steps, completed)
+		}
+		if len(wave) == 0 {
+			break
+		}
+
+		w := SimWave{Steps: wave, Parallel: parallel && len(wave) > 1}
+		for _, id := range wave {
+			s := index[id]
+			s.Wave = len(sim.Waves) + 1
+			s.Start = clock
+			s.Finish = clock + s.Estimate
+			if s.Estimate > w.Duration {
+				w.Duration = s.Estimate
+			}
+			completed[id] = true
+		}
+		clock += w.Duration
+		sim.Waves = append(sim.Waves, w)
+	}
+	sim.Makespan = clock
+
+	var unreachable []string
+	for _, s := range sim.Steps {
+		if s.Wave == 0 {
+			unreachable = append(unreachable, s.ID)
+		}
+	}
+	if len(unreachable) > 0 {
+		sim.warn("unreachable steps (their needs never complete, e.g. a cycle): %s", strings.Join(unreachable, ", "))
+	}
+	if ids := sortedKeys(serialized); len(ids) > 0 {
+		sim.warn("independent steps run one at a time: %s (mark them parallel = true to run them together)", strings.Join(ids, ", "))
+	}
+	if f.Type == TypeWorkflow {
+		for _, s := range sim.Steps {
+			if s.Parallel && s.Wave > 0 && len(sim.Waves[s.Wave-1].Steps) == 1 {
+				sim.warn("step %q is marked parallel but never runs alongside another step", s.ID)
+			}
+		}
+	}
+}
+
+// readySimSteps returns steps whose needs are all complete, in declaration order.
+func readySimSteps(steps []SimStep, completed map[string]bool) []string {
+	var ready []string
+	for _, s := range steps {
+		if completed[s.ID] {
+			continue
+		}
+		met := true
+		for _, need := range s.Needs {
+			if !completed[need] {
+				met = false
+				break
+			}
+		}
+		if met {
+			ready = append(ready, s.ID)
+		}
+	}
+	return ready
+}
+
+// criticalPath finds the longest estimated chain through the dependency
+// graph, ignoring unreachable steps.
+func (sim *Simulation) criticalPath() {
+	index := make(map[string]*SimStep, len(sim.Steps))
+	for i := range sim.Steps {
+		index[sim.Steps[i].ID] = &sim.Steps[i]
+	}
+
+	finish := make(map[string]time.Duration)
+	prev := make(map[string]string)
+	// Waves are a topological order of the reachable steps.
+	for _, w := range sim.Waves {
+		for _, id := range w.Steps {
+			var start time.Duration
+			for _, need := range index[id].Needs {
+				if finish[need] > start {
+					start, prev[id] = finish[need], need
+				}
+			}
+			finish[id] = start + index[id].Estimate
+		}
+	}
+
+	var last string
+	for _, w := range sim.Waves {
+		for _, id := range w.Steps {
+			if last == "" || finish[id] > finish[last] {
+				last = id
+			}
+		}
+	}
+	if last == "" {
+		return
+	}
+	sim.CriticalPathLength = finish[last]
+	for id := last; id != ""; id = prev[id] {
+		sim.CriticalPath = append([]string{id}, sim.CriticalPath...)
+		index[id].Critical = true
+	}
+}
+
+// checkStructure warns about shape problems: dangling end steps and convoy
+// legs that are never synthesized.
+func (f *Formula) checkStructure(sim *Simulation) {
+	switch f.Type {
+	case TypeWorkflow, TypeExpansion:
+		needed := make(map[string]bool)
+		for _, s := range sim.Steps {
+			for _, need := range s.Needs {
+				needed[need] = true
+			}
+		}
+		var ends []string
+		for _, s := range sim.Steps {
+			if !needed[s.ID] {
+				ends = append(ends, s.ID)
+			}
+		}
+		if len(ends) > 1 {
+			sim.warn("%d end steps (%s) are not joined by a final step; nothing waits for all of them", len(ends), strings.Join(ends, ", "))
+		}
+
+	case TypeConvoy:
+		if f.Synthesis == nil {
+			if len(f.Legs) > 1 {
+				sim.warn("convoy has %d legs but no [synthesis]; leg outputs are never combined", len(f.Legs))
+			}
+			break
+		}
+		if len(f.Synthesis.DependsOn) > 0 {
+			depends := make(map[string]bool)
+			for _, dep := range f.Synthesis.DependsOn {
+				if depends[dep] {
+					sim.warn("synthesis depends_on lists leg %q twice", dep)
+				}
+				depends[dep] = true
+			}
+			var missing []string
+			for _, l := range f.Legs {
+				if !depends[l.ID] {
+					missing = append(missing, l.ID)
+				}
+			}
+			if len(missing) > 0 {
+				sim.warn("synthesis does not depend on legs %s; their findings are never synthesized", strings.Join(missing, ", "))
+			}
+		}
+		if f.Output != nil && len(f.Legs) > 1 && f.Output.LegPattern != "" && !strings.Contains(f.Output.LegPattern, ".leg.id") {
+			sim.warn("output.leg_pattern %q does not include {{.leg.id}}; legs would overwrite each other's findings", f.Output.LegPattern)
+		}
+	}
+}
+
+// allText returns every piece of formula text that may reference variables.
+func (f *Formula) allText() string {
+	var b strings.Builder
+	b.WriteString(f.Description)
+	for _, s := range f.Steps {
+		b.WriteString("\n" + s.Title + "\n" + s.Description)
+	}
+	for _, l := range f.Legs {
+		b.WriteString("\n" + l.Title + "\n" + l.Description + "\n" + l.Focus)
+	}
+	if f.Synthesis != nil {
+		b.WriteString("\n" + f.Synthesis.Title + "\n" + f.Synthesis.Description)
+	}
+	for _, t := range f.Template {
+		b.WriteString("\n" + t.Title + "\n" + t.Description)
+	}
+	for _, a := range f.Aspects {
+		b.WriteString("\n" + a.Title + "\n" + a.Description + "\n" + a.Focus)
+	}
+	return b.String()
+}
+
+// expandVars substitutes {{name}} placeholders that have a value.
+func expandVars(text string, vars map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(m string) string {
+		if v, ok := vars[m[2:len(m)-2]]; ok && v != "" {
+			return v
+		}
+		return m
+	})
+}
+
+func (sim *Simulation) warn(format string, args ...interface{}) {
+	sim.Warnings = append(sim.Warnings, fmt.Sprintf(format, args...))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FormatEstimate renders a duration compactly ("1h30m", "45m", "90s").
+func FormatEstimate(d time.Duration) string {
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// mermaidIDPattern matches characters Mermaid does not accept in node IDs.
+var mermaidIDPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Mermaid renders the simulated DAG as a Mermaid flowchart. Critical path
+// steps are highlighted.
+func (sim *Simulation) Mermaid() string {
+	id := func(s string) string { return mermaidIDPattern.ReplaceAllString(s, "_") }
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for _, s := range sim.Steps {
+		label := strings.ReplaceAll(s.Title, `"`, "#quot;")
+		if label == "" {
+			label = s.ID
+		}
+		fmt.Fprintf(&b, "    %s[\"%s<br/>%s · wave %d\"]\n", id(s.ID), label, FormatEstimate(s.Estimate), s.Wave)
+	}
+	for _, s := range sim.Steps {
+		for _, need := range s.Needs {
+			fmt.Fprintf(&b, "    %s --> %s\n", id(need), id(s.ID))
+		}
+	}
+	if len(sim.CriticalPath) > 0 {
+		ids := make([]string, len(sim.CriticalPath))
+		for i, c := range sim.CriticalPath {
+			ids[i] = id(c)
+		}
+		b.WriteString("    classDef critical stroke:#d33,stroke-width:3px\n")
+		fmt.Fprintf(&b, "    class %s critical\n", strings.Join(ids, ","))
+	}
+	return b.String()
+}
+
+// DOT renders the simulated DAG in Graphviz DOT format. Critical path
+// steps and edges are drawn in red.
+func (sim *Simulation) DOT() string {
+	critical := make(map[string]bool)
+	for _, id := range sim.CriticalPath {
+		critical[id] = true
+	}
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace
+	quote := func(s string) string { return `"` + escape(s) + `"` }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", quote(sim.Formula))
+	b.WriteString("  rankdir=TB;\n  node [shape=box];\n")
+	for _, s := range sim.Steps {
+		label := s.Title
+		if label == "" {
+			label = s.ID
+		}
+		attrs := fmt.Sprintf("label=%s", quote(fmt.Sprintf("%s\n%s · wave %d", label, FormatEstimate(s.Estimate), s.Wave)))
+		if critical[s.ID] {
+			attrs += ", color=red, penwidth=2"
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", quote(s.ID), attrs)
+	}
+	for _, s := range sim.Steps {
+		for _, need := range s.Needs {
+			attrs := ""
+			if critical[s.ID] && critical[need] {
+				attrs = " [color=red, penwidth=2]"
+			}
+			fmt.Fprintf(&b, "  %s -> %s%s;\n", quote(need), quote(s.ID), attrs)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
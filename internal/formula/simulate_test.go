@@ -0,0 +1,218 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimulateWorkflow(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "feature"
+type = "workflow"
+
+[vars.feature]
+required = true
+[vars.owner]
+description = "never referenced"
+
+[[steps]]
+id = "design"
+title = "Design {{feature}}"
+estimate = "1h"
+
+[[steps]]
+id = "backend"
+title = "Backend"
+needs = ["design"]
+parallel = true
+estimate = "2h"
+
+[[steps]]
+id = "frontend"
+title = "Frontend"
+needs = ["design"]
+parallel = true
+
+[[steps]]
+id = "submit"
+title = "Submit"
+needs = ["backend", "frontend"]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	sim := f.Simulate(SimulateOptions{
+		Vars:      map[string]string{"feature": "login", "colour": "red"},
+		Estimates: map[string]time.Duration{"submit": 10 * time.Minute},
+	})
+
+	if sim.Steps[0].Title != "Design login" {
+		t.Errorf("title = %q, want vars expanded", sim.Steps[0].Title)
+	}
+	if len(sim.Waves) != 3 || !sim.Waves[1].Parallel || len(sim.Waves[1].Steps) != 2 {
+		t.Fatalf("waves = %+v", sim.Waves)
+	}
+	if got := strings.Join(sim.CriticalPath, ","); got != "design,backend,submit" {
+		t.Errorf("critical path = %s", got)
+	}
+	if sim.CriticalPathLength != 3*time.Hour+10*time.Minute || sim.Makespan != sim.CriticalPathLength {
+		t.Errorf("critical = %v, makespan = %v", sim.CriticalPathLength, sim.Makespan)
+	}
+	if sim.TotalWork != 3*time.Hour+40*time.Minute {
+		t.Errorf("total work = %v", sim.TotalWork)
+	}
+
+	warnings := strings.Join(sim.Warnings, "\n")
+	for _, want := range []string{"--var colour", `"owner" is declared but never used`} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings missing %q:\n%s", want, warnings)
+		}
+	}
+	if strings.Contains(warnings, "required variable") {
+		t.Errorf("unexpected required-variable warning:\n%s", warnings)
+	}
+}
+
+func TestSimulateSerializedSteps(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "serial"
+
+[[steps]]
+id = "a"
+[[steps]]
+id = "b"
+[[steps]]
+id = "c"
+needs = ["a", "b"]
+parallel = true
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	sim := f.Simulate(SimulateOptions{})
+	if len(sim.Waves) != 3 || sim.Makespan != 3*DefaultEstimate || sim.CriticalPathLength != 2*DefaultEstimate {
+		t.Errorf("waves = %+v, makespan = %v, critical = %v", sim.Waves, sim.Makespan, sim.CriticalPathLength)
+	}
+	warnings := strings.Join(sim.Warnings, "\n")
+	for _, want := range []string{"independent steps run one at a time: a, b", `"c" is marked parallel`} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings missing %q:\n%s", want, warnings)
+		}
+	}
+}
+
+func TestSimulateConvoyWarnings(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "review"
+type = "convoy"
+
+[inputs.pr]
+required = true
+
+[output]
+directory = ".reviews"
+leg_pattern = "findings.md"
+
+[[legs]]
+id = "security"
+estimate = "45m"
+[[legs]]
+id = "style"
+estimate = "soon"
+
+[synthesis]
+title = "Combine"
+depends_on = ["security"]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	sim := f.Simulate(SimulateOptions{DefaultEstimate: 20 * time.Minute})
+
+	if len(sim.Waves) != 2 || sim.Waves[1].Steps[0] != SynthesisID {
+		t.Errorf("waves = %+v", sim.Waves)
+	}
+	if sim.Steps[1].Estimate != 20*time.Minute {
+		t.Errorf("invalid estimate not defaulted: %v", sim.Steps[1].Estimate)
+	}
+	warnings := strings.Join(sim.Warnings, "\n")
+	for _, want := range []string{
+		`required input "pr"`,
+		`invalid estimate "soon"`,
+		"does not depend on legs style",
+		"overwrite each other's findings",
+	} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings missing %q:\n%s", want, warnings)
+		}
+	}
+}
+
+func TestSimulateUnreachable(t *testing.T) {
+	// Expansion formulas are not cycle-checked on parse.
+	f, err := Parse([]byte(`
+formula = "loop"
+type = "expansion"
+
+[[template]]
+id = "start"
+[[template]]
+id = "x"
+needs = ["y"]
+[[template]]
+id = "y"
+needs = ["x"]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	sim := f.Simulate(SimulateOptions{})
+	if !strings.Contains(strings.Join(sim.Warnings, "\n"), "unreachable steps (their needs never complete, e.g. a cycle): x, y") {
+		t.Errorf("warnings = %q", sim.Warnings)
+	}
+}
+
+func TestSimulateExport(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "tiny"
+
+[[steps]]
+id = "step.one"
+title = "Say \"hi\""
+[[steps]]
+id = "two"
+needs = ["step.one"]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	sim := f.Simulate(SimulateOptions{})
+
+	mermaid := sim.Mermaid()
+	for _, want := range []string{"flowchart TD", `step_one["Say #quot;hi#quot;<br/>30m · wave 1"]`, "step_one --> two", "class step_one,two critical"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid missing %q:\n%s", want, mermaid)
+		}
+	}
+	dot := sim.DOT()
+	for _, want := range []string{`digraph "tiny"`, `"step.one" -> "two" [color=red, penwidth=2];`, `label="Say \"hi\"`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT missing %q:\n%s", want, dot)
+		}
+	}
+}
+
+func TestFormatEstimate(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		90 * time.Minute: "1h30m",
+		2 * time.Hour:    "2h",
+		45 * time.Minute: "45m",
+		90 * time.Second: "1m30s",
+	} {
+		if got := FormatEstimate(d); got != want {
+			t.Errorf("FormatEstimate(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
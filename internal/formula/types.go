@@ -46,6 +46,25 @@ type Formula struct {
 
 	// Aspect-specific (similar to convoy but for analysis)
 	Aspects []Aspect `toml:"aspects"`
+
+	// Capabilities the formula exercises, used to route its work
+	Capabilities *Capabilities `toml:"capabilities"`
+}
+
+// Capabilities declares what a formula's work involves ("go", "testing",
+// "code-review"). gt sling --auto prefers agents with a track record on
+// beads labeled with them.
+type Capabilities struct {
+	Primary   []string `toml:"primary"`
+	Secondary []string `toml:"secondary"`
+}
+
+// Tags returns the primary then secondary capabilities.
+func (c *Capabilities) Tags() []string {
+	if c == nil {
+		return nil
+	}
+	return append(append([]string{}, c.Primary...), c.Secondary...)
 }
 
 // Aspect represents a parallel analysis aspect in an aspect formula.
@@ -54,6 +73,7 @@ type Aspect struct {
 	Title       string `toml:"title"`
 	Focus       string `toml:"focus"`
 	Description string `toml:"description"`
+	Estimate    string `toml:"estimate"` // Expected duration (e.g., "30m"), used by Simulate
 }
 
 // Input represents an input parameter for a formula.
@@ -78,6 +98,7 @@ type Leg struct {
 	Title       string `toml:"title"`
 	Focus       string `toml:"focus"`
 	Description string `toml:"description"`
+	Estimate    string `toml:"estimate"` // Expected duration (e.g., "30m"), used by Simulate
 }
 
 // Synthesis represents the synthesis step that combines leg outputs.
@@ -85,6 +106,7 @@ type Synthesis struct {
 	Title       string   `toml:"title"`
 	Description string   `toml:"description"`
 	DependsOn   []string `toml:"depends_on"`
+	Estimate    string   `toml:"estimate"` // Expected duration (e.g., "30m"), used by Simulate
 }
 
 // Step represents a sequential step in a workflow formula.
@@ -94,6 +116,7 @@ type Step struct {
 	Description string   `toml:"description"`
 	Needs       []string `toml:"needs"`
 	Parallel    bool     `toml:"parallel"` // If true, this step can run concurrently with other parallel steps that share the same needs
+	Estimate    string   `toml:"estimate"` // Expected duration (e.g., "30m"), used by Simulate
 }
 
 // Template represents a template step in an expansion formula.
@@ -102,6 +125,7 @@ type Template struct {
 	Title       string   `toml:"title"`
 	Description string   `toml:"description"`
 	Needs       []string `toml:"needs"`
+	Estimate    string   `toml:"estimate"` // Expected duration (e.g., "30m"), used by Simulate
 }
 
 // Var represents a variable definition for formulas.
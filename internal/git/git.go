@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -341,6 +342,12 @@ func (g *Git) Checkout(ref string) error {
 	return err
 }
 
+// CheckoutNewBranch creates a branch at startPoint and checks it out.
+func (g *Git) CheckoutNewBranch(branch, startPoint string) error {
+	_, err := g.run("checkout", "-b", branch, startPoint)
+	return err
+}
+
 // Fetch fetches from the remote.
 func (g *Git) Fetch(remote string) error {
 	_, err := g.run("fetch", remote)
@@ -562,6 +569,26 @@ func (g *Git) Rebase(onto string) error {
 	return err
 }
 
+// RebaseOnto replays the commits of branch that aren't in upstream onto newBase
+// (git rebase --onto newBase upstream branch). The branch is checked out.
+func (g *Git) RebaseOnto(newBase, upstream, branch string) error {
+	_, err := g.run("rebase", "--onto", newBase, upstream, branch)
+	return err
+}
+
+// Revert creates a new commit that undoes the given commit.
+// The default revert message ("Revert ...") is used without opening an editor.
+func (g *Git) Revert(commit string) error {
+	_, err := g.run("revert", "--no-edit", commit)
+	return err
+}
+
+// AbortRevert aborts a revert in progress.
+func (g *Git) AbortRevert() error {
+	_, err := g.run("revert", "--abort")
+	return err
+}
+
 // AbortMerge aborts a merge in progress.
 func (g *Git) AbortMerge() error {
 	_, err := g.run("merge", "--abort")
@@ -729,6 +756,45 @@ func (g *Git) ResetBranch(name, ref string) error {
 	return err
 }
 
+// IsTracked reports whether path, relative to the work tree, is tracked.
+func (g *Git) IsTracked(path string) bool {
+	_, err := g.run("ls-files", "--error-unmatch", "--", path)
+	return err == nil
+}
+
+// ExcludeLocally adds a pattern, under a comment, to the repo's info/exclude,
+// which all its worktrees share. It does nothing if the pattern is there.
+func (g *Git) ExcludeLocally(pattern, comment string) error {
+	commonDir, err := g.run("rev-parse", "--git-common-dir")
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(g.workDir, commonDir)
+	}
+	excludePath := filepath.Join(commonDir, "info", "exclude")
+
+	data, _ := os.ReadFile(excludePath) //nolint:gosec // G304: path is inside the repo's git dir
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(excludePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: exclude file is not sensitive
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		_, _ = f.WriteString("\n")
+	}
+	_, err = f.WriteString("# " + comment + "\n" + pattern + "\n")
+	return err
+}
+
 // Rev returns the commit hash for the given ref.
 func (g *Git) Rev(ref string) (string, error) {
 	return g.run("rev-parse", ref)
@@ -936,6 +1002,12 @@ func (g *Git) WorktreeRemove(path string, force bool) error {
 	return err
 }
 
+// WorktreeMove moves a worktree to a new path, updating git's bookkeeping.
+func (g *Git) WorktreeMove(path, newPath string) error {
+	_, err := g.run("worktree", "move", path, newPath)
+	return err
+}
+
 // WorktreePrune removes worktree entries for deleted paths.
 func (g *Git) WorktreePrune() error {
 	_, err := g.run("worktree", "prune")
@@ -1041,6 +1113,124 @@ func (g *Git) CommitsAhead(base, branch string) (int, error) {
 	return count, nil
 }
 
+// DiffHunk is a changed region of a file, in merge-base line numbers.
+// OldLines is 0 for pure insertions, which sit after line OldStart.
+type DiffHunk struct {
+	OldStart int `json:"old_start"`
+	OldLines int `json:"old_lines"`
+}
+
+// DiffNameOnly returns the files changed on branch since it diverged from base
+// (git diff --name-only base...branch).
+func (g *Git) DiffNameOnly(base, branch string) ([]string, error) {
+	out, err := g.run("-c", "core.quotePath=false", "diff", "--name-only", "--no-renames", base+"..."+branch)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// DiffHunks returns the changed regions of every file touched on branch since
+// it diverged from base (git diff -U0 base...branch), keyed by path.
+// Binary files appear with no hunks.
+func (g *Git) DiffHunks(base, branch string) (map[string][]DiffHunk, error) {
+	out, err := g.run("-c", "core.quotePath=false", "diff", "-U0", "--no-color", "--no-ext-diff", "--no-renames", base+"..."+branch)
+	if err != nil {
+		return nil, err
+	}
+	return parseDiffHunks(out), nil
+}
+
+// parseDiffHunks parses unified diff output. Paths come from the "--- a/"
+// and "+++ b/" lines (the new path, or the old one for deletions); only
+// binary files, which have neither, fall back to the "diff --git" header.
+// They are read only in a file's header, before its first "@@", since a
+// removed "-- x" or added "++ x" line looks the same in a hunk.
+func parseDiffHunks(out string) map[string][]DiffHunk {
+	files := make(map[string][]DiffHunk)
+	current, oldPath := "", ""
+	inHeader := false
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current, oldPath = headerPath(strings.TrimPrefix(line, "diff --git ")), ""
+			inHeader = true
+			if current != "" {
+				files[current] = nil
+			}
+		case inHeader && strings.HasPrefix(line, "--- "):
+			oldPath = diffPath(strings.TrimPrefix(line, "--- "))
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			path := diffPath(strings.TrimPrefix(line, "+++ "))
+			if path == "" {
+				path = oldPath // deleted file
+			}
+			if path != "" && path != current {
+				delete(files, current)
+				current = path
+				files[current] = nil
+			}
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			inHeader = false
+			// @@ -start[,count] +start[,count] @@
+			fields := strings.Fields(line)
+			if len(fields) < 2 || !strings.HasPrefix(fields[1], "-") {
+				continue
+			}
+			hunk := DiffHunk{OldLines: 1}
+			spec := strings.TrimPrefix(fields[1], "-")
+			if start, count, ok := strings.Cut(spec, ","); ok {
+				_, _ = fmt.Sscanf(start, "%d", &hunk.OldStart)
+				_, _ = fmt.Sscanf(count, "%d", &hunk.OldLines)
+			} else {
+				_, _ = fmt.Sscanf(spec, "%d", &hunk.OldStart)
+			}
+			files[current] = append(files[current], hunk)
+		}
+	}
+	return files
+}
+
+// diffPath returns the path in a "--- " or "+++ " line of a diff, without
+// its a/ or b/ prefix, or "" for /dev/null. Git quotes paths with unusual
+// characters C-style and ends paths containing spaces with a tab.
+func diffPath(s string) string {
+	s = strings.TrimSuffix(s, "\t")
+	if strings.HasPrefix(s, `"`) {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return ""
+		}
+		s = unquoted
+	}
+	if s == "/dev/null" {
+		return ""
+	}
+	if len(s) > 2 && (strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/")) {
+		return s[2:]
+	}
+	return s
+}
+
+// headerPath returns the b/ path of a "diff --git a/<old> b/<new>" header.
+// Unquoted headers are ambiguous when paths contain " b/"; they are split
+// assuming old and new paths are the same, as they are without renames.
+func headerPath(rest string) string {
+	if strings.HasSuffix(rest, `"`) {
+		if i := strings.LastIndex(rest[:len(rest)-1], ` "`); i >= 0 {
+			return diffPath(rest[i+1:])
+		}
+	}
+	if len(rest) < 5 {
+		return ""
+	}
+	n := (len(rest) - 5) / 2
+	return rest[len(rest)-n:]
+}
+
 // CountCommitsBehind returns the number of commits that HEAD is behind the given ref.
 // For example, CountCommitsBehind("origin/main") returns how many commits
 // are on origin/main that are not on the current HEAD.
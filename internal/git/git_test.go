@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -543,3 +544,175 @@ func stringContains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestRevert(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add("feature.txt"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := g.Commit("add feature"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	featureSHA, err := g.Rev("HEAD")
+	if err != nil {
+		t.Fatalf("rev: %v", err)
+	}
+
+	if err := g.Revert(featureSHA); err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "feature.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected feature.txt to be removed by revert, stat err = %v", err)
+	}
+	head, err := g.Rev("HEAD")
+	if err != nil {
+		t.Fatalf("rev: %v", err)
+	}
+	if head == featureSHA {
+		t.Error("expected revert to create a new commit")
+	}
+}
+
+func TestDiffHunks(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	lines := "one\ntwo\nthree\nfour\nfive\n"
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(lines), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "café notes.txt"), []byte(lines), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add("."); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := g.Commit("add file"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	base, err := g.CurrentBranch()
+	if err != nil {
+		t.Fatalf("current branch: %v", err)
+	}
+
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	changed := "one\nTWO\nthree\nfour\nfive\n"
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(changed), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new file.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "café notes.txt"), []byte("one\ntwo\nthree\nFOUR\nfive\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add("."); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := g.Commit("change"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	names, err := g.DiffNameOnly(base, "feature")
+	if err != nil {
+		t.Fatalf("DiffNameOnly: %v", err)
+	}
+	if len(names) != 3 {
+		t.Errorf("DiffNameOnly = %v, want 3 files", names)
+	}
+
+	hunks, err := g.DiffHunks(base, "feature")
+	if err != nil {
+		t.Fatalf("DiffHunks: %v", err)
+	}
+	got := hunks["file.txt"]
+	if len(got) != 1 || got[0].OldStart != 2 || got[0].OldLines != 1 {
+		t.Errorf("file.txt hunks = %+v, want [{2 1}]", got)
+	}
+	if _, ok := hunks["new file.txt"]; !ok {
+		t.Errorf("expected path with space in hunks, got %v", hunks)
+	}
+	if got := hunks["café notes.txt"]; len(got) != 1 || got[0].OldStart != 4 {
+		t.Errorf("café notes.txt hunks = %+v, want [{4 1}] (all: %v)", got, hunks)
+	}
+}
+
+func TestParseDiffHunks(t *testing.T) {
+	out := `diff --git a/a.go b/a.go
+index 1..2 100644
+--- a/a.go
++++ b/a.go
+@@ -10,3 +10,4 @@ func x()
+@@ -20,0 +22 @@ func y()
+diff --git a/img.png b/img.png
+Binary files a/img.png and b/img.png differ
+diff --git "a/caf\303\251 b/x.go" "b/caf\303\251 b/x.go"
+--- "a/caf\303\251 b/x.go"
++++ "b/caf\303\251 b/x.go"
+@@ -1 +1 @@
+diff --git a/old name.go b/new name.go
+--- a/old name.go	
++++ b/new name.go	
+@@ -5,2 +5,2 @@
+diff --git a/gone.go b/gone.go
+--- a/gone.go
++++ /dev/null
+@@ -1,7 +0,0 @@
+diff --git a/schema.sql b/schema.sql
+--- a/schema.sql
++++ b/schema.sql
+@@ -3 +3 @@
+--- old comment
++++ new comment
+@@ -9,2 +9,0 @@
+--- a/other.sql
+-+++ b/other.sql
+diff --git "a/bin \"q\".png" "b/bin \"q\".png"
+Binary files differ`
+
+	files := parseDiffHunks(out)
+	want := []DiffHunk{{OldStart: 10, OldLines: 3}, {OldStart: 20, OldLines: 0}}
+	got := files["a.go"]
+	if len(got) != len(want) {
+		t.Fatalf("a.go hunks = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("hunk %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if hunks, ok := files["img.png"]; !ok || len(hunks) != 0 {
+		t.Errorf("binary file: hunks=%v ok=%v, want present with no hunks", hunks, ok)
+	}
+	for path, want := range map[string]DiffHunk{
+		"café b/x.go": {OldStart: 1, OldLines: 1},
+		"new name.go": {OldStart: 5, OldLines: 2},
+		"gone.go":     {OldStart: 1, OldLines: 7},
+	} {
+		if got := files[path]; len(got) != 1 || got[0] != want {
+			t.Errorf("%s hunks = %+v, want [%+v]", path, got, want)
+		}
+	}
+	if _, ok := files[`bin "q".png`]; !ok {
+		t.Errorf("quoted binary path missing: %v", files)
+	}
+	// Hunk lines that look like file headers don't start a new file
+	wantSQL := []DiffHunk{{OldStart: 3, OldLines: 1}, {OldStart: 9, OldLines: 2}}
+	if got := files["schema.sql"]; !reflect.DeepEqual(got, wantSQL) {
+		t.Errorf("schema.sql hunks = %+v, want %+v", got, wantSQL)
+	}
+	if len(files) != 7 {
+		t.Errorf("got %d files, want 7: %v", len(files), files)
+	}
+}
@@ -1,7 +1,8 @@
-// Package hooks provides centralized Claude Code hook management for Gas Town.
+// Package hooks provides centralized hook management for Gas Town.
 //
 // It manages a base hook configuration and per-role/per-rig overrides,
-// generating .claude/settings.json files for all agents in the workspace.
+// generating .claude/settings.json files for all agents in the workspace,
+// and translating the same hooks for other runtimes through a Provider.
 package hooks
 
 import (
@@ -130,6 +131,11 @@ type Target struct {
 	Role string // crew, witness, refinery, polecats, mayor, deacon
 }
 
+// WorkDir returns the agent directory the target's settings belong to.
+func (t Target) WorkDir() string {
+	return filepath.Dir(filepath.Dir(t.Path))
+}
+
 // DisplayKey returns a human-readable label for the target.
 // For targets with a rig, shows "rig/role"; for town-level targets, shows the role.
 func (t Target) DisplayKey() string {
@@ -0,0 +1,153 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Markers around the managed block in an instructions file.
+const (
+	instructionsBegin = "<!-- gt hooks: begin (managed by gt hooks sync) -->"
+	instructionsEnd   = "<!-- gt hooks: end -->"
+)
+
+// ShimEvents maps the event names `gt hooks run` takes to canonical events,
+// in the order instructions list them.
+var ShimEvents = []struct {
+	Name  string
+	Event string
+	When  string
+}{
+	{"session-start", "SessionStart", "When a session starts or resumes, unless `gt prime` output is already in your context"},
+	{"prompt-submit", "UserPromptSubmit", "Before working on each new message from the user"},
+	{"pre-tool", "PreToolUse", "Before using"},
+	{"post-tool", "PostToolUse", "After using"},
+	{"stop", "Stop", "Before you stop and wait for the user"},
+	{"compact", "PreCompact", "After your context is compacted or cleared"},
+}
+
+// EventForShim returns the canonical event for a `gt hooks run` event name.
+// Canonical names are accepted too.
+func EventForShim(name string) (string, bool) {
+	for _, s := range ShimEvents {
+		if s.Name == name || s.Event == name {
+			return s.Event, true
+		}
+	}
+	return "", false
+}
+
+// MatchTool reports whether a Claude Code style matcher selects a tool.
+// An empty matcher or "*" matches every tool, "Edit|Write" either, and
+// "Bash(git push*)" the Bash tool (the argument pattern is left to the hook).
+func MatchTool(matcher, tool string) bool {
+	if matcher == "" || matcher == "*" {
+		return true
+	}
+	for _, alt := range strings.Split(matcher, "|") {
+		if alt == tool || strings.HasPrefix(alt, tool+"(") {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandsFor returns the commands to run for an event. For tool events,
+// only entries matching tool run; with no tool, only catch-all entries do.
+func CommandsFor(cfg *HooksConfig, eventType, tool string) []string {
+	var out []string
+	for _, entry := range cfg.GetEntries(eventType) {
+		if tool == "" && entry.Matcher != "" && entry.Matcher != "*" {
+			continue
+		}
+		if tool != "" && !MatchTool(entry.Matcher, tool) {
+			continue
+		}
+		for _, h := range entry.Hooks {
+			out = append(out, h.Command)
+		}
+	}
+	return out
+}
+
+// instructionsBlock renders the managed block for hooks a runtime cannot
+// run itself, or "" when there are none.
+func instructionsBlock(cfg *HooksConfig) string {
+	var lines []string
+	for _, s := range ShimEvents {
+		for _, entry := range cfg.GetEntries(s.Event) {
+			if len(entry.Hooks) == 0 {
+				continue
+			}
+			switch s.Event {
+			case "PreToolUse", "PostToolUse":
+				tool, label := "<tool>", "any tool"
+				if entry.Matcher != "" && entry.Matcher != "*" {
+					tool = strings.SplitN(strings.Split(entry.Matcher, "|")[0], "(", 2)[0]
+					label = "the " + entry.Matcher + " tool"
+				}
+				line := fmt.Sprintf("- %s %s: `gt hooks run %s %s`", s.When, label, s.Name, tool)
+				if s.Event == "PreToolUse" {
+					line += "; if it fails, do not use the tool"
+				}
+				lines = append(lines, line)
+			default:
+				lines = append(lines, fmt.Sprintf("- %s: `gt hooks run %s`", s.When, s.Name))
+			}
+			if s.Event != "PreToolUse" && s.Event != "PostToolUse" {
+				break // One line per event; gt hooks run runs every entry
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return instructionsBegin + `
+## Gas Town Hooks
+
+This runtime does not run Gas Town's hooks by itself. Run them at these
+moments and follow what they print:
+
+` + strings.Join(lines, "\n") + "\n" + instructionsEnd + "\n"
+}
+
+// renderInstructions adds, updates or removes the managed block in an
+// instructions file, leaving the rest of the file alone.
+func renderInstructions(path string, cfg *HooksConfig) (*File, error) {
+	current, err := os.ReadFile(path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	block := instructionsBlock(cfg)
+	if !exists && block == "" {
+		return nil, nil
+	}
+
+	rest := current
+	if start := bytes.Index(current, []byte(instructionsBegin)); start >= 0 {
+		end := bytes.Index(current[start:], []byte(instructionsEnd))
+		if end < 0 {
+			return nil, fmt.Errorf("%s: managed hooks block has no end marker", path)
+		}
+		end += start + len(instructionsEnd)
+		if end < len(current) && current[end] == '\n' {
+			end++
+		}
+		rest = append(append([]byte(nil), current[:start]...), current[end:]...)
+	}
+
+	content := bytes.TrimRight(rest, "\n")
+	if block == "" {
+		if len(bytes.TrimSpace(content)) == 0 {
+			return &File{Path: path, Remove: true}, nil
+		}
+		return &File{Path: path, Content: append(content, '\n')}, nil
+	}
+	if len(content) > 0 {
+		content = append(content, "\n\n"...)
+	}
+	return &File{Path: path, Content: append(content, block...)}, nil
+}
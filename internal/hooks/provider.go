@@ -0,0 +1,301 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// Gas Town's canonical hook events are the HooksConfig event types, named
+// after Claude Code's: SessionStart, UserPromptSubmit, PreToolUse,
+// PostToolUse, Stop and PreCompact. A Provider translates them into one
+// runtime's native mechanism. Events (or tool matchers) a runtime cannot run
+// itself are delivered as instructions in its instructions file (AGENTS.md),
+// which tell the agent to run `gt hooks run <event>` at the right moments.
+
+// Provider renders hooks for one agent runtime.
+type Provider interface {
+	// Name is the provider name used in RuntimeHooksConfig.Provider.
+	Name() string
+
+	// Native reports whether the runtime runs hooks for an event and tool
+	// matcher itself.
+	Native(eventType, matcher string) bool
+
+	// Render returns the runtime's hook files in workDir for the native part
+	// of cfg, merged with whatever else those files hold.
+	Render(workDir string, cfg *HooksConfig) ([]File, error)
+}
+
+// File is a file a provider manages.
+type File struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+	Remove  bool // the file should not exist
+}
+
+// providers are the built-in providers by name.
+var providers = map[string]Provider{
+	"claude":       claudeProvider{},
+	"gemini":       geminiProvider{},
+	"cursor":       cursorProvider{},
+	"opencode":     opencodeProvider{},
+	"instructions": instructionsProvider{},
+}
+
+// GetProvider returns the provider with the given name, or nil for "none"
+// and unknown names.
+func GetProvider(name string) Provider {
+	return providers[name]
+}
+
+// ProviderNames returns the built-in provider names, sorted.
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunsSessionStart reports whether a provider primes the agent through a
+// native SessionStart hook. Unknown custom providers are assumed to.
+func RunsSessionStart(name string) bool {
+	if name == "" || name == "none" {
+		return false
+	}
+	if p := GetProvider(name); p != nil {
+		return p.Native("SessionStart", "")
+	}
+	return true
+}
+
+// Split divides cfg into the entries a provider runs natively and the rest.
+func Split(p Provider, cfg *HooksConfig) (native, fallback *HooksConfig) {
+	native, fallback = &HooksConfig{}, &HooksConfig{}
+	for _, eventType := range EventTypes {
+		for _, entry := range cfg.GetEntries(eventType) {
+			if len(entry.Hooks) == 0 {
+				continue
+			}
+			if p.Native(eventType, entry.Matcher) {
+				native.SetEntries(eventType, append(native.GetEntries(eventType), entry))
+			} else {
+				fallback.SetEntries(eventType, append(fallback.GetEntries(eventType), entry))
+			}
+		}
+	}
+	return native, fallback
+}
+
+// RenderFor returns every file that delivers cfg to a runtime in workDir:
+// the provider's native files plus the instructions block for the rest.
+// instructionsFile is the runtime's instructions file name (e.g. AGENTS.md).
+func RenderFor(p Provider, workDir, instructionsFile string, cfg *HooksConfig) ([]File, error) {
+	native, fallback := Split(p, cfg)
+	files, err := p.Render(workDir, native)
+	if err != nil {
+		return nil, err
+	}
+	if instructionsFile == "" {
+		if !isEmpty(fallback) {
+			return nil, fmt.Errorf("%s runtime needs an instructions file for its non-native hooks", p.Name())
+		}
+		return files, nil
+	}
+	f, err := renderInstructions(filepath.Join(workDir, instructionsFile), fallback)
+	if err != nil {
+		return nil, err
+	}
+	if f != nil {
+		files = append(files, *f)
+	}
+	return files, nil
+}
+
+// Change is a rendered file that differs from what is on disk.
+type Change struct {
+	File
+	Current []byte // nil when the file does not exist
+	Exists  bool
+}
+
+// Pending returns the files whose content on disk differs from the
+// rendered content. JSON files are compared structurally.
+func Pending(files []File) ([]Change, error) {
+	var changes []Change
+	for _, f := range files {
+		current, err := os.ReadFile(f.Path)
+		exists := err == nil
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if f.Remove {
+			if exists {
+				changes = append(changes, Change{File: f, Current: current, Exists: true})
+			}
+			continue
+		}
+		if exists && sameContent(f.Path, current, f.Content) {
+			continue
+		}
+		changes = append(changes, Change{File: f, Current: current, Exists: exists})
+	}
+	return changes, nil
+}
+
+// Apply writes (or removes) changed files.
+func Apply(changes []Change) error {
+	for _, c := range changes {
+		if c.Remove {
+			if err := os.Remove(c.Path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+			return fmt.Errorf("creating directory: %w", err)
+		}
+		mode := c.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		if err := os.WriteFile(c.Path, c.Content, mode); err != nil {
+			return fmt.Errorf("writing %s: %w", c.Path, err)
+		}
+	}
+	return nil
+}
+
+// Install renders the expected hooks for an override target (e.g.
+// "gastown/crew" or "polecats") into workDir and writes whatever changed.
+// workDir may be a checkout: an instructions file it tracks is left alone,
+// and one created for the managed block is excluded from git.
+func Install(providerName, workDir, instructionsFile, target string) error {
+	p := GetProvider(providerName)
+	if p == nil {
+		return nil
+	}
+	expected, err := ComputeExpected(target)
+	if err != nil {
+		return err
+	}
+	files, err := RenderFor(p, workDir, instructionsFile, expected)
+	if err != nil {
+		return err
+	}
+	changes, err := Pending(files)
+	if err != nil {
+		return err
+	}
+	if instructionsFile != "" {
+		changes = keepCheckoutClean(workDir, instructionsFile, changes)
+	}
+	return Apply(changes)
+}
+
+// keepCheckoutClean drops the change to an instructions file that workDir's
+// git work tree tracks, so installing never edits or deletes a checked-in
+// AGENTS.md, and excludes the file from git when it creates it.
+func keepCheckoutClean(workDir, instructionsFile string, changes []Change) []Change {
+	g := git.NewGit(workDir)
+	path := filepath.Join(workDir, instructionsFile)
+	var kept []Change
+	for _, c := range changes {
+		if c.Path != path {
+			kept = append(kept, c)
+			continue
+		}
+		if g.IsTracked(instructionsFile) {
+			fmt.Printf("Warning: not adding hook instructions to %s: it is tracked by git\n", path)
+			continue
+		}
+		if !c.Exists {
+			_ = g.ExcludeLocally("/"+filepath.ToSlash(instructionsFile), "gt hooks")
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
+
+// TargetForRole returns the override target for a runtime role ("polecat",
+// "crew", "witness", ...) in a rig, or for a GT_ROLE value such as
+// "gastown/crew/joe" when rig is empty.
+func TargetForRole(role, rig string) string {
+	parts := strings.Split(role, "/")
+	if rig == "" && len(parts) >= 2 && parts[0] != "deacon" {
+		rig, parts = parts[0], parts[1:]
+	}
+	switch parts[0] {
+	case "polecat", "polecats":
+		role = "polecats"
+	case "crew", "witness", "refinery":
+		role = parts[0]
+	case "mayor":
+		return "mayor"
+	default:
+		// deacon, deacon/boot and dogs share the deacon's hooks
+		return "deacon"
+	}
+	if rig == "" {
+		return role
+	}
+	return rig + "/" + role
+}
+
+func isEmpty(cfg *HooksConfig) bool {
+	return len(cfg.ToMap()) == 0
+}
+
+// sameContent compares file contents, structurally for JSON.
+func sameContent(path string, a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	if filepath.Ext(path) != ".json" {
+		return false
+	}
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// loadJSONObject reads a JSON object file as raw fields, preserving unknown
+// ones. A missing file is an empty object.
+func loadJSONObject(path string) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fields, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fields, nil
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return fields, nil
+}
+
+// marshalJSONObject encodes raw fields the way saveConfig does.
+func marshalJSONObject(fields map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,294 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testProviderConfig() *HooksConfig {
+	return &HooksConfig{
+		SessionStart: []HookEntry{
+			{Matcher: "", Hooks: []Hook{{Type: "command", Command: "gt prime --hook"}}},
+		},
+		UserPromptSubmit: []HookEntry{
+			{Matcher: "", Hooks: []Hook{{Type: "command", Command: "gt mail check --inject"}}},
+		},
+		PreToolUse: []HookEntry{
+			{Matcher: "Bash(git push*)", Hooks: []Hook{{Type: "command", Command: "gt tap guard pr-workflow"}}},
+		},
+		PostToolUse: []HookEntry{
+			{Matcher: "Edit|Write", Hooks: []Hook{{Type: "command", Command: "gt lint"}}},
+		},
+		Stop: []HookEntry{
+			{Matcher: "", Hooks: []Hook{{Type: "command", Command: "gt costs record"}}},
+		},
+	}
+}
+
+func TestSplit(t *testing.T) {
+	cfg := testProviderConfig()
+
+	native, fallback := Split(GetProvider("claude"), cfg)
+	if !isEmpty(fallback) {
+		t.Errorf("claude fallback = %v, want empty", fallback.ToMap())
+	}
+	if len(native.ToMap()) != 5 {
+		t.Errorf("claude native events = %d, want 5", len(native.ToMap()))
+	}
+
+	native, fallback = Split(GetProvider("gemini"), cfg)
+	if len(fallback.PreToolUse) != 1 || len(native.PreToolUse) != 0 {
+		t.Errorf("gemini should fall back for argument matchers, got native %v", native.PreToolUse)
+	}
+	if len(native.PostToolUse) != 1 {
+		t.Errorf("gemini should run Edit|Write natively")
+	}
+
+	native, fallback = Split(GetProvider("cursor"), cfg)
+	if len(native.Stop) != 1 || len(native.PostToolUse) != 1 {
+		t.Errorf("cursor native = %v, want Stop and PostToolUse", native.ToMap())
+	}
+	if len(fallback.SessionStart) != 1 || len(fallback.UserPromptSubmit) != 1 {
+		t.Errorf("cursor fallback = %v, want SessionStart and UserPromptSubmit", fallback.ToMap())
+	}
+
+	native, _ = Split(GetProvider("instructions"), cfg)
+	if !isEmpty(native) {
+		t.Errorf("instructions native = %v, want empty", native.ToMap())
+	}
+}
+
+func TestRenderGemini(t *testing.T) {
+	dir := t.TempDir()
+	settings := filepath.Join(dir, ".gemini", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settings), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settings, []byte(`{"theme": "dark"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := RenderFor(GetProvider("gemini"), dir, "GEMINI.md", testProviderConfig())
+	if err != nil {
+		t.Fatalf("RenderFor: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want settings.json and GEMINI.md", len(files))
+	}
+
+	var got struct {
+		Theme string                 `json:"theme"`
+		Hooks map[string][]HookEntry `json:"hooks"`
+	}
+	if err := json.Unmarshal(files[0].Content, &got); err != nil {
+		t.Fatalf("parsing settings: %v", err)
+	}
+	if got.Theme != "dark" {
+		t.Errorf("theme = %q, want existing field preserved", got.Theme)
+	}
+	for _, event := range []string{"SessionStart", "BeforeAgent", "AfterTool", "AfterAgent"} {
+		if len(got.Hooks[event]) != 1 {
+			t.Errorf("hooks[%s] = %v, want 1 entry", event, got.Hooks[event])
+		}
+	}
+	if m := got.Hooks["AfterTool"][0].Matcher; m != "replace|write_file" {
+		t.Errorf("AfterTool matcher = %q, want translated tool names", m)
+	}
+	if !strings.Contains(string(files[1].Content), "`gt hooks run pre-tool Bash`") {
+		t.Errorf("instructions missing pre-tool shim:\n%s", files[1].Content)
+	}
+}
+
+func TestRenderCursor(t *testing.T) {
+	dir := t.TempDir()
+	files, err := RenderFor(GetProvider("cursor"), dir, "AGENTS.md", testProviderConfig())
+	if err != nil {
+		t.Fatalf("RenderFor: %v", err)
+	}
+	var hooksFile struct {
+		Version int                     `json:"version"`
+		Hooks   map[string][]cursorHook `json:"hooks"`
+	}
+	if err := json.Unmarshal(files[0].Content, &hooksFile); err != nil {
+		t.Fatalf("parsing hooks.json: %v", err)
+	}
+	if hooksFile.Version != 1 {
+		t.Errorf("version = %d, want 1", hooksFile.Version)
+	}
+	if stop := hooksFile.Hooks["stop"]; len(stop) != 1 || stop[0].Command != "gt costs record" {
+		t.Errorf("stop hooks = %v", stop)
+	}
+	if edit := hooksFile.Hooks["afterFileEdit"]; len(edit) != 1 || edit[0].Command != "gt lint" {
+		t.Errorf("afterFileEdit hooks = %v", edit)
+	}
+}
+
+func TestRenderInstructionsPreservesFile(t *testing.T) {
+	dir := t.TempDir()
+	agents := filepath.Join(dir, "AGENTS.md")
+	original := "# Project\n\nExisting instructions.\n"
+	if err := os.WriteFile(agents, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := installFiles(t, "instructions", dir, testProviderConfig()); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(agents)
+	if !strings.HasPrefix(string(data), original) {
+		t.Errorf("existing content not preserved:\n%s", data)
+	}
+	for _, want := range []string{instructionsBegin, "`gt hooks run session-start`", "`gt hooks run stop`", instructionsEnd} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("AGENTS.md missing %q:\n%s", want, data)
+		}
+	}
+
+	// Rendering again changes nothing
+	files, err := RenderFor(GetProvider("instructions"), dir, "AGENTS.md", testProviderConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes, _ := Pending(files); len(changes) != 0 {
+		t.Errorf("second render pending %d changes, want 0", len(changes))
+	}
+
+	// With no hooks the block is removed and the file restored
+	if err := installFiles(t, "instructions", dir, &HooksConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(agents)
+	if string(data) != original {
+		t.Errorf("after removing hooks got:\n%s\nwant:\n%s", data, original)
+	}
+}
+
+func TestInstallKeepsCheckoutClean(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // default base hooks
+	gitRun := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+	newRepo := func() string {
+		dir := t.TempDir()
+		gitRun(dir, "init")
+		gitRun(dir, "config", "user.email", "test@test.com")
+		gitRun(dir, "config", "user.name", "Test")
+		return dir
+	}
+
+	// A tracked AGENTS.md is left alone
+	tracked := newRepo()
+	original := "# Project\n"
+	if err := os.WriteFile(filepath.Join(tracked, "AGENTS.md"), []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(tracked, "add", "AGENTS.md")
+	gitRun(tracked, "commit", "-m", "agents")
+	if err := Install("instructions", tracked, "AGENTS.md", "crew"); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tracked, "AGENTS.md")); string(data) != original {
+		t.Errorf("tracked AGENTS.md changed:\n%s", data)
+	}
+
+	// One created for the block is excluded from git
+	fresh := newRepo()
+	if err := Install("instructions", fresh, "AGENTS.md", "crew"); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(fresh, "AGENTS.md")); !strings.Contains(string(data), instructionsBegin) {
+		t.Fatalf("AGENTS.md missing the managed block:\n%s", data)
+	}
+	if status := gitRun(fresh, "status", "--porcelain"); status != "" {
+		t.Errorf("worktree dirty after Install:\n%s", status)
+	}
+}
+
+func TestRenderOpenCode(t *testing.T) {
+	files, err := RenderFor(GetProvider("opencode"), t.TempDir(), "AGENTS.md", testProviderConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin := string(files[0].Content)
+	if !strings.HasSuffix(files[0].Path, filepath.Join(".opencode", "plugin", "gastown.js")) {
+		t.Errorf("plugin path = %s", files[0].Path)
+	}
+	for _, want := range []string{`"gt costs record"`, `"edit"`, "tool.execute.before"} {
+		if !strings.Contains(plugin, want) {
+			t.Errorf("plugin missing %s", want)
+		}
+	}
+	if len(files) != 2 || !strings.Contains(string(files[1].Content), "`gt hooks run prompt-submit`") {
+		t.Errorf("prompt-submit should fall back to AGENTS.md")
+	}
+}
+
+func TestCommandsFor(t *testing.T) {
+	cfg := testProviderConfig()
+	if got := CommandsFor(cfg, "PreToolUse", "Bash"); len(got) != 1 {
+		t.Errorf("pre-tool Bash = %v, want the git push guard", got)
+	}
+	if got := CommandsFor(cfg, "PreToolUse", "Read"); len(got) != 0 {
+		t.Errorf("pre-tool Read = %v, want none", got)
+	}
+	if got := CommandsFor(cfg, "PostToolUse", "Write"); len(got) != 1 {
+		t.Errorf("post-tool Write = %v, want gt lint", got)
+	}
+	if got := CommandsFor(cfg, "Stop", ""); len(got) != 1 || got[0] != "gt costs record" {
+		t.Errorf("stop = %v", got)
+	}
+}
+
+func TestTargetForRole(t *testing.T) {
+	tests := []struct {
+		role, rig, want string
+	}{
+		{"polecat", "gastown", "gastown/polecats"},
+		{"crew", "", "crew"},
+		{"mayor", "", "mayor"},
+		{"deacon/boot", "", "deacon"},
+		{"gastown/crew/joe", "", "gastown/crew"},
+		{"gastown/polecats/toast", "", "gastown/polecats"},
+		{"beads/witness", "", "beads/witness"},
+	}
+	for _, tt := range tests {
+		if got := TargetForRole(tt.role, tt.rig); got != tt.want {
+			t.Errorf("TargetForRole(%q, %q) = %q, want %q", tt.role, tt.rig, got, tt.want)
+		}
+	}
+}
+
+func TestRunsSessionStart(t *testing.T) {
+	for name, want := range map[string]bool{
+		"claude": true, "gemini": true, "opencode": true,
+		"cursor": false, "instructions": false, "none": false, "": false,
+	} {
+		if got := RunsSessionStart(name); got != want {
+			t.Errorf("RunsSessionStart(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func installFiles(t *testing.T, provider, dir string, cfg *HooksConfig) error {
+	t.Helper()
+	files, err := RenderFor(GetProvider(provider), dir, "AGENTS.md", cfg)
+	if err != nil {
+		return err
+	}
+	changes, err := Pending(files)
+	if err != nil {
+		return err
+	}
+	return Apply(changes)
+}
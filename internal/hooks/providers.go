@@ -0,0 +1,297 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// claudeProvider writes Claude Code's .claude/settings.json, which uses the
+// canonical events as they are.
+type claudeProvider struct{}
+
+func (claudeProvider) Name() string            { return "claude" }
+func (claudeProvider) Native(_, _ string) bool { return true }
+
+func (claudeProvider) Render(workDir string, cfg *HooksConfig) ([]File, error) {
+	path := filepath.Join(workDir, ".claude", "settings.json")
+	settings, err := LoadSettings(path)
+	if err != nil {
+		return nil, err
+	}
+	settings.Hooks = *cfg
+	if settings.EnabledPlugins == nil {
+		settings.EnabledPlugins = make(map[string]bool)
+	}
+	settings.EnabledPlugins["beads@beads-marketplace"] = false
+	data, err := MarshalSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	return []File{{Path: path, Content: append(data, '\n')}}, nil
+}
+
+// geminiEvents maps canonical events to Gemini CLI hook events.
+var geminiEvents = map[string]string{
+	"SessionStart":     "SessionStart",
+	"UserPromptSubmit": "BeforeAgent",
+	"PreToolUse":       "BeforeTool",
+	"PostToolUse":      "AfterTool",
+	"Stop":             "AfterAgent",
+	"PreCompact":       "PreCompress",
+}
+
+// geminiTools maps Claude Code tool names to Gemini CLI's.
+var geminiTools = map[string]string{
+	"Bash":      "run_shell_command",
+	"Read":      "read_file",
+	"Write":     "write_file",
+	"Edit":      "replace",
+	"MultiEdit": "replace",
+	"Glob":      "glob",
+	"Grep":      "search_file_content",
+	"WebFetch":  "web_fetch",
+}
+
+// geminiProvider writes the hooks section of .gemini/settings.json. Gemini
+// CLI's hooks take the same entry shape as Claude Code's.
+type geminiProvider struct{}
+
+func (geminiProvider) Name() string { return "gemini" }
+
+func (geminiProvider) Native(eventType, matcher string) bool {
+	_, ok := translateTools(matcher, geminiTools)
+	return ok && geminiEvents[eventType] != ""
+}
+
+func (geminiProvider) Render(workDir string, cfg *HooksConfig) ([]File, error) {
+	path := filepath.Join(workDir, ".gemini", "settings.json")
+	hooks := make(map[string][]HookEntry)
+	for _, eventType := range EventTypes {
+		for _, entry := range cfg.GetEntries(eventType) {
+			matcher, _ := translateTools(entry.Matcher, geminiTools)
+			name := geminiEvents[eventType]
+			hooks[name] = append(hooks[name], HookEntry{Matcher: matcher, Hooks: entry.Hooks})
+		}
+	}
+	return renderJSONSection(path, "hooks", hooks, nil)
+}
+
+// cursorProvider writes .cursor/hooks.json. Cursor's hooks cannot add to
+// the agent's context, so only side-effect events are native: stop, and
+// file edits after the fact.
+type cursorProvider struct{}
+
+func (cursorProvider) Name() string { return "cursor" }
+
+func (cursorProvider) Native(eventType, matcher string) bool {
+	switch eventType {
+	case "Stop":
+		return true
+	case "PostToolUse":
+		if matcher == "" {
+			return false
+		}
+		for _, tool := range strings.Split(matcher, "|") {
+			if tool != "Edit" && tool != "Write" && tool != "MultiEdit" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+type cursorHook struct {
+	Command string `json:"command"`
+}
+
+func (cursorProvider) Render(workDir string, cfg *HooksConfig) ([]File, error) {
+	path := filepath.Join(workDir, ".cursor", "hooks.json")
+	hooks := make(map[string][]cursorHook)
+	for eventType, name := range map[string]string{"Stop": "stop", "PostToolUse": "afterFileEdit"} {
+		for _, entry := range cfg.GetEntries(eventType) {
+			for _, h := range entry.Hooks {
+				hooks[name] = append(hooks[name], cursorHook{Command: h.Command})
+			}
+		}
+	}
+	return renderJSONSection(path, "hooks", hooks, map[string]interface{}{"version": 1})
+}
+
+// opencodeTools maps Claude Code tool names to OpenCode's.
+var opencodeTools = map[string]string{
+	"Bash":      "bash",
+	"Read":      "read",
+	"Write":     "write",
+	"Edit":      "edit",
+	"MultiEdit": "edit",
+	"Glob":      "glob",
+	"Grep":      "grep",
+	"WebFetch":  "webfetch",
+}
+
+// opencodeProvider generates the .opencode/plugin/gastown.js plugin. The
+// plugin API has no hook that adds to a prompt, so prompt-submit hooks are
+// delivered as instructions.
+type opencodeProvider struct{}
+
+func (opencodeProvider) Name() string { return "opencode" }
+
+func (opencodeProvider) Native(eventType, matcher string) bool {
+	switch eventType {
+	case "SessionStart", "PreCompact", "Stop":
+		return true
+	case "PreToolUse", "PostToolUse":
+		_, ok := translateTools(matcher, opencodeTools)
+		return ok
+	}
+	return false
+}
+
+type opencodeEntry struct {
+	Tools    []string `json:"tools"`
+	Commands []string `json:"commands"`
+}
+
+func (opencodeProvider) Render(workDir string, cfg *HooksConfig) ([]File, error) {
+	hooks := make(map[string][]opencodeEntry)
+	for _, eventType := range EventTypes {
+		for _, entry := range cfg.GetEntries(eventType) {
+			e := opencodeEntry{Tools: []string{}}
+			if matcher, _ := translateTools(entry.Matcher, opencodeTools); matcher != "" {
+				e.Tools = strings.Split(matcher, "|")
+			}
+			for _, h := range entry.Hooks {
+				e.Commands = append(e.Commands, h.Command)
+			}
+			hooks[eventType] = append(hooks[eventType], e)
+		}
+	}
+	data, err := json.MarshalIndent(hooks, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	content := strings.Replace(opencodePlugin, "{{HOOKS}}", string(data), 1)
+	return []File{{Path: filepath.Join(workDir, ".opencode", "plugin", "gastown.js"), Content: []byte(content)}}, nil
+}
+
+const opencodePlugin = `// Gas Town OpenCode plugin, generated by gt hooks sync from the hooks base
+// config and overrides. Change those (gt hooks base, gt hooks override)
+// rather than this file.
+const HOOKS = {{HOOKS}};
+
+export const GasTown = async ({ $, directory }) => {
+  let didInit = false;
+
+  const run = async (cmd) => {
+    try {
+      await $` + "`/bin/sh -lc ${cmd}`" + `.cwd(directory);
+      return true;
+    } catch (err) {
+      console.error(` + "`[gastown] ${cmd} failed`" + `, err?.message || err);
+      return false;
+    }
+  };
+
+  const runAll = async (event, tool) => {
+    let ok = true;
+    for (const entry of HOOKS[event] || []) {
+      if (entry.tools.length > 0 && !entry.tools.includes(tool)) continue;
+      for (const cmd of entry.commands) {
+        ok = (await run(cmd)) && ok;
+      }
+    }
+    return ok;
+  };
+
+  return {
+    event: async ({ event }) => {
+      switch (event?.type) {
+        case "session.created":
+          if (didInit) return;
+          didInit = true;
+          await runAll("SessionStart");
+          break;
+        case "session.compacted":
+          await runAll("PreCompact");
+          break;
+        case "session.idle":
+          await runAll("Stop");
+          break;
+      }
+    },
+    "tool.execute.before": async (input) => {
+      if (!(await runAll("PreToolUse", input.tool))) {
+        throw new Error("blocked by a Gas Town PreToolUse hook");
+      }
+    },
+    "tool.execute.after": async (input) => {
+      await runAll("PostToolUse", input.tool);
+    },
+  };
+};
+`
+
+// instructionsProvider is for runtimes without hooks (Codex, Auggie, Amp):
+// every event is delivered through the instructions file.
+type instructionsProvider struct{}
+
+func (instructionsProvider) Name() string                                { return "instructions" }
+func (instructionsProvider) Native(_, _ string) bool                     { return false }
+func (instructionsProvider) Render(string, *HooksConfig) ([]File, error) { return nil, nil }
+
+// translateTools maps a Claude Code matcher ("Bash", "Edit|Write") to
+// another runtime's tool names. Matchers with argument patterns such as
+// "Bash(git push*)" have no equivalent and report false.
+func translateTools(matcher string, names map[string]string) (string, bool) {
+	if matcher == "" || matcher == "*" {
+		return "", true
+	}
+	var out []string
+	for _, tool := range strings.Split(matcher, "|") {
+		name, ok := names[tool]
+		if !ok {
+			return "", false
+		}
+		out = append(out, name)
+	}
+	return strings.Join(out, "|"), true
+}
+
+// renderJSONSection sets one field of a JSON settings file, preserving the
+// rest. An empty section is removed; a file that would only hold defaults
+// is not created.
+func renderJSONSection(path, key string, section interface{}, defaults map[string]interface{}) ([]File, error) {
+	fields, err := loadJSONObject(path)
+	if err != nil {
+		return nil, err
+	}
+	_, statErr := os.Stat(path)
+	exists := statErr == nil
+
+	raw, err := json.Marshal(section)
+	if err != nil {
+		return nil, err
+	}
+	empty := string(raw) == "{}" || string(raw) == "null"
+	if empty {
+		if !exists {
+			return nil, nil
+		}
+		delete(fields, key)
+	} else {
+		fields[key] = raw
+		for k, v := range defaults {
+			if _, ok := fields[k]; !ok {
+				fields[k], _ = json.Marshal(v)
+			}
+		}
+	}
+	data, err := marshalJSONObject(fields)
+	if err != nil {
+		return nil, err
+	}
+	return []File{{Path: path, Content: data}}, nil
+}
@@ -0,0 +1,228 @@
+package inbound
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// CommandTimeout bounds each bd/gt command an action runs.
+const CommandTimeout = 45 * time.Second
+
+// PathPrefix is where the receiver is mounted.
+const PathPrefix = "/hooks/"
+
+// Runner runs one command from the town root (or a directory below it) and
+// returns its combined output.
+type Runner func(ctx context.Context, townRoot string, c Command) (string, error)
+
+// ExecRunner runs commands as subprocesses.
+func ExecRunner(ctx context.Context, townRoot string, c Command) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, CommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.Args[0], c.Args[1:]...) //nolint:gosec // G204: args come from validated rules
+	cmd.Dir = filepath.Join(townRoot, c.Dir)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return out.String(), fmt.Errorf("%s timed out after %v", c.Args[0], CommandTimeout)
+	}
+	if err != nil {
+		msg := strings.TrimSpace(out.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return out.String(), fmt.Errorf("%s %s: %s", c.Args[0], c.Args[1], msg)
+	}
+	return out.String(), nil
+}
+
+// Result is the outcome of one matched rule.
+type Result struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Bead   string `json:"bead,omitempty"` // created bead (create_bead)
+	Error  string `json:"error,omitempty"`
+}
+
+// Execute runs a step's commands, stopping at the first failure.
+func Execute(ctx context.Context, run Runner, townRoot string, step *Step) Result {
+	res := Result{Rule: step.Rule, Action: step.Action}
+	for _, c := range Commands(step) {
+		out, err := run(ctx, townRoot, c)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		if step.Action == ActionCreateBead {
+			var issue struct {
+				ID string `json:"id"`
+			}
+			if json.Unmarshal([]byte(out), &issue) == nil {
+				res.Bead = issue.ID
+			}
+		}
+	}
+	return res
+}
+
+// Response is the JSON body the receiver answers with.
+type Response struct {
+	Source  string   `json:"source"`
+	Results []Result `json:"results"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Handler serves /hooks/<source>. Configuration is re-read on every request,
+// so rule edits take effect without a restart.
+type Handler struct {
+	townRoot string
+	run      Runner
+	now      func() time.Time
+	logger   func(format string, args ...interface{})
+}
+
+// NewHandler creates a receiver for a town.
+func NewHandler(townRoot string, logger func(format string, args ...interface{})) *Handler {
+	return &Handler{
+		townRoot: townRoot,
+		run:      ExecRunner,
+		now:      time.Now,
+		logger:   logger,
+	}
+}
+
+// ServeHTTP verifies a request and runs every rule it matches.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	source := strings.TrimPrefix(r.URL.Path, PathPrefix)
+	resp := Response{Source: source, Results: []Result{}}
+	fail := func(status int, format string, args ...interface{}) {
+		resp.Error = fmt.Sprintf(format, args...)
+		h.logger("Inbound %s: %s", source, resp.Error)
+		writeJSON(w, status, resp)
+	}
+
+	if r.Method != http.MethodPost {
+		fail(http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		return
+	}
+	cfg, err := Load(h.townRoot)
+	if err != nil {
+		fail(http.StatusInternalServerError, "%v", err)
+		return
+	}
+	rules := Rules(cfg, source)
+	if len(rules) == 0 {
+		fail(http.StatusNotFound, "no rules for source %q", source)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+	if err != nil {
+		fail(http.StatusBadRequest, "reading body: %v", err)
+		return
+	}
+	if len(body) > MaxBodySize {
+		fail(http.StatusRequestEntityTooLarge, "body exceeds %d bytes", MaxBodySize)
+		return
+	}
+
+	now := h.now()
+	maxSkew, _ := MaxSkew(cfg) // Validated by Load
+	if err := Verify(Secret(cfg, source), r.Header, body, now, maxSkew); err != nil {
+		fail(http.StatusUnauthorized, "%v", err)
+		return
+	}
+
+	var payload any
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			fail(http.StatusBadRequest, "body is not JSON: %v", err)
+			return
+		}
+	}
+
+	// Claim the signature before running anything, so concurrent deliveries
+	// of one request can't both run; a failed request gives it back below.
+	signature := r.Header.Get(HeaderSignature)
+	done, replay, err := Claim(h.townRoot, signature, now, maxSkew)
+	if err != nil {
+		fail(http.StatusInternalServerError, "replay check: %v", err)
+		return
+	} else if replay {
+		fail(http.StatusConflict, "request already processed")
+		return
+	}
+	completed := make(map[string]bool, len(done))
+	for _, name := range done {
+		completed[name] = true
+	}
+
+	status := http.StatusOK
+	for _, rule := range rules {
+		if !Matches(rule, payload, r.Header) {
+			continue
+		}
+		if completed[rule.Name] {
+			// Ran on an earlier attempt of this request
+			continue
+		}
+		var res Result
+		if step, err := Plan(rule, payload); err != nil {
+			res = Result{Rule: rule.Name, Action: rule.Action, Error: err.Error()}
+		} else {
+			res = Execute(r.Context(), h.run, h.townRoot, step)
+		}
+		if res.Error != "" {
+			status = http.StatusInternalServerError
+			h.logger("Inbound %s: rule %s failed: %s", source, rule.Name, res.Error)
+		} else {
+			done = append(done, rule.Name)
+		}
+		resp.Results = append(resp.Results, res)
+		_ = events.LogFeedTo(h.townRoot, events.TypeInbound, "inbound/"+source, resultPayload(r, res))
+	}
+	if status != http.StatusOK {
+		// Let the sender's retry through rather than answering it 409; it
+		// only reruns the rules that failed
+		if err := Release(h.townRoot, signature, done); err != nil {
+			h.logger("Inbound %s: releasing failed request: %v", source, err)
+		}
+	}
+	writeJSON(w, status, resp)
+}
+
+func resultPayload(r *http.Request, res Result) map[string]interface{} {
+	p := map[string]interface{}{
+		"rule":   res.Rule,
+		"action": res.Action,
+	}
+	if id := r.Header.Get(HeaderDelivery); id != "" {
+		p["delivery"] = id
+	}
+	if res.Bead != "" {
+		p["bead"] = res.Bead
+	}
+	if res.Error != "" {
+		p["error"] = res.Error
+	}
+	return p
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,416 @@
+// Package inbound receives webhooks from external systems and maps them to
+// town actions.
+//
+// Requests are POSTed to /hooks/<source>, either on gt dashboard or on a
+// standalone gt inbound serve. The rules under "inbound" in the town
+// settings (settings/config.json) decide what each payload does: close or
+// approve a gate and wake its waiters, create a bead, sling a formula, or
+// send mail.
+//
+// Every request must carry:
+//
+//	X-Gastown-Timestamp: unix seconds when the request was signed
+//	X-Gastown-Signature: sha256=<hex HMAC of "<timestamp>.<body>">
+//
+// Requests outside the clock-skew window are rejected, and so is a second
+// request with the same signature inside it, which makes each signed request
+// single-use.
+package inbound
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
+	"github.com/steveyegge/gastown/internal/webhook"
+)
+
+// Defaults for the receiver.
+const (
+	DefaultMaxSkew = 5 * time.Minute
+	MaxBodySize    = 1 << 20
+)
+
+// Request headers.
+const (
+	HeaderTimestamp = "X-Gastown-Timestamp"
+	HeaderSignature = webhook.HeaderSignature
+	HeaderDelivery  = webhook.HeaderDelivery
+)
+
+// Actions a rule can take.
+const (
+	ActionGateClose   = "gate_close"
+	ActionGateApprove = "gate_approve"
+	ActionCreateBead  = "create_bead"
+	ActionSling       = "sling"
+	ActionMail        = "mail"
+)
+
+// Actions lists the valid rule actions.
+var Actions = []string{ActionGateClose, ActionGateApprove, ActionCreateBead, ActionSling, ActionMail}
+
+// headerPrefix marks a Match key that names a request header.
+const headerPrefix = "header:"
+
+// sourcePattern restricts sources to single URL path segments.
+var sourcePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Load returns the inbound configuration from the town settings. It returns
+// an empty configuration when none is set.
+func Load(townRoot string) (*config.InboundConfig, error) {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	cfg := settings.Inbound
+	if cfg == nil {
+		cfg = &config.InboundConfig{}
+	}
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the skew, and that every rule has a unique name, a source,
+// a known action with its required parameters, and templates that parse.
+func Validate(cfg *config.InboundConfig) error {
+	if _, err := MaxSkew(cfg); err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(cfg.Rules))
+	for i, rule := range cfg.Rules {
+		if rule == nil || rule.Name == "" {
+			return fmt.Errorf("inbound.rules[%d]: name is required", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("inbound rule %q: name is used by another rule", rule.Name)
+		}
+		names[rule.Name] = true
+		if !sourcePattern.MatchString(rule.Source) {
+			return fmt.Errorf("inbound rule %q: source %q may only contain letters, digits, '.', '_' and '-'", rule.Name, rule.Source)
+		}
+		var required map[string]string
+		switch rule.Action {
+		case ActionGateClose, ActionGateApprove:
+			required = map[string]string{"gate": rule.Gate}
+		case ActionCreateBead:
+			required = map[string]string{"title": rule.Title}
+		case ActionSling:
+			required = map[string]string{"formula": rule.Formula}
+		case ActionMail:
+			required = map[string]string{"to": rule.To, "subject": rule.Subject}
+		default:
+			return fmt.Errorf("inbound rule %q: unknown action %q (want one of %s)", rule.Name, rule.Action, strings.Join(Actions, ", "))
+		}
+		for field, value := range required {
+			if value == "" {
+				return fmt.Errorf("inbound rule %q: %s requires %q", rule.Name, rule.Action, field)
+			}
+		}
+		for field, text := range templates(rule) {
+			if _, err := parseTemplate(field, text); err != nil {
+				return fmt.Errorf("inbound rule %q: %s: %w", rule.Name, field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MaxSkew returns the accepted clock skew for request timestamps.
+func MaxSkew(cfg *config.InboundConfig) (time.Duration, error) {
+	if cfg.MaxSkew == "" {
+		return DefaultMaxSkew, nil
+	}
+	d, err := time.ParseDuration(cfg.MaxSkew)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("inbound: invalid max_skew %q", cfg.MaxSkew)
+	}
+	return d, nil
+}
+
+// Secret resolves the signing secret for a source, reading "$NAME" from the
+// environment.
+func Secret(cfg *config.InboundConfig, source string) string {
+	s := cfg.Secret
+	if v, ok := cfg.Secrets[source]; ok {
+		s = v
+	}
+	if strings.HasPrefix(s, "$") {
+		return os.Getenv(strings.TrimPrefix(s, "$"))
+	}
+	return s
+}
+
+// Sign returns the X-Gastown-Signature value for a timestamp and body.
+func Sign(secret, timestamp string, body []byte) string {
+	return webhook.Sign(secret, append([]byte(timestamp+"."), body...))
+}
+
+// Verify checks a request's timestamp and signature. It does not check for
+// replays; see Seen.
+func Verify(secret string, header http.Header, body []byte, now time.Time, maxSkew time.Duration) error {
+	if secret == "" {
+		return fmt.Errorf("no inbound secret configured for this source")
+	}
+	ts := header.Get(HeaderTimestamp)
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid %s", HeaderTimestamp)
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("%s is %s off the receiver's clock (max %s)", HeaderTimestamp, skew.Round(time.Second), maxSkew)
+	}
+	if !hmacEqual(header.Get(HeaderSignature), Sign(secret, ts, body)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// hmacEqual compares signatures in constant time.
+func hmacEqual(got, want string) bool {
+	return hmac.Equal([]byte(got), []byte(want))
+}
+
+// Rules returns the rules listening on a source.
+func Rules(cfg *config.InboundConfig, source string) []*config.InboundRule {
+	var rules []*config.InboundRule
+	for _, rule := range cfg.Rules {
+		if rule.Source == source {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Matches reports whether a payload and its request headers satisfy every
+// condition of a rule.
+func Matches(rule *config.InboundRule, payload any, header http.Header) bool {
+	for key, pattern := range rule.Match {
+		var value string
+		if name, ok := strings.CutPrefix(key, headerPrefix); ok {
+			value = header.Get(name)
+		} else {
+			v, found := lookup(payload, key)
+			if !found {
+				return false
+			}
+			value = fmt.Sprint(v)
+		}
+		if !util.MatchGlob(pattern, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup resolves a dotted path ("workflow_run.conclusion") in a decoded
+// JSON value. Numeric segments index arrays.
+func lookup(v any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// Step is a rule rendered against one payload: the concrete action to run.
+type Step struct {
+	Rule        string            `json:"rule"`
+	Action      string            `json:"action"`
+	Gate        string            `json:"gate,omitempty"`
+	Reason      string            `json:"reason,omitempty"`
+	Rig         string            `json:"rig,omitempty"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Priority    int               `json:"priority,omitempty"`
+	Formula     string            `json:"formula,omitempty"`
+	Target      string            `json:"target,omitempty"`
+	Vars        map[string]string `json:"vars,omitempty"`
+	To          string            `json:"to,omitempty"`
+	Subject     string            `json:"subject,omitempty"`
+	Body        string            `json:"body,omitempty"`
+}
+
+// Plan renders a rule's templates against a payload. A template that
+// references a missing field is an error, so a malformed payload cannot
+// close the wrong gate.
+func Plan(rule *config.InboundRule, payload any) (*Step, error) {
+	step := &Step{Rule: rule.Name, Action: rule.Action, Priority: rule.Priority}
+	fields := map[string]*string{
+		"gate": &step.Gate, "reason": &step.Reason, "rig": &step.Rig,
+		"title": &step.Title, "description": &step.Description,
+		"formula": &step.Formula, "target": &step.Target,
+		"to": &step.To, "subject": &step.Subject, "body": &step.Body,
+	}
+	for field, text := range templates(rule) {
+		out, err := render(field, text, payload)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %s: %w", rule.Name, field, err)
+		}
+		if name, ok := strings.CutPrefix(field, "vars."); ok {
+			if step.Vars == nil {
+				step.Vars = make(map[string]string)
+			}
+			step.Vars[name] = out
+		} else {
+			*fields[field] = out
+		}
+	}
+	for field, value := range map[string]string{"gate": step.Gate, "title": step.Title, "formula": step.Formula, "to": step.To} {
+		if value == "" && templates(rule)[field] != "" {
+			return nil, fmt.Errorf("rule %q: %s rendered empty", rule.Name, field)
+		}
+	}
+	// Payload data ends up in argv: keep it from posing as a flag or a path
+	for field, value := range map[string]string{"gate": step.Gate, "rig": step.Rig, "formula": step.Formula, "target": step.Target, "to": step.To} {
+		if strings.HasPrefix(value, "-") || strings.ContainsAny(value, " \t\n") {
+			return nil, fmt.Errorf("rule %q: %s %q is not a valid argument", rule.Name, field, value)
+		}
+	}
+	if step.Rig != "" && !sourcePattern.MatchString(step.Rig) {
+		return nil, fmt.Errorf("rule %q: rig %q is not a rig name", rule.Name, step.Rig)
+	}
+	return step, nil
+}
+
+// templates returns a rule's non-empty template parameters by field name.
+func templates(rule *config.InboundRule) map[string]string {
+	all := map[string]string{
+		"gate": rule.Gate, "reason": rule.Reason, "rig": rule.Rig,
+		"title": rule.Title, "description": rule.Description,
+		"formula": rule.Formula, "target": rule.Target,
+		"to": rule.To, "subject": rule.Subject, "body": rule.Body,
+	}
+	for k, v := range rule.Vars {
+		all["vars."+k] = v
+	}
+	for k, v := range all {
+		if v == "" {
+			delete(all, k)
+		}
+	}
+	return all
+}
+
+var templateFuncs = template.FuncMap{
+	// field looks up a dotted path, for keys that are not valid identifiers.
+	"field": func(path string, v any) any {
+		out, _ := lookup(v, path)
+		return out
+	},
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+func render(name, text string, payload any) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, payload); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Commands returns the commands that carry out a step, in order, each as
+// argv. The first element is the program (bd or gt); Dir is the rig, or
+// empty for the town root.
+func Commands(step *Step) []Command {
+	switch step.Action {
+	case ActionGateClose:
+		args := []string{"gate", "close", step.Gate}
+		if step.Reason != "" {
+			args = append(args, "--reason", step.Reason)
+		}
+		return []Command{{Args: append([]string{"bd"}, args...)}, wake(step.Gate)}
+	case ActionGateApprove:
+		return []Command{{Args: []string{"bd", "gate", "approve", step.Gate}}, wake(step.Gate)}
+	case ActionCreateBead:
+		args := []string{"bd", "create", "--json", "--title=" + step.Title}
+		if step.Description != "" {
+			args = append(args, "--description="+step.Description)
+		}
+		if step.Priority > 0 {
+			args = append(args, fmt.Sprintf("--priority=%d", step.Priority))
+		}
+		return []Command{{Dir: step.Rig, Args: args}}
+	case ActionSling:
+		args := []string{"gt", "sling", step.Formula}
+		if step.Target != "" {
+			args = append(args, step.Target)
+		}
+		names := make([]string, 0, len(step.Vars))
+		for k := range step.Vars {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			args = append(args, "--var", k+"="+step.Vars[k])
+		}
+		return []Command{{Args: args}}
+	case ActionMail:
+		args := []string{"gt", "mail", "send", step.To, "-s", step.Subject}
+		if step.Body != "" {
+			args = append(args, "-m", step.Body)
+		}
+		return []Command{{Args: args}}
+	}
+	return nil
+}
+
+// wake notifies a gate's waiters once it has closed.
+func wake(gate string) Command {
+	return Command{Args: []string{"gt", "gate", "wake", gate}}
+}
+
+// Command is one program invocation of a step.
+type Command struct {
+	Dir  string   `json:"dir,omitempty"`
+	Args []string `json:"args"`
+}
+
+func (c Command) String() string {
+	var b strings.Builder
+	if c.Dir != "" {
+		fmt.Fprintf(&b, "(in %s) ", c.Dir)
+	}
+	for i, a := range c.Args {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if a == "" || strings.ContainsAny(a, " \t\n\"'$`\\") {
+			a = strconv.Quote(a)
+		}
+		b.WriteString(a)
+	}
+	return b.String()
+}
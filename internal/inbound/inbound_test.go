@@ -0,0 +1,240 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+const testSecret = "s3cret"
+
+// setupTown writes town settings with the given rules.
+func setupTown(t *testing.T, rules ...*config.InboundRule) string {
+	t.Helper()
+	townRoot := t.TempDir()
+	settings := config.NewTownSettings()
+	settings.Inbound = &config.InboundConfig{Secret: testSecret, Rules: rules}
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+	return townRoot
+}
+
+// recorder is a Runner that records commands instead of running them.
+type recorder struct {
+	commands []Command
+	output   string
+}
+
+func (r *recorder) run(_ context.Context, _ string, c Command) (string, error) {
+	r.commands = append(r.commands, c)
+	return r.output, nil
+}
+
+func signedRequest(source, body string, ts time.Time) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, PathPrefix+source, strings.NewReader(body))
+	stamp := strconv.FormatInt(ts.Unix(), 10)
+	req.Header.Set(HeaderTimestamp, stamp)
+	req.Header.Set(HeaderSignature, Sign(testSecret, stamp, []byte(body)))
+	return req
+}
+
+func serve(h *Handler, req *http.Request) (int, Response) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	var resp Response
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestHandlerClosesGateAndRejectsReplay(t *testing.T) {
+	townRoot := setupTown(t,
+		&config.InboundRule{
+			Name:   "ci-green",
+			Source: "ci",
+			Match:  map[string]string{"run.conclusion": "success", "header:X-CI-Event": "workflow_*"},
+			Action: ActionGateClose,
+			Gate:   "{{.gate}}",
+			Reason: "CI passed: {{.run.url}}",
+		},
+		&config.InboundRule{Name: "ci-red", Source: "ci", Match: map[string]string{"run.conclusion": "failure"},
+			Action: ActionMail, To: "mayor/", Subject: "CI failed"},
+	)
+	rec := &recorder{}
+	now := time.Now()
+	h := NewHandler(townRoot, func(string, ...interface{}) {})
+	h.run = rec.run
+	h.now = func() time.Time { return now }
+
+	body := `{"gate": "gt-abc", "run": {"conclusion": "success", "url": "https://ci/1"}}`
+	req := signedRequest("ci", body, now)
+	req.Header.Set("X-CI-Event", "workflow_run")
+	code, resp := serve(h, req)
+	if code != http.StatusOK || len(resp.Results) != 1 || resp.Results[0].Rule != "ci-green" {
+		t.Fatalf("status %d, response %+v", code, resp)
+	}
+	want := []Command{
+		{Args: []string{"bd", "gate", "close", "gt-abc", "--reason", "CI passed: https://ci/1"}},
+		{Args: []string{"gt", "gate", "wake", "gt-abc"}},
+	}
+	if !reflect.DeepEqual(rec.commands, want) {
+		t.Errorf("commands = %+v, want %+v", rec.commands, want)
+	}
+
+	// The same signed request again is a replay
+	req = signedRequest("ci", body, now)
+	req.Header.Set("X-CI-Event", "workflow_run")
+	if code, _ := serve(h, req); code != http.StatusConflict {
+		t.Errorf("replay status = %d, want %d", code, http.StatusConflict)
+	}
+	if len(rec.commands) != 2 {
+		t.Errorf("replay ran commands: %+v", rec.commands)
+	}
+}
+
+func TestHandlerAcceptsRetryAfterFailure(t *testing.T) {
+	townRoot := setupTown(t,
+		&config.InboundRule{Name: "notify", Source: "ci", Action: ActionMail, To: "mayor/", Subject: "hi"},
+		&config.InboundRule{Name: "escalate", Source: "ci", Action: ActionMail, To: "deacon/", Subject: "hi"},
+	)
+	now := time.Now()
+	h := NewHandler(townRoot, func(string, ...interface{}) {})
+	h.now = func() time.Time { return now }
+	var mailed []string
+	locked := true
+	h.run = func(_ context.Context, _ string, c Command) (string, error) {
+		to := c.Args[3] // gt mail send <to>
+		if to == "deacon/" && locked {
+			locked = false
+			return "", errors.New("gt mail: town is locked")
+		}
+		mailed = append(mailed, to)
+		return "", nil
+	}
+
+	if code, _ := serve(h, signedRequest("ci", `{}`, now)); code != http.StatusInternalServerError {
+		t.Fatalf("failing request status = %d, want %d", code, http.StatusInternalServerError)
+	}
+	code, resp := serve(h, signedRequest("ci", `{}`, now))
+	if code != http.StatusOK {
+		t.Fatalf("retry status = %d (%s), want %d", code, resp.Error, http.StatusOK)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Rule != "escalate" {
+		t.Errorf("retry ran %+v, want only the failed rule", resp.Results)
+	}
+	if want := []string{"mayor/", "deacon/"}; !reflect.DeepEqual(mailed, want) {
+		t.Errorf("mailed %v, want %v", mailed, want)
+	}
+	if code, _ := serve(h, signedRequest("ci", `{}`, now)); code != http.StatusConflict {
+		t.Errorf("replay after success status = %d, want %d", code, http.StatusConflict)
+	}
+}
+
+func TestHandlerRejectsBadRequests(t *testing.T) {
+	townRoot := setupTown(t, &config.InboundRule{Name: "r", Source: "ci", Action: ActionMail, To: "mayor/", Subject: "hi"})
+	rec := &recorder{}
+	now := time.Now()
+	h := NewHandler(townRoot, func(string, ...interface{}) {})
+	h.run = rec.run
+	h.now = func() time.Time { return now }
+
+	tampered := signedRequest("ci", `{"a":1}`, now)
+	tampered.Body = http.NoBody
+	stale := signedRequest("ci", `{}`, now.Add(-DefaultMaxSkew-time.Minute))
+	unknown := signedRequest("deploy", `{}`, now)
+	get := httptest.NewRequest(http.MethodGet, PathPrefix+"ci", nil)
+	unsigned := httptest.NewRequest(http.MethodPost, PathPrefix+"ci", strings.NewReader(`{}`))
+
+	for name, tc := range map[string]struct {
+		req  *http.Request
+		want int
+	}{
+		"tampered": {tampered, http.StatusUnauthorized},
+		"stale":    {stale, http.StatusUnauthorized},
+		"unsigned": {unsigned, http.StatusUnauthorized},
+		"unknown":  {unknown, http.StatusNotFound},
+		"get":      {get, http.StatusMethodNotAllowed},
+	} {
+		if code, resp := serve(h, tc.req); code != tc.want {
+			t.Errorf("%s: status %d (%s), want %d", name, code, resp.Error, tc.want)
+		}
+	}
+	if len(rec.commands) != 0 {
+		t.Errorf("rejected requests ran commands: %+v", rec.commands)
+	}
+}
+
+func TestPlanAndCommands(t *testing.T) {
+	payload := map[string]any{
+		"repo":  "gastown",
+		"pr":    map[string]any{"number": 42.0, "title": "Fix flaky test"},
+		"tags":  []any{"urgent"},
+		"flag":  "--force",
+		"multi": "a b",
+	}
+
+	step, err := Plan(&config.InboundRule{
+		Name: "review", Action: ActionCreateBead, Rig: "{{.repo}}",
+		Title: "Review PR #{{.pr.number}}: {{.pr.title}}", Priority: 1,
+	}, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Commands(step)
+	want := []Command{{Dir: "gastown", Args: []string{"bd", "create", "--json", "--title=Review PR #42: Fix flaky test", "--priority=1"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("create_bead commands = %+v, want %+v", got, want)
+	}
+
+	step, err = Plan(&config.InboundRule{
+		Name: "deploy", Action: ActionSling, Formula: "mol-deploy", Target: "{{.repo}}",
+		Vars: map[string]string{"tag": `{{field "tags.0" .}}`, "pr": "{{.pr.number}}"},
+	}, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Commands(step)[0].String(); got != "gt sling mol-deploy gastown --var pr=42 --var tag=urgent" {
+		t.Errorf("sling command = %s", got)
+	}
+
+	for _, rule := range []*config.InboundRule{
+		{Name: "missing", Action: ActionGateClose, Gate: "{{.gate}}"},
+		{Name: "flag", Action: ActionGateClose, Gate: "{{.flag}}"},
+		{Name: "space", Action: ActionSling, Formula: "{{.multi}}"},
+		{Name: "path", Action: ActionCreateBead, Title: "x", Rig: "../{{.repo}}"},
+	} {
+		if _, err := Plan(rule, payload); err == nil {
+			t.Errorf("Plan(%s) accepted a bad argument", rule.Name)
+		}
+	}
+
+	if !Matches(&config.InboundRule{Match: map[string]string{"pr.number": "4*"}}, payload, nil) ||
+		Matches(&config.InboundRule{Match: map[string]string{"pr.missing": "*"}}, payload, nil) {
+		t.Error("Matches resolved paths wrong")
+	}
+
+	for _, bad := range []*config.InboundConfig{
+		{MaxSkew: "soon"},
+		{Rules: []*config.InboundRule{{Name: "a", Source: "ci", Action: "explode"}}},
+		{Rules: []*config.InboundRule{{Name: "a", Source: "../ci", Action: ActionGateClose, Gate: "x"}}},
+		{Rules: []*config.InboundRule{{Name: "a", Source: "ci", Action: ActionMail, To: "mayor/"}}},
+		{Rules: []*config.InboundRule{{Name: "a", Source: "ci", Action: ActionGateClose, Gate: "{{"}}},
+		{Rules: []*config.InboundRule{
+			{Name: "a", Source: "ci", Action: ActionGateClose, Gate: "x"},
+			{Name: "a", Source: "cd", Action: ActionGateClose, Gate: "y"},
+		}},
+	} {
+		if err := Validate(bad); err == nil {
+			t.Errorf("Validate accepted %+v", bad)
+		}
+	}
+}
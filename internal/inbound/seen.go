@@ -0,0 +1,104 @@
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// seenFile records the signatures of accepted requests.
+const seenFile = "seen.json"
+
+// seenEntry is the record of one accepted request.
+type seenEntry struct {
+	At time.Time `json:"at"`
+
+	// Released is set when the request failed, so its sender may retry it.
+	Released bool `json:"released,omitempty"`
+
+	// Done names the rules that completed on earlier attempts.
+	Done []string `json:"done,omitempty"`
+}
+
+// Dir returns the directory holding inbound receiver state.
+func Dir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "inbound")
+}
+
+// Claim records a request signature before its rules run, and reports
+// whether it was already recorded. A request given back by Release can be
+// claimed again; Claim then returns the rules that completed on earlier
+// attempts so they aren't run twice. Signatures are kept for twice the skew
+// window: any older request is rejected by its timestamp alone. The file is
+// locked, so a dashboard and a gt inbound serve sharing a town cannot both
+// accept the same request.
+func Claim(townRoot, signature string, now time.Time, maxSkew time.Duration) (done []string, replay bool, err error) {
+	err = updateSeen(townRoot, func(seen map[string]*seenEntry) bool {
+		if entry, ok := seen[signature]; ok {
+			if !entry.Released {
+				replay = true
+				return false
+			}
+			entry.Released = false
+			done = entry.Done
+			return true
+		}
+		for sig, entry := range seen {
+			if now.Sub(entry.At) > 2*maxSkew {
+				delete(seen, sig)
+			}
+		}
+		seen[signature] = &seenEntry{At: now}
+		return true
+	})
+	return done, replay, err
+}
+
+// Release gives back a failed request's signature so its sender can retry
+// it, recording the rules that completed so the retry skips them.
+func Release(townRoot, signature string, done []string) error {
+	return updateSeen(townRoot, func(seen map[string]*seenEntry) bool {
+		entry, ok := seen[signature]
+		if !ok {
+			return false
+		}
+		entry.Released = true
+		entry.Done = done
+		return true
+	})
+}
+
+// updateSeen calls fn with the recorded signatures under the state lock, and
+// writes them back if fn reports a change.
+func updateSeen(townRoot string, fn func(seen map[string]*seenEntry) bool) error {
+	if err := os.MkdirAll(Dir(townRoot), 0755); err != nil {
+		return err
+	}
+	lock := flock.New(filepath.Join(Dir(townRoot), ".lock"))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking inbound state: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	path := filepath.Join(Dir(townRoot), seenFile)
+	seen := make(map[string]*seenEntry)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &seen); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	if !fn(seen) {
+		return nil
+	}
+	return util.AtomicWriteJSON(path, seen)
+}
@@ -19,12 +19,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/eventstore"
+	"github.com/steveyegge/gastown/internal/resources"
+	"github.com/steveyegge/gastown/internal/util"
 )
 
 // Config defines TTL settings for ephemeral records.
@@ -45,6 +47,11 @@ type Config struct {
 	// MinRetainCount keeps at least N events even if expired (for debugging).
 	// Default: 100
 	MinRetainCount int `json:"min_retain_count"`
+
+	// ArchiveRetention is how long archived days of the event log are kept
+	// before they are deleted.
+	// Default: 0 (keep the archive forever)
+	ArchiveRetention time.Duration `json:"archive_retention,omitempty"`
 }
 
 // DefaultConfig returns the default KRC configuration.
@@ -80,6 +87,10 @@ func DefaultConfig() *Config {
 
 			// Merge events - important for audit
 			"merge_*":       30 * 24 * time.Hour, // 30 days
+
+			// Resource samples (.resources.jsonl) - only recent history matters
+			"resource_sample": 24 * time.Hour,     // 1 day
+			"disk_sample":     3 * 24 * time.Hour, // 3 days
 		},
 	}
 }
@@ -142,7 +153,7 @@ func (c *Config) GetTTL(eventType string) time.Duration {
 	})
 
 	for _, pattern := range patterns {
-		if matchGlob(pattern, eventType) {
+		if util.MatchGlob(pattern, eventType) {
 			return c.TTLs[pattern]
 		}
 	}
@@ -150,23 +161,13 @@ func (c *Config) GetTTL(eventType string) time.Duration {
 	return c.DefaultTTL
 }
 
-// matchGlob performs simple glob matching (only * is supported).
-func matchGlob(pattern, s string) bool {
-	// Convert glob to regex
-	regexPattern := "^" + regexp.QuoteMeta(pattern) + "$"
-	regexPattern = strings.ReplaceAll(regexPattern, `\*`, `.*`)
-	re, err := regexp.Compile(regexPattern)
-	if err != nil {
-		return false
-	}
-	return re.MatchString(s)
-}
-
 // PruneResult contains statistics from a prune operation.
 type PruneResult struct {
 	EventsProcessed int            `json:"events_processed"`
 	EventsPruned    int            `json:"events_pruned"`
 	EventsRetained  int            `json:"events_retained"`
+	EventsArchived  int            `json:"events_archived"`           // of EventsPruned, gzip-archived rather than deleted
+	ArchiveDeleted  []string       `json:"archive_deleted,omitempty"` // archived days past ArchiveRetention
 	BytesBefore     int64          `json:"bytes_before"`
 	BytesAfter      int64          `json:"bytes_after"`
 	PrunedByType    map[string]int `json:"pruned_by_type"`
@@ -188,21 +189,24 @@ func NewPruner(townRoot string, config *Config) *Pruner {
 }
 
 // Prune removes expired events from the events and feed files.
-// It operates atomically by writing to temp files then renaming.
+// Expired events in the event log are moved to its gzip archive; the feed
+// and resource samples are rewritten atomically via temp files.
 func (p *Pruner) Prune() (*PruneResult, error) {
 	start := time.Now()
 	result := &PruneResult{
 		PrunedByType: make(map[string]int),
 	}
 
-	// Prune events file
-	eventsResult, err := p.pruneFile(filepath.Join(p.townRoot, events.EventsFile))
+	// Seal and archive the event log
+	eventsResult, err := p.pruneEvents()
 	if err != nil {
 		return nil, fmt.Errorf("pruning events: %w", err)
 	}
 	result.EventsProcessed += eventsResult.EventsProcessed
 	result.EventsPruned += eventsResult.EventsPruned
 	result.EventsRetained += eventsResult.EventsRetained
+	result.EventsArchived += eventsResult.EventsArchived
+	result.ArchiveDeleted = eventsResult.ArchiveDeleted
 	result.BytesBefore += eventsResult.BytesBefore
 	result.BytesAfter += eventsResult.BytesAfter
 	for k, v := range eventsResult.PrunedByType {
@@ -223,10 +227,73 @@ func (p *Pruner) Prune() (*PruneResult, error) {
 		result.PrunedByType[k] += v
 	}
 
+	// Prune resource samples
+	resourcesResult, err := p.pruneFile(resources.Path(p.townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("pruning resource samples: %w", err)
+	}
+	result.EventsProcessed += resourcesResult.EventsProcessed
+	result.EventsPruned += resourcesResult.EventsPruned
+	result.EventsRetained += resourcesResult.EventsRetained
+	result.BytesBefore += resourcesResult.BytesBefore
+	result.BytesAfter += resourcesResult.BytesAfter
+	for k, v := range resourcesResult.PrunedByType {
+		result.PrunedByType[k] += v
+	}
+
 	result.Duration = time.Since(start)
 	return result, nil
 }
 
+// pruneEvents seals the active event log into day segments, then archives
+// the events in them that are past their TTL and deletes archived days past
+// the archive retention.
+func (p *Pruner) pruneEvents() (*PruneResult, error) {
+	now := time.Now()
+	activePath := filepath.Join(p.townRoot, events.EventsFile)
+	sealedBefore, _ := eventstore.Size(p.townRoot)
+	result := &PruneResult{
+		PrunedByType: make(map[string]int),
+		BytesBefore:  fileSize(activePath) + sealedBefore,
+	}
+
+	sealed, err := eventstore.Seal(p.townRoot, now)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := eventstore.Archive(p.townRoot, p.config.GetTTL, now)
+	if err != nil {
+		return nil, err
+	}
+	if p.config.ArchiveRetention > 0 {
+		if result.ArchiveDeleted, err = eventstore.PruneArchive(p.townRoot, p.config.ArchiveRetention, now); err != nil {
+			return nil, fmt.Errorf("pruning archive: %w", err)
+		}
+	}
+	// Sign the audit chain head so later rewrites of what was pruned show up
+	if _, err := eventstore.Checkpoint(p.townRoot); err != nil {
+		return nil, fmt.Errorf("checkpointing audit chain: %w", err)
+	}
+
+	result.EventsProcessed = sealed.Retained + archived.Processed
+	result.EventsPruned = archived.Archived
+	result.EventsArchived = archived.Archived
+	result.EventsRetained = result.EventsProcessed - archived.Archived
+	result.BytesAfter = fileSize(activePath) + archived.BytesAfter
+	for k, v := range archived.ByType {
+		result.PrunedByType[k] += v
+	}
+	return result, nil
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
 // pruneFile prunes a single JSONL file.
 func (p *Pruner) pruneFile(filePath string) (result *PruneResult, err error) {
 	result = &PruneResult{
@@ -370,13 +437,16 @@ func (p *Pruner) pruneFile(filePath string) (result *PruneResult, err error) {
 
 // Stats contains statistics about the current ephemeral data.
 type Stats struct {
-	EventsFile   FileStats          `json:"events_file"`
-	FeedFile     FileStats          `json:"feed_file"`
-	ByType       map[string]int     `json:"by_type"`
-	ByAge        map[string]int     `json:"by_age"` // "0-1d", "1-7d", "7-30d", "30d+"
-	OldestEvent  time.Time          `json:"oldest_event"`
-	NewestEvent  time.Time          `json:"newest_event"`
-	TTLBreakdown map[string]TTLInfo `json:"ttl_breakdown"`
+	EventsFile    FileStats          `json:"events_file"`
+	Segments      FileStats          `json:"segments"` // sealed event log segments
+	Archive       FileStats          `json:"archive"`  // gzip-archived expired events
+	FeedFile      FileStats          `json:"feed_file"`
+	ResourcesFile FileStats          `json:"resources_file"`
+	ByType        map[string]int     `json:"by_type"`
+	ByAge         map[string]int     `json:"by_age"` // "0-1d", "1-7d", "7-30d", "30d+"
+	OldestEvent   time.Time          `json:"oldest_event"`
+	NewestEvent   time.Time          `json:"newest_event"`
+	TTLBreakdown  map[string]TTLInfo `json:"ttl_breakdown"`
 }
 
 // FileStats contains statistics for a single file.
@@ -418,6 +488,24 @@ func GetStats(townRoot string, config *Config) (*Stats, error) {
 		stats.NewestEvent = newest
 	}
 
+	// Sealed segments and the archive, from their indexes
+	segments, err := eventstore.Segments(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segments {
+		fs := &stats.Segments
+		if seg.Archived {
+			fs = &stats.Archive
+		}
+		fs.Path = filepath.Dir(seg.Path)
+		fs.Size += seg.Bytes
+		fs.EventCount += seg.Events
+		if !seg.Start.IsZero() && (stats.OldestEvent.IsZero() || seg.Start.Before(stats.OldestEvent)) {
+			stats.OldestEvent = seg.Start
+		}
+	}
+
 	// Process feed file
 	feedPath := filepath.Join(townRoot, ".feed.jsonl")
 	feedStats, oldest2, newest2, err := getFileStats(feedPath, config, now, stats.ByType, stats.ByAge, stats.TTLBreakdown)
@@ -432,6 +520,19 @@ func GetStats(townRoot string, config *Config) (*Stats, error) {
 		stats.NewestEvent = newest2
 	}
 
+	// Process resource samples
+	resourcesStats, oldest3, newest3, err := getFileStats(resources.Path(townRoot), config, now, stats.ByType, stats.ByAge, stats.TTLBreakdown)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	stats.ResourcesFile = resourcesStats
+	if !oldest3.IsZero() && (stats.OldestEvent.IsZero() || oldest3.Before(stats.OldestEvent)) {
+		stats.OldestEvent = oldest3
+	}
+	if !newest3.IsZero() && newest3.After(stats.NewestEvent) {
+		stats.NewestEvent = newest3
+	}
+
 	return stats, nil
 }
 
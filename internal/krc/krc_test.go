@@ -74,30 +74,6 @@ func TestGetTTL_DefaultFallback(t *testing.T) {
 	}
 }
 
-func TestMatchGlob(t *testing.T) {
-	tests := []struct {
-		pattern string
-		s       string
-		want    bool
-	}{
-		{"patrol_*", "patrol_started", true},
-		{"patrol_*", "patrol_complete", true},
-		{"patrol_*", "patrol", false},
-		{"patrol_*", "xpatrol_started", false},
-		{"merge_*", "merge_started", true},
-		{"*", "anything", true},
-		{"exact", "exact", true},
-		{"exact", "notexact", false},
-	}
-
-	for _, tt := range tests {
-		got := matchGlob(tt.pattern, tt.s)
-		if got != tt.want {
-			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
-		}
-	}
-}
-
 func TestLoadConfig_Default(t *testing.T) {
 	// Load from non-existent path should return defaults
 	config, err := LoadConfig("/nonexistent/path")
@@ -0,0 +1,330 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// DefaultAckWithin is how long a recipient has to acknowledge mail sent
+// with AckRequired before it is re-delivered, and again before it is
+// escalated.
+const DefaultAckWithin = 30 * time.Minute
+
+// Acknowledgement errors
+var (
+	ErrNoAckRequested = errors.New("message does not request an acknowledgement")
+	ErrAlreadyAcked   = errors.New("message already acknowledged")
+)
+
+// Acknowledgement labels. The timestamps are RFC3339.
+const (
+	labelAckRequired    = "ack-required"
+	labelAckWithin      = "ack-within:"
+	labelAcked          = "acked:"
+	labelAckRedelivered = "ack-redelivered:"
+	labelAckEscalated   = "ack-escalated:"
+)
+
+// Receipt statuses reported for ack-required mail.
+const (
+	ReceiptAcked       = "acked"
+	ReceiptRedelivered = "redelivered"
+	ReceiptEscalated   = "escalated"
+)
+
+// ackState holds the acknowledgement labels of a beads message.
+type ackState struct {
+	required      bool
+	within        time.Duration
+	ackedAt       *time.Time
+	redeliveredAt *time.Time
+	escalatedAt   *time.Time
+}
+
+func (a *ackState) parseLabel(label string) {
+	parseTime := func(prefix string) *time.Time {
+		if t, err := time.Parse(time.RFC3339, strings.TrimPrefix(label, prefix)); err == nil {
+			return &t
+		}
+		return nil
+	}
+	switch {
+	case label == labelAckRequired:
+		a.required = true
+	case strings.HasPrefix(label, labelAckWithin):
+		if d, err := time.ParseDuration(strings.TrimPrefix(label, labelAckWithin)); err == nil {
+			a.within = d
+		}
+	case strings.HasPrefix(label, labelAcked):
+		a.ackedAt = parseTime(labelAcked)
+	case strings.HasPrefix(label, labelAckRedelivered):
+		a.redeliveredAt = parseTime(labelAckRedelivered)
+	case strings.HasPrefix(label, labelAckEscalated):
+		a.escalatedAt = parseTime(labelAckEscalated)
+	}
+}
+
+// ackLabels returns the labels recording that msg needs acknowledging.
+func ackLabels(msg *Message) []string {
+	if !msg.AckRequired {
+		return nil
+	}
+	return []string{labelAckRequired, labelAckWithin + ackWindow(msg).String()}
+}
+
+func ackWindow(msg *Message) time.Duration {
+	if msg.AckWithin > 0 {
+		return msg.AckWithin
+	}
+	return DefaultAckWithin
+}
+
+// PendingAck reports whether msg still waits for an acknowledgement.
+func (m *Message) PendingAck() bool {
+	return m.AckRequired && m.AckedAt == nil
+}
+
+// AckDue returns when the next follow-up on an unacknowledged message is
+// due: re-delivery one window after sending, escalation one window after
+// re-delivery. It returns the zero time once the message is acknowledged
+// or escalated.
+func (m *Message) AckDue() time.Time {
+	switch {
+	case !m.PendingAck() || m.AckEscalatedAt != nil:
+		return time.Time{}
+	case m.AckRedeliveredAt != nil:
+		return m.AckRedeliveredAt.Add(ackWindow(m))
+	default:
+		return m.Timestamp.Add(ackWindow(m))
+	}
+}
+
+// ackSeverity maps a message priority to the severity its escalation is
+// raised at.
+func ackSeverity(p Priority) string {
+	switch p {
+	case PriorityUrgent:
+		return config.SeverityHigh
+	case PriorityHigh:
+		return config.SeverityMedium
+	default:
+		return config.SeverityLow
+	}
+}
+
+// addLabel adds one label to a message bead.
+func addLabel(id, label, workDir, beadsDir string) error {
+	_, err := runBdCommand([]string{"label", "add", id, label}, workDir, beadsDir)
+	if err != nil {
+		if bdErr, ok := err.(*bdError); ok && bdErr.ContainsError("not found") {
+			return ErrMessageNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// listByLabel returns the messages carrying label, open or closed.
+func listByLabel(label, workDir, beadsDir string) ([]*Message, error) {
+	if err := beads.EnsureCustomTypes(beadsDir); err != nil {
+		return nil, fmt.Errorf("ensuring custom types: %w", err)
+	}
+	stdout, err := runBdCommand([]string{"list",
+		"--type", "message",
+		"--label", label,
+		"--all",
+		"--json",
+	}, workDir, beadsDir)
+	if err != nil {
+		return nil, err
+	}
+	var beadsMsgs []BeadsMessage
+	if err := json.Unmarshal(stdout, &beadsMsgs); err != nil {
+		if len(stdout) == 0 || string(stdout) == "null" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	messages := make([]*Message, 0, len(beadsMsgs))
+	for i := range beadsMsgs {
+		messages = append(messages, beadsMsgs[i].ToMessage())
+	}
+	return messages, nil
+}
+
+// Ack acknowledges a message that requested it and marks it read. Only the
+// recipient can ack. Acking again returns the message with ErrAlreadyAcked.
+func (m *Mailbox) Ack(id string) (*Message, error) {
+	if m.legacy {
+		return nil, fmt.Errorf("acknowledgements need a beads mailbox")
+	}
+	msg, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !msg.AckRequired {
+		return msg, ErrNoAckRequested
+	}
+	if AddressToIdentity(msg.To) != m.identity {
+		return msg, fmt.Errorf("message %s is addressed to %s; only its recipient can acknowledge it", id, msg.To)
+	}
+	if msg.AckedAt != nil {
+		return msg, ErrAlreadyAcked
+	}
+	now := timeNow().UTC().Truncate(time.Second)
+	if err := addLabel(id, labelAcked+now.Format(time.RFC3339), m.workDir, m.beadsDir); err != nil {
+		return nil, err
+	}
+	msg.AckedAt = &now
+	if !msg.Read {
+		_ = m.MarkReadOnly(id) // best-effort: the ack itself is recorded
+		msg.Read = true
+	}
+	return msg, nil
+}
+
+// ListSent returns the messages this mailbox's identity sent, read or not,
+// newest first. With ackOnly, only messages that requested an
+// acknowledgement are returned.
+func (m *Mailbox) ListSent(ackOnly bool) ([]*Message, error) {
+	if m.legacy {
+		return nil, fmt.Errorf("sent mail needs a beads mailbox")
+	}
+	labels := []string{labelAckRequired}
+	if !ackOnly {
+		labels = nil
+		for _, v := range senderVariants(m.identity) {
+			labels = append(labels, "from:"+v)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var sent []*Message
+	for _, label := range labels {
+		msgs, err := listByLabel(label, m.workDir, m.beadsDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range msgs {
+			if seen[msg.ID] || AddressToIdentity(msg.From) != m.identity {
+				continue
+			}
+			seen[msg.ID] = true
+			sent = append(sent, msg)
+		}
+	}
+	sort.Slice(sent, func(i, j int) bool { return sent[i].Timestamp.After(sent[j].Timestamp) })
+	return sent, nil
+}
+
+// senderVariants returns the from: label values an identity may have sent
+// under: senders record their address as typed, so "gastown/max" may
+// appear as "gastown/crew/max" or "gastown/polecats/max".
+func senderVariants(identity string) []string {
+	variants := []string{identity}
+	switch identity {
+	case "mayor/", "deacon/":
+		return append(variants, strings.TrimSuffix(identity, "/"))
+	}
+	parts := strings.Split(identity, "/")
+	if len(parts) == 2 && parts[1] != "witness" && parts[1] != "refinery" {
+		variants = append(variants, parts[0]+"/crew/"+parts[1], parts[0]+"/polecats/"+parts[1])
+	}
+	return variants
+}
+
+// AckAction is a follow-up CheckAcks took on an unacknowledged message.
+type AckAction struct {
+	ID         string `json:"id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Subject    string `json:"subject"`
+	Status     string `json:"status"` // ReceiptRedelivered or ReceiptEscalated
+	Escalation string `json:"escalation,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CheckAcks follows up on ack-required mail that is overdue at now. A
+// message unacknowledged for one window is re-delivered as an interrupt:
+// reopened in the recipient's inbox and nudged. One more window without an
+// ack escalates it through the routes in settings/escalation.json, at a
+// severity that follows the message priority. Each step is recorded as a
+// label, so a message is re-delivered and escalated at most once.
+func (r *Router) CheckAcks(now time.Time) ([]AckAction, error) {
+	beadsDir := r.resolveBeadsDir("")
+	workDir := r.townRoot
+	if workDir == "" {
+		workDir = r.workDir
+	}
+	msgs, err := listByLabel(labelAckRequired, workDir, beadsDir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].Timestamp.Before(msgs[j].Timestamp) })
+
+	var actions []AckAction
+	for _, msg := range msgs {
+		due := msg.AckDue()
+		if due.IsZero() || due.After(now) {
+			continue
+		}
+		a := AckAction{ID: msg.ID, From: msg.From, To: msg.To, Subject: msg.Subject}
+		stamp := now.UTC().Truncate(time.Second).Format(time.RFC3339)
+
+		if msg.AckRedeliveredAt == nil {
+			a.Status = ReceiptRedelivered
+			if err := addLabel(msg.ID, labelAckRedelivered+stamp, workDir, beadsDir); err != nil {
+				a.Error = err.Error()
+				actions = append(actions, a)
+				continue
+			}
+			if err := r.redeliver(msg, workDir, beadsDir); err != nil {
+				a.Error = err.Error()
+			}
+		} else {
+			a.Status = ReceiptEscalated
+			if err := addLabel(msg.ID, labelAckEscalated+stamp, workDir, beadsDir); err != nil {
+				a.Error = err.Error()
+				actions = append(actions, a)
+				continue
+			}
+			waited := now.Sub(msg.Timestamp).Round(time.Minute)
+			id, err := r.raiseEscalation(msg, ackSeverity(msg.Priority),
+				"mail not acknowledged",
+				fmt.Sprintf("Mail %s to %s requested an acknowledgement and has none after %s, despite re-delivery.", msg.ID, msg.To, waited),
+				AddressToIdentity(msg.To), beadsDir)
+			a.Escalation = id
+			if err != nil {
+				a.Error = err.Error()
+			}
+		}
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+// redeliver puts an unacknowledged message back in the recipient's inbox
+// and nudges their session regardless of delivery mode.
+func (r *Router) redeliver(msg *Message, workDir, beadsDir string) error {
+	if msg.Read {
+		mb := NewMailboxWithBeadsDir(msg.To, workDir, beadsDir)
+		_ = mb.MarkUnread(msg.ID)     // closed: reopen
+		_ = mb.MarkUnreadOnly(msg.ID) // read label: drop it
+	}
+	for _, sessionID := range addressToSessionIDs(msg.To) {
+		if has, err := r.tmux.HasSession(sessionID); err != nil || !has {
+			continue
+		}
+		notification := fmt.Sprintf("⏰ Mail from %s needs your acknowledgement: %s. Run 'gt mail read %s' then 'gt mail ack %s'.",
+			msg.From, msg.Subject, msg.ID, msg.ID)
+		return r.tmux.NudgeSession(sessionID, notification)
+	}
+	return fmt.Errorf("no running session for %s", msg.To)
+}
@@ -0,0 +1,87 @@
+package mail
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBeadsMessage_AckLabels(t *testing.T) {
+	bm := BeadsMessage{
+		ID:     "hq-abc",
+		Labels: []string{"from:mayor/", "ack-required", "ack-within:1h0m0s", "ack-redelivered:2026-10-16T10:00:00Z", "acked:2026-10-16T10:20:00Z"},
+	}
+	msg := bm.ToMessage()
+	if !msg.AckRequired || msg.AckWithin != time.Hour {
+		t.Errorf("AckRequired=%v AckWithin=%v, want true 1h", msg.AckRequired, msg.AckWithin)
+	}
+	if msg.AckRedeliveredAt == nil || !msg.AckRedeliveredAt.Equal(time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("AckRedeliveredAt = %v", msg.AckRedeliveredAt)
+	}
+	if msg.AckedAt == nil || msg.PendingAck() {
+		t.Errorf("AckedAt = %v, want acknowledged", msg.AckedAt)
+	}
+	if msg.AckEscalatedAt != nil {
+		t.Errorf("AckEscalatedAt = %v, want nil", msg.AckEscalatedAt)
+	}
+
+	plain := (&BeadsMessage{Labels: []string{"from:mayor/"}}).ToMessage()
+	if plain.AckRequired || plain.PendingAck() {
+		t.Error("message without ack labels requires an ack")
+	}
+}
+
+func TestAckLabels(t *testing.T) {
+	if got := ackLabels(&Message{}); got != nil {
+		t.Errorf("ackLabels(no ack) = %v, want nil", got)
+	}
+	want := []string{"ack-required", "ack-within:30m0s"}
+	if got := ackLabels(&Message{AckRequired: true}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ackLabels(default) = %v, want %v", got, want)
+	}
+	want = []string{"ack-required", "ack-within:2h0m0s"}
+	if got := ackLabels(&Message{AckRequired: true, AckWithin: 2 * time.Hour}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ackLabels(2h) = %v, want %v", got, want)
+	}
+}
+
+func TestMessage_AckDue(t *testing.T) {
+	sent := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	redelivered := sent.Add(40 * time.Minute)
+	acked := sent.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		msg  Message
+		want time.Time
+	}{
+		{"no ack requested", Message{Timestamp: sent}, time.Time{}},
+		{"redeliver after default window", Message{Timestamp: sent, AckRequired: true}, sent.Add(DefaultAckWithin)},
+		{"redeliver after own window", Message{Timestamp: sent, AckRequired: true, AckWithin: 10 * time.Minute}, sent.Add(10 * time.Minute)},
+		{"escalate one window after redelivery", Message{Timestamp: sent, AckRequired: true, AckRedeliveredAt: &redelivered}, redelivered.Add(DefaultAckWithin)},
+		{"escalated", Message{Timestamp: sent, AckRequired: true, AckRedeliveredAt: &redelivered, AckEscalatedAt: &acked}, time.Time{}},
+		{"acknowledged", Message{Timestamp: sent, AckRequired: true, AckedAt: &acked}, time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := tt.msg.AckDue(); !got.Equal(tt.want) {
+			t.Errorf("%s: AckDue() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSenderVariants(t *testing.T) {
+	tests := []struct {
+		identity string
+		want     []string
+	}{
+		{"mayor/", []string{"mayor/", "mayor"}},
+		{"gastown/witness", []string{"gastown/witness"}},
+		{"gastown/max", []string{"gastown/max", "gastown/crew/max", "gastown/polecats/max"}},
+		{"overseer", []string{"overseer"}},
+	}
+	for _, tt := range tests {
+		if got := senderVariants(tt.identity); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("senderVariants(%q) = %v, want %v", tt.identity, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package mail
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// DigestSender is the From address of digest mail.
+const DigestSender = "digest"
+
+// Digest kinds, in the order digests list them.
+const (
+	DigestMerges      = "merges"
+	DigestConvoys     = "convoys"
+	DigestEscalations = "escalations"
+	DigestHelp        = "help"
+	DigestNudges      = "nudges"
+	DigestOther       = "other"
+)
+
+// DigestKinds lists the digest kinds in order.
+var DigestKinds = []string{DigestMerges, DigestConvoys, DigestEscalations, DigestHelp, DigestNudges, DigestOther}
+
+// digestTitles head each kind's section of a digest.
+var digestTitles = map[string]string{
+	DigestMerges:      "Merges",
+	DigestConvoys:     "Convoy updates",
+	DigestEscalations: "Escalations",
+	DigestHelp:        "Help requests",
+	DigestNudges:      "Nudges",
+	DigestOther:       "Other mail",
+}
+
+var (
+	convoyPattern     = regexp.MustCompile(`^(🚚\s*)?[Cc]onvoy\b`)
+	escalationPattern = regexp.MustCompile(`^(\[(CRITICAL|HIGH|MEDIUM|LOW|ESCALATION)\]|\[\w+→\w+\]|ESCALATION:|Escalation:)`)
+)
+
+// DigestItem is a notification held back from a muted agent.
+type DigestItem struct {
+	ID      string    `json:"id,omitempty"` // mail ID; empty for nudges
+	Kind    string    `json:"kind"`
+	From    string    `json:"from"`
+	Subject string    `json:"subject"` // mail subject or nudge text
+	At      time.Time `json:"at"`
+}
+
+// DigestQueue is the notifications held back for one mailbox.
+type DigestQueue struct {
+	Address string       `json:"address"`
+	Items   []DigestItem `json:"items"`
+}
+
+// Oldest returns when the oldest held-back notification arrived.
+func (q *DigestQueue) Oldest() time.Time {
+	var oldest time.Time
+	for _, it := range q.Items {
+		if oldest.IsZero() || it.At.Before(oldest) {
+			oldest = it.At
+		}
+	}
+	return oldest
+}
+
+// DigestKind classifies mail for a digest by its subject.
+func DigestKind(subject string) string {
+	switch ClassifyProtocol(subject) {
+	case "merged", "merge_failed", "merge_ready", "rework_request":
+		return DigestMerges
+	case "help":
+		return DigestHelp
+	}
+	trimmed := strings.TrimSpace(subject)
+	switch {
+	case convoyPattern.MatchString(trimmed):
+		return DigestConvoys
+	case escalationPattern.MatchString(trimmed):
+		return DigestEscalations
+	case strings.HasPrefix(trimmed, "HELP"):
+		return DigestHelp
+	}
+	return DigestOther
+}
+
+// digestDir holds the queued notifications.
+func digestDir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "digest")
+}
+
+func digestPath(townRoot string) string {
+	return filepath.Join(digestDir(townRoot), "pending.json")
+}
+
+// withDigestLock runs fn holding the digest lock.
+func withDigestLock(townRoot string, fn func() error) error {
+	if err := os.MkdirAll(digestDir(townRoot), 0755); err != nil {
+		return err
+	}
+	lock := flock.New(filepath.Join(digestDir(townRoot), ".lock"))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking digest queue: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return fn()
+}
+
+func loadDigests(townRoot string) (map[string]*DigestQueue, error) {
+	queues := make(map[string]*DigestQueue)
+	data, err := os.ReadFile(digestPath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil {
+		if os.IsNotExist(err) {
+			return queues, nil
+		}
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &queues); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", digestPath(townRoot), err)
+		}
+	}
+	return queues, nil
+}
+
+// QueueDigest holds a notification for a muted agent's next digest.
+func QueueDigest(townRoot, address string, item DigestItem) error {
+	if townRoot == "" {
+		return fmt.Errorf("town root not set")
+	}
+	identity := AddressToIdentity(address)
+	return withDigestLock(townRoot, func() error {
+		queues, err := loadDigests(townRoot)
+		if err != nil {
+			return err
+		}
+		q := queues[identity]
+		if q == nil {
+			q = &DigestQueue{Address: address}
+			queues[identity] = q
+		}
+		q.Items = append(q.Items, item)
+		return util.AtomicWriteJSON(digestPath(townRoot), queues)
+	})
+}
+
+// PendingDigests returns the held-back notifications by mailbox identity.
+func PendingDigests(townRoot string) (map[string]*DigestQueue, error) {
+	var queues map[string]*DigestQueue
+	err := withDigestLock(townRoot, func() error {
+		var err error
+		queues, err = loadDigests(townRoot)
+		return err
+	})
+	return queues, err
+}
+
+// BuildDigest builds the digest mail for a mailbox's held-back
+// notifications, grouped by kind with a count per kind and the command
+// that opens each message.
+func BuildDigest(q *DigestQueue) *Message {
+	byKind := make(map[string][]DigestItem)
+	for _, it := range q.Items {
+		kind := it.Kind
+		if digestTitles[kind] == "" {
+			kind = DigestOther
+		}
+		byKind[kind] = append(byKind[kind], it)
+	}
+
+	var counts []string
+	var body strings.Builder
+	fmt.Fprintf(&body, "%d notification(s) arrived while you were muted, since %s.\n",
+		len(q.Items), q.Oldest().Local().Format("2006-01-02 15:04"))
+	for _, kind := range DigestKinds {
+		items := byKind[kind]
+		if len(items) == 0 {
+			continue
+		}
+		sort.SliceStable(items, func(i, j int) bool { return items[i].At.Before(items[j].At) })
+		counts = append(counts, fmt.Sprintf("%d %s", len(items), kind))
+		fmt.Fprintf(&body, "\n%s (%d)\n", digestTitles[kind], len(items))
+		for _, it := range items {
+			line := fmt.Sprintf("  - %s (from %s, %s)", it.Subject, it.From, it.At.Local().Format("15:04"))
+			if it.ID != "" {
+				line += " → gt mail read " + it.ID
+			}
+			body.WriteString(line + "\n")
+		}
+	}
+
+	msg := NewMessage(DigestSender, q.Address,
+		fmt.Sprintf("📋 Digest: %d notification(s) (%s)", len(q.Items), strings.Join(counts, ", ")),
+		body.String())
+	msg.digest = true
+	return msg
+}
+
+// DigestSent is a digest SendDigests mailed, or failed to.
+type DigestSent struct {
+	Mailbox string       `json:"mailbox"`
+	Address string       `json:"address"`
+	Subject string       `json:"subject"`
+	Items   []DigestItem `json:"items"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// SendDigests mails a digest to each mailbox that due selects and drops
+// the notifications it covered. A failed digest keeps its notifications
+// for the next run.
+func (r *Router) SendDigests(due func(identity string, q *DigestQueue) bool) ([]DigestSent, error) {
+	if r.townRoot == "" {
+		return nil, fmt.Errorf("town root not set")
+	}
+	var sent []DigestSent
+	err := withDigestLock(r.townRoot, func() error {
+		queues, err := loadDigests(r.townRoot)
+		if err != nil {
+			return err
+		}
+		identities := make([]string, 0, len(queues))
+		for identity := range queues {
+			identities = append(identities, identity)
+		}
+		sort.Strings(identities)
+
+		changed := false
+		for _, identity := range identities {
+			q := queues[identity]
+			if len(q.Items) == 0 {
+				delete(queues, identity)
+				changed = true
+				continue
+			}
+			if !due(identity, q) {
+				continue
+			}
+			msg := BuildDigest(q)
+			d := DigestSent{Mailbox: identity, Address: q.Address, Subject: msg.Subject, Items: q.Items}
+			if err := r.Send(msg); err != nil {
+				d.Error = err.Error()
+			} else {
+				delete(queues, identity)
+				changed = true
+			}
+			sent = append(sent, d)
+		}
+		if !changed {
+			return nil
+		}
+		return util.AtomicWriteJSON(digestPath(r.townRoot), queues)
+	})
+	return sent, err
+}
+
+// notificationLevel returns an agent bead's notification level.
+var notificationLevel = func(townRoot, agentBeadID string) (string, error) {
+	return beads.New(townRoot).GetAgentNotificationLevel(agentBeadID)
+}
+
+// IsMuted reports whether the agent at address has muted notifications
+// (gt dnd on). Addresses without an agent bead are never muted.
+func IsMuted(townRoot, address string) bool {
+	if townRoot == "" {
+		return false
+	}
+	for _, id := range agentBeadIDs(townRoot, address) {
+		if level, err := notificationLevel(townRoot, id); err == nil {
+			return level == beads.NotifyMuted
+		}
+	}
+	return false
+}
+
+// agentBeadIDs returns the agent beads an address may belong to. A bare
+// "rig/name" may be a crew member or a polecat.
+func agentBeadIDs(townRoot, address string) []string {
+	switch identity := AddressToIdentity(address); identity {
+	case "mayor/":
+		return []string{beads.MayorBeadIDTown()}
+	case "deacon/":
+		return []string{beads.DeaconBeadIDTown()}
+	}
+	parts := strings.Split(strings.TrimSuffix(address, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" {
+		return nil
+	}
+	rig := parts[0]
+	prefix := beads.GetPrefixForRig(townRoot, rig)
+	switch {
+	case len(parts) == 2 && parts[1] == "witness":
+		return []string{beads.WitnessBeadIDWithPrefix(prefix, rig)}
+	case len(parts) == 2 && parts[1] == "refinery":
+		return []string{beads.RefineryBeadIDWithPrefix(prefix, rig)}
+	case len(parts) == 2:
+		return []string{beads.CrewBeadIDWithPrefix(prefix, rig, parts[1]), beads.PolecatBeadIDWithPrefix(prefix, rig, parts[1])}
+	case len(parts) == 3 && parts[1] == "crew":
+		return []string{beads.CrewBeadIDWithPrefix(prefix, rig, parts[2])}
+	case len(parts) == 3 && parts[1] == "polecats":
+		return []string{beads.PolecatBeadIDWithPrefix(prefix, rig, parts[2])}
+	}
+	return nil
+}
+
+// holdForDigest reports whether the nudge for new mail should be held for
+// the recipient's next digest: they are muted and the mail is neither
+// urgent, awaiting an ack, nor a digest itself.
+func (r *Router) holdForDigest(msg *Message) bool {
+	if msg.digest || msg.Priority == PriorityUrgent || msg.AckRequired {
+		return false
+	}
+	return IsMuted(r.townRoot, msg.To)
+}
+
+// queueForDigest holds a delivered message's notification for the
+// recipient's next digest.
+func (r *Router) queueForDigest(msg *Message, id string) error {
+	return QueueDigest(r.townRoot, msg.To, DigestItem{
+		ID:      id,
+		Kind:    DigestKind(msg.Subject),
+		From:    msg.From,
+		Subject: msg.Subject,
+		At:      timeNow(),
+	})
+}
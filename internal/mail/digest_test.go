@@ -0,0 +1,138 @@
+package mail
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestDigestKind(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"MERGED nux", DigestMerges},
+		{"MERGE_FAILED nux", DigestMerges},
+		{"HELP: Tests failing", DigestHelp},
+		{"🚚 Convoy landed: auth rewrite", DigestConvoys},
+		{"[HIGH] Dolt server down", DigestEscalations},
+		{"[ESCALATION] stuck polecat", DigestEscalations},
+		{"[witness→mayor] Re-escalated", DigestEscalations},
+		{"Lunch?", DigestOther},
+	}
+	for _, tt := range tests {
+		if got := DigestKind(tt.subject); got != tt.want {
+			t.Errorf("DigestKind(%q) = %q, want %q", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestBuildDigest(t *testing.T) {
+	at := time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local)
+	q := &DigestQueue{Address: "gastown/crew/jack", Items: []DigestItem{
+		{ID: "hq-2", Kind: DigestMerges, From: "gastown/refinery", Subject: "MERGED ace", At: at.Add(time.Minute)},
+		{ID: "hq-1", Kind: DigestMerges, From: "gastown/refinery", Subject: "MERGED nux", At: at},
+		{Kind: DigestNudges, From: "mayor/", Subject: "check the build", At: at.Add(2 * time.Minute)},
+		{ID: "hq-3", Kind: DigestHelp, From: "gastown/nux", Subject: "HELP: stuck", At: at.Add(3 * time.Minute)},
+	}}
+
+	msg := BuildDigest(q)
+	if msg.From != DigestSender || msg.To != "gastown/crew/jack" || !msg.digest {
+		t.Errorf("digest from %q to %q (digest=%v)", msg.From, msg.To, msg.digest)
+	}
+	if want := "📋 Digest: 4 notification(s) (2 merges, 1 help, 1 nudges)"; msg.Subject != want {
+		t.Errorf("subject = %q, want %q", msg.Subject, want)
+	}
+	merges := strings.Index(msg.Body, "Merges (2)")
+	help := strings.Index(msg.Body, "Help requests (1)")
+	nudges := strings.Index(msg.Body, "Nudges (1)")
+	if merges < 0 || help < merges || nudges < help {
+		t.Errorf("sections missing or out of order:\n%s", msg.Body)
+	}
+	if strings.Index(msg.Body, "MERGED nux") > strings.Index(msg.Body, "MERGED ace") {
+		t.Errorf("items not oldest first:\n%s", msg.Body)
+	}
+	if !strings.Contains(msg.Body, "→ gt mail read hq-3") {
+		t.Errorf("missing mail link:\n%s", msg.Body)
+	}
+	if strings.Contains(msg.Body, "check the build (from mayor/, 09:02) →") {
+		t.Errorf("nudge should have no link:\n%s", msg.Body)
+	}
+}
+
+func TestQueueDigest(t *testing.T) {
+	townRoot := t.TempDir()
+	at := time.Now().Add(-time.Hour)
+	if err := QueueDigest(townRoot, "mayor", DigestItem{ID: "hq-1", Kind: DigestOther, At: at}); err != nil {
+		t.Fatal(err)
+	}
+	if err := QueueDigest(townRoot, "mayor/", DigestItem{Kind: DigestNudges, At: at.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := QueueDigest(townRoot, "gastown/crew/jack", DigestItem{ID: "hq-2", Kind: DigestMerges, At: at}); err != nil {
+		t.Fatal(err)
+	}
+
+	queues, err := PendingDigests(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queues) != 2 {
+		t.Fatalf("got %d queues, want 2: %v", len(queues), queues)
+	}
+	mayor := queues["mayor/"]
+	if mayor == nil || len(mayor.Items) != 2 {
+		t.Fatalf("mayor queue = %+v", mayor)
+	}
+	if !mayor.Oldest().Equal(at) {
+		t.Errorf("Oldest() = %v, want %v", mayor.Oldest(), at)
+	}
+}
+
+func TestIsMuted(t *testing.T) {
+	townRoot := t.TempDir()
+	crew := beads.CrewBeadIDWithPrefix("gt", "gastown", "jack")
+	orig := notificationLevel
+	defer func() { notificationLevel = orig }()
+	notificationLevel = func(_, id string) (string, error) {
+		if id == crew {
+			return beads.NotifyMuted, nil
+		}
+		return beads.NotifyNormal, nil
+	}
+
+	for _, address := range []string{"gastown/crew/jack", "gastown/jack"} {
+		if !IsMuted(townRoot, address) {
+			t.Errorf("IsMuted(%q) = false, want true", address)
+		}
+	}
+	for _, address := range []string{"mayor/", "gastown/witness", "gastown/polecats/nux", "overseer"} {
+		if IsMuted(townRoot, address) {
+			t.Errorf("IsMuted(%q) = true, want false", address)
+		}
+	}
+}
+
+func TestAgentBeadIDs(t *testing.T) {
+	townRoot := t.TempDir()
+	tests := []struct {
+		address string
+		want    []string
+	}{
+		{"mayor", []string{beads.MayorBeadIDTown()}},
+		{"deacon/", []string{beads.DeaconBeadIDTown()}},
+		{"gastown/witness", []string{beads.WitnessBeadIDWithPrefix("gt", "gastown")}},
+		{"gastown/crew/jack", []string{beads.CrewBeadIDWithPrefix("gt", "gastown", "jack")}},
+		{"gastown/polecats/nux", []string{beads.PolecatBeadIDWithPrefix("gt", "gastown", "nux")}},
+		{"gastown/nux", []string{beads.CrewBeadIDWithPrefix("gt", "gastown", "nux"), beads.PolecatBeadIDWithPrefix("gt", "gastown", "nux")}},
+		{"overseer", nil},
+	}
+	for _, tt := range tests {
+		if got := agentBeadIDs(townRoot, tt.address); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("agentBeadIDs(%q) = %v, want %v", tt.address, got, tt.want)
+		}
+	}
+}
@@ -642,6 +642,8 @@ func (r *Router) shouldBeWisp(msg *Message) bool {
 // Supports single-copy delivery for:
 // - Queues (queue:name) - stores single message for worker claiming
 // - Announces (announce:name) - bulletin board, no claiming, retention-limited
+// Each copy delivered to an agent's mailbox goes through that mailbox's
+// rules in config/messaging.json (see EvaluateRules).
 func (r *Router) Send(msg *Message) error {
 	// Check for mailing list address
 	if isListAddress(msg.To) {
@@ -752,7 +754,8 @@ func (r *Router) validateRecipient(identity string) error {
 	return fmt.Errorf("no agent found")
 }
 
-// sendToSingle sends a message to a single recipient.
+// sendToSingle sends a message to a single recipient, applying the
+// recipient's mailbox rules.
 func (r *Router) sendToSingle(msg *Message) error {
 	// Convert addresses to beads identities
 	toIdentity := AddressToIdentity(msg.To)
@@ -762,6 +765,26 @@ func (r *Router) sendToSingle(msg *Message) error {
 		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
 	}
 
+	rules, err := r.mailboxRules(msg)
+	if err != nil {
+		return err
+	}
+	if msg.AckRequired {
+		// Mail awaiting an ack must land in the inbox and be announced
+		rules.Bead = ""
+		rules.Archive = false
+		if rules.Delivery == DeliveryQueue {
+			rules.Delivery = ""
+		}
+	}
+	beadsDir := r.resolveBeadsDir(msg.To)
+	if rules.Bead != "" {
+		if err := r.convertToBead(msg, rules, beadsDir); err != nil {
+			return err
+		}
+		return r.afterRules(msg, rules, beadsDir)
+	}
+
 	// Build labels for from/thread/reply-to/cc
 	var labels []string
 	labels = append(labels, "from:"+msg.From)
@@ -771,11 +794,19 @@ func (r *Router) sendToSingle(msg *Message) error {
 	if msg.ReplyTo != "" {
 		labels = append(labels, "reply-to:"+msg.ReplyTo)
 	}
+	if msg.Type != "" && msg.Type != TypeNotification {
+		labels = append(labels, "msg-type:"+string(msg.Type))
+	}
 	// Add CC labels (one per recipient)
 	for _, cc := range msg.CC {
 		ccIdentity := AddressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	if msg.List != "" {
+		labels = append(labels, "list:"+msg.List, "list-send:"+msg.ListSend)
+	}
+	labels = append(labels, rules.Labels...)
+	labels = append(labels, ackLabels(msg)...)
 
 	// Build command: bd create <subject> --type=message --assignee=<recipient> -d <body>
 	args := []string{"create", msg.Subject,
@@ -801,21 +832,63 @@ func (r *Router) sendToSingle(msg *Message) error {
 		args = append(args, "--ephemeral")
 	}
 
-	beadsDir := r.resolveBeadsDir(msg.To)
+	// Notify the recipient if they have an active session (best-effort notification)
+	// Skip notification for self-mail (handoffs to future-self don't need present-self notified),
+	// archived mail, and mail a rule or the sender queued for `gt mail check`
+	delivery := msg.Delivery
+	if rules.Delivery != "" {
+		delivery = rules.Delivery
+	}
+	notify := !isSelfMail(msg.From, msg.To) && !rules.Archive && delivery != DeliveryQueue
+	hold := notify && r.holdForDigest(msg)
+
+	// Archived messages are closed right after creation, and held ones are
+	// listed in the digest, which needs the ID
+	if rules.Archive || hold {
+		args = append(args, "--json")
+	}
+
 	if err := r.ensureCustomTypes(beadsDir); err != nil {
 		return err
 	}
-	_, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
+	out, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
 	if err != nil {
 		return fmt.Errorf("sending message: %w", err)
 	}
+	if rules.Archive {
+		if err := archiveCreated(out, beadsDir); err != nil {
+			return err
+		}
+	}
 
-	// Notify recipient if they have an active session (best-effort notification)
-	// Skip notification for self-mail (handoffs to future-self don't need present-self notified)
-	if !isSelfMail(msg.From, msg.To) {
+	switch {
+	case hold:
+		_ = r.queueForDigest(msg, createdID(out)) // best-effort, like the nudge
+	case notify && !(msg.digest && IsMuted(r.townRoot, msg.To)):
 		_ = r.notifyRecipient(msg)
 	}
 
+	return r.afterRules(msg, rules, beadsDir)
+}
+
+// afterRules runs the rule actions that follow delivery: forwards and
+// escalation. The message itself was delivered, so failures are reported
+// as such.
+func (r *Router) afterRules(msg *Message, rules *RuleResult, beadsDir string) error {
+	var errs []string
+	if len(rules.Forward) > 0 {
+		if err := r.forwardCopies(msg, rules); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if rules.Escalate != "" {
+		if err := r.escalate(msg, rules, beadsDir); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("delivered to %s, but mail rules failed: %s", msg.To, strings.Join(errs, "; "))
+	}
 	return nil
 }
 
@@ -829,13 +902,16 @@ func (r *Router) sendToList(msg *Message) error {
 		return err
 	}
 
-	// Send to each recipient
+	// Send to each recipient, marking the copies as one send
+	listSend := generateID()
 	var lastErr error
 	successCount := 0
 	for _, recipient := range recipients {
 		// Create a copy of the message for this recipient
 		copy := *msg
 		copy.To = recipient
+		copy.List = listName
+		copy.ListSend = listSend
 
 		if err := r.Send(&copy); err != nil {
 			lastErr = err
@@ -852,6 +928,13 @@ func (r *Router) sendToList(msg *Message) error {
 	return nil
 }
 
+// ListCopies returns the members' copies of mail sent to a mailing list,
+// read or not. Copies of one send share a ListSend.
+func (r *Router) ListCopies(listName string) ([]*Message, error) {
+	beadsDir := r.resolveBeadsDir("")
+	return listByLabel("list:"+listName, filepath.Dir(beadsDir), beadsDir)
+}
+
 // ExpandListAddress expands a list:name address to its recipients.
 // Returns ErrUnknownList if the list is not found.
 // This is exported for use by commands that want to show fan-out details.
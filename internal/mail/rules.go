@@ -0,0 +1,317 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// ProtocolNone is the protocol type of ordinary mail.
+const ProtocolNone = "none"
+
+// witnessProtocols mirror witness.ClassifyMessage, checked in its order.
+// The witness and protocol packages import this one, so their patterns
+// are repeated here; tests in those packages keep the copies in sync.
+var witnessProtocols = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"polecat_done", regexp.MustCompile(`^POLECAT_DONE\s+(\S+)`)},
+	{"lifecycle_shutdown", regexp.MustCompile(`^LIFECYCLE:Shutdown\s+(\S+)`)},
+	{"help", regexp.MustCompile(`^HELP:\s+(.+)`)},
+	{"merged", regexp.MustCompile(`^MERGED\s+(\S+)`)},
+	{"merge_failed", regexp.MustCompile(`^MERGE_FAILED\s+(\S+)`)},
+	{"handoff", regexp.MustCompile(`^🤝\s*HANDOFF`)},
+	{"swarm_start", regexp.MustCompile(`^SWARM_START`)},
+}
+
+// refineryProtocols mirror protocol.ParseMessageType's subject prefixes.
+var refineryProtocols = []string{"MERGE_READY", "MERGED", "MERGE_FAILED", "REWORK_REQUEST"}
+
+// ClassifyProtocol returns the protocol message type of a subject, named
+// as witness.ProtocolType ("polecat_done") or, for Witness-Refinery
+// messages, the lowercased protocol.MessageType ("merge_ready").
+// Returns ProtocolNone for ordinary mail.
+func ClassifyProtocol(subject string) string {
+	for _, p := range witnessProtocols {
+		if p.pattern.MatchString(subject) {
+			return p.name
+		}
+	}
+	trimmed := strings.TrimSpace(subject)
+	for _, prefix := range refineryProtocols {
+		if strings.HasPrefix(trimmed, prefix) {
+			return strings.ToLower(prefix)
+		}
+	}
+	return ProtocolNone
+}
+
+// RuleResult is what a recipient's mailbox rules decided for a message.
+type RuleResult struct {
+	Mailbox  string   `json:"mailbox"`
+	Protocol string   `json:"protocol"`
+	Matched  []string `json:"matched,omitempty"` // names of matching rules, in order
+	Archive  bool     `json:"archive,omitempty"`
+	Labels   []string `json:"labels,omitempty"`
+	Forward  []string `json:"forward,omitempty"`
+	Bead     string   `json:"bead,omitempty"`
+	Escalate string   `json:"escalate,omitempty"`
+	Delivery Delivery `json:"delivery,omitempty"`
+}
+
+// EvaluateRules runs the rules for a message's recipient mailbox. Rules
+// keyed by the recipient's own address run first, then wildcard keys in
+// name order. cfg may be nil.
+func EvaluateRules(cfg *config.MessagingConfig, msg *Message) *RuleResult {
+	res := &RuleResult{Mailbox: AddressToIdentity(msg.To), Protocol: ClassifyProtocol(msg.Subject)}
+	if cfg == nil {
+		return res
+	}
+	for _, rule := range MailboxRules(cfg, msg.To) {
+		if !ruleMatches(rule.Match, msg, res.Protocol) {
+			continue
+		}
+		res.Matched = append(res.Matched, rule.Name)
+		res.Labels = append(res.Labels, "rule:"+rule.Name)
+		res.Labels = append(res.Labels, rule.Labels...)
+		res.Forward = append(res.Forward, rule.Forward...)
+		res.Archive = res.Archive || rule.Archive
+		if rule.Bead != "" && res.Bead == "" {
+			res.Bead = rule.Bead
+		}
+		if rule.Escalate != "" && res.Escalate == "" {
+			res.Escalate = rule.Escalate
+		}
+		if rule.Delivery != "" && res.Delivery == "" {
+			res.Delivery = Delivery(rule.Delivery)
+		}
+		if !rule.Continue {
+			break
+		}
+	}
+	return res
+}
+
+// MailboxRules returns the rules that apply to a mailbox, in evaluation
+// order.
+func MailboxRules(cfg *config.MessagingConfig, address string) []config.MailRule {
+	identity := AddressToIdentity(address)
+	var exact []config.MailRule
+	var patterns []string
+	for key := range cfg.Rules {
+		switch {
+		case strings.Contains(key, "*"):
+			if key == "*" || matchPattern(AddressToIdentity(key), identity) {
+				patterns = append(patterns, key)
+			}
+		case AddressToIdentity(key) == identity:
+			exact = append(exact, cfg.Rules[key]...)
+		}
+	}
+	// "*" sorts before letters; run the catch-all last
+	sort.Slice(patterns, func(i, j int) bool {
+		if (patterns[i] == "*") != (patterns[j] == "*") {
+			return patterns[j] == "*"
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, key := range patterns {
+		exact = append(exact, cfg.Rules[key]...)
+	}
+	return exact
+}
+
+// priorityRank orders priorities for "high+" matches.
+var priorityRank = map[Priority]int{PriorityLow: 0, PriorityNormal: 1, PriorityHigh: 2, PriorityUrgent: 3}
+
+func ruleMatches(m config.MailMatch, msg *Message, protocol string) bool {
+	if m.From != "" && !matchSender(m.From, msg.From) {
+		return false
+	}
+	if m.Protocol != "" && !strings.EqualFold(m.Protocol, protocol) {
+		return false
+	}
+	if m.Priority != "" {
+		priority := msg.Priority
+		if priority == "" {
+			priority = PriorityNormal
+		}
+		want := Priority(strings.TrimSuffix(m.Priority, "+"))
+		if strings.HasSuffix(m.Priority, "+") {
+			if priorityRank[priority] < priorityRank[want] {
+				return false
+			}
+		} else if priority != want {
+			return false
+		}
+	}
+	// Patterns were validated when the config was loaded
+	if m.Subject != "" {
+		if re, err := regexp.Compile(m.Subject); err != nil || !re.MatchString(msg.Subject) {
+			return false
+		}
+	}
+	if m.Body != "" {
+		if re, err := regexp.Compile(m.Body); err != nil || !re.MatchString(msg.Body) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchSender matches a sender against an address pattern, as written or
+// in canonical form ("gastown/polecats/Toast" is also "gastown/Toast").
+func matchSender(pattern, from string) bool {
+	if pattern == "*" {
+		return true
+	}
+	return matchPattern(strings.TrimSuffix(pattern, "/"), strings.TrimSuffix(from, "/")) ||
+		matchPattern(AddressToIdentity(pattern), AddressToIdentity(from))
+}
+
+// mailboxRules evaluates the town's mailbox rules for a message. A town
+// without a messaging config has no rules.
+func (r *Router) mailboxRules(msg *Message) (*RuleResult, error) {
+	if r.townRoot == "" || msg.skipRules {
+		return EvaluateRules(nil, msg), nil
+	}
+	cfg, err := config.LoadMessagingConfig(config.MessagingConfigPath(r.townRoot))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return EvaluateRules(nil, msg), nil
+		}
+		return nil, fmt.Errorf("loading messaging config: %w", err)
+	}
+	return EvaluateRules(cfg, msg), nil
+}
+
+// convertToBead files a message as a bead of the rule's type instead of
+// delivering it.
+func (r *Router) convertToBead(msg *Message, rules *RuleResult, beadsDir string) error {
+	desc := fmt.Sprintf("%s\n\nFrom: %s\nTo: %s\nRule: %s", msg.Body, msg.From, msg.To, strings.Join(rules.Matched, ", "))
+	b := beads.NewWithBeadsDir(filepath.Dir(beadsDir), beadsDir)
+	if _, err := b.Create(beads.CreateOptions{
+		Title:       msg.Subject,
+		Type:        rules.Bead,
+		Priority:    PriorityToBeads(msg.Priority),
+		Description: desc,
+		Actor:       msg.From,
+	}); err != nil {
+		return fmt.Errorf("converting message to %s bead: %w", rules.Bead, err)
+	}
+	return nil
+}
+
+// archiveCreated closes a message bead just created with --json, so it is
+// delivered already read.
+func archiveCreated(out []byte, beadsDir string) error {
+	id := createdID(out)
+	if id == "" {
+		return fmt.Errorf("archiving message: no ID in bd create output")
+	}
+	if _, err := runBdCommand([]string{"close", id}, filepath.Dir(beadsDir), beadsDir); err != nil {
+		return fmt.Errorf("archiving message %s: %w", id, err)
+	}
+	return nil
+}
+
+// createdID returns the bead ID in bd create --json output, or "".
+func createdID(out []byte) string {
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(out, &created); err != nil {
+		return ""
+	}
+	return created.ID
+}
+
+// forwardCopies sends a copy of a message to each forward address. Copies
+// skip mailbox rules, so rules cannot forward in a loop.
+func (r *Router) forwardCopies(msg *Message, rules *RuleResult) error {
+	var errs []string
+	for _, to := range rules.Forward {
+		fwd := *msg
+		fwd.To = to
+		fwd.CC = nil
+		fwd.skipRules = true
+		if err := r.Send(&fwd); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", to, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("forwarding: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// escalate raises the escalation a matching rule asks for.
+func (r *Router) escalate(msg *Message, rules *RuleResult, beadsDir string) error {
+	matched := strings.Join(rules.Matched, ", ")
+	_, err := r.raiseEscalation(msg, rules.Escalate, "mail rule "+matched,
+		fmt.Sprintf("Mail to %s matched rule %s.", rules.Mailbox, matched), rules.Mailbox, beadsDir)
+	return err
+}
+
+// raiseEscalation creates an escalation bead for a message and mails the
+// targets settings/escalation.json routes the severity to, other than the
+// recipient mailbox, who has the message already. It returns the bead ID.
+func (r *Router) raiseEscalation(msg *Message, severity, reason, note, mailbox, beadsDir string) (string, error) {
+	b := beads.NewWithBeadsDir(filepath.Dir(beadsDir), beadsDir)
+	issue, err := b.CreateEscalationBead(msg.Subject, &beads.EscalationFields{
+		Severity:    severity,
+		Reason:      reason,
+		Source:      "mail:" + mailbox,
+		EscalatedBy: msg.From,
+		EscalatedAt: time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating escalation bead: %w", err)
+	}
+
+	escCfg, err := config.LoadOrCreateEscalationConfig(config.EscalationConfigPath(r.townRoot))
+	if err != nil {
+		return issue.ID, fmt.Errorf("loading escalation config: %w", err)
+	}
+	priority := PriorityNormal
+	switch severity {
+	case config.SeverityCritical:
+		priority = PriorityUrgent
+	case config.SeverityHigh:
+		priority = PriorityHigh
+	case config.SeverityLow:
+		priority = PriorityLow
+	}
+	var errs []string
+	for _, action := range escCfg.GetRouteForSeverity(severity) {
+		target := strings.TrimPrefix(action, "mail:")
+		if target == action || target == "" || AddressToIdentity(target) == mailbox {
+			continue
+		}
+		esc := &Message{
+			From:      msg.From,
+			To:        target,
+			Subject:   fmt.Sprintf("[%s] %s", strings.ToUpper(severity), msg.Subject),
+			Body:      fmt.Sprintf("Escalation: %s\n%s\n\n%s", issue.ID, note, msg.Body),
+			Priority:  priority,
+			Type:      TypeTask,
+			skipRules: true,
+		}
+		if err := r.Send(esc); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", target, err))
+		}
+	}
+	if len(errs) > 0 {
+		return issue.ID, fmt.Errorf("escalation %s mail: %s", issue.ID, strings.Join(errs, "; "))
+	}
+	return issue.ID, nil
+}
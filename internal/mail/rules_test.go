@@ -0,0 +1,119 @@
+package mail
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestClassifyProtocol(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"POLECAT_DONE nux", "polecat_done"},
+		{"HELP: Tests failing", "help"},
+		{"MERGED nux", "merged"},
+		{"MERGE_READY nux", "merge_ready"},
+		{"  REWORK_REQUEST ace", "rework_request"},
+		{"MERGED", "merged"}, // no polecat name: refinery prefix
+		{"Lunch?", ProtocolNone},
+	}
+	for _, tt := range tests {
+		if got := ClassifyProtocol(tt.subject); got != tt.want {
+			t.Errorf("ClassifyProtocol(%q) = %q, want %q", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	cfg := &config.MessagingConfig{Rules: map[string][]config.MailRule{
+		"mayor/": {
+			{Name: "overseer", Match: config.MailMatch{From: "overseer"}},
+			{Name: "done", Match: config.MailMatch{Protocol: "polecat_done"}, Archive: true, Labels: []string{"noise"}},
+			{Name: "help", Match: config.MailMatch{Protocol: "help", Priority: "high+"}, Escalate: "high", Continue: true},
+			{Name: "help-now", Match: config.MailMatch{Subject: "^HELP:"}, Delivery: "interrupt"},
+		},
+		"*/witness": {
+			{Name: "failed", Match: config.MailMatch{Protocol: "merge_failed"}, Forward: []string{"mayor/"}},
+		},
+		"*": {
+			{Name: "quiet", Match: config.MailMatch{Priority: "low"}, Delivery: "queue"},
+			{Name: "bug", Match: config.MailMatch{From: "gastown/polecats/*", Body: "(?i)panic"}, Bead: "bug"},
+		},
+	}}
+
+	tests := []struct {
+		name string
+		msg  *Message
+		want *RuleResult
+	}{
+		{
+			name: "protocol archive",
+			msg:  &Message{From: "gastown/witness", To: "mayor/", Subject: "POLECAT_DONE nux"},
+			want: &RuleResult{Mailbox: "mayor/", Protocol: "polecat_done", Matched: []string{"done"},
+				Archive: true, Labels: []string{"rule:done", "noise"}},
+		},
+		{
+			name: "first match stops",
+			msg:  &Message{From: "overseer", To: "mayor", Subject: "POLECAT_DONE nux"},
+			want: &RuleResult{Mailbox: "mayor/", Protocol: "polecat_done", Matched: []string{"overseer"},
+				Labels: []string{"rule:overseer"}},
+		},
+		{
+			name: "continue accumulates",
+			msg:  &Message{From: "gastown/polecats/nux", To: "mayor/", Subject: "HELP: stuck", Priority: PriorityUrgent},
+			want: &RuleResult{Mailbox: "mayor/", Protocol: "help", Matched: []string{"help", "help-now"},
+				Labels: []string{"rule:help", "rule:help-now"}, Escalate: "high", Delivery: DeliveryInterrupt},
+		},
+		{
+			name: "priority below threshold",
+			msg:  &Message{From: "gastown/polecats/nux", To: "mayor/", Subject: "HELP: stuck", Priority: PriorityNormal},
+			want: &RuleResult{Mailbox: "mayor/", Protocol: "help", Matched: []string{"help-now"},
+				Labels: []string{"rule:help-now"}, Delivery: DeliveryInterrupt},
+		},
+		{
+			name: "wildcard mailbox",
+			msg:  &Message{From: "gastown/refinery", To: "gastown/witness", Subject: "MERGE_FAILED nux"},
+			want: &RuleResult{Mailbox: "gastown/witness", Protocol: "merge_failed", Matched: []string{"failed"},
+				Labels: []string{"rule:failed"}, Forward: []string{"mayor/"}},
+		},
+		{
+			name: "catch-all with canonical sender",
+			msg:  &Message{From: "gastown/nux", To: "gastown/crew/max", Subject: "Crash", Body: "PANIC: nil map"},
+			want: &RuleResult{Mailbox: "gastown/max", Protocol: ProtocolNone, Matched: []string{"bug"},
+				Labels: []string{"rule:bug"}, Bead: "bug"},
+		},
+		{
+			name: "no match",
+			msg:  &Message{From: "deacon/", To: "gastown/witness", Subject: "Patrol"},
+			want: &RuleResult{Mailbox: "gastown/witness", Protocol: ProtocolNone},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EvaluateRules(cfg, tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EvaluateRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMailboxRules_Order(t *testing.T) {
+	cfg := &config.MessagingConfig{Rules: map[string][]config.MailRule{
+		"*":                {{Name: "all"}},
+		"gastown/*":        {{Name: "rig"}},
+		"*/witness":        {{Name: "witnesses"}},
+		"gastown/witness/": {{Name: "own"}},
+		"mayor/":           {{Name: "mayor"}},
+	}}
+	var names []string
+	for _, r := range MailboxRules(cfg, "gastown/witness") {
+		names = append(names, r.Name)
+	}
+	want := []string{"own", "witnesses", "rig", "all"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("MailboxRules = %v, want %v", names, want)
+	}
+}
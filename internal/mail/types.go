@@ -123,6 +123,33 @@ type Message struct {
 	// ClaimedAt is when the queue message was claimed.
 	// Only set for queue messages after claiming.
 	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
+
+	// AckRequired asks the recipient to acknowledge with gt mail ack.
+	// Unacknowledged mail is re-delivered as an interrupt after AckWithin
+	// (DefaultAckWithin when zero), then escalated after another AckWithin.
+	AckRequired bool          `json:"ack_required,omitempty"`
+	AckWithin   time.Duration `json:"ack_within,omitempty"`
+
+	// AckedAt is when the recipient acknowledged the message.
+	AckedAt *time.Time `json:"acked_at,omitempty"`
+
+	// AckRedeliveredAt and AckEscalatedAt record the follow-ups taken on
+	// an unacknowledged message.
+	AckRedeliveredAt *time.Time `json:"ack_redelivered_at,omitempty"`
+	AckEscalatedAt   *time.Time `json:"ack_escalated_at,omitempty"`
+
+	// List is the mailing list a copy was fanned out from. ListSend
+	// identifies the send and is shared by every member's copy.
+	List     string `json:"list,omitempty"`
+	ListSend string `json:"list_send,omitempty"`
+
+	// skipRules delivers without applying mailbox rules (rule forwards
+	// and escalations).
+	skipRules bool
+
+	// digest marks digest mail, which is never held for a digest and
+	// never nudges a muted recipient.
+	digest bool
 }
 
 // NewMessage creates a new message with a generated ID and thread ID.
@@ -290,6 +317,9 @@ type BeadsMessage struct {
 	channel   string     // Channel name (for broadcast messages)
 	claimedBy string     // Who claimed the queue message
 	claimedAt *time.Time // When the queue message was claimed
+	ack       ackState   // Acknowledgement labels (ack-required, acked:X, ...)
+	list      string     // Mailing list the copy was fanned out from
+	listSend  string     // Fan-out ID shared by the list's copies
 }
 
 // ParseLabels extracts metadata from the labels array.
@@ -316,6 +346,12 @@ func (bm *BeadsMessage) ParseLabels() {
 			if t, err := time.Parse(time.RFC3339, ts); err == nil {
 				bm.claimedAt = &t
 			}
+		} else if strings.HasPrefix(label, "list:") {
+			bm.list = strings.TrimPrefix(label, "list:")
+		} else if strings.HasPrefix(label, "list-send:") {
+			bm.listSend = strings.TrimPrefix(label, "list-send:")
+		} else {
+			bm.ack.parseLabel(label)
 		}
 	}
 }
@@ -384,6 +420,15 @@ func (bm *BeadsMessage) ToMessage() *Message {
 		Channel:   bm.channel,
 		ClaimedBy: bm.claimedBy,
 		ClaimedAt: bm.claimedAt,
+
+		AckRequired:      bm.ack.required,
+		AckWithin:        bm.ack.within,
+		AckedAt:          bm.ack.ackedAt,
+		AckRedeliveredAt: bm.ack.redeliveredAt,
+		AckEscalatedAt:   bm.ack.escalatedAt,
+
+		List:     bm.list,
+		ListSend: bm.listSend,
 	}
 }
 
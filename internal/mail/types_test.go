@@ -622,6 +622,23 @@ func TestBeadsMessageParseChannelLabel(t *testing.T) {
 	}
 }
 
+func TestBeadsMessageParseListLabels(t *testing.T) {
+	bm := BeadsMessage{
+		ID:       "hq-list",
+		Assignee: "mayor/",
+		Labels:   []string{"from:deacon/", "msg-type:reply", "list:oncall", "list-send:msg-abc"},
+	}
+
+	msg := bm.ToMessage()
+
+	if msg.List != "oncall" || msg.ListSend != "msg-abc" {
+		t.Errorf("List = %q, ListSend = %q, want oncall, msg-abc", msg.List, msg.ListSend)
+	}
+	if msg.Type != TypeReply {
+		t.Errorf("Type = %q, want reply", msg.Type)
+	}
+}
+
 func TestBeadsMessageIsQueueMessage(t *testing.T) {
 	queueMsg := BeadsMessage{
 		ID:     "hq-queue",
@@ -0,0 +1,193 @@
+package mockagent
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeExec records commands and answers gt hook show / gt mol current from a
+// script of canned outputs.
+type fakeExec struct {
+	calls []string
+	steps []string // current_step_id answers, in order
+}
+
+func (f *fakeExec) run(dir string, env []string, name string, args ...string) (string, error) {
+	call := strings.Join(append([]string{name}, args...), " ")
+	f.calls = append(f.calls, call)
+	switch call {
+	case "gt hook show --json":
+		return `{"agent":"gastown/polecats/Toast","bead_id":"gt-abc","title":"Fix login","status":"hooked"}`, nil
+	case "gt mol current --json":
+		if len(f.steps) == 0 {
+			return `{"status":"complete"}`, nil
+		}
+		id := f.steps[0]
+		f.steps = f.steps[1:]
+		return `{"current_step_id":"` + id + `","status":"working"}`, nil
+	}
+	return "", nil
+}
+
+func TestParseValidates(t *testing.T) {
+	s, err := Parse([]byte(`
+name = "happy"
+
+[[step]]
+action = "prime"
+
+[[step]]
+action = "commit"
+message = "Fix $MOCK_HOOK"
+files = { "fix.txt" = "fixed\n" }
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if s.Name != "happy" || len(s.Steps) != 2 || s.Steps[1].Files["fix.txt"] != "fixed\n" {
+		t.Errorf("scenario = %+v", s)
+	}
+
+	for _, bad := range []string{
+		`[[step]]` + "\n" + `action = "teleport"`,
+		`[[step]]` + "\n" + `action = "wait"` + "\n" + `match = "("`,
+		`[[step]]` + "\n" + `action = "sleep"` + "\n" + `duration = "soon"`,
+		`[[step]]` + "\n" + `action = "commit"`,
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("Parse accepted %q", bad)
+		}
+	}
+}
+
+func TestRunHappyPath(t *testing.T) {
+	dir := t.TempDir()
+	fake := &fakeExec{steps: []string{"gt-abc.1", "gt-abc.2"}}
+	var out bytes.Buffer
+	r := &Runner{
+		Scenario: &Scenario{Steps: []Step{
+			{Action: ActionPrime},
+			{Action: ActionWait, Match: "hook", Text: "on it"},
+			{Action: ActionCompleteSteps},
+			{Action: ActionCommit, Message: "Fix $MOCK_HOOK", Files: map[string]string{"src/fix.txt": "$MOCK_HOOK_TITLE\n"}},
+			{Action: ActionDone},
+			{Action: ActionSay, Text: "unreachable"},
+		}},
+		In:   strings.NewReader("hello\nCheck your hook\n"),
+		Out:  &out,
+		Dir:  dir,
+		Exec: fake.run,
+	}
+
+	if code := r.Run(); code != 0 {
+		t.Fatalf("Run = %d, want 0\n%s", code, out.String())
+	}
+	want := []string{
+		"gt prime",
+		"gt hook show --json",
+		"gt mol current --json", "bd close gt-abc.1",
+		"gt mol current --json", "bd close gt-abc.2",
+		"gt mol current --json",
+		"git add -A", "git commit --allow-empty -m Fix gt-abc",
+		"gt done",
+	}
+	if strings.Join(fake.calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls:\n%s\nwant:\n%s", strings.Join(fake.calls, "\n"), strings.Join(want, "\n"))
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "src", "fix.txt"))
+	if err != nil || string(data) != "Fix login\n" {
+		t.Errorf("fix.txt = %q, %v", data, err)
+	}
+	for _, s := range []string{Prompt, "ignoring nudge", "on it"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("output missing %q:\n%s", s, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "unreachable") {
+		t.Error("scenario continued after gt done")
+	}
+}
+
+func TestRunCrashAndHelp(t *testing.T) {
+	t.Setenv("GT_RIG", "gastown")
+	fake := &fakeExec{}
+	r := &Runner{
+		Scenario: &Scenario{Steps: []Step{
+			{Action: ActionHelp, Text: "tests fail"},
+			{Action: ActionCrash, ExitCode: 3},
+		}},
+		In:   strings.NewReader(""),
+		Out:  io.Discard,
+		Exec: fake.run,
+	}
+	if code := r.Run(); code != 3 {
+		t.Errorf("Run = %d, want 3", code)
+	}
+	if len(fake.calls) != 1 || !strings.HasPrefix(fake.calls[0], "gt mail send gastown/witness -s HELP: tests fail") {
+		t.Errorf("calls = %q", fake.calls)
+	}
+}
+
+func TestCommitRejectsEscapingPaths(t *testing.T) {
+	r := &Runner{
+		Scenario: &Scenario{Steps: []Step{
+			{Action: ActionCommit, Message: "x", Files: map[string]string{"../evil": "x"}},
+		}},
+		In:   strings.NewReader(""),
+		Out:  io.Discard,
+		Dir:  t.TempDir(),
+		Exec: (&fakeExec{}).run,
+	}
+	r.Run()
+	if _, err := os.Stat(filepath.Join(filepath.Dir(r.Dir), "evil")); err == nil {
+		t.Error("commit wrote outside the worktree")
+	}
+}
+
+func TestIdleExitsOnEOF(t *testing.T) {
+	var out bytes.Buffer
+	r := &Runner{
+		Scenario: &Scenario{},
+		In:       strings.NewReader("are you there?\n"),
+		Out:      &out,
+		Exec:     (&fakeExec{}).run,
+	}
+	if code := r.Run(); code != 0 {
+		t.Errorf("Run = %d, want 0", code)
+	}
+	if !strings.Contains(out.String(), "idle") {
+		t.Errorf("nudge not acknowledged:\n%s", out.String())
+	}
+}
+
+func TestResolvePerRole(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, scenario string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(scenario), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("polecat.toml", `name = "polecat"`)
+	write("default.toml", `name = "default"`)
+
+	for role, want := range map[string]string{
+		"gastown/polecats/Toast": "polecat",
+		"gastown/witness":        "default",
+	} {
+		s, err := Resolve(dir, role)
+		if err != nil || s.Name != want {
+			t.Errorf("Resolve(%q) = %+v, %v; want %q", role, s, err, want)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(dir, "default.toml")); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := Resolve(dir, "mayor"); err != nil || s.Name != "idle" {
+		t.Errorf("Resolve(mayor) = %+v, %v; want the idle default", s, err)
+	}
+}
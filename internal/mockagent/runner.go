@@ -0,0 +1,374 @@
+package mockagent
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// forever stands in for an unbounded hang. A live timer also keeps the Go
+// runtime from reporting a deadlock while the agent ignores its input.
+const forever = time.Duration(1<<63 - 1)
+
+// ExecFunc runs a command in dir with extra environment variables and
+// returns its combined output.
+type ExecFunc func(dir string, env []string, name string, args ...string) (string, error)
+
+// Runner plays a scenario against a terminal.
+//
+// While it runs it exports the hooked work it discovered to the environment
+// of its commands and to $VAR expansion in step text:
+//
+//	MOCK_HOOK       hooked bead ID (after prime)
+//	MOCK_HOOK_TITLE hooked bead title
+//	MOCK_STEP       molecule step most recently completed
+//	MOCK_NUDGE      text of the last nudge received
+//	MOCK_PROMPT     initial prompt the agent was started with
+type Runner struct {
+	Scenario *Scenario
+	In       io.Reader
+	Out      io.Writer
+	// Dir is the agent's working directory (its worktree).
+	Dir string
+	// InitialPrompt is the startup prompt (beacon) passed on the command line.
+	InitialPrompt string
+	// Exec runs commands; defaults to running them for real.
+	Exec ExecFunc
+
+	vars  map[string]string
+	lines chan string
+}
+
+// Run plays the scenario and returns the exit status the agent should
+// exit with. It returns when the scenario exits, or when input closes
+// while the agent is waiting for it.
+func (r *Runner) Run() int {
+	if r.Exec == nil {
+		r.Exec = defaultExec
+	}
+	if r.Scenario == nil {
+		r.Scenario = DefaultScenario()
+	}
+	r.vars = map[string]string{"MOCK_PROMPT": r.InitialPrompt}
+	r.lines = make(chan string)
+	go r.readInput()
+
+	name := r.Scenario.Name
+	if name == "" {
+		name = "unnamed"
+	}
+	r.printf("mock agent: scenario %q (%d steps)\n", name, len(r.Scenario.Steps))
+	if r.InitialPrompt != "" {
+		r.printf("%s%s\n", Prompt, r.InitialPrompt)
+	}
+
+	for i, step := range r.Scenario.Steps {
+		r.printf("● [%d/%d] %s\n", i+1, len(r.Scenario.Steps), step.Action)
+		code, exit, err := r.runStep(step)
+		if err != nil {
+			r.printf("  ✗ %v\n", err)
+		}
+		if exit {
+			return code
+		}
+	}
+	return r.idle()
+}
+
+// runStep executes one step. exit reports whether the agent should exit
+// with code; errors are reported but do not stop the scenario, matching an
+// agent that notices a failed command and moves on.
+func (r *Runner) runStep(s Step) (code int, exit bool, err error) {
+	switch s.Action {
+	case ActionPrime:
+		return 0, false, r.prime()
+	case ActionWait:
+		return r.wait(s)
+	case ActionSay:
+		r.printf("%s\n", r.expand(s.Text))
+	case ActionRun:
+		return 0, false, r.exec("sh", "-c", r.expand(s.Command))
+	case ActionCompleteSteps:
+		return 0, false, r.completeSteps(s.Count)
+	case ActionCommit:
+		return 0, false, r.commit(s)
+	case ActionDone:
+		if err := r.exec("gt", append([]string{"done"}, s.Args...)...); err != nil {
+			return 1, true, err
+		}
+		return 0, true, nil
+	case ActionHelp:
+		return 0, false, r.help(s)
+	case ActionEscalate:
+		severity := s.Severity
+		if severity == "" {
+			severity = "medium"
+		}
+		return 0, false, r.exec("gt", "escalate", r.expand(s.Text), "--severity", severity, "--reason", r.expand(s.Message))
+	case ActionSleep:
+		d, _ := time.ParseDuration(s.Duration)
+		time.Sleep(d)
+	case ActionHang:
+		return r.hang(s)
+	case ActionCrash:
+		if s.Text != "" {
+			r.printf("%s\n", r.expand(s.Text))
+		}
+		code := s.ExitCode
+		if code == 0 {
+			code = 1
+		}
+		r.printf("mock agent: crashing with exit code %d\n", code)
+		return code, true, nil
+	case ActionExit:
+		if s.Text != "" {
+			r.printf("%s\n", r.expand(s.Text))
+		}
+		return 0, true, nil
+	}
+	return 0, false, nil
+}
+
+// prime loads the agent's context the way a session-start hook would and
+// records what is on its hook.
+func (r *Runner) prime() error {
+	if err := r.exec("gt", "prime"); err != nil {
+		return err
+	}
+	out, err := r.Exec(r.Dir, r.environ(), "gt", "hook", "show", "--json")
+	if err != nil {
+		return fmt.Errorf("gt hook show: %w", err)
+	}
+	var hook struct {
+		BeadID string `json:"bead_id"`
+		Title  string `json:"title"`
+	}
+	if err := decodeJSON(out, &hook); err != nil {
+		return fmt.Errorf("parsing gt hook show output: %w", err)
+	}
+	r.vars["MOCK_HOOK"] = hook.BeadID
+	r.vars["MOCK_HOOK_TITLE"] = hook.Title
+	if hook.BeadID == "" {
+		r.printf("  hook is empty\n")
+	} else {
+		r.printf("  hooked: %s %s\n", hook.BeadID, hook.Title)
+	}
+	return nil
+}
+
+// wait shows the prompt and blocks until a nudge matching s.Match arrives.
+func (r *Runner) wait(s Step) (int, bool, error) {
+	var re *regexp.Regexp
+	if s.Match != "" {
+		re = regexp.MustCompile(s.Match) // validated on load
+	}
+	var timeout <-chan time.Time
+	if s.Timeout != "" {
+		d, _ := time.ParseDuration(s.Timeout)
+		timeout = time.After(d)
+	}
+
+	r.printf("%s", Prompt)
+	for {
+		select {
+		case line, ok := <-r.lines:
+			if !ok {
+				return 0, true, nil
+			}
+			r.vars["MOCK_NUDGE"] = line
+			if re != nil && !re.MatchString(line) {
+				r.printf("  (ignoring nudge, waiting for /%s/)\n%s", s.Match, Prompt)
+				continue
+			}
+			if s.Text != "" {
+				r.printf("%s\n", r.expand(s.Text))
+			}
+			return 0, false, nil
+		case <-timeout:
+			r.printf("\n  (no nudge after %s, continuing)\n", s.Timeout)
+			return 0, false, nil
+		}
+	}
+}
+
+// hang stops responding: input is swallowed without acknowledgement, the way
+// a stuck agent looks to the Witness.
+func (r *Runner) hang(s Step) (int, bool, error) {
+	d := forever
+	if s.Duration != "" {
+		d, _ = time.ParseDuration(s.Duration)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case _, ok := <-r.lines:
+			if !ok {
+				// Input closed; keep hanging without it.
+				r.lines = nil
+			}
+		case <-timer.C:
+			return 0, false, nil
+		}
+	}
+}
+
+// idle keeps the agent at its prompt after the scenario ends, acknowledging
+// nudges until input closes.
+func (r *Runner) idle() int {
+	r.printf("%s", Prompt)
+	for line := range r.lines {
+		r.vars["MOCK_NUDGE"] = line
+		r.printf("  (mock agent idle, scenario complete)\n%s", Prompt)
+	}
+	return 0
+}
+
+// completeSteps closes the current molecule step until count steps are
+// done or the molecule has no ready step left.
+func (r *Runner) completeSteps(count int) error {
+	for n := 0; count == 0 || n < count; n++ {
+		out, err := r.Exec(r.Dir, r.environ(), "gt", "mol", "current", "--json")
+		if err != nil {
+			return fmt.Errorf("gt mol current: %w", err)
+		}
+		var cur struct {
+			CurrentStepID string `json:"current_step_id"`
+			CurrentStep   string `json:"current_step"`
+		}
+		if err := decodeJSON(out, &cur); err != nil {
+			return fmt.Errorf("parsing gt mol current output: %w", err)
+		}
+		if cur.CurrentStepID == "" || cur.CurrentStepID == r.vars["MOCK_STEP"] {
+			if n == 0 {
+				r.printf("  no ready molecule step\n")
+			}
+			return nil
+		}
+		r.printf("  working on %s %s\n", cur.CurrentStepID, cur.CurrentStep)
+		if err := r.exec("bd", "close", cur.CurrentStepID); err != nil {
+			return err
+		}
+		r.vars["MOCK_STEP"] = cur.CurrentStepID
+	}
+	return nil
+}
+
+// commit writes the step's files into the worktree and commits everything.
+func (r *Runner) commit(s Step) error {
+	paths := make([]string, 0, len(s.Files))
+	for p := range s.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if filepath.IsAbs(p) || strings.HasPrefix(filepath.Clean(p), "..") {
+			return fmt.Errorf("file %q is outside the worktree", p)
+		}
+		full := filepath.Join(r.Dir, p)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(full, []byte(r.expand(s.Files[p])), 0644); err != nil { //nolint:gosec // G306: worktree file
+			return err
+		}
+		r.printf("  wrote %s\n", p)
+	}
+	if err := r.exec("git", "add", "-A"); err != nil {
+		return err
+	}
+	return r.exec("git", "commit", "--allow-empty", "-m", r.expand(s.Message))
+}
+
+// help mails the rig's Witness, as a stuck polecat is told to. Agents
+// outside a rig escalate instead.
+func (r *Runner) help(s Step) error {
+	subject := "HELP: " + r.expand(s.Text)
+	rig := os.Getenv("GT_RIG")
+	if rig == "" {
+		return r.exec("gt", "escalate", subject, "--reason", r.expand(s.Message))
+	}
+	body := r.expand(s.Message)
+	if body == "" {
+		body = "Issue: " + r.vars["MOCK_HOOK"]
+	}
+	return r.exec("gt", "mail", "send", rig+"/witness", "-s", subject, "-m", body)
+}
+
+// exec runs a command, echoing it and its output like an agent's tool call.
+func (r *Runner) exec(name string, args ...string) error {
+	r.printf("  $ %s\n", strings.Join(append([]string{name}, args...), " "))
+	out, err := r.Exec(r.Dir, r.environ(), name, args...)
+	if out = strings.TrimRight(out, "\n"); out != "" {
+		for _, line := range strings.Split(out, "\n") {
+			r.printf("    %s\n", line)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// expand substitutes $VARS from the runner's variables, then the environment.
+func (r *Runner) expand(s string) string {
+	return os.Expand(s, func(key string) string {
+		if v, ok := r.vars[key]; ok {
+			return v
+		}
+		return os.Getenv(key)
+	})
+}
+
+func (r *Runner) readInput() {
+	defer close(r.lines)
+	scanner := bufio.NewScanner(r.In)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			r.lines <- line
+		}
+	}
+}
+
+// decodeJSON decodes command output that may carry warnings on stderr
+// ahead of the JSON document.
+func decodeJSON(out string, v interface{}) error {
+	if i := strings.Index(out, "{"); i > 0 {
+		out = out[i:]
+	}
+	return json.Unmarshal([]byte(out), v)
+}
+
+func (r *Runner) printf(format string, args ...interface{}) {
+	fmt.Fprintf(r.Out, format, args...)
+}
+
+// environ returns the MOCK_* variables in os/exec form.
+func (r *Runner) environ() []string {
+	keys := make([]string, 0, len(r.vars))
+	for k := range r.vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys))
+	for _, k := range keys {
+		env = append(env, k+"="+r.vars[k])
+	}
+	return env
+}
+
+func defaultExec(dir string, env []string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...) //nolint:gosec // G204: scenario-driven by design
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
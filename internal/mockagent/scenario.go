@@ -0,0 +1,248 @@
+// Package mockagent implements a deterministic, scriptable fake agent runtime.
+//
+// The mock agent stands in for an LLM CLI inside a tmux session so whole-town
+// flows (polecat work, Witness patrols, Refinery merges) can be exercised in
+// CI without API keys or network. It behaves like a real agent from the
+// outside: it prints the runtime prompt that WaitForRuntimeReady looks for,
+// reads nudges from its terminal, runs gt/bd/git commands and exits the way a
+// real agent would. What it does is fixed by a scenario file.
+package mockagent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ScenarioEnv names the environment variable holding the scenario file path.
+const ScenarioEnv = "GT_MOCK_SCENARIO"
+
+// Prompt is the prompt printed whenever the mock agent waits for input.
+// It matches Claude Code's prompt so the default ready detection works.
+const Prompt = "❯ "
+
+// Step actions.
+const (
+	// ActionPrime runs "gt prime" and records the hooked work.
+	ActionPrime = "prime"
+	// ActionWait blocks until a nudge (a line of input) arrives.
+	ActionWait = "wait"
+	// ActionSay prints text.
+	ActionSay = "say"
+	// ActionRun runs a shell command in the agent's working directory.
+	ActionRun = "run"
+	// ActionCompleteSteps closes molecule steps in order.
+	ActionCompleteSteps = "complete-steps"
+	// ActionCommit writes files and commits them.
+	ActionCommit = "commit"
+	// ActionDone runs "gt done" and exits.
+	ActionDone = "done"
+	// ActionHelp asks the rig's Witness for help by mail.
+	ActionHelp = "help"
+	// ActionEscalate files an escalation.
+	ActionEscalate = "escalate"
+	// ActionSleep pauses for a duration.
+	ActionSleep = "sleep"
+	// ActionHang stops responding, ignoring all input.
+	ActionHang = "hang"
+	// ActionCrash exits with a non-zero status.
+	ActionCrash = "crash"
+	// ActionExit exits cleanly.
+	ActionExit = "exit"
+)
+
+// Scenario is a script for the mock agent, loaded from TOML:
+//
+//	name = "polecat-happy-path"
+//
+//	[[step]]
+//	action = "prime"
+//
+//	[[step]]
+//	action = "commit"
+//	message = "Fix $MOCK_HOOK"
+//	files = { "fix.txt" = "fixed\n" }
+//
+//	[[step]]
+//	action = "done"
+//
+// Steps run in order. When they run out, the agent idles at its prompt and
+// acknowledges nudges until its input closes.
+type Scenario struct {
+	Name        string `toml:"name"`
+	Description string `toml:"description"`
+	Steps       []Step `toml:"step"`
+}
+
+// Step is one scripted action. Which fields apply depends on Action.
+// Text fields have $VARS expanded from the environment, including the
+// MOCK_* variables set by prime (see Runner).
+type Step struct {
+	Action string `toml:"action"`
+
+	// Text is printed by say, wait (as the reply), crash and exit; it is the
+	// subject for help and the description for escalate.
+	Text string `toml:"text"`
+
+	// Match is a regular expression a nudge must match to end a wait.
+	// Non-matching nudges are acknowledged and ignored. Empty matches any.
+	Match string `toml:"match"`
+
+	// Timeout bounds a wait; on expiry the scenario continues (e.g., "30s").
+	// Duration is how long sleep and hang last; hang defaults to forever.
+	Timeout  string `toml:"timeout"`
+	Duration string `toml:"duration"`
+
+	// Command is the shell command for run.
+	Command string `toml:"command"`
+
+	// Files maps worktree-relative paths to contents for commit.
+	// Message is the commit message (or the mail body for help).
+	Files   map[string]string `toml:"files"`
+	Message string            `toml:"message"`
+
+	// Count limits complete-steps; 0 completes every remaining step.
+	Count int `toml:"count"`
+
+	// Args are extra arguments for done (e.g., ["--status", "ESCALATED"]).
+	Args []string `toml:"args"`
+
+	// Severity is the escalation severity (default "medium").
+	Severity string `toml:"severity"`
+
+	// ExitCode is the status for crash (default 1).
+	ExitCode int `toml:"exit_code"`
+}
+
+// DefaultScenario primes and then idles, like an agent with nothing to do.
+func DefaultScenario() *Scenario {
+	return &Scenario{
+		Name:  "idle",
+		Steps: []Step{{Action: ActionPrime}},
+	}
+}
+
+// Load reads and validates a scenario file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is operator-supplied
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Resolve loads the scenario for an agent. path may be a file, or a
+// directory holding per-role scenarios: <role>.toml is used if present,
+// then default.toml, then DefaultScenario. gtRole is the agent's GT_ROLE.
+func Resolve(path, gtRole string) (*Scenario, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return Load(path)
+	}
+	for _, name := range []string{simpleRole(gtRole), "default"} {
+		if name == "" {
+			continue
+		}
+		s, err := Load(filepath.Join(path, name+".toml"))
+		if err == nil || !os.IsNotExist(err) {
+			return s, err
+		}
+	}
+	return DefaultScenario(), nil
+}
+
+// simpleRole reduces a GT_ROLE such as "gastown/polecats/Toast" to its
+// role name ("polecat").
+func simpleRole(gtRole string) string {
+	parts := strings.Split(gtRole, "/")
+	switch len(parts) {
+	case 1:
+		return parts[0]
+	case 2:
+		return parts[1]
+	default:
+		return strings.TrimSuffix(parts[1], "s")
+	}
+}
+
+// Parse decodes and validates a TOML scenario.
+func Parse(data []byte) (*Scenario, error) {
+	var s Scenario
+	if _, err := toml.Decode(string(data), &s); err != nil {
+		return nil, fmt.Errorf("parsing scenario: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate checks that every step has a known action and well-formed fields.
+func (s *Scenario) Validate() error {
+	for i, step := range s.Steps {
+		if err := step.validate(); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Action, err)
+		}
+	}
+	return nil
+}
+
+func (s Step) validate() error {
+	switch s.Action {
+	case ActionPrime, ActionDone, ActionHang, ActionExit, ActionCompleteSteps:
+	case ActionWait:
+		if s.Match != "" {
+			if _, err := regexp.Compile(s.Match); err != nil {
+				return fmt.Errorf("invalid match: %w", err)
+			}
+		}
+	case ActionSay:
+		if s.Text == "" {
+			return fmt.Errorf("text is required")
+		}
+	case ActionRun:
+		if s.Command == "" {
+			return fmt.Errorf("command is required")
+		}
+	case ActionCommit:
+		if s.Message == "" {
+			return fmt.Errorf("message is required")
+		}
+	case ActionHelp, ActionEscalate:
+		if s.Text == "" {
+			return fmt.Errorf("text is required")
+		}
+	case ActionSleep:
+		if s.Duration == "" {
+			return fmt.Errorf("duration is required")
+		}
+	case ActionCrash:
+		if s.ExitCode < 0 {
+			return fmt.Errorf("exit_code must not be negative")
+		}
+	case "":
+		return fmt.Errorf("action is required")
+	default:
+		return fmt.Errorf("unknown action")
+	}
+	for name, v := range map[string]string{"timeout": s.Timeout, "duration": s.Duration} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	if s.Count < 0 {
+		return fmt.Errorf("count must not be negative")
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -27,6 +28,7 @@ var (
 	ErrHasChanges         = errors.New("polecat has uncommitted changes")
 	ErrHasUncommittedWork = errors.New("polecat has uncommitted work")
 	ErrShellInWorktree    = errors.New("shell working directory is inside polecat worktree")
+	ErrNoWarmPolecat      = errors.New("no warm polecat available")
 )
 
 // UncommittedWorkError provides details about uncommitted work.
@@ -402,15 +404,7 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 
 	// Ensure AGENTS.md exists - critical for polecats to "land the plane"
 	// Fall back to copy from mayor/rig if not in git (e.g., stale fetch, local-only file)
-	agentsMDPath := filepath.Join(clonePath, "AGENTS.md")
-	if _, err := os.Stat(agentsMDPath); os.IsNotExist(err) {
-		srcPath := filepath.Join(m.rig.Path, "mayor", "rig", "AGENTS.md")
-		if srcData, readErr := os.ReadFile(srcPath); readErr == nil {
-			if writeErr := os.WriteFile(agentsMDPath, srcData, 0644); writeErr != nil {
-				fmt.Printf("Warning: could not copy AGENTS.md: %v\n", writeErr)
-			}
-		}
-	}
+	m.ensureAgentsMD(clonePath)
 
 	// NOTE: We intentionally do NOT write to CLAUDE.md here.
 	// Gas Town context is injected ephemerally via SessionStart hook (gt prime).
@@ -440,6 +434,9 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
 
+	// Materialize file secrets scoped to this rig's polecats
+	m.materializeSecrets(name, clonePath)
+
 	// Ensure .gitignore has required Gas Town patterns
 	if err := rig.EnsureGitignorePatterns(clonePath); err != nil {
 		fmt.Printf("Warning: could not update .gitignore: %v\n", err)
@@ -573,6 +570,10 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool)
 		}
 	}
 
+	// Delete materialized secrets before the files go, so the ledger and
+	// audit log record the scrub
+	m.scrubSecrets(name, clonePath)
+
 	// Get repo base to remove the worktree properly
 	repoGit, err := m.repoBase()
 	if err != nil {
@@ -705,6 +706,78 @@ func (m *Manager) AllocateName() (string, error) {
 	return name, nil
 }
 
+// AllocateNamed allocates a specific free name from the name pool.
+func (m *Manager) AllocateNamed(name string) error {
+	m.ReconcilePool()
+
+	if err := m.namePool.Claim(name); err != nil {
+		return err
+	}
+	if err := m.namePool.Save(); err != nil {
+		return fmt.Errorf("saving pool state: %w", err)
+	}
+	return nil
+}
+
+// AllocatePreferred allocates name if it is still free, else the next free
+// name from NameSlots, else an overflow name as AllocateName does. A name
+// picked from NameSlots can be taken by a concurrent spawn before it is
+// allocated; the loser moves on rather than failing.
+func (m *Manager) AllocatePreferred(name string) (string, error) {
+	if err := m.AllocateNamed(name); err == nil {
+		return name, nil
+	}
+	for _, slot := range m.NameSlots() {
+		if slot.Name == name || slot.Busy || slot.Warm {
+			continue
+		}
+		if err := m.AllocateNamed(slot.Name); err == nil {
+			return slot.Name, nil
+		}
+	}
+	return m.AllocateName()
+}
+
+// NameSlot is a pool name and whether a new polecat could get it.
+type NameSlot struct {
+	Name string `json:"name"`
+	Warm bool   `json:"warm,omitempty"` // held by a warm worktree, claimable with ClaimWarmNamed
+	Busy bool   `json:"busy,omitempty"` // a polecat has it
+}
+
+// NameSlots returns the pool's names in allocation order with their state.
+// Unlike AllocateName it changes nothing on disk or in tmux.
+func (m *Manager) NameSlots() []NameSlot {
+	polecats := make(map[string]bool)
+	if list, err := m.List(); err == nil {
+		for _, p := range list {
+			polecats[p.Name] = true
+		}
+	}
+	// Warm entries hold their names until claimed, or pruned if broken
+	held := make(map[string]bool)
+	for _, name := range m.warmNames() {
+		held[name] = true
+	}
+	warm := make(map[string]bool)
+	if list, err := m.ListWarm(); err == nil {
+		for _, w := range list {
+			warm[w.Name] = !polecats[w.Name]
+		}
+	}
+
+	names := m.namePool.Names()
+	slots := make([]NameSlot, 0, len(names))
+	for _, name := range names {
+		slots = append(slots, NameSlot{
+			Name: name,
+			Warm: warm[name],
+			Busy: polecats[name] || (held[name] && !warm[name]),
+		})
+	}
+	return slots
+}
+
 // ReleaseName releases a name back to the pool.
 // This is called when a polecat is removed.
 func (m *Manager) ReleaseName(name string) {
@@ -767,6 +840,8 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 		}
 	}
 
+	m.scrubSecrets(name, oldClonePath)
+
 	// Remove the old worktree (use force for git worktree removal)
 	if err := repoGit.WorktreeRemove(oldClonePath, true); err != nil {
 		// Fall back to direct removal
@@ -827,6 +902,9 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
 
+	// Materialize file secrets scoped to this rig's polecats
+	m.materializeSecrets(name, newClonePath)
+
 	// Ensure .gitignore has required Gas Town patterns
 	if err := rig.EnsureGitignorePatterns(newClonePath); err != nil {
 		fmt.Printf("Warning: could not update .gitignore: %v\n", err)
@@ -878,6 +956,9 @@ func (m *Manager) ReconcilePool() {
 		namesWithDirs = append(namesWithDirs, p.Name)
 	}
 
+	// Warm pool worktrees hold their names until claimed
+	namesWithDirs = append(namesWithDirs, m.warmNames()...)
+
 	// Get names with tmux sessions
 	var namesWithSessions []string
 	if m.tmux != nil {
@@ -1179,6 +1260,23 @@ func (m *Manager) loadFromBeads(name string) (*Polecat, error) {
 	}, nil
 }
 
+// materializeSecrets writes the polecat's file secrets into its worktree.
+// Non-fatal: a spawn without secrets is better than no spawn.
+func (m *Manager) materializeSecrets(name, clonePath string) {
+	townRoot := filepath.Dir(m.rig.Path)
+	if _, err := secrets.Materialize(townRoot, m.rig.Name, "polecat", m.assigneeID(name), clonePath); err != nil {
+		fmt.Printf("Warning: could not materialize secrets: %v\n", err)
+	}
+}
+
+// scrubSecrets deletes secret files materialized into a polecat's worktree.
+func (m *Manager) scrubSecrets(name, clonePath string) {
+	townRoot := filepath.Dir(m.rig.Path)
+	if _, err := secrets.Scrub(townRoot, clonePath, m.assigneeID(name)); err != nil {
+		fmt.Printf("Warning: could not scrub secrets: %v\n", err)
+	}
+}
+
 // setupSharedBeads creates a redirect file so the polecat uses the rig's shared .beads database.
 // This eliminates the need for git sync between polecat clones - all polecats share one database.
 func (m *Manager) setupSharedBeads(clonePath string) error {
@@ -262,6 +262,39 @@ func (p *NamePool) Allocate() (string, error) {
 	return name, nil
 }
 
+// Claim allocates a specific name, for callers that choose the name
+// themselves (skill-based routing picks the identity with the best track
+// record). The name must be a free pool name.
+func (p *NamePool) Claim(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	names := p.getNames()
+	for i := 0; i < len(names) && i < p.MaxSize; i++ {
+		if names[i] != name {
+			continue
+		}
+		if p.InUse[name] {
+			return fmt.Errorf("name %s is in use", name)
+		}
+		p.InUse[name] = true
+		return nil
+	}
+	return fmt.Errorf("name %s is not in the pool", name)
+}
+
+// Names returns the pool's names in allocation order.
+func (p *NamePool) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := p.getNames()
+	if len(names) > p.MaxSize {
+		names = names[:p.MaxSize]
+	}
+	return names
+}
+
 // Release returns a name slot to the available pool.
 // Called when a polecat is nuked - the name becomes available for new polecats.
 // NOTE: This releases the NAME, not the polecat. The polecat is gone (nuked).
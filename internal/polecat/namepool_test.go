@@ -525,3 +525,37 @@ func TestNamePool_ReservedNamesInCustomNames(t *testing.T) {
 		t.Errorf("expected alpha, beta, gamma to be allocated, got %v", allocated)
 	}
 }
+
+func TestNamePool_Claim(t *testing.T) {
+	pool := NewNamePoolWithConfig(t.TempDir(), "testrig", "mad-max", nil, 3)
+
+	if err := pool.Claim("nux"); err != nil {
+		t.Fatalf("Claim(nux): %v", err)
+	}
+	if err := pool.Claim("nux"); err == nil {
+		t.Error("Claim(nux) twice succeeded, want in-use error")
+	}
+	// Beyond MaxSize and outside the theme are not pool names
+	if err := pool.Claim("cheedo"); err == nil {
+		t.Error("Claim(cheedo) succeeded past MaxSize")
+	}
+	if err := pool.Claim("bob"); err == nil {
+		t.Error("Claim(bob) succeeded for a name outside the pool")
+	}
+
+	// Allocate skips the claimed name
+	name, err := pool.Allocate()
+	if err != nil {
+		t.Fatalf("Allocate: %v", err)
+	}
+	if name != "furiosa" {
+		t.Errorf("Allocate() = %s, want furiosa", name)
+	}
+	if name, _ = pool.Allocate(); name != "slit" {
+		t.Errorf("Allocate() = %s, want slit", name)
+	}
+
+	if got := pool.Names(); len(got) != 3 || got[0] != "furiosa" || got[2] != "slit" {
+		t.Errorf("Names() = %v, want the first 3 theme names", got)
+	}
+}
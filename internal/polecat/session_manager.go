@@ -14,8 +14,11 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/recording"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/sandbox"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -209,9 +212,25 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 		command = config.PrependEnv(command, map[string]string{runtimeConfig.Session.ConfigDirEnv: opts.RuntimeConfigDir})
 	}
 
+	// Apply the polecat role's resource limits (cgroup v2, Linux only)
+	command, warnings := sandbox.WrapRoleChecked(filepath.Dir(m.rig.Path), m.rig.Path, "polecat", sessionID, command)
+	for _, w := range warnings {
+		fmt.Printf("Warning: %s\n", w)
+	}
+
+	// Export env secrets scoped to this rig's polecats. The values go
+	// through a private file that the command sources and deletes, so they
+	// never appear in the command line or tmux environment.
+	envFile, err := secrets.SessionEnvFile(filepath.Dir(m.rig.Path), m.rig.Name, "polecat", address)
+	if err != nil {
+		fmt.Printf("Warning: could not export secrets: %v\n", err)
+	}
+	command = secrets.WrapCommand(command, envFile)
+
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
 	if err := m.tmux.NewSessionWithCommand(sessionID, workDir, command); err != nil {
+		secrets.DiscardEnvFile(envFile)
 		return fmt.Errorf("creating session: %w", err)
 	}
 
@@ -242,6 +261,8 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	theme := tmux.AssignTheme(m.rig.Name)
 	debugSession("ConfigureGasTownSession", m.tmux.ConfigureGasTownSession(sessionID, theme, m.rig.Name, polecat, "polecat"))
 
+	debugSession("recording.Start", recording.Start(m.tmux, m.rig.Path, sessionID))
+
 	// Set pane-died hook for crash detection (non-fatal)
 	agentID := fmt.Sprintf("%s/%s", m.rig.Name, polecat)
 	debugSession("SetPaneDiedHook", m.tmux.SetPaneDiedHook(sessionID, agentID))
@@ -0,0 +1,492 @@
+package polecat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// WarmPoolConfigKey is the rig config key for the warm pool size.
+// Zero (the default) disables the pool.
+const WarmPoolConfigKey = "polecat_warm_pool"
+
+// warmDirName is the directory under polecats/ that holds the warm pool.
+// The leading dot keeps warm worktrees out of List() and the Witness's
+// view, so they are never mistaken for idle polecats and nuked.
+const warmDirName = ".warm"
+
+// WarmPolecat is a pre-provisioned worktree waiting to be claimed.
+// Its name is already taken from the name pool.
+type WarmPolecat struct {
+	Name          string    `json:"name"`
+	ClonePath     string    `json:"clone_path"`
+	Commit        string    `json:"commit"`
+	CommitsBehind int       `json:"commits_behind"`
+	WarmedAt      time.Time `json:"warmed_at"`
+}
+
+// WarmPoolSize returns the configured number of warm worktrees for the rig.
+func (m *Manager) WarmPoolSize() int {
+	n := m.rig.GetIntConfig(WarmPoolConfigKey)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// warmPoolDir returns polecats/.warm/.
+func (m *Manager) warmPoolDir() string {
+	return filepath.Join(m.rig.Path, "polecats", warmDirName)
+}
+
+// warmClonePath returns polecats/.warm/<name>/<rigname>/, mirroring the
+// layout of a claimed polecat one level down.
+func (m *Manager) warmClonePath(name string) string {
+	return filepath.Join(m.warmPoolDir(), name, m.rig.Name)
+}
+
+// warmStartPoint returns origin/<default-branch>, which warm worktrees track.
+func (m *Manager) warmStartPoint() string {
+	defaultBranch := "main"
+	if rigCfg, err := rig.LoadRigConfig(m.rig.Path); err == nil && rigCfg.DefaultBranch != "" {
+		defaultBranch = rigCfg.DefaultBranch
+	}
+	return "origin/" + defaultBranch
+}
+
+// lockWarmPool takes the rig's warm pool lock and returns its release.
+// Claiming, refreshing and removing a warm worktree each hold it, so one
+// can't remove a worktree (and release its name) while another claims it.
+// The lock is not reentrant.
+func (m *Manager) lockWarmPool() (func(), error) {
+	if err := os.MkdirAll(m.warmPoolDir(), 0755); err != nil {
+		return nil, err
+	}
+	lock := flock.New(filepath.Join(m.warmPoolDir(), ".lock"))
+	if err := lock.Lock(); err != nil {
+		return nil, fmt.Errorf("locking warm pool: %w", err)
+	}
+	return func() { _ = lock.Unlock() }, nil
+}
+
+// isWarm reports whether a name still holds a warm worktree; it stops once
+// the worktree is claimed.
+func (m *Manager) isWarm(name string) bool {
+	_, err := os.Stat(filepath.Join(m.warmClonePath(name), ".git"))
+	return err == nil
+}
+
+// warmNames returns the names held by warm worktrees, sorted.
+func (m *Manager) warmNames() []string {
+	entries, err := os.ReadDir(m.warmPoolDir())
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ListWarm returns the warm pool's worktrees. Entries whose worktree is
+// missing or broken are skipped; FillWarmPool removes them.
+func (m *Manager) ListWarm() ([]*WarmPolecat, error) {
+	startPoint := m.warmStartPoint()
+
+	var warm []*WarmPolecat
+	for _, name := range m.warmNames() {
+		clonePath := m.warmClonePath(name)
+		info, err := os.Stat(filepath.Join(clonePath, ".git"))
+		if err != nil {
+			continue
+		}
+		g := git.NewGit(clonePath)
+		commit, err := g.Rev("HEAD")
+		if err != nil {
+			continue
+		}
+		behind, _ := g.CountCommitsBehind(startPoint)
+		warm = append(warm, &WarmPolecat{
+			Name:          name,
+			ClonePath:     clonePath,
+			Commit:        commit,
+			CommitsBehind: behind,
+			WarmedAt:      info.ModTime(),
+		})
+	}
+	return warm, nil
+}
+
+// FillWarmPool tops the warm pool up to the configured size and drains any
+// surplus if the size was lowered. Broken entries are removed first.
+// Returns the number of worktrees added.
+func (m *Manager) FillWarmPool() (int, error) {
+	m.pruneBrokenWarm()
+
+	target := m.WarmPoolSize()
+	names := m.warmNames()
+
+	// Drain surplus, newest names last so the oldest stay warm
+	for len(names) > target {
+		name := names[len(names)-1]
+		names = names[:len(names)-1]
+		if err := m.RemoveWarm(name); err != nil {
+			return 0, fmt.Errorf("draining warm polecat %s: %w", name, err)
+		}
+	}
+
+	added := 0
+	for i := len(names); i < target; i++ {
+		name, err := m.AllocateName()
+		if err != nil {
+			return added, fmt.Errorf("allocating warm polecat name: %w", err)
+		}
+		if err := m.warm(name); err != nil {
+			m.ReleaseName(name)
+			return added, fmt.Errorf("warming %s: %w", name, err)
+		}
+		added++
+	}
+	return added, nil
+}
+
+// warm provisions a worktree in the warm pool. This does the slow part of
+// AddWithOptions ahead of time: worktree checkout, overlay, .gitignore and
+// setup hooks. The worktree sits on a detached HEAD at origin/<default>;
+// the polecat's branch is created when it is claimed.
+//
+// Setup hooks run here, so they see the warm path. Hooks that bake the
+// worktree's absolute path into generated files will break when the
+// worktree moves on claim.
+func (m *Manager) warm(name string) error {
+	warmDir := filepath.Join(m.warmPoolDir(), name)
+	clonePath := m.warmClonePath(name)
+
+	if err := os.MkdirAll(warmDir, 0755); err != nil {
+		return fmt.Errorf("creating warm dir: %w", err)
+	}
+
+	repoGit, err := m.repoBase()
+	if err != nil {
+		_ = os.RemoveAll(warmDir)
+		return fmt.Errorf("finding repo base: %w", err)
+	}
+
+	if err := repoGit.Fetch("origin"); err != nil {
+		// Non-fatal - RefreshWarmPool catches up later
+		fmt.Printf("Warning: could not fetch origin: %v\n", err)
+	}
+
+	startPoint := m.warmStartPoint()
+	if err := repoGit.WorktreeAddDetached(clonePath, startPoint); err != nil {
+		_ = os.RemoveAll(warmDir)
+		return fmt.Errorf("creating worktree from %s: %w", startPoint, err)
+	}
+
+	m.ensureAgentsMD(clonePath)
+
+	if err := rig.CopyOverlay(m.rig.Path, clonePath); err != nil {
+		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
+	}
+	if err := rig.EnsureGitignorePatterns(clonePath); err != nil {
+		fmt.Printf("Warning: could not update .gitignore: %v\n", err)
+	}
+	if err := rig.RunSetupHooks(m.rig.Path, clonePath); err != nil {
+		fmt.Printf("Warning: could not run setup hooks: %v\n", err)
+	}
+	return nil
+}
+
+// ensureAgentsMD copies AGENTS.md from mayor/rig when the checkout lacks it
+// (stale fetch, local-only file).
+func (m *Manager) ensureAgentsMD(clonePath string) {
+	agentsMDPath := filepath.Join(clonePath, "AGENTS.md")
+	if _, err := os.Stat(agentsMDPath); !os.IsNotExist(err) {
+		return
+	}
+	srcPath := filepath.Join(m.rig.Path, "mayor", "rig", "AGENTS.md")
+	if srcData, readErr := os.ReadFile(srcPath); readErr == nil {
+		if writeErr := os.WriteFile(agentsMDPath, srcData, 0644); writeErr != nil {
+			fmt.Printf("Warning: could not copy AGENTS.md: %v\n", writeErr)
+		}
+	}
+}
+
+// RefreshWarmPool moves every warm worktree that has fallen behind to the
+// latest origin/<default> and reruns the setup hooks, since dependencies
+// may have changed. Untracked files (installed dependencies) are kept.
+// Worktrees that can't be moved forward are removed for FillWarmPool to
+// replace. Returns the number refreshed.
+func (m *Manager) RefreshWarmPool() (int, error) {
+	names := m.warmNames()
+	if len(names) == 0 {
+		return 0, nil
+	}
+
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return 0, fmt.Errorf("finding repo base: %w", err)
+	}
+	if err := repoGit.Fetch("origin"); err != nil {
+		fmt.Printf("Warning: could not fetch origin: %v\n", err)
+	}
+
+	startPoint := m.warmStartPoint()
+	target, err := repoGit.Rev(startPoint)
+	if err != nil {
+		return 0, fmt.Errorf("resolving %s: %w", startPoint, err)
+	}
+
+	refreshed := 0
+	for _, name := range names {
+		ok, err := m.refreshWarm(name, startPoint, target)
+		if err != nil {
+			return refreshed, err
+		}
+		if ok {
+			refreshed++
+		}
+	}
+	return refreshed, nil
+}
+
+// refreshWarm moves one warm worktree to target under the warm pool lock,
+// skipping it if it was claimed meanwhile. Reports whether it was moved.
+func (m *Manager) refreshWarm(name, startPoint, target string) (bool, error) {
+	unlock, err := m.lockWarmPool()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	if !m.isWarm(name) {
+		return false, nil
+	}
+	clonePath := m.warmClonePath(name)
+	g := git.NewGit(clonePath)
+	head, err := g.Rev("HEAD")
+	if err == nil && head == target {
+		return false, nil
+	}
+	if err != nil || g.Checkout(startPoint) != nil {
+		fmt.Printf("Warning: removing warm polecat %s that could not be refreshed\n", name)
+		_ = m.removeWarm(name)
+		return false, nil
+	}
+	if err := rig.RunSetupHooks(m.rig.Path, clonePath); err != nil {
+		fmt.Printf("Warning: could not run setup hooks for %s: %v\n", name, err)
+	}
+	return true, nil
+}
+
+// ClaimWarm turns a warm worktree into a polecat: the worktree moves to
+// polecats/<name>/<rigname>/, a fresh branch is cut from the latest
+// origin/<default>, and the per-polecat bits (shared beads redirect,
+// PRIME.md, agent bead) are provisioned as in AddWithOptions.
+// Returns ErrNoWarmPolecat when the pool is empty.
+func (m *Manager) ClaimWarm(opts AddOptions) (*Polecat, error) {
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return nil, ErrNoWarmPolecat
+	}
+
+	fetched := false
+	for _, name := range m.warmNames() {
+		p, err := m.claimWarm(repoGit, name, opts, &fetched)
+		if err == ErrNoWarmPolecat {
+			continue
+		}
+		return p, err
+	}
+
+	return nil, ErrNoWarmPolecat
+}
+
+// ClaimWarmNamed claims the warm worktree held under a specific name, as
+// ClaimWarm does. Returns ErrNoWarmPolecat when there is none to claim.
+func (m *Manager) ClaimWarmNamed(name string, opts AddOptions) (*Polecat, error) {
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return nil, ErrNoWarmPolecat
+	}
+	for _, warm := range m.warmNames() {
+		if warm == name {
+			fetched := false
+			return m.claimWarm(repoGit, name, opts, &fetched)
+		}
+	}
+	return nil, ErrNoWarmPolecat
+}
+
+// claimWarm claims one warm worktree. Returns ErrNoWarmPolecat when the
+// entry cannot be claimed (taken by a concurrent claimer, a name collision
+// or a failed checkout) and the caller should try another. Origin is
+// fetched after the first successful move only, tracked by fetched.
+func (m *Manager) claimWarm(repoGit *git.Git, name string, opts AddOptions, fetched *bool) (*Polecat, error) {
+	if m.exists(name) {
+		return nil, ErrNoWarmPolecat // Name collision with a live polecat - leave for pruning
+	}
+
+	polecatDir := m.polecatDir(name)
+	clonePath := filepath.Join(polecatDir, m.rig.Name)
+	if err := m.moveWarm(repoGit, name, polecatDir, clonePath); err != nil {
+		return nil, err
+	}
+
+	if !*fetched {
+		if err := repoGit.Fetch("origin"); err != nil {
+			fmt.Printf("Warning: could not fetch origin: %v\n", err)
+		}
+		*fetched = true
+	}
+
+	branchName := m.buildBranchName(name, opts.HookBead)
+	startPoint := m.warmStartPoint()
+	if err := git.NewGit(clonePath).CheckoutNewBranch(branchName, startPoint); err != nil {
+		fmt.Printf("Warning: discarding warm polecat %s: %v\n", name, err)
+		_ = repoGit.WorktreeRemove(clonePath, true)
+		_ = os.RemoveAll(polecatDir)
+		return nil, ErrNoWarmPolecat
+	}
+
+	if err := m.setupSharedBeads(clonePath); err != nil {
+		fmt.Printf("Warning: could not set up shared beads: %v\n", err)
+	}
+	if err := beads.ProvisionPrimeMDForWorktree(clonePath); err != nil {
+		fmt.Printf("Warning: could not provision PRIME.md: %v\n", err)
+	}
+	// Secrets are delivered on claim, never to unclaimed worktrees
+	m.materializeSecrets(name, clonePath)
+
+	agentID := m.agentBeadID(name)
+	if _, err := m.beads.CreateOrReopenAgentBead(agentID, agentID, &beads.AgentFields{
+		RoleType:   "polecat",
+		Rig:        m.rig.Name,
+		AgentState: "spawning",
+		HookBead:   opts.HookBead,
+	}); err != nil {
+		fmt.Printf("Warning: could not create agent bead: %v\n", err)
+	}
+
+	now := time.Now()
+	return &Polecat{
+		Name:      name,
+		Rig:       m.rig.Name,
+		State:     StateWorking,
+		ClonePath: clonePath,
+		Branch:    branchName,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// moveWarm moves a warm worktree to clonePath under the warm pool lock.
+// The move is what claims the worktree: a concurrent claimer of the same
+// entry finds it gone and moves on to the next one.
+func (m *Manager) moveWarm(repoGit *git.Git, name, polecatDir, clonePath string) error {
+	unlock, err := m.lockWarmPool()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !m.isWarm(name) {
+		return ErrNoWarmPolecat
+	}
+	if err := os.MkdirAll(polecatDir, 0755); err != nil {
+		return fmt.Errorf("creating polecat dir: %w", err)
+	}
+	if err := repoGit.WorktreeMove(m.warmClonePath(name), clonePath); err != nil {
+		_ = os.Remove(polecatDir)
+		return ErrNoWarmPolecat
+	}
+	_ = os.Remove(filepath.Join(m.warmPoolDir(), name))
+	return nil
+}
+
+// RemoveWarm deletes a warm worktree and returns its name to the pool.
+// It does nothing if the worktree was claimed meanwhile.
+func (m *Manager) RemoveWarm(name string) error {
+	unlock, err := m.lockWarmPool()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := os.Stat(filepath.Join(m.warmPoolDir(), name)); os.IsNotExist(err) {
+		return nil
+	}
+	return m.removeWarm(name)
+}
+
+// removeWarm is RemoveWarm for callers holding the warm pool lock.
+func (m *Manager) removeWarm(name string) error {
+	clonePath := m.warmClonePath(name)
+	if repoGit, err := m.repoBase(); err == nil {
+		if err := repoGit.WorktreeRemove(clonePath, true); err != nil {
+			// Fall through to directory removal and prune
+			_ = repoGit.WorktreePrune()
+		}
+	}
+	if err := os.RemoveAll(filepath.Join(m.warmPoolDir(), name)); err != nil {
+		return err
+	}
+	m.ReleaseName(name)
+	return nil
+}
+
+// DrainWarmPool removes every warm worktree. Returns the number removed.
+func (m *Manager) DrainWarmPool() (int, error) {
+	removed := 0
+	for _, name := range m.warmNames() {
+		if err := m.RemoveWarm(name); err != nil {
+			return removed, fmt.Errorf("removing warm polecat %s: %w", name, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// pruneBrokenWarm removes warm entries without a usable worktree (a warm
+// that died midway) and entries whose name was taken by a live polecat.
+// Names are returned to the pool unless a live polecat holds them.
+func (m *Manager) pruneBrokenWarm() {
+	unlock, err := m.lockWarmPool()
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	for _, name := range m.warmNames() {
+		if m.isWarm(name) && !m.exists(name) {
+			continue
+		}
+		clonePath := m.warmClonePath(name)
+		if repoGit, err := m.repoBase(); err == nil {
+			_ = repoGit.WorktreeRemove(clonePath, true)
+		}
+		if err := os.RemoveAll(filepath.Join(m.warmPoolDir(), name)); err != nil {
+			continue
+		}
+		if !m.exists(name) {
+			m.ReleaseName(name)
+		}
+	}
+	if repoGit, err := m.repoBase(); err == nil {
+		_ = repoGit.WorktreePrune()
+	}
+}
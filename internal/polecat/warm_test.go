@@ -0,0 +1,271 @@
+package polecat
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// setupWarmTestRig creates a rig whose mayor/rig repo is its own origin,
+// with origin/main pointing at HEAD.
+func setupWarmTestRig(t *testing.T) (*Manager, string) {
+	t.Helper()
+	root := t.TempDir()
+	mayorRig := filepath.Join(root, "mayor", "rig")
+	if err := os.MkdirAll(mayorRig, 0755); err != nil {
+		t.Fatalf("mkdir mayor/rig: %v", err)
+	}
+
+	runGit(t, mayorRig, "init", "-b", "main")
+	runGit(t, mayorRig, "config", "user.email", "test@test.com")
+	runGit(t, mayorRig, "config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(mayorRig, "README.md"), []byte("v1\n"), 0644); err != nil {
+		t.Fatalf("write README: %v", err)
+	}
+	runGit(t, mayorRig, "add", ".")
+	runGit(t, mayorRig, "commit", "-m", "initial")
+	runGit(t, mayorRig, "remote", "add", "origin", mayorRig)
+	runGit(t, mayorRig, "update-ref", "refs/remotes/origin/main", "HEAD")
+
+	r := &rig.Rig{Name: "rig", Path: root}
+	return NewManager(r, git.NewGit(root), nil), mayorRig
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestClaimWarm_Empty(t *testing.T) {
+	m, _ := setupWarmTestRig(t)
+
+	if _, err := m.ClaimWarm(AddOptions{}); err != ErrNoWarmPolecat {
+		t.Errorf("ClaimWarm on empty pool = %v, want ErrNoWarmPolecat", err)
+	}
+}
+
+func TestWarmPool_RefreshAndClaim(t *testing.T) {
+	m, mayorRig := setupWarmTestRig(t)
+
+	if err := m.warm("Warm1"); err != nil {
+		t.Fatalf("warm: %v", err)
+	}
+
+	// Warm worktrees are not polecats yet
+	polecats, err := m.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(polecats) != 0 {
+		t.Errorf("List() = %d polecats, want 0 (warm worktrees are hidden)", len(polecats))
+	}
+
+	// Origin moves ahead of the warm worktree
+	if err := os.WriteFile(filepath.Join(mayorRig, "README.md"), []byte("v2\n"), 0644); err != nil {
+		t.Fatalf("write README: %v", err)
+	}
+	runGit(t, mayorRig, "commit", "-am", "update")
+	runGit(t, mayorRig, "update-ref", "refs/remotes/origin/main", "HEAD")
+
+	warm, err := m.ListWarm()
+	if err != nil {
+		t.Fatalf("ListWarm: %v", err)
+	}
+	if len(warm) != 1 || warm[0].Name != "Warm1" || warm[0].CommitsBehind != 1 {
+		t.Fatalf("ListWarm() = %+v, want Warm1 one commit behind", warm)
+	}
+
+	refreshed, err := m.RefreshWarmPool()
+	if err != nil {
+		t.Fatalf("RefreshWarmPool: %v", err)
+	}
+	if refreshed != 1 {
+		t.Errorf("RefreshWarmPool() = %d, want 1", refreshed)
+	}
+
+	p, err := m.ClaimWarm(AddOptions{})
+	if err != nil {
+		t.Fatalf("ClaimWarm: %v", err)
+	}
+	if p.Name != "Warm1" {
+		t.Errorf("claimed %q, want Warm1", p.Name)
+	}
+	wantPath := filepath.Join(m.rig.Path, "polecats", "Warm1", "rig")
+	if p.ClonePath != wantPath {
+		t.Errorf("ClonePath = %q, want %q", p.ClonePath, wantPath)
+	}
+
+	content, err := os.ReadFile(filepath.Join(p.ClonePath, "README.md"))
+	if err != nil {
+		t.Fatalf("read README: %v", err)
+	}
+	if string(content) != "v2\n" {
+		t.Errorf("claimed worktree README = %q, want latest origin/main", content)
+	}
+
+	branch, err := git.NewGit(p.ClonePath).CurrentBranch()
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+	if branch != p.Branch {
+		t.Errorf("claimed worktree on %q, want %q", branch, p.Branch)
+	}
+
+	if names := m.warmNames(); len(names) != 0 {
+		t.Errorf("warm pool after claim = %v, want empty", names)
+	}
+	if _, err := m.ClaimWarm(AddOptions{}); err != ErrNoWarmPolecat {
+		t.Errorf("second ClaimWarm = %v, want ErrNoWarmPolecat", err)
+	}
+}
+
+func TestClaimWarmNamed(t *testing.T) {
+	m, _ := setupWarmTestRig(t)
+	names := m.namePool.Names()
+	first, second, third := names[0], names[1], names[2]
+
+	for _, name := range []string{first, second} {
+		if err := m.warm(name); err != nil {
+			t.Fatalf("warm %s: %v", name, err)
+		}
+	}
+
+	slots := m.NameSlots()
+	if !slots[0].Warm || !slots[1].Warm || slots[2].Warm || slots[2].Busy {
+		t.Fatalf("NameSlots() = %+v, want %s and %s warm, %s free", slots[:3], first, second, third)
+	}
+
+	if _, err := m.ClaimWarmNamed(third, AddOptions{}); err != ErrNoWarmPolecat {
+		t.Errorf("ClaimWarmNamed(%s) = %v, want ErrNoWarmPolecat", third, err)
+	}
+	p, err := m.ClaimWarmNamed(second, AddOptions{})
+	if err != nil {
+		t.Fatalf("ClaimWarmNamed(%s): %v", second, err)
+	}
+	if p.Name != second {
+		t.Errorf("claimed %q, want %s", p.Name, second)
+	}
+
+	slots = m.NameSlots()
+	if !slots[0].Warm || slots[1].Warm || !slots[1].Busy {
+		t.Errorf("NameSlots() after claim = %+v, want %s warm, %s busy", slots[:2], first, second)
+	}
+
+	if err := m.AllocateNamed(second); err == nil {
+		t.Errorf("AllocateNamed(%s) succeeded for a live polecat", second)
+	}
+	if err := m.AllocateNamed(third); err != nil {
+		t.Errorf("AllocateNamed(%s): %v", third, err)
+	}
+}
+
+func TestAllocatePreferred_FallsBackToNextFreeName(t *testing.T) {
+	m, _ := setupWarmTestRig(t)
+	names := m.namePool.Names()
+	first, second, third := names[0], names[1], names[2]
+
+	if err := m.warm(first); err != nil {
+		t.Fatalf("warm %s: %v", first, err)
+	}
+	// A concurrent spawn got the routed name first
+	if err := os.MkdirAll(m.polecatDir(second), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.AllocatePreferred(second)
+	if err != nil {
+		t.Fatalf("AllocatePreferred(%s): %v", second, err)
+	}
+	if got != third {
+		t.Errorf("AllocatePreferred(%s) = %s, want %s (next free, skipping warm %s)", second, got, third, first)
+	}
+}
+
+func TestReconcilePool_ReservesWarmNames(t *testing.T) {
+	m, _ := setupWarmTestRig(t)
+
+	name, err := m.AllocateName()
+	if err != nil {
+		t.Fatalf("AllocateName: %v", err)
+	}
+	if err := m.warm(name); err != nil {
+		t.Fatalf("warm: %v", err)
+	}
+
+	// A fresh manager reconciles from disk; the warm name must stay taken
+	m2 := NewManager(m.rig, git.NewGit(m.rig.Path), nil)
+	next, err := m2.AllocateName()
+	if err != nil {
+		t.Fatalf("AllocateName: %v", err)
+	}
+	if next == name {
+		t.Errorf("AllocateName() = %q, which is held by the warm pool", next)
+	}
+
+	if err := m2.RemoveWarm(name); err != nil {
+		t.Fatalf("RemoveWarm: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.warmPoolDir(), name)); !os.IsNotExist(err) {
+		t.Errorf("warm dir for %s still exists after RemoveWarm", name)
+	}
+}
+
+func TestPruneBrokenWarm_ReleasesName(t *testing.T) {
+	m, _ := setupWarmTestRig(t)
+
+	name, err := m.AllocateName()
+	if err != nil {
+		t.Fatalf("AllocateName: %v", err)
+	}
+	if err := m.warm(name); err != nil {
+		t.Fatalf("warm: %v", err)
+	}
+	// The warm died midway: its worktree is gone
+	if err := os.RemoveAll(m.warmClonePath(name)); err != nil {
+		t.Fatal(err)
+	}
+
+	m.pruneBrokenWarm()
+	if names := m.warmNames(); len(names) != 0 {
+		t.Errorf("warm pool after prune = %v, want empty", names)
+	}
+	for _, active := range m.namePool.ActiveNames() {
+		if active == name {
+			t.Errorf("pruned name %q still in use", name)
+		}
+	}
+}
+
+func TestRemoveWarm_AfterClaimKeepsName(t *testing.T) {
+	m, _ := setupWarmTestRig(t)
+
+	name, err := m.AllocateName()
+	if err != nil {
+		t.Fatalf("AllocateName: %v", err)
+	}
+	if err := m.warm(name); err != nil {
+		t.Fatalf("warm: %v", err)
+	}
+	if _, err := m.ClaimWarm(AddOptions{}); err != nil {
+		t.Fatalf("ClaimWarm: %v", err)
+	}
+
+	// A drain that listed the name before the claim must not free it
+	if err := m.RemoveWarm(name); err != nil {
+		t.Fatalf("RemoveWarm: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.polecatDir(name), m.rig.Name)); err != nil {
+		t.Errorf("claimed worktree removed: %v", err)
+	}
+	if next, err := m.AllocateName(); err != nil || next == name {
+		t.Errorf("AllocateName() = %q, %v; %q belongs to the claimed polecat", next, err, name)
+	}
+}
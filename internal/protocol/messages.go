@@ -127,7 +127,7 @@ func formatMergeFailedBody(p MergeFailedPayload) string {
 	sb.WriteString(fmt.Sprintf("Target: %s\n", p.TargetBranch))
 	sb.WriteString(fmt.Sprintf("Failed-At: %s\n", p.FailedAt.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("Failure-Type: %s\n", p.FailureType))
-	sb.WriteString(fmt.Sprintf("Error: %s\n", p.Error))
+	sb.WriteString(formatFoldedField("Error", p.Error))
 	return sb.String()
 }
 
@@ -159,6 +159,38 @@ func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, c
 	return msg
 }
 
+// NewPostMergeReworkMessage creates a REWORK_REQUEST protocol message for a
+// merge that landed on the target branch but failed post-merge verification.
+// The Refinery has already reverted the merge; the polecat must fix the
+// interaction with the current target and resubmit.
+func NewPostMergeReworkMessage(rig, polecat, branch, issue, targetBranch, revertCommit, errorMsg string) *mail.Message {
+	payload := ReworkRequestPayload{
+		Branch:       branch,
+		Issue:        issue,
+		Polecat:      polecat,
+		Rig:          rig,
+		RequestedAt:  time.Now(),
+		TargetBranch: targetBranch,
+		FailureType:  FailureTypePostMerge,
+		RevertCommit: revertCommit,
+		Error:        errorMsg,
+		Instructions: formatPostMergeInstructions(targetBranch),
+	}
+
+	body := formatReworkRequestBody(payload)
+
+	msg := mail.NewMessage(
+		fmt.Sprintf("%s/refinery", rig),
+		fmt.Sprintf("%s/witness", rig),
+		fmt.Sprintf("REWORK_REQUEST %s", polecat),
+		body,
+	)
+	msg.Priority = mail.PriorityHigh
+	msg.Type = mail.TypeTask
+
+	return msg
+}
+
 // formatReworkRequestBody formats the body of a REWORK_REQUEST message.
 func formatReworkRequestBody(p ReworkRequestPayload) string {
 	var sb strings.Builder
@@ -172,6 +204,15 @@ func formatReworkRequestBody(p ReworkRequestPayload) string {
 	if len(p.ConflictFiles) > 0 {
 		sb.WriteString(fmt.Sprintf("Conflict-Files: %s\n", strings.Join(p.ConflictFiles, ", ")))
 	}
+	if p.FailureType != "" {
+		sb.WriteString(fmt.Sprintf("Failure-Type: %s\n", p.FailureType))
+	}
+	if p.RevertCommit != "" {
+		sb.WriteString(fmt.Sprintf("Revert-Commit: %s\n", p.RevertCommit))
+	}
+	if p.Error != "" {
+		sb.WriteString(formatFoldedField("Error", p.Error))
+	}
 
 	sb.WriteString("\n")
 	sb.WriteString(p.Instructions)
@@ -191,6 +232,19 @@ func formatRebaseInstructions(targetBranch string) string {
 The Refinery will retry the merge after rebase is complete.`, targetBranch, targetBranch)
 }
 
+// formatPostMergeInstructions returns instructions for fixing a reverted merge.
+func formatPostMergeInstructions(targetBranch string) string {
+	return fmt.Sprintf(`Your merge landed on %s but broke post-merge verification and was reverted.
+This usually means an interaction with another change that landed first.
+
+  git fetch origin
+  git rebase origin/%s
+  # Reproduce with the rig's verify command and fix the interaction
+  git push -f
+
+The Refinery queue is paused until %s is green again.`, targetBranch, targetBranch, targetBranch)
+}
+
 // ParseMergeReadyPayload parses a MERGE_READY message body into a payload.
 func ParseMergeReadyPayload(body string) *MergeReadyPayload {
 	return &MergeReadyPayload{
@@ -233,7 +287,7 @@ func ParseMergeFailedPayload(body string) *MergeFailedPayload {
 		Rig:          parseField(body, "Rig"),
 		TargetBranch: parseField(body, "Target"),
 		FailureType:  parseField(body, "Failure-Type"),
-		Error:        parseField(body, "Error"),
+		Error:        parseFoldedField(body, "Error"),
 	}
 
 	// Parse timestamp
@@ -254,6 +308,9 @@ func ParseReworkRequestPayload(body string) *ReworkRequestPayload {
 		Polecat:      parseField(body, "Polecat"),
 		Rig:          parseField(body, "Rig"),
 		TargetBranch: parseField(body, "Target"),
+		FailureType:  parseField(body, "Failure-Type"),
+		RevertCommit: parseField(body, "Revert-Commit"),
+		Error:        parseFoldedField(body, "Error"),
 	}
 
 	// Parse timestamp
@@ -271,6 +328,36 @@ func ParseReworkRequestPayload(body string) *ReworkRequestPayload {
 	return payload
 }
 
+// formatFoldedField formats a field whose value may span lines, such as
+// test output. Continuation lines are indented by two spaces.
+func formatFoldedField(key, value string) string {
+	lines := strings.Split(strings.TrimRight(value, "\n"), "\n")
+	return fmt.Sprintf("%s: %s\n", key, strings.Join(lines, "\n  "))
+}
+
+// parseFoldedField extracts a field written by formatFoldedField,
+// rejoining its continuation lines.
+func parseFoldedField(body, key string) string {
+	lines := strings.Split(body, "\n")
+	prefix := key + ": "
+
+	for i, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), prefix) {
+			continue
+		}
+		value := []string{strings.TrimPrefix(strings.TrimSpace(line), prefix)}
+		for _, next := range lines[i+1:] {
+			if !strings.HasPrefix(next, "  ") {
+				break
+			}
+			value = append(value, strings.TrimPrefix(next, "  "))
+		}
+		return strings.Join(value, "\n")
+	}
+
+	return ""
+}
+
 // parseField extracts a field value from a key-value body format.
 // Format: "Key: value"
 func parseField(body, key string) string {
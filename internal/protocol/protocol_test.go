@@ -34,6 +34,17 @@ func TestParseMessageType(t *testing.T) {
 	}
 }
 
+// TestParseMessageType_MailRules checks that mailbox rules, which cannot
+// import this package, classify protocol mail as ParseMessageType does.
+func TestParseMessageType_MailRules(t *testing.T) {
+	for _, subject := range []string{"MERGE_READY nux", "MERGED Toast", "MERGE_FAILED ace", "REWORK_REQUEST valkyrie", "  MERGE_READY nux  "} {
+		want := strings.ToLower(string(ParseMessageType(subject)))
+		if got := mail.ClassifyProtocol(subject); got != want {
+			t.Errorf("mail.ClassifyProtocol(%q) = %q, want %q", subject, got, want)
+		}
+	}
+}
+
 func TestExtractPolecat(t *testing.T) {
 	tests := []struct {
 		subject  string
@@ -151,6 +162,42 @@ func TestNewReworkRequestMessage(t *testing.T) {
 	}
 }
 
+func TestNewPostMergeReworkMessage(t *testing.T) {
+	msg := NewPostMergeReworkMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main", "def456", "verify failed")
+
+	if msg.Subject != "REWORK_REQUEST nux" {
+		t.Errorf("Subject = %q, want %q", msg.Subject, "REWORK_REQUEST nux")
+	}
+
+	payload := ParseReworkRequestPayload(msg.Body)
+	if payload.FailureType != FailureTypePostMerge {
+		t.Errorf("FailureType = %q, want %q", payload.FailureType, FailureTypePostMerge)
+	}
+	if payload.RevertCommit != "def456" {
+		t.Errorf("RevertCommit = %q, want %q", payload.RevertCommit, "def456")
+	}
+	if payload.Error != "verify failed" {
+		t.Errorf("Error = %q, want %q", payload.Error, "verify failed")
+	}
+	if payload.TargetBranch != "main" {
+		t.Errorf("TargetBranch = %q, want %q", payload.TargetBranch, "main")
+	}
+}
+
+func TestMultilineErrorRoundTrip(t *testing.T) {
+	errMsg := "verify failed: exit status 1\n--- FAIL: TestLogin (0.01s)\n\n    login_test.go:12: got 401"
+
+	rework := ParseReworkRequestPayload(NewPostMergeReworkMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main", "def456", errMsg).Body)
+	if rework.Error != errMsg {
+		t.Errorf("REWORK_REQUEST Error = %q, want %q", rework.Error, errMsg)
+	}
+
+	failed := ParseMergeFailedPayload(NewMergeFailedMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main", "tests", errMsg).Body)
+	if failed.Error != errMsg {
+		t.Errorf("MERGE_FAILED Error = %q, want %q", failed.Error, errMsg)
+	}
+}
+
 func TestParseMergeReadyPayload(t *testing.T) {
 	body := `Branch: polecat/nux/gt-abc
 Issue: gt-abc
@@ -163,8 +163,23 @@ type ReworkRequestPayload struct {
 
 	// Instructions provides specific rebase instructions.
 	Instructions string `json:"instructions,omitempty"`
+
+	// FailureType categorizes why rework is needed. Empty means a rebase
+	// conflict; "post_merge" means the merge landed, broke the target branch,
+	// and was reverted.
+	FailureType string `json:"failure_type,omitempty"`
+
+	// RevertCommit is the SHA of the revert commit (post_merge only).
+	RevertCommit string `json:"revert_commit,omitempty"`
+
+	// Error is the verification failure output summary (post_merge only).
+	Error string `json:"error,omitempty"`
 }
 
+// FailureTypePostMerge marks a merge that landed but failed post-merge
+// verification on the target branch and was reverted.
+const FailureTypePostMerge = "post_merge"
+
 // IsProtocolMessage returns true if the subject matches a known protocol type.
 func IsProtocolMessage(subject string) bool {
 	return ParseMessageType(subject) != ""
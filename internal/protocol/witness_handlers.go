@@ -113,6 +113,15 @@ func (h *DefaultWitnessHandler) HandleReworkRequest(payload *ReworkRequestPayloa
 		fmt.Fprintf(h.Output, "  Conflicts in: %v\n", payload.ConflictFiles)
 	}
 
+	if payload.FailureType == FailureTypePostMerge {
+		fmt.Fprintf(h.Output, "  Reverted in: %s\n", payload.RevertCommit)
+		if err := h.notifyPolecatPostMerge(payload); err != nil {
+			fmt.Fprintf(h.Output, "[Witness] Warning: failed to notify polecat: %v\n", err)
+		}
+		fmt.Fprintf(h.Output, "[Witness] ⚠ Polecat %s merge was reverted from %s\n", payload.Polecat, payload.TargetBranch)
+		return nil
+	}
+
 	// Notify the polecat about the rebase requirement
 	if err := h.notifyPolecatRebase(payload); err != nil {
 		fmt.Fprintf(h.Output, "[Witness] Warning: failed to notify polecat: %v\n", err)
@@ -214,5 +223,34 @@ Then run 'gt done' to resubmit for merge.`,
 	return h.Router.Send(msg)
 }
 
+// notifyPolecatPostMerge tells a polecat its merge broke the target and was reverted.
+func (h *DefaultWitnessHandler) notifyPolecatPostMerge(payload *ReworkRequestPayload) error {
+	msg := mail.NewMessage(
+		fmt.Sprintf("%s/witness", h.Rig),
+		fmt.Sprintf("%s/%s", h.Rig, payload.Polecat),
+		"Merge reverted - post-merge verification failed",
+		fmt.Sprintf(`Your merge to %s broke post-merge verification and was reverted.
+
+Branch: %s
+Issue: %s
+Revert: %s
+Error: %s
+
+Rebase onto the current %s, fix the interaction, and run 'gt done'
+to resubmit for merge.`,
+			payload.TargetBranch,
+			payload.Branch,
+			payload.Issue,
+			payload.RevertCommit,
+			payload.Error,
+			payload.TargetBranch,
+		),
+	)
+	msg.Priority = mail.PriorityHigh
+	msg.Type = mail.TypeTask
+
+	return h.Router.Send(msg)
+}
+
 // Ensure DefaultWitnessHandler implements WitnessHandler.
 var _ WitnessHandler = (*DefaultWitnessHandler)(nil)
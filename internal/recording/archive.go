@@ -0,0 +1,204 @@
+package recording
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// DefaultTTL is how long recordings are kept when the rig sets no TTL.
+const DefaultTTL = 72 * time.Hour
+
+// ext is the recording file extension.
+const ext = ".cast"
+
+// idTimeFormat is the timestamp suffix of a recording ID.
+const idTimeFormat = "20060102-150405"
+
+// Dir returns a rig's recordings directory.
+func Dir(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "recordings")
+}
+
+// Settings returns whether a rig records its sessions, and for how long
+// recordings are kept.
+func Settings(rigPath string) (enabled bool, ttl time.Duration) {
+	ttl = DefaultTTL
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil || settings.Recording == nil {
+		return false, ttl
+	}
+	if settings.Recording.TTL != "" {
+		if d, err := time.ParseDuration(settings.Recording.TTL); err == nil && d > 0 {
+			ttl = d
+		}
+	}
+	return settings.Recording.Enabled, ttl
+}
+
+// Start begins recording a session's pane if the rig has recording enabled.
+// The pane is piped to `gt session record`, which runs until the pane
+// closes; a pane that is already being recorded keeps its recording.
+// Recording is an archive only, so callers log a failure and carry on
+// starting the session.
+func Start(t *tmux.Tmux, rigPath, session string) error {
+	if enabled, _ := Settings(rigPath); !enabled {
+		return nil
+	}
+
+	dir := Dir(rigPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating recordings dir: %w", err)
+	}
+	id := session + "-" + time.Now().UTC().Format(idTimeFormat)
+	path := filepath.Join(dir, id+ext)
+
+	width, height, err := t.GetPaneSize(session)
+	if err != nil {
+		width, height = 80, 24
+	}
+	cmd := fmt.Sprintf("gt session record --output %s --width %d --height %d --title %s",
+		config.ShellQuote(path), width, height, config.ShellQuote(session))
+	if _, err := t.PipePane(session, cmd); err != nil {
+		return fmt.Errorf("piping pane: %w", err)
+	}
+	return nil
+}
+
+// Info describes a recording on disk.
+type Info struct {
+	ID      string    `json:"id"` // <session>-<YYYYMMDD-HHMMSS>
+	Rig     string    `json:"rig"`
+	Session string    `json:"session"`
+	Started time.Time `json:"started"`
+	Updated time.Time `json:"updated"` // Last write; end of the session if it has exited
+	Size    int64     `json:"size"`
+	Path    string    `json:"path"`
+}
+
+// List returns a rig's recordings, newest first.
+func List(rigPath string) ([]Info, error) {
+	entries, err := os.ReadDir(Dir(rigPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rigName := filepath.Base(rigPath)
+	var infos []Info
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ext) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSuffix(e.Name(), ext)
+		info := Info{
+			ID:      id,
+			Rig:     rigName,
+			Session: id,
+			Updated: fi.ModTime(),
+			Size:    fi.Size(),
+			Path:    filepath.Join(Dir(rigPath), e.Name()),
+		}
+		// The timestamp is the last two dash-separated fields
+		if parts := strings.Split(id, "-"); len(parts) > 2 {
+			stamp := strings.Join(parts[len(parts)-2:], "-")
+			if t, err := time.Parse(idTimeFormat, stamp); err == nil {
+				info.Session = strings.Join(parts[:len(parts)-2], "-")
+				info.Started = t
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Started.After(infos[j].Started) })
+	return infos, nil
+}
+
+// Find looks up a recording across rigs by ID. A session name selects that
+// session's latest recording, and a unique ID prefix is accepted.
+func Find(rigPaths []string, id string) (*Info, error) {
+	var all []Info
+	for _, rigPath := range rigPaths {
+		infos, err := List(rigPath)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, infos...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Started.After(all[j].Started) })
+
+	for i := range all {
+		if all[i].ID == id {
+			return &all[i], nil
+		}
+	}
+	for i := range all {
+		if all[i].Session == id {
+			return &all[i], nil // Newest first
+		}
+	}
+	var matches []Info
+	for _, info := range all {
+		if strings.HasPrefix(info.ID, id) {
+			matches = append(matches, info)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no recording %q", id)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, fmt.Errorf("%q matches %d recordings; use a longer ID", id, len(matches))
+	}
+}
+
+// Prune removes a rig's recordings not written to within ttl. Recordings of
+// running sessions are written continuously and so are kept.
+func Prune(rigPath string, ttl time.Duration) (int, error) {
+	infos, err := List(rigPath)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-ttl)
+	removed := 0
+	for _, info := range infos {
+		if info.Updated.Before(cutoff) {
+			if err := os.Remove(info.Path); err == nil {
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+// PruneTown prunes the recordings of every rig in a town, each by its own
+// TTL.
+func PruneTown(townRoot string) (int, error) {
+	dirs, err := filepath.Glob(filepath.Join(townRoot, "*", ".runtime", "recordings"))
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, dir := range dirs {
+		rigPath := filepath.Dir(filepath.Dir(dir))
+		_, ttl := Settings(rigPath)
+		n, err := Prune(rigPath, ttl)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
@@ -0,0 +1,251 @@
+// Package recording archives agent session output as asciicast v2 files, so
+// what a polecat did can be replayed after its session is gone.
+//
+// A recording is started by piping a tmux pane into `gt session record`
+// (see Start), which timestamps each chunk of output. Recordings live under
+// <rig>/.runtime/recordings/ and expire after the rig's recording TTL.
+//
+// Format reference: https://docs.asciinema.org/manual/asciicast/v2/
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+)
+
+// Version is the asciicast format version written and accepted.
+const Version = 2
+
+// Header is the first line of an asciicast v2 file.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"` // Unix seconds
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is one chunk of output, Time seconds after the recording started.
+type Event struct {
+	Time float64
+	Type string // "o" for output
+	Data string
+}
+
+// MarshalJSON encodes an event as asciicast's [time, type, data] array.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Time, e.Type, e.Data})
+}
+
+// UnmarshalJSON decodes an asciicast [time, type, data] array.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("event has %d fields, want 3", len(raw))
+	}
+	if err := json.Unmarshal(raw[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &e.Type); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[2], &e.Data)
+}
+
+// Writer writes an asciicast stream, timestamping output as it arrives.
+type Writer struct {
+	w       *bufio.Writer
+	start   time.Time
+	pending []byte // Trailing bytes of an incomplete UTF-8 sequence
+
+	now func() time.Time
+}
+
+// NewWriter writes header to w and returns a Writer for the events.
+// A zero header timestamp is set to the current time.
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	header.Version = Version
+	start := time.Now()
+	if header.Timestamp == 0 {
+		header.Timestamp = start.Unix()
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw, start: start, now: time.Now}, bw.Flush()
+}
+
+// Write records p as one output event. A UTF-8 sequence split across writes
+// is held back until it is complete, since events must be valid strings.
+func (w *Writer) Write(p []byte) (int, error) {
+	buf := append(w.pending, p...)
+	cut := len(buf)
+	for i := len(buf) - 1; i >= 0 && i >= len(buf)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(buf[i]) {
+			if !utf8.FullRune(buf[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	w.pending = append([]byte(nil), buf[cut:]...)
+	if cut == 0 {
+		return len(p), nil
+	}
+	if err := w.emit(buf[:cut]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes any held-back bytes. Invalid UTF-8 is replaced rather than
+// dropped.
+func (w *Writer) Close() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	pending := w.pending
+	w.pending = nil
+	return w.emit(pending)
+}
+
+// emit writes one output event and flushes it: the recorder is killed with
+// its pane, and whatever was buffered would be lost with it.
+func (w *Writer) emit(data []byte) error {
+	event := Event{
+		Time: float64(w.now().Sub(w.start).Microseconds()) / 1e6,
+		Type: "o",
+		Data: string(data),
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := w.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// Record copies r to a new asciicast stream on w until r is exhausted.
+func Record(r io.Reader, w io.Writer, header Header) error {
+	cw, err := NewWriter(w, header)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := cw.Write(buf[:n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return cw.Close()
+		}
+		if err != nil {
+			_ = cw.Close()
+			return err
+		}
+	}
+}
+
+// Cast is a parsed recording.
+type Cast struct {
+	Header Header
+	Events []Event
+}
+
+// Duration returns the time of the last event.
+func (c *Cast) Duration() time.Duration {
+	if len(c.Events) == 0 {
+		return 0
+	}
+	return time.Duration(c.Events[len(c.Events)-1].Time * float64(time.Second))
+}
+
+// Read parses an asciicast v2 stream. A truncated final line, as left by a
+// recorder killed mid-write, is ignored.
+func Read(r io.Reader) (*Cast, error) {
+	br := bufio.NewReader(r)
+	line, err := br.ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	var cast Cast
+	if err := json.Unmarshal(line, &cast.Header); err != nil {
+		return nil, fmt.Errorf("parsing header: %w", err)
+	}
+	if cast.Header.Version != Version {
+		return nil, fmt.Errorf("unsupported asciicast version %d", cast.Header.Version)
+	}
+
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			var e Event
+			if jerr := json.Unmarshal(line, &e); jerr == nil {
+				cast.Events = append(cast.Events, e)
+			} else if err == nil {
+				return nil, fmt.Errorf("parsing event %d: %w", len(cast.Events)+1, jerr)
+			}
+		}
+		if err == io.EOF {
+			return &cast, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Load reads a recording file.
+func Load(path string) (*Cast, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is a recording in the rig's runtime dir
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Play writes a recording's output to w, reproducing its timing at the given
+// speed. Pauses longer than maxIdle are shortened to maxIdle; zero keeps
+// them. A speed of zero or less dumps the output without delay.
+func Play(cast *Cast, w io.Writer, speed float64, maxIdle time.Duration) error {
+	var last float64
+	for _, e := range cast.Events {
+		if e.Type != "o" {
+			continue
+		}
+		if speed > 0 {
+			delay := time.Duration((e.Time - last) * float64(time.Second))
+			if maxIdle > 0 && delay > maxIdle {
+				delay = maxIdle
+			}
+			if delay > 0 {
+				time.Sleep(time.Duration(float64(delay) / speed))
+			}
+		}
+		last = e.Time
+		if _, err := io.WriteString(w, e.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package recording
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Width: 120, Height: 40, Title: "gt-gastown-Toast"})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	tick := w.start
+	w.now = func() time.Time { tick = tick.Add(500 * time.Millisecond); return tick }
+
+	// "é" is split across writes; it must not be mangled
+	for _, chunk := range [][]byte{[]byte("hello \xc3"), []byte("\xa9\r\n"), []byte("done\n")} {
+		if _, err := w.Write(chunk); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	cast, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if cast.Header.Version != 2 || cast.Header.Width != 120 || cast.Header.Timestamp == 0 {
+		t.Errorf("header = %+v", cast.Header)
+	}
+	if len(cast.Events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(cast.Events), cast.Events)
+	}
+	if cast.Events[0].Data != "hello " || cast.Events[1].Data != "é\r\n" {
+		t.Errorf("events = %+v", cast.Events)
+	}
+	if cast.Duration() != 1500*time.Millisecond {
+		t.Errorf("Duration = %v, want 1.5s", cast.Duration())
+	}
+
+	var out bytes.Buffer
+	if err := Play(cast, &out, 0, 0); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	if out.String() != "hello é\r\ndone\n" {
+		t.Errorf("Play output = %q", out.String())
+	}
+}
+
+func TestReadTruncated(t *testing.T) {
+	data := `{"version":2,"width":80,"height":24}
+[0.1,"o","ok\n"]
+[0.2,"o","cut of`
+	cast, err := Read(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(cast.Events) != 1 {
+		t.Errorf("got %d events, want the truncated one dropped", len(cast.Events))
+	}
+
+	if _, err := Read(strings.NewReader(`{"version":1,"width":80,"height":24}`)); err == nil {
+		t.Error("Read accepted asciicast v1")
+	}
+}
+
+func TestListFindPrune(t *testing.T) {
+	rigPath := filepath.Join(t.TempDir(), "gastown")
+	dir := Dir(rigPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{
+		"gt-gastown-Toast-20260101-090000",
+		"gt-gastown-Toast-20260102-090000",
+		"gt-gastown-Nux-20260102-100000",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, id+ext), []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-100 * time.Hour)
+	_ = os.Chtimes(filepath.Join(dir, "gt-gastown-Toast-20260101-090000"+ext), old, old)
+
+	infos, err := List(rigPath)
+	if err != nil || len(infos) != 3 {
+		t.Fatalf("List = %d, %v; want 3", len(infos), err)
+	}
+	if infos[0].ID != "gt-gastown-Nux-20260102-100000" || infos[0].Session != "gt-gastown-Nux" || infos[0].Rig != "gastown" {
+		t.Errorf("newest = %+v", infos[0])
+	}
+
+	info, err := Find([]string{rigPath}, "gt-gastown-Toast")
+	if err != nil || info.ID != "gt-gastown-Toast-20260102-090000" {
+		t.Errorf("Find(session) = %+v, %v; want the latest Toast recording", info, err)
+	}
+	if info, err := Find([]string{rigPath}, "gt-gastown-Nux-2026"); err != nil || info.Session != "gt-gastown-Nux" {
+		t.Errorf("Find(prefix) = %+v, %v", info, err)
+	}
+	if _, err := Find([]string{rigPath}, "gt-gastown-"); err == nil {
+		t.Error("Find accepted an ambiguous prefix")
+	}
+
+	n, err := Prune(rigPath, DefaultTTL)
+	if err != nil || n != 1 {
+		t.Errorf("Prune = %d, %v; want 1", n, err)
+	}
+}
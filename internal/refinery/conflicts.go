@@ -0,0 +1,359 @@
+package refinery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// InFlightBranch is a branch whose work hasn't landed on the target yet:
+// either an active polecat's branch or a submitted merge request.
+type InFlightBranch struct {
+	Branch  string `json:"branch"`
+	Polecat string `json:"polecat,omitempty"` // Polecat name, if known
+	MRID    string `json:"mr_id,omitempty"`   // Merge request bead, if submitted
+}
+
+// Label returns a short display name for the branch.
+func (b InFlightBranch) Label() string {
+	switch {
+	case b.Polecat != "" && b.MRID != "":
+		return b.Polecat + " (" + b.MRID + ")"
+	case b.Polecat != "":
+		return b.Polecat
+	case b.MRID != "":
+		return b.MRID
+	default:
+		return b.Branch
+	}
+}
+
+// BranchChanges holds the changed regions of one in-flight branch.
+type BranchChanges struct {
+	InFlightBranch
+	Files map[string][]git.DiffHunk `json:"files"`
+}
+
+// FileOverlap is a file touched by both branches of an overlap.
+type FileOverlap struct {
+	Path string `json:"path"`
+
+	// Hunks is true when the changed regions overlap or touch, which is
+	// what makes git's three-way merge conflict. Binary files always count.
+	Hunks bool `json:"hunks"`
+}
+
+// BranchOverlap is a pair of in-flight branches that touch the same files.
+type BranchOverlap struct {
+	A     InFlightBranch `json:"a"`
+	B     InFlightBranch `json:"b"`
+	Files []FileOverlap  `json:"files"`
+}
+
+// Likely reports whether the overlap is expected to conflict at merge time.
+func (o *BranchOverlap) Likely() bool {
+	for _, f := range o.Files {
+		if f.Hunks {
+			return true
+		}
+	}
+	return false
+}
+
+// Key identifies the branch pair independent of order.
+func (o *BranchOverlap) Key() string {
+	a, b := o.A.Branch, o.B.Branch
+	if b < a {
+		a, b = b, a
+	}
+	return a + " " + b
+}
+
+// ConflictTargetRef returns the ref in-flight branches should be diffed
+// against: origin/<target> when the remote-tracking branch exists, since the
+// local target may be stale, otherwise the local branch.
+func ConflictTargetRef(g *git.Git, target string) string {
+	if _, err := g.Rev("origin/" + target); err == nil {
+		return "origin/" + target
+	}
+	return target
+}
+
+// CollectBranchChanges diffs each in-flight branch against the target
+// (git diff target...branch). Branches that can't be diffed (deleted,
+// not fetched) are skipped. Duplicate branches are merged so a polecat's
+// branch with an open MR appears once.
+func CollectBranchChanges(g *git.Git, targetRef string, branches []InFlightBranch) []*BranchChanges {
+	byBranch := make(map[string]*BranchChanges)
+	var order []string
+	for _, b := range branches {
+		if existing, ok := byBranch[b.Branch]; ok {
+			if existing.Polecat == "" {
+				existing.Polecat = b.Polecat
+			}
+			if existing.MRID == "" {
+				existing.MRID = b.MRID
+			}
+			continue
+		}
+		files, err := g.DiffHunks(targetRef, b.Branch)
+		if err != nil || len(files) == 0 {
+			continue
+		}
+		byBranch[b.Branch] = &BranchChanges{InFlightBranch: b, Files: files}
+		order = append(order, b.Branch)
+	}
+
+	changes := make([]*BranchChanges, 0, len(order))
+	for _, branch := range order {
+		changes = append(changes, byBranch[branch])
+	}
+	return changes
+}
+
+// FindOverlaps returns every pair of branches that touch a common file,
+// likely conflicts first.
+func FindOverlaps(changes []*BranchChanges) []*BranchOverlap {
+	var overlaps []*BranchOverlap
+	for i := 0; i < len(changes); i++ {
+		for j := i + 1; j < len(changes); j++ {
+			a, b := changes[i], changes[j]
+			var files []FileOverlap
+			for path, hunksA := range a.Files {
+				hunksB, ok := b.Files[path]
+				if !ok {
+					continue
+				}
+				files = append(files, FileOverlap{Path: path, Hunks: hunksOverlap(hunksA, hunksB)})
+			}
+			if len(files) == 0 {
+				continue
+			}
+			sort.Slice(files, func(x, y int) bool { return files[x].Path < files[y].Path })
+			overlaps = append(overlaps, &BranchOverlap{A: a.InFlightBranch, B: b.InFlightBranch, Files: files})
+		}
+	}
+
+	sort.SliceStable(overlaps, func(i, j int) bool {
+		li, lj := overlaps[i].Likely(), overlaps[j].Likely()
+		if li != lj {
+			return li
+		}
+		return len(overlaps[i].Files) > len(overlaps[j].Files)
+	})
+	return overlaps
+}
+
+// hunksOverlap reports whether any changed regions of two diffs against the
+// same base overlap or are adjacent. Files without hunks are binary, and
+// binary changes on both sides always conflict.
+func hunksOverlap(a, b []git.DiffHunk) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, ha := range a {
+		loA, hiA := hunkSpan(ha)
+		for _, hb := range b {
+			loB, hiB := hunkSpan(hb)
+			if loA <= hiB+1 && loB <= hiA+1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hunkSpan returns the base lines a hunk touches. Pure insertions sit
+// after OldStart, so they touch that line's boundary.
+func hunkSpan(h git.DiffHunk) (int, int) {
+	if h.OldLines == 0 {
+		return h.OldStart, h.OldStart
+	}
+	return h.OldStart, h.OldStart + h.OldLines - 1
+}
+
+// ConflictWarnings remembers which overlaps polecats have already been warned
+// about, so each heartbeat doesn't resend the same mail.
+// Stored at <rig>/.runtime/refinery/conflict-warnings.json.
+type ConflictWarnings struct {
+	// Warned maps an overlap key to the files it was warned about.
+	Warned map[string]ConflictWarning `json:"warned"`
+
+	path string
+}
+
+// ConflictWarning records one sent overlap warning.
+type ConflictWarning struct {
+	Files    []string  `json:"files"`
+	WarnedAt time.Time `json:"warned_at"`
+}
+
+// ConflictWarningsPath returns the path to a rig's conflict warning state.
+func ConflictWarningsPath(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "refinery", "conflict-warnings.json")
+}
+
+// LoadConflictWarnings loads a rig's conflict warning state.
+// A missing file yields empty state.
+func LoadConflictWarnings(rigPath string) (*ConflictWarnings, error) {
+	w := &ConflictWarnings{
+		Warned: make(map[string]ConflictWarning),
+		path:   ConflictWarningsPath(rigPath),
+	}
+
+	data, err := os.ReadFile(w.path) //nolint:gosec // G304: path is constructed from trusted rigPath
+	if err != nil {
+		if os.IsNotExist(err) {
+			return w, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, w); err != nil {
+		return nil, err
+	}
+	if w.Warned == nil {
+		w.Warned = make(map[string]ConflictWarning)
+	}
+	return w, nil
+}
+
+// Save writes the warning state back to disk.
+func (w *ConflictWarnings) Save() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(w.path, w)
+}
+
+// NeedsWarning reports whether an overlap is new or has grown since the last
+// warning. Pairs that no longer overlap are forgotten by Prune.
+func (w *ConflictWarnings) NeedsWarning(o *BranchOverlap) bool {
+	prev, ok := w.Warned[o.Key()]
+	if !ok {
+		return true
+	}
+	seen := make(map[string]bool, len(prev.Files))
+	for _, f := range prev.Files {
+		seen[f] = true
+	}
+	for _, f := range o.Files {
+		if f.Hunks && !seen[f.Path] {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkWarned records that an overlap's polecats were warned.
+func (w *ConflictWarnings) MarkWarned(o *BranchOverlap) {
+	var files []string
+	for _, f := range o.Files {
+		if f.Hunks {
+			files = append(files, f.Path)
+		}
+	}
+	w.Warned[o.Key()] = ConflictWarning{Files: files, WarnedAt: time.Now().UTC()}
+}
+
+// Prune forgets warnings for pairs that no longer overlap, so a pair that
+// collides again later gets a fresh warning.
+func (w *ConflictWarnings) Prune(current []*BranchOverlap) {
+	live := make(map[string]bool, len(current))
+	for _, o := range current {
+		live[o.Key()] = true
+	}
+	for key := range w.Warned {
+		if !live[key] {
+			delete(w.Warned, key)
+		}
+	}
+}
+
+// NewConflictWarningMessage builds the mail warning one polecat that its
+// branch is likely to conflict with another in-flight branch.
+func NewConflictWarningMessage(rigName string, self, other InFlightBranch, files []string) *mail.Message {
+	otherDesc := other.Branch
+	if other.Polecat != "" {
+		otherDesc = fmt.Sprintf("%s (polecat %s)", other.Branch, other.Polecat)
+	}
+	if other.MRID != "" {
+		otherDesc += fmt.Sprintf(", already in the merge queue as %s", other.MRID)
+	}
+
+	advice := "Coordinate with the other polecat before going further, or ask the Mayor to serialize the two tasks."
+	if other.MRID != "" {
+		advice = "The other branch is already queued and will likely land first. Consider rebasing onto it once it merges (gt mq list " + rigName + ") rather than diverging further."
+	}
+
+	msg := mail.NewMessage(
+		rigName+"/refinery",
+		rigName+"/"+self.Polecat,
+		fmt.Sprintf("CONFLICT_PREDICTED %s", self.Branch),
+		fmt.Sprintf(`Your branch is editing the same lines as another in-flight branch.
+It will likely conflict at merge time.
+
+Branch: %s
+Overlaps-With: %s
+Files: %s
+
+%s
+
+See 'gt mq conflicts %s' for the full overlap matrix.`,
+			self.Branch, otherDesc, strings.Join(files, ", "), advice, rigName),
+	)
+	msg.Priority = mail.PriorityNormal
+	return msg
+}
+
+// WarnOverlaps mails the polecats on both sides of every likely conflict that
+// hasn't been warned about yet. Returns the number of messages sent.
+func WarnOverlaps(router *mail.Router, rigName, rigPath string, overlaps []*BranchOverlap) (int, error) {
+	warnings, err := LoadConflictWarnings(rigPath)
+	if err != nil {
+		return 0, fmt.Errorf("loading conflict warnings: %w", err)
+	}
+	warnings.Prune(overlaps)
+
+	sent := 0
+	for _, o := range overlaps {
+		if !o.Likely() || !warnings.NeedsWarning(o) {
+			continue
+		}
+		var files []string
+		for _, f := range o.Files {
+			if f.Hunks {
+				files = append(files, f.Path)
+			}
+		}
+		for _, pair := range [][2]InFlightBranch{{o.A, o.B}, {o.B, o.A}} {
+			if pair[0].Polecat == "" {
+				continue
+			}
+			if err := router.Send(NewConflictWarningMessage(rigName, pair[0], pair[1], files)); err != nil {
+				return sent, fmt.Errorf("warning %s: %w", pair[0].Polecat, err)
+			}
+			sent++
+		}
+		warnings.MarkWarned(o)
+	}
+
+	if err := warnings.Save(); err != nil {
+		return sent, fmt.Errorf("saving conflict warnings: %w", err)
+	}
+	return sent, nil
+}
+
+// PredictConflicts diffs the in-flight branches against the merge target and
+// returns their changes along with every pairwise overlap.
+func (e *Engineer) PredictConflicts(branches []InFlightBranch) ([]*BranchChanges, []*BranchOverlap) {
+	changes := CollectBranchChanges(e.git, ConflictTargetRef(e.git, e.config.TargetBranch), branches)
+	return changes, FindOverlaps(changes)
+}
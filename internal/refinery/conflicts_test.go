@@ -0,0 +1,146 @@
+package refinery
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// setupConflictRepo creates a repo with a shared file and three branches:
+// "a" and "b" edit the same line, "c" edits a distant line.
+func setupConflictRepo(t *testing.T) *git.Git {
+	t.Helper()
+	dir := t.TempDir()
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "shared.go"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	base := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n"
+	run("init", "-b", "main")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test User")
+	write(base)
+	run("add", ".")
+	run("commit", "-m", "initial")
+
+	for _, br := range []struct{ name, content string }{
+		{"a", "1\n2\nA\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n"},
+		{"b", "1\n2\nB\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n"},
+		{"c", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\nC\n19\n20\n"},
+	} {
+		run("checkout", "-q", "-b", br.name, "main")
+		write(br.content)
+		run("commit", "-q", "-am", "edit "+br.name)
+	}
+	run("checkout", "-q", "main")
+
+	return git.NewGit(dir)
+}
+
+func TestPredictConflicts(t *testing.T) {
+	g := setupConflictRepo(t)
+
+	changes := CollectBranchChanges(g, ConflictTargetRef(g, "main"), []InFlightBranch{
+		{Branch: "a", Polecat: "nux"},
+		{Branch: "b", Polecat: "toast"},
+		{Branch: "c", Polecat: "slit"},
+		{Branch: "b", MRID: "gt-mr1"},
+		{Branch: "missing", Polecat: "ghost"},
+	})
+	if len(changes) != 3 {
+		t.Fatalf("got %d branch changes, want 3 (duplicate merged, missing skipped)", len(changes))
+	}
+	if changes[1].MRID != "gt-mr1" {
+		t.Errorf("duplicate branch MR not merged: %+v", changes[1].InFlightBranch)
+	}
+
+	overlaps := FindOverlaps(changes)
+	if len(overlaps) != 3 {
+		t.Fatalf("got %d overlaps, want 3", len(overlaps))
+	}
+
+	first := overlaps[0]
+	if !first.Likely() || first.Key() != "a b" {
+		t.Errorf("first overlap = %s likely=%v, want likely a/b conflict", first.Key(), first.Likely())
+	}
+	for _, o := range overlaps[1:] {
+		if o.Likely() {
+			t.Errorf("overlap %s should touch different regions", o.Key())
+		}
+	}
+}
+
+func TestHunksOverlap(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []git.DiffHunk
+		want bool
+	}{
+		{"same line", []git.DiffHunk{{OldStart: 5, OldLines: 1}}, []git.DiffHunk{{OldStart: 5, OldLines: 1}}, true},
+		{"adjacent", []git.DiffHunk{{OldStart: 5, OldLines: 1}}, []git.DiffHunk{{OldStart: 6, OldLines: 2}}, true},
+		{"distant", []git.DiffHunk{{OldStart: 5, OldLines: 1}}, []git.DiffHunk{{OldStart: 20, OldLines: 2}}, false},
+		{"insert inside", []git.DiffHunk{{OldStart: 10, OldLines: 5}}, []git.DiffHunk{{OldStart: 12, OldLines: 0}}, true},
+		{"binary", nil, []git.DiffHunk{{OldStart: 1, OldLines: 1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hunksOverlap(tt.a, tt.b); got != tt.want {
+				t.Errorf("hunksOverlap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConflictWarnings_Dedup(t *testing.T) {
+	rigPath := t.TempDir()
+	o := &BranchOverlap{
+		A:     InFlightBranch{Branch: "b", Polecat: "toast"},
+		B:     InFlightBranch{Branch: "a", Polecat: "nux"},
+		Files: []FileOverlap{{Path: "x.go", Hunks: true}},
+	}
+
+	w, err := LoadConflictWarnings(rigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.NeedsWarning(o) {
+		t.Fatal("new overlap should need a warning")
+	}
+	w.MarkWarned(o)
+	if err := w.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err = LoadConflictWarnings(rigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.NeedsWarning(o) {
+		t.Error("already-warned overlap should not need another warning")
+	}
+
+	o.Files = append(o.Files, FileOverlap{Path: "y.go", Hunks: true})
+	if !w.NeedsWarning(o) {
+		t.Error("overlap that grew should need a new warning")
+	}
+
+	w.Prune(nil)
+	if len(w.Warned) != 0 {
+		t.Errorf("Prune should forget resolved overlaps, got %v", w.Warned)
+	}
+}
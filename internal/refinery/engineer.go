@@ -45,7 +45,9 @@ type MergeQueueConfig struct {
 	// DeleteMergedBranches controls whether to delete branches after merge.
 	DeleteMergedBranches bool `json:"delete_merged_branches"`
 
-	// RetryFlakyTests is the number of times to retry flaky tests.
+	// RetryFlakyTests is the number of times to retry flaky tests. Without
+	// TestOutputFormat it counts whole runs of the command (at least one);
+	// with it, reruns of just the failing tests (0 = no reruns).
 	RetryFlakyTests int `json:"retry_flaky_tests"`
 
 	// PollInterval is how often to check for new MRs.
@@ -53,6 +55,36 @@ type MergeQueueConfig struct {
 
 	// MaxConcurrent is the maximum number of MRs to process concurrently.
 	MaxConcurrent int `json:"max_concurrent"`
+
+	// PostMergeVerify runs VerifyCommand on the target HEAD after each push.
+	// On failure the merge is reverted and the queue is paused.
+	PostMergeVerify bool `json:"post_merge_verify"`
+
+	// VerifyCommand is the post-merge verification command.
+	// Falls back to TestCommand when empty.
+	VerifyCommand string `json:"verify_command"`
+
+	// TestOutputFormat enables flake detection by parsing test results:
+	// "" (exit code only), "go-json" (`go test -json` on stdout) or "junit".
+	// When set, RetryFlakyTests reruns only the failing tests.
+	TestOutputFormat string `json:"test_output_format"`
+
+	// JUnitReport is the JUnit XML file TestCommand writes (relative to the worktree).
+	JUnitReport string `json:"junit_report"`
+
+	// RerunCommand reruns only failing tests, with "{tests}" replaced by their
+	// space-separated names. go-json defaults to `go test -json -run`; other
+	// formats rerun TestCommand when empty.
+	RerunCommand string `json:"rerun_command"`
+
+	// FlakyQuarantineThreshold is the number of recorded flakes after which a
+	// test's failures no longer bounce MRs; they are waived and recorded in
+	// the flake history instead (0 disables quarantine).
+	FlakyQuarantineThreshold int `json:"flaky_quarantine_threshold"`
+
+	// FlakyBeadThreshold is the number of recorded flakes after which a bug
+	// bead is filed for the test (0 disables).
+	FlakyBeadThreshold int `json:"flaky_bead_threshold"`
 }
 
 // DefaultMergeQueueConfig returns sensible defaults for merge queue configuration.
@@ -68,6 +100,9 @@ func DefaultMergeQueueConfig() *MergeQueueConfig {
 		RetryFlakyTests:      1,
 		PollInterval:         30 * time.Second,
 		MaxConcurrent:        1,
+
+		FlakyQuarantineThreshold: 2,
+		FlakyBeadThreshold:       5,
 	}
 }
 
@@ -88,6 +123,8 @@ type MRInfo struct {
 	ConvoyCreatedAt *time.Time // Convoy creation time
 	CreatedAt       time.Time  // MR creation time
 	BlockedBy       string     // Task ID blocking this MR
+	ParentMR        string     // MR this one is stacked on, if any
+	ParentHead      string     // Parent branch tip when the parent landed
 }
 
 // Engineer is the merge queue processor that polls for ready merge-requests
@@ -175,6 +212,14 @@ func (e *Engineer) LoadConfig() error {
 		RetryFlakyTests      *int    `json:"retry_flaky_tests"`
 		PollInterval         *string `json:"poll_interval"`
 		MaxConcurrent        *int    `json:"max_concurrent"`
+		PostMergeVerify      *bool   `json:"post_merge_verify"`
+		VerifyCommand        *string `json:"verify_command"`
+
+		TestOutputFormat         *string `json:"test_output_format"`
+		JUnitReport              *string `json:"junit_report"`
+		RerunCommand             *string `json:"rerun_command"`
+		FlakyQuarantineThreshold *int    `json:"flaky_quarantine_threshold"`
+		FlakyBeadThreshold       *int    `json:"flaky_bead_threshold"`
 	}
 
 	if err := json.Unmarshal(rawConfig.MergeQueue, &mqRaw); err != nil {
@@ -209,6 +254,32 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.MaxConcurrent != nil {
 		e.config.MaxConcurrent = *mqRaw.MaxConcurrent
 	}
+	if mqRaw.PostMergeVerify != nil {
+		e.config.PostMergeVerify = *mqRaw.PostMergeVerify
+	}
+	if mqRaw.VerifyCommand != nil {
+		e.config.VerifyCommand = *mqRaw.VerifyCommand
+	}
+	if mqRaw.TestOutputFormat != nil {
+		switch *mqRaw.TestOutputFormat {
+		case TestOutputNone, TestOutputGoJSON, TestOutputJUnit:
+			e.config.TestOutputFormat = *mqRaw.TestOutputFormat
+		default:
+			return fmt.Errorf("invalid test_output_format %q (want %q or %q)", *mqRaw.TestOutputFormat, TestOutputGoJSON, TestOutputJUnit)
+		}
+	}
+	if mqRaw.JUnitReport != nil {
+		e.config.JUnitReport = *mqRaw.JUnitReport
+	}
+	if mqRaw.RerunCommand != nil {
+		e.config.RerunCommand = *mqRaw.RerunCommand
+	}
+	if mqRaw.FlakyQuarantineThreshold != nil {
+		e.config.FlakyQuarantineThreshold = *mqRaw.FlakyQuarantineThreshold
+	}
+	if mqRaw.FlakyBeadThreshold != nil {
+		e.config.FlakyBeadThreshold = *mqRaw.FlakyBeadThreshold
+	}
 	if mqRaw.PollInterval != nil {
 		dur, err := time.ParseDuration(*mqRaw.PollInterval)
 		if err != nil {
@@ -227,11 +298,13 @@ func (e *Engineer) Config() *MergeQueueConfig {
 
 // ProcessResult contains the result of processing a merge request.
 type ProcessResult struct {
-	Success     bool
-	MergeCommit string
-	Error       string
-	Conflict    bool
-	TestsFailed bool
+	Success         bool
+	MergeCommit     string
+	Error           string
+	Conflict        bool
+	TestsFailed     bool
+	PostMergeFailed bool   // Merge landed but broke the target and was reverted
+	RevertCommit    string // Revert commit SHA when PostMergeFailed
 }
 
 // ProcessMR processes a single merge request from a beads issue.
@@ -251,6 +324,9 @@ func (e *Engineer) ProcessMR(ctx context.Context, mr *beads.Issue) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Target: %s\n", mrFields.Target)
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mrFields.Worker)
 
+	if mrFields.ParentHead != "" {
+		return e.mergeStacked(ctx, mrFields.Branch, mrFields.Target, mrFields.SourceIssue, mrFields.ParentHead)
+	}
 	return e.doMerge(ctx, mrFields.Branch, mrFields.Target, mrFields.SourceIssue)
 }
 
@@ -369,6 +445,13 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}
 	}
 
+	// Step 8: Verify the new target HEAD (optional)
+	if e.config.PostMergeVerify {
+		if result := e.verifyPostMerge(ctx, branch, target, mergeCommit); !result.Success {
+			return result
+		}
+	}
+
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Successfully merged: %s\n", mergeCommit[:8])
 	return ProcessResult{
 		Success:     true,
@@ -378,11 +461,20 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 
 // runTests runs the configured test command and returns the result.
 func (e *Engineer) runTests(ctx context.Context) ProcessResult {
-	if e.config.TestCommand == "" {
+	if e.config.TestOutputFormat != TestOutputNone {
+		return e.runStructuredTests(ctx)
+	}
+	return e.runCommand(ctx, e.config.TestCommand)
+}
+
+// runCommand runs a test or verify command in the work directory, retrying
+// up to RetryFlakyTests times before reporting failure.
+func (e *Engineer) runCommand(ctx context.Context, command string) ProcessResult {
+	if command == "" {
 		return ProcessResult{Success: true}
 	}
 
-	// Run the test command with retries for flaky tests
+	// Run the command with retries for flaky tests
 	maxRetries := e.config.RetryFlakyTests
 	if maxRetries < 1 {
 		maxRetries = 1
@@ -394,9 +486,9 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying tests (attempt %d/%d)...\n", attempt, maxRetries)
 		}
 
-		// Note: TestCommand comes from rig's config.json (trusted infrastructure config),
+		// Note: TestCommand/VerifyCommand come from rig's config.json (trusted infrastructure config),
 		// not from PR branches. Shell execution is intentional for flexibility (pipes, etc).
-		cmd := exec.CommandContext(ctx, "sh", "-c", e.config.TestCommand) //nolint:gosec // G204: TestCommand is from trusted rig config
+		cmd := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // G204: command is from trusted rig config
 		cmd.Dir = e.workDir
 		var stdout, stderr bytes.Buffer
 		cmd.Stdout = &stdout
@@ -438,6 +530,9 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 		mrFields = &beads.MRFields{}
 	}
 
+	// 0. Let MRs stacked on this one rebase past its commits
+	e.recordParentLanded(mr.ID, mrFields.Branch)
+
 	// 1. Update MR with merge_commit SHA
 	mrFields.MergeCommit = result.MergeCommit
 	mrFields.CloseReason = "merged"
@@ -533,6 +628,13 @@ func (e *Engineer) syncCrewWorkspaces() {
 // handleFailure handles a failed merge request.
 // Reopens the MR for rework and logs the failure.
 func (e *Engineer) handleFailure(mr *beads.Issue, result ProcessResult) {
+	if result.PostMergeFailed {
+		e.recordPostMergeFailure(mr.ID, result)
+		e.sendPostMergeRework(beads.ParseMRFields(mr), result)
+		_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ Reverted: %s - %s\n", mr.ID, result.Error)
+		return
+	}
+
 	// Reopen the MR (back to open status for rework)
 	open := "open"
 	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Status: &open}); err != nil {
@@ -552,6 +654,12 @@ func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mr.Worker)
 	_, _ = fmt.Fprintf(e.output, "  Source: %s\n", mr.SourceIssue)
 
+	// Stacked MR whose parent landed: replay only its own commits
+	if mr.ParentHead != "" {
+		_, _ = fmt.Fprintf(e.output, "  Stacked on: %s\n", mr.ParentMR)
+		return e.mergeStacked(ctx, mr.Branch, mr.Target, mr.SourceIssue, mr.ParentHead)
+	}
+
 	// Use the shared merge logic
 	return e.doMerge(ctx, mr.Branch, mr.Target, mr.SourceIssue)
 }
@@ -572,6 +680,10 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Released merge slot\n")
 	}
 
+	// Let MRs stacked on this one rebase past its commits
+	// (before the MR closes and unblocks them, and before the branch is deleted)
+	e.recordParentLanded(mr.ID, mr.Branch)
+
 	// Update and close the MR bead
 	if mr.ID != "" {
 		// Fetch the MR bead to update its fields
@@ -648,11 +760,19 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	} else if result.TestsFailed {
 		failureType = "tests"
 	}
-	msg := protocol.NewMergeFailedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, failureType, result.Error)
-	if err := e.router.Send(msg); err != nil {
-		fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGE_FAILED to witness: %v\n", err)
+	if result.PostMergeFailed {
+		// The merge landed and was reverted: the polecat must rework against the new target.
+		if mr.ID != "" {
+			e.recordPostMergeFailure(mr.ID, result)
+		}
+		e.sendPostMergeRework(&beads.MRFields{Worker: mr.Worker, Branch: mr.Branch, SourceIssue: mr.SourceIssue, Target: mr.Target}, result)
 	} else {
-		fmt.Fprintf(e.output, "[Engineer] Notified witness of merge failure for %s\n", mr.Worker)
+		msg := protocol.NewMergeFailedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, failureType, result.Error)
+		if err := e.router.Send(msg); err != nil {
+			fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGE_FAILED to witness: %v\n", err)
+		} else {
+			fmt.Fprintf(e.output, "[Engineer] Notified witness of merge failure for %s\n", mr.Worker)
+		}
 	}
 
 	// If this was a conflict, create a conflict-resolution task for dispatch
@@ -812,10 +932,16 @@ func (e *Engineer) IsBeadOpen(beadID string) (bool, error) {
 // ListReadyMRs returns MRs that are ready for processing:
 // - Not claimed by another worker (checked via assignee field)
 // - Not blocked by an open task (handled by bd ready)
+// - Queue not paused by a post-merge verification failure
 // Sorted by priority (highest first).
 //
 // This queries beads for merge-request wisps.
 func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
+	// Nothing is ready while the queue is paused on a broken target
+	if paused, _, err := IsQueuePaused(e.rig.Path); err == nil && paused {
+		return nil, nil
+	}
+
 	// Query beads for ready merge-request issues
 	issues, err := e.beads.ReadyWithType("merge-request")
 	if err != nil {
@@ -871,6 +997,8 @@ func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
 			ConvoyID:        fields.ConvoyID,
 			ConvoyCreatedAt: convoyCreatedAt,
 			CreatedAt:       createdAt,
+			ParentMR:        fields.ParentMR,
+			ParentHead:      fields.ParentHead,
 		}
 		mrs = append(mrs, mr)
 	}
@@ -960,6 +1088,8 @@ func (e *Engineer) ListBlockedMRs() ([]*MRInfo, error) {
 			ConvoyCreatedAt: convoyCreatedAt,
 			CreatedAt:       createdAt,
 			BlockedBy:       blockedBy,
+			ParentMR:        fields.ParentMR,
+			ParentHead:      fields.ParentHead,
 		}
 		mrs = append(mrs, mr)
 	}
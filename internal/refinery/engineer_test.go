@@ -118,6 +118,34 @@ func TestEngineer_LoadConfig_WithMergeQueue(t *testing.T) {
 	}
 }
 
+func TestEngineer_LoadConfig_PostMergeVerify(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := map[string]interface{}{
+		"merge_queue": map[string]interface{}{
+			"post_merge_verify": true,
+			"verify_command":    "make verify",
+		},
+	}
+
+	data, _ := json.MarshalIndent(config, "", "  ")
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+
+	if !e.config.PostMergeVerify {
+		t.Error("expected PostMergeVerify true")
+	}
+	if e.config.VerifyCommand != "make verify" {
+		t.Errorf("expected VerifyCommand 'make verify', got %q", e.config.VerifyCommand)
+	}
+}
+
 func TestEngineer_LoadConfig_NoMergeQueueSection(t *testing.T) {
 	// Create a temp directory with config.json without merge_queue
 	tmpDir, err := os.MkdirTemp("", "engineer-test-*")
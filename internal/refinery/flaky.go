@@ -0,0 +1,180 @@
+package refinery
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// FlakeRecord tracks one test's outcomes across merge queue runs.
+type FlakeRecord struct {
+	// Package and Name identify the test.
+	Package string `json:"package"`
+	Name    string `json:"name"`
+
+	// Passes and Failures count every recorded run (including reruns).
+	Passes   int `json:"passes"`
+	Failures int `json:"failures"`
+
+	// Flakes counts runs where the test failed and then passed on rerun
+	// against the same commit.
+	Flakes int `json:"flakes"`
+
+	// Waived counts failures ignored because the test was quarantined.
+	Waived int `json:"waived,omitempty"`
+
+	// FirstSeenCommit is the target commit where the test first flaked.
+	FirstSeenCommit string    `json:"first_seen_commit,omitempty"`
+	FirstSeenAt     time.Time `json:"first_seen_at,omitempty"`
+	LastFlakeAt     time.Time `json:"last_flake_at,omitempty"`
+
+	// BeadID is the bug filed for this test once it became chronically flaky.
+	BeadID string `json:"bead_id,omitempty"`
+}
+
+// Key returns the history key for this record ("pkg.TestName").
+func (r *FlakeRecord) Key() string {
+	return TestOutcome{Package: r.Package, Name: r.Name}.Key()
+}
+
+// FlakeHistory is the per-rig record of test outcomes used for flake detection.
+// Stored at <rig>/.runtime/refinery/flakes.json.
+type FlakeHistory struct {
+	Tests map[string]*FlakeRecord `json:"tests"`
+
+	path string
+}
+
+// FlakeHistoryPath returns the path to a rig's flake history file.
+func FlakeHistoryPath(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "refinery", "flakes.json")
+}
+
+// LoadFlakeHistory loads a rig's flake history.
+// A missing file yields an empty history.
+func LoadFlakeHistory(rigPath string) (*FlakeHistory, error) {
+	h := &FlakeHistory{
+		Tests: make(map[string]*FlakeRecord),
+		path:  FlakeHistoryPath(rigPath),
+	}
+
+	data, err := os.ReadFile(h.path) //nolint:gosec // G304: path is constructed from trusted rigPath
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+	if h.Tests == nil {
+		h.Tests = make(map[string]*FlakeRecord)
+	}
+	return h, nil
+}
+
+// Save writes the flake history back to disk.
+func (h *FlakeHistory) Save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(h.path, h)
+}
+
+// record returns the record for a test, creating it if needed.
+func (h *FlakeHistory) record(o TestOutcome) *FlakeRecord {
+	key := o.Key()
+	rec, ok := h.Tests[key]
+	if !ok {
+		rec = &FlakeRecord{Package: o.Package, Name: o.Name}
+		h.Tests[key] = rec
+	}
+	return rec
+}
+
+// RecordRun adds pass/fail counts for every outcome in a report.
+// Only tests that have failed at least once are kept, so the history
+// doesn't grow with the full test suite.
+func (h *FlakeHistory) RecordRun(report *TestReport) {
+	for _, o := range report.Outcomes {
+		if o.Passed {
+			if rec, ok := h.Tests[o.Key()]; ok {
+				rec.Passes++
+			}
+			continue
+		}
+		h.record(o).Failures++
+	}
+}
+
+// RecordFlake marks a test as having failed then passed on rerun at commit.
+func (h *FlakeHistory) RecordFlake(o TestOutcome, commit string) {
+	rec := h.record(o)
+	now := time.Now().UTC()
+	rec.Flakes++
+	rec.LastFlakeAt = now
+	if rec.FirstSeenCommit == "" {
+		rec.FirstSeenCommit = commit
+		rec.FirstSeenAt = now
+	}
+}
+
+// RecordWaived marks a quarantined test's failure as ignored.
+func (h *FlakeHistory) RecordWaived(o TestOutcome) {
+	h.record(o).Waived++
+}
+
+// IsQuarantined reports whether a test has flaked at least threshold times.
+// Failures of quarantined tests don't bounce MRs back to the polecat.
+func (h *FlakeHistory) IsQuarantined(o TestOutcome, threshold int) bool {
+	if threshold < 1 {
+		return false
+	}
+	rec, ok := h.Tests[o.Key()]
+	return ok && rec.Flakes >= threshold
+}
+
+// Flaky returns every test that has flaked at least once, most flakes first.
+func (h *FlakeHistory) Flaky() []*FlakeRecord {
+	var recs []*FlakeRecord
+	for _, rec := range h.Tests {
+		if rec.Flakes > 0 {
+			recs = append(recs, rec)
+		}
+	}
+	SortFlakeRecords(recs)
+	return recs
+}
+
+// SortFlakeRecords orders records by flake count, then failures, then name.
+func SortFlakeRecords(recs []*FlakeRecord) {
+	sort.Slice(recs, func(i, j int) bool {
+		if recs[i].Flakes != recs[j].Flakes {
+			return recs[i].Flakes > recs[j].Flakes
+		}
+		if recs[i].Failures != recs[j].Failures {
+			return recs[i].Failures > recs[j].Failures
+		}
+		return recs[i].Key() < recs[j].Key()
+	})
+}
+
+// NeedsBead returns chronically flaky tests (threshold or more flakes)
+// that don't have a bug filed yet.
+func (h *FlakeHistory) NeedsBead(threshold int) []*FlakeRecord {
+	if threshold < 1 {
+		return nil
+	}
+	var recs []*FlakeRecord
+	for _, rec := range h.Flaky() {
+		if rec.Flakes >= threshold && rec.BeadID == "" {
+			recs = append(recs, rec)
+		}
+	}
+	return recs
+}
@@ -0,0 +1,180 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestFlakeHistory_RecordAndPersist(t *testing.T) {
+	rigPath := t.TempDir()
+
+	h, err := LoadFlakeHistory(rigPath)
+	if err != nil {
+		t.Fatalf("LoadFlakeHistory: %v", err)
+	}
+
+	flaky := TestOutcome{Package: "pkg", Name: "TestFlaky"}
+	h.RecordRun(&TestReport{Outcomes: []TestOutcome{
+		{Package: "pkg", Name: "TestOK", Passed: true},
+		flaky,
+	}})
+	if _, ok := h.Tests["pkg.TestOK"]; ok {
+		t.Error("tests that never failed should not be tracked")
+	}
+
+	h.RecordFlake(flaky, "abc123")
+	h.RecordFlake(flaky, "def456")
+
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadFlakeHistory(rigPath)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	rec := loaded.Tests["pkg.TestFlaky"]
+	if rec == nil {
+		t.Fatal("expected pkg.TestFlaky to be persisted")
+	}
+	if rec.Flakes != 2 || rec.Failures != 1 {
+		t.Errorf("Flakes=%d Failures=%d, want 2 and 1", rec.Flakes, rec.Failures)
+	}
+	if rec.FirstSeenCommit != "abc123" {
+		t.Errorf("FirstSeenCommit = %q, want %q", rec.FirstSeenCommit, "abc123")
+	}
+
+	if !loaded.IsQuarantined(flaky, 2) {
+		t.Error("expected test to be quarantined at threshold 2")
+	}
+	if loaded.IsQuarantined(flaky, 3) {
+		t.Error("expected test not to be quarantined at threshold 3")
+	}
+	if loaded.IsQuarantined(flaky, 0) {
+		t.Error("threshold 0 should disable quarantine")
+	}
+
+	if got := loaded.NeedsBead(2); len(got) != 1 {
+		t.Errorf("NeedsBead(2) = %d records, want 1", len(got))
+	}
+	rec.BeadID = "gt-flaky"
+	if got := loaded.NeedsBead(2); len(got) != 0 {
+		t.Errorf("NeedsBead should skip tests with a bead, got %d", len(got))
+	}
+}
+
+// writeFlakyScript writes a script emitting go test -json output in which
+// TestFlaky fails on the first run only and TestBroken always fails.
+func writeFlakyScript(t *testing.T, dir string, withBroken bool) string {
+	t.Helper()
+	script := `#!/bin/sh
+echo '{"Action":"pass","Package":"pkg","Test":"TestOK"}'
+if [ -f "$(dirname "$0")/ran" ]; then
+  echo '{"Action":"pass","Package":"pkg","Test":"TestFlaky"}'
+else
+  touch "$(dirname "$0")/ran"
+  echo '{"Action":"fail","Package":"pkg","Test":"TestFlaky"}'
+  FAILED=1
+fi
+`
+	if withBroken {
+		script += `echo '{"Action":"fail","Package":"pkg","Test":"TestBroken"}'
+exit 1
+`
+	} else {
+		script += `[ -n "$FAILED" ] && exit 1
+exit 0
+`
+	}
+	path := filepath.Join(dir, "fake-go-test.sh")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newFlakyEngineer(t *testing.T, script string) *Engineer {
+	t.Helper()
+	cfg := DefaultMergeQueueConfig()
+	cfg.TestOutputFormat = TestOutputGoJSON
+	cfg.TestCommand = script
+	cfg.RerunCommand = script + " {tests}"
+	cfg.RetryFlakyTests = 1
+	cfg.FlakyBeadThreshold = 0
+	workDir := t.TempDir()
+	return &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: t.TempDir()},
+		git:     git.NewGit(workDir),
+		config:  cfg,
+		workDir: workDir,
+		output:  &bytes.Buffer{},
+	}
+}
+
+func TestRunStructuredTests_FlakePassesAndIsRecorded(t *testing.T) {
+	e := newFlakyEngineer(t, writeFlakyScript(t, t.TempDir(), false))
+
+	result := e.runTests(context.Background())
+	if !result.Success {
+		t.Fatalf("expected flaky failure to pass on rerun, got %q", result.Error)
+	}
+
+	h, err := LoadFlakeHistory(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := h.Tests["pkg.TestFlaky"]
+	if rec == nil || rec.Flakes != 1 {
+		t.Fatalf("expected one recorded flake, got %+v", rec)
+	}
+}
+
+func TestRunStructuredTests_HardFailureBounces(t *testing.T) {
+	e := newFlakyEngineer(t, writeFlakyScript(t, t.TempDir(), true))
+
+	result := e.runTests(context.Background())
+	if result.Success || !result.TestsFailed {
+		t.Fatalf("expected test failure, got %+v", result)
+	}
+	if result.Error != "tests failed: pkg.TestBroken" {
+		t.Errorf("Error = %q, want only the non-flaky test", result.Error)
+	}
+}
+
+func TestRunStructuredTests_QuarantinedFailureIsWaived(t *testing.T) {
+	e := newFlakyEngineer(t, writeFlakyScript(t, t.TempDir(), true))
+
+	// TestBroken has flaked before; it's quarantined at the default threshold
+	h, _ := LoadFlakeHistory(e.rig.Path)
+	broken := TestOutcome{Package: "pkg", Name: "TestBroken"}
+	h.RecordFlake(broken, "abc")
+	h.RecordFlake(broken, "def")
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	result := e.runTests(context.Background())
+	if !result.Success {
+		t.Fatalf("expected quarantined failure to be waived, got %q", result.Error)
+	}
+	h, _ = LoadFlakeHistory(e.rig.Path)
+	if rec := h.Tests["pkg.TestBroken"]; rec == nil || rec.Waived != 1 {
+		t.Errorf("expected the waived failure recorded, got %+v", rec)
+	}
+}
+
+func TestRunStructuredTests_NoRerunsWhenRetriesOff(t *testing.T) {
+	e := newFlakyEngineer(t, writeFlakyScript(t, t.TempDir(), false))
+	e.config.RetryFlakyTests = 0
+
+	result := e.runTests(context.Background())
+	if result.Success || result.Error != "tests failed: pkg.TestFlaky" {
+		t.Fatalf("expected failure without reruns, got %+v", result)
+	}
+}
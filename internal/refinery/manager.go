@@ -17,6 +17,7 @@ import (
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
@@ -146,6 +147,11 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 	} else {
 		command = config.BuildAgentStartupCommand("refinery", m.rig.Name, townRoot, m.rig.Path, initialPrompt)
 	}
+	// Apply the refinery role's resource limits (cgroup v2, Linux only)
+	command, warnings := sandbox.WrapRoleChecked(townRoot, m.rig.Path, "refinery", sessionID, command)
+	for _, w := range warnings {
+		_, _ = fmt.Fprintf(m.output, "Warning: %s\n", w)
+	}
 
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
@@ -324,6 +330,7 @@ func (m *Manager) issueToMR(issue *beads.Issue) *MergeRequest {
 		Branch:       fields.Branch,
 		Worker:       fields.Worker,
 		IssueID:      fields.SourceIssue,
+		ParentMR:     fields.ParentMR,
 		TargetBranch: target,
 		Status:       MROpen,
 		CreatedAt:    parseTime(issue.CreatedAt),
@@ -598,6 +605,9 @@ func (m *Manager) RejectMR(idOrBranch string, reason string, notify bool) (*Merg
 		m.notifyWorkerRejected(mr, reason)
 	}
 
+	// MRs stacked on this one can't land without it
+	mr.InvalidatedChildren = m.invalidateStackChildren(mr, notify)
+
 	return mr, nil
 }
 
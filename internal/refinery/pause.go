@@ -0,0 +1,89 @@
+package refinery
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// QueuePauseState represents the merge queue pause file contents.
+// While paused, the Engineer must not land further MRs on the target branch.
+type QueuePauseState struct {
+	// Paused is true if the merge queue is currently paused.
+	Paused bool `json:"paused"`
+
+	// Reason explains why the queue was paused.
+	Reason string `json:"reason,omitempty"`
+
+	// PausedAt is when the queue was paused.
+	PausedAt time.Time `json:"paused_at"`
+
+	// Target is the branch that failed verification (e.g., "main").
+	Target string `json:"target,omitempty"`
+
+	// Branch is the source branch whose merge broke the target.
+	Branch string `json:"branch,omitempty"`
+
+	// MergeCommit is the merge commit that failed verification.
+	MergeCommit string `json:"merge_commit,omitempty"`
+
+	// RevertCommit is the commit that reverted MergeCommit (empty if the revert failed).
+	RevertCommit string `json:"revert_commit,omitempty"`
+}
+
+// GetQueuePauseFile returns the path to a rig's merge queue pause file.
+func GetQueuePauseFile(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "refinery", "paused.json")
+}
+
+// IsQueuePaused checks if a rig's merge queue is currently paused.
+// Returns (isPaused, pauseState, error).
+// If the pause file doesn't exist, returns (false, nil, nil).
+func IsQueuePaused(rigPath string) (bool, *QueuePauseState, error) {
+	data, err := os.ReadFile(GetQueuePauseFile(rigPath)) //nolint:gosec // G304: path is constructed from trusted rigPath
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+
+	var state QueuePauseState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return false, nil, err
+	}
+
+	return state.Paused, &state, nil
+}
+
+// PauseQueue pauses a rig's merge queue by writing the pause file.
+func PauseQueue(rigPath string, state QueuePauseState) error {
+	pauseFile := GetQueuePauseFile(rigPath)
+
+	// Ensure parent directory exists
+	if err := os.MkdirAll(filepath.Dir(pauseFile), 0755); err != nil {
+		return err
+	}
+
+	state.Paused = true
+	if state.PausedAt.IsZero() {
+		state.PausedAt = time.Now().UTC()
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(pauseFile, data, 0600)
+}
+
+// ResumeQueue resumes a rig's merge queue by removing the pause file.
+func ResumeQueue(rigPath string) error {
+	err := os.Remove(GetQueuePauseFile(rigPath))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package refinery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// stackBranchPrefix namespaces the scratch branches used to rebase stacked
+// MRs, so they never collide with polecat branches.
+const stackBranchPrefix = "refinery/stack/"
+
+// StackChildren returns the open MRs stacked directly on parentID.
+func StackChildren(b *beads.Beads, parentID string) ([]*beads.Issue, error) {
+	issues, err := b.List(beads.ListOptions{
+		Status:   "open",
+		Label:    "gt:merge-request",
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("querying merge-requests: %w", err)
+	}
+
+	var children []*beads.Issue
+	for _, issue := range issues {
+		// Workaround for bd list not respecting --status filter
+		if issue.Status != "open" {
+			continue
+		}
+		if fields := beads.ParseMRFields(issue); fields != nil && fields.ParentMR == parentID {
+			children = append(children, issue)
+		}
+	}
+	return children, nil
+}
+
+// recordParentLanded stamps each child of a just-merged MR with the parent's
+// branch tip. The child can then be rebased past the parent's commits onto
+// the squash that landed. Must run before the parent branch is deleted.
+func (e *Engineer) recordParentLanded(parentID, parentBranch string) {
+	if parentID == "" || parentBranch == "" {
+		return
+	}
+	children, err := StackChildren(e.beads, parentID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to find stacked MRs on %s: %v\n", parentID, err)
+		return
+	}
+	if len(children) == 0 {
+		return
+	}
+
+	head, err := e.git.Rev(parentBranch)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to resolve %s for stacked MRs: %v\n", parentBranch, err)
+		return
+	}
+
+	for _, child := range children {
+		fields := beads.ParseMRFields(child)
+		fields.ParentHead = head
+		newDesc := beads.SetMRFields(child, fields)
+		if err := e.beads.Update(child.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to update stacked MR %s: %v\n", child.ID, err)
+			continue
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Stacked MR %s is next in line after %s\n", child.ID, parentID)
+	}
+}
+
+// mergeStacked lands an MR whose parent already merged. The parent's commits
+// reached the target as a squash, so only the child's own commits (those after
+// parentHead) are replayed onto the target in a scratch branch, and the scratch
+// branch is merged. The polecat's branch is left untouched.
+func (e *Engineer) mergeStacked(ctx context.Context, branch, target, sourceIssue, parentHead string) ProcessResult {
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Rebasing stacked branch %s onto %s (past parent %s)...\n", branch, target, shortSHA(parentHead))
+
+	if err := e.git.Checkout(target); err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to checkout target %s: %v", target, err),
+		}
+	}
+	if err := e.git.Pull("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s: %v (continuing)\n", target, err)
+	}
+
+	scratch := stackBranchPrefix + branch
+	if err := e.git.ResetBranch(scratch, branch); err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to create stack branch for %s: %v", branch, err),
+		}
+	}
+	defer func() {
+		_ = e.git.Checkout(target)
+		_ = e.git.DeleteBranch(scratch, true)
+	}()
+
+	if err := e.git.RebaseOnto(target, parentHead, scratch); err != nil {
+		_ = e.git.AbortRebase()
+		return ProcessResult{
+			Success:  false,
+			Conflict: true,
+			Error:    fmt.Sprintf("stacked branch %s does not rebase cleanly onto %s: %v", branch, target, err),
+		}
+	}
+
+	return e.doMerge(ctx, scratch, target, sourceIssue)
+}
+
+// invalidateStackChildren closes every MR stacked (directly or transitively)
+// on a rejected MR. Children carry the parent's commits, so they can't land
+// without it. Returns the IDs of the closed MRs.
+func (m *Manager) invalidateStackChildren(parent *MergeRequest, notify bool) []string {
+	b := beads.New(m.rig.BeadsPath())
+	children, err := StackChildren(b, parent.ID)
+	if err != nil {
+		_, _ = fmt.Fprintf(m.output, "Warning: failed to find stacked MRs on %s: %v\n", parent.ID, err)
+		return nil
+	}
+
+	var closed []string
+	for _, child := range children {
+		reason := fmt.Sprintf("rejected: parent MR %s was rejected", parent.ID)
+		if err := b.CloseWithReason(reason, child.ID); err != nil {
+			_, _ = fmt.Fprintf(m.output, "Warning: failed to close stacked MR %s: %v\n", child.ID, err)
+			continue
+		}
+		closed = append(closed, child.ID)
+
+		childMR := m.issueToMR(child)
+		if notify && childMR.Worker != "" {
+			m.notifyWorkerStackInvalidated(childMR, parent)
+		}
+		closed = append(closed, m.invalidateStackChildren(childMR, notify)...)
+	}
+	return closed
+}
+
+// notifyWorkerStackInvalidated tells a polecat its stacked MR was closed
+// because the MR it builds on was rejected.
+func (m *Manager) notifyWorkerStackInvalidated(mr, parent *MergeRequest) {
+	router := mail.NewRouter(m.workDir)
+	msg := &mail.Message{
+		From:    fmt.Sprintf("%s/refinery", m.rig.Name),
+		To:      fmt.Sprintf("%s/%s", m.rig.Name, mr.Worker),
+		Subject: "Stacked merge request invalidated",
+		Body: fmt.Sprintf(`Your merge request was closed because the MR it is stacked on was rejected.
+
+Branch: %s
+Issue: %s
+Parent MR: %s (%s)
+
+Rebase your own commits onto %s without the parent's commits:
+  git fetch origin
+  git rebase --onto origin/%s %s
+
+Then resubmit with 'gt mq submit'.`,
+			mr.Branch, mr.IssueID, parent.ID, parent.Branch,
+			mr.TargetBranch, mr.TargetBranch, parent.Branch),
+		Priority: mail.PriorityNormal,
+	}
+	_ = router.Send(msg) // best-effort notification
+}
+
+// shortSHA abbreviates a commit SHA for log output.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,126 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// setupStackRepo creates a clone where polecat/child is stacked on
+// polecat/parent, and polecat/parent has already been squash-merged to main.
+// The child edits a file the parent added, so a plain squash merge of the
+// child would conflict with the parent's squash. Returns the clone and the
+// parent branch tip.
+func setupStackRepo(t *testing.T) (string, string) {
+	t.Helper()
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	work := filepath.Join(tmp, "work")
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(work, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run(tmp, "init", "--bare", "-b", "main", origin)
+	run(tmp, "clone", origin, work)
+	run(work, "config", "user.email", "test@test.com")
+	run(work, "config", "user.name", "Test User")
+	run(work, "checkout", "-b", "main")
+	write("README.md", "# Test\n")
+	run(work, "add", ".")
+	run(work, "commit", "-m", "initial")
+	run(work, "push", "origin", "main")
+
+	run(work, "checkout", "-b", "polecat/parent")
+	write("a.txt", "a\n")
+	run(work, "add", ".")
+	run(work, "commit", "-m", "feat: add a")
+
+	run(work, "checkout", "-b", "polecat/child")
+	write("a.txt", "a\nmore\n")
+	write("b.txt", "b\n")
+	run(work, "add", ".")
+	run(work, "commit", "-m", "feat: extend a, add b")
+
+	// Parent lands as a squash
+	run(work, "checkout", "main")
+	run(work, "merge", "--squash", "polecat/parent")
+	run(work, "commit", "-m", "feat: add a")
+	run(work, "push", "origin", "main")
+
+	parentHead, err := git.NewGit(work).Rev("polecat/parent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return work, parentHead
+}
+
+func TestMergeStacked_RebasesPastParentSquash(t *testing.T) {
+	work, parentHead := setupStackRepo(t)
+	cfg := DefaultMergeQueueConfig()
+	cfg.RunTests = false
+	e := &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: t.TempDir()},
+		git:     git.NewGit(work),
+		config:  cfg,
+		workDir: work,
+		output:  &bytes.Buffer{},
+	}
+
+	childBefore, err := e.git.Rev("polecat/child")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := e.mergeStacked(context.Background(), "polecat/child", "main", "", parentHead)
+	if !result.Success {
+		t.Fatalf("expected success, got %q\n%s", result.Error, e.output)
+	}
+
+	data, err := os.ReadFile(filepath.Join(work, "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a\nmore\n" {
+		t.Errorf("a.txt = %q, want child's version", data)
+	}
+	if _, err := os.Stat(filepath.Join(work, "b.txt")); err != nil {
+		t.Errorf("expected b.txt on main: %v", err)
+	}
+
+	remoteHead, err := e.git.Rev("origin/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remoteHead != result.MergeCommit {
+		t.Errorf("origin/main = %s, want merge commit %s", remoteHead, result.MergeCommit)
+	}
+
+	childAfter, err := e.git.Rev("polecat/child")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if childAfter != childBefore {
+		t.Error("polecat branch should not be rewritten")
+	}
+	if exists, _ := e.git.BranchExists(stackBranchPrefix + "polecat/child"); exists {
+		t.Error("scratch stack branch should be deleted")
+	}
+}
@@ -0,0 +1,209 @@
+package refinery
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Test output formats understood by the merge queue.
+const (
+	// TestOutputNone treats the test command as a pass/fail exit code only.
+	TestOutputNone = ""
+
+	// TestOutputGoJSON parses `go test -json` output from stdout.
+	TestOutputGoJSON = "go-json"
+
+	// TestOutputJUnit parses a JUnit XML report file written by the test command.
+	TestOutputJUnit = "junit"
+)
+
+// TestOutcome is the final result of a single test in a structured test run.
+type TestOutcome struct {
+	Package string `json:"package"`
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+}
+
+// Key returns the stable identifier used for flake history ("pkg.TestName").
+func (o TestOutcome) Key() string {
+	if o.Package == "" {
+		return o.Name
+	}
+	return o.Package + "." + o.Name
+}
+
+// TestReport summarizes a structured test run.
+type TestReport struct {
+	// Outcomes holds one entry per test that passed or failed (skips are dropped).
+	Outcomes []TestOutcome
+
+	// BrokenPackages lists packages that failed without any failing test
+	// (build errors, panics in TestMain, timeouts). These can't be rerun
+	// selectively and are never treated as flakes.
+	BrokenPackages []string
+}
+
+// Failed returns the failing tests in the report.
+func (r *TestReport) Failed() []TestOutcome {
+	var failed []TestOutcome
+	for _, o := range r.Outcomes {
+		if !o.Passed {
+			failed = append(failed, o)
+		}
+	}
+	return failed
+}
+
+// goTestEvent is one line of `go test -json` (test2json) output.
+type goTestEvent struct {
+	Action  string `json:"Action"`
+	Package string `json:"Package"`
+	Test    string `json:"Test"`
+}
+
+// ParseGoTestJSON parses `go test -json` output.
+// Non-JSON lines (e.g., build output printed by the go command) are ignored.
+func ParseGoTestJSON(r io.Reader) (*TestReport, error) {
+	results := make(map[string]TestOutcome)
+	var order []string
+	pkgFailed := make(map[string]bool)
+	pkgHasFailedTest := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var ev goTestEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			continue
+		}
+		if ev.Action != "pass" && ev.Action != "fail" {
+			continue
+		}
+
+		if ev.Test == "" {
+			if ev.Action == "fail" {
+				pkgFailed[ev.Package] = true
+			}
+			continue
+		}
+
+		outcome := TestOutcome{Package: ev.Package, Name: ev.Test, Passed: ev.Action == "pass"}
+		key := outcome.Key()
+		if _, seen := results[key]; !seen {
+			order = append(order, key)
+		}
+		results[key] = outcome
+		if !outcome.Passed {
+			pkgHasFailedTest[ev.Package] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading go test output: %w", err)
+	}
+
+	report := &TestReport{}
+	for _, key := range order {
+		report.Outcomes = append(report.Outcomes, results[key])
+	}
+	for pkg := range pkgFailed {
+		if !pkgHasFailedTest[pkg] {
+			report.BrokenPackages = append(report.BrokenPackages, pkg)
+		}
+	}
+	sort.Strings(report.BrokenPackages)
+	return report, nil
+}
+
+// junitTestCase is a <testcase> element in a JUnit XML report.
+type junitTestCase struct {
+	Name      string    `xml:"name,attr"`
+	ClassName string    `xml:"classname,attr"`
+	Failure   *struct{} `xml:"failure"`
+	Error     *struct{} `xml:"error"`
+	Skipped   *struct{} `xml:"skipped"`
+}
+
+// junitTestSuite is a <testsuite> element, possibly nested.
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	TestCases []junitTestCase  `xml:"testcase"`
+	Suites    []junitTestSuite `xml:"testsuite"`
+}
+
+// ParseJUnitXML parses a JUnit XML report with either a <testsuites> or a
+// single <testsuite> root element.
+func ParseJUnitXML(r io.Reader) (*TestReport, error) {
+	var root struct {
+		XMLName   xml.Name
+		Name      string           `xml:"name,attr"`
+		TestCases []junitTestCase  `xml:"testcase"`
+		Suites    []junitTestSuite `xml:"testsuite"`
+	}
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("parsing junit report: %w", err)
+	}
+
+	report := &TestReport{}
+	var walk func(suite junitTestSuite)
+	walk = func(suite junitTestSuite) {
+		for _, tc := range suite.TestCases {
+			if tc.Skipped != nil {
+				continue
+			}
+			pkg := tc.ClassName
+			if pkg == "" {
+				pkg = suite.Name
+			}
+			report.Outcomes = append(report.Outcomes, TestOutcome{
+				Package: pkg,
+				Name:    tc.Name,
+				Passed:  tc.Failure == nil && tc.Error == nil,
+			})
+		}
+		for _, child := range suite.Suites {
+			walk(child)
+		}
+	}
+	walk(junitTestSuite{Name: root.Name, TestCases: root.TestCases, Suites: root.Suites})
+	return report, nil
+}
+
+// goRerunArgs builds `go test` arguments that rerun only the given failures.
+// Subtests are rerun through their top-level test, since -run matches the
+// top-level name first.
+func goRerunArgs(failed []TestOutcome) []string {
+	byPkg := make(map[string]map[string]bool)
+	for _, o := range failed {
+		top := strings.SplitN(o.Name, "/", 2)[0]
+		if byPkg[o.Package] == nil {
+			byPkg[o.Package] = make(map[string]bool)
+		}
+		byPkg[o.Package][top] = true
+	}
+
+	var pkgs, names []string
+	seen := make(map[string]bool)
+	for pkg, tests := range byPkg {
+		pkgs = append(pkgs, pkg)
+		for name := range tests {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(pkgs)
+	sort.Strings(names)
+
+	args := []string{"test", "-json", "-count=1", "-run", "^(" + strings.Join(names, "|") + ")$"}
+	return append(args, pkgs...)
+}
@@ -0,0 +1,107 @@
+package refinery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoTestJSON(t *testing.T) {
+	output := `{"Action":"run","Package":"example.com/a","Test":"TestOK"}
+{"Action":"pass","Package":"example.com/a","Test":"TestOK","Elapsed":0.01}
+{"Action":"run","Package":"example.com/a","Test":"TestFlaky"}
+{"Action":"output","Package":"example.com/a","Test":"TestFlaky","Output":"boom\n"}
+{"Action":"fail","Package":"example.com/a","Test":"TestFlaky/sub","Elapsed":0.01}
+{"Action":"fail","Package":"example.com/a","Test":"TestFlaky","Elapsed":0.01}
+{"Action":"skip","Package":"example.com/a","Test":"TestSkipped"}
+{"Action":"fail","Package":"example.com/a","Elapsed":0.5}
+# example.com/b
+b.go:3:1: syntax error
+{"Action":"fail","Package":"example.com/b","Elapsed":0}
+`
+	report, err := ParseGoTestJSON(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("ParseGoTestJSON: %v", err)
+	}
+
+	if len(report.Outcomes) != 3 {
+		t.Fatalf("expected 3 outcomes (skips dropped), got %d: %+v", len(report.Outcomes), report.Outcomes)
+	}
+
+	var failed []string
+	for _, o := range report.Failed() {
+		failed = append(failed, o.Key())
+	}
+	want := []string{"example.com/a.TestFlaky/sub", "example.com/a.TestFlaky"}
+	if !reflect.DeepEqual(failed, want) {
+		t.Errorf("Failed() = %v, want %v", failed, want)
+	}
+
+	// Package b failed without a failing test: a build failure, not a flake
+	if !reflect.DeepEqual(report.BrokenPackages, []string{"example.com/b"}) {
+		t.Errorf("BrokenPackages = %v, want [example.com/b]", report.BrokenPackages)
+	}
+}
+
+func TestParseJUnitXML(t *testing.T) {
+	xmlReport := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="auth">
+    <testcase classname="auth.LoginTest" name="test_ok"/>
+    <testcase classname="auth.LoginTest" name="test_fail"><failure message="nope"/></testcase>
+    <testcase classname="auth.LoginTest" name="test_error"><error message="crash"/></testcase>
+    <testcase classname="auth.LoginTest" name="test_skip"><skipped/></testcase>
+  </testsuite>
+  <testsuite name="billing">
+    <testcase name="test_invoice"/>
+  </testsuite>
+</testsuites>`
+
+	report, err := ParseJUnitXML(strings.NewReader(xmlReport))
+	if err != nil {
+		t.Fatalf("ParseJUnitXML: %v", err)
+	}
+	if len(report.Outcomes) != 4 {
+		t.Fatalf("expected 4 outcomes, got %d: %+v", len(report.Outcomes), report.Outcomes)
+	}
+
+	var failed []string
+	for _, o := range report.Failed() {
+		failed = append(failed, o.Key())
+	}
+	want := []string{"auth.LoginTest.test_fail", "auth.LoginTest.test_error"}
+	if !reflect.DeepEqual(failed, want) {
+		t.Errorf("Failed() = %v, want %v", failed, want)
+	}
+
+	// Testcases without a classname fall back to the suite name
+	if got := report.Outcomes[3].Key(); got != "billing.test_invoice" {
+		t.Errorf("Outcomes[3].Key() = %q, want %q", got, "billing.test_invoice")
+	}
+}
+
+func TestParseJUnitXML_SingleSuiteRoot(t *testing.T) {
+	xmlReport := `<testsuite name="pkg"><testcase name="TestA"/></testsuite>`
+
+	report, err := ParseJUnitXML(strings.NewReader(xmlReport))
+	if err != nil {
+		t.Fatalf("ParseJUnitXML: %v", err)
+	}
+	if len(report.Outcomes) != 1 || report.Outcomes[0].Key() != "pkg.TestA" {
+		t.Errorf("unexpected outcomes: %+v", report.Outcomes)
+	}
+}
+
+func TestGoRerunArgs(t *testing.T) {
+	failed := []TestOutcome{
+		{Package: "example.com/b", Name: "TestB"},
+		{Package: "example.com/a", Name: "TestA/sub"},
+		{Package: "example.com/a", Name: "TestA"},
+	}
+
+	got := goRerunArgs(failed)
+	want := []string{"test", "-json", "-count=1", "-run", "^(TestA|TestB)$", "example.com/a", "example.com/b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("goRerunArgs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// runStructuredTests runs TestCommand with structured output. Instead of
+// rerunning the whole command on failure, it reruns only the failing tests
+// up to RetryFlakyTests times and records which ones flaked in the rig's
+// flake history. Failures of quarantined (known flaky) tests are waived
+// and recorded so they don't bounce the MR.
+func (e *Engineer) runStructuredTests(ctx context.Context) ProcessResult {
+	report, runErr, parseErr := e.runTestReport(ctx, shellCommand(e.config.TestCommand))
+	if ctx.Err() != nil {
+		return ProcessResult{Success: false, Error: "test run canceled"}
+	}
+
+	history, err := LoadFlakeHistory(e.rig.Path)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to load flake history: %v\n", err)
+		history = &FlakeHistory{Tests: make(map[string]*FlakeRecord), path: FlakeHistoryPath(e.rig.Path)}
+	}
+
+	if runErr == nil {
+		if report != nil {
+			history.RecordRun(report)
+			e.saveFlakeHistory(history)
+		}
+		return ProcessResult{Success: true}
+	}
+
+	if parseErr != nil {
+		return ProcessResult{
+			Success:     false,
+			TestsFailed: true,
+			Error:       fmt.Sprintf("tests failed: %v (test output unparseable: %v)", runErr, parseErr),
+		}
+	}
+	failed := report.Failed()
+	if len(report.BrokenPackages) > 0 || len(failed) == 0 {
+		// Build failures and crashes aren't attributable to a test: never flaky.
+		return ProcessResult{
+			Success:     false,
+			TestsFailed: true,
+			Error:       fmt.Sprintf("tests failed: %v (broken packages: %s)", runErr, strings.Join(report.BrokenPackages, ", ")),
+		}
+	}
+
+	history.RecordRun(report)
+	commit, _ := e.git.Rev("HEAD")
+
+	reruns := e.config.RetryFlakyTests
+	for attempt := 1; attempt <= reruns && len(failed) > 0; attempt++ {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Rerunning %d failing test(s) (attempt %d/%d)...\n", len(failed), attempt, reruns)
+		rerun, err := e.rerunFailed(ctx, failed)
+		if ctx.Err() != nil {
+			return ProcessResult{Success: false, Error: "test run canceled"}
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: rerun output unparseable: %v\n", err)
+			break
+		}
+		history.RecordRun(rerun)
+
+		passed := make(map[string]bool)
+		for _, o := range rerun.Outcomes {
+			if o.Passed {
+				passed[o.Key()] = true
+			}
+		}
+		var still []TestOutcome
+		for _, o := range failed {
+			if passed[o.Key()] {
+				history.RecordFlake(o, commit)
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Flaky: %s failed then passed on rerun\n", o.Key())
+				continue
+			}
+			still = append(still, o)
+		}
+		failed = still
+	}
+
+	var hard []string
+	for _, o := range failed {
+		if history.IsQuarantined(o, e.config.FlakyQuarantineThreshold) {
+			history.RecordWaived(o)
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Ignoring quarantined flaky test: %s\n", o.Key())
+			continue
+		}
+		hard = append(hard, o.Key())
+	}
+
+	e.fileFlakyTestBeads(history)
+	e.saveFlakeHistory(history)
+
+	if len(hard) > 0 {
+		return ProcessResult{
+			Success:     false,
+			TestsFailed: true,
+			Error:       fmt.Sprintf("tests failed: %s", strings.Join(hard, ", ")),
+		}
+	}
+	return ProcessResult{Success: true}
+}
+
+// shellCommand returns the argv for running a configured command through sh.
+// Test commands come from rig's config.json (trusted infrastructure config).
+func shellCommand(command string) []string {
+	return []string{"sh", "-c", command}
+}
+
+// rerunFailed reruns only the given failing tests and parses the result.
+func (e *Engineer) rerunFailed(ctx context.Context, failed []TestOutcome) (*TestReport, error) {
+	var argv []string
+	switch {
+	case e.config.RerunCommand != "":
+		names := make([]string, 0, len(failed))
+		for _, o := range failed {
+			names = append(names, o.Name)
+		}
+		command := strings.ReplaceAll(e.config.RerunCommand, "{tests}", strings.Join(names, " "))
+		argv = shellCommand(command)
+	case e.config.TestOutputFormat == TestOutputGoJSON:
+		argv = append([]string{"go"}, goRerunArgs(failed)...)
+	default:
+		argv = shellCommand(e.config.TestCommand)
+	}
+
+	report, _, parseErr := e.runTestReport(ctx, argv)
+	return report, parseErr
+}
+
+// runTestReport runs a test command and parses its structured output.
+// Returns the parsed report, the command's exit error (nil on success),
+// and any error parsing the output.
+func (e *Engineer) runTestReport(ctx context.Context, argv []string) (report *TestReport, runErr, parseErr error) {
+	reportPath := ""
+	if e.config.TestOutputFormat == TestOutputJUnit {
+		if e.config.JUnitReport == "" {
+			err := fmt.Errorf("junit_report not configured")
+			return nil, err, err
+		}
+		reportPath = e.config.JUnitReport
+		if !filepath.IsAbs(reportPath) {
+			reportPath = filepath.Join(e.workDir, reportPath)
+		}
+		// Never parse a stale report from a previous run
+		_ = os.Remove(reportPath)
+	}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...) //nolint:gosec // G204: trusted rig config or our own test names
+	cmd.Dir = e.workDir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr = cmd.Run()
+
+	switch e.config.TestOutputFormat {
+	case TestOutputGoJSON:
+		report, parseErr = ParseGoTestJSON(&stdout)
+		return report, runErr, parseErr
+	case TestOutputJUnit:
+		f, err := os.Open(reportPath) //nolint:gosec // G304: path is from trusted rig config
+		if err != nil {
+			return nil, runErr, fmt.Errorf("opening junit report: %w", err)
+		}
+		defer f.Close()
+		report, parseErr = ParseJUnitXML(f)
+		return report, runErr, parseErr
+	default:
+		return nil, runErr, fmt.Errorf("unknown test_output_format %q", e.config.TestOutputFormat)
+	}
+}
+
+// fileFlakyTestBeads files a bug bead for every chronically flaky test that
+// doesn't have one yet, so the team can fix it instead of living with reruns.
+func (e *Engineer) fileFlakyTestBeads(history *FlakeHistory) {
+	for _, rec := range history.NeedsBead(e.config.FlakyBeadThreshold) {
+		description := fmt.Sprintf(`Test %s is chronically flaky in the %s merge queue.
+
+## Metadata
+- Package: %s
+- Test: %s
+- Flakes: %d
+- Passes: %d
+- Failures: %d
+- First seen commit: %s
+
+The Refinery reruns failing tests and records a flake whenever a test fails and
+then passes against the same commit. See 'gt mq flakes %s' for history.`,
+			rec.Key(), e.rig.Name,
+			rec.Package, rec.Name,
+			rec.Flakes, rec.Passes, rec.Failures,
+			rec.FirstSeenCommit,
+			e.rig.Name,
+		)
+
+		issue, err := e.beads.Create(beads.CreateOptions{
+			Title:       fmt.Sprintf("Flaky test: %s", rec.Name),
+			Type:        "bug",
+			Priority:    2,
+			Description: description,
+			Actor:       e.rig.Name + "/refinery",
+		})
+		if err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to file flaky test bead for %s: %v\n", rec.Key(), err)
+			continue
+		}
+		rec.BeadID = issue.ID
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Filed flaky test bead %s for %s\n", issue.ID, rec.Key())
+	}
+}
+
+// saveFlakeHistory persists the flake history, warning on failure.
+func (e *Engineer) saveFlakeHistory(history *FlakeHistory) {
+	if err := history.Save(); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save flake history: %v\n", err)
+	}
+}
@@ -41,6 +41,12 @@ type MergeRequest struct {
 
 	// Error contains error details if the MR failed.
 	Error string `json:"error,omitempty"`
+
+	// ParentMR is the MR this one is stacked on (if any).
+	ParentMR string `json:"parent_mr,omitempty"`
+
+	// InvalidatedChildren lists stacked MRs closed because this one was rejected.
+	InvalidatedChildren []string `json:"invalidated_children,omitempty"`
 }
 
 // MRStatus represents the status of a merge request.
@@ -0,0 +1,181 @@
+package refinery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/protocol"
+)
+
+// verifyCommand returns the command used for post-merge verification.
+// Falls back to TestCommand when no dedicated VerifyCommand is configured.
+func (e *Engineer) verifyCommand() string {
+	if e.config.VerifyCommand != "" {
+		return e.config.VerifyCommand
+	}
+	return e.config.TestCommand
+}
+
+// verifyPostMerge runs the verify command on the freshly pushed target HEAD.
+// A squash merge that passed in isolation can still break the target through
+// an interaction with another MR. On failure the merge commit is reverted and
+// pushed, and the queue is paused until the target is green again.
+func (e *Engineer) verifyPostMerge(ctx context.Context, branch, target, mergeCommit string) ProcessResult {
+	command := e.verifyCommand()
+	if command == "" {
+		return ProcessResult{Success: true, MergeCommit: mergeCommit}
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Verifying %s at %s: %s\n", target, mergeCommit[:8], command)
+	verify := e.runCommand(ctx, command)
+	if verify.Success {
+		_, _ = fmt.Fprintln(e.output, "[Engineer] Post-merge verification passed")
+		return ProcessResult{Success: true, MergeCommit: mergeCommit}
+	}
+	if ctx.Err() != nil {
+		// The merge landed; with verification interrupted we can't justify a revert.
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: post-merge verification canceled, %s not verified\n", target)
+		return ProcessResult{Success: true, MergeCommit: mergeCommit}
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ Post-merge verification failed: %s\n", verify.Error)
+	result := ProcessResult{
+		Success:         false,
+		PostMergeFailed: true,
+		MergeCommit:     mergeCommit,
+		Error:           fmt.Sprintf("post-merge verification failed on %s: %s", target, verify.Error),
+	}
+
+	revertCommit, err := e.revertMerge(target, mergeCommit)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to revert %s: %v\n", mergeCommit[:8], err)
+		result.Error = fmt.Sprintf("%s (revert failed: %v)", result.Error, err)
+	} else {
+		result.RevertCommit = revertCommit
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Reverted %s in %s\n", mergeCommit[:8], revertCommit[:8])
+	}
+
+	// Pause even if the revert failed: the target may still be broken.
+	state := QueuePauseState{
+		Reason:       result.Error,
+		Target:       target,
+		Branch:       branch,
+		MergeCommit:  mergeCommit,
+		RevertCommit: result.RevertCommit,
+	}
+	if err := PauseQueue(e.rig.Path, state); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to pause merge queue: %v\n", err)
+	} else {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Merge queue paused until %s is green\n", target)
+	}
+
+	return result
+}
+
+// revertMerge reverts mergeCommit on the checked-out target and pushes it.
+// A revert that conflicts is aborted. Returns the SHA of the revert commit.
+func (e *Engineer) revertMerge(target, mergeCommit string) (string, error) {
+	if err := e.git.Revert(mergeCommit); err != nil {
+		_ = e.git.AbortRevert()
+		return "", fmt.Errorf("reverting %s: %w", mergeCommit, err)
+	}
+	revertCommit, err := e.git.Rev("HEAD")
+	if err != nil {
+		return "", fmt.Errorf("getting revert commit SHA: %w", err)
+	}
+	if err := e.git.Push("origin", target, false); err != nil {
+		return "", fmt.Errorf("pushing revert to origin/%s: %w", target, err)
+	}
+	return revertCommit, nil
+}
+
+// PauseState returns the merge queue pause state, or nil if the queue is running.
+func (e *Engineer) PauseState() (*QueuePauseState, error) {
+	paused, state, err := IsQueuePaused(e.rig.Path)
+	if err != nil || !paused {
+		return nil, err
+	}
+	return state, nil
+}
+
+// VerifyTarget re-runs the verify command against the latest origin target
+// and resumes the merge queue if it passes.
+func (e *Engineer) VerifyTarget(ctx context.Context) ProcessResult {
+	target := e.config.TargetBranch
+	if state, err := e.PauseState(); err == nil && state != nil && state.Target != "" {
+		target = state.Target
+	}
+
+	command := e.verifyCommand()
+	if command == "" {
+		return ProcessResult{
+			Success: false,
+			Error:   "no verify_command or test_command configured",
+		}
+	}
+
+	if err := e.git.Checkout(target); err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to checkout target %s: %v", target, err),
+		}
+	}
+	if err := e.git.Pull("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s: %v (continuing)\n", target, err)
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Verifying %s: %s\n", target, command)
+	result := e.runCommand(ctx, command)
+	if !result.Success {
+		return result
+	}
+
+	if err := ResumeQueue(e.rig.Path); err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("verification passed but failed to resume queue: %v", err),
+		}
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] %s is green, merge queue resumed\n", target)
+	return ProcessResult{Success: true}
+}
+
+// sendPostMergeRework asks the Witness to have the polecat rework a merge
+// that was reverted after landing.
+func (e *Engineer) sendPostMergeRework(mr *beads.MRFields, result ProcessResult) {
+	if mr == nil {
+		mr = &beads.MRFields{}
+	}
+	msg := protocol.NewPostMergeReworkMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, result.RevertCommit, result.Error)
+	if err := e.router.Send(msg); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to send REWORK_REQUEST to witness: %v\n", err)
+		return
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Sent REWORK_REQUEST for %s to witness\n", mr.Worker)
+}
+
+// recordPostMergeFailure reopens an MR whose merge was reverted and records
+// the failure type and revert commit on the MR bead for traceability.
+func (e *Engineer) recordPostMergeFailure(mrID string, result ProcessResult) {
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to fetch MR bead %s: %v\n", mrID, err)
+		return
+	}
+
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+	mrFields.FailureType = protocol.FailureTypePostMerge
+	mrFields.RevertCommit = result.RevertCommit
+	mrFields.MergeCommit = ""
+	mrFields.CloseReason = ""
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+
+	open := "open"
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Status: &open, Description: &newDesc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to reopen MR %s: %v\n", mrID, err)
+	}
+}
@@ -0,0 +1,202 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// setupVerifyRepo creates a bare origin and a clone with one landed commit.
+// Returns the clone directory and the SHA of the landed commit.
+func setupVerifyRepo(t *testing.T) (string, string) {
+	t.Helper()
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	work := filepath.Join(tmp, "work")
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	run(tmp, "init", "--bare", "-b", "main", origin)
+	run(tmp, "clone", origin, work)
+	run(work, "config", "user.email", "test@test.com")
+	run(work, "config", "user.name", "Test User")
+	run(work, "checkout", "-b", "main")
+
+	if err := os.WriteFile(filepath.Join(work, "README.md"), []byte("# Test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(work, "add", ".")
+	run(work, "commit", "-m", "initial")
+	run(work, "push", "origin", "main")
+
+	if err := os.WriteFile(filepath.Join(work, "broken"), []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(work, "add", ".")
+	run(work, "commit", "-m", "feat: breaks main")
+	run(work, "push", "origin", "main")
+
+	sha, err := git.NewGit(work).Rev("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return work, sha
+}
+
+func newVerifyEngineer(t *testing.T, workDir, verifyCmd string) *Engineer {
+	t.Helper()
+	cfg := DefaultMergeQueueConfig()
+	cfg.PostMergeVerify = true
+	cfg.VerifyCommand = verifyCmd
+	return &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: t.TempDir()},
+		git:     git.NewGit(workDir),
+		config:  cfg,
+		workDir: workDir,
+		output:  &bytes.Buffer{},
+	}
+}
+
+func TestVerifyPostMerge_Passes(t *testing.T) {
+	work, sha := setupVerifyRepo(t)
+	e := newVerifyEngineer(t, work, "true")
+
+	result := e.verifyPostMerge(context.Background(), "polecat/nux", "main", sha)
+	if !result.Success {
+		t.Fatalf("expected success, got error %q", result.Error)
+	}
+	if paused, _, _ := IsQueuePaused(e.rig.Path); paused {
+		t.Error("queue should not be paused after passing verification")
+	}
+}
+
+func TestVerifyPostMerge_FailureRevertsAndPauses(t *testing.T) {
+	work, sha := setupVerifyRepo(t)
+	// Verification fails while the file added by the merge exists
+	e := newVerifyEngineer(t, work, "test ! -f broken")
+
+	result := e.verifyPostMerge(context.Background(), "polecat/nux", "main", sha)
+	if result.Success {
+		t.Fatal("expected verification failure")
+	}
+	if !result.PostMergeFailed {
+		t.Error("expected PostMergeFailed to be set")
+	}
+	if result.RevertCommit == "" {
+		t.Fatalf("expected a revert commit, error: %s", result.Error)
+	}
+
+	if _, err := os.Stat(filepath.Join(work, "broken")); !os.IsNotExist(err) {
+		t.Error("expected merge to be reverted in the worktree")
+	}
+	remoteHead, err := e.git.Rev("origin/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remoteHead != result.RevertCommit {
+		t.Errorf("origin/main = %s, want revert commit %s", remoteHead, result.RevertCommit)
+	}
+
+	paused, state, err := IsQueuePaused(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !paused {
+		t.Fatal("expected queue to be paused")
+	}
+	if state.MergeCommit != sha || state.RevertCommit != result.RevertCommit || state.Target != "main" {
+		t.Errorf("unexpected pause state: %+v", state)
+	}
+
+	// Main is green again after the revert, so VerifyTarget resumes the queue
+	if res := e.VerifyTarget(context.Background()); !res.Success {
+		t.Fatalf("VerifyTarget: %s", res.Error)
+	}
+	if paused, _, _ := IsQueuePaused(e.rig.Path); paused {
+		t.Error("expected queue to be resumed")
+	}
+}
+
+func TestVerifyCommandFallsBackToTestCommand(t *testing.T) {
+	e := &Engineer{config: DefaultMergeQueueConfig()}
+	e.config.TestCommand = "make test"
+	if got := e.verifyCommand(); got != "make test" {
+		t.Errorf("verifyCommand() = %q, want %q", got, "make test")
+	}
+	e.config.VerifyCommand = "make verify"
+	if got := e.verifyCommand(); got != "make verify" {
+		t.Errorf("verifyCommand() = %q, want %q", got, "make verify")
+	}
+}
+
+func TestQueuePauseRoundTrip(t *testing.T) {
+	rigPath := t.TempDir()
+
+	if paused, state, err := IsQueuePaused(rigPath); err != nil || paused || state != nil {
+		t.Fatalf("IsQueuePaused on fresh rig = (%v, %v, %v)", paused, state, err)
+	}
+
+	if err := PauseQueue(rigPath, QueuePauseState{Reason: "main broken", Target: "main"}); err != nil {
+		t.Fatalf("PauseQueue: %v", err)
+	}
+	paused, state, err := IsQueuePaused(rigPath)
+	if err != nil || !paused {
+		t.Fatalf("expected paused, got (%v, %v)", paused, err)
+	}
+	if state.Reason != "main broken" || state.PausedAt.IsZero() {
+		t.Errorf("unexpected state: %+v", state)
+	}
+
+	if err := ResumeQueue(rigPath); err != nil {
+		t.Fatalf("ResumeQueue: %v", err)
+	}
+	if paused, _, _ := IsQueuePaused(rigPath); paused {
+		t.Error("expected queue to be resumed")
+	}
+	// Resuming twice is a no-op
+	if err := ResumeQueue(rigPath); err != nil {
+		t.Errorf("second ResumeQueue: %v", err)
+	}
+}
+
+func TestRevertMerge_ConflictAborts(t *testing.T) {
+	work, sha := setupVerifyRepo(t)
+	e := newVerifyEngineer(t, work, "true")
+
+	// A later commit edits the file the merge added, so reverting conflicts
+	if err := os.WriteFile(filepath.Join(work, "broken"), []byte("y\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "commit", "-am", "fix: edit broken")
+	cmd.Dir = work
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+
+	if _, err := e.revertMerge("main", sha); err == nil {
+		t.Fatal("expected the revert to fail")
+	}
+	if _, err := os.Stat(filepath.Join(work, ".git", "REVERT_HEAD")); !os.IsNotExist(err) {
+		t.Error("revert still in progress after failure")
+	}
+	status, err := e.git.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Errorf("worktree not clean after aborted revert: %+v", status)
+	}
+}
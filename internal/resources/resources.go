@@ -0,0 +1,139 @@
+// Package resources samples what each agent costs the machine: CPU, memory,
+// open files and child processes of its tmux process tree, and disk usage of
+// its worktree and the rig's shared repo.
+//
+// Samples are appended to <town>/.resources.jsonl, which KRC prunes like the
+// events and feed files, so history is short-lived operational data.
+// Process metrics are read from /proc and are only available on Linux.
+package resources
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// File is the name of the sample history file in the town root.
+const File = ".resources.jsonl"
+
+// Sample types, used as KRC event types for TTLs.
+const (
+	TypeProcess = "resource_sample" // An agent's process tree
+	TypeDisk    = "disk_sample"     // A worktree's or repo's disk usage
+)
+
+// HotCPUPercent is the CPU use at which an agent is considered spinning.
+const HotCPUPercent = 90.0
+
+// Sample is one measurement of an agent's process tree or of a directory's
+// disk usage.
+type Sample struct {
+	Timestamp string `json:"ts"` // RFC3339, as KRC expects
+	Type      string `json:"type"`
+	Agent     string `json:"agent"`             // e.g. "gastown/polecats/Toast", or "gastown/.repo.git" for disk
+	Session   string `json:"session,omitempty"` // TypeProcess only
+
+	// TypeProcess
+	CPUPercent float64 `json:"cpu_percent,omitempty"` // 100 = one full core
+	RSS        int64   `json:"rss_bytes,omitempty"`
+	OpenFDs    int     `json:"open_fds,omitempty"`
+	Children   int     `json:"children,omitempty"` // Descendants of the pane process
+
+	// TypeDisk
+	Path      string `json:"path,omitempty"`
+	DiskBytes int64  `json:"disk_bytes,omitempty"`
+}
+
+// Time returns the sample's timestamp.
+func (s Sample) Time() time.Time {
+	t, _ := time.Parse(time.RFC3339, s.Timestamp)
+	return t
+}
+
+// Hot reports whether the sample shows the agent spinning a full core.
+func (s Sample) Hot() bool {
+	return s.Type == TypeProcess && s.CPUPercent >= HotCPUPercent
+}
+
+// Path returns the sample history file for a town.
+func Path(townRoot string) string {
+	return filepath.Join(townRoot, File)
+}
+
+// Record appends samples to the town's history.
+func Record(townRoot string, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(Path(townRoot), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: samples are non-sensitive operational data
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, s := range samples {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		_, _ = w.Write(append(data, '\n'))
+	}
+	return w.Flush()
+}
+
+// Load returns samples recorded at or after since, oldest first. A missing
+// history yields none. Unparseable lines are skipped.
+func Load(townRoot string, since time.Time) ([]Sample, error) {
+	f, err := os.Open(Path(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var samples []Sample
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s Sample
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			continue
+		}
+		if !since.IsZero() && s.Time().Before(since) {
+			continue
+		}
+		samples = append(samples, s)
+	}
+	return samples, scanner.Err()
+}
+
+// Latest returns the most recent sample of typ for each agent.
+func Latest(samples []Sample, typ string) map[string]Sample {
+	latest := make(map[string]Sample)
+	for _, s := range samples {
+		if s.Type != typ {
+			continue
+		}
+		if prev, ok := latest[s.Agent]; !ok || !s.Time().Before(prev.Time()) {
+			latest[s.Agent] = s
+		}
+	}
+	return latest
+}
+
+// History returns an agent's samples of typ, oldest first.
+func History(samples []Sample, agent, typ string) []Sample {
+	var out []Sample
+	for _, s := range samples {
+		if s.Agent == agent && s.Type == typ {
+			out = append(out, s)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time().Before(out[j].Time()) })
+	return out
+}
@@ -0,0 +1,109 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordLoadLatest(t *testing.T) {
+	town := t.TempDir()
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	err := Record(town, []Sample{
+		{Timestamp: old, Type: TypeProcess, Agent: "gastown/polecats/Toast", CPUPercent: 5},
+		{Timestamp: now, Type: TypeProcess, Agent: "gastown/polecats/Toast", CPUPercent: 99.5},
+		{Timestamp: now, Type: TypeDisk, Agent: "gastown/polecats/Toast", DiskBytes: 4096},
+	})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	all, err := Load(town, time.Time{})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("Load = %d samples, %v; want 3", len(all), err)
+	}
+	recent, _ := Load(town, time.Now().Add(-time.Hour))
+	if len(recent) != 2 {
+		t.Errorf("Load since 1h ago = %d samples, want 2", len(recent))
+	}
+
+	latest := Latest(all, TypeProcess)["gastown/polecats/Toast"]
+	if latest.CPUPercent != 99.5 || !latest.Hot() {
+		t.Errorf("Latest = %+v, want the 99.5%% sample, hot", latest)
+	}
+	if disk := Latest(all, TypeDisk)["gastown/polecats/Toast"]; disk.DiskBytes != 4096 || disk.Hot() {
+		t.Errorf("Latest disk = %+v, want 4096 bytes, not hot", disk)
+	}
+	if h := History(all, "gastown/polecats/Toast", TypeProcess); len(h) != 2 || h[0].CPUPercent != 5 {
+		t.Errorf("History = %+v, want 2 samples oldest first", h)
+	}
+}
+
+func TestProcReaders(t *testing.T) {
+	root := t.TempDir()
+	oldRoot := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = oldRoot })
+
+	write := func(pid, file, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(root, pid, "fd"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, pid, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// utime=150 stime=50; the command name contains a space and a paren
+	write("100", "stat", "100 (node (v2) x) S 1 100 100 0 -1 4194304 0 0 0 0 150 50 0 0 20 0 1 0 0 0 0\n")
+	write("101", "stat", "101 (sh) S 100 100 100 0 -1 4194304 0 0 0 0 10 0 0 0 20 0 1 0 0 0 0\n")
+	write("100", "statm", "5000 256 100 1 0 300 0\n")
+	_ = os.WriteFile(filepath.Join(root, "100", "fd", "0"), nil, 0644)
+	_ = os.WriteFile(filepath.Join(root, "100", "fd", "1"), nil, 0644)
+
+	if got := cpuTicks([]string{"100", "101", "999"}); got != 210 {
+		t.Errorf("cpuTicks = %d, want 210", got)
+	}
+	if got, want := rss("100"), int64(256*os.Getpagesize()); got != want {
+		t.Errorf("rss = %d, want %d", got, want)
+	}
+	if got := openFDs("100"); got != 2 {
+		t.Errorf("openFDs = %d, want 2", got)
+	}
+}
+
+func TestTargets(t *testing.T) {
+	town := t.TempDir()
+	for _, dir := range []string{
+		"gastown/polecats/Toast",
+		"gastown/polecats/.warm/Nux",
+		"gastown/crew/max",
+		"gastown/.repo.git",
+	} {
+		if err := os.MkdirAll(filepath.Join(town, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := make(map[string]Target)
+	for _, target := range Targets(town, []string{"gastown"}) {
+		got[target.Agent] = target
+	}
+	for _, agent := range []string{"mayor", "deacon", "gastown/witness", "gastown/refinery", "gastown/polecats/Toast", "gastown/crew/max", "gastown/.repo.git"} {
+		if _, ok := got[agent]; !ok {
+			t.Errorf("Targets missing %s", agent)
+		}
+	}
+	if _, ok := got["gastown/polecats/.warm"]; ok {
+		t.Error("Targets included the warm pool directory")
+	}
+	if toast := got["gastown/polecats/Toast"]; toast.Session != "gt-gastown-Toast" || toast.Path == "" {
+		t.Errorf("polecat target = %+v, want session and worktree path", toast)
+	}
+	if repo := got["gastown/.repo.git"]; repo.Session != "" {
+		t.Errorf("repo target has a session: %+v", repo)
+	}
+}
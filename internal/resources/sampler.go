@@ -0,0 +1,262 @@
+package resources
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// procRoot is where procfs is mounted. Tests override it.
+var procRoot = "/proc"
+
+// clockTicks is USER_HZ, the unit of utime/stime in /proc/<pid>/stat. It is
+// 100 on every mainstream Linux architecture.
+const clockTicks = 100
+
+// duTimeout bounds a single directory's disk usage scan.
+const duTimeout = 2 * time.Minute
+
+// Target is an agent to sample.
+type Target struct {
+	Agent   string // Agent address, e.g. "gastown/polecats/Toast"
+	Session string // tmux session; "" for disk-only targets
+	Path    string // Directory to measure disk usage of; "" to skip
+}
+
+// Targets lists the agents of a town and the given rigs: the mayor and
+// deacon, each rig's witness, refinery, polecats and crew, and each rig's
+// shared .repo.git.
+func Targets(townRoot string, rigs []string) []Target {
+	targets := []Target{
+		{Agent: "mayor", Session: session.MayorSessionName()},
+		{Agent: "deacon", Session: session.DeaconSessionName()},
+	}
+	for _, rig := range rigs {
+		rigPath := filepath.Join(townRoot, rig)
+		targets = append(targets,
+			Target{Agent: rig + "/witness", Session: session.WitnessSessionName(rig)},
+			Target{Agent: rig + "/refinery", Session: session.RefinerySessionName(rig)},
+		)
+		for _, name := range listDirs(filepath.Join(rigPath, "polecats")) {
+			targets = append(targets, Target{
+				Agent:   rig + "/polecats/" + name,
+				Session: session.PolecatSessionName(rig, name),
+				Path:    filepath.Join(rigPath, "polecats", name),
+			})
+		}
+		for _, name := range listDirs(filepath.Join(rigPath, "crew")) {
+			targets = append(targets, Target{
+				Agent:   rig + "/crew/" + name,
+				Session: session.CrewSessionName(rig, name),
+				Path:    filepath.Join(rigPath, "crew", name),
+			})
+		}
+		if repo := filepath.Join(rigPath, ".repo.git"); isDir(repo) {
+			targets = append(targets, Target{Agent: rig + "/.repo.git", Path: repo})
+		}
+	}
+	return targets
+}
+
+// listDirs returns the non-hidden subdirectories of dir.
+func listDirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// Sampler measures targets.
+type Sampler struct {
+	tmux *tmux.Tmux
+
+	// Window is how long CPU time is measured over.
+	Window time.Duration
+}
+
+// NewSampler creates a sampler that finds agent processes through t.
+func NewSampler(t *tmux.Tmux) *Sampler {
+	return &Sampler{tmux: t, Window: time.Second}
+}
+
+// Sample measures the process tree of every target with a running session
+// and, if withDisk, the disk usage of every target with a path. Agents whose
+// session isn't running are skipped.
+func (s *Sampler) Sample(targets []Target, withDisk bool) []Sample {
+	ts := time.Now().UTC().Format(time.RFC3339)
+
+	var (
+		mu      sync.Mutex
+		samples []Sample
+		wg      sync.WaitGroup
+	)
+	add := func(sample Sample) {
+		mu.Lock()
+		samples = append(samples, sample)
+		mu.Unlock()
+	}
+
+	if withDisk {
+		for _, t := range targets {
+			if t.Path == "" {
+				continue
+			}
+			wg.Add(1)
+			go func(t Target) {
+				defer wg.Done()
+				if bytes, err := DiskUsage(t.Path); err == nil {
+					add(Sample{Timestamp: ts, Type: TypeDisk, Agent: t.Agent, Path: t.Path, DiskBytes: bytes})
+				}
+			}(t)
+		}
+	}
+
+	for _, sample := range s.sampleProcesses(targets, ts) {
+		add(sample)
+	}
+	wg.Wait()
+	return samples
+}
+
+// tree is one agent's process tree.
+type tree struct {
+	target Target
+	pids   []string
+	ticks  uint64 // CPU ticks at the start of the window
+}
+
+// sampleProcesses measures every running target's process tree over one
+// shared window.
+func (s *Sampler) sampleProcesses(targets []Target, ts string) []Sample {
+	if _, err := os.Stat(filepath.Join(procRoot, "self", "stat")); err != nil {
+		return nil // No procfs (not Linux)
+	}
+
+	var trees []*tree
+	for _, t := range targets {
+		if t.Session == "" {
+			continue
+		}
+		pid, err := s.tmux.GetPanePID(t.Session)
+		if err != nil || pid == "" {
+			continue // Session not running
+		}
+		pids := append([]string{pid}, tmux.GetDescendants(pid)...)
+		trees = append(trees, &tree{target: t, pids: pids, ticks: cpuTicks(pids)})
+	}
+	if len(trees) == 0 {
+		return nil
+	}
+
+	time.Sleep(s.Window)
+
+	samples := make([]Sample, 0, len(trees))
+	for _, tr := range trees {
+		sample := Sample{
+			Timestamp: ts,
+			Type:      TypeProcess,
+			Agent:     tr.target.Agent,
+			Session:   tr.target.Session,
+			Children:  len(tr.pids) - 1,
+		}
+		if end := cpuTicks(tr.pids); end > tr.ticks {
+			cpu := float64(end-tr.ticks) / clockTicks / s.Window.Seconds() * 100
+			sample.CPUPercent = float64(int(cpu*10)) / 10
+		}
+		for _, pid := range tr.pids {
+			sample.RSS += rss(pid)
+			sample.OpenFDs += openFDs(pid)
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
+// cpuTicks returns the total user+system CPU ticks of pids. Processes that
+// have exited contribute nothing.
+func cpuTicks(pids []string) uint64 {
+	var total uint64
+	for _, pid := range pids {
+		data, err := os.ReadFile(filepath.Join(procRoot, pid, "stat")) //nolint:gosec // G304: procfs path
+		if err != nil {
+			continue
+		}
+		// The command name is parenthesized and may contain spaces; fields
+		// are counted from after its closing paren.
+		stat := string(data)
+		i := strings.LastIndexByte(stat, ')')
+		if i < 0 {
+			continue
+		}
+		fields := strings.Fields(stat[i+1:])
+		if len(fields) < 13 {
+			continue
+		}
+		utime, _ := strconv.ParseUint(fields[11], 10, 64)
+		stime, _ := strconv.ParseUint(fields[12], 10, 64)
+		total += utime + stime
+	}
+	return total
+}
+
+// rss returns a process's resident set size in bytes.
+func rss(pid string) int64 {
+	data, err := os.ReadFile(filepath.Join(procRoot, pid, "statm")) //nolint:gosec // G304: procfs path
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, _ := strconv.ParseInt(fields[1], 10, 64)
+	return pages * int64(os.Getpagesize())
+}
+
+// openFDs returns the number of open file descriptors of a process.
+func openFDs(pid string) int {
+	entries, err := os.ReadDir(filepath.Join(procRoot, pid, "fd"))
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
+
+// DiskUsage returns the disk space used by a directory tree, in bytes.
+func DiskUsage(path string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), duTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "du", "-sk", path).Output() //nolint:gosec // G204: path is a managed worktree
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	kb, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return kb * 1024, nil
+}
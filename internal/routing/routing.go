@@ -0,0 +1,366 @@
+// Package routing ranks who should take a bead slung to a rig: which
+// polecat identity (a name whose track record lives on in the rig's beads)
+// and which runtime to start it with.
+//
+// Each candidate gets four scores between 0 and 1, combined with weights
+// from town settings:
+//
+//   - skill: work done on beads sharing the bead's tags
+//   - reliability: few failures on those beads
+//   - load: little work still hooked to the identity
+//   - cost: a cheap runtime
+//
+// A bead's tags are its labels ("area:auth" counts as "auth"), its issue
+// type, and the capabilities of the formula it is slung with.
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/polecat"
+)
+
+// DefaultWeights are used for weights town settings leave at zero.
+var DefaultWeights = config.RoutingWeights{Skill: 0.4, Reliability: 0.3, Load: 0.2, Cost: 0.1}
+
+// skillHalf is the amount of similar work done at which skill reaches 0.5.
+const skillHalf = 2.0
+
+// Cost tiers.
+const (
+	TierCheap     = 1
+	TierStandard  = 2
+	TierExpensive = 3
+)
+
+// Task is a bead to route.
+type Task struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+}
+
+// NewTask returns the task for a bead slung with a formula's capabilities.
+// issue may be nil when a formula is slung on its own.
+func NewTask(issue *beads.Issue, capabilities []string) *Task {
+	t := &Task{}
+	if issue != nil {
+		t.ID, t.Title = issue.ID, issue.Title
+		t.Tags = issueTags(issue)
+	}
+	for _, c := range capabilities {
+		if c = strings.ToLower(strings.TrimSpace(c)); c != "" {
+			t.Tags = append(t.Tags, c)
+		}
+	}
+	t.Tags = dedupe(t.Tags)
+	return t
+}
+
+// tagPrefixes are label namespaces whose value is a tag.
+var tagPrefixes = []string{"area:", "skill:", "cap:"}
+
+// issueTags returns a bead's labels and issue type as tags. Other
+// namespaced labels (gt:agent, from:, thread:) are bookkeeping and skipped.
+func issueTags(issue *beads.Issue) []string {
+	var tags []string
+	for _, label := range issue.Labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		tag := label
+		for _, p := range tagPrefixes {
+			tag = strings.TrimPrefix(tag, p)
+		}
+		if tag == label && strings.Contains(label, ":") {
+			continue
+		}
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if issue.Type != "" {
+		tags = append(tags, "type:"+issue.Type)
+	}
+	return dedupe(tags)
+}
+
+// nonWorkTypes are bead types that are not work a polecat does.
+var nonWorkTypes = map[string]bool{
+	"agent":         true,
+	"message":       true,
+	"merge-request": true,
+	"molecule":      true,
+	"convoy":        true,
+	"gate":          true,
+	"event":         true,
+}
+
+// pastWork is one bead in an identity's record.
+type pastWork struct {
+	tags   []string
+	failed bool
+}
+
+// History is a rig's work record per polecat name, read from its beads:
+// closed beads are work done; escalated and deferred beads count as failed
+// (as in the polecat CV); hooked and in-progress beads are current load.
+type History struct {
+	Rig  string
+	work map[string][]pastWork
+	load map[string]int
+}
+
+// NewHistory builds a rig's history from its beads.
+func NewHistory(rig string, issues []*beads.Issue) *History {
+	h := &History{Rig: rig, work: make(map[string][]pastWork), load: make(map[string]int)}
+	prefix := rig + "/polecats/"
+	for _, issue := range issues {
+		if !strings.HasPrefix(issue.Assignee, prefix) || issue.Ephemeral || nonWorkTypes[issue.Type] {
+			continue
+		}
+		name := strings.TrimPrefix(issue.Assignee, prefix)
+		switch issue.Status {
+		case "closed":
+			h.work[name] = append(h.work[name], pastWork{tags: issueTags(issue)})
+		case "escalated", "deferred":
+			h.work[name] = append(h.work[name], pastWork{tags: issueTags(issue), failed: true})
+		case "hooked", "in_progress":
+			h.load[name]++
+		}
+	}
+	return h
+}
+
+// similar sums an identity's past work weighted by how many of the task's
+// tags it shares: a bead with every tag counts 1. Without task tags all
+// past work counts.
+func (h *History) similar(name string, tags []string) (done, failed float64) {
+	for _, w := range h.work[name] {
+		overlap := 1.0
+		if len(tags) > 0 {
+			shared := 0
+			for _, tag := range tags {
+				if contains(w.tags, tag) {
+					shared++
+				}
+			}
+			overlap = float64(shared) / float64(len(tags))
+		}
+		if w.failed {
+			failed += overlap
+		} else {
+			done += overlap
+		}
+	}
+	return done, failed
+}
+
+// known reports whether an identity has any record.
+func (h *History) known(name string) bool {
+	return len(h.work[name]) > 0 || h.load[name] > 0
+}
+
+// Agent is a runtime a polecat can be started with.
+type Agent struct {
+	Name string `json:"name"`
+	Tier int    `json:"tier"`
+}
+
+// cheapWords and expensiveWords guess an agent's tier from its name.
+var (
+	cheapWords     = []string{"haiku", "flash", "mini", "lite", "nano", "small"}
+	expensiveWords = []string{"opus", "ultra", "max"}
+)
+
+// Tier returns an agent's cost tier: from tiers if listed there, else
+// guessed from the words of its name ("claude-haiku" is cheap).
+func Tier(agent string, tiers map[string]int) int {
+	if t, ok := tiers[agent]; ok && t >= TierCheap && t <= TierExpensive {
+		return t
+	}
+	words := strings.FieldsFunc(strings.ToLower(agent), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == '/'
+	})
+	for _, w := range words {
+		if contains(cheapWords, w) {
+			return TierCheap
+		}
+		if contains(expensiveWords, w) {
+			return TierExpensive
+		}
+	}
+	return TierStandard
+}
+
+// Router ranks candidates for a rig.
+type Router struct {
+	History  *History
+	Agents   []Agent
+	MinTiers map[string]int
+	Weights  config.RoutingWeights
+}
+
+// New returns a router over a rig's history for the given runtimes, with
+// town settings' routing config (nil for defaults).
+func New(history *History, agents []string, cfg *config.RoutingConfig) *Router {
+	if cfg == nil {
+		cfg = &config.RoutingConfig{}
+	}
+	r := &Router{History: history, MinTiers: make(map[string]int), Weights: DefaultWeights}
+	for tag, tier := range cfg.MinTiers {
+		r.MinTiers[strings.ToLower(tag)] = tier
+	}
+	for _, name := range agents {
+		r.Agents = append(r.Agents, Agent{Name: name, Tier: Tier(name, cfg.CostTiers)})
+	}
+	if w := cfg.Weights; w != nil {
+		if w.Skill > 0 {
+			r.Weights.Skill = w.Skill
+		}
+		if w.Reliability > 0 {
+			r.Weights.Reliability = w.Reliability
+		}
+		if w.Load > 0 {
+			r.Weights.Load = w.Load
+		}
+		if w.Cost > 0 {
+			r.Weights.Cost = w.Cost
+		}
+	}
+	return r
+}
+
+// Candidate is one identity and runtime scored for a task.
+type Candidate struct {
+	Name  string `json:"name"`
+	Agent string `json:"agent"`
+	Tier  int    `json:"tier"`
+	Warm  bool   `json:"warm,omitempty"`
+
+	Score       float64 `json:"score"`
+	Skill       float64 `json:"skill"`
+	Reliability float64 `json:"reliability"`
+	Load        float64 `json:"load"`
+	Cost        float64 `json:"cost"`
+
+	Done   float64 `json:"similar_done"`   // similar work done, weighted by tag overlap
+	Failed float64 `json:"similar_failed"` // similar work failed
+	Hooked int     `json:"hooked"`         // work still hooked to the identity
+
+	// Excluded says why the candidate cannot take the task; it is ranked
+	// after every candidate that can.
+	Excluded string `json:"excluded,omitempty"`
+}
+
+// Rank scores each name slot with each runtime, best first. Free names
+// without a record are interchangeable, so only the first of them (and the
+// first such warm one) is scored; busy names are listed, excluded, when
+// they have a record. Ties keep pool order, warm names first, so with no
+// history routing picks what plain allocation would.
+func (r *Router) Rank(task *Task, slots []polecat.NameSlot) []*Candidate {
+	var out []*Candidate
+	freshFree, freshWarm := false, false
+	for _, slot := range slots {
+		known := r.History.known(slot.Name)
+		switch {
+		case slot.Busy && !known:
+			continue
+		case !slot.Busy && !known && slot.Warm:
+			if freshWarm {
+				continue
+			}
+			freshWarm = true
+		case !slot.Busy && !known:
+			if freshFree {
+				continue
+			}
+			freshFree = true
+		}
+		done, failed := r.History.similar(slot.Name, task.Tags)
+		hooked := r.History.load[slot.Name]
+		for _, agent := range r.Agents {
+			c := &Candidate{
+				Name:        slot.Name,
+				Agent:       agent.Name,
+				Tier:        agent.Tier,
+				Warm:        slot.Warm,
+				Skill:       done / (done + skillHalf),
+				Reliability: (done + 1) / (done + failed + 2),
+				Load:        1 / (1 + float64(hooked)),
+				Cost:        float64(TierExpensive-agent.Tier) / float64(TierExpensive-TierCheap),
+				Done:        done,
+				Failed:      failed,
+				Hooked:      hooked,
+			}
+			c.Score = r.score(c)
+			if slot.Busy {
+				c.Excluded = "busy"
+			} else {
+				c.Excluded = r.tierExclusion(task, agent)
+			}
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if (a.Excluded == "") != (b.Excluded == "") {
+			return a.Excluded == ""
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Warm && !b.Warm
+	})
+	return out
+}
+
+// Best returns the top candidate that can take the task, or nil.
+func Best(ranked []*Candidate) *Candidate {
+	if len(ranked) > 0 && ranked[0].Excluded == "" {
+		return ranked[0]
+	}
+	return nil
+}
+
+func (r *Router) score(c *Candidate) float64 {
+	w := r.Weights
+	total := w.Skill + w.Reliability + w.Load + w.Cost
+	if total == 0 {
+		return 0
+	}
+	return (w.Skill*c.Skill + w.Reliability*c.Reliability + w.Load*c.Load + w.Cost*c.Cost) / total
+}
+
+// tierExclusion returns why a runtime is too cheap for a task's tags.
+func (r *Router) tierExclusion(task *Task, agent Agent) string {
+	for _, tag := range task.Tags {
+		if need := r.MinTiers[tag]; agent.Tier < need {
+			return fmt.Sprintf("%s needs tier %d", tag, need)
+		}
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	out := list[:0]
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,153 @@
+package routing
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/polecat"
+)
+
+func issue(id, status, assignee, typ string, labels ...string) *beads.Issue {
+	return &beads.Issue{ID: id, Status: status, Assignee: assignee, Type: typ, Labels: labels}
+}
+
+func slots(names ...string) []polecat.NameSlot {
+	var out []polecat.NameSlot
+	for _, n := range names {
+		out = append(out, polecat.NameSlot{Name: n})
+	}
+	return out
+}
+
+func TestNewTask_Tags(t *testing.T) {
+	task := NewTask(issue("gt-1", "open", "", "bug", "area:Auth", "gt:agent", "from:mayor", "go", "skill:go"), []string{"Testing", "go"})
+	want := []string{"auth", "go", "type:bug", "testing"}
+	if !reflect.DeepEqual(task.Tags, want) {
+		t.Errorf("Tags = %v, want %v", task.Tags, want)
+	}
+}
+
+func TestTier(t *testing.T) {
+	tests := []struct {
+		agent string
+		tiers map[string]int
+		want  int
+	}{
+		{"claude", nil, TierStandard},
+		{"claude-haiku", nil, TierCheap},
+		{"gemini-flash", nil, TierCheap},
+		{"claude-opus", nil, TierExpensive},
+		{"claude", map[string]int{"claude": 3}, TierExpensive},
+		{"claude-haiku", map[string]int{"claude-haiku": 9}, TierCheap}, // out of range: guessed
+	}
+	for _, tt := range tests {
+		if got := Tier(tt.agent, tt.tiers); got != tt.want {
+			t.Errorf("Tier(%q, %v) = %d, want %d", tt.agent, tt.tiers, got, tt.want)
+		}
+	}
+}
+
+func TestRank_PrefersTrackRecord(t *testing.T) {
+	history := NewHistory("gastown", []*beads.Issue{
+		issue("gt-1", "closed", "gastown/polecats/nux", "bug", "auth"),
+		issue("gt-2", "closed", "gastown/polecats/nux", "bug", "auth"),
+		issue("gt-3", "closed", "gastown/polecats/slit", "task", "ui"),
+		issue("gt-4", "escalated", "gastown/polecats/toast", "bug", "auth"),
+		issue("gt-5", "closed", "gastown/polecats/toast", "bug", "auth"),
+		issue("gt-6", "closed", "other/polecats/furiosa", "bug", "auth"), // other rig
+		issue("gt-7", "closed", "gastown/polecats/furiosa", "message"),   // not work
+	})
+	r := New(history, []string{"claude"}, nil)
+	task := NewTask(issue("gt-9", "open", "", "bug", "auth"), nil)
+
+	ranked := r.Rank(task, slots("furiosa", "nux", "slit", "toast", "dag"))
+	var order []string
+	for _, c := range ranked {
+		order = append(order, c.Name)
+	}
+	// nux has done the most similar work and toast failed once. slit's
+	// unrelated work counts for nothing, so it ties with furiosa, the first
+	// fresh name, and pool order decides. dag is another fresh name.
+	want := []string{"nux", "toast", "furiosa", "slit"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("rank = %v, want %v", order, want)
+	}
+	if best := Best(ranked); best == nil || best.Name != "nux" {
+		t.Errorf("Best = %+v, want nux", best)
+	}
+	if nux := ranked[0]; nux.Done != 2 || nux.Failed != 0 {
+		t.Errorf("nux similar = %v done, %v failed, want 2, 0", nux.Done, nux.Failed)
+	}
+}
+
+func TestRank_NoHistoryKeepsPoolOrder(t *testing.T) {
+	r := New(NewHistory("gastown", nil), []string{"claude"}, nil)
+	task := NewTask(issue("gt-1", "open", "", "task"), nil)
+
+	in := []polecat.NameSlot{{Name: "furiosa", Busy: true}, {Name: "nux"}, {Name: "slit", Warm: true}, {Name: "toast"}}
+	ranked := r.Rank(task, in)
+	if len(ranked) != 2 {
+		t.Fatalf("rank = %d candidates, want the first warm and first free name", len(ranked))
+	}
+	if ranked[0].Name != "slit" || ranked[1].Name != "nux" {
+		t.Errorf("rank = %s, %s; want the warm slit first, then nux", ranked[0].Name, ranked[1].Name)
+	}
+}
+
+func TestRank_LoadAndBusy(t *testing.T) {
+	history := NewHistory("gastown", []*beads.Issue{
+		issue("gt-1", "closed", "gastown/polecats/nux", "task", "go"),
+		issue("gt-2", "closed", "gastown/polecats/slit", "task", "go"),
+		issue("gt-3", "hooked", "gastown/polecats/slit", "task"),
+		issue("gt-4", "closed", "gastown/polecats/toast", "task", "go"),
+		issue("gt-5", "closed", "gastown/polecats/toast", "task", "go"),
+	})
+	r := New(history, []string{"claude"}, nil)
+	task := NewTask(issue("gt-9", "open", "", "task", "go"), nil)
+
+	in := slots("nux", "slit", "toast")
+	in[2].Busy = true
+	ranked := r.Rank(task, in)
+	if ranked[0].Name != "nux" || ranked[1].Name != "slit" {
+		t.Errorf("rank = %s, %s; want nux before slit, which has work hooked", ranked[0].Name, ranked[1].Name)
+	}
+	last := ranked[len(ranked)-1]
+	if last.Name != "toast" || last.Excluded != "busy" {
+		t.Errorf("last = %+v, want toast excluded as busy despite the best record", last)
+	}
+}
+
+func TestRank_CostAndMinTiers(t *testing.T) {
+	cfg := &config.RoutingConfig{MinTiers: map[string]int{"Security": 3}}
+	r := New(NewHistory("gastown", nil), []string{"claude", "claude-haiku", "claude-opus"}, cfg)
+
+	plain := r.Rank(NewTask(issue("gt-1", "open", "", "task"), nil), slots("nux"))
+	if plain[0].Agent != "claude-haiku" || plain[len(plain)-1].Agent != "claude-opus" {
+		t.Errorf("plain task ranked %s first and %s last, want haiku then opus", plain[0].Agent, plain[len(plain)-1].Agent)
+	}
+
+	secure := r.Rank(NewTask(issue("gt-2", "open", "", "task", "security"), nil), slots("nux"))
+	if best := Best(secure); best == nil || best.Agent != "claude-opus" {
+		t.Fatalf("Best for security = %+v, want claude-opus", best)
+	}
+	for _, c := range secure[1:] {
+		if c.Excluded != "security needs tier 3" {
+			t.Errorf("%s excluded = %q, want tier exclusion", c.Agent, c.Excluded)
+		}
+	}
+
+	allExcluded := New(NewHistory("gastown", nil), []string{"claude-haiku"}, cfg)
+	if best := Best(allExcluded.Rank(NewTask(issue("gt-3", "open", "", "task", "security"), nil), slots("nux"))); best != nil {
+		t.Errorf("Best = %+v, want nil when every runtime is excluded", best)
+	}
+}
+
+func TestNew_Weights(t *testing.T) {
+	r := New(NewHistory("gastown", nil), nil, &config.RoutingConfig{Weights: &config.RoutingWeights{Cost: 0.5}})
+	want := config.RoutingWeights{Skill: 0.4, Reliability: 0.3, Load: 0.2, Cost: 0.5}
+	if r.Weights != want {
+		t.Errorf("Weights = %+v, want %+v", r.Weights, want)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/claude"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/hooks"
 	"github.com/steveyegge/gastown/internal/opencode"
 	"github.com/steveyegge/gastown/internal/templates/commands"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -35,7 +36,8 @@ func EnsureSettingsForRole(workDir, role string, rc *config.RuntimeConfig) error
 		return nil
 	}
 
-	// 1. Provider-specific settings (settings.json for Claude, plugin for OpenCode)
+	// 1. Provider-specific settings (settings.json for Claude, plugin for OpenCode,
+	// translated hooks for the rest)
 	switch provider {
 	case "claude":
 		if err := claude.EnsureSettingsForRoleAt(workDir, role, rc.Hooks.Dir, rc.Hooks.SettingsFile); err != nil {
@@ -45,6 +47,16 @@ func EnsureSettingsForRole(workDir, role string, rc *config.RuntimeConfig) error
 		if err := opencode.EnsurePluginAt(workDir, rc.Hooks.Dir, rc.Hooks.SettingsFile); err != nil {
 			return err
 		}
+	default:
+		// Gemini, Cursor and hookless runtimes: translated from the hooks base
+		// config and role override
+		instructions := ""
+		if rc.Instructions != nil {
+			instructions = rc.Instructions.File
+		}
+		if err := hooks.Install(provider, workDir, instructions, hooks.TargetForRole(role, "")); err != nil {
+			return err
+		}
 	}
 
 	// 2. Slash commands (agent-agnostic, uses shared body with provider-specific frontmatter)
@@ -85,7 +97,7 @@ func StartupFallbackCommands(role string, rc *config.RuntimeConfig) []string {
 	if rc == nil {
 		rc = config.DefaultRuntimeConfig()
 	}
-	if rc.Hooks != nil && rc.Hooks.Provider != "" && rc.Hooks.Provider != "none" {
+	if rc.Hooks != nil && hooks.RunsSessionStart(rc.Hooks.Provider) {
 		return nil
 	}
 
@@ -165,7 +177,7 @@ func GetStartupFallbackInfo(rc *config.RuntimeConfig) *StartupFallbackInfo {
 		rc = config.DefaultRuntimeConfig()
 	}
 
-	hasHooks := rc.Hooks != nil && rc.Hooks.Provider != "" && rc.Hooks.Provider != "none"
+	hasHooks := rc.Hooks != nil && hooks.RunsSessionStart(rc.Hooks.Provider)
 	hasPrompt := rc.PromptMode != "none"
 
 	info := &StartupFallbackInfo{}
@@ -0,0 +1,337 @@
+// Package sandbox applies per-role resource limits to agent sessions.
+//
+// Limits come from the [resources] table of a role definition (see
+// config.RoleResourceConfig) and are enforced with cgroup v2 on Linux, using
+// one of two backends:
+//
+//   - cgroupfs: a writable cgroup v2 directory (default /sys/fs/cgroup/gastown,
+//     or $GT_CGROUP_ROOT). Each session gets a child cgroup and the session
+//     command moves itself into it before starting the agent.
+//   - systemd: `systemd-run --user --scope`, when the user's systemd manager is
+//     running. Each session runs in a transient scope unit named after it.
+//
+// The backend and limits used for each session are recorded in
+// <town>/.runtime/sandbox/<session>.json so `gt status` can report usage and
+// a dead session can be attributed to a limit kill.
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Backends.
+const (
+	BackendNone     = ""
+	BackendCgroupfs = "cgroupfs"
+	BackendSystemd  = "systemd"
+)
+
+// CgroupRootEnv overrides the cgroup directory used by the cgroupfs backend.
+const CgroupRootEnv = "GT_CGROUP_ROOT"
+
+// ErrUnavailable is returned when limits are configured but no backend can
+// enforce them on this host.
+var ErrUnavailable = errors.New("cgroup v2 resource limits are not available (need a writable cgroup v2 directory or systemd --user)")
+
+// cgroupMount is where the cgroup v2 hierarchy is mounted. Tests override it.
+var cgroupMount = "/sys/fs/cgroup"
+
+// state records how a session was sandboxed.
+type state struct {
+	Session   string                    `json:"session"`
+	Backend   string                    `json:"backend"`
+	Cgroup    string                    `json:"cgroup,omitempty"` // cgroupfs: the session's cgroup directory
+	Unit      string                    `json:"unit,omitempty"`   // systemd: the scope unit
+	Limits    config.RoleResourceConfig `json:"limits"`
+	StartedAt time.Time                 `json:"started_at"`
+	// OOMKills is the cgroup's oom_kill count when the session started. A
+	// reused cgroup keeps its counters, so only kills past it are ours.
+	OOMKills int `json:"oom_kills,omitempty"`
+}
+
+func stateDir(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "sandbox")
+}
+
+func statePath(townRoot, session string) string {
+	return filepath.Join(stateDir(townRoot), session+".json")
+}
+
+func loadState(townRoot, session string) (*state, error) {
+	data, err := os.ReadFile(statePath(townRoot, session)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil {
+		return nil, err
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (st *state) save(townRoot string) error {
+	if err := os.MkdirAll(stateDir(townRoot), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(statePath(townRoot, st.Session), data, 0644)
+}
+
+// cgroupfsRoot returns the directory the cgroupfs backend creates session
+// cgroups in.
+func cgroupfsRoot() string {
+	if root := os.Getenv(CgroupRootEnv); root != "" {
+		return root
+	}
+	return filepath.Join(cgroupMount, "gastown")
+}
+
+var (
+	detectOnce    sync.Once
+	detectedValue string
+)
+
+// Detect returns the backend available on this host, or BackendNone.
+// cgroupfs is preferred when its root directory exists on a cgroup v2 mount.
+func Detect() string {
+	detectOnce.Do(func() {
+		detectedValue = detect()
+	})
+	return detectedValue
+}
+
+func detect() string {
+	if runtime.GOOS != "linux" {
+		return BackendNone
+	}
+	if _, err := os.Stat(filepath.Join(cgroupMount, "cgroup.controllers")); err != nil {
+		return BackendNone // Not cgroup v2
+	}
+	if info, err := os.Stat(cgroupfsRoot()); err == nil && info.IsDir() {
+		return BackendCgroupfs
+	}
+	if _, err := exec.LookPath("systemd-run"); err == nil {
+		// "degraded" exits non-zero but the manager still runs units
+		out, _ := exec.Command("systemctl", "--user", "is-system-running").Output()
+		switch strings.TrimSpace(string(out)) {
+		case "running", "degraded":
+			return BackendSystemd
+		}
+	}
+	return BackendNone
+}
+
+// WrapRole wraps a session command with the resource limits of role, loaded
+// with the usual builtin → town → rig override resolution. rigPath is empty
+// for town-level roles.
+func WrapRole(townRoot, rigPath, role, session, command string) (string, error) {
+	def, err := config.LoadRoleDefinition(townRoot, rigPath, role)
+	if err != nil {
+		return command, err
+	}
+	return Wrap(townRoot, session, command, def.Resources)
+}
+
+// WrapRoleChecked is WrapRole for session startup: it first reports whether
+// the session's previous run was killed by a limit (wrapping releases that
+// run's sandbox), and returns what went wrong as warnings, since neither
+// should stop the session from starting.
+func WrapRoleChecked(townRoot, rigPath, role, session, command string) (string, []string) {
+	var warnings []string
+	if reason := LimitKill(townRoot, session); reason != "" {
+		warnings = append(warnings, "previous session was killed: "+reason)
+	}
+	wrapped, err := WrapRole(townRoot, rigPath, role, session, command)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("resource limits not applied: %v", err))
+	}
+	return wrapped, warnings
+}
+
+// Wrap returns command wrapped so the session runs under limits, and records
+// the session's sandbox state. Any state left by a previous session of the
+// same name is released first. With no limits configured the command is
+// returned unchanged. network = "none" needs no cgroup backend and is applied
+// on its own; other limits without a backend fail with ErrUnavailable. On
+// error the unwrapped command is returned so callers can warn and start the
+// session anyway.
+func Wrap(townRoot, session, command string, limits config.RoleResourceConfig) (string, error) {
+	Release(townRoot, session)
+	if limits.IsZero() {
+		return command, nil
+	}
+
+	memMax, err := ParseSize(limits.MemoryMax)
+	if err != nil {
+		return command, err
+	}
+	if limits.CPUWeight < 0 || limits.CPUWeight > 10000 {
+		return command, fmt.Errorf("invalid cpu_weight %d: must be 1-10000", limits.CPUWeight)
+	}
+	if limits.PidsMax < 0 {
+		return command, fmt.Errorf("invalid pids_max %d", limits.PidsMax)
+	}
+
+	wrapped := command
+	switch limits.Network {
+	case "", "host":
+	case "none":
+		// --map-current-user keeps the agent's uid: Claude refuses to skip
+		// permissions as root, which --map-root-user would make it.
+		wrapped = "exec unshare --user --map-current-user --net -- sh -c " + config.ShellQuote(wrapped)
+	default:
+		return command, fmt.Errorf("invalid network %q: must be host or none", limits.Network)
+	}
+
+	st := &state{Session: session, Limits: limits, StartedAt: time.Now().UTC()}
+	switch backend := Detect(); {
+	case limits.CPUWeight == 0 && memMax == 0 && limits.PidsMax == 0:
+		// Network isolation only: nothing for a cgroup to enforce
+	case backend == BackendCgroupfs:
+		dir, err := createCgroup(session, limits, memMax)
+		if err != nil {
+			return command, err
+		}
+		st.Backend, st.Cgroup = BackendCgroupfs, dir
+		st.OOMKills = int(readKeyed(dir, "memory.events", "oom_kill"))
+		// The shell joins the cgroup before exec'ing the agent, so every
+		// process it spawns is accounted to the session.
+		wrapped = "echo $$ > " + config.ShellQuote(filepath.Join(dir, "cgroup.procs")) + " && " + wrapped
+	case backend == BackendSystemd:
+		st.Backend, st.Unit = BackendSystemd, session+".scope"
+		wrapped = systemdRunCommand(session, limits, memMax, wrapped)
+	case limits.Network == "none":
+		return command, fmt.Errorf("%w; network = \"none\" not applied either", ErrUnavailable)
+	default:
+		return command, ErrUnavailable
+	}
+
+	if err := st.save(townRoot); err != nil {
+		return command, fmt.Errorf("recording sandbox state: %w", err)
+	}
+	return wrapped, nil
+}
+
+// createCgroup creates the session's cgroup under the cgroupfs root and
+// writes its limits.
+func createCgroup(session string, limits config.RoleResourceConfig, memMax int64) (string, error) {
+	root := cgroupfsRoot()
+	// Controllers must be enabled on the parent before children can use
+	// them. Each is enabled separately so one missing controller doesn't
+	// block the others; writeLimit reports the ones actually needed.
+	for _, c := range []string{"+cpu", "+memory", "+pids"} {
+		_ = os.WriteFile(filepath.Join(root, "cgroup.subtree_control"), []byte(c), 0644) //nolint:gosec // G306: cgroup interface file
+	}
+
+	dir := filepath.Join(root, session)
+	if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+		return "", fmt.Errorf("creating cgroup: %w", err)
+	}
+	if limits.CPUWeight > 0 {
+		if err := writeLimit(dir, "cpu.weight", strconv.Itoa(limits.CPUWeight)); err != nil {
+			return "", err
+		}
+	}
+	if memMax > 0 {
+		if err := writeLimit(dir, "memory.max", strconv.FormatInt(memMax, 10)); err != nil {
+			return "", err
+		}
+		// Without this the session swaps instead of hitting the limit
+		_ = writeLimit(dir, "memory.swap.max", "0")
+	}
+	if limits.PidsMax > 0 {
+		if err := writeLimit(dir, "pids.max", strconv.Itoa(limits.PidsMax)); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+func writeLimit(dir, file, value string) error {
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(value), 0644); err != nil { //nolint:gosec // G306: cgroup interface file
+		return fmt.Errorf("setting %s: %w", file, err)
+	}
+	return nil
+}
+
+// systemdRunCommand wraps command in a transient systemd scope with limits.
+// The scope is not garbage-collected on failure, so an OOM kill stays
+// visible (Result=oom-kill) until Release resets it.
+func systemdRunCommand(session string, limits config.RoleResourceConfig, memMax int64, command string) string {
+	args := []string{"exec systemd-run --user --scope --quiet", "--unit=" + config.ShellQuote(session)}
+	if limits.CPUWeight > 0 {
+		args = append(args, fmt.Sprintf("-p CPUWeight=%d", limits.CPUWeight))
+	}
+	if memMax > 0 {
+		args = append(args, fmt.Sprintf("-p MemoryMax=%d", memMax), "-p MemorySwapMax=0")
+	}
+	if limits.PidsMax > 0 {
+		args = append(args, fmt.Sprintf("-p TasksMax=%d", limits.PidsMax))
+	}
+	args = append(args, "-- sh -c", config.ShellQuote(command))
+	return strings.Join(args, " ")
+}
+
+// Release removes a session's sandbox state: the empty cgroup directory or
+// the failed systemd scope, and the state file. Best-effort.
+func Release(townRoot, session string) {
+	st, err := loadState(townRoot, session)
+	if err != nil {
+		return
+	}
+	switch st.Backend {
+	case BackendCgroupfs:
+		_ = os.Remove(st.Cgroup) // Fails harmlessly while processes remain
+	case BackendSystemd:
+		_ = exec.Command("systemctl", "--user", "reset-failed", st.Unit).Run() //nolint:gosec // G204: unit is derived from a session name
+	}
+	_ = os.Remove(statePath(townRoot, session))
+}
+
+// ParseSize parses a memory size such as "512M", "4G" or "1073741824".
+// Suffixes K, M, G and T are powers of 1024. Empty and "max" return 0
+// (unlimited).
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "max" {
+		return 0, nil
+	}
+	mult := int64(1)
+	upper := strings.TrimSuffix(strings.ToUpper(s), "B")
+	if n := len(upper); n > 0 {
+		switch upper[n-1] {
+		case 'K':
+			mult = 1 << 10
+		case 'M':
+			mult = 1 << 20
+		case 'G':
+			mult = 1 << 30
+		case 'T':
+			mult = 1 << 40
+		}
+		if mult > 1 {
+			upper = upper[:n-1]
+		}
+	}
+	n, err := strconv.ParseFloat(upper, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid memory size %q", s)
+	}
+	return int64(n * float64(mult)), nil
+}
@@ -0,0 +1,217 @@
+package sandbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// fakeCgroupMount points the package at a fake cgroup v2 hierarchy with a
+// gastown root, so the cgroupfs backend is detected.
+func fakeCgroupMount(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("cgroup backends are Linux-only")
+	}
+	mount := t.TempDir()
+	if err := os.WriteFile(filepath.Join(mount, "cgroup.controllers"), []byte("cpu memory pids\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(mount, "gastown"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	oldMount := cgroupMount
+	cgroupMount = mount
+	t.Setenv(CgroupRootEnv, "")
+	detectOnce = sync.Once{}
+	t.Cleanup(func() {
+		cgroupMount = oldMount
+		detectOnce = sync.Once{}
+	})
+	return mount
+}
+
+// noCgroupMount points the package at a host without cgroup v2, so no
+// backend is detected.
+func noCgroupMount(t *testing.T) {
+	t.Helper()
+	oldMount := cgroupMount
+	cgroupMount = t.TempDir()
+	detectOnce = sync.Once{}
+	t.Cleanup(func() {
+		cgroupMount = oldMount
+		detectOnce = sync.Once{}
+	})
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"", 0},
+		{"max", 0},
+		{"1024", 1024},
+		{"512M", 512 << 20},
+		{"4G", 4 << 30},
+		{"4GB", 4 << 30},
+		{"1.5g", 3 << 29},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"lots", "-1G", "G"} {
+		if _, err := ParseSize(bad); err == nil {
+			t.Errorf("ParseSize(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestWrap_NoLimitsLeavesCommand(t *testing.T) {
+	town := t.TempDir()
+	got, err := Wrap(town, "gt-gastown-Toast", "exec claude", config.RoleResourceConfig{})
+	if err != nil || got != "exec claude" {
+		t.Errorf("Wrap = %q, %v; want command unchanged", got, err)
+	}
+	if stats, _ := Usage(town, "gt-gastown-Toast"); stats != nil {
+		t.Errorf("Usage = %+v for an unsandboxed session, want nil", stats)
+	}
+}
+
+func TestWrap_Cgroupfs(t *testing.T) {
+	mount := fakeCgroupMount(t)
+	town := t.TempDir()
+	limits := config.RoleResourceConfig{CPUWeight: 50, MemoryMax: "1G", PidsMax: 256}
+
+	got, err := Wrap(town, "gt-gastown-Toast", "exec claude", limits)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	dir := filepath.Join(mount, "gastown", "gt-gastown-Toast")
+	want := "echo $$ > " + filepath.Join(dir, "cgroup.procs") + " && exec claude"
+	if got != want {
+		t.Errorf("Wrap = %q, want %q", got, want)
+	}
+	for file, value := range map[string]string{"cpu.weight": "50", "memory.max": "1073741824", "pids.max": "256"} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil || string(data) != value {
+			t.Errorf("%s = %q, %v; want %q", file, data, err, value)
+		}
+	}
+
+	// Simulate the kernel reporting usage and an OOM kill
+	_ = os.WriteFile(filepath.Join(dir, "memory.current"), []byte("536870912\n"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "memory.events"), []byte("low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n"), 0644)
+	stats, err := Usage(town, "gt-gastown-Toast")
+	if err != nil || stats == nil {
+		t.Fatalf("Usage = %v, %v", stats, err)
+	}
+	if stats.Backend != BackendCgroupfs || stats.MemoryPercent() != 50 || stats.PidsMax != 256 {
+		t.Errorf("Usage = %+v, want cgroupfs at 50%% memory with pids_max 256", stats)
+	}
+	if reason := LimitKill(town, "gt-gastown-Toast"); !strings.Contains(reason, "memory limit") {
+		t.Errorf("LimitKill = %q, want a memory limit reason", reason)
+	}
+
+	// Restarting the session releases the old state
+	if _, err := Wrap(town, "gt-gastown-Toast", "exec claude", config.RoleResourceConfig{}); err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	if reason := LimitKill(town, "gt-gastown-Toast"); reason != "" {
+		t.Errorf("LimitKill after restart = %q, want none", reason)
+	}
+}
+
+func TestWrap_NetworkNone(t *testing.T) {
+	fakeCgroupMount(t)
+	got, err := Wrap(t.TempDir(), "gt-gastown-Nux", "exec claude", config.RoleResourceConfig{Network: "none"})
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	if !strings.Contains(got, "unshare --user --map-current-user --net -- sh -c 'exec claude'") {
+		t.Errorf("Wrap = %q, want the command run in a new network namespace", got)
+	}
+
+	if _, err := Wrap(t.TempDir(), "gt-gastown-Nux", "exec claude", config.RoleResourceConfig{Network: "vpn"}); err == nil {
+		t.Error("Wrap accepted an unknown network mode")
+	}
+}
+
+func TestLimitKill_OnlyCountsNewOOMKills(t *testing.T) {
+	mount := fakeCgroupMount(t)
+	town := t.TempDir()
+	dir := filepath.Join(mount, "gastown", "gt-gastown-Toast")
+
+	// A cgroup left behind by an earlier run keeps its counters
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "memory.events"), []byte("oom 2\noom_kill 2\n"), 0644)
+	if _, err := Wrap(town, "gt-gastown-Toast", "exec claude", config.RoleResourceConfig{MemoryMax: "1G", PidsMax: 64}); err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	if reason := LimitKill(town, "gt-gastown-Toast"); reason != "" {
+		t.Errorf("LimitKill = %q for kills before the session started, want none", reason)
+	}
+
+	// Refused forks don't kill the session
+	_ = os.WriteFile(filepath.Join(dir, "pids.events"), []byte("max 5\n"), 0644)
+	if reason := LimitKill(town, "gt-gastown-Toast"); reason != "" {
+		t.Errorf("LimitKill = %q after pids.max hits, want none", reason)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "memory.events"), []byte("oom 3\noom_kill 3\n"), 0644)
+	if reason := LimitKill(town, "gt-gastown-Toast"); !strings.Contains(reason, "memory limit") {
+		t.Errorf("LimitKill = %q, want a memory limit reason", reason)
+	}
+}
+
+func TestWrap_NoBackend(t *testing.T) {
+	noCgroupMount(t)
+
+	got, err := Wrap(t.TempDir(), "gt-gastown-Nux", "exec claude", config.RoleResourceConfig{Network: "none"})
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	if !strings.Contains(got, "unshare --user --map-current-user --net") {
+		t.Errorf("Wrap = %q, want network isolation without a cgroup backend", got)
+	}
+
+	got, err = Wrap(t.TempDir(), "gt-gastown-Nux", "exec claude", config.RoleResourceConfig{MemoryMax: "1G", Network: "none"})
+	if !errors.Is(err, ErrUnavailable) || !strings.Contains(err.Error(), "network") {
+		t.Errorf("Wrap error = %v, want ErrUnavailable naming the network limit", err)
+	}
+	if got != "exec claude" {
+		t.Errorf("Wrap = %q on error, want the command unchanged", got)
+	}
+}
+
+func TestWrapRoleChecked_WarnsWithoutBackend(t *testing.T) {
+	noCgroupMount(t)
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "roles"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	override := "[resources]\nmemory_max = \"1G\"\n"
+	if err := os.WriteFile(filepath.Join(townRoot, "roles", "crew.toml"), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, warnings := WrapRoleChecked(townRoot, "", "crew", "gt-gastown-crew-joe", "exec claude")
+	if got != "exec claude" {
+		t.Errorf("WrapRoleChecked = %q, want the command unchanged", got)
+	}
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "resource limits not applied") {
+		t.Errorf("warnings = %q, want one about limits not applied", warnings)
+	}
+}
@@ -0,0 +1,150 @@
+package sandbox
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stats is a session's resource usage as reported by its cgroup.
+type Stats struct {
+	Backend       string        `json:"backend"`
+	MemoryCurrent int64         `json:"memory_current"`
+	MemoryMax     int64         `json:"memory_max,omitempty"` // 0 = unlimited
+	PidsCurrent   int           `json:"pids_current"`
+	PidsMax       int           `json:"pids_max,omitempty"` // 0 = unlimited
+	CPUWeight     int           `json:"cpu_weight,omitempty"`
+	CPUTime       time.Duration `json:"cpu_time"`
+	OOMKills      int           `json:"oom_kills,omitempty"`       // Processes killed at memory.max
+	PidsLimitHits int           `json:"pids_limit_hits,omitempty"` // Forks refused at pids.max (the session survives these)
+}
+
+// MemoryPercent returns memory use as a percentage of the limit, or 0 when
+// memory is unlimited.
+func (s *Stats) MemoryPercent() int {
+	if s.MemoryMax <= 0 {
+		return 0
+	}
+	return int(s.MemoryCurrent * 100 / s.MemoryMax)
+}
+
+// Usage returns the resource usage of a sandboxed session, or nil if the
+// session wasn't started with limits or its cgroup is gone.
+func Usage(townRoot, session string) (*Stats, error) {
+	st, err := loadState(townRoot, session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	dir := st.cgroupDir()
+	if dir == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, nil
+	}
+	stats := readStats(dir)
+	stats.Backend = st.Backend
+	return stats, nil
+}
+
+// LimitKill reports whether a dead session was killed by one of its limits,
+// returning a reason suitable for a session-death event, or "" if not.
+// Only OOM kills since the session started count. Forks refused at pids.max
+// don't kill anything, so they are never reported as the cause of death.
+// Call it before the session is restarted, which releases the evidence.
+func LimitKill(townRoot, session string) string {
+	st, err := loadState(townRoot, session)
+	if err != nil {
+		return ""
+	}
+
+	if dir := st.cgroupDir(); dir != "" {
+		if _, err := os.Stat(dir); err == nil {
+			if readStats(dir).OOMKills > st.OOMKills {
+				return fmt.Sprintf("memory limit exceeded (memory_max %s)", st.Limits.MemoryMax)
+			}
+			return ""
+		}
+	}
+
+	// A systemd scope's cgroup is gone once its processes exit, but a
+	// failed scope keeps its result until reset.
+	if st.Backend == BackendSystemd {
+		out, err := exec.Command("systemctl", "--user", "show", "-p", "Result", "--value", st.Unit).Output() //nolint:gosec // G204: unit is derived from a session name
+		if err == nil && strings.TrimSpace(string(out)) == "oom-kill" {
+			return fmt.Sprintf("memory limit exceeded (memory_max %s)", st.Limits.MemoryMax)
+		}
+	}
+	return ""
+}
+
+// cgroupDir returns the session's cgroup directory, or "" if unknown.
+func (st *state) cgroupDir() string {
+	switch st.Backend {
+	case BackendCgroupfs:
+		return st.Cgroup
+	case BackendSystemd:
+		out, err := exec.Command("systemctl", "--user", "show", "-p", "ControlGroup", "--value", st.Unit).Output() //nolint:gosec // G204: unit is derived from a session name
+		if err != nil {
+			return ""
+		}
+		cg := strings.TrimSpace(string(out))
+		if cg == "" {
+			return ""
+		}
+		return filepath.Join(cgroupMount, cg)
+	}
+	return ""
+}
+
+// readStats reads usage from a cgroup v2 directory. Missing files (e.g. a
+// controller that isn't enabled) leave their fields zero.
+func readStats(dir string) *Stats {
+	s := &Stats{
+		MemoryCurrent: readInt(dir, "memory.current"),
+		MemoryMax:     readInt(dir, "memory.max"),
+		PidsCurrent:   int(readInt(dir, "pids.current")),
+		PidsMax:       int(readInt(dir, "pids.max")),
+		CPUWeight:     int(readInt(dir, "cpu.weight")),
+		OOMKills:      int(readKeyed(dir, "memory.events", "oom_kill")),
+		PidsLimitHits: int(readKeyed(dir, "pids.events", "max")),
+	}
+	s.CPUTime = time.Duration(readKeyed(dir, "cpu.stat", "usage_usec")) * time.Microsecond
+	return s
+}
+
+// readInt reads a single-value cgroup file. "max" reads as 0.
+func readInt(dir, file string) int64 {
+	data, err := os.ReadFile(filepath.Join(dir, file)) //nolint:gosec // G304: cgroup interface file
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	return n
+}
+
+// readKeyed reads one key from a flat-keyed cgroup file such as memory.events.
+func readKeyed(dir, file, key string) int64 {
+	f, err := os.Open(filepath.Join(dir, file)) //nolint:gosec // G304: cgroup interface file
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			n, _ := strconv.ParseInt(fields[1], 10, 64)
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,43 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * FOO *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	// Friday 2026-10-16 08:30 local
+	from := time.Date(2026, 10, 16, 8, 30, 20, 0, time.Local)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 16, 8, 31, 0, 0, time.Local)},
+		{"0 9 * * MON-FRI", time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)},
+		{"0 8 * * 1-5", time.Date(2026, 10, 19, 8, 0, 0, 0, time.Local)}, // past today: Monday
+		{"*/15 * * * *", time.Date(2026, 10, 16, 8, 45, 0, 0, time.Local)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)}, // 7 is Sunday
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local)},
+		{"30 12 1 JAN *", time.Date(2027, 1, 1, 12, 30, 0, 0, time.Local)},
+		// Both day fields restricted: either matches (the 20th is a Tuesday)
+		{"0 0 20 * SAT", time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
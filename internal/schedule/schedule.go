@@ -0,0 +1,325 @@
+// Package schedule stores delayed and recurring mail and nudges. The
+// daemon heartbeat runs `gt schedule run`, which delivers the entries that
+// are due.
+//
+// Entries live in a town runtime file, so they survive daemon restarts; a
+// one-shot entry scheduled while the daemon was down fires on the first
+// heartbeat after it comes back, and a recurring one fires once for the
+// occurrences it missed. Runs hold a lock and mark an entry before
+// delivering it, so no occurrence is delivered twice: an occurrence whose
+// run died mid-delivery is reported as interrupted rather than repeated.
+package schedule
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Entry kinds.
+const (
+	KindMail  = "mail"
+	KindNudge = "nudge"
+)
+
+// MaxAttempts is how many runs in a row may fail to deliver an occurrence
+// before it is dropped (one-shot) or skipped (recurring).
+const MaxAttempts = 5
+
+// ErrNotFound indicates no entry has the given ID.
+var ErrNotFound = errors.New("schedule entry not found")
+
+// Nudge is a scheduled nudge.
+type Nudge struct {
+	Target  string `json:"target"`
+	Sender  string `json:"sender"`
+	Message string `json:"message"`
+	Force   bool   `json:"force,omitempty"`
+}
+
+// Entry is a scheduled mail or nudge.
+type Entry struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Next is when the entry fires next.
+	Next time.Time `json:"next"`
+
+	// Cron makes the entry recurring.
+	Cron string `json:"cron,omitempty"`
+
+	Mail  *mail.Message `json:"mail,omitempty"`
+	Nudge *Nudge        `json:"nudge,omitempty"`
+
+	Fired     int       `json:"fired"`
+	LastFired time.Time `json:"last_fired,omitempty"`
+	Attempts  int       `json:"attempts,omitempty"` // failed runs for the current occurrence
+	LastError string    `json:"last_error,omitempty"`
+
+	// Firing is set while an occurrence is being delivered.
+	Firing *time.Time `json:"firing,omitempty"`
+}
+
+// Target returns the address the entry is sent to.
+func (e *Entry) Target() string {
+	switch {
+	case e.Mail != nil:
+		return e.Mail.To
+	case e.Nudge != nil:
+		return e.Nudge.Target
+	}
+	return ""
+}
+
+// Summary returns the mail subject or nudge text.
+func (e *Entry) Summary() string {
+	switch {
+	case e.Mail != nil:
+		return e.Mail.Subject
+	case e.Nudge != nil:
+		return e.Nudge.Message
+	}
+	return ""
+}
+
+// Dir returns the directory holding the schedule.
+func Dir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "schedule")
+}
+
+func entriesPath(townRoot string) string {
+	return filepath.Join(Dir(townRoot), "entries.json")
+}
+
+// withLock runs fn holding the schedule lock.
+func withLock(townRoot string, fn func() error) error {
+	if err := os.MkdirAll(Dir(townRoot), 0755); err != nil {
+		return err
+	}
+	lock := flock.New(filepath.Join(Dir(townRoot), ".lock"))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking schedule: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return fn()
+}
+
+func load(townRoot string) ([]*Entry, error) {
+	data, err := os.ReadFile(entriesPath(townRoot)) //nolint:gosec // G304: path is under the town runtime dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing schedule: %w", err)
+	}
+	return entries, nil
+}
+
+func save(townRoot string, entries []*Entry) error {
+	if entries == nil {
+		entries = []*Entry{}
+	}
+	return util.AtomicWriteJSON(entriesPath(townRoot), entries)
+}
+
+// Add validates an entry, assigns its ID and stores it. A recurring entry
+// without a Next time fires at its first cron occurrence.
+func Add(townRoot string, e *Entry) error {
+	if (e.Mail == nil) == (e.Nudge == nil) {
+		return fmt.Errorf("schedule entry needs a mail or a nudge")
+	}
+	e.Kind = KindMail
+	if e.Nudge != nil {
+		e.Kind = KindNudge
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if e.Cron != "" {
+		c, err := ParseCron(e.Cron)
+		if err != nil {
+			return err
+		}
+		if e.Next.IsZero() {
+			e.Next = c.Next(e.CreatedAt)
+		}
+		if e.Next.IsZero() {
+			return fmt.Errorf("cron %q never fires", e.Cron)
+		}
+	}
+	if e.Next.IsZero() {
+		return fmt.Errorf("schedule entry needs a time or a cron expression")
+	}
+	e.ID = newID()
+	return withLock(townRoot, func() error {
+		entries, err := load(townRoot)
+		if err != nil {
+			return err
+		}
+		return save(townRoot, append(entries, e))
+	})
+}
+
+// List returns the entries, soonest first.
+func List(townRoot string) ([]*Entry, error) {
+	var entries []*Entry
+	err := withLock(townRoot, func() error {
+		var err error
+		entries, err = load(townRoot)
+		return err
+	})
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Next.Before(entries[j].Next) })
+	return entries, err
+}
+
+// Cancel removes an entry and returns it.
+func Cancel(townRoot, id string) (*Entry, error) {
+	var removed *Entry
+	err := withLock(townRoot, func() error {
+		entries, err := load(townRoot)
+		if err != nil {
+			return err
+		}
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.ID == id {
+				removed = e
+				continue
+			}
+			kept = append(kept, e)
+		}
+		if removed == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return save(townRoot, kept)
+	})
+	return removed, err
+}
+
+// Deliverer delivers one occurrence of an entry.
+type Deliverer func(e *Entry) error
+
+// Fired reports what a run did with a due entry.
+type Fired struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Target string `json:"target"`
+	Error  string `json:"error,omitempty"`
+
+	// Interrupted is set when an earlier run died while delivering the
+	// occurrence; it is not delivered again.
+	Interrupted bool `json:"interrupted,omitempty"`
+
+	// Dropped is set when the occurrence failed MaxAttempts runs in a row
+	// and was given up.
+	Dropped bool `json:"dropped,omitempty"`
+}
+
+// Run delivers the entries due at now. Each occurrence is marked as firing
+// and saved before deliver is called, then advanced and saved again, so a
+// run that dies mid-delivery cannot deliver it a second time. Failed
+// deliveries are retried on later runs.
+func Run(townRoot string, now time.Time, deliver Deliverer) ([]Fired, error) {
+	var fired []Fired
+	err := withLock(townRoot, func() error {
+		entries, err := load(townRoot)
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Next.Before(entries[j].Next) })
+
+		for _, e := range entries {
+			if e.Next.After(now) {
+				continue
+			}
+			f := Fired{ID: e.ID, Kind: e.Kind, Target: e.Target()}
+
+			if e.Firing != nil {
+				f.Interrupted = true
+				e.LastError = fmt.Sprintf("interrupted while delivering at %s; not repeated", e.Firing.Format(time.RFC3339))
+				e.Fired++
+				e.LastFired = *e.Firing
+				advance(e, now)
+			} else {
+				started := now
+				e.Firing = &started
+				if err := save(townRoot, entries); err != nil {
+					return err
+				}
+				if err := deliver(e); err != nil {
+					f.Error = err.Error()
+					e.LastError = err.Error()
+					e.Attempts++
+					e.Firing = nil
+					if e.Attempts >= MaxAttempts {
+						f.Dropped = true
+						advance(e, now)
+					}
+				} else {
+					e.LastError = ""
+					e.Fired++
+					e.LastFired = now
+					advance(e, now)
+				}
+			}
+			fired = append(fired, f)
+			entries = compact(entries)
+			if err := save(townRoot, entries); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return fired, err
+}
+
+// advance moves an entry past its current occurrence. One-shot entries
+// are marked done (zero Next) and removed by compact.
+func advance(e *Entry, now time.Time) {
+	e.Firing = nil
+	e.Attempts = 0
+	if e.Cron == "" {
+		e.Next = time.Time{}
+		return
+	}
+	c, err := ParseCron(e.Cron)
+	if err != nil {
+		e.Next = time.Time{}
+		return
+	}
+	e.Next = c.Next(now)
+}
+
+// compact drops entries that will not fire again.
+func compact(entries []*Entry) []*Entry {
+	kept := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		if !e.Next.IsZero() {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+func newID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b) // crypto/rand.Read only fails on broken system
+	return "sch-" + hex.EncodeToString(b)
+}
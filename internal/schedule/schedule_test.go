@@ -0,0 +1,166 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+func TestAdd_Validates(t *testing.T) {
+	town := t.TempDir()
+	if err := Add(town, &Entry{Next: time.Now()}); err == nil {
+		t.Error("Add without payload succeeded")
+	}
+	if err := Add(town, &Entry{Nudge: &Nudge{Target: "mayor"}}); err == nil {
+		t.Error("Add without time succeeded")
+	}
+	if err := Add(town, &Entry{Nudge: &Nudge{Target: "mayor"}, Cron: "0 0 30 2 *"}); err == nil {
+		t.Error("Add with a cron that never fires succeeded")
+	}
+
+	e := &Entry{Nudge: &Nudge{Target: "mayor"}, Cron: "0 9 * * *"}
+	if err := Add(town, e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Kind != KindNudge || e.ID == "" || e.Next.IsZero() {
+		t.Errorf("Add left entry %+v, want kind, ID and first occurrence set", e)
+	}
+}
+
+func TestRun_DeliversOnceAndAdvances(t *testing.T) {
+	town := t.TempDir()
+	now := time.Date(2026, 10, 16, 9, 0, 30, 0, time.Local)
+
+	once := &Entry{Mail: &mail.Message{To: "gastown/witness", Subject: "Re-check nux"}, Next: now.Add(-time.Hour)}
+	daily := &Entry{Nudge: &Nudge{Target: "mayor", Message: "standup"}, Cron: "0 9 * * *", Next: now.Add(-30 * time.Second)}
+	later := &Entry{Nudge: &Nudge{Target: "mayor", Message: "later"}, Next: now.Add(time.Hour)}
+	for _, e := range []*Entry{once, daily, later} {
+		if err := Add(town, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var delivered []string
+	deliver := func(e *Entry) error {
+		delivered = append(delivered, e.Summary())
+		return nil
+	}
+	fired, err := Run(town, now, deliver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fired) != 2 || len(delivered) != 2 || delivered[0] != "Re-check nux" || delivered[1] != "standup" {
+		t.Fatalf("first run delivered %v (%+v), want the overdue mail then the standup", delivered, fired)
+	}
+
+	// A second run at the same time delivers nothing again
+	delivered = nil
+	if _, err := Run(town, now, deliver); err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 0 {
+		t.Errorf("second run delivered %v, want nothing", delivered)
+	}
+
+	entries, _ := List(town)
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want the one-shot removed", len(entries))
+	}
+	var got *Entry
+	for _, e := range entries {
+		if e.ID == daily.ID {
+			got = e
+		}
+	}
+	if got == nil || got.Fired != 1 || !got.Next.Equal(time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)) {
+		t.Errorf("daily = %+v, want fired once and next tomorrow 09:00", got)
+	}
+}
+
+func TestRun_KeepsNudgeForce(t *testing.T) {
+	town := t.TempDir()
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+	if err := Add(town, &Entry{Nudge: &Nudge{Target: "mayor", Message: "wake up", Force: true}, Next: now.Add(-time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	var forced bool
+	if _, err := Run(town, now, func(e *Entry) error {
+		forced = e.Nudge.Force
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !forced {
+		t.Error("delivered nudge lost its force flag, want DND overridden when it fires")
+	}
+}
+
+func TestRun_RetriesThenDrops(t *testing.T) {
+	town := t.TempDir()
+	now := time.Now()
+	e := &Entry{Nudge: &Nudge{Target: "gastown/nux", Message: "hi"}, Next: now.Add(-time.Minute)}
+	if err := Add(town, e); err != nil {
+		t.Fatal(err)
+	}
+	fail := func(*Entry) error { return errors.New("no session") }
+	for i := 1; i <= MaxAttempts; i++ {
+		fired, err := Run(town, now, fail)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fired) != 1 || fired[0].Error == "" || fired[0].Dropped != (i == MaxAttempts) {
+			t.Fatalf("run %d = %+v", i, fired)
+		}
+	}
+	if entries, _ := List(town); len(entries) != 0 {
+		t.Errorf("entries = %+v, want the failing entry dropped", entries)
+	}
+}
+
+func TestRun_InterruptedIsNotRepeated(t *testing.T) {
+	town := t.TempDir()
+	now := time.Now()
+	e := &Entry{Mail: &mail.Message{To: "mayor/", Subject: "hi"}, Next: now.Add(-time.Minute)}
+	if err := Add(town, e); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a run that died after marking the entry
+	entries, _ := load(town)
+	started := now.Add(-30 * time.Second)
+	entries[0].Firing = &started
+	if err := save(town, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	fired, err := Run(town, now, func(*Entry) error {
+		t.Error("interrupted occurrence delivered again")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fired) != 1 || !fired[0].Interrupted {
+		t.Errorf("fired = %+v, want the occurrence reported interrupted", fired)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	town := t.TempDir()
+	e := &Entry{Nudge: &Nudge{Target: "mayor"}, Next: time.Now().Add(time.Hour)}
+	if err := Add(town, e); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Cancel(town, "sch-nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(unknown) = %v, want ErrNotFound", err)
+	}
+	if got, err := Cancel(town, e.ID); err != nil || got.ID != e.ID {
+		t.Errorf("Cancel = %+v, %v", got, err)
+	}
+	if entries, _ := List(town); len(entries) != 0 {
+		t.Errorf("entries after cancel = %d, want 0", len(entries))
+	}
+}
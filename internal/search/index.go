@@ -0,0 +1,374 @@
+// Package search keeps a town-wide full-text index over beads, mail,
+// handoff notes, events and (optionally) agent transcripts, and answers
+// ranked queries over it.
+//
+// The index is a single gzip-compressed JSON file under
+// .runtime/search/, holding every document's text and an inverted index
+// from terms to the documents that use them. Update brings it up to date
+// incrementally: new events are read from the event stream since the last
+// update, and the beads databases those events touched (a sling names its
+// bead, mail lives in town beads) are listed again and diffed against
+// what is indexed. Transcripts are append-only files read from where the
+// last update stopped.
+package search
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Version is the index format version. Indexes written by another version
+// are rebuilt.
+const Version = 1
+
+// Sources a document can come from.
+const (
+	SourceBead       = "bead"
+	SourceMail       = "mail"
+	SourceHandoff    = "handoff"
+	SourceEvent      = "event"
+	SourceTranscript = "transcript"
+)
+
+// Sources lists every source, in display order.
+var Sources = []string{SourceBead, SourceMail, SourceHandoff, SourceEvent, SourceTranscript}
+
+// titleWeight is how many body occurrences a title occurrence counts as.
+const titleWeight = 3
+
+// Doc is one searchable document.
+type Doc struct {
+	ID      string    `json:"id"`
+	Source  string    `json:"source"`
+	Rig     string    `json:"rig,omitempty"`    // empty for town-level documents
+	Actors  []string  `json:"actors,omitempty"` // author, assignee, sender, recipient
+	Time    time.Time `json:"time"`
+	Title   string    `json:"title,omitempty"`
+	Text    string    `json:"text,omitempty"`
+	Ref     string    `json:"ref,omitempty"` // bead ID, event type or transcript file
+	Group   string    `json:"group"`         // the unit the document is refreshed with
+	Version string    `json:"version,omitempty"`
+	Len     int       `json:"len"` // weighted term count
+}
+
+// Postings are the documents holding a term, as alternating document
+// numbers and weights (title occurrences count titleWeight times).
+type Postings []int
+
+// Index is the inverted index and the documents it covers.
+type Index struct {
+	Version int                 `json:"version"`
+	Docs    []*Doc              `json:"docs"` // by number; nil once removed
+	Terms   map[string]Postings `json:"terms"`
+	Cursor  Cursor              `json:"cursor"`
+
+	byID     map[string]int
+	live     int
+	totalLen int
+}
+
+// Cursor records how far each source has been indexed.
+type Cursor struct {
+	Events      time.Time              `json:"events,omitempty"`      // newest event indexed
+	Beads       map[string]*BeadsState `json:"beads,omitempty"`       // by beads dir, relative to the town root
+	Transcripts map[string]*FileState  `json:"transcripts,omitempty"` // by transcript path
+	Built       time.Time              `json:"built"`
+	Updated     time.Time              `json:"updated"`
+}
+
+// BeadsState is the last refresh of one beads database.
+type BeadsState struct {
+	Rig       string    `json:"rig,omitempty"`
+	Stamp     time.Time `json:"stamp"` // newest modification time in the beads dir
+	Refreshed time.Time `json:"refreshed"`
+}
+
+// FileState is how much of an append-only file has been indexed.
+type FileState struct {
+	Offset int64 `json:"offset"`
+	Line   int   `json:"line"`
+}
+
+// Dir returns the search directory in a town.
+func Dir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "search")
+}
+
+// Path returns the index file of a town.
+func Path(townRoot string) string {
+	return filepath.Join(Dir(townRoot), "index.json.gz")
+}
+
+// New returns an empty index.
+func New() *Index {
+	idx := &Index{Version: Version, Terms: make(map[string]Postings)}
+	idx.init()
+	return idx
+}
+
+// Load reads a town's index. A missing index, or one in another format
+// version, is returned empty.
+func Load(townRoot string) (*Index, error) {
+	f, err := os.Open(Path(townRoot))
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading search index: %w", err)
+	}
+	defer zr.Close()
+
+	var idx Index
+	if err := json.NewDecoder(zr).Decode(&idx); err != nil {
+		return nil, fmt.Errorf("reading search index: %w", err)
+	}
+	if idx.Version != Version {
+		return New(), nil
+	}
+	if idx.Terms == nil {
+		idx.Terms = make(map[string]Postings)
+	}
+	idx.init()
+	return &idx, nil
+}
+
+// Save writes the index, compacting away removed documents first when they
+// make up most of it.
+func (idx *Index) Save(townRoot string) error {
+	if removed := len(idx.Docs) - idx.live; removed > idx.live {
+		idx.compact()
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(idx); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(Dir(townRoot), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(Path(townRoot), buf.Bytes(), 0644)
+}
+
+// init rebuilds the in-memory lookups after loading.
+func (idx *Index) init() {
+	idx.byID = make(map[string]int, len(idx.Docs))
+	idx.live, idx.totalLen = 0, 0
+	for n, d := range idx.Docs {
+		if d == nil {
+			continue
+		}
+		idx.byID[d.ID] = n
+		idx.live++
+		idx.totalLen += d.Len
+	}
+}
+
+// Len returns the number of documents.
+func (idx *Index) Len() int {
+	return idx.live
+}
+
+// Get returns the document with an ID, or nil.
+func (idx *Index) Get(id string) *Doc {
+	if n, ok := idx.byID[id]; ok {
+		return idx.Docs[n]
+	}
+	return nil
+}
+
+// Put adds a document, replacing any with the same ID. It reports false
+// when an identical version is already indexed.
+func (idx *Index) Put(d *Doc) bool {
+	if old := idx.Get(d.ID); old != nil {
+		if old.Version == d.Version && d.Version != "" {
+			return false
+		}
+		idx.Remove(d.ID)
+	}
+	n := len(idx.Docs)
+	weights := termWeights(d)
+	d.Len = 0
+	for term, w := range weights {
+		idx.Terms[term] = append(idx.Terms[term], n, w)
+		d.Len += w
+	}
+	idx.Docs = append(idx.Docs, d)
+	idx.byID[d.ID] = n
+	idx.live++
+	idx.totalLen += d.Len
+	return true
+}
+
+// Remove drops a document. It reports whether the document was indexed.
+func (idx *Index) Remove(id string) bool {
+	n, ok := idx.byID[id]
+	if !ok {
+		return false
+	}
+	d := idx.Docs[n]
+	for term := range termWeights(d) {
+		p := idx.Terms[term]
+		for i := 0; i+1 < len(p); i += 2 {
+			if p[i] == n {
+				p = append(p[:i:i], p[i+2:]...)
+				break
+			}
+		}
+		if len(p) == 0 {
+			delete(idx.Terms, term)
+		} else {
+			idx.Terms[term] = p
+		}
+	}
+	idx.Docs[n] = nil
+	delete(idx.byID, id)
+	idx.live--
+	idx.totalLen -= d.Len
+	return true
+}
+
+// RemoveGroup drops every document in a group and returns how many.
+func (idx *Index) RemoveGroup(group string) int {
+	removed := 0
+	for _, d := range idx.Docs {
+		if d != nil && d.Group == group && idx.Remove(d.ID) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// groupIDs returns the IDs of the documents in a group.
+func (idx *Index) groupIDs(group string) map[string]bool {
+	ids := make(map[string]bool)
+	for _, d := range idx.Docs {
+		if d != nil && d.Group == group {
+			ids[d.ID] = true
+		}
+	}
+	return ids
+}
+
+// compact renumbers the live documents and rebuilds the postings.
+func (idx *Index) compact() {
+	docs := idx.Docs
+	idx.Docs = nil
+	idx.Terms = make(map[string]Postings)
+	idx.init()
+	for _, d := range docs {
+		if d != nil {
+			idx.Put(d)
+		}
+	}
+}
+
+// Stats summarizes an index.
+type Stats struct {
+	Docs     int            `json:"docs"`
+	Terms    int            `json:"terms"`
+	BySource map[string]int `json:"by_source"`
+	Bytes    int64          `json:"bytes"`
+	Built    time.Time      `json:"built"`
+	Updated  time.Time      `json:"updated"`
+}
+
+// Stats returns the index's size by source. Bytes is the size of the index
+// file in townRoot, if any.
+func (idx *Index) Stats(townRoot string) *Stats {
+	s := &Stats{Docs: idx.live, Terms: len(idx.Terms), BySource: make(map[string]int),
+		Built: idx.Cursor.Built, Updated: idx.Cursor.Updated}
+	for _, d := range idx.Docs {
+		if d != nil {
+			s.BySource[d.Source]++
+		}
+	}
+	if info, err := os.Stat(Path(townRoot)); err == nil {
+		s.Bytes = info.Size()
+	}
+	return s
+}
+
+// termWeights returns a document's terms and their weights.
+func termWeights(d *Doc) map[string]int {
+	weights := make(map[string]int)
+	for _, t := range Tokenize(d.Title) {
+		weights[t] += titleWeight
+	}
+	for _, t := range Tokenize(d.Text) {
+		weights[t]++
+	}
+	return weights
+}
+
+// maxTermLen bounds indexed terms; longer runs (hashes, base64) are cut.
+const maxTermLen = 40
+
+// Tokenize splits text into lowercase terms: runs of letters and digits.
+func Tokenize(text string) []string {
+	var terms []string
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		term := strings.ToLower(text[start:end])
+		if len(term) > maxTermLen {
+			if r := []rune(term); len(r) > maxTermLen {
+				term = string(r[:maxTermLen])
+			}
+		}
+		terms = append(terms, term)
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+		} else {
+			flush(i)
+		}
+	}
+	flush(len(text))
+	return terms
+}
+
+// readLines reads a file from offset up to its last newline, leaving a
+// partly written last line for the next read.
+func readLines(path string, offset int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		return data[:i+1], nil
+	}
+	return nil, nil
+}
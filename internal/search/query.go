@@ -0,0 +1,365 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Query is a search. Zero fields do not filter.
+type Query struct {
+	// Text holds the words to find. Every word must match; "quoted
+	// phrases" must appear as written and a trailing * matches a prefix
+	// ("refact*").
+	Text string
+
+	Sources []string  // bead, mail, handoff, event, transcript
+	Rigs    []string  // rig names; "town" selects town-level documents
+	Actors  []string  // globs over authors, assignees, senders and recipients
+	Since   time.Time // inclusive
+	Until   time.Time // inclusive
+	Limit   int       // 0 for no limit
+}
+
+// Result is one matching document.
+type Result struct {
+	*Doc
+	Score   float64  `json:"score"`
+	Snippet string   `json:"snippet"`
+	Terms   []string `json:"-"` // index terms that matched, for highlighting
+}
+
+// clause is one required part of a query: a term, a prefix, or a phrase.
+type clause struct {
+	terms  []string // a phrase's terms, or the one term
+	prefix bool
+	phrase bool
+}
+
+// parseQuery splits query text into clauses. Words that tokenize into
+// several terms ("gt-abc", "auth/refactor") are matched as phrases.
+func parseQuery(text string) []clause {
+	var clauses []clause
+	for _, word := range splitQuoted(text) {
+		prefix := !word.quoted && strings.HasSuffix(word.text, "*")
+		terms := Tokenize(strings.TrimSuffix(word.text, "*"))
+		switch {
+		case len(terms) == 0:
+			continue
+		case len(terms) == 1:
+			clauses = append(clauses, clause{terms: terms, prefix: prefix})
+		default:
+			clauses = append(clauses, clause{terms: terms, phrase: true})
+		}
+	}
+	return clauses
+}
+
+type queryWord struct {
+	text   string
+	quoted bool
+}
+
+// splitQuoted splits on whitespace, keeping "quoted phrases" together.
+func splitQuoted(text string) []queryWord {
+	var words []queryWord
+	for {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return words
+		}
+		if text[0] == '"' {
+			end := strings.IndexByte(text[1:], '"')
+			if end < 0 {
+				words = append(words, queryWord{text: text[1:], quoted: true})
+				return words
+			}
+			words = append(words, queryWord{text: text[1 : end+1], quoted: true})
+			text = text[end+2:]
+			continue
+		}
+		end := strings.IndexFunc(text, unicode.IsSpace)
+		if end < 0 {
+			end = len(text)
+		}
+		words = append(words, queryWord{text: text[:end]})
+		text = text[end:]
+	}
+}
+
+// Search returns the documents matching q, best first. Documents are
+// ranked by BM25 over their title and text (title matches count more),
+// with a small boost for recent ones.
+func (idx *Index) Search(q Query, now time.Time) []*Result {
+	clauses := parseQuery(q.Text)
+	if len(clauses) == 0 {
+		return nil
+	}
+
+	// Every clause must match: intersect their documents, summing scores
+	avgLen := 1.0
+	if idx.live > 0 {
+		avgLen = math.Max(float64(idx.totalLen)/float64(idx.live), 1)
+	}
+	var scores map[int]float64
+	matched := make(map[int][]string)
+	for _, c := range clauses {
+		clauseScores := make(map[int]float64)
+		for _, term := range idx.expand(c) {
+			p := idx.Terms[term]
+			idf := math.Log(1 + (float64(idx.live)-float64(len(p)/2)+0.5)/(float64(len(p)/2)+0.5))
+			for i := 0; i+1 < len(p); i += 2 {
+				n, tf := p[i], float64(p[i+1])
+				if scores != nil {
+					if _, ok := scores[n]; !ok {
+						continue
+					}
+				}
+				dl := float64(idx.Docs[n].Len)
+				clauseScores[n] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*dl/avgLen))
+				matched[n] = append(matched[n], term)
+			}
+		}
+		if scores != nil {
+			for n, s := range clauseScores {
+				clauseScores[n] = s + scores[n]
+			}
+		}
+		scores = clauseScores
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	var results []*Result
+	for n, score := range scores {
+		d := idx.Docs[n]
+		if !q.matches(d) || !hasPhrases(d, clauses) {
+			continue
+		}
+		// Up to 25% more for documents from the last week or so
+		ageDays := now.Sub(d.Time).Hours() / 24
+		if ageDays < 0 {
+			ageDays = 0
+		}
+		score *= 1 + 0.25/(1+ageDays/7)
+		results = append(results, &Result{Doc: d, Score: score, Terms: dedupe(matched[n])})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Time.After(results[j].Time)
+	})
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	for _, r := range results {
+		r.Snippet = Snippet(r.Doc, r.Terms)
+	}
+	return results
+}
+
+// expand returns the index terms a clause looks up: its term, every term
+// with its prefix, or a phrase's terms.
+func (idx *Index) expand(c clause) []string {
+	if !c.prefix {
+		return c.terms
+	}
+	var terms []string
+	for term := range idx.Terms {
+		if strings.HasPrefix(term, c.terms[0]) {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// matches applies the query's filters.
+func (q Query) matches(d *Doc) bool {
+	if len(q.Sources) > 0 && !contains(q.Sources, d.Source) {
+		return false
+	}
+	if len(q.Rigs) > 0 {
+		rig := d.Rig
+		if rig == "" {
+			rig = "town"
+		}
+		if !contains(q.Rigs, rig) {
+			return false
+		}
+	}
+	if len(q.Actors) > 0 {
+		found := false
+		for _, actor := range d.Actors {
+			for _, g := range q.Actors {
+				if util.MatchGlob(g, actor) || util.MatchGlob(g, strings.TrimSuffix(actor, "/")) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && d.Time.Before(q.Since) {
+		return false
+	}
+	return q.Until.IsZero() || !d.Time.After(q.Until)
+}
+
+// hasPhrases checks that a document holds each phrase's terms in order.
+func hasPhrases(d *Doc, clauses []clause) bool {
+	var terms []string
+	for _, c := range clauses {
+		if !c.phrase {
+			continue
+		}
+		if terms == nil {
+			terms = append(Tokenize(d.Title), Tokenize(d.Text)...)
+		}
+		if !containsRun(terms, c.terms) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsRun(terms, run []string) bool {
+	for i := 0; i+len(run) <= len(terms); i++ {
+		j := 0
+		for j < len(run) && terms[i+j] == run[j] {
+			j++
+		}
+		if j == len(run) {
+			return true
+		}
+	}
+	return false
+}
+
+// snippetWidth is about how many characters a snippet shows.
+const snippetWidth = 160
+
+// Snippet returns the part of a document's text around its first match,
+// on one line. Documents without text show their title.
+func Snippet(d *Doc, terms []string) string {
+	text := d.Text
+	if strings.TrimSpace(text) == "" {
+		text = d.Title
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	start := 0
+	if pos := firstMatch(text, terms); pos > snippetWidth/3 {
+		start = pos - snippetWidth/3
+		// Start on a word
+		if sp := strings.IndexByte(text[start:], ' '); sp >= 0 && sp < 20 {
+			start += sp + 1
+		}
+		for start < len(text) && !utf8.RuneStart(text[start]) {
+			start++
+		}
+	}
+	end := start + snippetWidth
+	if end >= len(text) {
+		end = len(text)
+	} else {
+		if sp := strings.LastIndexByte(text[start:end], ' '); sp > snippetWidth/2 {
+			end = start + sp
+		}
+		for end > start && !utf8.RuneStart(text[end]) {
+			end--
+		}
+	}
+	snippet := text[start:end]
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// firstMatch returns the byte offset of the first word in text that is one
+// of terms, or -1.
+func firstMatch(text string, terms []string) int {
+	pos := -1
+	eachWord(text, func(start, end int) bool {
+		if contains(terms, strings.ToLower(text[start:end])) {
+			pos = start
+			return false
+		}
+		return true
+	})
+	return pos
+}
+
+// Highlight wraps the words of text that are one of terms with mark.
+func Highlight(text string, terms []string, mark func(string) string) string {
+	var b strings.Builder
+	last := 0
+	eachWord(text, func(start, end int) bool {
+		if contains(terms, strings.ToLower(text[start:end])) {
+			b.WriteString(text[last:start])
+			b.WriteString(mark(text[start:end]))
+			last = end
+		}
+		return true
+	})
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// eachWord calls fn with the byte range of each run of letters and digits,
+// the same runs Tokenize makes terms of.
+func eachWord(text string, fn func(start, end int) bool) {
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && !fn(start, i) {
+			return
+		}
+		start = -1
+	}
+	if start >= 0 {
+		fn(start, len(text))
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	out := list[:0]
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,333 @@
+package search
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+var testNow = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func ids(results []*Result) []string {
+	var out []string
+	for _, r := range results {
+		out = append(out, r.ID)
+	}
+	return out
+}
+
+func testIndex() *Index {
+	idx := New()
+	idx.Put(&Doc{ID: "gt-1", Source: SourceBead, Rig: "gastown", Actors: []string{"gastown/crew/joe"},
+		Time: testNow.Add(-48 * time.Hour), Title: "Auth refactor",
+		Text: "Split the auth middleware out of the router before the refactor lands."})
+	idx.Put(&Doc{ID: "hq-2", Source: SourceMail, Actors: []string{"mayor/", "gastown/crew/joe"},
+		Time: testNow.Add(-time.Hour), Title: "Re: deploy",
+		Text: "The auth refactor is blocked on the session store. Refactoring the cookie code first."})
+	idx.Put(&Doc{ID: "gt-3", Source: SourceBead, Rig: "gastown", Actors: []string{"gastown/polecats/toast"},
+		Time: testNow.Add(-24 * time.Hour), Title: "Flaky merge queue test",
+		Text: "TestMergeQueue fails one run in ten."})
+	idx.Put(&Doc{ID: "event:1", Source: SourceEvent, Rig: "beads", Actors: []string{"beads/polecats/nux"},
+		Time: testNow.Add(-2 * time.Hour), Title: "done", Text: "bead: bd-9\nbranch: polecat/nux-auth"})
+	return idx
+}
+
+func TestSearchRanksAndFilters(t *testing.T) {
+	idx := testIndex()
+
+	got := ids(idx.Search(Query{Text: "auth refactor"}, testNow))
+	if len(got) != 2 || got[0] != "gt-1" {
+		t.Errorf("auth refactor = %v, want gt-1 (title match) first, then hq-2", got)
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+		want string
+	}{
+		{"phrase", Query{Text: `"session store"`}, "hq-2"},
+		{"phrase order", Query{Text: `"store session"`}, ""},
+		{"prefix", Query{Text: "flak*"}, "gt-3"},
+		{"all words required", Query{Text: "auth flaky"}, ""},
+		{"hyphenated word is a phrase", Query{Text: "nux-auth"}, "event:1"},
+		{"source", Query{Text: "auth", Sources: []string{SourceMail}}, "hq-2"},
+		{"rig", Query{Text: "auth", Rigs: []string{"beads"}}, "event:1"},
+		{"town rig", Query{Text: "auth", Rigs: []string{"town"}}, "hq-2"},
+		{"actor glob", Query{Text: "auth", Actors: []string{"beads/polecats/*"}}, "event:1"},
+		{"actor trailing slash", Query{Text: "auth", Actors: []string{"mayor"}}, "hq-2"},
+		{"since", Query{Text: "refactor", Since: testNow.Add(-3 * time.Hour)}, "hq-2"},
+		{"until", Query{Text: "refactor", Until: testNow.Add(-3 * time.Hour)}, "gt-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(ids(idx.Search(tt.q, testNow)), ",")
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnippetAndHighlight(t *testing.T) {
+	d := &Doc{Title: "Notes", Text: strings.Repeat("filler words here ", 30) + "the Auth refactor is done. " + strings.Repeat("more text ", 30)}
+	snippet := Snippet(d, []string{"auth"})
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") {
+		t.Errorf("snippet should be cut on both sides: %q", snippet)
+	}
+	if !strings.Contains(snippet, "Auth refactor") {
+		t.Errorf("snippet should show the match: %q", snippet)
+	}
+	got := Highlight("the Auth refactor, authority", []string{"auth"}, func(s string) string { return "[" + s + "]" })
+	if got != "the [Auth] refactor, authority" {
+		t.Errorf("Highlight = %q", got)
+	}
+}
+
+func TestPutReplacesAndRemoves(t *testing.T) {
+	idx := testIndex()
+	if idx.Put(&Doc{ID: "gt-3", Source: SourceBead, Title: "Flaky merge queue test", Version: "v1"}) != true {
+		t.Fatal("new version should be indexed")
+	}
+	if idx.Put(&Doc{ID: "gt-3", Source: SourceBead, Title: "Flaky merge queue test", Version: "v1"}) {
+		t.Error("same version should not be reindexed")
+	}
+	idx.Put(&Doc{ID: "gt-3", Source: SourceBead, Title: "Stable merge queue test", Version: "v2"})
+	if got := ids(idx.Search(Query{Text: "flaky"}, testNow)); len(got) != 0 {
+		t.Errorf("old text still matches: %v", got)
+	}
+	if !idx.Remove("gt-1") || idx.Len() != 3 {
+		t.Errorf("Remove: len = %d, want 3", idx.Len())
+	}
+	if _, ok := idx.Terms["middleware"]; ok {
+		t.Error("removed document's terms should be dropped")
+	}
+}
+
+func TestSaveLoadCompacts(t *testing.T) {
+	townRoot := t.TempDir()
+	idx := testIndex()
+	idx.Remove("gt-1")
+	idx.Remove("gt-3")
+	idx.Remove("event:1")
+	if err := idx.Save(townRoot); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Docs) != 1 || loaded.Len() != 1 {
+		t.Errorf("loaded %d slots for %d docs, want compacted to 1", len(loaded.Docs), loaded.Len())
+	}
+	if got := ids(loaded.Search(Query{Text: "session"}, testNow)); len(got) != 1 || got[0] != "hq-2" {
+		t.Errorf("search after load = %v", got)
+	}
+}
+
+// fakeBeads stubs listIssues with per-beads-dir issue lists and counts calls.
+type fakeBeads struct {
+	issues map[string][]*beads.Issue // by beads dir base path
+	calls  map[string]int
+}
+
+func (f *fakeBeads) install(t *testing.T) {
+	t.Helper()
+	f.calls = make(map[string]int)
+	orig := listIssues
+	listIssues = func(workDir, beadsDir string) ([]*beads.Issue, error) {
+		f.calls[beadsDir]++
+		return f.issues[beadsDir], nil
+	}
+	t.Cleanup(func() { listIssues = orig })
+}
+
+func setupTown(t *testing.T) (townRoot, townBeads, rigBeads string) {
+	t.Helper()
+	townRoot = t.TempDir()
+	townBeads = filepath.Join(townRoot, ".beads")
+	rigBeads = filepath.Join(townRoot, "gastown", "mayor", "rig", ".beads")
+	for _, dir := range []string{townBeads, rigBeads} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	routes := `{"prefix":"hq-","path":"."}` + "\n" + `{"prefix":"gt-","path":"gastown/mayor/rig"}` + "\n"
+	if err := os.WriteFile(filepath.Join(townBeads, beads.RoutesFileName), []byte(routes), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Keep on-disk changes from forcing refreshes
+	old := testNow.Add(-time.Hour)
+	for _, dir := range []string{townBeads, rigBeads} {
+		_ = os.Chtimes(filepath.Join(dir, beads.RoutesFileName), old, old)
+		_ = os.Chtimes(dir, old, old)
+	}
+	return townRoot, townBeads, rigBeads
+}
+
+func appendEvent(t *testing.T, townRoot string, ts time.Time, typ, actor string, payload map[string]interface{}) {
+	t.Helper()
+	data, _ := json.Marshal(events.Event{Timestamp: ts.UTC().Format(time.RFC3339), Source: "gt",
+		Type: typ, Actor: actor, Payload: payload, Visibility: events.VisibilityFeed})
+	f, err := os.OpenFile(filepath.Join(townRoot, events.EventsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateFollowsEventStream(t *testing.T) {
+	townRoot, townBeads, rigBeads := setupTown(t)
+	fake := &fakeBeads{issues: map[string][]*beads.Issue{
+		townBeads: {
+			{ID: "hq-m1", Type: "message", Title: "Auth refactor plan", Description: "Let's split the middleware.",
+				Assignee: "gastown/crew/joe", Labels: []string{"from:mayor/"}, CreatedAt: "2026-03-09T10:00:00Z", UpdatedAt: "2026-03-09T10:00:00Z"},
+			{ID: "hq-h1", Type: "task", Status: beads.StatusPinned, Title: "mayor Handoff", Description: "Watch the auth refactor.",
+				UpdatedAt: "2026-03-09T11:00:00Z"},
+			{ID: "hq-w1", Type: "task", Ephemeral: true, Title: "patrol step auth"},
+		},
+		rigBeads: {
+			{ID: "gt-1", Type: "task", Status: "open", Title: "Refactor auth", CreatedBy: "mayor", UpdatedAt: "2026-03-09T09:00:00Z"},
+		},
+	}}
+	fake.install(t)
+	appendEvent(t, townRoot, testNow.Add(-time.Hour), events.TypeSling, "mayor", events.SlingPayload("gt-1", "gastown/polecats/toast"))
+
+	idx, result, err := Update(townRoot, UpdateOptions{Now: testNow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added[SourceMail] != 1 || result.Added[SourceHandoff] != 1 || result.Added[SourceBead] != 1 || result.Added[SourceEvent] != 1 {
+		t.Errorf("first update added %v, want one of each (wisps skipped)", result.Added)
+	}
+	got := strings.Join(ids(idx.Search(Query{Text: "auth", Sources: []string{SourceMail, SourceHandoff, SourceBead}}, testNow)), ",")
+	if !strings.Contains(got, "hq-m1") || !strings.Contains(got, "hq-h1") || !strings.Contains(got, "gt-1") || strings.Contains(got, "hq-w1") {
+		t.Errorf("search = %s", got)
+	}
+
+	// Nothing new: no beads database is listed again
+	_, result, err = Update(townRoot, UpdateOptions{Now: testNow.Add(time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Changed() || len(result.Refreshed) != 0 {
+		t.Errorf("idle update changed %v, refreshed %v", result.Added, result.Refreshed)
+	}
+
+	// A done event for gt-1 refreshes the rig's beads only
+	fake.issues[rigBeads] = []*beads.Issue{
+		{ID: "gt-1", Type: "task", Status: "closed", Title: "Refactor auth", Description: "Landed in the session store PR.",
+			UpdatedAt: "2026-03-10T11:59:00Z"},
+		{ID: "gt-2", Type: "bug", Status: "open", Title: "Session store leaks", UpdatedAt: "2026-03-10T11:59:00Z"},
+	}
+	delete(fake.issues, townBeads)
+	appendEvent(t, townRoot, testNow.Add(time.Minute), events.TypeDone, "gastown/polecats/toast", events.DonePayload("gt-1", "polecat/toast"))
+	idx, result, err = Update(townRoot, UpdateOptions{Now: testNow.Add(2 * time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Refreshed) != 1 || result.Refreshed[0] != filepath.Join("gastown", "mayor", "rig", ".beads") {
+		t.Errorf("refreshed %v, want the rig's beads only", result.Refreshed)
+	}
+	if got := ids(idx.Search(Query{Text: "session store", Sources: []string{SourceBead}}, testNow)); len(got) != 2 {
+		t.Errorf("updated beads = %v, want gt-1 and gt-2", got)
+	}
+
+	// Past BeadsMaxAge town beads are listed again; the mail was deleted
+	_, result, err = Update(townRoot, UpdateOptions{Now: testNow.Add(BeadsMaxAge + time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Removed != 2 {
+		t.Errorf("removed %d, want the deleted mail and handoff", result.Removed)
+	}
+	loaded, err := Load(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Get("hq-m1") != nil || loaded.Get("gt-2") == nil {
+		t.Error("saved index out of date")
+	}
+}
+
+func TestUpdateTranscripts(t *testing.T) {
+	townRoot, _, _ := setupTown(t)
+	(&fakeBeads{}).install(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	projectDir := filepath.Join(home, ".claude", "projects", strings.ReplaceAll(filepath.Join(townRoot, "gastown", "crew", "joe"), "/", "-"))
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cwd := filepath.Join(townRoot, "gastown", "crew", "joe")
+	line := func(typ string, content interface{}) string {
+		data, _ := json.Marshal(map[string]interface{}{
+			"type": typ, "timestamp": "2026-03-10T10:00:00Z", "cwd": cwd,
+			"message": map[string]interface{}{"role": typ, "content": content},
+		})
+		return string(data) + "\n"
+	}
+	transcript := filepath.Join(projectDir, "session.jsonl")
+	content := line("user", "Why is the oauth callback failing?") +
+		line("assistant", []map[string]string{{"type": "text", "text": "The oauth state cookie expires."}, {"type": "tool_use"}})
+	if err := os.WriteFile(transcript, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, result, err := Update(townRoot, UpdateOptions{Now: testNow, Transcripts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added[SourceTranscript] != 2 {
+		t.Fatalf("added %v, want 2 transcript messages", result.Added)
+	}
+	r := idx.Search(Query{Text: "oauth", Actors: []string{"gastown/crew/joe"}}, testNow)
+	if len(r) != 2 || r[0].Rig != "gastown" {
+		t.Errorf("transcript search = %v", ids(r))
+	}
+
+	// Appended lines are read from the saved offset; a partial line waits
+	f, _ := os.OpenFile(transcript, os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = f.WriteString(line("user", "Try rotating the oauth secret") + `{"type":"user"`)
+	f.Close()
+	_, result, err = Update(townRoot, UpdateOptions{Now: testNow, Transcripts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added[SourceTranscript] != 1 {
+		t.Errorf("incremental update added %v, want 1", result.Added)
+	}
+
+	// Disabling transcripts drops them
+	idx, _, err = Update(townRoot, UpdateOptions{Now: testNow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := idx.Search(Query{Text: "oauth"}, testNow); len(got) != 0 {
+		t.Errorf("transcripts still indexed: %v", ids(got))
+	}
+}
+
+func TestAgentForPath(t *testing.T) {
+	tests := []struct{ rel, rig, actor string }{
+		{"gastown/crew/joe/src", "gastown", "gastown/crew/joe"},
+		{"gastown/polecats/toast", "gastown", "gastown/polecats/toast"},
+		{"gastown/witness", "gastown", "gastown/witness"},
+		{"mayor", "", "mayor"},
+		{"gastown/mayor/rig", "gastown", ""},
+	}
+	for _, tt := range tests {
+		if rig, actor := agentForPath(tt.rel); rig != tt.rig || actor != tt.actor {
+			t.Errorf("agentForPath(%q) = %q, %q; want %q, %q", tt.rel, rig, actor, tt.rig, tt.actor)
+		}
+	}
+}
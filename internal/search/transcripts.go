@@ -0,0 +1,200 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// maxTranscriptText bounds the text indexed per transcript message; tool
+// output pasted into a message can run to megabytes.
+const maxTranscriptText = 4000
+
+// transcriptLine is the part of a Claude Code transcript line indexed.
+type transcriptLine struct {
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp"`
+	CWD       string `json:"cwd"`
+	IsMeta    bool   `json:"isMeta"`
+	Message   *struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+func transcriptGroup(path string) string {
+	return "transcript:" + path
+}
+
+// nonAlnum is what Claude Code replaces with "-" in project directory names.
+var nonAlnum = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// transcriptFiles returns the Claude Code transcripts of sessions run in
+// the town: ~/.claude/projects and each account's config dir.
+func transcriptFiles(townRoot string) []string {
+	var roots []string
+	if home, err := os.UserHomeDir(); err == nil {
+		roots = append(roots, filepath.Join(home, ".claude", "projects"))
+	}
+	if accounts, err := config.LoadAccountsConfig(constants.MayorAccountsPath(townRoot)); err == nil {
+		for _, acct := range accounts.Accounts {
+			if acct.ConfigDir != "" {
+				roots = append(roots, filepath.Join(acct.ConfigDir, "projects"))
+			}
+		}
+	}
+
+	prefix := nonAlnum.ReplaceAllString(townRoot, "-")
+	var files []string
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		dirs, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		for _, dir := range dirs {
+			name := nonAlnum.ReplaceAllString(dir.Name(), "-")
+			if !dir.IsDir() || (name != prefix && !strings.HasPrefix(name, prefix+"-")) {
+				continue
+			}
+			matches, _ := filepath.Glob(filepath.Join(root, dir.Name(), "*.jsonl"))
+			for _, m := range matches {
+				if !seen[m] {
+					seen[m] = true
+					files = append(files, m)
+				}
+			}
+		}
+	}
+	return files
+}
+
+// updateTranscripts indexes transcript lines written since the last update.
+// A transcript that shrank was rewritten and is indexed again from the start.
+func (idx *Index) updateTranscripts(townRoot string, result *UpdateResult) {
+	if idx.Cursor.Transcripts == nil {
+		idx.Cursor.Transcripts = make(map[string]*FileState)
+	}
+	current := make(map[string]bool)
+	for _, path := range transcriptFiles(townRoot) {
+		current[path] = true
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		state := idx.Cursor.Transcripts[path]
+		if state == nil || info.Size() < state.Offset {
+			result.Removed += idx.RemoveGroup(transcriptGroup(path))
+			state = &FileState{}
+			idx.Cursor.Transcripts[path] = state
+		}
+		if info.Size() == state.Offset {
+			continue
+		}
+		data, err := readLines(path, state.Offset)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		state.Offset += int64(len(data))
+		for _, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+			state.Line++
+			if d := transcriptDoc(townRoot, path, state.Line, line); d != nil {
+				idx.Put(d)
+				result.added(SourceTranscript)
+			}
+		}
+	}
+	for path := range idx.Cursor.Transcripts {
+		if !current[path] {
+			result.Removed += idx.RemoveGroup(transcriptGroup(path))
+			delete(idx.Cursor.Transcripts, path)
+		}
+	}
+}
+
+// transcriptDoc turns a user or assistant message into a document, or
+// returns nil for other lines and messages without text.
+func transcriptDoc(townRoot, path string, lineNo int, line []byte) *Doc {
+	var tl transcriptLine
+	if err := json.Unmarshal(line, &tl); err != nil || tl.Message == nil || tl.IsMeta {
+		return nil
+	}
+	if tl.Type != "user" && tl.Type != "assistant" {
+		return nil
+	}
+	text := strings.TrimSpace(messageText(tl.Message.Content))
+	if text == "" {
+		return nil
+	}
+	if len(text) > maxTranscriptText {
+		text = strings.ToValidUTF8(text[:maxTranscriptText], "")
+	}
+
+	rig, actor := "", ""
+	if rel, err := filepath.Rel(townRoot, tl.CWD); err == nil && tl.CWD != "" && !strings.HasPrefix(rel, "..") {
+		rig, actor = agentForPath(filepath.ToSlash(rel))
+	}
+	d := &Doc{
+		ID:     fmt.Sprintf("transcript:%s:%d", path, lineNo),
+		Source: SourceTranscript,
+		Rig:    rig,
+		Time:   parseTime(tl.Timestamp),
+		Title:  tl.Type,
+		Text:   text,
+		Ref:    fmt.Sprintf("%s:%d", path, lineNo),
+		Group:  transcriptGroup(path),
+	}
+	if actor != "" {
+		d.Actors = []string{actor}
+	}
+	return d
+}
+
+// messageText returns the text of a message's content: a string, or the
+// text blocks of a block list (tool calls and results are skipped).
+func messageText(content json.RawMessage) string {
+	var s string
+	if json.Unmarshal(content, &s) == nil {
+		return s
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(content, &blocks) != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Type == "text" && b.Text != "" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// agentForPath returns the rig and agent address a town-relative working
+// directory belongs to: "gastown/crew/joe/src" is gastown/crew/joe.
+func agentForPath(rel string) (rig, actor string) {
+	parts := strings.Split(rel, "/")
+	switch {
+	case parts[0] == "." || parts[0] == "":
+		return "", ""
+	case parts[0] == "mayor" || parts[0] == "deacon":
+		return "", parts[0]
+	case len(parts) >= 3 && (parts[1] == "crew" || parts[1] == "polecats"):
+		return parts[0], strings.Join(parts[:3], "/")
+	case len(parts) >= 2 && (parts[1] == "witness" || parts[1] == "refinery"):
+		return parts[0], parts[0] + "/" + parts[1]
+	default:
+		return parts[0], ""
+	}
+}
@@ -0,0 +1,389 @@
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/eventstore"
+)
+
+// BeadsMaxAge is how long a beads database goes without being listed again
+// when no event points at it. It catches changes made with bd directly,
+// which write no Gas Town events.
+const BeadsMaxAge = 15 * time.Minute
+
+// eventSlack re-reads events this far before the cursor, since processes
+// append events with slightly out-of-order timestamps. Events already
+// indexed are skipped by ID.
+const eventSlack = time.Minute
+
+// UpdateOptions controls an index update.
+type UpdateOptions struct {
+	Rebuild     bool // start from an empty index
+	Transcripts bool // index agent transcripts; false drops any indexed
+	Now         time.Time
+}
+
+// UpdateResult describes what an update changed.
+type UpdateResult struct {
+	Added     map[string]int `json:"added,omitempty"` // documents added or changed, by source
+	Removed   int            `json:"removed"`
+	Refreshed []string       `json:"refreshed,omitempty"` // beads dirs listed again
+	Errors    []string       `json:"errors,omitempty"`    // sources that could not be read
+}
+
+// Changed reports whether the update changed the index.
+func (r *UpdateResult) Changed() bool {
+	return len(r.Added) > 0 || r.Removed > 0
+}
+
+func (r *UpdateResult) added(source string) {
+	if r.Added == nil {
+		r.Added = make(map[string]int)
+	}
+	r.Added[source]++
+}
+
+// listIssues lists every issue in a beads database. Tests replace it.
+var listIssues = func(workDir, beadsDir string) ([]*beads.Issue, error) {
+	return beads.NewWithBeadsDir(workDir, beadsDir).List(beads.ListOptions{Status: "all", Priority: -1, Limit: -1})
+}
+
+// Update brings a town's index up to date and saves it. Concurrent updates
+// are serialized; readers see either the old or the new index.
+func Update(townRoot string, opts UpdateOptions) (*Index, *UpdateResult, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if err := os.MkdirAll(Dir(townRoot), 0755); err != nil {
+		return nil, nil, err
+	}
+	lock := flock.New(filepath.Join(Dir(townRoot), "index.lock"))
+	if err := lock.Lock(); err != nil {
+		return nil, nil, fmt.Errorf("locking search index: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	idx := New()
+	if !opts.Rebuild {
+		loaded, err := Load(townRoot)
+		if err != nil {
+			return nil, nil, err
+		}
+		idx = loaded
+	}
+	if idx.Cursor.Built.IsZero() {
+		idx.Cursor.Built = opts.Now
+	}
+
+	result := &UpdateResult{}
+	dirty, err := idx.updateEvents(townRoot, result)
+	if err != nil {
+		return nil, nil, err
+	}
+	idx.updateBeads(townRoot, dirty, opts.Now, result)
+	if opts.Transcripts {
+		idx.updateTranscripts(townRoot, result)
+	} else if len(idx.Cursor.Transcripts) > 0 {
+		for path := range idx.Cursor.Transcripts {
+			result.Removed += idx.RemoveGroup(transcriptGroup(path))
+		}
+		idx.Cursor.Transcripts = nil
+	}
+
+	if result.Changed() || opts.Rebuild || idx.Cursor.Updated.IsZero() || len(result.Refreshed) > 0 {
+		idx.Cursor.Updated = opts.Now
+		if err := idx.Save(townRoot); err != nil {
+			return nil, nil, fmt.Errorf("saving search index: %w", err)
+		}
+	}
+	return idx, result, nil
+}
+
+// eventsGroup holds every event document.
+const eventsGroup = "events"
+
+// updateEvents indexes events newer than the cursor and returns the beads
+// dirs (by prefix, "" for town beads) they point at.
+func (idx *Index) updateEvents(townRoot string, result *UpdateResult) (map[string]bool, error) {
+	q := eventstore.Query{Archived: idx.Cursor.Events.IsZero()}
+	if !idx.Cursor.Events.IsZero() {
+		q.Since = idx.Cursor.Events.Add(-eventSlack)
+	}
+	dirty := make(map[string]bool)
+	newest := idx.Cursor.Events
+	err := eventstore.Scan(townRoot, q, func(e *events.Event) bool {
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			return true
+		}
+		d := eventDoc(e, ts)
+		if idx.Get(d.ID) != nil {
+			return true
+		}
+		idx.Put(d)
+		result.added(SourceEvent)
+		if ts.After(newest) {
+			newest = ts
+		}
+		if prefix, ok := eventBeads(e); ok {
+			dirty[prefix] = true
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading events: %w", err)
+	}
+	idx.Cursor.Events = newest
+	return dirty, nil
+}
+
+// eventDoc turns an event into a document. Its ID hashes the event, so the
+// same event read twice is indexed once.
+func eventDoc(e *events.Event, ts time.Time) *Doc {
+	payload, _ := json.Marshal(e.Payload)
+	sum := sha256.Sum256([]byte(e.Timestamp + "\x00" + e.Type + "\x00" + e.Actor + "\x00" + string(payload)))
+
+	keys := make([]string, 0, len(e.Payload))
+	for k := range e.Payload {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var lines []string
+	for _, k := range keys {
+		lines = append(lines, k+": "+payloadText(e.Payload[k]))
+	}
+
+	rig := ""
+	if r, ok := e.Payload["rig"].(string); ok {
+		rig = r
+	} else if i := strings.Index(e.Actor, "/"); i > 0 {
+		rig = e.Actor[:i]
+	}
+	return &Doc{
+		ID:     "event:" + hex.EncodeToString(sum[:8]),
+		Source: SourceEvent,
+		Rig:    rig,
+		Actors: []string{e.Actor},
+		Time:   ts,
+		Title:  e.Type,
+		Text:   strings.Join(lines, "\n"),
+		Ref:    e.Type,
+		Group:  eventsGroup,
+	}
+}
+
+// payloadText renders a payload value for indexing.
+func payloadText(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// eventBeads returns the beads prefix an event changed: the prefix of the
+// bead it names, or "" (town beads) for mail, handoffs and escalations.
+func eventBeads(e *events.Event) (string, bool) {
+	for _, key := range []string{"bead", "issue", "mr"} {
+		if id, ok := e.Payload[key].(string); ok && id != "" {
+			return beads.ExtractPrefix(id), true
+		}
+	}
+	switch e.Type {
+	case events.TypeMail, events.TypeHandoff, events.TypeEscalationSent,
+		events.TypeEscalationAcked, events.TypeEscalationClosed, events.TypeInbound,
+		events.TypeMailReceipt:
+		return "", true
+	}
+	return "", false
+}
+
+// beadsSource is one beads database in the town.
+type beadsSource struct {
+	rel     string // beads dir relative to the town root
+	dir     string
+	workDir string
+	rig     string
+	prefix  string
+}
+
+// beadsSources lists the town's beads databases from its routes, town
+// beads first.
+func beadsSources(townRoot string) []beadsSource {
+	townBeads := beads.GetTownBeadsPath(townRoot)
+	sources := []beadsSource{{rel: ".beads", dir: townBeads, workDir: townRoot, prefix: ""}}
+	seen := map[string]bool{townBeads: true}
+	routes, _ := beads.LoadRoutes(townBeads)
+	for _, r := range routes {
+		if r.Path == "." {
+			sources[0].prefix = r.Prefix
+			continue
+		}
+		workDir := filepath.Join(townRoot, r.Path)
+		dir := beads.ResolveBeadsDir(workDir)
+		if seen[dir] {
+			continue
+		}
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		seen[dir] = true
+		rel, err := filepath.Rel(townRoot, dir)
+		if err != nil {
+			rel = dir
+		}
+		rig := strings.SplitN(filepath.ToSlash(r.Path), "/", 2)[0]
+		sources = append(sources, beadsSource{rel: rel, dir: dir, workDir: workDir, rig: rig, prefix: r.Prefix})
+	}
+	return sources
+}
+
+// updateBeads lists again the beads databases that events pointed at, that
+// changed on disk, or that have gone BeadsMaxAge without a listing.
+func (idx *Index) updateBeads(townRoot string, dirty map[string]bool, now time.Time, result *UpdateResult) {
+	if idx.Cursor.Beads == nil {
+		idx.Cursor.Beads = make(map[string]*BeadsState)
+	}
+	current := make(map[string]bool)
+	for _, src := range beadsSources(townRoot) {
+		if _, err := os.Stat(src.dir); err != nil {
+			continue
+		}
+		current[src.rel] = true
+		state := idx.Cursor.Beads[src.rel]
+		stamp := newestModTime(src.dir)
+		stale := state == nil || dirty[src.prefix] || (src.rig == "" && dirty[""]) ||
+			stamp.After(state.Stamp) || now.Sub(state.Refreshed) >= BeadsMaxAge
+		if !stale {
+			continue
+		}
+		issues, err := listIssues(src.workDir, src.dir)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", src.rel, err))
+			continue
+		}
+		idx.refreshBeads(src, issues, result)
+		idx.Cursor.Beads[src.rel] = &BeadsState{Rig: src.rig, Stamp: stamp, Refreshed: now}
+		result.Refreshed = append(result.Refreshed, src.rel)
+	}
+	// Beads databases that are gone (a removed rig)
+	for rel := range idx.Cursor.Beads {
+		if !current[rel] {
+			result.Removed += idx.RemoveGroup(beadsGroup(rel))
+			delete(idx.Cursor.Beads, rel)
+		}
+	}
+}
+
+func beadsGroup(rel string) string {
+	return "beads:" + rel
+}
+
+// refreshBeads replaces a beads database's documents with a new listing,
+// reindexing only issues that changed.
+func (idx *Index) refreshBeads(src beadsSource, issues []*beads.Issue, result *UpdateResult) {
+	group := beadsGroup(src.rel)
+	stale := idx.groupIDs(group)
+	for _, issue := range issues {
+		d := issueDoc(issue, src.rig, group)
+		if d == nil {
+			continue
+		}
+		delete(stale, d.ID)
+		if idx.Put(d) {
+			result.added(d.Source)
+		}
+	}
+	for id := range stale {
+		if idx.Remove(id) {
+			result.Removed++
+		}
+	}
+}
+
+// issueDoc turns an issue into a document: mail for messages, a handoff
+// note for handoff beads and handoff mail, a bead otherwise. Ephemeral
+// issues other than mail (patrol wisps) are not indexed.
+func issueDoc(issue *beads.Issue, rig, group string) *Doc {
+	d := &Doc{
+		ID:      issue.ID,
+		Source:  SourceBead,
+		Rig:     rig,
+		Title:   issue.Title,
+		Text:    issue.Description,
+		Ref:     issue.ID,
+		Group:   group,
+		Version: issue.UpdatedAt + "|" + issue.Status + "|" + strings.Join(issue.Labels, ","),
+		Time:    parseTime(issue.UpdatedAt, issue.CreatedAt),
+	}
+	switch {
+	case issue.Type == "message":
+		d.Source = SourceMail
+		if strings.Contains(issue.Title, "HANDOFF") {
+			d.Source = SourceHandoff
+		}
+		d.Time = parseTime(issue.CreatedAt, issue.UpdatedAt)
+		for _, label := range issue.Labels {
+			if from, ok := strings.CutPrefix(label, "from:"); ok {
+				d.Actors = append(d.Actors, from)
+			}
+		}
+	case issue.Ephemeral:
+		return nil
+	case issue.Status == beads.StatusPinned && strings.HasSuffix(issue.Title, " Handoff"):
+		d.Source = SourceHandoff
+		d.Actors = append(d.Actors, strings.TrimSuffix(issue.Title, " Handoff"))
+	default:
+		if issue.CreatedBy != "" {
+			d.Actors = append(d.Actors, issue.CreatedBy)
+		}
+	}
+	if issue.Assignee != "" {
+		d.Actors = append(d.Actors, issue.Assignee)
+	}
+	return d
+}
+
+// parseTime returns the first of values that parses as RFC 3339.
+func parseTime(values ...string) time.Time {
+	for _, v := range values {
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// newestModTime returns the newest modification time of a directory and
+// the entries directly in it.
+func newestModTime(dir string) time.Time {
+	var newest time.Time
+	if info, err := os.Stat(dir); err == nil {
+		newest = info.ModTime()
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return newest
+	}
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest
+}
@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Audit actions.
+const (
+	ActionSet         = "set"
+	ActionRotate      = "rotate"
+	ActionRevoke      = "revoke"
+	ActionMaterialize = "materialize"
+	ActionExport      = "export"
+	ActionScrub       = "scrub"
+)
+
+// AuditEntry is one line of the secrets access log. Values are never logged.
+type AuditEntry struct {
+	Time    time.Time `json:"ts"`
+	Action  string    `json:"action"`
+	Secret  string    `json:"secret"`
+	Scope   string    `json:"scope"`
+	Version int       `json:"version,omitempty"`
+	Agent   string    `json:"agent,omitempty"`  // Recipient, for deliveries
+	Target  string    `json:"target,omitempty"` // File path, for file deliveries
+	Actor   string    `json:"actor,omitempty"`  // Who ran the command
+}
+
+// AuditPath returns the path to a town's secrets audit log.
+func AuditPath(townRoot string) string {
+	return filepath.Join(Dir(townRoot), "audit.jsonl")
+}
+
+// Audit appends an entry to the audit log. Best-effort: failures are
+// returned but callers generally ignore them rather than block a spawn.
+func Audit(townRoot string, entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(Dir(townRoot), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(AuditPath(townRoot), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// auditFor builds an audit entry for a secret.
+func auditFor(action string, sec *Secret) AuditEntry {
+	return AuditEntry{Action: action, Secret: sec.Name, Scope: sec.ScopeLabel(), Version: sec.Version}
+}
+
+// ReadAudit returns audit entries, oldest first. A missing log yields none.
+// Unparseable lines are skipped.
+func ReadAudit(townRoot string) ([]AuditEntry, error) {
+	f, err := os.Open(AuditPath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
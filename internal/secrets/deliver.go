@@ -0,0 +1,303 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Delivery records a secret file written into a worktree.
+type Delivery struct {
+	Secret      string    `json:"secret"`
+	Scope       string    `json:"scope"`
+	Version     int       `json:"version"`
+	Agent       string    `json:"agent"`
+	Worktree    string    `json:"worktree"`
+	Path        string    `json:"path"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// ledger is the set of files currently materialized in worktrees.
+type ledger struct {
+	Deliveries []Delivery `json:"deliveries"`
+}
+
+func ledgerPath(townRoot string) string {
+	return filepath.Join(Dir(townRoot), "deliveries.json")
+}
+
+func loadLedger(townRoot string) (*ledger, error) {
+	l := &ledger{}
+	data, err := os.ReadFile(ledgerPath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("parsing secrets deliveries: %w", err)
+	}
+	return l, nil
+}
+
+func (l *ledger) save(townRoot string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(ledgerPath(townRoot), data, 0600)
+}
+
+// Deliveries returns the secret files currently materialized in worktrees.
+func Deliveries(townRoot string) ([]Delivery, error) {
+	l, err := loadLedger(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	return l.Deliveries, nil
+}
+
+// Materialize writes the file secrets an agent of role in rig receives into
+// its worktree (mode 0600, excluded from git) and records each delivery.
+// Returns the number of files written. Towns without secrets return
+// immediately.
+func Materialize(townRoot, rig, role, agent, worktree string) (int, error) {
+	store, err := Load(townRoot)
+	if err != nil {
+		return 0, err
+	}
+	var files []*Secret
+	for _, sec := range store.Resolve(rig, role) {
+		if sec.File != "" {
+			files = append(files, sec)
+		}
+	}
+	if len(files) == 0 {
+		return 0, nil
+	}
+
+	written := 0
+	err = withLock(townRoot, func() error {
+		l, err := loadLedger(townRoot)
+		if err != nil {
+			return err
+		}
+		l.drop(func(d Delivery) bool { return d.Worktree == worktree })
+
+		for _, sec := range files {
+			value, err := store.Decrypt(sec)
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(worktree, sec.File)
+			if err := writeSecretFile(path, value); err != nil {
+				return fmt.Errorf("writing %s: %w", sec.File, err)
+			}
+			excludeFromGit(worktree, sec.File)
+
+			l.Deliveries = append(l.Deliveries, Delivery{
+				Secret:      sec.Name,
+				Scope:       sec.ScopeLabel(),
+				Version:     sec.Version,
+				Agent:       agent,
+				Worktree:    worktree,
+				Path:        path,
+				DeliveredAt: time.Now().UTC(),
+			})
+			entry := auditFor(ActionMaterialize, sec)
+			entry.Agent, entry.Target = agent, path
+			_ = Audit(townRoot, entry)
+			written++
+		}
+		return l.save(townRoot)
+	})
+	return written, err
+}
+
+// Scrub deletes every secret file materialized into a worktree. Call it
+// before the worktree is removed. Returns the number of files deleted.
+func Scrub(townRoot, worktree, agent string) (int, error) {
+	if _, err := os.Stat(ledgerPath(townRoot)); os.IsNotExist(err) {
+		return 0, nil
+	}
+	return scrubWhere(townRoot, agent, func(d Delivery) bool { return d.Worktree == worktree })
+}
+
+// ScrubSecret deletes a secret's files from every worktree it was delivered
+// to. Used when the secret is revoked.
+func ScrubSecret(townRoot string, sec *Secret) (int, error) {
+	return scrubWhere(townRoot, "", func(d Delivery) bool {
+		return d.Secret == sec.Name && d.Scope == sec.ScopeLabel()
+	})
+}
+
+func scrubWhere(townRoot, actor string, match func(Delivery) bool) (int, error) {
+	removed := 0
+	err := withLock(townRoot, func() error {
+		l, err := loadLedger(townRoot)
+		if err != nil {
+			return err
+		}
+		for _, d := range l.drop(match) {
+			if err := os.Remove(d.Path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("removing %s: %w", d.Path, err)
+			}
+			_ = Audit(townRoot, AuditEntry{
+				Action:  ActionScrub,
+				Secret:  d.Secret,
+				Scope:   d.Scope,
+				Version: d.Version,
+				Agent:   d.Agent,
+				Target:  d.Path,
+				Actor:   actor,
+			})
+			removed++
+		}
+		return l.save(townRoot)
+	})
+	return removed, err
+}
+
+// Redeliver rewrites a rotated secret's files in every worktree that holds
+// an older version. Returns the number of files rewritten.
+func Redeliver(townRoot string, store *Store, sec *Secret) (int, error) {
+	value, err := store.Decrypt(sec)
+	if err != nil {
+		return 0, err
+	}
+
+	rewritten := 0
+	err = withLock(townRoot, func() error {
+		l, err := loadLedger(townRoot)
+		if err != nil {
+			return err
+		}
+		for i := range l.Deliveries {
+			d := &l.Deliveries[i]
+			if d.Secret != sec.Name || d.Scope != sec.ScopeLabel() || d.Version == sec.Version {
+				continue
+			}
+			if _, err := os.Stat(d.Worktree); err != nil {
+				continue // Worktree gone without a scrub; pruned below
+			}
+			if err := writeSecretFile(d.Path, value); err != nil {
+				return fmt.Errorf("rewriting %s: %w", d.Path, err)
+			}
+			d.Version = sec.Version
+			d.DeliveredAt = time.Now().UTC()
+			entry := auditFor(ActionMaterialize, sec)
+			entry.Agent, entry.Target = d.Agent, d.Path
+			_ = Audit(townRoot, entry)
+			rewritten++
+		}
+		l.drop(func(d Delivery) bool {
+			_, err := os.Stat(d.Worktree)
+			return os.IsNotExist(err)
+		})
+		return l.save(townRoot)
+	})
+	return rewritten, err
+}
+
+// drop removes matching deliveries from the ledger and returns them.
+func (l *ledger) drop(match func(Delivery) bool) []Delivery {
+	var kept, dropped []Delivery
+	for _, d := range l.Deliveries {
+		if match(d) {
+			dropped = append(dropped, d)
+		} else {
+			kept = append(kept, d)
+		}
+	}
+	l.Deliveries = kept
+	return dropped
+}
+
+func writeSecretFile(path string, value []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, value, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0600)
+}
+
+// excludeFromGit adds a materialized file to the repo's info/exclude, which
+// all worktrees share, so agents can't commit it by accident. Best-effort.
+func excludeFromGit(worktree, file string) {
+	_ = git.NewGit(worktree).ExcludeLocally("/"+filepath.ToSlash(file), "gt secrets")
+}
+
+// SessionEnvFile writes the env secrets an agent of role in rig receives to
+// a private file outside the worktree and returns its path, or "" if there
+// are none. Wrap the session command with WrapCommand so the file is sourced
+// and deleted before the agent starts, keeping values out of the command
+// line, tmux and the worktree.
+func SessionEnvFile(townRoot, rig, role, agent string) (string, error) {
+	store, err := Load(townRoot)
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	for _, sec := range store.Resolve(rig, role) {
+		if sec.File != "" {
+			continue
+		}
+		value, err := store.Decrypt(sec)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, fmt.Sprintf("export %s=%s", sec.Name, shellQuote(string(value))))
+		entry := auditFor(ActionExport, sec)
+		entry.Agent = agent
+		_ = Audit(townRoot, entry)
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	sort.Strings(lines)
+
+	dir := filepath.Join(Dir(townRoot), "sessions")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, strings.ReplaceAll(agent, "/", "-")+".env")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// WrapCommand prefixes a session command so it sources and deletes an env
+// file from SessionEnvFile. An empty envFile returns command unchanged.
+func WrapCommand(command, envFile string) string {
+	if envFile == "" {
+		return command
+	}
+	q := shellQuote(envFile)
+	return ". " + q + " && rm -f " + q + " && " + command
+}
+
+// DiscardEnvFile removes an env file from SessionEnvFile whose session
+// failed to start, so the values don't linger on disk.
+func DiscardEnvFile(envFile string) {
+	if envFile != "" {
+		_ = os.Remove(envFile)
+	}
+}
+
+// shellQuote single-quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
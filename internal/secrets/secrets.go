@@ -0,0 +1,372 @@
+// Package secrets provides the town's encrypted secret store.
+//
+// Secrets are encrypted with AES-256-GCM under a local key file and scoped
+// to a rig and/or role. At spawn they are either materialized as files in a
+// worktree or exported into the agent session's environment. Every delivery
+// is recorded so files can be scrubbed when the worktree goes away, and every
+// access is appended to an audit log.
+//
+// Layout:
+//
+//	<town>/.runtime/secrets/
+//	  key                 32-byte AES key (0600), or $GT_SECRETS_KEY_FILE
+//	  store.json          encrypted secrets
+//	  deliveries.json     files materialized into worktrees
+//	  audit.jsonl         access log
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// KeyFileEnv overrides the key file location, so the key can live outside
+// the town where agents can't read it.
+const KeyFileEnv = "GT_SECRETS_KEY_FILE"
+
+// Common errors
+var (
+	ErrSecretExists   = errors.New("secret already exists")
+	ErrSecretNotFound = errors.New("secret not found")
+	ErrNoKey          = errors.New("secrets key not found")
+)
+
+// envNamePattern matches names that can be exported as environment variables.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Secret is one encrypted value and its scope.
+type Secret struct {
+	Name string `json:"name"`
+
+	// Rig and Role scope the secret. Empty means every rig or every role.
+	Rig  string `json:"rig,omitempty"`
+	Role string `json:"role,omitempty"`
+
+	// File is the worktree-relative path the secret is written to. Empty
+	// means the secret is exported as the environment variable Name.
+	File string `json:"file,omitempty"`
+
+	Version    int       `json:"version"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ScopeLabel describes the secret's scope for display.
+func (s *Secret) ScopeLabel() string {
+	rig, role := s.Rig, s.Role
+	if rig == "" {
+		rig = "*"
+	}
+	if role == "" {
+		role = "*"
+	}
+	return rig + "/" + role
+}
+
+// Delivery describes how the secret reaches an agent.
+func (s *Secret) Delivery() string {
+	if s.File != "" {
+		return "file:" + s.File
+	}
+	return "env"
+}
+
+// Matches reports whether the secret applies to an agent of role in rig.
+func (s *Secret) Matches(rig, role string) bool {
+	return (s.Rig == "" || s.Rig == rig) && (s.Role == "" || s.Role == role)
+}
+
+// specificity ranks how narrowly a secret is scoped.
+func (s *Secret) specificity() int {
+	n := 0
+	if s.Rig != "" {
+		n += 2
+	}
+	if s.Role != "" {
+		n++
+	}
+	return n
+}
+
+// aad binds a ciphertext to its secret so entries can't be swapped.
+func (s *Secret) aad() []byte {
+	return []byte(s.Name + "\x00" + s.Rig + "\x00" + s.Role + "\x00" + strconv.Itoa(s.Version))
+}
+
+// Store is the town's secret store.
+type Store struct {
+	Secrets []*Secret `json:"secrets"`
+
+	townRoot string
+	key      []byte
+}
+
+// Dir returns the secrets directory for a town.
+func Dir(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "secrets")
+}
+
+// KeyPath returns the key file location, honoring GT_SECRETS_KEY_FILE.
+func KeyPath(townRoot string) string {
+	if p := os.Getenv(KeyFileEnv); p != "" {
+		return p
+	}
+	return filepath.Join(Dir(townRoot), "key")
+}
+
+func storePath(townRoot string) string {
+	return filepath.Join(Dir(townRoot), "store.json")
+}
+
+// Load reads the town's store. A missing store yields an empty one.
+// The key is read on first use.
+func Load(townRoot string) (*Store, error) {
+	s := &Store{townRoot: townRoot}
+
+	data, err := os.ReadFile(storePath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing secrets store: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the store back to disk, readable only by the owner.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(Dir(s.townRoot), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(storePath(s.townRoot), data, 0600)
+}
+
+// Update loads the store under an exclusive lock, applies fn, and saves it
+// if fn succeeds.
+func Update(townRoot string, fn func(*Store) error) error {
+	return withLock(townRoot, func() error {
+		s, err := Load(townRoot)
+		if err != nil {
+			return err
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+		return s.Save()
+	})
+}
+
+// withLock runs fn holding the town's secrets lock, which guards the store
+// and the delivery ledger.
+func withLock(townRoot string, fn func() error) error {
+	if err := os.MkdirAll(Dir(townRoot), 0700); err != nil {
+		return err
+	}
+	lock := flock.New(filepath.Join(Dir(townRoot), ".lock"))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking secrets store: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return fn()
+}
+
+// loadKey reads the key file, creating it when create is set.
+func (s *Store) loadKey(create bool) ([]byte, error) {
+	if s.key != nil {
+		return s.key, nil
+	}
+
+	path := KeyPath(s.townRoot)
+	key, err := os.ReadFile(path) //nolint:gosec // G304: path is the configured key file
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("secrets key %s: want 32 bytes, got %d", path, len(key))
+		}
+		s.key = key
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if !create {
+		return nil, fmt.Errorf("%w at %s", ErrNoKey, path)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	// O_EXCL so two concurrent first writers can't clobber each other's key
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) //nolint:gosec // G304: path is the configured key file
+	if err != nil {
+		if os.IsExist(err) {
+			return s.loadKey(false)
+		}
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Write(key); err != nil {
+		return nil, err
+	}
+	s.key = key
+	return key, nil
+}
+
+func (s *Store) aead(create bool) (cipher.AEAD, error) {
+	key, err := s.loadKey(create)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts value into sec under a fresh nonce.
+func (s *Store) seal(sec *Secret, value []byte) error {
+	gcm, err := s.aead(true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sec.Nonce = nonce
+	sec.Ciphertext = gcm.Seal(nil, nonce, value, sec.aad())
+	return nil
+}
+
+// Decrypt returns a secret's plaintext value.
+func (s *Store) Decrypt(sec *Secret) ([]byte, error) {
+	gcm, err := s.aead(false)
+	if err != nil {
+		return nil, err
+	}
+	value, err := gcm.Open(nil, sec.Nonce, sec.Ciphertext, sec.aad())
+	if err != nil {
+		return nil, fmt.Errorf("decrypting %s: %w", sec.Name, err)
+	}
+	return value, nil
+}
+
+// Find returns the secret with exactly this name and scope.
+func (s *Store) Find(name, rig, role string) *Secret {
+	for _, sec := range s.Secrets {
+		if sec.Name == name && sec.Rig == rig && sec.Role == role {
+			return sec
+		}
+	}
+	return nil
+}
+
+// Set adds a new secret. Existing secrets must be changed with Rotate.
+func (s *Store) Set(name, rig, role, file string, value []byte) (*Secret, error) {
+	if file == "" && !envNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid secret name %q: env secrets must be valid variable names (use --file for file secrets)", name)
+	}
+	if file != "" {
+		clean := filepath.Clean(file)
+		if filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("invalid file %q: must be a path inside the worktree", file)
+		}
+		file = clean
+	}
+	if s.Find(name, rig, role) != nil {
+		return nil, ErrSecretExists
+	}
+
+	now := time.Now().UTC()
+	sec := &Secret{Name: name, Rig: rig, Role: role, File: file, Version: 1, CreatedAt: now, UpdatedAt: now}
+	if err := s.seal(sec, value); err != nil {
+		return nil, err
+	}
+	s.Secrets = append(s.Secrets, sec)
+	s.sort()
+	return sec, nil
+}
+
+// Rotate replaces a secret's value and bumps its version.
+func (s *Store) Rotate(name, rig, role string, value []byte) (*Secret, error) {
+	sec := s.Find(name, rig, role)
+	if sec == nil {
+		return nil, ErrSecretNotFound
+	}
+	sec.Version++
+	sec.UpdatedAt = time.Now().UTC()
+	if err := s.seal(sec, value); err != nil {
+		return nil, err
+	}
+	return sec, nil
+}
+
+// Revoke removes a secret from the store and returns it.
+func (s *Store) Revoke(name, rig, role string) (*Secret, error) {
+	for i, sec := range s.Secrets {
+		if sec.Name == name && sec.Rig == rig && sec.Role == role {
+			s.Secrets = append(s.Secrets[:i], s.Secrets[i+1:]...)
+			return sec, nil
+		}
+	}
+	return nil, ErrSecretNotFound
+}
+
+// Resolve returns the secrets an agent of role in rig receives. When a name
+// is defined at several scopes, the most specific wins (rig+role, rig,
+// role, town-wide).
+func (s *Store) Resolve(rig, role string) []*Secret {
+	best := make(map[string]*Secret)
+	for _, sec := range s.Secrets {
+		if !sec.Matches(rig, role) {
+			continue
+		}
+		if cur, ok := best[sec.Name]; !ok || sec.specificity() > cur.specificity() {
+			best[sec.Name] = sec
+		}
+	}
+
+	resolved := make([]*Secret, 0, len(best))
+	for _, sec := range best {
+		resolved = append(resolved, sec)
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].Name < resolved[j].Name })
+	return resolved
+}
+
+func (s *Store) sort() {
+	sort.SliceStable(s.Secrets, func(i, j int) bool {
+		a, b := s.Secrets[i], s.Secrets[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ScopeLabel() < b.ScopeLabel()
+	})
+}
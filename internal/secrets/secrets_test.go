@@ -0,0 +1,240 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStore_SetDecryptRoundTrip(t *testing.T) {
+	town := t.TempDir()
+
+	err := Update(town, func(s *Store) error {
+		_, err := s.Set("API_TOKEN", "gastown", "polecat", "", []byte("s3cret"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(Dir(town), "store.json"))
+	if err != nil {
+		t.Fatalf("reading store: %v", err)
+	}
+	if strings.Contains(string(raw), "s3cret") {
+		t.Error("store.json contains the plaintext value")
+	}
+	info, err := os.Stat(KeyPath(town))
+	if err != nil {
+		t.Fatalf("key file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	s, err := Load(town)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	sec := s.Find("API_TOKEN", "gastown", "polecat")
+	if sec == nil {
+		t.Fatal("secret not found after reload")
+	}
+	value, err := s.Decrypt(sec)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if string(value) != "s3cret" {
+		t.Errorf("Decrypt = %q, want s3cret", value)
+	}
+
+	if _, err := s.Set("API_TOKEN", "gastown", "polecat", "", []byte("x")); err != ErrSecretExists {
+		t.Errorf("Set duplicate = %v, want ErrSecretExists", err)
+	}
+}
+
+func TestStore_CiphertextBoundToScope(t *testing.T) {
+	town := t.TempDir()
+	s, _ := Load(town)
+	a, err := s.Set("TOKEN", "rig-a", "", "", []byte("for-a"))
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	b, err := s.Set("TOKEN", "rig-b", "", "", []byte("for-b"))
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	// Moving rig-a's ciphertext onto rig-b's entry must not decrypt
+	b.Nonce, b.Ciphertext = a.Nonce, a.Ciphertext
+	if _, err := s.Decrypt(b); err == nil {
+		t.Error("Decrypt succeeded on a ciphertext swapped between scopes")
+	}
+}
+
+func TestStore_ResolvePrefersMostSpecific(t *testing.T) {
+	s, _ := Load(t.TempDir())
+	for _, scope := range [][2]string{{"", ""}, {"", "polecat"}, {"gastown", ""}, {"gastown", "polecat"}} {
+		if _, err := s.Set("TOKEN", scope[0], scope[1], "", []byte(scope[0]+"/"+scope[1])); err != nil {
+			t.Fatalf("Set %v: %v", scope, err)
+		}
+	}
+	if _, err := s.Set("OTHER", "beads", "", "", []byte("x")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	tests := []struct {
+		rig, role string
+		want      string
+	}{
+		{"gastown", "polecat", "gastown/polecat"},
+		{"gastown", "crew", "gastown/*"},
+		{"beads", "polecat", "*/polecat"},
+		{"beads", "crew", "*/*"},
+	}
+	for _, tt := range tests {
+		resolved := s.Resolve(tt.rig, tt.role)
+		var got string
+		for _, sec := range resolved {
+			if sec.Name == "TOKEN" {
+				got = sec.ScopeLabel()
+			}
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%s, %s) TOKEN scope = %s, want %s", tt.rig, tt.role, got, tt.want)
+		}
+	}
+
+	if n := len(s.Resolve("gastown", "polecat")); n != 1 {
+		t.Errorf("Resolve(gastown, polecat) = %d secrets, want 1 (OTHER is scoped to beads)", n)
+	}
+}
+
+func TestStore_SetRejectsBadNames(t *testing.T) {
+	s, _ := Load(t.TempDir())
+	if _, err := s.Set("not-an-env-var", "", "", "", []byte("x")); err == nil {
+		t.Error("Set accepted an env secret that isn't a valid variable name")
+	}
+	if _, err := s.Set("cfg", "", "", "../outside", []byte("x")); err == nil {
+		t.Error("Set accepted a file path outside the worktree")
+	}
+	if _, err := s.Set("cfg", "", "", "/etc/passwd", []byte("x")); err == nil {
+		t.Error("Set accepted an absolute file path")
+	}
+}
+
+func TestMaterializeRotateScrub(t *testing.T) {
+	town := t.TempDir()
+	worktree := filepath.Join(town, "gastown", "polecats", "Toast", "gastown")
+	if err := os.MkdirAll(worktree, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Update(town, func(s *Store) error {
+		if _, err := s.Set("app-env", "gastown", "polecat", "config/.env", []byte("A=1\n")); err != nil {
+			return err
+		}
+		_, err := s.Set("CREW_ONLY", "", "crew", "", []byte("nope"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	n, err := Materialize(town, "gastown", "polecat", "gastown/polecats/Toast", worktree)
+	if err != nil {
+		t.Fatalf("Materialize: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Materialize wrote %d files, want 1", n)
+	}
+	path := filepath.Join(worktree, "config", ".env")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("materialized file missing: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("materialized file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// Rotation rewrites the delivered file
+	var store *Store
+	var sec *Secret
+	err = Update(town, func(s *Store) error {
+		store = s
+		sec, err = s.Rotate("app-env", "gastown", "polecat", []byte("A=2\n"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if n, err := Redeliver(town, store, sec); err != nil || n != 1 {
+		t.Fatalf("Redeliver = %d, %v; want 1", n, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "A=2\n" {
+		t.Errorf("file after rotate = %q, want A=2", data)
+	}
+
+	n, err = Scrub(town, worktree, "gastown/polecats/Toast")
+	if err != nil {
+		t.Fatalf("Scrub: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Scrub removed %d files, want 1", n)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("secret file still exists after Scrub")
+	}
+
+	entries, err := ReadAudit(town)
+	if err != nil {
+		t.Fatalf("ReadAudit: %v", err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	want := "materialize,materialize,scrub"
+	if got := strings.Join(actions, ","); got != want {
+		t.Errorf("audit actions = %s, want %s", got, want)
+	}
+}
+
+func TestSessionEnvFile(t *testing.T) {
+	town := t.TempDir()
+	err := Update(town, func(s *Store) error {
+		_, err := s.Set("TOKEN", "gastown", "", "", []byte("it's secret"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if path, err := SessionEnvFile(town, "beads", "polecat", "beads/polecats/Nux"); err != nil || path != "" {
+		t.Errorf("SessionEnvFile for unscoped rig = %q, %v; want no file", path, err)
+	}
+
+	path, err := SessionEnvFile(town, "gastown", "polecat", "gastown/polecats/Toast")
+	if err != nil {
+		t.Fatalf("SessionEnvFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading env file: %v", err)
+	}
+	if got, want := string(data), "export TOKEN='it'\\''s secret'\n"; got != want {
+		t.Errorf("env file = %q, want %q", got, want)
+	}
+
+	cmd := WrapCommand("claude", path)
+	if strings.Contains(cmd, "it's") || strings.Contains(cmd, "TOKEN") {
+		t.Errorf("wrapped command leaks the value: %s", cmd)
+	}
+	if !strings.HasSuffix(cmd, "&& claude") || !strings.Contains(cmd, "rm -f") {
+		t.Errorf("WrapCommand = %q, want source, delete, then run", cmd)
+	}
+	if WrapCommand("claude", "") != "claude" {
+		t.Error("WrapCommand without env file changed the command")
+	}
+}
@@ -0,0 +1,78 @@
+package statusline
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Cache stores provider results as small JSON files so repeated status line
+// refreshes reuse them until they are older than TTL. Like keepalive, it is
+// best-effort: unreadable or unwritable entries just mean a cache miss.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+
+	now func() time.Time // for tests
+}
+
+// NewCache returns a cache under <townRoot>/.runtime/statusline. An empty
+// town root or a zero TTL yields a cache that never hits.
+func NewCache(townRoot string, ttl time.Duration) *Cache {
+	c := &Cache{TTL: ttl}
+	if townRoot != "" {
+		c.Dir = filepath.Join(constants.TownRuntimePath(townRoot), "statusline")
+	}
+	return c
+}
+
+type cacheEntry struct {
+	At    time.Time       `json:"at"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Fetch returns the cached value for key if it is fresh, otherwise calls fill
+// and caches its result. Errors from fill are returned and not cached.
+func Fetch[T any](c *Cache, key string, fill func() (T, error)) (T, error) {
+	if c == nil || c.Dir == "" || c.TTL <= 0 {
+		return fill()
+	}
+	path := filepath.Join(c.Dir, cacheFileName(key))
+
+	if data, err := os.ReadFile(path); err == nil { //nolint:gosec // G304: path is under the town runtime dir
+		var entry cacheEntry
+		var v T
+		if json.Unmarshal(data, &entry) == nil && c.clock().Sub(entry.At) < c.TTL &&
+			json.Unmarshal(entry.Value, &v) == nil {
+			return v, nil
+		}
+	}
+
+	v, err := fill()
+	if err != nil {
+		return v, err
+	}
+	if raw, err := json.Marshal(v); err == nil {
+		if os.MkdirAll(c.Dir, 0755) == nil {
+			_ = util.AtomicWriteJSON(path, cacheEntry{At: c.clock(), Value: raw})
+		}
+	}
+	return v, nil
+}
+
+func (c *Cache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// cacheFileName maps a key such as "mail:gastown/witness" to a file name.
+func cacheFileName(key string) string {
+	return strings.NewReplacer("/", "_", ":", "-", string(os.PathSeparator), "_").Replace(key) + ".json"
+}
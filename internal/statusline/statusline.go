@@ -0,0 +1,179 @@
+// Package statusline renders the tmux status line from per-role Go templates.
+//
+// Each role (mayor, deacon, witness, refinery, polecat, crew) has a built-in
+// template that reproduces the classic Gas Town status line. Towns and rigs
+// can replace it in settings/config.json:
+//
+//	{"status_line": {"templates": {
+//	  "polecat": "{{join .Icon .Hook .Context .Cost}} |"
+//	}}}
+//
+// Templates are evaluated against a data value whose fields and methods are
+// the data providers (see Providers). Providers are only invoked when the
+// template references them, and the expensive ones (bd queries, transcript
+// parsing) are cached on disk by Cache so a tmux refresh every few seconds
+// does not fork bd on every tick.
+package statusline
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// DefaultCacheTTL is how long cached provider results are reused when
+// status_line.cache_ttl is not set. tmux refreshes every 5 seconds.
+const DefaultCacheTTL = 30 * time.Second
+
+// Roles lists the roles that have a status line template.
+var Roles = []string{"mayor", "deacon", "witness", "refinery", "polecat", "crew"}
+
+// DefaultTemplates are the built-in templates for each role.
+var DefaultTemplates = map[string]string{
+	"mayor":    `{{join .Agents .Deacon .Rigs (or .Hook .Mail)}} |`,
+	"deacon":   `{{join (printf "%d rigs" .ActiveRigs) (or .Hook .Mail)}} |`,
+	"witness":  `{{join .Crew (or .Hook .Mail)}} |`,
+	"refinery": `{{join .MQ (or .Hook .Mail)}} |`,
+	"polecat":  `{{with join (spaced .Icon (or .Hook .Work)) (and (not .Hook) .Mail)}}{{.}} |{{end}}`,
+	"crew":     `{{with join (spaced .Icon (or .Hook .Work)) (and (not .Hook) .Mail)}}{{.}} |{{end}}`,
+}
+
+// Provider documents one value available to status line templates.
+type Provider struct {
+	Name        string
+	Description string
+	Cached      bool // result is cached for the cache TTL
+}
+
+// Providers documents the values templates can reference, in display order.
+var Providers = []Provider{
+	{Name: ".Role", Description: "role of the session (mayor, deacon, witness, refinery, polecat, crew)"},
+	{Name: ".Rig", Description: "rig name (empty for town-level roles)"},
+	{Name: ".Identity", Description: "mail address of the agent, e.g. gastown/polecats/Toast"},
+	{Name: ".Session", Description: "tmux session name"},
+	{Name: ".Icon", Description: "role icon"},
+	{Name: ".Hook", Description: "🪝 hooked bead (id: title), truncated", Cached: true},
+	{Name: ".HookBead", Description: "hooked bead (id: title), untruncated", Cached: true},
+	{Name: ".Work", Description: "$GT_ISSUE or the first in_progress bead in the worktree", Cached: true},
+	{Name: ".Mail", Description: "📬 first unread subject, or the unread count", Cached: true},
+	{Name: ".MailCount", Description: "number of unread messages", Cached: true},
+	{Name: ".MailSubject", Description: "subject of the first unread message, untruncated", Cached: true},
+	{Name: ".MQ", Description: "merge queue summary (merging X, N queued, idle)", Cached: true},
+	{Name: ".MQDepth", Description: "merge requests in the rig's queue, including the one merging", Cached: true},
+	{Name: ".Crew", Description: "N crew (running crew sessions in the rig)"},
+	{Name: ".CrewCount", Description: "running crew sessions in the rig"},
+	{Name: ".Agents", Description: "working/total witnesses and refineries, e.g. 1/3 👁️"},
+	{Name: ".Deacon", Description: "deacon icon when the deacon is running"},
+	{Name: ".Rigs", Description: "rig LEDs (🟢 🟡 ⚫ 🅿️ 🛑) with names"},
+	{Name: ".ActiveRigs", Description: "registered rigs with a running agent"},
+	{Name: ".Context", Description: "🧠 context window used by the agent, e.g. 🧠 42%", Cached: true},
+	{Name: ".ContextPercent", Description: "context window used, 0-100", Cached: true},
+	{Name: ".Cost", Description: "session cost so far, e.g. $1.23", Cached: true},
+	{Name: ".CostUSD", Description: "session cost so far as a number", Cached: true},
+}
+
+// Funcs are the helper functions available to templates, in addition to the
+// text/template builtins (and, or, not, printf, ...).
+var Funcs = template.FuncMap{
+	// join joins the non-empty arguments with " | ".
+	"join": func(parts ...any) string { return joinNonEmpty(" | ", parts) },
+	// spaced joins the non-empty arguments with a space.
+	"spaced": func(parts ...any) string { return joinNonEmpty(" ", parts) },
+	// trunc shortens s to n characters, ending in "…" when cut.
+	"trunc": Truncate,
+}
+
+// TemplateFor returns the template for a role. A rig template overrides the
+// town template, which overrides the built-in default.
+func TemplateFor(role string, town, rig *config.StatusLineConfig) string {
+	for _, cfg := range []*config.StatusLineConfig{rig, town} {
+		if cfg == nil {
+			continue
+		}
+		if tmpl := cfg.Templates[role]; tmpl != "" {
+			return tmpl
+		}
+	}
+	return DefaultTemplates[role]
+}
+
+// CacheTTL returns the provider cache TTL, preferring the rig setting.
+// Invalid durations fall back to DefaultCacheTTL; "0" disables caching.
+func CacheTTL(town, rig *config.StatusLineConfig) time.Duration {
+	for _, cfg := range []*config.StatusLineConfig{rig, town} {
+		if cfg == nil || cfg.CacheTTL == "" {
+			continue
+		}
+		d, err := time.ParseDuration(cfg.CacheTTL)
+		if err != nil || d < 0 {
+			return DefaultCacheTTL
+		}
+		return d
+	}
+	return DefaultCacheTTL
+}
+
+// Parse compiles a status line template.
+func Parse(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(Funcs).Option("missingkey=zero").Parse(text)
+}
+
+// Render executes a status line template against data. The result is a
+// single line: newlines become spaces and the ends are trimmed.
+func Render(name, text string, data any) (string, error) {
+	tmpl, err := Parse(name, text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.NewReplacer("\r\n", " ", "\n", " ").Replace(b.String())), nil
+}
+
+// Truncate shortens s to at most n characters, replacing the last one with
+// "…" when it has to cut.
+func Truncate(n int, s string) string {
+	r := []rune(s)
+	if n <= 0 || len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func joinNonEmpty(sep string, parts []any) string {
+	var out []string
+	for _, p := range parts {
+		s := strings.TrimSpace(toString(p))
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+// toString renders a template value for join, treating zero values and
+// false (from and/not) as empty.
+func toString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case bool:
+		return ""
+	case int:
+		if x == 0 {
+			return ""
+		}
+	case float64:
+		if x == 0 {
+			return ""
+		}
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,115 @@
+package statusline
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// fakeData stands in for the provider methods gt status-line exposes.
+type fakeData struct {
+	Icon, Hook, Work, Mail, MQ, Crew, Agents, Deacon, Rigs string
+	ActiveRigs                                             int
+}
+
+func TestDefaultTemplates(t *testing.T) {
+	tests := []struct {
+		role string
+		data fakeData
+		want string
+	}{
+		{"polecat", fakeData{Icon: "😺", Hook: "🪝 gt-1: Fix", Mail: "📬 hi"}, "😺 🪝 gt-1: Fix |"},
+		{"polecat", fakeData{Icon: "😺", Work: "gt-2: Wip", Mail: "📬 hi"}, "😺 gt-2: Wip | 📬 hi |"},
+		{"crew", fakeData{Icon: "👷"}, "👷 |"},
+		{"crew", fakeData{}, ""},
+		{"mayor", fakeData{Agents: "1/2 👁️", Deacon: "🐺", Rigs: "🟢 gastown", Mail: "📬 3"}, "1/2 👁️ | 🐺 | 🟢 gastown | 📬 3 |"},
+		{"deacon", fakeData{ActiveRigs: 2, Hook: "🪝 hq-1: Patrol", Mail: "📬 x"}, "2 rigs | 🪝 hq-1: Patrol |"},
+		{"witness", fakeData{Mail: "📬 help"}, "📬 help |"},
+		{"refinery", fakeData{MQ: "3 queued"}, "3 queued |"},
+	}
+	for _, tt := range tests {
+		got, err := Render(tt.role, DefaultTemplates[tt.role], tt.data)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.role, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s with %+v = %q, want %q", tt.role, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateForPrecedence(t *testing.T) {
+	town := &config.StatusLineConfig{Templates: map[string]string{"polecat": "town", "crew": "town-crew"}}
+	rig := &config.StatusLineConfig{Templates: map[string]string{"polecat": "rig"}}
+
+	if got := TemplateFor("polecat", town, rig); got != "rig" {
+		t.Errorf("polecat = %q, want rig override", got)
+	}
+	if got := TemplateFor("crew", town, rig); got != "town-crew" {
+		t.Errorf("crew = %q, want town template", got)
+	}
+	if got := TemplateFor("mayor", town, nil); got != DefaultTemplates["mayor"] {
+		t.Errorf("mayor = %q, want built-in", got)
+	}
+
+	if got := CacheTTL(&config.StatusLineConfig{CacheTTL: "10s"}, &config.StatusLineConfig{CacheTTL: "0"}); got != 0 {
+		t.Errorf("CacheTTL = %v, want rig's 0", got)
+	}
+	if got := CacheTTL(&config.StatusLineConfig{CacheTTL: "soon"}, nil); got != DefaultCacheTTL {
+		t.Errorf("CacheTTL(invalid) = %v, want default", got)
+	}
+}
+
+func TestRenderFuncs(t *testing.T) {
+	got, err := Render("x", "{{join .A (trunc 5 .B) .C}}\n{{spaced .A .A}}", map[string]any{"A": "a", "B": "abcdefgh", "C": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a | abcd… a a"; got != want {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+	if _, err := Render("bad", "{{join .A", nil); err == nil {
+		t.Error("Render accepted an unterminated action")
+	}
+}
+
+func TestCacheFetch(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := NewCache(t.TempDir(), 30*time.Second)
+	c.now = func() time.Time { return now }
+
+	calls := 0
+	fill := func() (int, error) { calls++; return calls, nil }
+
+	if v, _ := Fetch(c, "mail:gastown/witness", fill); v != 1 {
+		t.Fatalf("first Fetch = %d, want 1", v)
+	}
+	now = now.Add(10 * time.Second)
+	if v, _ := Fetch(c, "mail:gastown/witness", fill); v != 1 || calls != 1 {
+		t.Errorf("fresh Fetch = %d after %d calls, want cached 1", v, calls)
+	}
+	now = now.Add(30 * time.Second)
+	if v, _ := Fetch(c, "mail:gastown/witness", fill); v != 2 {
+		t.Errorf("stale Fetch = %d, want refilled 2", v)
+	}
+
+	boom := errors.New("bd unavailable")
+	if _, err := Fetch(c, "hook:x", func() (string, error) { return "", boom }); err != boom {
+		t.Errorf("Fetch error = %v, want %v", err, boom)
+	}
+	if _, err := os.Stat(c.Dir + "/hook-x.json"); err == nil {
+		t.Error("failed fill was cached")
+	}
+
+	// No town root: always fill
+	calls = 0
+	nc := NewCache("", time.Minute)
+	Fetch(nc, "k", fill)
+	Fetch(nc, "k", fill)
+	if calls != 2 {
+		t.Errorf("uncached Fetch ran fill %d times, want 2", calls)
+	}
+}
@@ -327,6 +327,11 @@ func getAllDescendants(pid string) []string {
 	return result
 }
 
+// GetDescendants returns all descendant PIDs of pid, deepest first.
+func GetDescendants(pid string) []string {
+	return getAllDescendants(pid)
+}
+
 // KillPaneProcesses explicitly kills all processes associated with a tmux pane.
 // This prevents orphan processes that survive pane respawn due to SIGHUP being ignored.
 //
@@ -1020,6 +1025,35 @@ func (t *Tmux) CapturePaneLines(session string, lines int) ([]string, error) {
 	return strings.Split(out, "\n"), nil
 }
 
+// PipePane pipes a pane's output to a shell command, as it is written, and
+// reports whether it did. A pane that is already piped is left alone:
+// pipe-pane would replace the existing pipe (or with -o, close it).
+func (t *Tmux) PipePane(session, command string) (bool, error) {
+	piped, err := t.run("display-message", "-p", "-t", session, "#{pane_pipe}")
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(piped) == "1" {
+		return false, nil
+	}
+	if _, err := t.run("pipe-pane", "-t", session, command); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetPaneSize returns a session's pane width and height in cells.
+func (t *Tmux) GetPaneSize(session string) (width, height int, err error) {
+	out, err := t.run("display-message", "-p", "-t", session, "#{pane_width} #{pane_height}")
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := fmt.Sscanf(strings.TrimSpace(out), "%d %d", &width, &height); err != nil {
+		return 0, 0, fmt.Errorf("parsing pane size %q: %w", out, err)
+	}
+	return width, height, nil
+}
+
 // AttachSession attaches to an existing session.
 // Note: This replaces the current process with tmux attach.
 func (t *Tmux) AttachSession(session string) error {
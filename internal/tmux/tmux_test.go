@@ -1092,3 +1092,28 @@ func TestKillPaneProcessesExcluding_FiltersPIDs(t *testing.T) {
 		}
 	}
 }
+
+func TestPipePaneSkipsPipedPane(t *testing.T) {
+	if !hasTmux() {
+		t.Skip("tmux not installed")
+	}
+
+	tm := NewTmux()
+	sessionName := "gt-test-pipe-" + t.Name()
+	_ = tm.KillSession(sessionName)
+	if err := tm.NewSession(sessionName, ""); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	defer func() { _ = tm.KillSession(sessionName) }()
+
+	if piped, err := tm.PipePane(sessionName, "cat > /dev/null"); err != nil || !piped {
+		t.Fatalf("PipePane = %v, %v; want a new pipe", piped, err)
+	}
+	if piped, err := tm.PipePane(sessionName, "cat > /dev/null"); err != nil || piped {
+		t.Fatalf("PipePane on a piped pane = %v, %v; want it left alone", piped, err)
+	}
+	out, err := tm.run("display-message", "-p", "-t", sessionName, "#{pane_pipe}")
+	if err != nil || strings.TrimSpace(out) != "1" {
+		t.Errorf("pane_pipe = %q, %v; want the first pipe still open", out, err)
+	}
+}
@@ -27,6 +27,8 @@ const (
 	EventCrash EventType = "crash"
 	// EventKill indicates an agent was killed intentionally.
 	EventKill EventType = "kill"
+	// EventLimitKill indicates an agent was killed by its resource limits.
+	EventLimitKill EventType = "limit_kill"
 	// EventCallback indicates a callback was processed during patrol.
 	EventCallback EventType = "callback"
 
@@ -156,6 +158,12 @@ func formatLogLine(e Event) string {
 		} else {
 			detail = "killed"
 		}
+	case EventLimitKill:
+		if e.Context != "" {
+			detail = fmt.Sprintf("killed by resource limit (%s)", e.Context)
+		} else {
+			detail = "killed by resource limit"
+		}
 	case EventCallback:
 		if e.Context != "" {
 			detail = fmt.Sprintf("callback: %s", e.Context)
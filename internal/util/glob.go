@@ -0,0 +1,18 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MatchGlob performs simple glob matching (only * is supported).
+func MatchGlob(pattern, s string) bool {
+	// Convert glob to regex
+	regexPattern := "^" + regexp.QuoteMeta(pattern) + "$"
+	regexPattern = strings.ReplaceAll(regexPattern, `\*`, `.*`)
+	re, err := regexp.Compile(regexPattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
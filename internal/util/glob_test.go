@@ -0,0 +1,32 @@
+package util
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"patrol_*", "patrol_started", true},
+		{"patrol_*", "patrol_complete", true},
+		{"patrol_*", "patrol", false},
+		{"patrol_*", "xpatrol_started", false},
+		{"merge_*", "merge_started", true},
+		{"merge_*", "merged", false},
+		{"*", "anything", true},
+		{"exact", "exact", true},
+		{"exact", "notexact", false},
+		{"gastown/polecats/*", "gastown/polecats/Toast", true},
+		{"*/refinery", "gastown/refinery", true},
+		{"*a*b*", "xaxbx", true},
+		{"a*a", "a", false},
+	}
+
+	for _, tt := range tests {
+		got := MatchGlob(tt.pattern, tt.s)
+		if got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
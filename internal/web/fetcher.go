@@ -14,6 +14,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/activity"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/resources"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -1434,6 +1435,57 @@ func (f *LiveConvoyFetcher) FetchHooks() ([]HookRow, error) {
 	return rows, nil
 }
 
+// FetchResources returns each agent's latest resource sample recorded by the
+// daemon's resources patrol in the last hour, busiest first.
+func (f *LiveConvoyFetcher) FetchResources() ([]ResourceRow, error) {
+	samples, err := resources.Load(f.townRoot, time.Now().Add(-time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("loading resource samples: %w", err)
+	}
+	procs := resources.Latest(samples, resources.TypeProcess)
+	disk := resources.Latest(samples, resources.TypeDisk)
+
+	var latest []resources.Sample
+	for _, s := range procs {
+		latest = append(latest, s)
+	}
+	sort.Slice(latest, func(i, j int) bool {
+		if latest[i].CPUPercent != latest[j].CPUPercent {
+			return latest[i].CPUPercent > latest[j].CPUPercent
+		}
+		return latest[i].Agent < latest[j].Agent
+	})
+
+	rows := make([]ResourceRow, 0, len(latest))
+	for _, s := range latest {
+		row := ResourceRow{
+			Agent: formatAgentAddress(s.Agent),
+			CPU:   fmt.Sprintf("%.0f%%", s.CPUPercent),
+			RSS:   formatResourceBytes(s.RSS),
+			IsHot: s.Hot(),
+		}
+		if d, ok := disk[s.Agent]; ok {
+			row.Disk = formatResourceBytes(d.DiskBytes)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// formatResourceBytes formats a byte count for the resources panel.
+func formatResourceBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.0f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.0f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
 // FetchMayor returns the Mayor's current status.
 func (f *LiveConvoyFetcher) FetchMayor() (*MayorStatus, error) {
 	status := &MayorStatus{
@@ -1661,6 +1713,7 @@ func eventIcon(eventType string) string {
 		"session_end":       "⏹️",
 		"session_death":     "☠️",
 		"mass_death":        "💥",
+		"resource_alert":    "🔥",
 		"patrol_started":    "🔍",
 		"patrol_complete":   "✔️",
 		"escalation_sent":   "⚠️",
@@ -1724,6 +1777,9 @@ func eventSummary(eventType, actor string, payload map[string]interface{}) strin
 	case "mass_death":
 		count, _ := payload["count"].(float64)
 		return fmt.Sprintf("%.0f sessions died", count)
+	case "resource_alert":
+		cpu, _ := payload["cpu_percent"].(float64)
+		return fmt.Sprintf("%s at %.0f%% CPU", shortActor, cpu)
 	default:
 		return eventType
 	}
@@ -33,6 +33,7 @@ type ConvoyFetcher interface {
 	FetchMayor() (*MayorStatus, error)
 	FetchIssues() ([]IssueRow, error)
 	FetchActivity() ([]ActivityRow, error)
+	FetchResources() ([]ResourceRow, error)
 }
 
 // ConvoyHandler handles HTTP requests for the convoy dashboard.
@@ -78,11 +79,12 @@ func (h *ConvoyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		mayor       *MayorStatus
 		issues      []IssueRow
 		activity    []ActivityRow
+		resources   []ResourceRow
 		wg          sync.WaitGroup
 	)
 
 	// Run all fetches in parallel with error logging
-	wg.Add(14)
+	wg.Add(15)
 
 	go func() {
 		defer wg.Done()
@@ -196,6 +198,14 @@ func (h *ConvoyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			log.Printf("dashboard: FetchActivity failed: %v", err)
 		}
 	}()
+	go func() {
+		defer wg.Done()
+		var err error
+		resources, err = h.fetcher.FetchResources()
+		if err != nil {
+			log.Printf("dashboard: FetchResources failed: %v", err)
+		}
+	}()
 
 	// Wait for fetches or timeout
 	done := make(chan struct{})
@@ -229,6 +239,7 @@ func (h *ConvoyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Mayor:       mayor,
 		Issues:      enrichIssuesWithAssignees(issues, hooks),
 		Activity:    activity,
+		Resources:   resources,
 		Summary:     summary,
 		Expand:      expandPanel,
 	}
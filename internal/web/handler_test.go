@@ -31,6 +31,7 @@ type MockConvoyFetcher struct {
 	Mayor       *MayorStatus
 	Issues      []IssueRow
 	Activity    []ActivityRow
+	Resources   []ResourceRow
 	Error       error
 }
 
@@ -90,6 +91,10 @@ func (m *MockConvoyFetcher) FetchActivity() ([]ActivityRow, error) {
 	return m.Activity, nil
 }
 
+func (m *MockConvoyFetcher) FetchResources() ([]ResourceRow, error) {
+	return m.Resources, nil
+}
+
 func TestConvoyHandler_RendersTemplate(t *testing.T) {
 	mock := &MockConvoyFetcher{
 		Convoys: []ConvoyRow{
@@ -375,6 +380,37 @@ func TestConvoyHandler_EmptyMergeQueue(t *testing.T) {
 
 // Integration tests for polecat workers rendering
 
+func TestConvoyHandler_ResourcesRendering(t *testing.T) {
+	mock := &MockConvoyFetcher{
+		Resources: []ResourceRow{
+			{Agent: "nux (gastown)", CPU: "98%", RSS: "1.2 GB", Disk: "340 MB", IsHot: true},
+			{Agent: "gastown/witness", CPU: "3%", RSS: "210 MB"},
+		},
+	}
+
+	handler, err := NewConvoyHandler(mock)
+	if err != nil {
+		t.Fatalf("NewConvoyHandler() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	body := w.Body.String()
+
+	if !strings.Contains(body, "🔥 Resources") {
+		t.Error("Response should contain resources panel")
+	}
+	if !strings.Contains(body, `<span class="badge badge-red">98%</span>`) {
+		t.Error("Hot agent CPU should be flagged")
+	}
+	if !strings.Contains(body, "1.2 GB") || !strings.Contains(body, "340 MB") {
+		t.Error("Response should contain memory and disk usage")
+	}
+}
+
 func TestConvoyHandler_PolecatWorkersRendering(t *testing.T) {
 	mock := &MockConvoyFetcher{
 		Convoys: []ConvoyRow{},
@@ -1055,6 +1091,10 @@ func (m *MockConvoyFetcherWithErrors) FetchActivity() ([]ActivityRow, error) {
 	return nil, nil
 }
 
+func (m *MockConvoyFetcherWithErrors) FetchResources() ([]ResourceRow, error) {
+	return nil, nil
+}
+
 func TestConvoyHandler_NonFatalErrors(t *testing.T) {
 	mock := &MockConvoyFetcherWithErrors{
 		Convoys: []ConvoyRow{
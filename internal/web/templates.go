@@ -29,6 +29,7 @@ type ConvoyData struct {
 	Mayor       *MayorStatus
 	Issues      []IssueRow
 	Activity    []ActivityRow
+	Resources   []ResourceRow
 	Summary     *DashboardSummary
 	Expand      string // Panel to show fullscreen (from ?expand=name)
 }
@@ -103,6 +104,15 @@ type HookRow struct {
 	IsStale  bool   // True if hooked > 1 hour (potentially stuck)
 }
 
+// ResourceRow represents an agent's latest resource sample in the dashboard.
+type ResourceRow struct {
+	Agent string // Agent address (e.g., "gastown/polecats/nux")
+	CPU   string // CPU use (e.g., "42%"; 100% = one core)
+	RSS   string // Resident memory (e.g., "512 MB")
+	Disk  string // Worktree disk usage, if sampled
+	IsHot bool   // True if spinning at or above resources.HotCPUPercent
+}
+
 // MayorStatus represents the Mayor's current state.
 type MayorStatus struct {
 	IsAttached   bool   // True if gt-mayor tmux session exists
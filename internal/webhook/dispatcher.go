@@ -0,0 +1,258 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// PollInterval is how often the dispatcher checks for new events.
+const PollInterval = time.Second
+
+// maxPerPoll bounds the deliveries one subscription makes per poll, so a
+// large backlog does not starve the others.
+const maxPerPoll = 100
+
+// Dispatcher tails .events.jsonl and delivers events to the webhook
+// subscriptions in the town settings. It runs as a background goroutine
+// within the daemon. Subscriptions are re-read every poll, so edits to
+// settings/config.json take effect without a restart.
+//
+// Each subscription keeps its own cursor and retry state in
+// .runtime/webhooks/<name>.json. A new subscription starts at the end of the
+// log; it does not receive history.
+type Dispatcher struct {
+	townRoot string
+	client   *http.Client
+	logger   func(format string, args ...interface{})
+	now      func() time.Time
+	lastErr  string
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewDispatcher creates a webhook dispatcher for a town.
+func NewDispatcher(townRoot string, logger func(format string, args ...interface{})) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		townRoot: townRoot,
+		client:   &http.Client{Timeout: RequestTimeout},
+		logger:   logger,
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins the dispatcher goroutine.
+func (d *Dispatcher) Start() error {
+	d.wg.Add(1)
+	go d.run()
+	return nil
+}
+
+// Stop gracefully stops the dispatcher.
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// run is the main dispatcher loop.
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.poll()
+		}
+	}
+}
+
+// poll delivers pending events to every enabled subscription.
+func (d *Dispatcher) poll() {
+	subs, err := Load(d.townRoot)
+	if err != nil {
+		// Log a bad config once, not every second
+		if err.Error() != d.lastErr {
+			d.logger("Webhooks: %v", err)
+			d.lastErr = err.Error()
+		}
+		return
+	}
+	d.lastErr = ""
+	if len(subs) == 0 {
+		return
+	}
+
+	// A missing log is empty: subscriptions created now start at its beginning
+	var size int64
+	file, err := os.Open(filepath.Join(d.townRoot, events.EventsFile)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err == nil {
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil {
+			return
+		}
+		size = info.Size()
+	}
+
+	for _, sub := range subs {
+		if sub.Disabled || d.ctx.Err() != nil {
+			continue
+		}
+		st, err := LoadState(d.townRoot, sub.Name)
+		if err != nil {
+			d.logger("Webhook %s: %v", sub.Name, err)
+			continue
+		}
+		if st == nil {
+			// New subscription: start at the end of the log
+			st = &State{Name: sub.Name, Cursor: Cursor{Offset: size}}
+			if err := SaveState(d.townRoot, st); err != nil {
+				d.logger("Webhook %s: saving state: %v", sub.Name, err)
+			}
+			continue
+		}
+		if file != nil && d.deliverPending(sub, st, file, size) {
+			if err := SaveState(d.townRoot, st); err != nil {
+				d.logger("Webhook %s: saving state: %v", sub.Name, err)
+			}
+		}
+	}
+}
+
+// deliverPending sends the events after a subscription's cursor, stopping at
+// the first failure that still has attempts left. It reports whether the
+// state changed.
+func (d *Dispatcher) deliverPending(sub *config.WebhookConfig, st *State, file *os.File, size int64) bool {
+	changed := false
+	if st.Cursor.Offset > size {
+		// The log shrank: krc prune rewrote it
+		st.Cursor = resync(file, st.Cursor, size)
+		changed = true
+	}
+	if st.Retry != nil && d.now().Before(st.Retry.NextAt) {
+		return changed
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(file, st.Cursor.Offset, size-st.Cursor.Offset))
+	for sent := 0; sent < maxPerPoll && d.ctx.Err() == nil; {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			break // Partial line: wait for the writer to finish it
+		}
+		id := EventID(line)
+		var event events.Event
+		if json.Unmarshal(line, &event) != nil || !Matches(sub, event.Type) {
+			st.Cursor.advance(int64(len(line)), id, event.Timestamp)
+			changed = true
+			continue
+		}
+
+		sent++
+		changed = true
+		if !d.attempt(sub, st, id, &event) {
+			return true // Retry scheduled; the cursor stays on this event
+		}
+		st.Cursor.advance(int64(len(line)), id, event.Timestamp)
+	}
+	return changed
+}
+
+// attempt makes one delivery attempt and updates the subscription's stats
+// and retry state. It returns false when the event should be retried later.
+func (d *Dispatcher) attempt(sub *config.WebhookConfig, st *State, id string, event *events.Event) bool {
+	res := Send(d.ctx, d.client, sub, id, event)
+	now := d.now()
+	st.Stats.LastStatus = res.Status
+	st.Stats.LastError = res.Error
+
+	if res.OK() {
+		st.Stats.Delivered++
+		st.Stats.LastDelivered = now
+		st.Retry = nil
+		return true
+	}
+	if d.ctx.Err() != nil {
+		return false // Shutting down: try again after restart
+	}
+
+	attempts := 1
+	if st.Retry != nil && st.Retry.ID == id {
+		attempts = st.Retry.Attempts + 1
+	}
+	st.Stats.LastFailed = now
+	if attempts < MaxAttempts(sub) {
+		st.Stats.Retried++
+		st.Retry = &Retry{ID: id, Attempts: attempts, NextAt: now.Add(Backoff(attempts)), LastError: res.Error}
+		return false
+	}
+
+	st.Stats.DeadLettered++
+	st.Retry = nil
+	d.logger("Webhook %s: dead-lettered %s event %s after %d attempts: %s", sub.Name, event.Type, id, attempts, res.Error)
+	if err := AppendDeadLetter(d.townRoot, DeadLetter{
+		ID:           id,
+		Subscription: sub.Name,
+		Event:        event,
+		Attempts:     attempts,
+		LastStatus:   res.Status,
+		LastError:    res.Error,
+		FailedAt:     now,
+	}); err != nil {
+		d.logger("Webhook %s: writing dead letter: %v", sub.Name, err)
+	}
+	return true
+}
+
+func (c *Cursor) advance(n int64, id, ts string) {
+	c.Offset += n
+	c.LastID = id
+	if ts != "" {
+		c.LastTS = ts
+	}
+}
+
+// resync finds the cursor's place in a rewritten log: just after the last
+// consumed event if it survived, otherwise at the first newer event.
+func resync(file *os.File, cur Cursor, size int64) Cursor {
+	reader := bufio.NewReader(io.NewSectionReader(file, 0, size))
+	var offset int64
+	var firstNewer int64 = -1
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		offset += int64(len(line))
+		if EventID(line) == cur.LastID {
+			return Cursor{Offset: offset, LastID: cur.LastID, LastTS: cur.LastTS}
+		}
+		if firstNewer < 0 {
+			var event events.Event
+			if json.Unmarshal(line, &event) == nil && event.Timestamp > cur.LastTS {
+				firstNewer = offset - int64(len(line))
+			}
+		}
+	}
+	if firstNewer < 0 {
+		firstNewer = size
+	}
+	return Cursor{Offset: firstNewer, LastTS: cur.LastTS}
+}
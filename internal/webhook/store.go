@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// DeadLetterFile holds deliveries that exhausted their attempts.
+const DeadLetterFile = "dead-letter.jsonl"
+
+// Dir returns the directory holding webhook delivery state.
+func Dir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "webhooks")
+}
+
+// Cursor is a subscription's position in .events.jsonl.
+type Cursor struct {
+	Offset int64  `json:"offset"`
+	LastID string `json:"last_id,omitempty"` // ID of the last event consumed
+	LastTS string `json:"last_ts,omitempty"` // its timestamp, to resync after krc prune
+}
+
+// Retry is a delivery waiting for its next attempt. The subscription's
+// cursor stays on the event until it is delivered or dead-lettered, so
+// events reach each endpoint in order.
+type Retry struct {
+	ID        string    `json:"id"`
+	Attempts  int       `json:"attempts"`
+	NextAt    time.Time `json:"next_at"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Stats counts deliveries for a subscription.
+type Stats struct {
+	Delivered     int       `json:"delivered"`
+	Retried       int       `json:"retried"`
+	DeadLettered  int       `json:"dead_lettered"`
+	LastStatus    int       `json:"last_status,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+	LastDelivered time.Time `json:"last_delivered,omitempty"`
+	LastFailed    time.Time `json:"last_failed,omitempty"`
+}
+
+// State is the persisted delivery state of one subscription.
+type State struct {
+	Name   string `json:"name"`
+	Cursor Cursor `json:"cursor"`
+	Retry  *Retry `json:"retry,omitempty"`
+	Stats  Stats  `json:"stats"`
+}
+
+func statePath(townRoot, name string) string {
+	return filepath.Join(Dir(townRoot), name+".json")
+}
+
+// LoadState reads a subscription's state. A subscription without state
+// returns nil.
+func LoadState(townRoot, name string) (*State, error) {
+	data, err := os.ReadFile(statePath(townRoot, name)) //nolint:gosec // G304: path is under the town runtime dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parsing webhook state %s: %w", name, err)
+	}
+	return &st, nil
+}
+
+// SaveState writes a subscription's state.
+func SaveState(townRoot string, st *State) error {
+	if err := os.MkdirAll(Dir(townRoot), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(statePath(townRoot, st.Name), st)
+}
+
+// DeadLetter is a delivery that exhausted its attempts.
+type DeadLetter struct {
+	ID           string        `json:"id"`
+	Subscription string        `json:"subscription"`
+	Event        *events.Event `json:"event"`
+	Attempts     int           `json:"attempts"`
+	LastStatus   int           `json:"last_status,omitempty"`
+	LastError    string        `json:"last_error,omitempty"`
+	FailedAt     time.Time     `json:"failed_at"`
+}
+
+// withLock runs fn holding the dead-letter lock, which serializes the
+// daemon's appends with gt webhooks replay rewriting the file.
+func withLock(townRoot string, fn func() error) error {
+	if err := os.MkdirAll(Dir(townRoot), 0755); err != nil {
+		return err
+	}
+	lock := flock.New(filepath.Join(Dir(townRoot), ".lock"))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking webhook state: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return fn()
+}
+
+// AppendDeadLetter records a failed delivery.
+func AppendDeadLetter(townRoot string, dl DeadLetter) error {
+	data, err := json.Marshal(dl)
+	if err != nil {
+		return err
+	}
+	return withLock(townRoot, func() error {
+		f, err := os.OpenFile(filepath.Join(Dir(townRoot), DeadLetterFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: delivery records are operational data
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.Write(append(data, '\n'))
+		return err
+	})
+}
+
+// ListDeadLetters returns the dead-lettered deliveries, oldest first.
+func ListDeadLetters(townRoot string) ([]DeadLetter, error) {
+	var out []DeadLetter
+	err := withLock(townRoot, func() error {
+		var err error
+		out, err = readDeadLetters(townRoot)
+		return err
+	})
+	return out, err
+}
+
+func readDeadLetters(townRoot string) ([]DeadLetter, error) {
+	f, err := os.Open(filepath.Join(Dir(townRoot), DeadLetterFile)) //nolint:gosec // G304: path is under the town runtime dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []DeadLetter
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var dl DeadLetter
+		if json.Unmarshal(scanner.Bytes(), &dl) == nil && dl.Event != nil {
+			out = append(out, dl)
+		}
+	}
+	return out, scanner.Err()
+}
+
+// RemoveDeadLetters rewrites the dead-letter file without the entries for
+// which drop returns true. It returns how many were removed.
+func RemoveDeadLetters(townRoot string, drop func(DeadLetter) bool) (int, error) {
+	removed := 0
+	err := withLock(townRoot, func() error {
+		all, err := readDeadLetters(townRoot)
+		if err != nil {
+			return err
+		}
+		var buf []byte
+		for _, dl := range all {
+			if drop(dl) {
+				removed++
+				continue
+			}
+			data, err := json.Marshal(dl)
+			if err != nil {
+				return err
+			}
+			buf = append(append(buf, data...), '\n')
+		}
+		if removed == 0 {
+			return nil
+		}
+		return util.AtomicWriteFile(filepath.Join(Dir(townRoot), DeadLetterFile), buf, 0644)
+	})
+	return removed, err
+}
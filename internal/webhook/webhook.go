@@ -0,0 +1,303 @@
+// Package webhook delivers Gas Town events to outbound HTTP subscriptions.
+//
+// Subscriptions are listed under "webhooks" in the town settings
+// (settings/config.json). The daemon's Dispatcher tails .events.jsonl and
+// POSTs every event whose type matches a subscription's globs. Failed
+// deliveries are retried with exponential backoff and, once a
+// subscription's attempts are exhausted, appended to a dead-letter file that
+// gt webhooks replay can redeliver.
+//
+// Each request carries:
+//
+//	X-Gastown-Event:     event type
+//	X-Gastown-Delivery:  stable event ID (the same on every retry)
+//	X-Gastown-Signature: sha256=<hex HMAC of the body> (when a secret is set)
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Defaults for delivery.
+const (
+	DefaultMaxAttempts = 5
+	RequestTimeout     = 10 * time.Second
+
+	// Backoff before retry n is BaseBackoff * 2^(n-1), capped at MaxBackoff.
+	BaseBackoff = 2 * time.Second
+	MaxBackoff  = 5 * time.Minute
+)
+
+// Request headers.
+const (
+	HeaderEvent     = "X-Gastown-Event"
+	HeaderDelivery  = "X-Gastown-Delivery"
+	HeaderSignature = "X-Gastown-Signature"
+)
+
+// TestEventType is the event type sent by gt webhooks test.
+const TestEventType = "webhook_test"
+
+// namePattern restricts names to ones usable as state file names.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Load returns the webhook subscriptions from the town settings.
+func Load(townRoot string) ([]*config.WebhookConfig, error) {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	if err := Validate(settings.Webhooks); err != nil {
+		return nil, err
+	}
+	return settings.Webhooks, nil
+}
+
+// Validate checks that subscriptions have unique names, URLs and valid
+// templates.
+func Validate(subs []*config.WebhookConfig) error {
+	seen := make(map[string]bool)
+	for i, sub := range subs {
+		if sub == nil || sub.Name == "" {
+			return fmt.Errorf("webhooks[%d]: name is required", i)
+		}
+		if !namePattern.MatchString(sub.Name) {
+			return fmt.Errorf("webhooks[%d]: name %q may only contain letters, digits, '.', '_' and '-'", i, sub.Name)
+		}
+		if seen[sub.Name] {
+			return fmt.Errorf("webhooks: duplicate name %q", sub.Name)
+		}
+		seen[sub.Name] = true
+		if !strings.HasPrefix(sub.URL, "http://") && !strings.HasPrefix(sub.URL, "https://") {
+			return fmt.Errorf("webhook %q: url must be http:// or https://", sub.Name)
+		}
+		if sub.Template != "" {
+			if _, err := parseTemplate(sub); err != nil {
+				return fmt.Errorf("webhook %q: template: %w", sub.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Find returns the subscription with the given name.
+func Find(subs []*config.WebhookConfig, name string) *config.WebhookConfig {
+	for _, sub := range subs {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Matches reports whether a subscription wants an event type.
+func Matches(sub *config.WebhookConfig, eventType string) bool {
+	if len(sub.Events) == 0 {
+		return true
+	}
+	for _, pattern := range sub.Events {
+		if util.MatchGlob(pattern, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxAttempts returns the subscription's attempt limit.
+func MaxAttempts(sub *config.WebhookConfig) int {
+	if sub.MaxAttempts > 0 {
+		return sub.MaxAttempts
+	}
+	return DefaultMaxAttempts
+}
+
+// Backoff returns the wait before the given retry (1 = first retry).
+func Backoff(retry int) time.Duration {
+	d := BaseBackoff
+	for i := 1; i < retry && d < MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, MaxBackoff)
+}
+
+// Sign returns the X-Gastown-Signature value for a body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// secret resolves a subscription secret, reading "$NAME" from the environment.
+func secret(sub *config.WebhookConfig) string {
+	if strings.HasPrefix(sub.Secret, "$") {
+		return os.Getenv(strings.TrimPrefix(sub.Secret, "$"))
+	}
+	return sub.Secret
+}
+
+// EventID returns a stable ID for a raw event line.
+func EventID(line []byte) string {
+	sum := sha256.Sum256(bytes.TrimSpace(line))
+	return hex.EncodeToString(sum[:8])
+}
+
+var templateFuncs = template.FuncMap{
+	// json renders a value as JSON, for embedding strings safely.
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+func parseTemplate(sub *config.WebhookConfig) (*template.Template, error) {
+	return template.New(sub.Name).Funcs(templateFuncs).Option("missingkey=zero").Parse(sub.Template)
+}
+
+// Body renders the request body for an event.
+func Body(sub *config.WebhookConfig, event *events.Event) ([]byte, error) {
+	if sub.Template == "" {
+		return json.Marshal(event)
+	}
+	tmpl, err := parseTemplate(sub)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, event); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Result describes one delivery attempt.
+type Result struct {
+	Status   int           `json:"status,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// OK reports whether the endpoint accepted the delivery (2xx).
+func (r Result) OK() bool {
+	return r.Error == "" && r.Status >= 200 && r.Status < 300
+}
+
+// Send POSTs one event to a subscription. It makes a single attempt; retries
+// are the caller's job.
+func Send(ctx context.Context, client *http.Client, sub *config.WebhookConfig, id string, event *events.Event) Result {
+	start := time.Now()
+	result := func(status int, err error) Result {
+		r := Result{Status: status, Duration: time.Since(start)}
+		if err != nil {
+			r.Error = err.Error()
+		} else if status < 200 || status >= 300 {
+			r.Error = fmt.Sprintf("HTTP %d", status)
+		}
+		return r
+	}
+
+	body, err := Body(sub, event)
+	if err != nil {
+		return result(0, fmt.Errorf("rendering body: %w", err))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return result(0, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gastown-webhook")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, id)
+	if key := secret(sub); key != "" {
+		req.Header.Set(HeaderSignature, Sign(key, body))
+	}
+	for k, v := range sub.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result(0, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return result(resp.StatusCode, nil)
+}
+
+// TestEvent returns the synthetic event sent by gt webhooks test.
+func TestEvent(eventType string) *events.Event {
+	if eventType == "" {
+		eventType = TestEventType
+	}
+	return &events.Event{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Source:     "gt",
+		Type:       eventType,
+		Actor:      "gt webhooks test",
+		Payload:    map[string]interface{}{"message": "Test delivery from Gas Town"},
+		Visibility: events.VisibilityAudit,
+	}
+}
+
+// ReplayResult is the outcome of redelivering one dead letter.
+type ReplayResult struct {
+	DeadLetter DeadLetter `json:"dead_letter"`
+	Result     Result     `json:"result"`
+}
+
+// Replay redelivers the dead letters selected by match, one attempt each,
+// and removes the ones that succeed. Dead letters whose subscription no
+// longer exists are reported as failures and kept.
+func Replay(ctx context.Context, townRoot string, subs []*config.WebhookConfig, match func(DeadLetter) bool) ([]ReplayResult, error) {
+	all, err := ListDeadLetters(townRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: RequestTimeout}
+	var results []ReplayResult
+	delivered := make(map[string]bool)
+	for _, dl := range all {
+		if !match(dl) {
+			continue
+		}
+		var res Result
+		if sub := Find(subs, dl.Subscription); sub == nil {
+			res = Result{Error: "subscription no longer configured"}
+		} else {
+			res = Send(ctx, client, sub, dl.ID, dl.Event)
+		}
+		if res.OK() {
+			delivered[dl.Subscription+"/"+dl.ID] = true
+		}
+		results = append(results, ReplayResult{DeadLetter: dl, Result: res})
+	}
+
+	if len(delivered) > 0 {
+		if _, err := RemoveDeadLetters(townRoot, func(dl DeadLetter) bool {
+			return delivered[dl.Subscription+"/"+dl.ID]
+		}); err != nil {
+			return results, fmt.Errorf("updating dead letters: %w", err)
+		}
+	}
+	return results, nil
+}
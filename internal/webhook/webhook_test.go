@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// receiver is a local endpoint that records requests and answers with a
+// configurable status.
+type receiver struct {
+	mu      sync.Mutex
+	status  int
+	bodies  []string
+	headers []http.Header
+	*httptest.Server
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.bodies = append(r.bodies, string(body))
+		r.headers = append(r.headers, req.Header.Clone())
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) setStatus(code int) {
+	r.mu.Lock()
+	r.status = code
+	r.mu.Unlock()
+}
+
+func (r *receiver) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.bodies...)
+}
+
+// setupTown writes town settings with the given subscriptions.
+func setupTown(t *testing.T, subs ...*config.WebhookConfig) string {
+	t.Helper()
+	townRoot := t.TempDir()
+	settings := config.NewTownSettings()
+	settings.Webhooks = subs
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+	return townRoot
+}
+
+func appendEvent(t *testing.T, townRoot, eventType, actor string) {
+	t.Helper()
+	data, _ := json.Marshal(events.Event{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339Nano),
+		Source:     "gt",
+		Type:       eventType,
+		Actor:      actor,
+		Visibility: events.VisibilityFeed,
+	})
+	f, err := os.OpenFile(filepath.Join(townRoot, events.EventsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestDispatcher(townRoot string, now *time.Time) *Dispatcher {
+	d := NewDispatcher(townRoot, func(string, ...interface{}) {})
+	d.now = func() time.Time { return *now }
+	return d
+}
+
+func TestDispatcherDeliversMatchingEvents(t *testing.T) {
+	recv := newReceiver(t)
+	townRoot := setupTown(t, &config.WebhookConfig{
+		Name:   "ci",
+		URL:    recv.URL,
+		Events: []string{"merge*"},
+		Secret: "s3cret",
+	})
+	now := time.Now()
+	d := newTestDispatcher(townRoot, &now)
+
+	appendEvent(t, townRoot, "sling", "mayor") // history: not delivered
+	d.poll()                                   // new subscription starts at the end
+	appendEvent(t, townRoot, "done", "gastown/polecats/Toast")
+	appendEvent(t, townRoot, "merged", "gastown/refinery")
+	d.poll()
+	d.poll() // nothing new: no duplicates
+
+	got := recv.received()
+	if len(got) != 1 || !strings.Contains(got[0], `"type":"merged"`) {
+		t.Fatalf("received %q, want just the merged event", got)
+	}
+	h := recv.headers[0]
+	if h.Get(HeaderEvent) != "merged" || h.Get(HeaderDelivery) == "" {
+		t.Errorf("headers = %v", h)
+	}
+	if h.Get(HeaderSignature) != Sign("s3cret", []byte(got[0])) {
+		t.Errorf("signature %q does not match body", h.Get(HeaderSignature))
+	}
+
+	st, err := LoadState(townRoot, "ci")
+	if err != nil || st == nil || st.Stats.Delivered != 1 || st.Stats.LastStatus != 200 {
+		t.Errorf("state = %+v, %v", st, err)
+	}
+}
+
+func TestDispatcherRetriesThenDeadLetters(t *testing.T) {
+	recv := newReceiver(t)
+	recv.setStatus(http.StatusBadGateway)
+	townRoot := setupTown(t, &config.WebhookConfig{Name: "chat", URL: recv.URL, MaxAttempts: 2})
+	now := time.Now()
+	d := newTestDispatcher(townRoot, &now)
+	d.poll()
+
+	appendEvent(t, townRoot, "merge_failed", "gastown/refinery")
+	appendEvent(t, townRoot, "done", "gastown/polecats/Toast")
+
+	d.poll() // attempt 1 fails: retry scheduled, later events held back
+	st, _ := LoadState(townRoot, "chat")
+	if st.Retry == nil || st.Retry.Attempts != 1 || len(recv.received()) != 1 {
+		t.Fatalf("after first failure: retry = %+v, requests = %d", st.Retry, len(recv.received()))
+	}
+
+	d.poll() // still backing off
+	if len(recv.received()) != 1 {
+		t.Fatalf("retried before backoff elapsed")
+	}
+
+	now = now.Add(Backoff(1))
+	recv.setStatus(http.StatusServiceUnavailable)
+	d.poll() // attempt 2 fails: dead-lettered, then "done" is tried once and scheduled
+	dead, err := ListDeadLetters(townRoot)
+	if err != nil || len(dead) != 1 || dead[0].Event.Type != "merge_failed" || dead[0].Attempts != 2 {
+		t.Fatalf("dead letters = %+v, %v", dead, err)
+	}
+
+	recv.setStatus(http.StatusOK)
+	now = now.Add(Backoff(1))
+	d.poll()
+	got := recv.received()
+	if !strings.Contains(got[len(got)-1], `"type":"done"`) {
+		t.Errorf("last delivery = %q, want the held-back done event", got[len(got)-1])
+	}
+
+	subs, _ := Load(townRoot)
+	results, err := Replay(context.Background(), townRoot, subs, func(DeadLetter) bool { return true })
+	if err != nil || len(results) != 1 || !results[0].Result.OK() {
+		t.Fatalf("Replay = %+v, %v", results, err)
+	}
+	if dead, _ := ListDeadLetters(townRoot); len(dead) != 0 {
+		t.Errorf("replayed dead letter not removed: %+v", dead)
+	}
+	if h := recv.headers[len(recv.headers)-1]; h.Get(HeaderDelivery) != results[0].DeadLetter.ID {
+		t.Errorf("replay delivery ID = %q, want original %q", h.Get(HeaderDelivery), results[0].DeadLetter.ID)
+	}
+}
+
+func TestDispatcherResyncsAfterPrune(t *testing.T) {
+	recv := newReceiver(t)
+	townRoot := setupTown(t, &config.WebhookConfig{Name: "ci", URL: recv.URL})
+	now := time.Now()
+	d := newTestDispatcher(townRoot, &now)
+	d.poll()
+
+	for _, actor := range []string{"a", "b", "c"} {
+		appendEvent(t, townRoot, "sling", actor)
+	}
+	d.poll()
+
+	// krc prune drops the first two events, then a new one arrives
+	eventsPath := filepath.Join(townRoot, events.EventsFile)
+	data, _ := os.ReadFile(eventsPath)
+	lines := strings.SplitAfter(string(data), "\n")
+	if err := os.WriteFile(eventsPath, []byte(lines[2]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	appendEvent(t, townRoot, "sling", "d")
+	d.poll()
+
+	got := recv.received()
+	if len(got) != 4 || !strings.Contains(got[3], `"actor":"d"`) {
+		t.Errorf("received %d events after prune, last %q; want 4 ending with d", len(got), got[len(got)-1])
+	}
+}
+
+func TestBodyTemplateAndValidate(t *testing.T) {
+	sub := &config.WebhookConfig{Name: "chat", URL: "https://x", Template: `{"text": {{json (printf "%s by %s" .Type .Actor)}}}`}
+	body, err := Body(sub, &events.Event{Type: "merged", Actor: `gastown/"refinery"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"text": "merged by gastown/\"refinery\""}`; string(body) != want {
+		t.Errorf("Body = %s, want %s", body, want)
+	}
+
+	for _, bad := range [][]*config.WebhookConfig{
+		{{Name: "", URL: "https://x"}},
+		{{Name: "a", URL: "ftp://x"}},
+		{{Name: "../a", URL: "https://x"}},
+		{{Name: "a", URL: "https://x"}, {Name: "a", URL: "https://y"}},
+		{{Name: "a", URL: "https://x", Template: "{{"}},
+	} {
+		if err := Validate(bad); err == nil {
+			t.Errorf("Validate accepted %+v", bad[len(bad)-1])
+		}
+	}
+
+	if Backoff(1) != BaseBackoff || Backoff(3) != 4*BaseBackoff || Backoff(30) != MaxBackoff {
+		t.Errorf("Backoff = %v, %v, %v", Backoff(1), Backoff(3), Backoff(30))
+	}
+	if !Matches(&config.WebhookConfig{Events: []string{"merge*"}}, "merge_failed") ||
+		Matches(&config.WebhookConfig{Events: []string{"merge*"}}, "done") {
+		t.Error("Matches globs wrong")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -138,6 +139,11 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	if err != nil {
 		return err
 	}
+	// Apply the witness role's resource limits (cgroup v2, Linux only)
+	command, warnings := sandbox.WrapRoleChecked(townRoot, m.rig.Path, "witness", sessionID, command)
+	for _, w := range warnings {
+		fmt.Printf("Warning: %s\n", w)
+	}
 
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
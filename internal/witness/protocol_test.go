@@ -2,6 +2,8 @@ package witness
 
 import (
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/mail"
 )
 
 func TestClassifyMessage(t *testing.T) {
@@ -35,6 +37,23 @@ func TestClassifyMessage(t *testing.T) {
 	}
 }
 
+// TestClassifyMessage_MailRules checks that mailbox rules, which cannot
+// import this package, classify protocol mail as ClassifyMessage does.
+func TestClassifyMessage_MailRules(t *testing.T) {
+	subjects := []string{
+		"POLECAT_DONE nux", "LIFECYCLE:Shutdown nux", "HELP: Tests failing",
+		"MERGED nux", "MERGE_FAILED ace", "🤝 HANDOFF: Patrol context", "SWARM_START",
+	}
+	for _, subject := range subjects {
+		if got, want := mail.ClassifyProtocol(subject), string(ClassifyMessage(subject)); got != want {
+			t.Errorf("mail.ClassifyProtocol(%q) = %q, want %q", subject, got, want)
+		}
+	}
+	if got := mail.ClassifyProtocol("Unknown message"); got != mail.ProtocolNone {
+		t.Errorf("mail.ClassifyProtocol(unknown) = %q, want %q", got, mail.ProtocolNone)
+	}
+}
+
 func TestParsePolecatDone(t *testing.T) {
 	subject := "POLECAT_DONE nux"
 	body := `Exit: MERGED